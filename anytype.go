@@ -0,0 +1,114 @@
+package raml
+
+import (
+	"fmt"
+
+	"github.com/acronis/go-stacktrace"
+	"gopkg.in/yaml.v3"
+)
+
+// inheritedType returns the type of the shape that inherits from a type of the parent type with the facets. Any
+// accepts every value, so types that inherit from a declared type of any are narrowed by facets of other types, e.g.
+// a subtype of any with properties is an object. Number facets narrow it to number rather than integer, since any
+// includes both. Shapes declared with the any type itself are not narrowed.
+func inheritedType(parentType string, shapeFacets []*yaml.Node) string {
+	if parentType != TypeAny {
+		return parentType
+	}
+	for i := 0; i+1 < len(shapeFacets); i += 2 {
+		if isTypeFacet(shapeFacets[i].Value) {
+			if t := identifyShapeType(shapeFacets); t != TypeInteger {
+				return t
+			}
+			return TypeNumber
+		}
+	}
+	return TypeAny
+}
+
+// isTypeFacet reports whether the facet is specific to a type other than any.
+func isTypeFacet(name string) bool {
+	for _, set := range []map[string]struct{}{
+		SetOfStringFacets, SetOfNumberFacets, SetOfFileFacets, SetOfObjectFacets, SetOfArrayFacets,
+	} {
+		if _, ok := set[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+type parseOptWithStrictAny struct{}
+
+func (parseOptWithStrictAny) Apply(opt *parserOptions) {
+	opt.strictAny = true
+}
+
+// OptWithStrictAny makes accidental uses of the any type linking errors: arrays without items and properties, items,
+// union members and facets declared inline as any, and shapes of any with facets of other types, e.g. "type: any"
+// with minLength. Declared types of the any type, e.g. "Anything: any", are considered intentional, so references to
+// them are allowed.
+func OptWithStrictAny() ParseOpt {
+	return parseOptWithStrictAny{}
+}
+
+// checkAccidentalAny reports accidental uses of the any type in declared types, see OptWithStrictAny.
+func (r *RAML) checkAccidentalAny() error {
+	var st *stacktrace.StackTrace
+	for _, n := range r.TypeGraph().Nodes {
+		walkInlineShapes(n.Shape, "", func(s *BaseShape, path string) {
+			msg := accidentalAny(s, path == "")
+			if msg == "" {
+				return
+			}
+			if path != "" {
+				msg += " at " + path
+			}
+			se := stacktrace.New(fmt.Sprintf("type %q %s", n.Name, msg), s.Location(),
+				stacktrace.WithPosition(&s.Position), stacktrace.WithType(stacktrace.TypeValidating))
+			if st == nil {
+				st = se
+			} else {
+				st = st.Append(se)
+			}
+		})
+	}
+	if st == nil {
+		return nil
+	}
+	return st
+}
+
+// accidentalAny returns why the shape accepts any value by accident, empty if it does not. The declared shape is
+// a declaration itself rather than a shape nested in a declaration.
+func accidentalAny(s *BaseShape, declared bool) string {
+	// Shapes that refer to declared types or inherit from them get their types from them.
+	if s.Alias != nil || s.Link != nil || len(s.Inherits) > 0 {
+		return ""
+	}
+	switch shape := s.Shape.(type) {
+	case *AnyShape:
+		if facet := typeFacetOfAny(s); facet != "" {
+			return fmt.Sprintf("declares facet %q of other types on any", facet)
+		}
+		if !declared {
+			return "uses any"
+		}
+	case *ArrayShape:
+		if shape.Items == nil {
+			return "has an array without items"
+		}
+	}
+	return ""
+}
+
+// typeFacetOfAny returns the first facet of the shape of any that belongs to other types, empty if there is none.
+// Any is not narrowed by such facets, only by inheritance.
+func typeFacetOfAny(s *BaseShape) string {
+	for pair := s.CustomShapeFacets.Oldest(); pair != nil; pair = pair.Next() {
+		if isTypeFacet(pair.Key) {
+			return pair.Key
+		}
+	}
+	return ""
+}
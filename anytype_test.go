@@ -0,0 +1,94 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnyNarrowing(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Anything: any
+  Named:
+    type: Anything
+    properties:
+      name: string
+  Code:
+    type: any
+    minLength: 2
+  Ratio:
+    type: Anything
+    minimum: 0.5
+  Described:
+    type: Anything
+    description: Still any.
+  Nothing: nil
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	lookup := func(name string) *BaseShape {
+		s, err := rml.LookupType(name, "")
+		require.NoError(t, err)
+		return s
+	}
+
+	anything := lookup("Anything")
+	for _, v := range []any{nil, "x", 1, []any{1}, map[string]any{"a": 1}} {
+		require.NoError(t, anything.Validate(v))
+		require.NoError(t, lookup("Described").Validate(v))
+	}
+
+	named := lookup("Named")
+	require.IsType(t, &ObjectShape{}, named.Shape)
+	require.NoError(t, named.Validate(map[string]any{"name": "Rex"}))
+	require.Error(t, named.Validate(map[string]any{"name": 1}))
+	require.Error(t, named.Validate("Rex"))
+
+	// Facets of other types do not retype any, only types that inherit from any are narrowed.
+	require.IsType(t, &AnyShape{}, lookup("Code").Shape)
+	require.NoError(t, lookup("Code").Validate("x"))
+	var warnings []string
+	for _, w := range rml.Warnings() {
+		warnings = append(warnings, w.Message)
+	}
+	require.Equal(t, []string{`facet "minLength" has no effect on any`}, warnings)
+	require.IsType(t, &NumberShape{}, lookup("Ratio").Shape)
+	require.NoError(t, lookup("Ratio").Validate(0.75))
+	require.Error(t, lookup("Ratio").Validate(0.25))
+
+	nothing := lookup("Nothing")
+	require.NoError(t, nothing.Validate(nil))
+	require.ErrorContains(t, nothing.Validate(""), "expected nil")
+}
+
+func TestOptWithStrictAny(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Anything: any
+  Tags: array
+  Pet:
+    properties:
+      name: string
+      data: any
+      extra: Anything
+      list: string[]
+      either: string | any
+      tags: Tags
+`
+	_, err := ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+
+	_, err = ParseFromString(content, "library.raml", t.TempDir(), OptWithStrictAny())
+	require.ErrorContains(t, err, `type "Pet" uses any at properties.data`)
+	// Union members of any and arrays without items are reported too, references to Anything are not.
+	require.ErrorContains(t, err, "and more (2)")
+
+	_, err = ParseFromString(`#%RAML 1.0 Library
+types:
+  Code:
+    type: any
+    minLength: 2
+`, "library.raml", t.TempDir(), OptWithStrictAny())
+	require.ErrorContains(t, err, `type "Code" declares facet "minLength" of other types on any`)
+}
@@ -0,0 +1,427 @@
+package raml
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// baseURIVersion is the reserved parameter of baseUri that is substituted with the version of the API.
+const baseURIVersion = "version"
+
+// API is the root of a RAML 1.0 API definition.
+//
+// Types, annotation types, uses and annotations of the root are parsed into Library, which is registered as
+// the fragment of the location and is the entry point of the RAML, so the root is resolved like a library.
+type API struct {
+	ID          string
+	Title       string
+	Description string
+	Version     string
+	// BaseURI is the URI template of the API, e.g. "https://{host}/api/{version}".
+	BaseURI string
+	// BaseURIParameters contains declared parameters of BaseURI. Undeclared parameters are strings.
+	BaseURIParameters *orderedmap.OrderedMap[string, *BaseShape]
+	// Protocols are the protocols of the API in upper case, "HTTP" or "HTTPS". The scheme of BaseURI is used if
+	// protocols are not declared.
+	Protocols []string
+	// MediaType contains the default media types of bodies declared without media type.
+	MediaType     []string
+	Documentation []*DocumentationItem
+	// SecuredBy contains names of security schemes that apply to all methods of the API.
+	SecuredBy []string
+
+	// Resources contains top-level resources by their paths. Traits, resource types and security schemes are
+	// already applied to their methods.
+	Resources       *orderedmap.OrderedMap[string, *Resource]
+	Traits          *orderedmap.OrderedMap[string, *Trait]
+	ResourceTypes   *orderedmap.OrderedMap[string, *ResourceType]
+	SecuritySchemes *orderedmap.OrderedMap[string, *SecurityScheme]
+
+	Library *Library
+
+	Location string
+	raml     *RAML
+	baseURI  []uriTemplatePart
+}
+
+// DocumentationItem is an entry of the user documentation of the API. Content is Markdown.
+type DocumentationItem struct {
+	Title   string
+	Content string
+
+	Location string
+	stacktrace.Position
+}
+
+// API returns the API definition if the entry point is an API root, nil otherwise.
+func (r *RAML) API() *API {
+	return r.api
+}
+
+func (r *RAML) MakeAPI(path string) *API {
+	return &API{
+		BaseURIParameters: orderedmap.New[string, *BaseShape](0),
+		Resources:         orderedmap.New[string, *Resource](0),
+		Traits:            orderedmap.New[string, *Trait](0),
+		ResourceTypes:     orderedmap.New[string, *ResourceType](0),
+		SecuritySchemes:   orderedmap.New[string, *SecurityScheme](0),
+		Library:           r.MakeLibrary(path),
+
+		Location: path,
+		raml:     r,
+	}
+}
+
+// UnmarshalYAML unmarshals an API from a yaml.Node, implementing the yaml.Unmarshaler interface
+func (api *API) UnmarshalYAML(value *yaml.Node) error {
+	if err := api.Library.UnmarshalYAML(value); err != nil {
+		return err
+	}
+	var titleSet bool
+	for i := 0; i != len(value.Content); i += 2 {
+		node := value.Content[i]
+		valueNode := value.Content[i+1]
+		switch node.Value {
+		case "title":
+			if err := decodeScalar(valueNode, &api.Title, api.Location); err != nil {
+				return StacktraceNewWrapped("parse title", err, api.Location, WithNodePosition(valueNode))
+			}
+			titleSet = true
+		case "description":
+			if err := decodeScalar(valueNode, &api.Description, api.Location); err != nil {
+				return StacktraceNewWrapped("parse description", err, api.Location, WithNodePosition(valueNode))
+			}
+		case "version":
+			if err := decodeScalar(valueNode, &api.Version, api.Location); err != nil {
+				return StacktraceNewWrapped("parse version", err, api.Location, WithNodePosition(valueNode))
+			}
+		case "baseUri":
+			if err := api.unmarshalBaseURI(valueNode); err != nil {
+				return fmt.Errorf("unmarshal base uri: %w", err)
+			}
+		case "baseUriParameters":
+			if err := api.unmarshalBaseURIParameters(valueNode); err != nil {
+				return fmt.Errorf("unmarshal base uri parameters: %w", err)
+			}
+		case "protocols":
+			if err := api.unmarshalProtocols(valueNode); err != nil {
+				return fmt.Errorf("unmarshal protocols: %w", err)
+			}
+		case "mediaType":
+			if err := api.unmarshalMediaType(valueNode); err != nil {
+				return fmt.Errorf("unmarshal media type: %w", err)
+			}
+		case "documentation":
+			if err := api.unmarshalDocumentation(valueNode); err != nil {
+				return fmt.Errorf("unmarshal documentation: %w", err)
+			}
+		case "traits":
+			if err := api.unmarshalTraits(valueNode); err != nil {
+				return fmt.Errorf("unmarshal traits: %w", err)
+			}
+		case "resourceTypes":
+			if err := api.unmarshalResourceTypes(valueNode); err != nil {
+				return fmt.Errorf("unmarshal resource types: %w", err)
+			}
+		case "securitySchemes":
+			if err := api.unmarshalSecuritySchemes(valueNode); err != nil {
+				return fmt.Errorf("unmarshal security schemes: %w", err)
+			}
+		case "securedBy":
+			securedBy, err := decodeSecuredBy(valueNode, api.Location)
+			if err != nil {
+				return StacktraceNewWrapped("parse securedBy", err, api.Location, WithNodePosition(valueNode))
+			}
+			api.SecuredBy = securedBy
+		}
+	}
+	if !titleSet {
+		return stacktrace.New("title is required", api.Location, WithNodePosition(value))
+	}
+	if err := api.unmarshalResources(value); err != nil {
+		return fmt.Errorf("unmarshal resources: %w", err)
+	}
+	if api.Protocols == nil {
+		if scheme, _, ok := strings.Cut(api.BaseURI, "://"); ok && isAPIProtocol(scheme) {
+			api.Protocols = []string{strings.ToUpper(scheme)}
+		}
+	}
+	return api.checkBaseURI(value)
+}
+
+// decodeScalars decodes the scalar or the sequence of scalars node as strings.
+func decodeScalars(node *yaml.Node, location string) ([]string, error) {
+	if node.Kind == yaml.ScalarNode {
+		var v string
+		if err := decodeScalar(node, &v, location); err != nil {
+			return nil, err
+		}
+		return []string{v}, nil
+	}
+	if node.Kind != yaml.SequenceNode {
+		return nil, stacktrace.New("must be scalar or sequence", location, WithNodePosition(node))
+	}
+	values := make([]string, len(node.Content))
+	for i, item := range node.Content {
+		if err := decodeScalar(item, &values[i], location); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func isAPIProtocol(protocol string) bool {
+	return strings.EqualFold(protocol, "http") || strings.EqualFold(protocol, "https")
+}
+
+func (api *API) unmarshalProtocols(valueNode *yaml.Node) error {
+	protocols, err := decodeScalars(valueNode, api.Location)
+	if err != nil {
+		return err
+	}
+	if len(protocols) == 0 {
+		return stacktrace.New("protocols must not be empty", api.Location, WithNodePosition(valueNode))
+	}
+	for i, protocol := range protocols {
+		if !isAPIProtocol(protocol) {
+			return stacktrace.New("protocol must be HTTP or HTTPS", api.Location, WithNodePosition(valueNode),
+				stacktrace.WithInfo("protocol", protocol))
+		}
+		protocols[i] = strings.ToUpper(protocol)
+	}
+	api.Protocols = protocols
+	return nil
+}
+
+func (api *API) unmarshalMediaType(valueNode *yaml.Node) error {
+	mediaTypes, err := decodeScalars(valueNode, api.Location)
+	if err != nil {
+		return err
+	}
+	for _, mediaType := range mediaTypes {
+		if _, _, err := mime.ParseMediaType(mediaType); err != nil {
+			return StacktraceNewWrapped("parse media type", err, api.Location, WithNodePosition(valueNode),
+				stacktrace.WithInfo("mediaType", mediaType))
+		}
+	}
+	api.MediaType = mediaTypes
+	return nil
+}
+
+func (api *API) unmarshalDocumentation(valueNode *yaml.Node) error {
+	if valueNode.Kind != yaml.SequenceNode {
+		return stacktrace.New("must be sequence", api.Location, WithNodePosition(valueNode))
+	}
+	api.Documentation = make([]*DocumentationItem, 0, len(valueNode.Content))
+	for _, itemNode := range valueNode.Content {
+		item, err := api.makeDocumentationItem(itemNode)
+		if err != nil {
+			return err
+		}
+		api.Documentation = append(api.Documentation, item)
+	}
+	return nil
+}
+
+func (api *API) makeDocumentationItem(node *yaml.Node) (*DocumentationItem, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, stacktrace.New("documentation item must be map", api.Location, WithNodePosition(node))
+	}
+	item := &DocumentationItem{
+		Location: api.Location,
+		Position: stacktrace.Position{Line: node.Line, Column: node.Column},
+	}
+	var titleSet, contentSet bool
+	for i := 0; i != len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		valueNode := node.Content[i+1]
+		switch keyNode.Value {
+		case "title":
+			if err := decodeScalar(valueNode, &item.Title, api.Location); err != nil {
+				return nil, StacktraceNewWrapped("parse title", err, api.Location, WithNodePosition(valueNode))
+			}
+			titleSet = true
+		case "content":
+			if err := api.decodeContent(valueNode, &item.Content); err != nil {
+				return nil, StacktraceNewWrapped("parse content", err, api.Location, WithNodePosition(valueNode))
+			}
+			contentSet = true
+		default:
+			return nil, stacktrace.New("unknown documentation item facet", api.Location,
+				WithNodePosition(keyNode), stacktrace.WithInfo("facet", keyNode.Value))
+		}
+	}
+	if !titleSet || !contentSet {
+		return nil, stacktrace.New("documentation item requires title and content", api.Location,
+			WithNodePosition(node))
+	}
+	return item, nil
+}
+
+// decodeContent decodes the scalar node or the content of the file it includes, usually a Markdown file.
+func (api *API) decodeContent(node *yaml.Node, out *string) error {
+	if node.Tag != TagInclude {
+		return decodeScalar(node, out, api.Location)
+	}
+	n, err := api.raml.makeIncludedNode(node, api.Location)
+	if err != nil {
+		return err
+	}
+	content, ok := n.Value.(string)
+	if !ok {
+		return stacktrace.New("included content must be text", api.Location, WithNodePosition(node))
+	}
+	*out = content
+	return nil
+}
+
+// decodeScalar decodes the scalar node as a string, so numeric values, e.g. "version: 1.0", are kept as written.
+func decodeScalar(node *yaml.Node, out *string, location string) error {
+	if node.Kind != yaml.ScalarNode {
+		return stacktrace.New("must be scalar", location, WithNodePosition(node))
+	}
+	if node.Tag != TagNull {
+		*out = node.Value
+	}
+	return nil
+}
+
+func (api *API) unmarshalBaseURI(valueNode *yaml.Node) error {
+	if err := decodeScalar(valueNode, &api.BaseURI, api.Location); err != nil {
+		return err
+	}
+	parts, err := parseURITemplate(api.BaseURI)
+	if err != nil {
+		return StacktraceNewWrapped("parse uri template", err, api.Location, WithNodePosition(valueNode))
+	}
+	api.baseURI = parts
+	return nil
+}
+
+func (api *API) unmarshalBaseURIParameters(valueNode *yaml.Node) error {
+	if valueNode.Tag == TagNull {
+		return nil
+	}
+	if valueNode.Kind != yaml.MappingNode {
+		return stacktrace.New("must be map", api.Location, WithNodePosition(valueNode))
+	}
+	// Map nodes come in pairs in order [key, value]
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		name := valueNode.Content[j].Value
+		data := valueNode.Content[j+1]
+		if name == baseURIVersion {
+			return stacktrace.New("version is a reserved base uri parameter", api.Location,
+				WithNodePosition(valueNode.Content[j]))
+		}
+		shape, err := api.raml.makeNewShapeYAML(data, name, api.Location)
+		if err != nil {
+			return StacktraceNewWrapped("make shape", err, api.Location, WithNodePosition(data))
+		}
+		api.BaseURIParameters.Set(name, shape)
+	}
+	return nil
+}
+
+// checkBaseURI checks that parameters of baseUri match the declared ones and the version.
+func (api *API) checkBaseURI(value *yaml.Node) error {
+	used := make(map[string]struct{})
+	for _, name := range uriTemplateParams(api.baseURI) {
+		used[name] = struct{}{}
+		if name == baseURIVersion && api.Version == "" {
+			return stacktrace.New("baseUri uses {version}, but version is not set", api.Location,
+				WithNodePosition(value))
+		}
+	}
+	for pair := api.BaseURIParameters.Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := used[pair.Key]; !ok {
+			api.raml.addWarning(fmt.Sprintf("base uri parameter %s is not used in baseUri", pair.Key), api.Location,
+				stacktrace.WithPosition(&pair.Value.Position))
+		}
+	}
+	return nil
+}
+
+// ExpandBaseURI returns the base URI with parameters substituted with the values and {version} substituted with
+// the version of the API. Values are validated against the declared parameters, defaults are used for missing
+// values. Parse with OptWithUnwrap to validate values against facets inherited by the parameters.
+func (api *API) ExpandBaseURI(values map[string]string) (string, error) {
+	if api.BaseURI == "" {
+		return "", fmt.Errorf("base uri is not set")
+	}
+	expanded := make(map[string]string, len(values)+1)
+	for _, name := range uriTemplateParams(api.baseURI) {
+		if name == baseURIVersion {
+			expanded[name] = api.Version
+			continue
+		}
+		shape, declared := api.BaseURIParameters.Get(name)
+		value, ok := values[name]
+		if !ok && declared && shape.Default != nil {
+			value, ok = fmt.Sprint(shape.Default.Value), true
+		}
+		if !ok {
+			return "", fmt.Errorf("base uri parameter %s is missing", name)
+		}
+		if declared {
+			if err := shape.Validate(ParameterValue(shape, []string{value})); err != nil {
+				return "", fmt.Errorf("base uri parameter %s: %w", name, err)
+			}
+		}
+		expanded[name] = value
+	}
+	return expandURITemplate(api.baseURI, expanded), nil
+}
+
+// unwrapAPI unwraps shapes of the API that are not declared in its library.
+func (r *RAML) unwrapAPI() *stacktrace.StackTrace {
+	if r.api == nil {
+		return nil
+	}
+	st := r.unwrapShapeMap(r.api.BaseURIParameters, nil)
+	var walk func(resources *orderedmap.OrderedMap[string, *Resource])
+	walk = func(resources *orderedmap.OrderedMap[string, *Resource]) {
+		for pair := resources.Oldest(); pair != nil; pair = pair.Next() {
+			res := pair.Value
+			st = r.unwrapShapeMap(res.URIParameters, st)
+			for m := res.Methods.Oldest(); m != nil; m = m.Next() {
+				st = r.unwrapShapeMap(m.Value.Headers, st)
+				st = r.unwrapShapeMap(m.Value.QueryParameters, st)
+				st = r.unwrapShapeMap(m.Value.Body, st)
+				for resp := m.Value.Responses.Oldest(); resp != nil; resp = resp.Next() {
+					st = r.unwrapShapeMap(resp.Value.Headers, st)
+					st = r.unwrapShapeMap(resp.Value.Body, st)
+				}
+			}
+			walk(res.Resources)
+		}
+	}
+	walk(r.api.Resources)
+	return st
+}
+
+// unwrapShapeMap replaces shapes of the map with unwrapped ones and appends errors to the stack trace.
+func (r *RAML) unwrapShapeMap(
+	shapes *orderedmap.OrderedMap[string, *BaseShape], st *stacktrace.StackTrace,
+) *stacktrace.StackTrace {
+	for pair := shapes.Oldest(); pair != nil; pair = pair.Next() {
+		us, err := r.unwrapBaseShape(pair.Value)
+		if err != nil {
+			se := StacktraceNewWrapped("unwrap shape", err, r.api.Location,
+				stacktrace.WithType(stacktrace.TypeUnwrapping), stacktrace.WithPosition(&pair.Value.Position))
+			if st == nil {
+				st = se
+			} else {
+				st = st.Append(se)
+			}
+			continue
+		}
+		shapes.Set(pair.Key, us)
+	}
+	return st
+}
@@ -0,0 +1,157 @@
+package raml
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_ExpandBaseURI(t *testing.T) {
+	content := `#%RAML 1.0
+title: Pets
+version: v1.2
+baseUri: https://{tenant}.example.com/{region}/api/{version}
+baseUriParameters:
+  tenant:
+    type: Tenant
+  region:
+    enum: [eu, us]
+    default: eu
+types:
+  Tenant:
+    type: string
+    pattern: ^[a-z]+$
+`
+	rml, err := ParseFromString(content, "api.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	api := rml.API()
+	require.NotNil(t, api)
+	require.Equal(t, "Pets", api.Title)
+	require.Equal(t, "v1.2", api.Version)
+	var names []string
+	for pair := api.BaseURIParameters.Oldest(); pair != nil; pair = pair.Next() {
+		names = append(names, pair.Key)
+	}
+	require.Equal(t, []string{"tenant", "region"}, names)
+	_, err = rml.LookupType("Tenant", "")
+	require.NoError(t, err)
+
+	uri, err := api.ExpandBaseURI(map[string]string{"tenant": "acme"})
+	require.NoError(t, err)
+	require.Equal(t, "https://acme.example.com/eu/api/v1.2", uri)
+	uri, err = api.ExpandBaseURI(map[string]string{"tenant": "acme", "region": "us"})
+	require.NoError(t, err)
+	require.Equal(t, "https://acme.example.com/us/api/v1.2", uri)
+
+	_, err = api.ExpandBaseURI(map[string]string{"tenant": "Acme"})
+	require.ErrorContains(t, err, "base uri parameter tenant")
+	_, err = api.ExpandBaseURI(map[string]string{"tenant": "acme", "region": "asia"})
+	require.ErrorContains(t, err, "base uri parameter region")
+	_, err = api.ExpandBaseURI(nil)
+	require.ErrorContains(t, err, "base uri parameter tenant is missing")
+}
+
+func TestAPI_baseURIErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "missing title",
+			content: "#%RAML 1.0\nbaseUri: https://example.com\n",
+			wantErr: "title is required",
+		},
+		{
+			name:    "version is not set",
+			content: "#%RAML 1.0\ntitle: Pets\nbaseUri: https://example.com/{version}\n",
+			wantErr: "baseUri uses {version}, but version is not set",
+		},
+		{
+			name:    "unclosed parameter",
+			content: "#%RAML 1.0\ntitle: Pets\nbaseUri: https://{host/api\n",
+			wantErr: "unclosed \"{\" at 8",
+		},
+		{
+			name: "reserved version",
+			content: "#%RAML 1.0\ntitle: Pets\nversion: 1\nbaseUri: https://example.com/{version}\n" +
+				"baseUriParameters:\n  version: string\n",
+			wantErr: "version is a reserved base uri parameter",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFromString(tt.content, "api.raml", t.TempDir())
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	rml, err := ParseFromString("#%RAML 1.0\ntitle: Pets\nbaseUri: https://example.com\n"+
+		"baseUriParameters:\n  host: string\n", "api.raml", t.TempDir())
+	require.NoError(t, err)
+	require.Len(t, rml.Warnings(), 1)
+	require.Contains(t, rml.Warnings()[0].Error(), "base uri parameter host is not used in baseUri")
+	uri, err := rml.API().ExpandBaseURI(nil)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com", uri)
+}
+
+func TestAPI_rootNodes(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "auth.md"), []byte("Use **tokens**.\n"), 0o600))
+	content := `#%RAML 1.0
+title: Pets
+description: The pet store.
+baseUri: https://example.com
+mediaType: [application/json, application/xml]
+documentation:
+  - title: Overview
+    content: Pets are animals.
+  - title: Authentication
+    content: !include auth.md
+types:
+  Pet:
+    properties:
+      name: string
+`
+	rml, err := ParseFromString(content, "api.raml", dir)
+	require.NoError(t, err)
+	api := rml.API()
+	require.Equal(t, []string{"HTTPS"}, api.Protocols)
+	require.Equal(t, []string{"application/json", "application/xml"}, api.MediaType)
+	require.Len(t, api.Documentation, 2)
+	require.Equal(t, "Overview", api.Documentation[0].Title)
+	require.Equal(t, "Pets are animals.", api.Documentation[0].Content)
+	require.Equal(t, "Use **tokens**.\n", api.Documentation[1].Content)
+
+	doc, err := NewDocGenerator().Generate(rml)
+	require.NoError(t, err)
+	require.Equal(t, "Pets", doc.Title)
+	require.Equal(t, "The pet store.", doc.Usage)
+	var md strings.Builder
+	require.NoError(t, doc.Write(&md, DocFormatMarkdown))
+	require.Contains(t, md.String(), "## Authentication\n\nUse **tokens**.\n\n## Types")
+
+	rml, err = ParseFromString("#%RAML 1.0\ntitle: Pets\nprotocols: [http, HTTPS]\nmediaType: application/json\n",
+		"api.raml", dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"HTTP", "HTTPS"}, rml.API().Protocols)
+	require.Equal(t, []string{"application/json"}, rml.API().MediaType)
+
+	for _, tt := range []struct {
+		content string
+		wantErr string
+	}{
+		{content: "protocols: [FTP]", wantErr: "protocol must be HTTP or HTTPS"},
+		{content: "mediaType: [application/json, ';']", wantErr: "parse media type"},
+		{content: "documentation:\n  - title: Overview", wantErr: "requires title and content"},
+		{content: "documentation:\n  title: Overview", wantErr: "must be sequence"},
+		{content: "documentation:\n  - {title: a, body: b}", wantErr: "unknown documentation item facet"},
+	} {
+		_, err = ParseFromString("#%RAML 1.0\ntitle: Pets\n"+tt.content+"\n", "api.raml", dir)
+		require.ErrorContains(t, err, tt.wantErr)
+	}
+}
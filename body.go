@@ -0,0 +1,102 @@
+package raml
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// ErrBodyNotFound is returned by SelectBody if no body is declared for the media type.
+var ErrBodyNotFound = errors.New("body not found")
+
+// SelectBody returns the body declared for the media type of the Content-Type (or Accept) header value and
+// the declared media type it matched. Keys of bodies are media types as they are declared in the body of a method or
+// response, e.g. "application/json" or "image/*". The empty key is a body declared without media type, which applies
+// to defaultMediaTypes (the API-level mediaType) or to any media type if there are no defaults.
+//
+// Parameters of media types (e.g. charset) are ignored. Both declared and requested media types may contain
+// wildcards. The most specific match wins: exact media types, then structured syntax suffixes (e.g.
+// "application/merge-patch+json" matches "application/json"), then "type/*" and "*/*" wildcards. Bodies that match
+// equally are taken in the order of declaration.
+//
+// Bodies of API definitions are Method.Body and Response.Body, and defaultMediaTypes are API.MediaType.
+func SelectBody(
+	bodies *orderedmap.OrderedMap[string, *BaseShape], defaultMediaTypes []string, contentType string,
+) (*BaseShape, string, error) {
+	requested, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, "", fmt.Errorf("parse media type %q: %w", contentType, err)
+	}
+	if !strings.Contains(requested, "/") {
+		return nil, "", fmt.Errorf("parse media type %q: subtype is missing", contentType)
+	}
+	var (
+		best      *BaseShape
+		bestType  string
+		bestScore int
+	)
+	consider := func(shape *BaseShape, declared string) {
+		score := 1
+		if declared != "" {
+			score = mediaTypeMatchScore(declared, requested)
+		}
+		if score > bestScore {
+			best, bestType, bestScore = shape, declared, score
+		}
+	}
+	for pair := bodies.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Key != "" {
+			consider(pair.Value, pair.Key)
+			continue
+		}
+		if len(defaultMediaTypes) == 0 {
+			consider(pair.Value, "")
+		}
+		for _, mt := range defaultMediaTypes {
+			// Explicit declarations take precedence over defaults.
+			if _, ok := bodies.Get(mt); !ok {
+				consider(pair.Value, mt)
+			}
+		}
+	}
+	if best == nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrBodyNotFound, requested)
+	}
+	return best, bestType, nil
+}
+
+// mediaTypeMatchScore returns how specifically the declared media type matches the requested one, zero means no match.
+func mediaTypeMatchScore(declared string, requested string) int {
+	d, _, err := mime.ParseMediaType(declared)
+	if err != nil {
+		return 0
+	}
+	switch {
+	case d == requested:
+		return 5
+	case mediaTypeSuffix(requested) == d || mediaTypeSuffix(d) == requested:
+		return 4
+	case matchMediaType(d, requested) && d != "*/*", matchMediaType(requested, d) && requested != "*/*":
+		return 3
+	case d == "*/*" || requested == "*/*":
+		return 2
+	}
+	return 0
+}
+
+// mediaTypeSuffix returns the media type of the structured syntax suffix, e.g. "application/json" for
+// "application/vnd.api+json", or an empty string if there is no suffix.
+func mediaTypeSuffix(mediaType string) string {
+	typ, subtype, ok := strings.Cut(mediaType, "/")
+	if !ok {
+		return ""
+	}
+	i := strings.LastIndex(subtype, "+")
+	if i < 0 {
+		return ""
+	}
+	return typ + "/" + subtype[i+1:]
+}
@@ -0,0 +1,52 @@
+package raml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/acronis/go-stacktrace"
+	"github.com/stretchr/testify/require"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+func TestSelectBody(t *testing.T) {
+	r := New(context.Background())
+	shape := func(name string) *BaseShape {
+		return r.MakeBaseShape(name, "api.raml", &stacktrace.Position{})
+	}
+	bodies := orderedmap.New[string, *BaseShape]()
+	bodies.Set("application/json", shape("json"))
+	bodies.Set("image/*", shape("image"))
+	bodies.Set("", shape("default"))
+
+	tests := []struct {
+		contentType string
+		defaults    []string
+		want        string
+		wantType    string
+		err         string
+	}{
+		{contentType: "application/json; charset=utf-8", want: "json", wantType: "application/json"},
+		{contentType: "application/merge-patch+json", want: "json", wantType: "application/json"},
+		{contentType: "image/png", want: "image", wantType: "image/*"},
+		{contentType: "text/plain", want: "default", wantType: ""},
+		{contentType: "text/plain", defaults: []string{"application/xml"}, err: "body not found: text/plain"},
+		{contentType: "application/xml", defaults: []string{"application/xml"}, want: "default",
+			wantType: "application/xml"},
+		{contentType: "application/*", defaults: []string{"application/json"}, want: "json",
+			wantType: "application/json"},
+		{contentType: "invalid", err: "parse media type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			got, gotType, err := SelectBody(bodies, tt.defaults, tt.contentType)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.Name)
+			require.Equal(t, tt.wantType, gotType)
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package raml
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// Canonicalize returns a canonical copy of the shape that is suitable for hashing and caching of type signatures:
+// shapes that accept the same values have the same canonical form. The copy is normalized as follows:
+//   - inheritance, links and aliases are flattened into complete types;
+//   - nested unions are expanded into members of the outer union, e.g. "(A | B)?" becomes "A | B | nil";
+//   - duplicate union members are removed and members are sorted by their canonical form;
+//   - properties are sorted by name;
+//   - facets equal to their defaults (e.g. "minLength: 0" or "additionalProperties: true") are removed;
+//   - documentation facets, examples and comments are removed.
+//
+// Names of types are kept, and implicit discriminator values are set explicitly since they depend on names.
+// The shape itself is not modified.
+func (s *BaseShape) Canonicalize() (*BaseShape, error) {
+	c := s.CloneDetached()
+	if !c.IsUnwrapped() {
+		if s.raml == nil {
+			return nil, fmt.Errorf("canonicalize %s: shape is not unwrapped", s.Name)
+		}
+		// The copy is unwrapped by a scratch RAML, so canonicalization registers no shapes in the model and can
+		// run concurrently with other readers of it.
+		sr := s.raml.scratch()
+		c.walk(func(b *BaseShape) { b.raml = sr })
+		us, err := sr.UnwrapShape(c)
+		if err != nil {
+			return nil, fmt.Errorf("canonicalize %s: %w", s.Name, err)
+		}
+		if _, err = sr.FindAndMarkRecursion(us); err != nil {
+			return nil, fmt.Errorf("canonicalize %s: %w", s.Name, err)
+		}
+		c = us
+	}
+	cn := &canonicalizer{visited: make(map[int64]struct{})}
+	if err := cn.shape(c); err != nil {
+		return nil, fmt.Errorf("canonicalize %s: %w", s.Name, err)
+	}
+	return c, nil
+}
+
+// scratch returns a RAML that reads fragments and options of r, but keeps shapes, IDs, recursion cycles and warnings
+// created by unwrapping to itself.
+func (r *RAML) scratch() *RAML {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	locations := slices.Clip(r.locationTable())
+	scratch := &RAML{
+		fragmentTypes:               r.fragmentTypes,
+		fragmentAnnotationTypes:     r.fragmentAnnotationTypes,
+		fragmentsCache:              r.fragmentsCache,
+		lastShapeID:                 atomic.LoadInt64(&r.lastShapeID),
+		derivedShapeIDs:             r.derivedShapeIDs,
+		usedShapeIDs:                maps.Clone(r.usedShapeIDs),
+		lowMemory:                   r.lowMemory,
+		locationIDs:                 maps.Clone(r.locationIDs),
+		fractionalSeconds:           r.fractionalSeconds,
+		facetValidators:             r.facetValidators,
+		regexEngine:                 r.regexEngine,
+		lenientPatterns:             r.lenientPatterns,
+		restrictedPatternProperties: r.restrictedPatternProperties,
+		inheritancePolicy:           r.inheritancePolicy,
+		maxRecursionDepth:           r.maxRecursionDepth,
+		ctx:                         r.ctx,
+	}
+	scratch.locations.Store(&locations)
+	return scratch
+}
+
+// walk calls f for the shape and every shape it refers to: parents, links, aliases, members, items and properties.
+func (s *BaseShape) walk(f func(*BaseShape)) {
+	visited := make(map[*BaseShape]struct{})
+	var visit func(b *BaseShape)
+	visit = func(b *BaseShape) {
+		if b == nil {
+			return
+		}
+		if _, ok := visited[b]; ok {
+			return
+		}
+		visited[b] = struct{}{}
+		f(b)
+		visit(b.Alias)
+		if b.Link != nil {
+			visit(b.Link.Shape)
+		}
+		for _, p := range b.Inherits {
+			visit(p)
+		}
+		if b.CustomShapeFacetDefinitions != nil {
+			for pair := b.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
+				visit(pair.Value.Shape)
+			}
+		}
+		switch shape := b.Shape.(type) {
+		case *ArrayShape:
+			visit(shape.Items)
+		case *ObjectShape:
+			if shape.Properties != nil {
+				for pair := shape.Properties.Oldest(); pair != nil; pair = pair.Next() {
+					visit(pair.Value.Shape)
+				}
+			}
+			if shape.PatternProperties != nil {
+				for pair := shape.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+					visit(pair.Value.Shape)
+				}
+			}
+		case *UnionShape:
+			for _, m := range shape.AnyOf {
+				visit(m)
+			}
+		case *RecursiveShape:
+			visit(shape.Head)
+		}
+	}
+	visit(s)
+}
+
+// canonicalizer normalizes unwrapped shapes in-place.
+type canonicalizer struct {
+	visited map[int64]struct{}
+}
+
+func (cn *canonicalizer) shape(s *BaseShape) error {
+	if _, ok := cn.visited[s.ID]; ok {
+		return nil
+	}
+	cn.visited[s.ID] = struct{}{}
+
+	// Parents are merged into unwrapped shapes, so the shape is written as a complete type.
+	s.TypeLabel = ""
+	s.Inherits = nil
+	s.DisplayName = nil
+	s.Description = nil
+	s.Example = nil
+	s.Examples = nil
+	s.Comments = nil
+
+	switch shape := s.Shape.(type) {
+	case *StringShape:
+		dropZero(&shape.MinLength)
+	case *FileShape:
+		dropZero(&shape.MinLength)
+	case *ArrayShape:
+		dropZero(&shape.MinItems)
+		if shape.UniqueItems != nil && !*shape.UniqueItems {
+			shape.UniqueItems = nil
+		}
+		if shape.Items != nil {
+			return cn.shape(shape.Items)
+		}
+	case *ObjectShape:
+		return cn.object(shape)
+	case *UnionShape:
+		return cn.union(shape)
+	}
+	return nil
+}
+
+func (cn *canonicalizer) object(s *ObjectShape) error {
+	dropZero(&s.MinProperties)
+	if s.AdditionalProperties != nil && *s.AdditionalProperties {
+		s.AdditionalProperties = nil
+	}
+	if s.Discriminator != nil && s.DiscriminatorValue == nil {
+		s.DiscriminatorValue = s.Name
+	}
+	if s.Properties != nil {
+		keys := make([]string, 0, s.Properties.Len())
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			keys = append(keys, pair.Key)
+		}
+		slices.Sort(keys)
+		props := orderedmap.New[string, Property](len(keys))
+		for _, k := range keys {
+			prop, _ := s.Properties.Get(k)
+			prop.Comments = nil
+			if err := cn.shape(prop.Shape); err != nil {
+				return fmt.Errorf("property %s: %w", k, err)
+			}
+			props.Set(k, prop)
+		}
+		s.Properties = props
+	}
+	for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		if err := cn.shape(pair.Value.Shape); err != nil {
+			return fmt.Errorf("pattern property %s: %w", pair.Key, err)
+		}
+	}
+	return nil
+}
+
+func (cn *canonicalizer) union(s *UnionShape) error {
+	members := make([]*BaseShape, 0, len(s.AnyOf))
+	for _, member := range s.AnyOf {
+		if err := cn.shape(member); err != nil {
+			return fmt.Errorf("union member %s: %w", member.Name, err)
+		}
+		// Nested unions without own enum accept values of any of their members.
+		if nested, ok := member.Shape.(*UnionShape); ok && nested.Enum == nil {
+			members = append(members, nested.AnyOf...)
+			continue
+		}
+		members = append(members, member)
+	}
+
+	keys := make(map[int64]string, len(members))
+	for _, member := range members {
+		b, err := member.signature()
+		if err != nil {
+			return fmt.Errorf("union member %s: %w", member.Name, err)
+		}
+		keys[member.ID] = string(b)
+	}
+	slices.SortStableFunc(members, func(a, b *BaseShape) int {
+		return strings.Compare(keys[a.ID], keys[b.ID])
+	})
+	s.AnyOf = slices.CompactFunc(members, func(a, b *BaseShape) bool {
+		return keys[a.ID] == keys[b.ID]
+	})
+	return nil
+}
+
+// signature serializes the canonical shape to YAML. Unlike MarshalRAML, members of unions that cannot be written as
+// type expressions are written in place to the "anyOf" facet.
+func (s *BaseShape) signature() ([]byte, error) {
+	ms := newMarshaller()
+	ms.anyOf = true
+	node, err := ms.shape(s)
+	if err != nil {
+		return nil, fmt.Errorf("marshal shape: %w", err)
+	}
+	return encodeRAML("", node)
+}
+
+// dropZero unsets the facet if it is set to zero, which is the default of lower bounds.
+func dropZero(v **uint64) {
+	if *v != nil && **v == 0 {
+		*v = nil
+	}
+}
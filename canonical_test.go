@@ -0,0 +1,106 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseShape_Canonicalize(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Base:
+    description: Base type.
+    properties:
+      id: integer
+  Pet:
+    type: Base
+    additionalProperties: true
+    properties:
+      name:
+        type: string
+        minLength: 0
+      tags:
+        type: array
+        items: string
+        uniqueItems: false
+  PetFlat:
+    displayName: Flat pet
+    properties:
+      tags: string[]
+      name: string
+      id: integer
+    example:
+      id: 1
+      name: Rex
+      tags: []
+  Name: string?
+  Either:
+    type: Pet | Name | integer | string
+`
+	for _, opts := range [][]ParseOpt{{OptWithUnwrap()}, nil} {
+		r, err := ParseFromString(content, "library.raml", t.TempDir(), opts...)
+		require.NoError(t, err)
+		signature := func(name string) string {
+			s, err := r.LookupType(name, r.GetLocation())
+			require.NoError(t, err)
+			c, err := s.Canonicalize()
+			require.NoError(t, err)
+			b, err := c.signature()
+			require.NoError(t, err)
+			return string(b)
+		}
+
+		pet := signature("Pet")
+		require.Equal(t, `type: object
+properties:
+  id: integer
+  name: string
+  tags: string[]
+`, pet)
+		require.Equal(t, pet, signature("PetFlat"))
+		require.Equal(t, "nil | string\n", signature("Name"))
+		require.Equal(t, `type: union
+anyOf:
+  - integer
+  - nil
+  - string
+  - type: object
+    properties:
+      id: integer
+      name: string
+      tags: string[]
+`, signature("Either"))
+
+		// The shape itself is not modified.
+		s, err := r.LookupType("Pet", r.GetLocation())
+		require.NoError(t, err)
+		require.Equal(t, "Base", s.TypeLabel)
+		require.True(t, *s.Shape.(*ObjectShape).AdditionalProperties)
+
+		// Nothing is registered in the model by canonicalization.
+		shapes := len(r.GetShapes())
+		for range 10 {
+			signature("Either")
+		}
+		require.Equal(t, shapes, len(r.GetShapes()))
+	}
+}
+
+func TestBaseShape_Canonicalize_Discriminator(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Pet:
+    discriminator: kind
+    properties:
+      kind: string
+`
+	r, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	s, err := r.LookupType("Pet", r.GetLocation())
+	require.NoError(t, err)
+	c, err := s.Canonicalize()
+	require.NoError(t, err)
+	require.Equal(t, "Pet", c.Shape.(*ObjectShape).DiscriminatorValue)
+	require.Nil(t, s.Shape.(*ObjectShape).DiscriminatorValue)
+}
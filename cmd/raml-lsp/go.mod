@@ -0,0 +1,17 @@
+module github.com/acronis/go-raml/cmd/raml-lsp
+
+go 1.23.0
+
+require github.com/acronis/go-raml v0.11.0
+
+require (
+	github.com/acronis/go-stacktrace v0.2.0 // indirect
+	github.com/antlr4-go/antlr/v4 v4.13.1 // indirect
+	github.com/bahlo/generic-list-go v0.2.0 // indirect
+	github.com/buger/jsonparser v1.1.1 // indirect
+	github.com/dlclark/regexp2 v1.7.0 // indirect
+	github.com/mailru/easyjson v0.7.7 // indirect
+	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
+	golang.org/x/exp v0.0.0-20240909161429-701f63a606c0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/acronis/go-raml/lsp"
+)
+
+func main() {
+	os.Exit(mainFn())
+}
+
+func mainFn() int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Stdout is used by the protocol, so logs must go to stderr.
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+
+	if err := lsp.NewServer(os.Stdin, os.Stdout).Run(ctx); err != nil {
+		slog.Error("Server failed", slog.String("error", err.Error()))
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/acronis/go-raml"
+)
+
+// Conversion targets of the convert command.
+const (
+	ConvertToJSONSchema = "jsonschema"
+	ConvertToOpenAPI3   = "openapi3"
+	ConvertToAsyncAPI2  = "asyncapi2"
+	ConvertToAsyncAPI3  = "asyncapi3"
+)
+
+var convertTargets = []string{ConvertToJSONSchema, ConvertToOpenAPI3, ConvertToAsyncAPI2, ConvertToAsyncAPI3}
+
+const (
+	openAPIVersion    = "3.1.0"
+	asyncAPI2Version  = "2.6.0"
+	asyncAPI3Version  = "3.0.0"
+	definitionsRef    = "#/definitions/"
+	componentsRef     = "#/components/schemas/"
+	defaultAPITitle   = "RAML types"
+	defaultAPIVersion = "1.0"
+)
+
+type ConvertOptions struct {
+	// To is the conversion target: jsonschema, openapi3, asyncapi2 or asyncapi3.
+	To string
+	// OutDir is the output directory. If empty, the bundled result is written to stdout.
+	OutDir string
+}
+
+type ConvertCommand struct {
+	Opts ConvertOptions
+	Path string
+
+	w io.Writer
+}
+
+func NewConvertCmd(opts ConvertOptions, path string) *ConvertCommand {
+	return &ConvertCommand{
+		Opts: opts,
+		Path: path,
+		w:    os.Stdout,
+	}
+}
+
+// namedShape is a type with the name qualified by library namespaces, e.g. "common.Parent".
+type namedShape struct {
+	name  string
+	shape *raml.BaseShape
+}
+
+// namedSchema is a converted type with its own definitions.
+type namedSchema struct {
+	name   string
+	schema *raml.JSONSchema
+}
+
+func (c ConvertCommand) Execute(ctx context.Context) error {
+	if !slices.Contains(convertTargets, c.Opts.To) {
+		return fmt.Errorf("unknown conversion target %q, expected one of %v", c.Opts.To, convertTargets)
+	}
+	slog.Info("Converting RAML...", slog.String("path", c.Path), slog.String("to", c.Opts.To))
+	rml, err := raml.ParseFromPathCtx(ctx, c.Path, raml.OptWithUnwrap(), raml.OptWithValidate())
+	if err != nil {
+		return fmt.Errorf("parse RAML: %w", err)
+	}
+	shapes := collectTypes(rml.EntryPoint())
+	schemas := make([]namedSchema, 0, len(shapes))
+	for _, ns := range shapes {
+		schema, errConv := raml.NewJSONSchemaConverter().Convert(ns.shape.Shape)
+		if errConv != nil {
+			return fmt.Errorf("convert type %s: %w", ns.name, errConv)
+		}
+		schemas = append(schemas, namedSchema{name: ns.name, schema: qualifySchema(ns, schema)})
+	}
+
+	if c.Opts.OutDir != "" {
+		if err = os.MkdirAll(c.Opts.OutDir, 0o755); err != nil {
+			return fmt.Errorf("create output directory: %w", err)
+		}
+	}
+	switch c.Opts.To {
+	case ConvertToJSONSchema:
+		if c.Opts.OutDir != "" {
+			return c.writeJSONSchemaFiles(schemas)
+		}
+		return writeIndentedJSON(c.w, bundleJSONSchema(schemas))
+	case ConvertToOpenAPI3:
+		doc := bundleOpenAPI(rml.EntryPoint(), schemas)
+		if c.Opts.OutDir != "" {
+			return writeJSONFile(filepath.Join(c.Opts.OutDir, "openapi.json"), doc)
+		}
+		return writeIndentedJSON(c.w, doc)
+	case ConvertToAsyncAPI2, ConvertToAsyncAPI3:
+		doc := bundleAsyncAPI(rml.EntryPoint(), schemas, c.Opts.To)
+		if c.Opts.OutDir != "" {
+			return writeJSONFile(filepath.Join(c.Opts.OutDir, "asyncapi.json"), doc)
+		}
+		return writeIndentedJSON(c.w, doc)
+	}
+	return nil
+}
+
+func (c ConvertCommand) writeJSONSchemaFiles(schemas []namedSchema) error {
+	for _, s := range schemas {
+		if err := writeJSONFile(filepath.Join(c.Opts.OutDir, s.name+".json"), s.schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectTypes returns types of the fragment and of all libraries it uses, sorted by qualified name.
+func collectTypes(frag raml.Fragment) []namedShape {
+	var result []namedShape
+	visited := make(map[string]struct{})
+	var walk func(lib *raml.Library, prefix string)
+	walk = func(lib *raml.Library, prefix string) {
+		// The same library may be used under different namespaces, it is bundled only once.
+		if _, ok := visited[lib.Location]; ok {
+			return
+		}
+		visited[lib.Location] = struct{}{}
+		for pair := lib.Types.Oldest(); pair != nil; pair = pair.Next() {
+			result = append(result, namedShape{name: prefix + pair.Key, shape: pair.Value})
+		}
+		for pair := lib.Uses.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value.Link != nil {
+				walk(pair.Value.Link, prefix+pair.Key+".")
+			}
+		}
+	}
+
+	switch f := frag.(type) {
+	case *raml.Library:
+		walk(f, "")
+	case *raml.DataType:
+		name := f.Shape.Name
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(f.Location), filepath.Ext(f.Location))
+		}
+		result = append(result, namedShape{name: name, shape: f.Shape})
+		for pair := f.Uses.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value.Link != nil {
+				walk(pair.Value.Link, pair.Key+".")
+			}
+		}
+	}
+	slices.SortStableFunc(result, func(a, b namedShape) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return result
+}
+
+// qualifySchema renames the entrypoint definition of the converted schema to the qualified type name.
+func qualifySchema(ns namedShape, schema *raml.JSONSchema) *raml.JSONSchema {
+	local := ns.shape.Name
+	if local == ns.name {
+		return schema
+	}
+	def, ok := schema.Definitions[local]
+	if !ok {
+		return schema
+	}
+	delete(schema.Definitions, local)
+	schema.Definitions[ns.name] = def
+	rewriteRefs(schema, func(ref string) string {
+		if ref == definitionsRef+local {
+			return definitionsRef + ns.name
+		}
+		return ref
+	}, make(map[*raml.JSONSchema]struct{}))
+	return schema
+}
+
+func bundleJSONSchema(schemas []namedSchema) *raml.JSONSchema {
+	bundle := &raml.JSONSchema{
+		Version:     raml.JSONSchemaVersion,
+		Definitions: make(raml.Definitions),
+	}
+	for _, s := range schemas {
+		for name, def := range s.schema.Definitions {
+			// NOTE: Definitions of recursive types may be shared between types, the first one is kept.
+			if _, ok := bundle.Definitions[name]; !ok {
+				bundle.Definitions[name] = def
+			}
+		}
+	}
+	return bundle
+}
+
+type openAPIDocument struct {
+	OpenAPI    string            `json:"openapi"`
+	Info       openAPIInfo       `json:"info"`
+	Paths      map[string]any    `json:"paths"`
+	Components openAPIComponents `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas raml.Definitions `json:"schemas"`
+}
+
+func bundleOpenAPI(frag raml.Fragment, schemas []namedSchema) *openAPIDocument {
+	return &openAPIDocument{
+		OpenAPI:    openAPIVersion,
+		Info:       openAPIInfo{Title: documentTitle(frag), Version: defaultAPIVersion},
+		Paths:      map[string]any{},
+		Components: openAPIComponents{Schemas: bundleComponentSchemas(schemas)},
+	}
+}
+
+// asyncAPIDocument is an AsyncAPI 2.x or 3.x document. Channels are required by AsyncAPI 2.x only.
+type asyncAPIDocument struct {
+	AsyncAPI   string            `json:"asyncapi"`
+	Info       openAPIInfo       `json:"info"`
+	Channels   any               `json:"channels,omitempty"`
+	Components openAPIComponents `json:"components"`
+}
+
+// bundleAsyncAPI bundles types into components/schemas of an AsyncAPI document, so messages of event-driven APIs can
+// reference payloads declared in RAML libraries. The default schema format of AsyncAPI is a superset of JSON Schema
+// Draft 07, so converted schemas are used as is.
+func bundleAsyncAPI(frag raml.Fragment, schemas []namedSchema, target string) *asyncAPIDocument {
+	doc := &asyncAPIDocument{
+		AsyncAPI:   asyncAPI3Version,
+		Info:       openAPIInfo{Title: documentTitle(frag), Version: defaultAPIVersion},
+		Components: openAPIComponents{Schemas: bundleComponentSchemas(schemas)},
+	}
+	if target == ConvertToAsyncAPI2 {
+		doc.AsyncAPI = asyncAPI2Version
+		doc.Channels = map[string]any{}
+	}
+	return doc
+}
+
+// bundleComponentSchemas bundles types and rewrites references to definitions to references to components/schemas,
+// which are shared by OpenAPI and AsyncAPI.
+func bundleComponentSchemas(schemas []namedSchema) raml.Definitions {
+	bundle := bundleJSONSchema(schemas)
+	visited := make(map[*raml.JSONSchema]struct{})
+	for _, def := range bundle.Definitions {
+		rewriteRefs(def, func(ref string) string {
+			return strings.Replace(ref, definitionsRef, componentsRef, 1)
+		}, visited)
+	}
+	return bundle.Definitions
+}
+
+func documentTitle(frag raml.Fragment) string {
+	if lib, ok := frag.(*raml.Library); ok && lib.Usage != "" {
+		return lib.Usage
+	}
+	return defaultAPITitle
+}
+
+// rewriteRefs replaces $ref values in the schema tree.
+func rewriteRefs(s *raml.JSONSchema, fn func(string) string, visited map[*raml.JSONSchema]struct{}) {
+	if s == nil {
+		return
+	}
+	if _, ok := visited[s]; ok {
+		return
+	}
+	visited[s] = struct{}{}
+	if s.Ref != "" {
+		s.Ref = fn(s.Ref)
+	}
+	for _, def := range s.Definitions {
+		rewriteRefs(def, fn, visited)
+	}
+	for _, items := range [][]*raml.JSONSchema{s.AllOf, s.AnyOf, s.OneOf} {
+		for _, item := range items {
+			rewriteRefs(item, fn, visited)
+		}
+	}
+	for _, item := range []*raml.JSONSchema{s.Not, s.If, s.Then, s.Else, s.Items, s.PropertyNames} {
+		rewriteRefs(item, fn, visited)
+	}
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		rewriteRefs(pair.Value, fn, visited)
+	}
+	for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		rewriteRefs(pair.Value, fn, visited)
+	}
+}
+
+func writeIndentedJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode json: %w", err)
+	}
+	return nil
+}
+
+func writeJSONFile(path string, v any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer f.Close()
+	return writeIndentedJSON(f, v)
+}
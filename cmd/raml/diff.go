@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/acronis/go-raml"
+)
+
+type DiffOptions struct {
+	// Output is the output format of changes: text or json.
+	Output string
+	// AllowBreaking makes the command succeed even if breaking changes are found.
+	AllowBreaking bool
+}
+
+type DiffCommand struct {
+	Opts    DiffOptions
+	OldPath string
+	NewPath string
+
+	w io.Writer
+}
+
+func NewDiffCmd(opts DiffOptions, oldPath, newPath string) *DiffCommand {
+	return &DiffCommand{
+		Opts:    opts,
+		OldPath: oldPath,
+		NewPath: newPath,
+		w:       os.Stdout,
+	}
+}
+
+// ChangeEntry is a machine-readable representation of raml.Change.
+type ChangeEntry struct {
+	Kind     string `json:"kind"`
+	Path     string `json:"path"`
+	Old      any    `json:"old,omitempty"`
+	New      any    `json:"new,omitempty"`
+	Breaking bool   `json:"breaking"`
+}
+
+func (d DiffCommand) Execute(ctx context.Context) error {
+	if d.Opts.Output != OutputText && d.Opts.Output != OutputJSON {
+		return fmt.Errorf("unknown output format %q, expected one of %v", d.Opts.Output,
+			[]string{OutputText, OutputJSON})
+	}
+	slog.Info("Comparing RAML...", slog.String("old", d.OldPath), slog.String("new", d.NewPath))
+	oldRAML, err := raml.ParseFromPathCtx(ctx, d.OldPath, raml.OptWithUnwrap(), raml.OptWithValidate())
+	if err != nil {
+		return fmt.Errorf("parse old RAML: %w", err)
+	}
+	newRAML, err := raml.ParseFromPathCtx(ctx, d.NewPath, raml.OptWithUnwrap(), raml.OptWithValidate())
+	if err != nil {
+		return fmt.Errorf("parse new RAML: %w", err)
+	}
+	changes := raml.Diff(oldRAML, newRAML)
+
+	switch d.Opts.Output {
+	case OutputText:
+		for _, c := range changes {
+			if _, err = fmt.Fprintln(d.w, c.String()); err != nil {
+				return fmt.Errorf("write change: %w", err)
+			}
+		}
+	case OutputJSON:
+		entries := make([]ChangeEntry, 0, len(changes))
+		for _, c := range changes {
+			entries = append(entries, ChangeEntry{
+				Kind:     string(c.Kind),
+				Path:     c.Path,
+				Old:      c.Old,
+				New:      c.New,
+				Breaking: c.Breaking,
+			})
+		}
+		if err = writeIndentedJSON(d.w, struct {
+			Changes []ChangeEntry `json:"changes"`
+		}{Changes: entries}); err != nil {
+			return err
+		}
+	}
+	if raml.HasBreakingChanges(changes) && !d.Opts.AllowBreaking {
+		return errors.New("breaking changes have been found")
+	}
+	return nil
+}
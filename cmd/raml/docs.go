@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/acronis/go-raml"
+)
+
+type DocsOptions struct {
+	// Format is the output format of the documentation: markdown or html.
+	Format string
+	// Out is the output file. If empty, the documentation is written to stdout.
+	Out string
+	// Title overrides the title of the documentation.
+	Title string
+	// SourceURL links declarations to their source lines.
+	SourceURL string
+}
+
+type DocsCommand struct {
+	Opts DocsOptions
+	Path string
+
+	w io.Writer
+}
+
+func NewDocsCmd(opts DocsOptions, path string) *DocsCommand {
+	return &DocsCommand{
+		Opts: opts,
+		Path: path,
+		w:    os.Stdout,
+	}
+}
+
+func (d DocsCommand) Execute(ctx context.Context) error {
+	format := raml.DocFormat(d.Opts.Format)
+	if format != raml.DocFormatMarkdown && format != raml.DocFormatHTML {
+		return fmt.Errorf("unknown documentation format %q, expected one of %v", d.Opts.Format,
+			[]raml.DocFormat{raml.DocFormatMarkdown, raml.DocFormatHTML})
+	}
+	slog.Info("Generating documentation...", slog.String("path", d.Path), slog.String("format", d.Opts.Format))
+	// The model is not unwrapped to keep references of type expressions, the generator resolves facets itself.
+	rml, err := raml.ParseFromPathCtx(ctx, d.Path, raml.OptWithValidate())
+	if err != nil {
+		return fmt.Errorf("parse RAML: %w", err)
+	}
+	var opts []raml.DocGeneratorOpt
+	if d.Opts.Title != "" {
+		opts = append(opts, raml.WithDocTitle(d.Opts.Title))
+	}
+	if d.Opts.SourceURL != "" {
+		opts = append(opts, raml.WithDocSourceURL(d.Opts.SourceURL))
+	}
+	doc, err := raml.NewDocGenerator(opts...).Generate(rml)
+	if err != nil {
+		return fmt.Errorf("generate documentation: %w", err)
+	}
+	if d.Opts.Out == "" {
+		return doc.Write(d.w, format)
+	}
+	f, err := os.Create(d.Opts.Out)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer f.Close()
+	return doc.Write(f, format)
+}
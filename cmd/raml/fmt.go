@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/acronis/go-raml"
+)
+
+type FmtOptions struct {
+	// Write rewrites the files instead of printing the formatted content to stdout.
+	Write bool
+	// List prints names of the files whose formatting differs instead of their formatted content.
+	List bool
+}
+
+type FmtCommand struct {
+	Opts FmtOptions
+	Args []string
+
+	w io.Writer
+}
+
+func NewFmtCmd(opts FmtOptions, args []string) *FmtCommand {
+	return &FmtCommand{
+		Opts: opts,
+		Args: args,
+		w:    os.Stdout,
+	}
+}
+
+func (f FmtCommand) Execute(ctx context.Context) error {
+	for _, path := range f.Args {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read file: %w", err)
+		}
+		formatted, err := raml.Format(data)
+		if err != nil {
+			return fmt.Errorf("format %s: %w", path, err)
+		}
+		changed := !bytes.Equal(data, formatted)
+		if f.Opts.List && changed {
+			fmt.Fprintln(f.w, path)
+		}
+		if f.Opts.Write && changed {
+			// The mode is kept because the file exists.
+			if err = os.WriteFile(path, formatted, 0o644); err != nil {
+				return fmt.Errorf("write file: %w", err)
+			}
+		}
+		if !f.Opts.List && !f.Opts.Write {
+			if _, err = f.w.Write(formatted); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+		}
+	}
+	return nil
+}
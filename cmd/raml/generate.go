@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/acronis/go-raml"
+)
+
+type GenerateOptions struct {
+	// Template is the directory with templates.
+	Template string
+	// OutDir is the output directory of rendered files.
+	OutDir string
+	// Params are passed to templates as .Params.
+	Params map[string]string
+}
+
+type GenerateCommand struct {
+	Opts GenerateOptions
+	Path string
+}
+
+func NewGenerateCmd(opts GenerateOptions, path string) *GenerateCommand {
+	return &GenerateCommand{
+		Opts: opts,
+		Path: path,
+	}
+}
+
+func (c GenerateCommand) Execute(ctx context.Context) error {
+	slog.Info("Generating from templates...", slog.String("path", c.Path), slog.String("template", c.Opts.Template))
+	// The model is not unwrapped to keep references to declared types, templates resolve facets with helpers.
+	rml, err := raml.ParseFromPathCtx(ctx, c.Path, raml.OptWithValidate())
+	if err != nil {
+		return fmt.Errorf("parse RAML: %w", err)
+	}
+	files, err := raml.NewTemplateGenerator(raml.WithTemplateParams(c.Opts.Params)).
+		Generate(rml, os.DirFS(c.Opts.Template))
+	if err != nil {
+		return fmt.Errorf("generate: %w", err)
+	}
+	for _, f := range files {
+		path := filepath.Join(c.Opts.OutDir, filepath.FromSlash(f.Path))
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("create output directory: %w", err)
+		}
+		if err = os.WriteFile(path, f.Content, 0o644); err != nil {
+			return fmt.Errorf("write file: %w", err)
+		}
+		slog.Info("Generated", slog.String("file", path))
+	}
+	return nil
+}
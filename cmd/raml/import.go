@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/acronis/go-stacktrace"
+
+	"github.com/acronis/go-raml"
+)
+
+type ImportOptions struct {
+	// Out is the output file of the RAML definition. If empty, the definition is written to stdout.
+	Out string
+}
+
+type ImportCommand struct {
+	Opts ImportOptions
+	Path string
+
+	w io.Writer
+}
+
+func NewImportCmd(opts ImportOptions, path string) *ImportCommand {
+	return &ImportCommand{
+		Opts: opts,
+		Path: path,
+		w:    os.Stdout,
+	}
+}
+
+func (c ImportCommand) Execute(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	slog.Info("Importing OpenAPI document...", slog.String("path", c.Path))
+	path, err := filepath.Abs(c.Path)
+	if err != nil {
+		return fmt.Errorf("absolute path: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+	converted, warnings, err := raml.ConvertOpenAPI(data, path)
+	if err != nil {
+		return fmt.Errorf("convert OpenAPI document: %w", err)
+	}
+	for _, w := range warnings {
+		slog.Warn("Not imported", stacktrace.ErrToSlogAttr(w))
+	}
+	// The converted definition is parsed to report issues before it is written.
+	if _, err = raml.ParseFromStringCtx(ctx, string(converted), filepath.Base(path), filepath.Dir(path),
+		raml.OptWithValidate()); err != nil {
+		return fmt.Errorf("parse converted RAML: %w", err)
+	}
+	if c.Opts.Out == "" {
+		_, err = c.w.Write(converted)
+		return err
+	}
+	if err = os.WriteFile(c.Opts.Out, converted, 0o644); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/acronis/go-raml"
+	"github.com/acronis/go-stacktrace"
+)
+
+type LintOptions struct {
+	// Config is the path to the YAML configuration of lint rules, see raml.ParseLintConfig.
+	Config string
+	// Output is the output format of diagnostics: log, text, json, sarif or junit.
+	Output string
+}
+
+type LintCommand struct {
+	Opts LintOptions
+	Args []string
+
+	w io.Writer
+}
+
+func NewLintCmd(opts LintOptions, args []string) *LintCommand {
+	return &LintCommand{
+		Opts: opts,
+		Args: args,
+		w:    os.Stdout,
+	}
+}
+
+func (l LintCommand) Execute(ctx context.Context) error {
+	if err := checkOutputFormat(l.Opts.Output); err != nil {
+		return err
+	}
+	var linterOpts []raml.LinterOpt
+	if l.Opts.Config != "" {
+		data, err := os.ReadFile(l.Opts.Config)
+		if err != nil {
+			return fmt.Errorf("read config: %w", err)
+		}
+		cfg, err := raml.ParseLintConfig(data)
+		if err != nil {
+			return err
+		}
+		linterOpts = append(linterOpts, raml.WithLintConfig(cfg))
+	}
+	linter, err := raml.NewLinter(linterOpts...)
+	if err != nil {
+		return fmt.Errorf("create linter: %w", err)
+	}
+
+	reports := make([]FileReport, 0, len(l.Args))
+	clean := true
+	for _, arg := range l.Args {
+		slog.Info("Linting RAML...", slog.String("path", arg))
+		// References must be kept by rules, so the model is not unwrapped.
+		rml, err := raml.ParseFromPathCtx(ctx, arg)
+		var findings []raml.Diagnostic
+		if rml != nil {
+			findings = linter.Lint(rml)
+		}
+		if l.Opts.Output == OutputLog {
+			for _, f := range findings {
+				level := slog.LevelWarn
+				if f.Severity != stacktrace.SeverityWarning {
+					level = slog.LevelError
+				}
+				slog.Log(ctx, level, f.Message, slog.String("rule", string(f.Type)),
+					slog.String("location", f.Location), slog.Int("line", f.Line))
+			}
+			if err != nil {
+				slog.Error("RAML is invalid", stacktrace.ErrToSlogAttr(err))
+			}
+		}
+		if err != nil || len(findings) > 0 {
+			clean = false
+		}
+		reports = append(reports, newFileReport(arg, err, findings))
+	}
+
+	switch l.Opts.Output {
+	case OutputText:
+		err = writeText(l.w, reports)
+	case OutputJSON:
+		err = writeJSON(l.w, reports)
+	case OutputSARIF:
+		err = writeSARIF(l.w, reports)
+	case OutputJUnit:
+		err = writeJUnit(l.w, reports)
+	}
+	if err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	if !clean {
+		return fmt.Errorf("lint issues have been found in the RAML files")
+	}
+	return nil
+}
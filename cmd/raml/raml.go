@@ -10,6 +10,8 @@ import (
 
 	"github.com/acronis/go-stacktrace"
 	"github.com/spf13/cobra"
+
+	"github.com/acronis/go-raml"
 )
 
 type CommandError struct {
@@ -52,21 +54,164 @@ func main() {
 func mainFn() int {
 	var ensureDuplicates bool
 	verbosity := 0
+	output := OutputLog
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer stop()
 
 	cmdValidate := func() *cobra.Command {
-		opts := ValidateOptions{
-			EnsureDuplicates: ensureDuplicates,
-		}
 		cmd := &cobra.Command{
 			Use:   "validate",
 			Short: "validate raml files",
 			Args:  cobra.MinimumNArgs(1),
 			RunE: func(_ *cobra.Command, args []string) error {
+				opts := ValidateOptions{
+					EnsureDuplicates: ensureDuplicates,
+					Output:           output,
+				}
 				return InitLoggingAndRun(ctx, verbosity, NewValidateCmd(opts, args))
 			},
 		}
+		cmd.Flags().StringVarP(&output, "output", "o", OutputLog,
+			"output format of diagnostics: log, text, json, sarif or junit")
+
+		return cmd
+	}()
+
+	cmdConvert := func() *cobra.Command {
+		var opts ConvertOptions
+		cmd := &cobra.Command{
+			Use:   "convert",
+			Short: "convert raml types to JSON Schema, OpenAPI 3 or AsyncAPI",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewConvertCmd(opts, args[0]))
+			},
+		}
+		cmd.Flags().StringVarP(&opts.To, "to", "t", ConvertToJSONSchema,
+			"conversion target: jsonschema, openapi3, asyncapi2 or asyncapi3")
+		cmd.Flags().StringVar(&opts.OutDir, "out-dir", "",
+			"output directory, the bundled result is written to stdout if empty")
+
+		return cmd
+	}()
+
+	cmdDiff := func() *cobra.Command {
+		opts := DiffOptions{}
+		cmd := &cobra.Command{
+			Use:   "diff <old> <new>",
+			Short: "compare types of two raml files and detect breaking changes",
+			Args:  cobra.ExactArgs(2),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewDiffCmd(opts, args[0], args[1]))
+			},
+		}
+		cmd.Flags().StringVarP(&opts.Output, "output", "o", OutputText, "output format of changes: text or json")
+		cmd.Flags().BoolVar(&opts.AllowBreaking, "allow-breaking", false,
+			"do not fail if breaking changes are found")
+
+		return cmd
+	}()
+
+	cmdDocs := func() *cobra.Command {
+		opts := DocsOptions{}
+		cmd := &cobra.Command{
+			Use:   "docs",
+			Short: "generate documentation of raml types in markdown or html",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewDocsCmd(opts, args[0]))
+			},
+		}
+		cmd.Flags().StringVarP(&opts.Format, "format", "f", string(raml.DocFormatMarkdown),
+			"documentation format: markdown or html")
+		cmd.Flags().StringVar(&opts.Out, "out", "", "output file, the documentation is written to stdout if empty")
+		cmd.Flags().StringVar(&opts.Title, "title", "", "title of the documentation")
+		cmd.Flags().StringVar(&opts.SourceURL, "source-url", "",
+			"base URL to link declarations to their source lines, e.g. a repository browser URL")
+
+		return cmd
+	}()
+
+	cmdLint := func() *cobra.Command {
+		opts := LintOptions{}
+		cmd := &cobra.Command{
+			Use:   "lint",
+			Short: "check raml files with lint rules",
+			Args:  cobra.MinimumNArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewLintCmd(opts, args))
+			},
+		}
+		cmd.Flags().StringVarP(&opts.Output, "output", "o", OutputLog,
+			"output format of diagnostics: log, text, json, sarif or junit")
+		cmd.Flags().StringVarP(&opts.Config, "config", "c", "", "path to the YAML configuration of lint rules")
+
+		return cmd
+	}()
+
+	cmdFmt := func() *cobra.Command {
+		opts := FmtOptions{}
+		cmd := &cobra.Command{
+			Use:   "fmt",
+			Short: "format raml files with canonical indentation, key order and type expressions",
+			Args:  cobra.MinimumNArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewFmtCmd(opts, args))
+			},
+		}
+		cmd.Flags().BoolVarP(&opts.Write, "write", "w", false, "write the result to the files instead of stdout")
+		cmd.Flags().BoolVarP(&opts.List, "list", "l", false, "list files whose formatting differs")
+
+		return cmd
+	}()
+
+	cmdImport := func() *cobra.Command {
+		opts := ImportOptions{}
+		cmd := &cobra.Command{
+			Use:   "import",
+			Short: "convert a Swagger 2.0 or OpenAPI 3.0 document into a raml api definition",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewImportCmd(opts, args[0]))
+			},
+		}
+		cmd.Flags().StringVar(&opts.Out, "out", "", "output file, the definition is written to stdout if empty")
+
+		return cmd
+	}()
+
+	cmdGenerate := func() *cobra.Command {
+		opts := GenerateOptions{}
+		cmd := &cobra.Command{
+			Use:   "generate",
+			Short: "render go templates with declared types of a raml file",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewGenerateCmd(opts, args[0]))
+			},
+		}
+		cmd.Flags().StringVar(&opts.Template, "template", "", "directory with templates (*.tmpl)")
+		cmd.Flags().StringVar(&opts.OutDir, "out-dir", ".", "output directory of rendered files")
+		cmd.Flags().StringToStringVar(&opts.Params, "param", nil,
+			"parameters passed to templates as .Params, e.g. --param package=models")
+		_ = cmd.MarkFlagRequired("template")
+
+		return cmd
+	}()
+
+	cmdTCK := func() *cobra.Command {
+		opts := TCKOptions{}
+		cmd := &cobra.Command{
+			Use:   "tck <dir>",
+			Short: "run the raml test compatibility kit against the parser",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				return InitLoggingAndRun(ctx, verbosity, NewTCKCmd(opts, args[0]))
+			},
+		}
+		cmd.Flags().StringVarP(&opts.Output, "output", "o", OutputText, "output format of results: text or json")
+		cmd.Flags().StringVar(&opts.Run, "run", "", "run only tests whose names match the regular expression")
+		cmd.Flags().BoolVar(&opts.Strict, "strict", false, "fail if any test fails")
 
 		return cmd
 	}()
@@ -88,6 +233,14 @@ func mainFn() int {
 
 		cmd.AddCommand(
 			cmdValidate,
+			cmdConvert,
+			cmdDiff,
+			cmdDocs,
+			cmdLint,
+			cmdFmt,
+			cmdImport,
+			cmdGenerate,
+			cmdTCK,
 		)
 		return cmd
 	}()
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/acronis/go-raml"
+)
+
+// Output formats of the validate command.
+const (
+	OutputLog   = "log"
+	OutputText  = "text"
+	OutputJSON  = "json"
+	OutputSARIF = "sarif"
+	OutputJUnit = "junit"
+)
+
+var outputFormats = []string{OutputLog, OutputText, OutputJSON, OutputSARIF, OutputJUnit}
+
+func checkOutputFormat(format string) error {
+	if !slices.Contains(outputFormats, format) {
+		return fmt.Errorf("unknown output format %q, expected one of %v", format, outputFormats)
+	}
+	return nil
+}
+
+// FileReport contains diagnostics produced for a single validated file.
+type FileReport struct {
+	Path        string            `json:"path"`
+	Valid       bool              `json:"valid"`
+	Diagnostics []DiagnosticEntry `json:"diagnostics"`
+
+	diags []raml.Diagnostic
+}
+
+// DiagnosticEntry is a machine-readable representation of raml.Diagnostic.
+type DiagnosticEntry struct {
+	Severity string   `json:"severity"`
+	Type     string   `json:"type"`
+	Message  string   `json:"message"`
+	Location string   `json:"location,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Trace    []string `json:"trace,omitempty"`
+}
+
+// newFileReport creates the report from the parsing error and warnings of the parsed model.
+func newFileReport(path string, err error, warnings []raml.Diagnostic) FileReport {
+	diags := append(raml.DiagnosticsFromError(err), warnings...)
+	report := FileReport{
+		Path:        path,
+		Valid:       err == nil,
+		Diagnostics: make([]DiagnosticEntry, 0, len(diags)),
+		diags:       diags,
+	}
+	for _, d := range diags {
+		report.Diagnostics = append(report.Diagnostics, DiagnosticEntry{
+			Severity: string(d.Severity),
+			Type:     string(d.Type),
+			Message:  d.Message,
+			Location: d.Location,
+			Line:     d.Line,
+			Column:   d.Column,
+			Trace:    d.Trace,
+		})
+	}
+	return report
+}
+
+func writeText(w io.Writer, reports []FileReport) error {
+	for _, report := range reports {
+		for _, d := range report.diags {
+			if _, err := fmt.Fprintln(w, d.String()); err != nil {
+				return fmt.Errorf("write diagnostic: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, reports []FileReport) error {
+	return writeIndentedJSON(w, struct {
+		Files []FileReport `json:"files"`
+	}{Files: reports})
+}
+
+func diagnosticReports(reports []FileReport) []raml.DiagnosticReport {
+	result := make([]raml.DiagnosticReport, 0, len(reports))
+	for _, report := range reports {
+		result = append(result, raml.DiagnosticReport{Path: report.Path, Diagnostics: report.diags})
+	}
+	return result
+}
+
+func writeSARIF(w io.Writer, reports []FileReport) error {
+	return raml.WriteSARIF(w, diagnosticReports(reports))
+}
+
+func writeJUnit(w io.Writer, reports []FileReport) error {
+	return raml.WriteJUnit(w, diagnosticReports(reports))
+}
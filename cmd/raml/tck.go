@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+
+	"github.com/acronis/go-raml/tck"
+)
+
+type TCKOptions struct {
+	// Output is the output format of results: text or json.
+	Output string
+	// Run is the regular expression of names of tests to run.
+	Run string
+	// Strict makes the command fail if any test fails.
+	Strict bool
+}
+
+type TCKCommand struct {
+	Opts TCKOptions
+	Path string
+
+	w io.Writer
+}
+
+func NewTCKCmd(opts TCKOptions, path string) *TCKCommand {
+	return &TCKCommand{
+		Opts: opts,
+		Path: path,
+		w:    os.Stdout,
+	}
+}
+
+func (c TCKCommand) Execute(ctx context.Context) error {
+	if c.Opts.Output != OutputText && c.Opts.Output != OutputJSON {
+		return fmt.Errorf("unknown output format %q, expected one of %v", c.Opts.Output,
+			[]string{OutputText, OutputJSON})
+	}
+	var opts []tck.Opt
+	if c.Opts.Run != "" {
+		filter, err := regexp.Compile(c.Opts.Run)
+		if err != nil {
+			return fmt.Errorf("compile run filter: %w", err)
+		}
+		opts = append(opts, tck.WithFilter(filter))
+	}
+	slog.Info("Running RAML TCK...", slog.String("path", c.Path))
+	report, err := tck.Run(ctx, c.Path, opts...)
+	if err != nil {
+		return fmt.Errorf("run tck: %w", err)
+	}
+
+	switch c.Opts.Output {
+	case OutputText:
+		if err = writeTCKText(c.w, report); err != nil {
+			return err
+		}
+	case OutputJSON:
+		if err = writeIndentedJSON(c.w, report); err != nil {
+			return err
+		}
+	}
+	if c.Opts.Strict && report.Failed > 0 {
+		return errors.New("tck tests have failed")
+	}
+	return nil
+}
+
+func writeTCKText(w io.Writer, report *tck.Report) error {
+	for _, res := range report.Results {
+		status := "PASS"
+		if !res.Passed {
+			status = "FAIL"
+		}
+		line := fmt.Sprintf("%s %s (expected %s)", status, res.Name, res.Expect)
+		if !res.Passed && res.Error != "" {
+			line += ": " + res.Error
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("write result: %w", err)
+		}
+	}
+	for _, g := range report.Groups {
+		if _, err := fmt.Fprintf(w, "%s: %d/%d passed\n", g.Name, g.Passed, g.Total); err != nil {
+			return fmt.Errorf("write summary: %w", err)
+		}
+	}
+	if _, err := fmt.Fprintf(w, "total: %d/%d passed\n", report.Passed, report.Total); err != nil {
+		return fmt.Errorf("write summary: %w", err)
+	}
+	return nil
+}
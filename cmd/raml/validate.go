@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
 
 	"github.com/acronis/go-raml"
 	"github.com/acronis/go-stacktrace"
@@ -11,36 +13,73 @@ import (
 
 type ValidateOptions struct {
 	EnsureDuplicates bool
+	// Output is the output format of diagnostics: log, text, json, sarif or junit.
+	Output string
 }
 
 type ValidateCommand struct {
 	Opts ValidateOptions
 	Args []string
+
+	w io.Writer
 }
 
 func NewValidateCmd(opts ValidateOptions, args []string) *ValidateCommand {
 	return &ValidateCommand{
 		Opts: opts,
 		Args: args,
+		w:    os.Stdout,
 	}
 }
 
 func (v ValidateCommand) Execute(ctx context.Context) error {
-	var err error
+	if err := checkOutputFormat(v.Opts.Output); err != nil {
+		return err
+	}
 	var stOpts []stacktrace.TracesOpt
 	if v.Opts.EnsureDuplicates {
 		stOpts = append(stOpts, stacktrace.WithEnsureDuplicates())
 	}
+	reports := make([]FileReport, 0, len(v.Args))
+	valid := true
 	for _, arg := range v.Args {
 		slog.Info("Validating RAML...", slog.String("path", arg))
-		_, err = raml.ParseFromPathCtx(ctx, arg, raml.OptWithUnwrap(), raml.OptWithValidate())
+		rml, err := raml.ParseFromPathCtx(ctx, arg, raml.OptWithUnwrap(), raml.OptWithValidate())
+		var warnings []raml.Diagnostic
+		if rml != nil {
+			warnings = rml.Diagnostics()
+		}
+		if v.Opts.Output == OutputLog {
+			for _, w := range warnings {
+				slog.Warn(w.Message, slog.String("location", w.Location), slog.Int("line", w.Line))
+			}
+		}
 		if err != nil {
-			slog.Error("RAML is invalid", stacktrace.ErrToSlogAttr(err, stOpts...))
+			valid = false
+			if v.Opts.Output == OutputLog {
+				slog.Error("RAML is invalid", stacktrace.ErrToSlogAttr(err, stOpts...))
+			}
 		} else {
 			slog.Info("RAML is valid", slog.String("path", arg))
 		}
+		reports = append(reports, newFileReport(arg, err, warnings))
+	}
+
+	var err error
+	switch v.Opts.Output {
+	case OutputText:
+		err = writeText(v.w, reports)
+	case OutputJSON:
+		err = writeJSON(v.w, reports)
+	case OutputSARIF:
+		err = writeSARIF(v.w, reports)
+	case OutputJUnit:
+		err = writeJUnit(v.w, reports)
 	}
 	if err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	if !valid {
 		return fmt.Errorf("errors have been found in the RAML files")
 	}
 	return nil
@@ -0,0 +1,100 @@
+package raml
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// NameCollision is a canonical name shared by different types declared in the entry point and the libraries it uses,
+// e.g. "Pet" of "billing.Pet" and "inventory.Pet". Generators that drop namespaces, e.g. code generators or bundlers,
+// would declare such types twice.
+type NameCollision struct {
+	// Name is the canonical name of the types, the PascalCase identifier of their names without namespaces.
+	Name string
+	// Types are the colliding types from the type graph sorted by their qualified names. Each type is declared once,
+	// types used through several aliases are not collisions.
+	Types []*TypeGraphNode
+}
+
+// NameCollisions returns canonical names shared by different declared types, sorted by name. Parse without
+// OptWithUnwrap to keep the declarations of all fragments.
+func (r *RAML) NameCollisions() []NameCollision {
+	return nameCollisions(r.TypeGraph())
+}
+
+func nameCollisions(graph *TypeGraph) []NameCollision {
+	byName := make(map[string][]*TypeGraphNode)
+	for _, n := range graph.Nodes {
+		name := canonicalTypeName(n.Name)
+		byName[name] = append(byName[name], n)
+	}
+	var result []NameCollision
+	for name, nodes := range byName {
+		if len(nodes) > 1 {
+			result = append(result, NameCollision{Name: name, Types: nodes})
+		}
+	}
+	slices.SortFunc(result, func(a, b NameCollision) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
+// canonicalTypeName returns the name of the qualified type name in generated artifacts.
+func canonicalTypeName(qualified string) string {
+	return pascalIdentifier(qualified[strings.LastIndex(qualified, ".")+1:])
+}
+
+// CollisionFreeNames returns unique names for declared types by their qualified names in the type graph, e.g.
+// "common.Pet". Types keep their names unless their canonical names collide. The first colliding type of the entry
+// point keeps its name and the others are qualified with their namespaces joined by the separator, e.g. "common_Pet";
+// a number is appended if the name is taken too. The result only depends on the declarations, so generated names
+// are stable across runs.
+func (r *RAML) CollisionFreeNames(separator string) map[string]string {
+	graph := r.TypeGraph()
+	// Colliding types are renamed except the first colliding type of the entry point.
+	renamedSet := make(map[string]struct{})
+	for _, c := range nameCollisions(graph) {
+		kept := false
+		for _, n := range c.Types {
+			if namespace, _ := splitQualifiedName(n.Name); len(namespace) == 0 && !kept {
+				kept = true
+				continue
+			}
+			renamedSet[n.Name] = struct{}{}
+		}
+	}
+	result := make(map[string]string, len(graph.Nodes))
+	taken := make(map[string]struct{}, len(graph.Nodes))
+	var renamed []string
+	// Names that are kept are reserved first, so renamed types cannot take them.
+	for _, n := range graph.Nodes {
+		if _, ok := renamedSet[n.Name]; ok {
+			renamed = append(renamed, n.Name)
+			continue
+		}
+		_, name := splitQualifiedName(n.Name)
+		result[n.Name] = name
+		taken[canonicalTypeName(name)] = struct{}{}
+	}
+	for _, qualified := range renamed {
+		namespace, name := splitQualifiedName(qualified)
+		base := strings.Join(append(namespace, name), separator)
+		unique := base
+		for i := 2; ; i++ {
+			if _, ok := taken[canonicalTypeName(unique)]; !ok {
+				break
+			}
+			unique = fmt.Sprintf("%s%d", base, i)
+		}
+		result[qualified] = unique
+		taken[canonicalTypeName(unique)] = struct{}{}
+	}
+	return result
+}
+
+func splitQualifiedName(qualified string) ([]string, string) {
+	parts := strings.Split(qualified, ".")
+	return parts[:len(parts)-1], parts[len(parts)-1]
+}
@@ -0,0 +1,98 @@
+package raml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAML_NameCollisions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"billing.raml": `#%RAML 1.0 Library
+uses:
+  shared: shared.raml
+types:
+  Pet:
+    properties:
+      price: number
+  Invoice:
+    properties:
+      pet: Pet
+      id: shared.Id
+`,
+		"inventory.raml": `#%RAML 1.0 Library
+uses:
+  shared: shared.raml
+types:
+  pet:
+    properties:
+      count: integer
+  InventoryPet: string
+`,
+		"shared.raml": `#%RAML 1.0 Library
+types:
+  Id: string
+`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	content := `#%RAML 1.0 Library
+uses:
+  billing: billing.raml
+  inventory: inventory.raml
+types:
+  Pet:
+    properties:
+      name: string
+  Order:
+    properties:
+      invoice: billing.Invoice
+      pet: inventory.pet
+`
+	rml, err := ParseFromString(content, "library.raml", dir)
+	require.NoError(t, err)
+
+	collisions := rml.NameCollisions()
+	require.Len(t, collisions, 1)
+	require.Equal(t, "Pet", collisions[0].Name)
+	var names []string
+	for _, n := range collisions[0].Types {
+		names = append(names, n.Name)
+	}
+	// shared.Id is used through two aliases but declared once.
+	require.Equal(t, []string{"Pet", "billing.Pet", "inventory.pet"}, names)
+	require.Equal(t, 6, collisions[0].Types[2].Shape.Line)
+
+	require.Equal(t, map[string]string{
+		"Order":                  "Order",
+		"Pet":                    "Pet",
+		"billing.Invoice":        "Invoice",
+		"billing.Pet":            "billing_Pet",
+		"billing.shared.Id":      "Id",
+		"inventory.InventoryPet": "InventoryPet",
+		"inventory.pet":          "inventory_pet2",
+	}, rml.CollisionFreeNames("_"))
+
+	out, err := rml.Flatten(OptFlattenWithCollisionRenaming("_"))
+	require.NoError(t, err)
+	require.Contains(t, string(out), "  billing_Pet:\n")
+	require.Contains(t, string(out), "      invoice: Invoice\n")
+	require.Contains(t, string(out), "      pet: inventory_pet2\n")
+
+	linter, err := NewLinter()
+	require.NoError(t, err)
+	var messages []string
+	for _, d := range linter.Lint(rml) {
+		if d.Type == LintRuleNameCollision {
+			messages = append(messages, d.Message)
+		}
+	}
+	require.Equal(t, []string{
+		`type "billing.Pet" collides with "Pet" as "Pet"`,
+		`type "inventory.pet" collides with "Pet" as "Pet"`,
+	}, messages)
+}
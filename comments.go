@@ -0,0 +1,51 @@
+package raml
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Comments contains YAML comments adjacent to a declaration. Comment markers are stripped and lines of
+// multi-line comments are separated by "\n".
+type Comments struct {
+	// Head is the comment on the lines above the declaration.
+	Head string
+	// Line is the comment at the end of the line of the declaration.
+	Line string
+	// Foot is the comment on the lines below the declaration, separated from the next declaration by an empty line.
+	Foot string
+}
+
+// makeComments collects comments of the key and the value of a declaration. The parser attaches comments
+// to the key node except for line comments of scalar values.
+func makeComments(key *yaml.Node, value *yaml.Node) *Comments {
+	c := Comments{
+		Head: stripComment(key.HeadComment),
+		Line: stripComment(key.LineComment),
+		Foot: stripComment(key.FootComment),
+	}
+	if c.Line == "" {
+		c.Line = stripComment(value.LineComment)
+	}
+	if c.Foot == "" {
+		c.Foot = stripComment(value.FootComment)
+	}
+	if c == (Comments{}) {
+		return nil
+	}
+	return &c
+}
+
+// stripComment removes "#" markers and a single following space from every line of the comment.
+func stripComment(comment string) string {
+	if comment == "" {
+		return ""
+	}
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "#")
+		lines[i] = strings.TrimPrefix(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,64 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComments(t *testing.T) {
+	content := `#%RAML 1.0 Library
+annotationTypes:
+  # Marks internal types.
+  internal: boolean
+types:
+  # Pet is an animal.
+  # It has a name.
+  Pet: # inline
+    type: object
+    facets:
+      # Unit of weight.
+      unit?: string
+    properties:
+      # Name of the pet.
+      name: string # shown to users
+      /^x-/: string # extensions
+      owner: Owner
+      age:
+        type: integer
+      # Age in years.
+
+  Owner:
+    type: object
+`
+	for _, unwrap := range []bool{false, true} {
+		var opts []ParseOpt
+		if unwrap {
+			opts = append(opts, OptWithUnwrap())
+		}
+		rml, err := ParseFromString(content, "library.raml", t.TempDir(), opts...)
+		require.NoError(t, err)
+		lib := rml.EntryPoint().(*Library)
+
+		pet, _ := lib.Types.Get("Pet")
+		require.Equal(t, &Comments{Head: "Pet is an animal.\nIt has a name.", Line: "inline"}, pet.Comments)
+		internal, _ := lib.AnnotationTypes.Get("internal")
+		require.Equal(t, &Comments{Head: "Marks internal types."}, internal.Comments)
+		owner, _ := lib.Types.Get("Owner")
+		require.Nil(t, owner.Comments)
+
+		unit, _ := pet.CustomShapeFacetDefinitions.Get("unit")
+		require.Equal(t, "Unit of weight.", unit.Comments.Head)
+
+		obj := pet.Shape.(*ObjectShape)
+		name, _ := obj.Properties.Get("name")
+		require.Equal(t, &Comments{Head: "Name of the pet.", Line: "shown to users"}, name.Comments)
+		require.Same(t, name.Comments, name.Shape.Comments)
+		age, _ := obj.Properties.Get("age")
+		require.Equal(t, "Age in years.", age.Comments.Foot)
+		ownerProp, _ := obj.Properties.Get("owner")
+		require.Nil(t, ownerProp.Comments)
+		ext := obj.PatternProperties.Oldest().Value
+		require.Equal(t, "extensions", ext.Shape.Comments.Line)
+	}
+}
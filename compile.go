@@ -0,0 +1,271 @@
+package raml
+
+import (
+	"fmt"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// CompiledValidator validates values against the shape it was compiled for, see BaseShape.Compile.
+type CompiledValidator struct {
+	validate compiledFunc
+}
+
+// compiledFunc validates the value at the path, depth is the number of recursive shapes entered.
+type compiledFunc func(v interface{}, path *valuePath, depth int) error
+
+// Validate validates the value like BaseShape.Validate does.
+func (c *CompiledValidator) Validate(v interface{}) error {
+	path := newValuePath("$")
+	defer path.release()
+	return c.validate(v, path, 0)
+}
+
+// Compile compiles the shape into a validator for hot paths. The tree of shapes is turned into closures once, so
+// validation does not dispatch on shape kinds, looks up declared properties by name in the instance instead of
+// looking up every instance key in the declarations and dispatches unions of discriminated object types by the
+// discriminator value. The result is the same as of Validate with default options. The shape should be unwrapped,
+// later changes of the shape are not reflected by the validator.
+func (s *BaseShape) Compile() (*CompiledValidator, error) {
+	if s.Shape == nil {
+		return nil, fmt.Errorf("shape %s is not resolved", s.Name)
+	}
+	c := &shapeCompiler{compiled: make(map[*BaseShape]*compiledFunc)}
+	return &CompiledValidator{validate: c.compile(s.Shape)}, nil
+}
+
+type shapeCompiler struct {
+	// compiled maps shapes to their validators. A validator is nil while the shape is being compiled, so recursive
+	// shapes refer to validators of their heads by pointers.
+	compiled map[*BaseShape]*compiledFunc
+}
+
+// compile returns the validator of the shape that reports null like validateValue does.
+func (c *shapeCompiler) compile(s Shape) compiledFunc {
+	base := s.Base()
+	if ref, ok := c.compiled[base]; ok {
+		if *ref != nil {
+			return *ref
+		}
+		// The shape refers to itself, the validator is looked up when it is called.
+		return func(v interface{}, path *valuePath, depth int) error {
+			return (*ref)(v, path, depth)
+		}
+	}
+	ref := new(compiledFunc)
+	c.compiled[base] = ref
+	var f compiledFunc
+	switch shape := s.(type) {
+	case *ObjectShape:
+		f = c.compileObject(shape)
+	case *ArrayShape:
+		f = c.compileArray(shape)
+	case *UnionShape:
+		f = c.compileUnion(shape)
+	case *RecursiveShape:
+		f = c.compileRecursive(shape)
+	default:
+		validate := s.validate
+		f = func(v interface{}, _ *valuePath, _ int) error {
+			return validate(v, "")
+		}
+	}
+	typ := base.Type
+	nullable := func(v interface{}, path *valuePath, depth int) error {
+		err := f(v, path, depth)
+		if err != nil && v == nil {
+			return fmt.Errorf("null is not allowed, expected %s", typ)
+		}
+		return err
+	}
+	*ref = nullable
+	return nullable
+}
+
+// compiledProperty is a declared property of a compiled object.
+type compiledProperty struct {
+	name     string
+	required bool
+	validate compiledFunc
+}
+
+// compiledPatternProperty is a pattern property of a compiled object.
+type compiledPatternProperty struct {
+	pattern  Regexp
+	validate compiledFunc
+}
+
+func (c *shapeCompiler) compileObject(s *ObjectShape) compiledFunc {
+	// Subtypes are selected by the discriminator value at validation time.
+	if s.Discriminator != nil {
+		return func(v interface{}, path *valuePath, depth int) error {
+			return validateValue(s, v, &validationState{path: path, depth: depth})
+		}
+	}
+	properties := make([]compiledProperty, 0, s.Properties.Len())
+	declared := make(map[string]struct{}, s.Properties.Len())
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		properties = append(properties, compiledProperty{
+			name: pair.Key, required: pair.Value.Required, validate: c.compile(pair.Value.Shape.Shape),
+		})
+		declared[pair.Key] = struct{}{}
+	}
+	patterns := make([]compiledPatternProperty, 0, s.PatternProperties.Len())
+	for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		patterns = append(patterns, compiledPatternProperty{
+			pattern: pair.Value.Pattern, validate: c.compile(pair.Value.Shape.Shape),
+		})
+	}
+	restricted := s.AdditionalProperties != nil && !*s.AdditionalProperties
+	checkUnknown := restricted || len(patterns) > 0
+
+	return func(v interface{}, path *valuePath, depth int) error {
+		props, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid type, got %T, expected map[string]interface{}", v)
+		}
+		found := 0
+		var missing []string
+		for _, p := range properties {
+			item, present := props[p.name]
+			if !present {
+				if p.required {
+					missing = append(missing, p.name)
+				}
+				continue
+			}
+			found++
+			n := path.key(p.name)
+			if err := p.validate(item, path, depth); err != nil {
+				err = fmt.Errorf("validate properties: validate property %s: %w", path, err)
+				path.truncate(n)
+				return err
+			}
+			path.truncate(n)
+		}
+		// Keys that are not declared are checked only if there are any.
+		if checkUnknown && found < len(props) {
+			for k, item := range props {
+				if _, ok := declared[k]; ok {
+					continue
+				}
+				n := path.key(k)
+				err := validateUnknownProperty(patterns, restricted, k, item, path, depth)
+				path.truncate(n)
+				if err != nil {
+					return fmt.Errorf("validate properties: %w", err)
+				}
+			}
+		}
+		if len(missing) > 0 {
+			return &MissingPropertiesError{Path: path.String(), Properties: missing}
+		}
+		return s.validatePropertyCount(len(props))
+	}
+}
+
+// validateUnknownProperty validates the property that is not declared against pattern properties, the path points
+// to the property.
+func validateUnknownProperty(
+	patterns []compiledPatternProperty, restricted bool, k string, item interface{}, path *valuePath, depth int,
+) error {
+	var patternErr error
+	for _, pp := range patterns {
+		// NOTE: The first defined pattern property to validate prevails.
+		if !pp.pattern.MatchString(k) {
+			continue
+		}
+		err := pp.validate(item, path, depth)
+		if err == nil {
+			return nil
+		}
+		if patternErr == nil {
+			patternErr = err
+		}
+	}
+	if !restricted {
+		return nil
+	}
+	if patternErr != nil {
+		return fmt.Errorf("validate pattern property %s: %w", path, patternErr)
+	}
+	return fmt.Errorf("unexpected additional property \"%s\"", k)
+}
+
+func (c *shapeCompiler) compileArray(s *ArrayShape) compiledFunc {
+	var items compiledFunc
+	if s.Items != nil {
+		items = c.compile(s.Items.Shape)
+	}
+	return func(v interface{}, path *valuePath, depth int) error {
+		i, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("invalid type, got %T, expected []interface{}", v)
+		}
+		if err := s.validateItemCount(len(i)); err != nil {
+			return err
+		}
+		if items != nil {
+			for ii, item := range i {
+				n := path.index(ii)
+				if err := items(item, path, depth); err != nil {
+					err = fmt.Errorf("validate array item %s: %w", path, err)
+					path.truncate(n)
+					return err
+				}
+				path.truncate(n)
+			}
+		}
+		return s.validateUniqueItems(i)
+	}
+}
+
+func (c *shapeCompiler) compileUnion(s *UnionShape) compiledFunc {
+	members := make([]compiledFunc, len(s.AnyOf))
+	for i, member := range s.AnyOf {
+		members[i] = c.compile(member.Shape)
+	}
+	d := s.discriminator
+	if d == nil {
+		d = newUnionDiscriminator(s.AnyOf)
+	}
+	return func(v interface{}, path *valuePath, depth int) error {
+		// Members with discriminators are identified by the value instead of trying each of them.
+		if props, ok := v.(map[string]interface{}); ok && d.property != "" {
+			if value, present := props[d.property]; present {
+				if member, found := d.members[discriminatorKey(value)]; found {
+					if err := members[member](v, path, depth); err != nil {
+						return StacktraceNewWrapped("value does not match any type", err, s.Location(),
+							stacktrace.WithPosition(&s.Position))
+					}
+					return s.validateEnum(v)
+				}
+			}
+		}
+		for _, member := range members {
+			if member(v, path, depth) == nil {
+				return s.validateEnum(v)
+			}
+		}
+		return stacktrace.New("value does not match any type", s.Location(), stacktrace.WithPosition(&s.Position))
+	}
+}
+
+func (c *shapeCompiler) compileRecursive(s *RecursiveShape) compiledFunc {
+	head, ok := c.compiled[s.Head]
+	if !ok {
+		// The head is not an ancestor of the recursive shape within the compiled shape.
+		c.compile(s.Head.Shape)
+		head = c.compiled[s.Head]
+	}
+	return func(v interface{}, path *valuePath, depth int) error {
+		depth++
+		if err := s.raml.checkRecursionDepth(path, depth); err != nil {
+			return err
+		}
+		if err := (*head)(v, path, depth); err != nil {
+			return fmt.Errorf("validate recursive shape: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,139 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseShape_Compile(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Node:
+    type: object
+    properties:
+      value: integer
+      children?: Node[]
+  Event:
+    type: object
+    discriminator: type
+    properties:
+      type: string
+  Created:
+    type: Event
+    discriminatorValue: created
+    properties:
+      id: integer
+  Deleted:
+    type: Event
+    discriminatorValue: deleted
+  Pet:
+    type: object
+    additionalProperties: false
+    properties:
+      name:
+        type: string
+        minLength: 2
+      tags?:
+        type: string[]
+        uniqueItems: true
+        maxItems: 2
+      /^x-/: number
+    minProperties: 1
+  Owner:
+    properties:
+      pet: Pet | string
+      event?: Created | Deleted
+      root?: Node
+      nick?: string?
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	owner, err := rml.LookupType("Owner", "")
+	require.NoError(t, err)
+	validator, err := owner.Compile()
+	require.NoError(t, err)
+
+	values := []any{
+		map[string]any{"pet": "Rex"},
+		map[string]any{"pet": map[string]any{"name": "Rex", "x-age": 3.5, "tags": []any{"a", "b"}}},
+		map[string]any{"pet": map[string]any{"name": "R"}},
+		map[string]any{"pet": map[string]any{"name": "Rex", "color": "brown"}},
+		map[string]any{"pet": map[string]any{"name": "Rex", "x-age": "old"}},
+		map[string]any{"pet": map[string]any{"name": "Rex", "tags": []any{"a", "a"}}},
+		map[string]any{"pet": map[string]any{"name": "Rex", "tags": []any{"a", "b", "c"}}},
+		map[string]any{"pet": map[string]any{}},
+		map[string]any{"pet": nil},
+		map[string]any{"pet": "Rex", "nick": nil},
+		map[string]any{"pet": "Rex", "event": map[string]any{"type": "created", "id": 1}},
+		map[string]any{"pet": "Rex", "event": map[string]any{"type": "created", "id": "one"}},
+		map[string]any{"pet": "Rex", "event": map[string]any{"type": "deleted"}},
+		map[string]any{"pet": "Rex", "event": map[string]any{"type": "unknown"}},
+		map[string]any{"pet": "Rex", "root": map[string]any{"value": 1, "children": []any{
+			map[string]any{"value": 2, "children": []any{map[string]any{"value": 3}}},
+		}}},
+		map[string]any{"pet": "Rex", "root": map[string]any{"value": 1, "children": []any{
+			map[string]any{"value": 2, "children": []any{map[string]any{"value": "three"}}},
+		}}},
+		map[string]any{},
+		[]any{},
+		nil,
+	}
+	for i, v := range values {
+		want := owner.Validate(v)
+		got := validator.Validate(v)
+		if want == nil {
+			require.NoError(t, got, "value %d", i)
+			continue
+		}
+		require.Error(t, got, "value %d", i)
+		require.Equal(t, want.Error(), got.Error(), "value %d", i)
+	}
+}
+
+func BenchmarkBaseShape_Compile(b *testing.B) {
+	content := `#%RAML 1.0 Library
+types:
+  Item:
+    properties:
+      id: integer
+      name:
+        type: string
+        maxLength: 64
+      price: number
+      tags: string[]
+  Order:
+    properties:
+      id: string
+      items: Item[]
+`
+	rml, err := ParseFromString(content, "library.raml", b.TempDir(), OptWithUnwrap())
+	require.NoError(b, err)
+	order, err := rml.LookupType("Order", "")
+	require.NoError(b, err)
+	items := make([]any, 20)
+	for i := range items {
+		items[i] = map[string]any{"id": i, "name": "item", "price": 1.5, "tags": []any{"a", "b"}}
+	}
+	value := map[string]any{"id": "order", "items": items}
+
+	b.Run("Validate", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if err := order.Validate(value); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Compiled", func(b *testing.B) {
+		validator, err := order.Compile()
+		require.NoError(b, err)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for range b.N {
+			if err := validator.Validate(value); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
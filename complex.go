@@ -2,8 +2,8 @@ package raml
 
 import (
 	"fmt"
-	"regexp"
 	"strconv"
+	"strings"
 
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 	"gopkg.in/yaml.v3"
@@ -41,35 +41,50 @@ func (s *ArrayShape) clone(base *BaseShape, clonedMap map[int64]*BaseShape) Shap
 }
 
 func (s *ArrayShape) validate(v interface{}, ctxPath string) error {
+	return validateAt(s, v, ctxPath)
+}
+
+func (s *ArrayShape) validateWithState(v interface{}, st *validationState) error {
 	i, ok := v.([]interface{})
 	if !ok {
 		return fmt.Errorf("invalid type, got %T, expected []interface{}", v)
 	}
 
-	arrayLen := uint64(len(i))
+	if err := s.validateItemCount(len(i)); err != nil {
+		return err
+	}
+	if s.Items != nil {
+		for ii, item := range i {
+			n := st.path.index(ii)
+			if err := validateValue(s.Items.Shape, item, st); err != nil {
+				err = fmt.Errorf("validate array item %s: %w", st.path, err)
+				st.path.truncate(n)
+				return err
+			}
+			st.path.truncate(n)
+		}
+	}
+	return s.validateUniqueItems(i)
+}
+
+func (s *ArrayShape) validateItemCount(n int) error {
+	arrayLen := uint64(n)
 	if s.MinItems != nil && arrayLen < *s.MinItems {
 		return fmt.Errorf("array must have at least %d items", *s.MinItems)
 	}
 	if s.MaxItems != nil && arrayLen > *s.MaxItems {
 		return fmt.Errorf("array must have not more than %d items", *s.MaxItems)
 	}
-	validateUniqueItems := s.UniqueItems != nil && *s.UniqueItems
-	uniqueItems := make(map[interface{}]struct{})
-	for ii, item := range i {
-		ctxPathA := ctxPath + "[" + strconv.Itoa(ii) + "]"
-		if s.Items != nil {
-			if err := s.Items.Shape.validate(item, ctxPathA); err != nil {
-				return fmt.Errorf("validate array item %s: %w", ctxPathA, err)
-			}
-		}
-		if validateUniqueItems {
-			uniqueItems[item] = struct{}{}
-		}
+	return nil
+}
+
+func (s *ArrayShape) validateUniqueItems(items []interface{}) error {
+	if s.UniqueItems == nil || !*s.UniqueItems {
+		return nil
 	}
-	if validateUniqueItems && len(uniqueItems) != len(i) {
+	if !hasUniqueItems(items) {
 		return fmt.Errorf("array contains duplicate items")
 	}
-
 	return nil
 }
 
@@ -77,7 +92,7 @@ func (s *ArrayShape) validate(v interface{}, ctxPath string) error {
 func (s *ArrayShape) inherit(source Shape) (Shape, error) {
 	ss, ok := source.(*ArrayShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location,
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(),
 			stacktrace.WithPosition(&s.Position), stacktrace.WithInfo("source", source.Base().Type),
 			stacktrace.WithInfo("target", s.Type))
 	}
@@ -86,42 +101,51 @@ func (s *ArrayShape) inherit(source Shape) (Shape, error) {
 	} else if ss.Items != nil {
 		_, err := s.Items.Inherit(ss.Items)
 		if err != nil {
-			return nil, StacktraceNewWrapped("merge array items", err, s.Location,
+			return nil, StacktraceNewWrapped("merge array items", err, s.Location(),
 				stacktrace.WithPosition(&s.Items.Position))
 		}
 	}
 	if s.MinItems == nil {
 		s.MinItems = ss.MinItems
-	} else if ss.MinItems != nil && *s.MinItems > *ss.MinItems {
-		return nil, stacktrace.New("minItems constraint violation", s.Location,
+	} else if ss.MinItems != nil && *s.MinItems < *ss.MinItems {
+		st := stacktrace.New("minItems constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position), stacktrace.WithInfo("source", *ss.MinItems),
 			stacktrace.WithInfo("target", *s.MinItems))
+		if err := s.inheritConflict(st, func() { s.MinItems = ss.MinItems }); err != nil {
+			return nil, err
+		}
 	}
 	if s.MaxItems == nil {
 		s.MaxItems = ss.MaxItems
-	} else if ss.MaxItems != nil && *s.MaxItems < *ss.MaxItems {
-		return nil, stacktrace.New("maxItems constraint violation", s.Location,
+	} else if ss.MaxItems != nil && *s.MaxItems > *ss.MaxItems {
+		st := stacktrace.New("maxItems constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position), stacktrace.WithInfo("source", *ss.MaxItems),
 			stacktrace.WithInfo("target", *s.MaxItems))
+		if err := s.inheritConflict(st, func() { s.MaxItems = ss.MaxItems }); err != nil {
+			return nil, err
+		}
 	}
 	if s.UniqueItems == nil {
 		s.UniqueItems = ss.UniqueItems
 	} else if ss.UniqueItems != nil && *ss.UniqueItems && !*s.UniqueItems {
-		return nil, stacktrace.New("uniqueItems constraint violation", s.Location,
+		st := stacktrace.New("uniqueItems constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position), stacktrace.WithInfo("source", *ss.UniqueItems),
 			stacktrace.WithInfo("target", *s.UniqueItems))
+		if err := s.inheritConflict(st, func() { s.UniqueItems = ss.UniqueItems }); err != nil {
+			return nil, err
+		}
 	}
 	return s, nil
 }
 
 func (s *ArrayShape) check() error {
 	if s.MinItems != nil && s.MaxItems != nil && *s.MinItems > *s.MaxItems {
-		return stacktrace.New("minItems must be less than or equal to maxItems", s.Location,
+		return stacktrace.New("minItems must be less than or equal to maxItems", s.Location(),
 			stacktrace.WithPosition(&s.Position))
 	}
 	if s.Items != nil {
 		if err := s.Items.Check(); err != nil {
-			return StacktraceNewWrapped("check items", err, s.Location,
+			return StacktraceNewWrapped("check items", err, s.Location(),
 				stacktrace.WithPosition(&s.Items.Position))
 		}
 	}
@@ -131,7 +155,7 @@ func (s *ArrayShape) check() error {
 // UnmarshalYAMLNodes unmarshals the array shape from YAML nodes.
 func (s *ArrayShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 	if len(v)%2 != 0 {
-		return stacktrace.New("odd number of nodes", s.Location, stacktrace.WithPosition(&s.Position))
+		return stacktrace.New("odd number of nodes", s.Location(), stacktrace.WithPosition(&s.Position))
 	}
 	for i := 0; i != len(v); i += 2 {
 		node := v[i]
@@ -140,36 +164,36 @@ func (s *ArrayShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 		switch node.Value {
 		case FacetMinItems:
 			if err := valueNode.Decode(&s.MinItems); err != nil {
-				return StacktraceNewWrapped("decode", err, s.Location,
+				return StacktraceNewWrapped("decode", err, s.Location(),
 					WithNodePosition(valueNode),
 					stacktrace.WithInfo("facet", FacetMinItems))
 			}
 		case FacetMaxItems:
 			if err := valueNode.Decode(&s.MaxItems); err != nil {
-				return StacktraceNewWrapped("decode", err, s.Location,
+				return StacktraceNewWrapped("decode", err, s.Location(),
 					WithNodePosition(valueNode),
 					stacktrace.WithInfo("facet", FacetMaxItems))
 			}
 		case FacetItems:
-			shape, err := s.raml.makeNewShapeYAML(valueNode, FacetItems, s.Location)
+			shape, err := s.raml.makeNewShapeYAML(valueNode, FacetItems, s.Location())
 			if err != nil {
-				return StacktraceNewWrapped("make shape", err, s.Location,
+				return StacktraceNewWrapped("make shape", err, s.Location(),
 					WithNodePosition(valueNode),
 					stacktrace.WithInfo("facet", FacetItems))
 			}
 			s.Items = shape
 		case FacetUniqueItems:
 			if err := valueNode.Decode(&s.UniqueItems); err != nil {
-				return StacktraceNewWrapped("decode", err, s.Location,
+				return StacktraceNewWrapped("decode", err, s.Location(),
 					WithNodePosition(valueNode),
 					stacktrace.WithInfo("facet", FacetUniqueItems))
 			}
 		default:
-			n, err := s.raml.makeRootNode(valueNode, s.Location)
+			n, err := s.raml.makeRootNode(valueNode, s.Location())
 			if err != nil {
-				return StacktraceNewWrapped("make node", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
 			}
-			s.CustomShapeFacets.Set(node.Value, n)
+			s.setCustomShapeFacet(node.Value, n)
 		}
 	}
 	return nil
@@ -191,36 +215,45 @@ type ObjectShape struct {
 	*BaseShape
 
 	ObjectFacets
+
+	// subtypes maps keys of discriminator values to unwrapped subtypes, see buildDiscriminators.
+	subtypes map[string]*BaseShape
 }
 
 func (s *ObjectShape) unmarshalPatternProperties(
-	nodeName, propertyName string, data *yaml.Node, hasImplicitOptional bool) error {
+	keyNode *yaml.Node, propertyName string, data *yaml.Node, hasImplicitOptional bool) error {
 	if s.PatternProperties == nil {
 		s.PatternProperties = orderedmap.New[string, PatternProperty]()
 	}
-	property, err := s.raml.makePatternProperty(nodeName, propertyName, data, s.Location,
+	property, err := s.raml.makePatternProperty(keyNode.Value, propertyName, data, s.Location(),
 		hasImplicitOptional)
 	if err != nil {
-		return StacktraceNewWrapped("make pattern property", err, s.Location,
+		return StacktraceNewWrapped("make pattern property", err, s.Location(),
 			WithNodePosition(data))
 	}
+	property.Shape.Comments = makeComments(keyNode, data)
+	property.rawNode = s.raml.rawNode(keyNode)
 	s.PatternProperties.Set(propertyName, property)
 	return nil
 }
 
-func (s *ObjectShape) unmarshalProperty(nodeName string, data *yaml.Node) error {
+func (s *ObjectShape) unmarshalProperty(keyNode *yaml.Node, data *yaml.Node) error {
+	nodeName := keyNode.Value
 	propertyName, hasImplicitOptional := s.raml.chompImplicitOptional(nodeName)
 	if len(propertyName) > 1 && propertyName[0] == '/' && propertyName[len(propertyName)-1] == '/' {
-		return s.unmarshalPatternProperties(nodeName, propertyName, data, hasImplicitOptional)
+		return s.unmarshalPatternProperties(keyNode, propertyName, data, hasImplicitOptional)
 	}
 
 	if s.Properties == nil {
 		s.Properties = orderedmap.New[string, Property]()
 	}
-	property, err := s.raml.makeProperty(nodeName, propertyName, data, s.Location, hasImplicitOptional)
+	property, err := s.raml.makeProperty(nodeName, propertyName, data, s.Location(), hasImplicitOptional)
 	if err != nil {
-		return StacktraceNewWrapped("make property", err, s.Location, WithNodePosition(data))
+		return StacktraceNewWrapped("make property", err, s.Location(), WithNodePosition(data))
 	}
+	property.Shape.Comments = makeComments(keyNode, data)
+	property.Comments = property.Shape.Comments
+	property.rawNode = s.raml.rawNode(keyNode)
 	s.Properties.Set(property.Name, property)
 	return nil
 }
@@ -234,49 +267,49 @@ func (s *ObjectShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 		switch node.Value {
 		case FacetAdditionalProperties:
 			if err := valueNode.Decode(&s.AdditionalProperties); err != nil {
-				return StacktraceNewWrapped("decode", err, s.Location,
+				return StacktraceNewWrapped("decode", err, s.Location(),
 					WithNodePosition(valueNode),
 					stacktrace.WithInfo("facet", FacetAdditionalProperties))
 			}
 		case FacetDiscriminator:
 			if err := valueNode.Decode(&s.Discriminator); err != nil {
-				return StacktraceNewWrapped("decode", err, s.Location,
+				return StacktraceNewWrapped("decode", err, s.Location(),
 					WithNodePosition(valueNode),
 					stacktrace.WithInfo("facet", FacetDiscriminator))
 			}
 		case FacetDiscriminatorValue:
 			if err := valueNode.Decode(&s.DiscriminatorValue); err != nil {
-				return StacktraceNewWrapped("decode", err, s.Location,
+				return StacktraceNewWrapped("decode", err, s.Location(),
 					WithNodePosition(valueNode),
 					stacktrace.WithInfo("facet", FacetDiscriminatorValue))
 			}
 		case FacetMinProperties:
 			if err := valueNode.Decode(&s.MinProperties); err != nil {
-				return StacktraceNewWrapped("decode", err, s.Location,
+				return StacktraceNewWrapped("decode", err, s.Location(),
 					WithNodePosition(valueNode),
 					stacktrace.WithInfo("facet", FacetMinProperties))
 			}
 		case FacetMaxProperties:
 			if err := valueNode.Decode(&s.MaxProperties); err != nil {
-				return StacktraceNewWrapped("decode", err, s.Location,
+				return StacktraceNewWrapped("decode", err, s.Location(),
 					WithNodePosition(valueNode),
 					stacktrace.WithInfo("facet", FacetMaxProperties))
 			}
 		case FacetProperties:
 			for j := 0; j != len(valueNode.Content); j += 2 {
-				nodeName := valueNode.Content[j].Value
+				keyNode := valueNode.Content[j]
 				data := valueNode.Content[j+1]
 
-				if err := s.unmarshalProperty(nodeName, data); err != nil {
+				if err := s.unmarshalProperty(keyNode, data); err != nil {
 					return fmt.Errorf("unmarshal property: %w", err)
 				}
 			}
 		default:
-			n, err := s.raml.makeRootNode(valueNode, s.Location)
+			n, err := s.raml.makeRootNode(valueNode, s.Location())
 			if err != nil {
-				return StacktraceNewWrapped("make node", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
 			}
-			s.CustomShapeFacets.Set(node.Value, n)
+			s.setCustomShapeFacet(node.Value, n)
 		}
 	}
 	return nil
@@ -309,74 +342,177 @@ func (s *ObjectShape) clone(base *BaseShape, clonedMap map[int64]*BaseShape) Sha
 	return &c
 }
 
-func (s *ObjectShape) validateProperties(ctxPath string, props map[string]interface{}) error {
-	restrictedAdditionalProperties := s.AdditionalProperties != nil && !*s.AdditionalProperties
+func (s *ObjectShape) validateProperties(props map[string]interface{}, st *validationState) error {
 	for k, item := range props {
-		// Explicitly defined properties have priority over pattern properties.
-		ctxPathK := ctxPath + "." + k
-		if s.Properties != nil {
-			if p, present := s.Properties.Get(k); present {
-				if err := p.Shape.Shape.validate(item, ctxPathK); err != nil {
-					return fmt.Errorf("validate property %s: %w", ctxPathK, err)
-				}
-				continue
+		n := st.path.key(k)
+		err := s.validateProperty(k, item, st)
+		st.path.truncate(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateProperty validates the property of the instance, the path of the state points to the property.
+func (s *ObjectShape) validateProperty(k string, item interface{}, st *validationState) error {
+	// Explicitly defined properties have priority over pattern properties.
+	if s.Properties != nil {
+		if p, present := s.Properties.Get(k); present {
+			if item == nil && !p.Required && st.opts.OptionalNull {
+				return nil
 			}
+			if err := validateValue(p.Shape.Shape, item, st); err != nil {
+				return fmt.Errorf("validate property %s: %w", st.path, err)
+			}
+			return nil
 		}
-		if s.PatternProperties != nil {
-			found := false
-			for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
-				pp := pair.Value
-				// NOTE: We validate only those keys that match the pattern.
-				// The keys that do not match are considered as additional properties and are not validated.
-				if pp.Pattern.MatchString(k) {
-					// NOTE: The first defined pattern property to validate prevails.
-					if err := pp.Shape.Shape.validate(item, ctxPathK); err == nil {
-						found = true
-						break
-					}
+	}
+	var patternErr error
+	if s.PatternProperties != nil {
+		if item == nil && st.opts.OptionalNull && s.matchesPatternProperty(k) {
+			return nil
+		}
+		for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+			pp := pair.Value
+			// NOTE: We validate only those keys that match the pattern.
+			// The keys that do not match are considered as additional properties and are not validated.
+			if pp.Pattern.MatchString(k) {
+				// NOTE: The first defined pattern property to validate prevails.
+				ps := st.fork()
+				ps.note("pattern property %s", pair.Key)
+				err := validateValue(pp.Shape.Shape, item, ps)
+				if err == nil {
+					st.merge(ps)
+					return nil
+				}
+				if patternErr == nil {
+					patternErr = err
 				}
-			}
-			if found {
-				continue
 			}
 		}
-		// Will never happen if pattern properties are present.
-		if restrictedAdditionalProperties {
-			return fmt.Errorf("unexpected additional property \"%s\"", k)
+	}
+	// Pattern properties are combined with restricted additional properties only with
+	// OptWithRestrictedPatternProperties.
+	if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+		if patternErr != nil {
+			return fmt.Errorf("validate pattern property %s: %w", st.path, patternErr)
+		}
+		return fmt.Errorf("unexpected additional property \"%s\"", k)
+	}
+	// The policy of the caller applies only if the type does not decide on additional properties.
+	if s.AdditionalProperties == nil {
+		switch st.opts.UnknownProperties {
+		case UnknownPropertiesWarn:
+			st.warn(fmt.Sprintf("unknown property \"%s\"", k))
+		case UnknownPropertiesReject:
+			return fmt.Errorf("unknown property \"%s\"", k)
 		}
 	}
 	return nil
 }
 
+// matchesPatternProperty reports whether the name matches any pattern property.
+func (s *ObjectShape) matchesPatternProperty(name string) bool {
+	for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Value.Pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingPropertiesError is returned when an object instance lacks required properties.
+type MissingPropertiesError struct {
+	// Path is the path of the object, e.g. "$.owner".
+	Path string
+	// Properties contains names of missing properties in the order of declaration.
+	Properties []string
+}
+
+func (e *MissingPropertiesError) Error() string {
+	quoted := make([]string, len(e.Properties))
+	for i, p := range e.Properties {
+		quoted[i] = strconv.Quote(p)
+	}
+	if len(quoted) == 1 {
+		return fmt.Sprintf("missing required property %s at %s", quoted[0], e.Path)
+	}
+	return fmt.Sprintf("missing required properties %s at %s", strings.Join(quoted, ", "), e.Path)
+}
+
+// validateRequiredProperties returns MissingPropertiesError that lists all required properties absent in the instance.
+func (s *ObjectShape) validateRequiredProperties(props map[string]interface{}, path *valuePath) error {
+	if s.Properties == nil {
+		return nil
+	}
+	var missing []string
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		if !pair.Value.Required {
+			continue
+		}
+		if _, ok := props[pair.Key]; !ok {
+			missing = append(missing, pair.Key)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingPropertiesError{Path: path.String(), Properties: missing}
+	}
+	return nil
+}
+
 func (s *ObjectShape) validate(v interface{}, ctxPath string) error {
+	return validateAt(s, v, ctxPath)
+}
+
+func (s *ObjectShape) validateWithState(v interface{}, st *validationState) error {
 	props, ok := v.(map[string]interface{})
 	if !ok {
 		return fmt.Errorf("invalid type, got %T, expected map[string]interface{}", v)
 	}
 
-	if err := s.validateProperties(ctxPath, props); err != nil {
+	// Instances of types with discriminator are validated against the subtype identified by the discriminator value.
+	sub, err := s.discriminatedSubtype(props)
+	if err != nil {
+		return err
+	}
+	if sub != nil {
+		st.note("discriminator %s", *s.Discriminator)
+		return validateValue(sub.Shape, v, st)
+	}
+
+	if err := s.validateProperties(props, st); err != nil {
 		return fmt.Errorf("validate properties: %w", err)
 	}
+	if err := s.validateRequiredProperties(props, st.path); err != nil {
+		return err
+	}
+
+	return s.validatePropertyCount(len(props))
+}
 
-	mapLen := uint64(len(props))
+func (s *ObjectShape) validatePropertyCount(n int) error {
+	mapLen := uint64(n)
 	if s.MinProperties != nil && mapLen < *s.MinProperties {
 		return fmt.Errorf("object must have at least %d properties", *s.MinProperties)
 	}
 	if s.MaxProperties != nil && mapLen > *s.MaxProperties {
 		return fmt.Errorf("object must have not more than %d properties", *s.MaxProperties)
 	}
-
 	return nil
 }
 
 func (s *ObjectShape) inheritMinProperties(source *ObjectShape) error {
 	if s.MinProperties == nil {
 		s.MinProperties = source.MinProperties
-	} else if source.MinProperties != nil && *s.MinProperties > *source.MinProperties {
-		return stacktrace.New("minProperties constraint violation", s.Location,
+	} else if source.MinProperties != nil && *s.MinProperties < *source.MinProperties {
+		st := stacktrace.New("minProperties constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", *source.MinProperties),
 			stacktrace.WithInfo("target", *s.MinProperties))
+		if err := s.inheritConflict(st, func() { s.MinProperties = source.MinProperties }); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -384,11 +520,14 @@ func (s *ObjectShape) inheritMinProperties(source *ObjectShape) error {
 func (s *ObjectShape) inheritMaxProperties(source *ObjectShape) error {
 	if s.MaxProperties == nil {
 		s.MaxProperties = source.MaxProperties
-	} else if source.MaxProperties != nil && *s.MaxProperties < *source.MaxProperties {
-		return stacktrace.New("maxProperties constraint violation", s.Location,
+	} else if source.MaxProperties != nil && *s.MaxProperties > *source.MaxProperties {
+		st := stacktrace.New("maxProperties constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", *source.MaxProperties),
 			stacktrace.WithInfo("target", *s.MaxProperties))
+		if err := s.inheritConflict(st, func() { s.MaxProperties = source.MaxProperties }); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -407,7 +546,7 @@ func (s *ObjectShape) inheritProperties(source *ObjectShape) error {
 		k, sourceProp := pair.Key, pair.Value
 		if targetProp, present := s.Properties.Get(k); present {
 			if sourceProp.Required && !targetProp.Required {
-				return stacktrace.New("cannot make required property optional", s.Location,
+				return stacktrace.New("cannot make required property optional", s.Location(),
 					stacktrace.WithPosition(&targetProp.Shape.Position),
 					stacktrace.WithInfo("property", k),
 					stacktrace.WithInfo("source", sourceProp.Required),
@@ -416,7 +555,7 @@ func (s *ObjectShape) inheritProperties(source *ObjectShape) error {
 			}
 			_, err := targetProp.Shape.Inherit(sourceProp.Shape)
 			if err != nil {
-				return StacktraceNewWrapped("inherit property", err, s.Location,
+				return StacktraceNewWrapped("inherit property", err, s.Location(),
 					stacktrace.WithPosition(&targetProp.Shape.Position),
 					stacktrace.WithInfo("property", k),
 					stacktrace.WithType(stacktrace.TypeUnwrapping))
@@ -439,7 +578,7 @@ func (s *ObjectShape) inheritPatternProperties(source *ObjectShape) error {
 			if targetProp, present := s.PatternProperties.Get(k); present {
 				_, err := targetProp.Shape.Inherit(sourceProp.Shape)
 				if err != nil {
-					return StacktraceNewWrapped("inherit pattern property", err, s.Location,
+					return StacktraceNewWrapped("inherit pattern property", err, s.Location(),
 						stacktrace.WithPosition(&targetProp.Shape.Position),
 						stacktrace.WithInfo("property", k),
 						stacktrace.WithType(stacktrace.TypeUnwrapping))
@@ -459,7 +598,7 @@ func (s *ObjectShape) inherit(source Shape) (Shape, error) {
 	}
 	ss, ok := source.(*ObjectShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location,
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", source.Base().Type),
 			stacktrace.WithInfo("target", s.Base().Type))
@@ -496,18 +635,18 @@ func (s *ObjectShape) checkPatternProperties() error {
 	if s.PatternProperties == nil {
 		return nil
 	}
-	if s.AdditionalProperties != nil && !*s.AdditionalProperties {
-		// TODO: We actually can allow pattern properties with "additionalProperties: false" for stricter
-		// 	validation.
-		// This will contradict RAML 1.0 spec, but JSON Schema allows that.
-		// https://json-schema.org/understanding-json-schema/reference/object#additionalproperties
+	// RAML 1.0 forbids the combination, but JSON Schema allows it to reject properties that match neither
+	// explicit nor pattern properties.
+	// https://json-schema.org/understanding-json-schema/reference/object#additionalproperties
+	if s.AdditionalProperties != nil && !*s.AdditionalProperties &&
+		(s.raml == nil || !s.raml.restrictedPatternProperties) {
 		return stacktrace.New("pattern properties are not allowed with \"additionalProperties: false\"",
-			s.Location, stacktrace.WithPosition(&s.Position))
+			s.Location(), stacktrace.WithPosition(&s.Position))
 	}
 	for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
 		prop := pair.Value
 		if err := prop.Shape.Check(); err != nil {
-			return StacktraceNewWrapped("check pattern property", err, s.Location,
+			return StacktraceNewWrapped("check pattern property", err, s.Location(),
 				stacktrace.WithPosition(&prop.Shape.Position),
 				stacktrace.WithInfo("property", prop.Pattern.String()))
 		}
@@ -523,7 +662,7 @@ func (s *ObjectShape) checkProperties() error {
 	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
 		prop := pair.Value
 		if err := prop.Shape.Check(); err != nil {
-			return StacktraceNewWrapped("check property", err, s.Location,
+			return StacktraceNewWrapped("check property", err, s.Location(),
 				stacktrace.WithPosition(&prop.Shape.Position),
 				stacktrace.WithInfo("property", prop.Name))
 		}
@@ -533,12 +672,12 @@ func (s *ObjectShape) checkProperties() error {
 	if s.Discriminator != nil {
 		prop, ok := s.Properties.Get(*s.Discriminator)
 		if !ok {
-			return stacktrace.New("discriminator property not found", s.Location,
+			return stacktrace.New("discriminator property not found", s.Location(),
 				stacktrace.WithPosition(&s.Position),
 				stacktrace.WithInfo("discriminator", *s.Discriminator))
 		}
-		if prop.Shape.IsScalar() {
-			return stacktrace.New("discriminator property must be a scalar", s.Location,
+		if !prop.Shape.IsScalar() {
+			return stacktrace.New("discriminator property must be a scalar", s.Location(),
 				stacktrace.WithPosition(&prop.Shape.Position),
 				stacktrace.WithInfo("discriminator", *s.Discriminator))
 		}
@@ -547,7 +686,7 @@ func (s *ObjectShape) checkProperties() error {
 			discriminatorValue = s.Base().Name
 		}
 		if err := prop.Shape.Validate(discriminatorValue); err != nil {
-			return StacktraceNewWrapped("validate discriminator value", err, s.Location,
+			return StacktraceNewWrapped("validate discriminator value", err, s.Location(),
 				stacktrace.WithPosition(&s.Base().Position),
 				stacktrace.WithInfo("discriminator", *s.Discriminator))
 		}
@@ -559,7 +698,7 @@ func (s *ObjectShape) checkProperties() error {
 func (s *ObjectShape) check() error {
 	if s.MinProperties != nil && s.MaxProperties != nil && *s.MinProperties > *s.MaxProperties {
 		return stacktrace.New("minProperties must be less than or equal to maxProperties",
-			s.Location, stacktrace.WithPosition(&s.Position))
+			s.Location(), stacktrace.WithPosition(&s.Position))
 	}
 	if err := s.checkPatternProperties(); err != nil {
 		return fmt.Errorf("check pattern properties: %w", err)
@@ -568,7 +707,7 @@ func (s *ObjectShape) check() error {
 		return fmt.Errorf("check properties: %w", err)
 	}
 	if s.Discriminator != nil && s.Properties == nil {
-		return stacktrace.New("discriminator without properties", s.Location,
+		return stacktrace.New("discriminator without properties", s.Location(),
 			stacktrace.WithPosition(&s.Position))
 	}
 	return nil
@@ -587,7 +726,7 @@ func (r *RAML) makePatternProperty(nodeName string, propertyName string, v *yaml
 		return PatternProperty{}, stacktrace.New("'required' facet is not supported on pattern property",
 			location, WithNodePosition(v))
 	}
-	re, err := regexp.Compile(propertyName[1 : len(propertyName)-1])
+	re, err := r.compilePattern(propertyName[1:len(propertyName)-1], location, v)
 	if err != nil {
 		return PatternProperty{}, StacktraceNewWrapped("compile pattern", err, location, WithNodePosition(v))
 	}
@@ -629,7 +768,7 @@ func (r *RAML) makeProperty(nodeName string, propertyName string, v *yaml.Node,
 		required = *shapeRequired
 	}
 	return Property{
-		Name:     finalName,
+		Name:     r.intern(finalName),
 		Shape:    shape,
 		Required: required,
 		raml:     r,
@@ -641,15 +780,19 @@ type Property struct {
 	Name     string
 	Shape    *BaseShape
 	Required bool
+	// Comments are YAML comments adjacent to the property declaration, nil if there are none.
+	Comments *Comments
 	raml     *RAML
+	rawNode  *yaml.Node
 }
 
 // Property represents a pattern property of an object shape.
 type PatternProperty struct {
-	Pattern *regexp.Regexp
+	Pattern Regexp
 	Shape   *BaseShape
 	// Pattern properties are always optional.
-	raml *RAML
+	raml    *RAML
+	rawNode *yaml.Node
 }
 
 // UnionFacets contains constraints for union shapes.
@@ -663,10 +806,34 @@ type UnionShape struct {
 
 	EnumFacets
 	UnionFacets
+
+	// discriminator dispatches instances to members, see buildDiscriminators.
+	discriminator *unionDiscriminator
 }
 
 // UnmarshalYAMLNodes unmarshals the union shape from YAML nodes.
-func (s *UnionShape) unmarshalYAMLNodes(_ []*yaml.Node) error {
+func (s *UnionShape) unmarshalYAMLNodes(v []*yaml.Node) error {
+	if len(v)%2 != 0 {
+		return stacktrace.New("odd number of nodes", s.Location())
+	}
+	for i := 0; i != len(v); i += 2 {
+		node := v[i]
+		valueNode := v[i+1]
+
+		if node.Value == FacetEnum {
+			enums, err := s.raml.MakeEnum(valueNode, s.Location())
+			if err != nil {
+				return StacktraceNewWrapped("make enum", err, s.Location(), WithNodePosition(valueNode))
+			}
+			s.Enum = enums
+			continue
+		}
+		n, err := s.raml.makeRootNode(valueNode, s.Location())
+		if err != nil {
+			return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
+		}
+		s.setCustomShapeFacet(node.Value, n)
+	}
 	return nil
 }
 
@@ -686,31 +853,58 @@ func (s *UnionShape) clone(base *BaseShape, clonedMap map[int64]*BaseShape) Shap
 }
 
 func (s *UnionShape) validate(v interface{}, ctxPath string) error {
+	return validateAt(s, v, ctxPath)
+}
+
+func (s *UnionShape) validateWithState(v interface{}, st *validationState) error {
+	// Members with discriminators are identified by the value instead of trying each of them.
+	if member := s.discriminatedMember(v); member != nil {
+		st.note("discriminator %s", *member.Shape.(*ObjectShape).Discriminator)
+		if err := validateValue(member.Shape, v, st); err != nil {
+			return StacktraceNewWrapped("value does not match any type", err, s.Location(),
+				stacktrace.WithPosition(&s.Position))
+		}
+		return s.validateEnum(v)
+	}
 	// TODO: Collect errors
-	for _, item := range s.AnyOf {
-		if err := item.Shape.validate(v, ctxPath); err == nil {
-			return nil
+	if !s.matchesMember(v, st) {
+		return stacktrace.New("value does not match any type", s.Location(),
+			stacktrace.WithPosition(&s.Position))
+	}
+	return s.validateEnum(v)
+}
+
+// matchesMember reports whether the value is valid against any member. Only warnings of the matching member are kept.
+func (s *UnionShape) matchesMember(v interface{}, st *validationState) bool {
+	for i, item := range s.AnyOf {
+		ms := st.fork()
+		ms.note("union member %d of %d", i+1, len(s.AnyOf))
+		if err := validateValue(item.Shape, v, ms); err == nil {
+			st.merge(ms)
+			return true
 		}
 	}
-	return stacktrace.New("value does not match any type", s.Location,
-		stacktrace.WithPosition(&s.Position))
+	return false
 }
 
 // Inherit merges the source shape into the target shape.
 func (s *UnionShape) inherit(source Shape) (Shape, error) {
 	ss, ok := source.(*UnionShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location,
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", source.Base().Type),
 			stacktrace.WithInfo("target", s.Base().Type))
 	}
+	if err := s.inheritEnum(&ss.EnumFacets, s.BaseShape); err != nil {
+		return nil, err
+	}
 	if len(s.AnyOf) == 0 {
 		s.AnyOf = ss.AnyOf
 		return s, nil
 	}
-	// TODO: Implement enum facets inheritance
 	var finalFiltered []*BaseShape
+	var st *stacktrace.StackTrace
 	for _, sourceMember := range ss.AnyOf {
 		var filtered []*BaseShape
 		for _, targetMember := range s.AnyOf {
@@ -718,19 +912,32 @@ func (s *UnionShape) inherit(source Shape) (Shape, error) {
 				// Clone is required to avoid modifying the original target member shape.
 				cs := targetMember.CloneDetached()
 				// TODO: Probably all copied shapes must change IDs since these are actually new shapes.
-				cs.ID = generateShapeID()
+				cs.ID = s.raml.newCopyID(targetMember.ID, sourceMember.ID)
 				ms, err := cs.Inherit(sourceMember)
 				if err != nil {
-					// TODO: Collect errors
-					// StacktraceNewWrapped("merge union member", err, s.Location)
+					se := StacktraceNewWrapped("merge union member", err, s.Location(),
+						stacktrace.WithPosition(&targetMember.Position))
+					if st == nil {
+						st = se
+					} else {
+						st = st.Append(se)
+					}
+					continue
+				}
+				// Members are narrowed by the enum of the union, members that accept no enum value are dropped.
+				if !ms.intersectEnum(s.Enum) {
 					continue
 				}
 				filtered = append(filtered, ms)
 			}
 		}
 		if len(filtered) == 0 {
-			return nil, stacktrace.New("failed to find compatible union member", s.Location,
+			se := stacktrace.New("failed to find compatible union member", s.Location(),
 				stacktrace.WithPosition(&s.Position))
+			if st != nil {
+				se = se.Append(st)
+			}
+			return nil, se
 		}
 		finalFiltered = append(finalFiltered, filtered...)
 	}
@@ -741,10 +948,19 @@ func (s *UnionShape) inherit(source Shape) (Shape, error) {
 func (s *UnionShape) check() error {
 	for _, item := range s.AnyOf {
 		if err := item.Check(); err != nil {
-			return StacktraceNewWrapped("check union member", err, s.Location,
+			return StacktraceNewWrapped("check union member", err, s.Location(),
 				stacktrace.WithPosition(&item.Position))
 		}
 	}
+	// Enum of the union is intersected with members, so every value must be accepted by at least one member.
+	st := newValidationState(ValidateOptions{})
+	defer st.release()
+	for _, e := range s.Enum {
+		if !s.matchesMember(e.Value, st) {
+			return stacktrace.New("enum value does not match any union member", e.Location,
+				stacktrace.WithPosition(&e.Position), stacktrace.WithInfo("value", e.String()))
+		}
+	}
 	return nil
 }
 
@@ -777,12 +993,12 @@ func (s *JSONShape) unmarshalYAMLNodes(_ []*yaml.Node) error {
 func (s *JSONShape) inherit(source Shape) (Shape, error) {
 	ss, ok := source.(*JSONShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location,
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(),
 			stacktrace.WithPosition(&s.Position), stacktrace.WithInfo("source", source.Base().Type),
 			stacktrace.WithInfo("target", s.Base().Type))
 	}
 	if s.Raw != "" && ss.Raw != "" && s.Raw != ss.Raw {
-		return nil, stacktrace.New("cannot inherit from different JSON schema", s.Location,
+		return nil, stacktrace.New("cannot inherit from different JSON schema", s.Location(),
 			stacktrace.WithPosition(&s.Position))
 	}
 	s.Schema = ss.Schema
@@ -812,7 +1028,7 @@ func (s *UnknownShape) clone(base *BaseShape, _ map[int64]*BaseShape) Shape {
 }
 
 func (s *UnknownShape) validate(_ interface{}, _ string) error {
-	return stacktrace.New("cannot validate against unknown shape", s.Location, stacktrace.WithPosition(&s.Position))
+	return stacktrace.New("cannot validate against unknown shape", s.Location(), stacktrace.WithPosition(&s.Position))
 }
 
 func (s *UnknownShape) unmarshalYAMLNodes(v []*yaml.Node) error {
@@ -821,11 +1037,11 @@ func (s *UnknownShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 }
 
 func (s *UnknownShape) inherit(_ Shape) (Shape, error) {
-	return nil, stacktrace.New("cannot inherit from unknown shape", s.Location, stacktrace.WithPosition(&s.Position))
+	return nil, stacktrace.New("cannot inherit from unknown shape", s.Location(), stacktrace.WithPosition(&s.Position))
 }
 
 func (s *UnknownShape) check() error {
-	return stacktrace.New("cannot check unknown shape", s.Location, stacktrace.WithPosition(&s.Position))
+	return stacktrace.New("cannot check unknown shape", s.Location(), stacktrace.WithPosition(&s.Position))
 }
 
 type RecursiveShape struct {
@@ -849,7 +1065,17 @@ func (s *RecursiveShape) clone(base *BaseShape, _ map[int64]*BaseShape) Shape {
 }
 
 func (s *RecursiveShape) validate(v interface{}, ctxPath string) error {
-	if err := s.Head.Shape.validate(v, ctxPath); err != nil {
+	return validateAt(s, v, ctxPath)
+}
+
+func (s *RecursiveShape) validateWithState(v interface{}, st *validationState) error {
+	st.depth++
+	defer func() { st.depth-- }()
+	if err := s.raml.checkRecursionDepth(st.path, st.depth); err != nil {
+		return err
+	}
+	st.note("recursive reference")
+	if err := validateValue(s.Head.Shape, v, st); err != nil {
 		return fmt.Errorf("validate recursive shape: %w", err)
 	}
 	return nil
@@ -857,7 +1083,7 @@ func (s *RecursiveShape) validate(v interface{}, ctxPath string) error {
 
 // Inherit merges the source shape into the target shape.
 func (s *RecursiveShape) inherit(_ Shape) (Shape, error) {
-	return nil, stacktrace.New("cannot inherit from recursive shape", s.Location, stacktrace.WithPosition(&s.Position))
+	return nil, stacktrace.New("cannot inherit from recursive shape", s.Location(), stacktrace.WithPosition(&s.Position))
 }
 
 func (s *RecursiveShape) check() error {
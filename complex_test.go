@@ -1,6 +1,8 @@
 package raml
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 
 	orderedmap "github.com/wk8/go-ordered-map/v2"
@@ -340,11 +342,11 @@ func TestArrayShape_Inherit(t *testing.T) {
 							},
 						},
 						MinItems: func() *uint64 {
-							i := uint64(3)
+							i := uint64(1)
 							return &i
 						}(),
 						MaxItems: func() *uint64 {
-							i := uint64(3)
+							i := uint64(5)
 							return &i
 						}(),
 						UniqueItems: func() *bool {
@@ -423,7 +425,7 @@ func TestArrayShape_Inherit(t *testing.T) {
 				BaseShape: &BaseShape{},
 				ArrayFacets: ArrayFacets{
 					MinItems: func() *uint64 {
-						i := uint64(2)
+						i := uint64(1)
 						return &i
 					}(),
 				},
@@ -433,7 +435,7 @@ func TestArrayShape_Inherit(t *testing.T) {
 					BaseShape: &BaseShape{},
 					ArrayFacets: ArrayFacets{
 						MinItems: func() *uint64 {
-							i := uint64(1)
+							i := uint64(2)
 							return &i
 						}(),
 					},
@@ -447,7 +449,7 @@ func TestArrayShape_Inherit(t *testing.T) {
 				BaseShape: &BaseShape{},
 				ArrayFacets: ArrayFacets{
 					MaxItems: func() *uint64 {
-						i := uint64(1)
+						i := uint64(2)
 						return &i
 					}(),
 				},
@@ -457,7 +459,7 @@ func TestArrayShape_Inherit(t *testing.T) {
 					BaseShape: &BaseShape{},
 					ArrayFacets: ArrayFacets{
 						MaxItems: func() *uint64 {
-							i := uint64(2)
+							i := uint64(1)
 							return &i
 						}(),
 					},
@@ -831,3 +833,121 @@ func TestArrayShape_unmarshalYAMLNodes(t *testing.T) {
 		})
 	}
 }
+
+func TestObjectShape_RestrictedPatternProperties(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Labels:
+    type: object
+    additionalProperties: false
+    properties:
+      name: string
+      /^x-/: integer
+`
+	if _, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithValidate()); err == nil {
+		t.Fatalf("ParseFromString() expected error for pattern properties with additionalProperties: false")
+	}
+
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithRestrictedPatternProperties(),
+		OptWithUnwrap(), OptWithValidate())
+	if err != nil {
+		t.Fatalf("ParseFromString() error = %v", err)
+	}
+	labels, _ := rml.EntryPoint().(*Library).Types.Get("Labels")
+	tests := []struct {
+		name    string
+		v       map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "explicit and pattern properties",
+			v:    map[string]interface{}{"name": "a", "x-count": 1},
+		},
+		{
+			name:    "invalid pattern property",
+			v:       map[string]interface{}{"name": "a", "x-count": "one"},
+			wantErr: true,
+		},
+		{
+			name:    "additional property",
+			v:       map[string]interface{}{"name": "a", "other": 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := labels.Validate(tt.v); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestObjectShape_validateRequiredProperties(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Pet:
+    type: object
+    properties:
+      name: string
+      kind: string
+      age?: integer
+      owner:
+        type: object
+        required: false
+        properties:
+          email: string
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	if err != nil {
+		t.Fatalf("ParseFromString() error = %v", err)
+	}
+	pet, _ := rml.EntryPoint().(*Library).Types.Get("Pet")
+	tests := []struct {
+		name    string
+		v       map[string]interface{}
+		wantErr *MissingPropertiesError
+	}{
+		{
+			name: "all required properties",
+			v:    map[string]interface{}{"name": "Rex", "kind": "dog"},
+		},
+		{
+			name:    "one missing property",
+			v:       map[string]interface{}{"name": "Rex", "age": 3},
+			wantErr: &MissingPropertiesError{Path: "$", Properties: []string{"kind"}},
+		},
+		{
+			name:    "all missing properties are listed",
+			v:       map[string]interface{}{},
+			wantErr: &MissingPropertiesError{Path: "$", Properties: []string{"name", "kind"}},
+		},
+		{
+			name:    "nested object",
+			v:       map[string]interface{}{"name": "Rex", "kind": "dog", "owner": map[string]interface{}{}},
+			wantErr: &MissingPropertiesError{Path: "$.owner", Properties: []string{"email"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := pet.Validate(tt.v)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			var missing *MissingPropertiesError
+			if !errors.As(err, &missing) {
+				t.Fatalf("Validate() error = %v, want MissingPropertiesError", err)
+			}
+			if !reflect.DeepEqual(missing, tt.wantErr) {
+				t.Errorf("Validate() error = %#v, want %#v", missing, tt.wantErr)
+			}
+		})
+	}
+	want := `missing required properties "name", "kind" at $`
+	if err := pet.Validate(map[string]interface{}{}); err == nil || err.Error() != want {
+		t.Errorf("Validate() error = %v, want %s", err, want)
+	}
+}
@@ -32,9 +32,13 @@ var SetOfArrayFacets = map[string]struct{}{
 	FacetItems: {}, FacetMinItems: {}, FacetMaxItems: {}, FacetUniqueItems: {},
 }
 
+// NumberFormatDecimal is the number format of arbitrary precision values. It is an extension of RAML formats, values
+// are not limited by the range of double and are expected to be decoded exactly, e.g. as json.Number.
+const NumberFormatDecimal = "decimal"
+
 // SetOfNumberFormats contains a set of number formats
 var SetOfNumberFormats = map[string]struct{}{
-	"float": {}, "double": {},
+	"float": {}, "double": {}, NumberFormatDecimal: {},
 }
 
 // SetOfIntegerFormats contains a set of integer formats
@@ -0,0 +1,62 @@
+package raml
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// context returns the context the RAML was created with. Parsing, reparsing and reading of included fragments honor
+// this context, as well as Link, Resolve and Check unless a context is passed explicitly.
+func (r *RAML) context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// canceledTrace returns the error of ctx as a stacktrace of the operation at location if ctx is canceled or its
+// deadline is exceeded.
+func canceledTrace(ctx context.Context, location string, errType stacktrace.Type) *stacktrace.StackTrace {
+	if err := ctx.Err(); err != nil {
+		return StacktraceNewWrapped("aborted", err, location, stacktrace.WithType(errType))
+	}
+	return nil
+}
+
+// abortErr replaces the error of the operation with the wrapped error of ctx if ctx is done, so callers can check
+// the cause with errors.Is regardless of where the operation was interrupted.
+func abortErr(ctx context.Context, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if cerr := ctx.Err(); cerr != nil {
+		return fmt.Errorf("%s: %w", op, cerr)
+	}
+	return err
+}
+
+// LinkCtx is like Link, but aborts when ctx is done.
+func (r *RAML) LinkCtx(ctx context.Context) error {
+	if ctx == nil {
+		return fmt.Errorf("context is nil")
+	}
+	return r.link(ctx)
+}
+
+// ResolveCtx is like Resolve, but aborts when ctx is done.
+func (r *RAML) ResolveCtx(ctx context.Context) error {
+	if ctx == nil {
+		return fmt.Errorf("context is nil")
+	}
+	return r.resolve(ctx)
+}
+
+// CheckCtx is like Check, but aborts when ctx is done.
+func (r *RAML) CheckCtx(ctx context.Context) error {
+	if ctx == nil {
+		return fmt.Errorf("context is nil")
+	}
+	return r.check(ctx)
+}
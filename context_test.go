@@ -0,0 +1,68 @@
+package raml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextCancellation(t *testing.T) {
+	content := `#%RAML 1.0 Library
+annotationTypes:
+  tag: string
+types:
+  A:
+    (tag): a
+    type: string
+  B:
+    (tag): b
+    type: A
+  C:
+    (tag): c
+    type: B
+`
+	dir := t.TempDir()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ParseFromStringCtx(canceled, content, "library.raml", dir)
+	require.ErrorIs(t, err, context.Canceled)
+
+	for name, opts := range map[string][]ParseOpt{
+		"sequential": nil,
+		"parallel":   {OptWithParallelResolution(2)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, err := ParseFromString(content, "library.raml", dir, append(opts, OptWithStopAfter(StageParsed))...)
+			require.NoError(t, err)
+			require.ErrorIs(t, r.LinkCtx(canceled), context.Canceled)
+			require.ErrorIs(t, r.ResolveCtx(canceled), context.Canceled)
+			require.Equal(t, StageParsed, r.Stage())
+
+			// The context of the model is restored after the call.
+			require.NoError(t, r.LinkCtx(context.Background()))
+			require.ErrorIs(t, r.ResolveCtx(canceled), context.Canceled)
+			require.Equal(t, StageLinked, r.Stage())
+		})
+	}
+
+	// Validation is aborted in the middle once the context is canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	r, err := ParseFromStringCtx(ctx, content, "library.raml", dir,
+		OptWithFacetValidator("tag", func(Shape, *Node) error {
+			calls++
+			cancel()
+			return nil
+		}))
+	require.NoError(t, err)
+	err = r.Check()
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, calls)
+	require.NoError(t, r.CheckCtx(context.Background()))
+
+	_, err = ParseFromStringCtx(ctx, content, "library.raml", dir)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,379 @@
+// Package contract replays recorded HTTP traffic against an API definition: requests and responses of HAR files are
+// validated against operations of the API, and the report shows which operations, status codes and types were
+// exercised.
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/acronis/go-raml"
+	"github.com/acronis/go-raml/httpvalidate"
+)
+
+type Opt interface {
+	Apply(*Options)
+}
+
+type Options struct {
+	basePath    *string
+	maxBodySize int64
+	tracker     *Tracker
+}
+
+type optBasePath struct {
+	path string
+}
+
+func (o optBasePath) Apply(opts *Options) {
+	opts.basePath = &o.path
+}
+
+// WithBasePath sets the path of the base URI that is trimmed from recorded URLs, e.g. "/api/v1". By default, it is
+// the path of the baseUri of the API with {version} substituted.
+func WithBasePath(path string) Opt {
+	return optBasePath{path: path}
+}
+
+type optMaxBodySize struct {
+	size int64
+}
+
+func (o optMaxBodySize) Apply(opts *Options) {
+	opts.maxBodySize = o.size
+}
+
+// WithMaxBodySize limits the size of request bodies that are validated, see httpvalidate.WithMaxBodySize.
+func WithMaxBodySize(size int64) Opt {
+	return optMaxBodySize{size: size}
+}
+
+type optTracker struct {
+	tracker *Tracker
+}
+
+func (o optTracker) Apply(opts *Options) {
+	opts.tracker = o.tracker
+}
+
+// WithTracker feeds replayed entries to the tracker, so its report includes type branches that the traffic did not
+// exercise.
+func WithTracker(t *Tracker) Opt {
+	return optTracker{tracker: t}
+}
+
+// Replayer validates recorded traffic against the API.
+type Replayer struct {
+	api       *raml.API
+	router    *raml.Router
+	validator *httpvalidate.Validator
+	tracker   *Tracker
+	basePath  string
+}
+
+// New returns a replayer of the API. The API must be parsed with raml.OptWithUnwrap.
+func New(api *raml.API, opts ...Opt) (*Replayer, error) {
+	var o Options
+	for _, opt := range opts {
+		opt.Apply(&o)
+	}
+	router, err := raml.NewRouter(api)
+	if err != nil {
+		return nil, fmt.Errorf("make router: %w", err)
+	}
+	var validatorOpts []httpvalidate.Opt
+	if o.maxBodySize > 0 {
+		validatorOpts = append(validatorOpts, httpvalidate.WithMaxBodySize(o.maxBodySize))
+	}
+	r := &Replayer{
+		api:    api,
+		router: router,
+		// Operations are matched by the replayer, so the validator does not resolve requests.
+		validator: httpvalidate.New(nil, validatorOpts...),
+		tracker:   o.tracker,
+		basePath:  defaultBasePath(api),
+	}
+	if o.basePath != nil {
+		r.basePath = *o.basePath
+	}
+	r.basePath = strings.TrimSuffix(r.basePath, "/")
+	return r, nil
+}
+
+// defaultBasePath returns the path of the base URI, e.g. "/api/v1" for "https://{host}/api/{version}".
+func defaultBasePath(api *raml.API) string {
+	uri := api.BaseURI
+	if _, rest, ok := strings.Cut(uri, "://"); ok {
+		i := strings.Index(rest, "/")
+		if i < 0 {
+			return ""
+		}
+		uri = rest[i:]
+	}
+	return strings.ReplaceAll(uri, "{version}", api.Version)
+}
+
+// Report contains results of replayed entries and the coverage of the API.
+type Report struct {
+	Entries  []EntryResult `json:"entries"`
+	Coverage Coverage      `json:"coverage"`
+}
+
+// EntryResult is the result of a replayed entry.
+type EntryResult struct {
+	// Index is the index of the entry in the HAR file.
+	Index  int    `json:"index"`
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Operation is the matched operation, e.g. "GET /pets/{id}", empty if the entry is not matched.
+	Operation string                   `json:"operation,omitempty"`
+	Status    int                      `json:"status"`
+	Request   []httpvalidate.Violation `json:"request,omitempty"`
+	Response  []httpvalidate.Violation `json:"response,omitempty"`
+	// Error is set if the entry cannot be replayed or matched to an operation.
+	Error string `json:"error,omitempty"`
+}
+
+// Passed reports whether the entry is matched and both the request and the response are valid.
+func (e *EntryResult) Passed() bool {
+	return e.Error == "" && len(e.Request) == 0 && len(e.Response) == 0
+}
+
+// Coverage shows which operations, status codes and declared types were exercised by the traffic.
+type Coverage struct {
+	Operations []OperationCoverage `json:"operations"`
+	Types      []TypeCoverage      `json:"types"`
+}
+
+// OperationCoverage counts calls of the operation. Statuses contain declared status codes in the order of
+// declaration followed by undeclared ones in the order they were seen.
+type OperationCoverage struct {
+	Method   string           `json:"method"`
+	FullPath string           `json:"fullPath"`
+	Calls    int              `json:"calls"`
+	Statuses []StatusCoverage `json:"statuses"`
+}
+
+type StatusCoverage struct {
+	Status   int  `json:"status"`
+	Declared bool `json:"declared"`
+	Calls    int  `json:"calls"`
+}
+
+// TypeCoverage counts request and response bodies of the declared type, including arrays of it.
+type TypeCoverage struct {
+	Name  string `json:"name"`
+	Calls int    `json:"calls"`
+}
+
+// Passed reports whether all entries passed.
+func (r *Report) Passed() bool {
+	for i := range r.Entries {
+		if !r.Entries[i].Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Replay validates entries of the HAR file in order. Entries that cannot be replayed or matched to operations are
+// reported with errors in their results.
+func (r *Replayer) Replay(h *HAR) *Report {
+	ops := orderedmap.New[string, *OperationCoverage](0)
+	for _, op := range r.api.Operations() {
+		oc := &OperationCoverage{Method: op.Method, FullPath: op.FullPath}
+		for pair := op.Responses.Oldest(); pair != nil; pair = pair.Next() {
+			oc.Statuses = append(oc.Statuses, StatusCoverage{Status: pair.Key, Declared: true})
+		}
+		ops.Set(operationName(op), oc)
+	}
+	types := orderedmap.New[string, *TypeCoverage](0)
+	for pair := r.api.Library.Types.Oldest(); pair != nil; pair = pair.Next() {
+		types.Set(pair.Key, &TypeCoverage{Name: pair.Key})
+	}
+
+	report := &Report{Entries: make([]EntryResult, 0, len(h.Log.Entries))}
+	for i := range h.Log.Entries {
+		entry := &h.Log.Entries[i]
+		res := EntryResult{
+			Index:  i,
+			Method: entry.Request.Method,
+			URL:    entry.Request.URL,
+			Status: entry.Response.Status,
+		}
+		op, err := r.replay(entry, &res, types)
+		if err != nil {
+			res.Error = err.Error()
+		}
+		if op != nil {
+			oc, _ := ops.Get(res.Operation)
+			oc.Calls++
+			oc.countStatus(res.Status)
+		}
+		report.Entries = append(report.Entries, res)
+	}
+
+	for pair := ops.Oldest(); pair != nil; pair = pair.Next() {
+		report.Coverage.Operations = append(report.Coverage.Operations, *pair.Value)
+	}
+	for pair := types.Oldest(); pair != nil; pair = pair.Next() {
+		report.Coverage.Types = append(report.Coverage.Types, *pair.Value)
+	}
+	return report
+}
+
+func operationName(op *raml.Operation) string {
+	return op.Method + " " + op.FullPath
+}
+
+func (oc *OperationCoverage) countStatus(status int) {
+	for i := range oc.Statuses {
+		if oc.Statuses[i].Status == status {
+			oc.Statuses[i].Calls++
+			return
+		}
+	}
+	oc.Statuses = append(oc.Statuses, StatusCoverage{Status: status, Calls: 1})
+}
+
+// replay matches the entry to an operation and validates it. The matched operation is returned even if
+// the entry cannot be validated.
+func (r *Replayer) replay(
+	entry *HAREntry, res *EntryResult, types *orderedmap.OrderedMap[string, *TypeCoverage],
+) (*raml.Operation, error) {
+	req, err := entry.Request.httpRequest()
+	if err != nil {
+		return nil, err
+	}
+	path, ok := strings.CutPrefix(req.URL.EscapedPath(), r.basePath)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is outside of the base path %s", raml.ErrRouteNotFound, req.URL.Path,
+			r.basePath)
+	}
+	// Match unescapes values of URI parameters itself.
+	op, params, err := r.router.MatchOperation(req.Method, path)
+	if op == nil {
+		return nil, err
+	}
+	res.Operation = operationName(op)
+	if r.tracker != nil {
+		r.tracker.Observe(entry.observation(req, path))
+	}
+
+	vop := httpvalidate.FromOperation(op, r.api.MediaType)
+	if err := r.validator.ValidateRequest(req, vop, params); err != nil {
+		var verr *httpvalidate.Error
+		if !errors.As(err, &verr) {
+			return op, err
+		}
+		res.Request = verr.Violations
+	}
+	countType(types, op.Body, r.api.MediaType, req.Header.Get("Content-Type"))
+
+	body, err := entry.Response.body()
+	if err != nil {
+		return op, err
+	}
+	header := entry.Response.header()
+	if verr := r.validator.ValidateResponse(vop, entry.Response.Status, header, body); verr != nil {
+		res.Response = verr.Violations
+	}
+	if resp, ok := op.Responses.Get(entry.Response.Status); ok {
+		countType(types, resp.Body, r.api.MediaType, header.Get("Content-Type"))
+	}
+	return op, nil
+}
+
+// countType counts the declared type of the body selected for the content type. Bodies of other types, e.g. inline
+// declarations, are not counted.
+func countType(
+	types *orderedmap.OrderedMap[string, *TypeCoverage], bodies *orderedmap.OrderedMap[string, *raml.BaseShape],
+	defaultMediaTypes []string, contentType string,
+) {
+	if bodies.Len() == 0 {
+		return
+	}
+	shape, _, err := raml.SelectBody(bodies, defaultMediaTypes, contentType)
+	if err != nil {
+		return
+	}
+	if arr, ok := shape.Shape.(*raml.ArrayShape); ok && arr.Items != nil {
+		shape = arr.Items
+	}
+	if tc, ok := types.Get(shape.TypeLabel); ok {
+		tc.Calls++
+	}
+}
+
+// WriteText writes failed entries and the coverage in a human-readable form.
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for i := range r.Entries {
+		e := &r.Entries[i]
+		if e.Passed() {
+			continue
+		}
+		fmt.Fprintf(&b, "entry %d: %s %s -> %d\n", e.Index, e.Method, redactQuery(e.URL), e.Status)
+		if e.Error != "" {
+			fmt.Fprintf(&b, "  error: %s\n", e.Error)
+		}
+		for _, v := range e.Request {
+			fmt.Fprintf(&b, "  request: %s\n", violationText(v))
+		}
+		for _, v := range e.Response {
+			fmt.Fprintf(&b, "  response: %s\n", violationText(v))
+		}
+	}
+	var exercised int
+	for _, oc := range r.Coverage.Operations {
+		if oc.Calls > 0 {
+			exercised++
+		}
+	}
+	fmt.Fprintf(&b, "operations: %d/%d exercised\n", exercised, len(r.Coverage.Operations))
+	for _, oc := range r.Coverage.Operations {
+		fmt.Fprintf(&b, "  %s %s: %d calls", oc.Method, oc.FullPath, oc.Calls)
+		for _, sc := range oc.Statuses {
+			mark := ""
+			if !sc.Declared {
+				mark = " (undeclared)"
+			}
+			fmt.Fprintf(&b, ", %d: %d%s", sc.Status, sc.Calls, mark)
+		}
+		b.WriteString("\n")
+	}
+	exercised = 0
+	for _, tc := range r.Coverage.Types {
+		if tc.Calls > 0 {
+			exercised++
+		}
+	}
+	fmt.Fprintf(&b, "types: %d/%d exercised\n", exercised, len(r.Coverage.Types))
+	for _, tc := range r.Coverage.Types {
+		fmt.Fprintf(&b, "  %s: %d calls\n", tc.Name, tc.Calls)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func violationText(v httpvalidate.Violation) string {
+	if v.Name != "" {
+		return fmt.Sprintf("%s %s: %s", v.In, v.Name, v.Message)
+	}
+	return fmt.Sprintf("%s: %s", v.In, v.Message)
+}
+
+// redactQuery drops the query of the URL, since recorded queries may contain credentials.
+func redactQuery(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.RawQuery = ""
+	return u.String()
+}
@@ -0,0 +1,135 @@
+package contract
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-raml"
+)
+
+const testAPI = `#%RAML 1.0
+title: Pets
+version: v1
+baseUri: https://{host}/api/{version}
+mediaType: application/json
+types:
+  Pet:
+    properties:
+      name:
+        type: string
+        minLength: 3
+  Owner:
+    properties:
+      name: string
+/pets:
+  get:
+    queryParameters:
+      limit:
+        type: integer
+        required: false
+    responses:
+      200:
+        body: Pet[]
+  post:
+    body: Pet
+    responses:
+      201:
+        body: Pet
+      400:
+/owners/{id}:
+  get:
+    responses:
+      200:
+        body: Owner
+`
+
+const testHAR = `{"log": {"entries": [
+  {
+    "request": {"method": "GET", "url": "https://example.com/api/v1/pets?limit=10", "headers": []},
+    "response": {"status": 200, "headers": [{"name": "Content-Type", "value": "application/json"}],
+      "content": {"mimeType": "application/json", "text": "[{\"name\": \"Rex\"}]"}}
+  },
+  {
+    "request": {"method": "GET", "url": "https://example.com/api/v1/pets?limit=ten", "headers": []},
+    "response": {"status": 200, "headers": [],
+      "content": {"mimeType": "application/json", "encoding": "base64", "text": "W3sibmFtZSI6ICJSIn1d"}}
+  },
+  {
+    "request": {"method": "POST", "url": "https://example.com/api/v1/pets",
+      "headers": [{"name": ":authority", "value": "example.com"}],
+      "postData": {"mimeType": "application/json", "text": "{\"name\": \"Rex\"}"}},
+    "response": {"status": 409, "headers": [], "content": {"mimeType": "application/json", "text": "{}"}}
+  },
+  {
+    "request": {"method": "DELETE", "url": "https://example.com/api/v1/pets", "headers": []},
+    "response": {"status": 204, "headers": [], "content": {}}
+  }
+]}}`
+
+func TestReplayer_Replay(t *testing.T) {
+	rml, err := raml.ParseFromString(testAPI, "api.raml", t.TempDir(), raml.OptWithUnwrap())
+	require.NoError(t, err)
+	h, err := ReadHAR(strings.NewReader(testHAR))
+	require.NoError(t, err)
+	r, err := New(rml.API())
+	require.NoError(t, err)
+
+	report := r.Replay(h)
+	require.False(t, report.Passed())
+	require.Len(t, report.Entries, 4)
+
+	require.True(t, report.Entries[0].Passed())
+	require.Equal(t, "GET /pets", report.Entries[0].Operation)
+
+	second := report.Entries[1]
+	require.Len(t, second.Request, 1)
+	require.Equal(t, "limit", second.Request[0].Name)
+	require.Len(t, second.Response, 1)
+	require.Contains(t, second.Response[0].Message, "length must be greater than 3")
+
+	third := report.Entries[2]
+	require.Empty(t, third.Request)
+	require.Equal(t, "status 409 is not declared", third.Response[0].Message)
+
+	require.Equal(t, "", report.Entries[3].Operation)
+	require.Contains(t, report.Entries[3].Error, "method not allowed")
+
+	require.Equal(t, []OperationCoverage{
+		{Method: "GET", FullPath: "/pets", Calls: 2, Statuses: []StatusCoverage{{Status: 200, Declared: true, Calls: 2}}},
+		{Method: "POST", FullPath: "/pets", Calls: 1, Statuses: []StatusCoverage{
+			{Status: 201, Declared: true}, {Status: 400, Declared: true}, {Status: 409, Calls: 1},
+		}},
+		{Method: "GET", FullPath: "/owners/{id}", Statuses: []StatusCoverage{{Status: 200, Declared: true}}},
+	}, report.Coverage.Operations)
+	require.Equal(t, []TypeCoverage{{Name: "Pet", Calls: 3}, {Name: "Owner"}}, report.Coverage.Types)
+
+	var b bytes.Buffer
+	require.NoError(t, report.WriteText(&b))
+	require.Contains(t, b.String(), "entry 1: GET https://example.com/api/v1/pets -> 200\n")
+	require.Contains(t, b.String(), "operations: 2/3 exercised\n")
+	require.Contains(t, b.String(), "  POST /pets: 1 calls, 201: 0, 400: 0, 409: 1 (undeclared)\n")
+	require.Contains(t, b.String(), "types: 1/2 exercised\n")
+}
+
+func TestReplayer_basePath(t *testing.T) {
+	rml, err := raml.ParseFromString(testAPI, "api.raml", t.TempDir(), raml.OptWithUnwrap())
+	require.NoError(t, err)
+	h := &HAR{Log: HARLog{Entries: []HAREntry{{
+		Request:  HARRequest{Method: "GET", URL: "http://localhost:8080/owners/1"},
+		Response: HARResponse{Status: 200, Content: HARContent{MimeType: "application/json", Text: `{"name":"Ann"}`}},
+	}}}}
+
+	r, err := New(rml.API())
+	require.NoError(t, err)
+	report := r.Replay(h)
+	require.Contains(t, report.Entries[0].Error, "outside of the base path /api/v1")
+
+	r, err = New(rml.API(), WithBasePath(""))
+	require.NoError(t, err)
+	report = r.Replay(h)
+	require.True(t, report.Passed())
+	require.Equal(t, "GET /owners/{id}", report.Entries[0].Operation)
+}
@@ -0,0 +1,303 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/acronis/go-raml"
+)
+
+// BranchKind is the kind of a type branch.
+type BranchKind string
+
+const (
+	// BranchProperty is an optional property that is exercised if a body contains it.
+	BranchProperty BranchKind = "property"
+	// BranchUnionMember is a member of a union that is exercised if a body validates against it.
+	BranchUnionMember BranchKind = "union member"
+)
+
+// Branch is a part of a type that bodies may or may not exercise.
+type Branch struct {
+	Kind BranchKind `json:"kind"`
+	// Type is the declared type the branch belongs to, e.g. "Pet", or the path to an inline type from the body, e.g.
+	// "GET /pets 200 application/json[].owner".
+	Type string `json:"type"`
+	// Name is the name of the property or the member, e.g. "Cat" or "string".
+	Name string `json:"name"`
+}
+
+func (b Branch) String() string {
+	return fmt.Sprintf("%s %s of %s", b.Kind, b.Name, b.Type)
+}
+
+// Observation is an exercised request and its response, e.g. recorded by tests or middleware. Bodies are
+// optional, only JSON bodies are inspected for type branches.
+type Observation struct {
+	Method string
+	// Path is the path relative to the base URI, e.g. "/pets/1".
+	Path string
+	// Status is the status code of the response, 0 if the response is not observed.
+	Status              int
+	RequestContentType  string
+	RequestBody         []byte
+	ResponseContentType string
+	ResponseBody        []byte
+}
+
+// Tracker collects observations and reports parts of the API that were never exercised: operations, declared
+// responses, optional properties and union members of bodies. It is safe for concurrent use.
+type Tracker struct {
+	api    *raml.API
+	router *raml.Router
+	// declared contains names of declared types, which label branches instead of paths from bodies.
+	declared map[string]struct{}
+
+	mu         sync.Mutex
+	operations *orderedmap.OrderedMap[string, bool]
+	responses  *orderedmap.OrderedMap[string, bool]
+	branches   *orderedmap.OrderedMap[Branch, bool]
+	unmatched  int
+}
+
+// NewTracker returns a tracker of the API. The API must be parsed with raml.OptWithUnwrap.
+func NewTracker(api *raml.API) (*Tracker, error) {
+	router, err := raml.NewRouter(api)
+	if err != nil {
+		return nil, fmt.Errorf("make router: %w", err)
+	}
+	t := &Tracker{
+		api:        api,
+		router:     router,
+		declared:   make(map[string]struct{}, api.Library.Types.Len()),
+		operations: orderedmap.New[string, bool](0),
+		responses:  orderedmap.New[string, bool](0),
+		branches:   orderedmap.New[Branch, bool](0),
+	}
+	for pair := api.Library.Types.Oldest(); pair != nil; pair = pair.Next() {
+		t.declared[pair.Key] = struct{}{}
+	}
+	for _, op := range api.Operations() {
+		name := operationName(op)
+		t.operations.Set(name, false)
+		for pair := op.Body.Oldest(); pair != nil; pair = pair.Next() {
+			t.collectBranches(pair.Value, bodyLabel(name, "request", pair.Key), make(map[string]struct{}))
+		}
+		for pair := op.Responses.Oldest(); pair != nil; pair = pair.Next() {
+			t.responses.Set(responseName(op, pair.Key), false)
+			for body := pair.Value.Body.Oldest(); body != nil; body = body.Next() {
+				t.collectBranches(body.Value, bodyLabel(name, strconv.Itoa(pair.Key), body.Key),
+					make(map[string]struct{}))
+			}
+		}
+	}
+	return t, nil
+}
+
+func responseName(op *raml.Operation, status int) string {
+	return fmt.Sprintf("%s %d", operationName(op), status)
+}
+
+func bodyLabel(operation string, part string, mediaType string) string {
+	label := operation + " " + part
+	if mediaType != "" {
+		label += " " + mediaType
+	}
+	return label
+}
+
+// label returns the name of the declared type of the shape or the path to the shape.
+func (t *Tracker) label(shape *raml.BaseShape, path string) string {
+	if _, ok := t.declared[shape.TypeLabel]; ok {
+		return shape.TypeLabel
+	}
+	return path
+}
+
+func memberName(member *raml.BaseShape) string {
+	if member.TypeLabel != "" {
+		return member.TypeLabel
+	}
+	return member.Type
+}
+
+// collectBranches registers branches of the shape. Seen contains labels of visited types to stop at recursive types.
+func (t *Tracker) collectBranches(shape *raml.BaseShape, path string, seen map[string]struct{}) {
+	label := t.label(shape, path)
+	if _, ok := seen[label]; ok {
+		return
+	}
+	seen[label] = struct{}{}
+	defer delete(seen, label)
+
+	switch s := shape.Shape.(type) {
+	case *raml.ObjectShape:
+		if s.Properties == nil {
+			return
+		}
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			if !pair.Value.Required {
+				b := Branch{Kind: BranchProperty, Type: label, Name: pair.Key}
+				if _, ok := t.branches.Get(b); !ok {
+					t.branches.Set(b, false)
+				}
+			}
+			t.collectBranches(pair.Value.Shape, label+"."+pair.Key, seen)
+		}
+	case *raml.ArrayShape:
+		if s.Items != nil {
+			t.collectBranches(s.Items, label+"[]", seen)
+		}
+	case *raml.UnionShape:
+		for _, member := range s.AnyOf {
+			b := Branch{Kind: BranchUnionMember, Type: label, Name: memberName(member)}
+			if _, ok := t.branches.Get(b); !ok {
+				t.branches.Set(b, false)
+			}
+			t.collectBranches(member, label+"|"+memberName(member), seen)
+		}
+	}
+}
+
+// markBranches marks branches of the shape exercised by the value.
+func (t *Tracker) markBranches(shape *raml.BaseShape, value any, path string) {
+	label := t.label(shape, path)
+	switch s := shape.Shape.(type) {
+	case *raml.ObjectShape:
+		obj, ok := value.(map[string]any)
+		if !ok || s.Properties == nil {
+			return
+		}
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			v, ok := obj[pair.Key]
+			if !ok {
+				continue
+			}
+			if !pair.Value.Required {
+				t.branches.Set(Branch{Kind: BranchProperty, Type: label, Name: pair.Key}, true)
+			}
+			t.markBranches(pair.Value.Shape, v, label+"."+pair.Key)
+		}
+	case *raml.ArrayShape:
+		items, ok := value.([]any)
+		if !ok || s.Items == nil {
+			return
+		}
+		for _, item := range items {
+			t.markBranches(s.Items, item, label+"[]")
+		}
+	case *raml.UnionShape:
+		// The value exercises the first member it is valid against, as validation of unions does.
+		for _, member := range s.AnyOf {
+			if member.Validate(value) == nil {
+				t.branches.Set(Branch{Kind: BranchUnionMember, Type: label, Name: memberName(member)}, true)
+				t.markBranches(member, value, label+"|"+memberName(member))
+				return
+			}
+		}
+	}
+}
+
+// Observe records the observation. Observations that match no operation are counted as unmatched.
+func (t *Tracker) Observe(o Observation) {
+	op, _, _ := t.router.MatchOperation(o.Method, o.Path)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if op == nil {
+		t.unmatched++
+		return
+	}
+	name := operationName(op)
+	t.operations.Set(name, true)
+	t.observeBody(op.Body, bodyLabel(name, "request", ""), o.RequestContentType, o.RequestBody)
+	if o.Status == 0 {
+		return
+	}
+	resp, ok := op.Responses.Get(o.Status)
+	if !ok {
+		return
+	}
+	t.responses.Set(responseName(op, o.Status), true)
+	t.observeBody(resp.Body, bodyLabel(name, strconv.Itoa(o.Status), ""), o.ResponseContentType, o.ResponseBody)
+}
+
+func (t *Tracker) observeBody(
+	bodies *orderedmap.OrderedMap[string, *raml.BaseShape], label string, contentType string, body []byte,
+) {
+	if len(body) == 0 || bodies.Len() == 0 {
+		return
+	}
+	shape, mediaType, err := raml.SelectBody(bodies, t.api.MediaType, contentType)
+	if err != nil || !isJSON(contentType) {
+		return
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return
+	}
+	if _, declared := bodies.Get(mediaType); declared && mediaType != "" {
+		label += " " + mediaType
+	}
+	t.markBranches(shape, value, label)
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// CoverageReport lists parts of the API that were never exercised. Totals count all operations, declared responses
+// and branches.
+type CoverageReport struct {
+	UnexercisedOperations []string `json:"unexercisedOperations"`
+	UnexercisedResponses  []string `json:"unexercisedResponses"`
+	UnexercisedBranches   []Branch `json:"unexercisedBranches"`
+	TotalOperations       int      `json:"totalOperations"`
+	TotalResponses        int      `json:"totalResponses"`
+	TotalBranches         int      `json:"totalBranches"`
+	// Unmatched is the number of observations that matched no operation.
+	Unmatched int `json:"unmatched"`
+}
+
+// Report returns parts of the API that were not exercised by the observations so far. Operations and responses are
+// in the order of declaration, branches are sorted by type and name.
+func (t *Tracker) Report() *CoverageReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r := &CoverageReport{
+		TotalOperations: t.operations.Len(),
+		TotalResponses:  t.responses.Len(),
+		TotalBranches:   t.branches.Len(),
+		Unmatched:       t.unmatched,
+	}
+	for pair := t.operations.Oldest(); pair != nil; pair = pair.Next() {
+		if !pair.Value {
+			r.UnexercisedOperations = append(r.UnexercisedOperations, pair.Key)
+		}
+	}
+	for pair := t.responses.Oldest(); pair != nil; pair = pair.Next() {
+		if !pair.Value {
+			r.UnexercisedResponses = append(r.UnexercisedResponses, pair.Key)
+		}
+	}
+	for pair := t.branches.Oldest(); pair != nil; pair = pair.Next() {
+		if !pair.Value {
+			r.UnexercisedBranches = append(r.UnexercisedBranches, pair.Key)
+		}
+	}
+	sort.SliceStable(r.UnexercisedBranches, func(i, j int) bool {
+		a, b := r.UnexercisedBranches[i], r.UnexercisedBranches[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Name < b.Name
+	})
+	return r
+}
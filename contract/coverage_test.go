@@ -0,0 +1,98 @@
+package contract
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-raml"
+)
+
+func TestTracker(t *testing.T) {
+	content := `#%RAML 1.0
+title: Zoo
+mediaType: application/json
+types:
+  Cat:
+    properties:
+      meows: boolean
+      color?: string
+  Dog:
+    properties:
+      barks: boolean
+  Animal: Cat | Dog
+  Pen:
+    properties:
+      name: string
+      animals?: Animal[]
+      keeper?:
+        properties:
+          name: string
+          phone?: string
+/pens:
+  get:
+    responses:
+      200:
+        body: Pen[]
+  post:
+    body: Pen
+    responses:
+      201:
+      409:
+/pens/{id}:
+  delete:
+`
+	rml, err := raml.ParseFromString(content, "api.raml", t.TempDir(), raml.OptWithUnwrap())
+	require.NoError(t, err)
+	tracker, err := NewTracker(rml.API())
+	require.NoError(t, err)
+
+	report := tracker.Report()
+	require.Equal(t, 3, report.TotalOperations)
+	require.Equal(t, 3, report.TotalResponses)
+	require.Equal(t, []Branch{
+		{Kind: BranchUnionMember, Type: "Animal", Name: "Cat"},
+		{Kind: BranchUnionMember, Type: "Animal", Name: "Dog"},
+		{Kind: BranchProperty, Type: "Cat", Name: "color"},
+		{Kind: BranchProperty, Type: "Pen", Name: "animals"},
+		{Kind: BranchProperty, Type: "Pen", Name: "keeper"},
+		{Kind: BranchProperty, Type: "Pen.keeper", Name: "phone"},
+	}, report.UnexercisedBranches)
+
+	tracker.Observe(Observation{
+		Method: "GET", Path: "/pens", Status: 200, ResponseContentType: "application/json",
+		ResponseBody: []byte(`[{"name": "A", "animals": [{"meows": true}], "keeper": {"name": "Bob"}}]`),
+	})
+	tracker.Observe(Observation{
+		Method: "POST", Path: "/pens", Status: 201, RequestContentType: "application/json",
+		RequestBody: []byte(`{"name": "B", "animals": [{"meows": false, "color": "black"}]}`),
+	})
+	tracker.Observe(Observation{Method: "GET", Path: "/owners"})
+
+	report = tracker.Report()
+	require.Equal(t, []string{"DELETE /pens/{id}"}, report.UnexercisedOperations)
+	require.Equal(t, []string{"POST /pens 409"}, report.UnexercisedResponses)
+	require.Equal(t, []Branch{
+		{Kind: BranchUnionMember, Type: "Animal", Name: "Dog"},
+		{Kind: BranchProperty, Type: "Pen.keeper", Name: "phone"},
+	}, report.UnexercisedBranches)
+	require.Equal(t, 1, report.Unmatched)
+	require.Equal(t, "union member Dog of Animal", report.UnexercisedBranches[0].String())
+}
+
+func TestReplayer_WithTracker(t *testing.T) {
+	rml, err := raml.ParseFromString(testAPI, "api.raml", t.TempDir(), raml.OptWithUnwrap())
+	require.NoError(t, err)
+	tracker, err := NewTracker(rml.API())
+	require.NoError(t, err)
+	h, err := ReadHAR(strings.NewReader(testHAR))
+	require.NoError(t, err)
+	r, err := New(rml.API(), WithTracker(tracker))
+	require.NoError(t, err)
+	r.Replay(h)
+
+	report := tracker.Report()
+	require.Equal(t, []string{"GET /owners/{id}"}, report.UnexercisedOperations)
+	require.Equal(t, []string{"POST /pets 201", "POST /pets 400", "GET /owners/{id} 200"}, report.UnexercisedResponses)
+}
@@ -0,0 +1,140 @@
+package contract
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// HAR is an HTTP Archive, e.g. exported by browsers or recorded by proxies. Only the fields needed to replay
+// requests and responses are decoded.
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Entries []HAREntry `json:"entries"`
+}
+
+// HAREntry is a recorded request and its response.
+type HAREntry struct {
+	Request  HARRequest  `json:"request"`
+	Response HARResponse `json:"response"`
+}
+
+type HARRequest struct {
+	Method   string         `json:"method"`
+	URL      string         `json:"url"`
+	Headers  []HARNameValue `json:"headers"`
+	PostData *HARPostData   `json:"postData,omitempty"`
+}
+
+type HARResponse struct {
+	Status  int            `json:"status"`
+	Headers []HARNameValue `json:"headers"`
+	Content HARContent     `json:"content"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData is the body of a request. Params are fields of urlencoded forms recorded without Text.
+type HARPostData struct {
+	MimeType string         `json:"mimeType"`
+	Text     string         `json:"text"`
+	Params   []HARNameValue `json:"params,omitempty"`
+}
+
+// HARContent is the body of a response. Text is base64 encoded if Encoding is "base64".
+type HARContent struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// ReadHAR decodes the HTTP Archive.
+func ReadHAR(r io.Reader) (*HAR, error) {
+	var h HAR
+	if err := json.NewDecoder(r).Decode(&h); err != nil {
+		return nil, fmt.Errorf("decode har: %w", err)
+	}
+	return &h, nil
+}
+
+// httpRequest returns the recorded request as *http.Request.
+func (r *HARRequest) httpRequest() (*http.Request, error) {
+	var body string
+	if r.PostData != nil {
+		body = r.PostData.Text
+		if body == "" && len(r.PostData.Params) > 0 {
+			form := url.Values{}
+			for _, p := range r.PostData.Params {
+				form.Add(p.Name, p.Value)
+			}
+			body = form.Encode()
+		}
+	}
+	req, err := http.NewRequest(r.Method, r.URL, strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("make request: %w", err)
+	}
+	for _, h := range r.Headers {
+		// HTTP/2 pseudo-headers, e.g. ":authority", are not headers of the request.
+		if !strings.HasPrefix(h.Name, ":") {
+			req.Header.Add(h.Name, h.Value)
+		}
+	}
+	if r.PostData != nil && r.PostData.MimeType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", r.PostData.MimeType)
+	}
+	return req, nil
+}
+
+// header returns headers of the recorded response.
+func (r *HARResponse) header() http.Header {
+	header := make(http.Header, len(r.Headers))
+	for _, h := range r.Headers {
+		if !strings.HasPrefix(h.Name, ":") {
+			header.Add(h.Name, h.Value)
+		}
+	}
+	if r.Content.MimeType != "" && header.Get("Content-Type") == "" {
+		header.Set("Content-Type", r.Content.MimeType)
+	}
+	return header
+}
+
+// body returns the decoded body of the recorded response.
+func (r *HARResponse) body() ([]byte, error) {
+	if r.Content.Encoding != "base64" {
+		return []byte(r.Content.Text), nil
+	}
+	b, err := base64.StdEncoding.DecodeString(r.Content.Text)
+	if err != nil {
+		return nil, fmt.Errorf("decode response body: %w", err)
+	}
+	return b, nil
+}
+
+// observation returns the entry as an observation of the tracker. The path is relative to the base URI.
+func (e *HAREntry) observation(req *http.Request, path string) Observation {
+	o := Observation{
+		Method:              req.Method,
+		Path:                path,
+		Status:              e.Response.Status,
+		RequestContentType:  req.Header.Get("Content-Type"),
+		ResponseContentType: e.Response.header().Get("Content-Type"),
+	}
+	if e.Request.PostData != nil {
+		o.RequestBody = []byte(e.Request.PostData.Text)
+	}
+	// Bodies that cannot be decoded are not inspected.
+	o.ResponseBody, _ = e.Response.body()
+	return o
+}
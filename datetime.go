@@ -0,0 +1,139 @@
+package raml
+
+import (
+	"fmt"
+	"time"
+)
+
+// Layouts of obsolete HTTP-date formats that recipients must accept according to RFC 7231, section 7.1.1.1.
+const (
+	RFC850  = "Monday, 02-Jan-06 15:04:05 GMT"
+	ASCTime = "Mon Jan _2 15:04:05 2006"
+)
+
+// FractionalSeconds defines how fractional seconds of date-time instances are validated.
+type FractionalSeconds int
+
+const (
+	// FractionalSecondsAllow accepts fractional seconds in rfc3339, datetime-only and time-only values as defined by
+	// the RAML spec. This is the default.
+	FractionalSecondsAllow FractionalSeconds = iota
+	// FractionalSecondsDeny rejects values with fractional seconds.
+	FractionalSecondsDeny
+	// FractionalSecondsLenient additionally accepts fractional seconds in rfc2616 values and a comma as a decimal
+	// separator.
+	FractionalSecondsLenient
+)
+
+type parseOptWithFractionalSeconds struct {
+	policy FractionalSeconds
+}
+
+func (o parseOptWithFractionalSeconds) Apply(opt *parserOptions) {
+	opt.fractionalSeconds = o.policy
+}
+
+// OptWithFractionalSeconds sets the policy of fractional seconds in date-time instances.
+func OptWithFractionalSeconds(policy FractionalSeconds) ParseOpt {
+	return parseOptWithFractionalSeconds{policy: policy}
+}
+
+// fractionalSeconds returns the policy of the RAML the shape belongs to.
+func (s *BaseShape) fractionalSeconds() FractionalSeconds {
+	if s == nil || s.raml == nil {
+		return FractionalSecondsAllow
+	}
+	return s.raml.fractionalSeconds
+}
+
+// cutFraction removes fractional seconds that follow the seconds field at the given offset.
+func cutFraction(value string, offset int, policy FractionalSeconds) (string, error) {
+	if len(value) <= offset {
+		return value, nil
+	}
+	switch sep := value[offset]; {
+	case sep == ',' && policy != FractionalSecondsLenient:
+		return "", fmt.Errorf("comma is not allowed as a decimal separator")
+	case sep != '.' && sep != ',':
+		return value, nil
+	}
+	end := offset + 1
+	for end < len(value) && value[end] >= '0' && value[end] <= '9' {
+		end++
+	}
+	if end == offset+1 {
+		return "", fmt.Errorf("fractional seconds must contain at least one digit")
+	}
+	if policy == FractionalSecondsDeny {
+		return "", fmt.Errorf("fractional seconds are not allowed")
+	}
+	return value[:offset] + value[end:], nil
+}
+
+// matchWithFraction matches the value against the layout that has the seconds field ending at the given offset.
+// NOTE: time.Parse silently accepts fractional seconds, so they are cut and checked separately.
+func matchWithFraction(value string, layout string, offset int, policy FractionalSeconds) error {
+	v, err := cutFraction(value, offset, policy)
+	if err != nil {
+		return fmt.Errorf("value must match format %s: %w", layout, err)
+	}
+	if _, err = time.Parse(layout, v); err != nil {
+		return fmt.Errorf("value must match format %s", layout)
+	}
+	return nil
+}
+
+// validateRFC3339 validates the "date-time" notation of RFC 3339, e.g. "2016-02-28T16:41:41.090Z".
+func validateRFC3339(value string, policy FractionalSeconds) error {
+	return matchWithFraction(value, time.RFC3339, len(DateTime), policy)
+}
+
+// validateRFC2616 validates the HTTP-date, e.g. "Sun, 06 Nov 1994 08:49:37 GMT".
+// Obsolete RFC 850 and asctime formats are accepted as well.
+func validateRFC2616(value string, policy FractionalSeconds) error {
+	v := value
+	// HTTP-date has no fractional seconds, they are accepted only if leniency is requested.
+	if offset := secondsEnd(value); offset > 0 && offset < len(value) && (value[offset] == '.' || value[offset] == ',') {
+		if policy != FractionalSecondsLenient {
+			return fmt.Errorf("value must match format %s: fractional seconds are not allowed", RFC2616)
+		}
+		var err error
+		if v, err = cutFraction(value, offset, policy); err != nil {
+			return fmt.Errorf("value must match format %s: %w", RFC2616, err)
+		}
+	}
+	for _, layout := range []string{RFC2616, RFC850, ASCTime} {
+		if _, err := time.Parse(layout, v); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("value must match format %s", RFC2616)
+}
+
+// secondsEnd returns the offset after the "hh:mm:ss" group of the value or -1 if there is no such group.
+func secondsEnd(value string) int {
+	for i := 0; i+8 <= len(value); i++ {
+		if value[i+2] == ':' && value[i+5] == ':' {
+			return i + 8
+		}
+	}
+	return -1
+}
+
+// validateDateTimeOnly validates the "datetime-only" notation, e.g. "2015-07-04T21:00:00".
+func validateDateTimeOnly(value string, policy FractionalSeconds) error {
+	return matchWithFraction(value, DateTime, len(DateTime), policy)
+}
+
+// validateTimeOnly validates the "time-only" notation, e.g. "12:30:00".
+func validateTimeOnly(value string, policy FractionalSeconds) error {
+	return matchWithFraction(value, time.TimeOnly, len(time.TimeOnly), policy)
+}
+
+// validateDateOnly validates the "date-only" notation, e.g. "2015-05-23".
+func validateDateOnly(value string) error {
+	if _, err := time.Parse(time.DateOnly, value); err != nil {
+		return fmt.Errorf("value must match format %s", time.DateOnly)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateTimeFormats(t *testing.T) {
+	rfc3339 := func(policy FractionalSeconds) func(string) error {
+		return func(v string) error { return validateRFC3339(v, policy) }
+	}
+	rfc2616 := func(policy FractionalSeconds) func(string) error {
+		return func(v string) error { return validateRFC2616(v, policy) }
+	}
+	dateTimeOnly := func(policy FractionalSeconds) func(string) error {
+		return func(v string) error { return validateDateTimeOnly(v, policy) }
+	}
+	timeOnly := func(policy FractionalSeconds) func(string) error {
+		return func(v string) error { return validateTimeOnly(v, policy) }
+	}
+	tests := []struct {
+		name     string
+		validate func(string) error
+		value    string
+		wantErr  string
+	}{
+		{"rfc3339", rfc3339(FractionalSecondsAllow), "2016-02-28T16:41:41Z", ""},
+		{"rfc3339 with offset", rfc3339(FractionalSecondsAllow), "2016-02-28T16:41:41+03:00", ""},
+		{"rfc3339 with fraction", rfc3339(FractionalSecondsAllow), "2016-02-28T16:41:41.090Z", ""},
+		{"rfc3339 without zone", rfc3339(FractionalSecondsAllow), "2016-02-28T16:41:41", "value must match format"},
+		{"rfc3339 denied fraction", rfc3339(FractionalSecondsDeny), "2016-02-28T16:41:41.090Z",
+			"fractional seconds are not allowed"},
+		{"rfc3339 empty fraction", rfc3339(FractionalSecondsAllow), "2016-02-28T16:41:41.Z",
+			"fractional seconds must contain at least one digit"},
+		{"rfc3339 comma", rfc3339(FractionalSecondsAllow), "2016-02-28T16:41:41,090Z",
+			"comma is not allowed as a decimal separator"},
+		{"rfc3339 lenient comma", rfc3339(FractionalSecondsLenient), "2016-02-28T16:41:41,090Z", ""},
+		{"rfc2616", rfc2616(FractionalSecondsAllow), "Sun, 06 Nov 1994 08:49:37 GMT", ""},
+		{"rfc2616 rfc850", rfc2616(FractionalSecondsAllow), "Sunday, 06-Nov-94 08:49:37 GMT", ""},
+		{"rfc2616 asctime", rfc2616(FractionalSecondsAllow), "Sun Nov  6 08:49:37 1994", ""},
+		{"rfc2616 fraction", rfc2616(FractionalSecondsAllow), "Sun, 06 Nov 1994 08:49:37.5 GMT",
+			"fractional seconds are not allowed"},
+		{"rfc2616 lenient fraction", rfc2616(FractionalSecondsLenient), "Sun, 06 Nov 1994 08:49:37.5 GMT", ""},
+		{"rfc2616 invalid", rfc2616(FractionalSecondsAllow), "2016-02-28T16:41:41Z", "value must match format"},
+		{"datetime-only", dateTimeOnly(FractionalSecondsAllow), "2015-07-04T21:00:00", ""},
+		{"datetime-only fraction", dateTimeOnly(FractionalSecondsAllow), "2015-07-04T21:00:00.123", ""},
+		{"datetime-only denied fraction", dateTimeOnly(FractionalSecondsDeny), "2015-07-04T21:00:00.123",
+			"fractional seconds are not allowed"},
+		{"datetime-only with zone", dateTimeOnly(FractionalSecondsAllow), "2015-07-04T21:00:00Z",
+			"value must match format"},
+		{"time-only", timeOnly(FractionalSecondsAllow), "12:30:00", ""},
+		{"time-only fraction", timeOnly(FractionalSecondsAllow), "12:30:00.5", ""},
+		{"time-only denied fraction", timeOnly(FractionalSecondsDeny), "12:30:00.5",
+			"fractional seconds are not allowed"},
+		{"time-only invalid", timeOnly(FractionalSecondsAllow), "25:30:00", "value must match format"},
+		{"date-only", validateDateOnly, "2015-05-23", ""},
+		{"date-only invalid", validateDateOnly, "2015-02-30", "value must match format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validate(tt.value)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestOptWithFractionalSeconds(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Time:
+    type: time-only
+    example: "12:30:00.5"
+`
+	_, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithValidate())
+	require.NoError(t, err)
+	_, err = ParseFromString(content, "library.raml", t.TempDir(), OptWithValidate(),
+		OptWithFractionalSeconds(FractionalSecondsDeny))
+	require.ErrorContains(t, err, "fractional seconds are not allowed")
+}
@@ -0,0 +1,49 @@
+package raml
+
+type parseOptWithDeclaredShapes struct{}
+
+func (parseOptWithDeclaredShapes) Apply(opt *parserOptions) {
+	opt.preserveDeclared = true
+}
+
+// OptWithDeclaredShapes keeps copies of shapes as they are declared (linked, but not unwrapped) when the model is
+// resolved, so documentation and diff tools can show what the author wrote next to the effective type, see
+// BaseShape.Declared. Copies double the memory used by shapes.
+func OptWithDeclaredShapes() ParseOpt {
+	return parseOptWithDeclaredShapes{}
+}
+
+// Declared returns the shape as it was declared before the model was resolved: parents, links and aliases are kept
+// and only the facets the author wrote are set. Shapes that are not unwrapped are returned as is. For unwrapped
+// shapes, it returns a detached copy that must not be modified, or nil if the model was parsed without
+// OptWithDeclaredShapes or the shape was created by resolution, e.g. a union member produced by inheritance from
+// a union.
+func (s *BaseShape) Declared() *BaseShape {
+	if !s.IsUnwrapped() {
+		return s
+	}
+	if s.raml == nil {
+		return nil
+	}
+	return s.raml.declaredShapes[s.ID]
+}
+
+// preserveDeclaredShapes copies shapes that are not unwrapped yet. Shapes are copied together, so references
+// between declared shapes point to declared copies.
+func (r *RAML) preserveDeclaredShapes() {
+	if r.declaredShapes == nil {
+		r.declaredShapes = make(map[int64]*BaseShape)
+	}
+	cloned := make(map[int64]*BaseShape)
+	for id, s := range r.declaredShapes {
+		cloned[id] = s
+	}
+	for _, s := range r.shapes {
+		if !s.IsUnwrapped() {
+			s.clone(cloned)
+		}
+	}
+	for id, s := range cloned {
+		r.declaredShapes[id] = s
+	}
+}
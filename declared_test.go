@@ -0,0 +1,55 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseShape_Declared(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Base:
+    properties:
+      id: integer
+  Pet:
+    type: Base
+    properties:
+      name:
+        type: string
+        maxLength: 10
+`
+	r, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap(), OptWithDeclaredShapes())
+	require.NoError(t, err)
+	pet, err := r.LookupType("Pet", r.GetLocation())
+	require.NoError(t, err)
+	require.Equal(t, 2, pet.Shape.(*ObjectShape).Properties.Len())
+
+	declared := pet.Declared()
+	require.NotNil(t, declared)
+	require.NotSame(t, pet, declared)
+	require.Equal(t, pet.ID, declared.ID)
+	require.Len(t, declared.Inherits, 1)
+	require.Equal(t, "Base", declared.Inherits[0].Name)
+	props := declared.Shape.(*ObjectShape).Properties
+	require.Equal(t, 1, props.Len())
+	name, ok := props.Get("name")
+	require.True(t, ok)
+	require.Same(t, name.Shape, name.Shape.Declared())
+
+	// Resolved shapes of properties refer to their declared copies.
+	resolvedName, _ := pet.Shape.(*ObjectShape).Properties.Get("name")
+	require.Same(t, name.Shape, resolvedName.Shape.Declared())
+
+	r, err = ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	pet, err = r.LookupType("Pet", r.GetLocation())
+	require.NoError(t, err)
+	require.Nil(t, pet.Declared())
+
+	r, err = ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+	pet, err = r.LookupType("Pet", r.GetLocation())
+	require.NoError(t, err)
+	require.Same(t, pet, pet.Declared())
+}
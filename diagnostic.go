@@ -0,0 +1,85 @@
+package raml
+
+import (
+	"fmt"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// Diagnostic is a flattened view of a single error trace that points to the innermost known position.
+type Diagnostic struct {
+	Severity stacktrace.Severity
+	Type     stacktrace.Type
+	// Message is the message of the innermost error in the trace.
+	Message string
+	// Trace contains messages of all errors in the trace from the outermost to the innermost one.
+	Trace []string
+
+	Location string
+	stacktrace.Position
+}
+
+// HasPosition returns true if the diagnostic points to a specific line in the file.
+func (d Diagnostic) HasPosition() bool {
+	return d.Line > 0
+}
+
+// String implements fmt.Stringer in the "file:line:col: severity: message" form.
+func (d Diagnostic) String() string {
+	location := d.Location
+	if d.HasPosition() {
+		location = fmt.Sprintf("%s:%d:%d", location, d.Line, d.Column)
+	}
+	if location == "" {
+		return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, d.Severity, d.Message)
+}
+
+// DiagnosticsFromError converts an error returned by the parser to a list of diagnostics.
+// Every trace of the stacktrace (including appended ones) produces a separate diagnostic.
+func DiagnosticsFromError(err error) []Diagnostic {
+	if err == nil {
+		return nil
+	}
+	st, ok := stacktrace.Unwrap(err)
+	if !ok {
+		return []Diagnostic{{
+			Severity: stacktrace.SeverityError,
+			Type:     stacktrace.TypeUnknown,
+			Message:  err.Error(),
+			Trace:    []string{err.Error()},
+		}}
+	}
+	var result []Diagnostic
+	collectDiagnostics(st, &result)
+	return result
+}
+
+func collectDiagnostics(st *stacktrace.StackTrace, result *[]Diagnostic) {
+	d := Diagnostic{
+		Severity: st.Severity,
+		Type:     st.Type,
+		Location: st.Location,
+	}
+	for cur := st; cur != nil; cur = cur.Wrapped {
+		msg := cur.FullMessageWithInfo()
+		if msg != "" {
+			d.Trace = append(d.Trace, msg)
+			d.Message = msg
+		}
+		// Innermost error with known position prevails.
+		if cur.Position != nil {
+			d.Location = cur.Location
+			d.Position = *cur.Position
+			d.Severity = cur.Severity
+			d.Type = cur.Type
+		} else if cur.Location != "" && !d.HasPosition() {
+			d.Location = cur.Location
+		}
+	}
+	*result = append(*result, d)
+	for _, item := range st.List {
+		collectDiagnostics(item, result)
+	}
+}
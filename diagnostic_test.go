@@ -0,0 +1,50 @@
+package raml
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/acronis/go-stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnosticsFromError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "plain error",
+			err:  errors.New("boom"),
+			want: []string{"error: boom"},
+		},
+		{
+			name: "innermost position prevails",
+			err: stacktrace.NewWrapped("outer", stacktrace.New("inner", "/tmp/b.raml",
+				stacktrace.WithPosition(stacktrace.NewPosition(3, 5))), "/tmp/a.raml",
+				stacktrace.WithPosition(stacktrace.NewPosition(1, 1))),
+			want: []string{"/tmp/b.raml:3:5: error: inner"},
+		},
+		{
+			name: "appended traces",
+			err: stacktrace.New("first", "/tmp/a.raml", stacktrace.WithPosition(stacktrace.NewPosition(1, 2))).
+				Append(stacktrace.New("second", "/tmp/b.raml")),
+			want: []string{"/tmp/a.raml:1:2: error: first", "/tmp/b.raml: error: second"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range DiagnosticsFromError(tt.err) {
+				got = append(got, d.String())
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,428 @@
+package raml
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ChangeKind is a kind of change between two RAML models.
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// Change describes a single difference between two RAML models.
+// A change is breaking if data that was valid against the old model may become invalid against the new one,
+// or if a declaration the consumers may rely on has been removed.
+type Change struct {
+	Kind ChangeKind
+	// Path is a dot-separated path to the changed element, e.g. "Pet.properties.name.minLength".
+	Path     string
+	Old      any
+	New      any
+	Breaking bool
+}
+
+// String implements fmt.Stringer.
+func (c Change) String() string {
+	prefix := "non-breaking"
+	if c.Breaking {
+		prefix = "breaking"
+	}
+	if c.Kind != ChangeChanged {
+		return fmt.Sprintf("%s: %s %s", prefix, c.Kind, c.Path)
+	}
+	return fmt.Sprintf("%s: %s %s: %v -> %v", prefix, c.Kind, c.Path, c.Old, c.New)
+}
+
+// HasBreakingChanges returns true if at least one of the changes is breaking.
+func HasBreakingChanges(changes []Change) bool {
+	return slices.ContainsFunc(changes, func(c Change) bool { return c.Breaking })
+}
+
+// Diff compares types declared in the entry points of two RAML models (including types of used libraries)
+// and returns a list of changes sorted by path.
+// NOTE: Models are expected to be unwrapped, otherwise inherited facets are not compared.
+func Diff(oldRAML, newRAML *RAML) []Change {
+	d := &differ{visited: make(map[[2]int64]struct{})}
+	oldTypes := entryPointTypes(oldRAML.EntryPoint())
+	newTypes := entryPointTypes(newRAML.EntryPoint())
+	for name, o := range oldTypes {
+		n, ok := newTypes[name]
+		if !ok {
+			d.add(ChangeRemoved, name, nil, nil, true)
+			continue
+		}
+		d.diffShapes(name, o, n)
+	}
+	for name := range newTypes {
+		if _, ok := oldTypes[name]; !ok {
+			d.add(ChangeAdded, name, nil, nil, false)
+		}
+	}
+	slices.SortStableFunc(d.changes, func(a, b Change) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return d.changes
+}
+
+// entryPointTypes returns types of the fragment and of all libraries it uses.
+// Types of used libraries are qualified with the namespace, e.g. "common.Parent".
+func entryPointTypes(frag Fragment) map[string]*BaseShape {
+	result := make(map[string]*BaseShape)
+	visited := make(map[string]struct{})
+	var walk func(lib *Library, prefix string)
+	walk = func(lib *Library, prefix string) {
+		if _, ok := visited[lib.Location]; ok {
+			return
+		}
+		visited[lib.Location] = struct{}{}
+		for pair := lib.Types.Oldest(); pair != nil; pair = pair.Next() {
+			result[prefix+pair.Key] = pair.Value
+		}
+		for pair := lib.Uses.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value.Link != nil {
+				walk(pair.Value.Link, prefix+pair.Key+".")
+			}
+		}
+	}
+	switch f := frag.(type) {
+	case *Library:
+		walk(f, "")
+	case *DataType:
+		name := f.Shape.Name
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(f.Location), filepath.Ext(f.Location))
+		}
+		result[name] = f.Shape
+		for pair := f.Uses.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value.Link != nil {
+				walk(pair.Value.Link, pair.Key+".")
+			}
+		}
+	}
+	return result
+}
+
+type differ struct {
+	changes []Change
+	// visited contains pairs of compared shape IDs to stop on recursive types.
+	visited map[[2]int64]struct{}
+	// skipDocs skips documentation facets that do not affect validation.
+	skipDocs bool
+}
+
+func (d *differ) add(kind ChangeKind, path string, o, n any, breaking bool) {
+	d.changes = append(d.changes, Change{Kind: kind, Path: path, Old: o, New: n, Breaking: breaking})
+}
+
+func (d *differ) diffShapes(path string, o, n *BaseShape) {
+	key := [2]int64{o.ID, n.ID}
+	if _, ok := d.visited[key]; ok {
+		return
+	}
+	d.visited[key] = struct{}{}
+
+	if !d.skipDocs {
+		diffValue(d, path+".displayName", o.DisplayName, n.DisplayName)
+		diffValue(d, path+".description", o.Description, n.Description)
+	}
+
+	if reflect.TypeOf(o.Shape) != reflect.TypeOf(n.Shape) {
+		// Changing a type to any or to a union with a compatible member accepts all data that was valid before.
+		_, isAny := n.Shape.(*AnyShape)
+		d.add(ChangeChanged, path+".type", o.Type, n.Type, !isAny && !hasCompatibleMember(o, n))
+		return
+	}
+
+	switch oldShape := o.Shape.(type) {
+	case *StringShape:
+		newShape := n.Shape.(*StringShape)
+		lowerBound(d, path+"."+FacetMinLength, oldShape.MinLength, newShape.MinLength, comparePtr[uint64])
+		upperBound(d, path+"."+FacetMaxLength, oldShape.MaxLength, newShape.MaxLength, comparePtr[uint64])
+		d.diffPattern(path, oldShape, newShape)
+		d.diffEnum(path, oldShape.Enum, newShape.Enum)
+	case *NumberShape:
+		newShape := n.Shape.(*NumberShape)
+		lowerBound(d, path+"."+FacetMinimum, oldShape.Minimum, newShape.Minimum, comparePtr[float64])
+		upperBound(d, path+"."+FacetMaximum, oldShape.Maximum, newShape.Maximum, comparePtr[float64])
+		strictValue(d, path+"."+FacetMultipleOf, oldShape.MultipleOf, newShape.MultipleOf)
+		strictValue(d, path+"."+FacetFormat, oldShape.Format, newShape.Format)
+		d.diffEnum(path, oldShape.Enum, newShape.Enum)
+	case *IntegerShape:
+		newShape := n.Shape.(*IntegerShape)
+		lowerBound(d, path+"."+FacetMinimum, oldShape.Minimum, newShape.Minimum, (*big.Int).Cmp)
+		upperBound(d, path+"."+FacetMaximum, oldShape.Maximum, newShape.Maximum, (*big.Int).Cmp)
+		strictValue(d, path+"."+FacetMultipleOf, oldShape.MultipleOf, newShape.MultipleOf)
+		strictValue(d, path+"."+FacetFormat, oldShape.Format, newShape.Format)
+		d.diffEnum(path, oldShape.Enum, newShape.Enum)
+	case *BooleanShape:
+		d.diffEnum(path, oldShape.Enum, n.Shape.(*BooleanShape).Enum)
+	case *FileShape:
+		newShape := n.Shape.(*FileShape)
+		lowerBound(d, path+"."+FacetMinLength, oldShape.MinLength, newShape.MinLength, comparePtr[uint64])
+		upperBound(d, path+"."+FacetMaxLength, oldShape.MaxLength, newShape.MaxLength, comparePtr[uint64])
+		d.diffNodeSet(path+"."+FacetFileTypes, oldShape.FileTypes, newShape.FileTypes)
+	case *DateTimeShape:
+		newShape := n.Shape.(*DateTimeShape)
+		strictValue(d, path+"."+FacetFormat, oldShape.Format, newShape.Format)
+		d.diffEnum(path, oldShape.Enum, newShape.Enum)
+	case *DateTimeOnlyShape:
+		d.diffEnum(path, oldShape.Enum, n.Shape.(*DateTimeOnlyShape).Enum)
+	case *DateOnlyShape:
+		d.diffEnum(path, oldShape.Enum, n.Shape.(*DateOnlyShape).Enum)
+	case *TimeOnlyShape:
+		d.diffEnum(path, oldShape.Enum, n.Shape.(*TimeOnlyShape).Enum)
+	case *ArrayShape:
+		d.diffArray(path, oldShape, n.Shape.(*ArrayShape))
+	case *ObjectShape:
+		d.diffObject(path, oldShape, n.Shape.(*ObjectShape))
+	case *UnionShape:
+		d.diffUnion(path, oldShape, n.Shape.(*UnionShape))
+	case *RecursiveShape:
+		newShape := n.Shape.(*RecursiveShape)
+		if oldShape.Head.Name != newShape.Head.Name {
+			d.add(ChangeChanged, path+".type", oldShape.Head.Name, newShape.Head.Name, true)
+		}
+	case *JSONShape:
+		newShape := n.Shape.(*JSONShape)
+		if oldShape.Raw != newShape.Raw {
+			d.add(ChangeChanged, path+".schema", nil, nil, true)
+		}
+	}
+}
+
+func (d *differ) diffPattern(path string, o, n *StringShape) {
+	var op, np *string
+	if o.Pattern != nil {
+		op = &[]string{o.Pattern.String()}[0]
+	}
+	if n.Pattern != nil {
+		np = &[]string{n.Pattern.String()}[0]
+	}
+	strictValue(d, path+"."+FacetPattern, op, np)
+}
+
+func (d *differ) diffArray(path string, o, n *ArrayShape) {
+	lowerBound(d, path+"."+FacetMinItems, o.MinItems, n.MinItems, comparePtr[uint64])
+	upperBound(d, path+"."+FacetMaxItems, o.MaxItems, n.MaxItems, comparePtr[uint64])
+	oUnique := o.UniqueItems != nil && *o.UniqueItems
+	nUnique := n.UniqueItems != nil && *n.UniqueItems
+	if oUnique != nUnique {
+		d.add(ChangeChanged, path+"."+FacetUniqueItems, oUnique, nUnique, nUnique)
+	}
+	switch {
+	case o.Items != nil && n.Items != nil:
+		d.diffShapes(path+"."+FacetItems, o.Items, n.Items)
+	case o.Items == nil && n.Items != nil:
+		d.add(ChangeAdded, path+"."+FacetItems, nil, n.Items.Type, true)
+	case o.Items != nil && n.Items == nil:
+		d.add(ChangeRemoved, path+"."+FacetItems, o.Items.Type, nil, false)
+	}
+}
+
+func (d *differ) diffObject(path string, o, n *ObjectShape) {
+	lowerBound(d, path+"."+FacetMinProperties, o.MinProperties, n.MinProperties, comparePtr[uint64])
+	upperBound(d, path+"."+FacetMaxProperties, o.MaxProperties, n.MaxProperties, comparePtr[uint64])
+	strictValue(d, path+"."+FacetDiscriminator, o.Discriminator, n.Discriminator)
+	if !reflect.DeepEqual(o.DiscriminatorValue, n.DiscriminatorValue) {
+		d.add(ChangeChanged, path+"."+FacetDiscriminatorValue, o.DiscriminatorValue, n.DiscriminatorValue, true)
+	}
+	// Additional properties are allowed by default.
+	oAdditional := o.AdditionalProperties == nil || *o.AdditionalProperties
+	nAdditional := n.AdditionalProperties == nil || *n.AdditionalProperties
+	if oAdditional != nAdditional {
+		d.add(ChangeChanged, path+"."+FacetAdditionalProperties, oAdditional, nAdditional, !nAdditional)
+	}
+
+	propsPath := path + "." + FacetProperties + "."
+	for pair := o.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		op := pair.Value
+		np, ok := n.Properties.Get(pair.Key)
+		if !ok {
+			d.add(ChangeRemoved, propsPath+pair.Key, nil, nil, true)
+			continue
+		}
+		if op.Required != np.Required {
+			d.add(ChangeChanged, propsPath+pair.Key+".required", op.Required, np.Required, np.Required)
+		}
+		d.diffShapes(propsPath+pair.Key, op.Shape, np.Shape)
+	}
+	for pair := n.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := o.Properties.Get(pair.Key); !ok {
+			// Only new required property rejects data that was valid before.
+			d.add(ChangeAdded, propsPath+pair.Key, nil, nil, pair.Value.Required)
+		}
+	}
+
+	patternPath := path + ".patternProperties."
+	for pair := o.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		np, ok := n.PatternProperties.Get(pair.Key)
+		if !ok {
+			d.add(ChangeRemoved, patternPath+pair.Key, nil, nil, true)
+			continue
+		}
+		d.diffShapes(patternPath+pair.Key, pair.Value.Shape, np.Shape)
+	}
+	for pair := n.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := o.PatternProperties.Get(pair.Key); !ok {
+			d.add(ChangeAdded, patternPath+pair.Key, nil, nil, true)
+		}
+	}
+}
+
+// hasCompatibleMember returns true if the new shape is a union with a member that accepts all data valid against
+// the old shape.
+func hasCompatibleMember(o, n *BaseShape) bool {
+	union, ok := n.Shape.(*UnionShape)
+	if !ok || union.Enum != nil {
+		return false
+	}
+	for _, member := range union.AnyOf {
+		md := &differ{visited: make(map[[2]int64]struct{}), skipDocs: true}
+		md.diffShapes("", o, member)
+		if !HasBreakingChanges(md.changes) {
+			return true
+		}
+	}
+	return false
+}
+
+func unionMemberKey(s *BaseShape) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Type
+}
+
+func (d *differ) diffUnion(path string, o, n *UnionShape) {
+	newMembers := make(map[string]*BaseShape, len(n.AnyOf))
+	for _, m := range n.AnyOf {
+		newMembers[unionMemberKey(m)] = m
+	}
+	oldMembers := make(map[string]struct{}, len(o.AnyOf))
+	for _, m := range o.AnyOf {
+		key := unionMemberKey(m)
+		oldMembers[key] = struct{}{}
+		nm, ok := newMembers[key]
+		if !ok {
+			d.add(ChangeRemoved, path+".anyOf."+key, nil, nil, true)
+			continue
+		}
+		d.diffShapes(path+".anyOf."+key, m, nm)
+	}
+	for _, m := range n.AnyOf {
+		if _, ok := oldMembers[unionMemberKey(m)]; !ok {
+			d.add(ChangeAdded, path+".anyOf."+unionMemberKey(m), nil, nil, false)
+		}
+	}
+	d.diffEnum(path, o.Enum, n.Enum)
+}
+
+func (d *differ) diffEnum(path string, o, n Nodes) {
+	path += "." + FacetEnum
+	switch {
+	case o == nil && n == nil:
+		return
+	case o == nil:
+		d.add(ChangeAdded, path, nil, n.String(), true)
+	case n == nil:
+		d.add(ChangeRemoved, path, o.String(), nil, false)
+	default:
+		d.diffNodeSet(path, o, n)
+	}
+}
+
+// diffNodeSet compares sets of values, where removal of a value is a breaking change.
+func (d *differ) diffNodeSet(path string, o, n Nodes) {
+	oldValues := make(map[string]struct{}, len(o))
+	for _, v := range o {
+		oldValues[fmt.Sprint(v.Value)] = struct{}{}
+	}
+	newValues := make(map[string]struct{}, len(n))
+	for _, v := range n {
+		newValues[fmt.Sprint(v.Value)] = struct{}{}
+	}
+	for _, v := range o {
+		key := fmt.Sprint(v.Value)
+		if _, ok := newValues[key]; !ok {
+			d.add(ChangeRemoved, path+"."+key, key, nil, true)
+		}
+	}
+	for _, v := range n {
+		key := fmt.Sprint(v.Value)
+		if _, ok := oldValues[key]; !ok {
+			d.add(ChangeAdded, path+"."+key, nil, key, false)
+		}
+	}
+}
+
+func comparePtr[T cmp.Ordered](a, b *T) int {
+	return cmp.Compare(*a, *b)
+}
+
+func deref[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	// Types like big.Int implement fmt.Stringer on a pointer receiver.
+	if s, ok := any(v).(fmt.Stringer); ok {
+		return s.String()
+	}
+	return *v
+}
+
+// diffValue reports non-breaking changes of informational values.
+func diffValue[T comparable](d *differ, path string, o, n *T) {
+	compareValues(d, path, o, n, func(*T, *T) bool { return false })
+}
+
+// strictValue reports changes of values where any change, except removal, is breaking.
+func strictValue[T comparable](d *differ, path string, o, n *T) {
+	compareValues(d, path, o, n, func(_ *T, n *T) bool { return n != nil })
+}
+
+func compareValues[T comparable](d *differ, path string, o, n *T, breaking func(o, n *T) bool) {
+	switch {
+	case o == nil && n == nil:
+	case o == nil:
+		d.add(ChangeAdded, path, nil, *n, breaking(o, n))
+	case n == nil:
+		d.add(ChangeRemoved, path, *o, nil, breaking(o, n))
+	case *o != *n:
+		d.add(ChangeChanged, path, *o, *n, breaking(o, n))
+	}
+}
+
+// lowerBound reports changes of a lower bound facet, where adding or increasing the bound is breaking.
+func lowerBound[T any](d *differ, path string, o, n *T, compare func(a, b *T) int) {
+	bound(d, path, o, n, compare)
+}
+
+// upperBound reports changes of an upper bound facet, where adding or decreasing the bound is breaking.
+func upperBound[T any](d *differ, path string, o, n *T, compare func(a, b *T) int) {
+	bound(d, path, o, n, func(a, b *T) int { return compare(b, a) })
+}
+
+// bound reports changes of a bound facet, where the bound is tightened if compare(new, old) > 0.
+func bound[T any](d *differ, path string, o, n *T, compare func(a, b *T) int) {
+	switch {
+	case o == nil && n == nil:
+	case o == nil:
+		d.add(ChangeAdded, path, nil, deref(n), true)
+	case n == nil:
+		d.add(ChangeRemoved, path, deref(o), nil, false)
+	default:
+		if c := compare(n, o); c != 0 {
+			d.add(ChangeChanged, path, deref(o), deref(n), c > 0)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name         string
+		oldContent   string
+		newContent   string
+		want         []string
+		wantBreaking bool
+	}{
+		{
+			name: "no changes",
+			oldContent: `#%RAML 1.0 Library
+types:
+  A: string
+`,
+			newContent: `#%RAML 1.0 Library
+types:
+  A: string
+`,
+			want: nil,
+		},
+		{
+			name: "added and removed types",
+			oldContent: `#%RAML 1.0 Library
+types:
+  A: string
+`,
+			newContent: `#%RAML 1.0 Library
+types:
+  B: string
+`,
+			want: []string{
+				"breaking: removed A",
+				"non-breaking: added B",
+			},
+			wantBreaking: true,
+		},
+		{
+			name: "changed type",
+			oldContent: `#%RAML 1.0 Library
+types:
+  A: string
+  B: string
+`,
+			newContent: `#%RAML 1.0 Library
+types:
+  A: integer
+  B: any
+`,
+			want: []string{
+				"breaking: changed A.type: string -> integer",
+				"non-breaking: changed B.type: string -> any",
+			},
+			wantBreaking: true,
+		},
+		{
+			name: "relaxed facets",
+			oldContent: `#%RAML 1.0 Library
+types:
+  A:
+    type: string
+    minLength: 2
+    maxLength: 5
+    description: old
+  B:
+    type: integer
+    enum: [1, 2]
+`,
+			newContent: `#%RAML 1.0 Library
+types:
+  A:
+    type: string
+    minLength: 1
+    maxLength: 10
+    description: new
+  B:
+    type: integer
+    enum: [1, 2, 3]
+`,
+			want: []string{
+				"non-breaking: changed A.description: old -> new",
+				"non-breaking: changed A.maxLength: 5 -> 10",
+				"non-breaking: changed A.minLength: 2 -> 1",
+				"non-breaking: added B.enum.3",
+			},
+		},
+		{
+			name: "tightened facets",
+			oldContent: `#%RAML 1.0 Library
+types:
+  A:
+    type: integer
+    maximum: 10
+  B:
+    type: array
+    items: string
+`,
+			newContent: `#%RAML 1.0 Library
+types:
+  A:
+    type: integer
+    minimum: 1
+    maximum: 5
+  B:
+    type: array
+    items:
+      type: string
+      pattern: ^a
+    uniqueItems: true
+`,
+			want: []string{
+				"breaking: changed A.maximum: 10 -> 5",
+				"breaking: added A.minimum",
+				"breaking: added B.items.pattern",
+				"breaking: changed B.uniqueItems: false -> true",
+			},
+			wantBreaking: true,
+		},
+		{
+			name: "object properties",
+			oldContent: `#%RAML 1.0 Library
+types:
+  A:
+    properties:
+      a: string
+      b?: string
+      c: string
+`,
+			newContent: `#%RAML 1.0 Library
+types:
+  A:
+    additionalProperties: false
+    properties:
+      a?: string
+      b: string
+      d?: string
+      e: string
+`,
+			want: []string{
+				"breaking: changed A.additionalProperties: true -> false",
+				"non-breaking: changed A.properties.a.required: true -> false",
+				"breaking: changed A.properties.b.required: false -> true",
+				"breaking: removed A.properties.c",
+				"non-breaking: added A.properties.d",
+				"breaking: added A.properties.e",
+			},
+			wantBreaking: true,
+		},
+		{
+			name: "union members",
+			oldContent: `#%RAML 1.0 Library
+types:
+  A: string | integer
+`,
+			newContent: `#%RAML 1.0 Library
+types:
+  A: string | boolean
+`,
+			want: []string{
+				"non-breaking: added A.anyOf.boolean",
+				"breaking: removed A.anyOf.integer",
+			},
+			wantBreaking: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			oldRAML, err := ParseFromString(tt.oldContent, "old.raml", dir, OptWithUnwrap(), OptWithValidate())
+			require.NoError(t, err)
+			newRAML, err := ParseFromString(tt.newContent, "new.raml", dir, OptWithUnwrap(), OptWithValidate())
+			require.NoError(t, err)
+
+			changes := Diff(oldRAML, newRAML)
+			var got []string
+			for _, c := range changes {
+				got = append(got, c.String())
+			}
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantBreaking, HasBreakingChanges(changes))
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package raml
+
+import (
+	"fmt"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// discriminatorValue returns the value of the discriminator property that identifies the type.
+// The name of the type is used if discriminatorValue is not set.
+func (s *ObjectShape) discriminatorValue() any {
+	if s.DiscriminatorValue != nil {
+		return s.DiscriminatorValue
+	}
+	return s.Base().Name
+}
+
+// isSubtypeOf reports whether the shape inherits from the shape with the given ID directly or through its parents.
+func (s *BaseShape) isSubtypeOf(id int64) bool {
+	visited := make(map[int64]struct{})
+	var visit func(parent *BaseShape) bool
+	visit = func(parent *BaseShape) bool {
+		if parent.ID == id {
+			return true
+		}
+		if _, ok := visited[parent.ID]; ok {
+			return false
+		}
+		visited[parent.ID] = struct{}{}
+		if parent.Alias != nil && visit(parent.Alias) {
+			return true
+		}
+		if parent.Link != nil && visit(parent.Link.Shape) {
+			return true
+		}
+		for _, p := range parent.Inherits {
+			if visit(p) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, parent := range s.Inherits {
+		if visit(parent) {
+			return true
+		}
+	}
+	return false
+}
+
+// declaredTypes returns object types declared by libraries and data type fragments.
+func (r *RAML) declaredTypes() []*BaseShape {
+	var types []*BaseShape
+	for _, frag := range r.fragmentsCache {
+		switch f := frag.(type) {
+		case *Library:
+			for pair := f.Types.Oldest(); pair != nil; pair = pair.Next() {
+				types = append(types, pair.Value)
+			}
+		case *DataType:
+			if f.Shape != nil {
+				types = append(types, f.Shape)
+			}
+		}
+	}
+	return types
+}
+
+// subtypesOf returns declared object types that inherit from the shape with the given ID.
+// The registry of subtypes is built on the first call and is reset when fragments are added or unwrapped.
+func (r *RAML) subtypesOf(id int64) []*BaseShape {
+	r.subtypesMu.Lock()
+	defer r.subtypesMu.Unlock()
+	if r.subtypes == nil {
+
+		r.subtypes = make(map[int64][]*BaseShape)
+		types := r.declaredTypes()
+		for _, parent := range types {
+			if obj, ok := parent.Shape.(*ObjectShape); !ok || obj.Discriminator == nil {
+				continue
+			}
+			for _, t := range types {
+				if _, ok := t.Shape.(*ObjectShape); ok && t.ID != parent.ID && t.isSubtypeOf(parent.ID) {
+					r.subtypes[parent.ID] = append(r.subtypes[parent.ID], t)
+				}
+			}
+		}
+	}
+	return r.subtypes[id]
+}
+
+// unwrappedSubtype returns the subtype if it is unwrapped in-place, otherwise its unwrapped copy, which is shared
+// by all shapes that dispatch to the subtype.
+func (r *RAML) unwrappedSubtype(t *BaseShape) (*BaseShape, error) {
+	if t.IsUnwrapped() {
+		return t, nil
+	}
+	r.subtypesMu.Lock()
+	us, ok := r.unwrappedSubtypes[t.ID]
+	r.subtypesMu.Unlock()
+	if ok {
+		return us, nil
+	}
+	us, err := r.unwrapBaseShape(t.CloneDetached())
+	if err != nil {
+		return nil, fmt.Errorf("unwrap subtype %s: %w", t.Name, err)
+	}
+	r.subtypesMu.Lock()
+	if r.unwrappedSubtypes == nil {
+		r.unwrappedSubtypes = make(map[int64]*BaseShape)
+	}
+	r.unwrappedSubtypes[t.ID] = us
+	r.subtypesMu.Unlock()
+	// The copy is registered before its own tables are built, so subtypes that refer back to it are not unwrapped
+	// again.
+	if err = r.buildDiscriminators(us); err != nil {
+		return nil, err
+	}
+	return us, nil
+}
+
+// buildDiscriminators computes dispatch tables of object types with discriminators and of unions of them in the
+// shape and every shape it refers to. Tables are computed when shapes are unwrapped, so validation only reads them.
+func (r *RAML) buildDiscriminators(base *BaseShape) error {
+	var err error
+	base.walk(func(b *BaseShape) {
+		if err == nil && b.IsUnwrapped() {
+			err = r.buildDiscriminator(b)
+		}
+	})
+	return err
+}
+
+// buildShapeDiscriminators computes dispatch tables of all shapes of the RAML after they are unwrapped in-place.
+func (r *RAML) buildShapeDiscriminators() error {
+	for _, b := range r.GetShapes() {
+		if !b.IsUnwrapped() {
+			continue
+		}
+		if err := r.buildDiscriminator(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RAML) buildDiscriminator(b *BaseShape) error {
+	switch s := b.Shape.(type) {
+	case *ObjectShape:
+		if s.Discriminator == nil || s.subtypes != nil {
+			return nil
+		}
+		subtypes := make(map[string]*BaseShape)
+		// Unwrapped shapes may share the object shape of their parent, which identifies the type.
+		for _, t := range r.subtypesOf(s.Base().ID) {
+			key := discriminatorKey(t.Shape.(*ObjectShape).discriminatorValue())
+			if _, ok := subtypes[key]; ok {
+				continue
+			}
+			us, err := r.unwrappedSubtype(t)
+			if err != nil {
+				return StacktraceNewWrapped("discriminator", err, b.Location(), stacktrace.WithPosition(&b.Position))
+			}
+			subtypes[key] = us
+		}
+		s.subtypes = subtypes
+	case *UnionShape:
+		if s.discriminator == nil {
+			s.discriminator = newUnionDiscriminator(s.AnyOf)
+		}
+	}
+	return nil
+}
+
+// discriminatedSubtype returns the declared subtype that is selected by the value of the discriminator property.
+// Nil is returned if the shape has no discriminator, the instance has no discriminator property or the value
+// identifies the shape itself. Subtypes are only dispatched to by shapes that are unwrapped by the RAML.
+func (s *ObjectShape) discriminatedSubtype(props map[string]interface{}) (*BaseShape, error) {
+	if s.Discriminator == nil || s.subtypes == nil {
+		return nil, nil
+	}
+	value, ok := props[*s.Discriminator]
+	if !ok || isSameValue(s.discriminatorValue(), value) {
+		return nil, nil
+	}
+	if sub, ok := s.subtypes[discriminatorKey(value)]; ok {
+		return sub, nil
+	}
+	return nil, fmt.Errorf("discriminator value %v does not match %s or any of its subtypes", value, s.Base().Name)
+}
+
+// resetSubtypes drops the registry of subtypes after the set of declared types or their shapes change.
+func (r *RAML) resetSubtypes() {
+	r.subtypesMu.Lock()
+	defer r.subtypesMu.Unlock()
+	r.subtypes = nil
+	r.unwrappedSubtypes = nil
+}
+
+// unionDiscriminator dispatches instances of a union of object types with the same discriminator property to
+// members by the value of the property. The table is shared by copies of the union, so members are referred to by
+// their indexes.
+type unionDiscriminator struct {
+	// property is the discriminator property, empty if members cannot be dispatched.
+	property string
+	members  map[string]int
+}
+
+// discriminatorKey returns the key of the discriminator value, numbers are compared by value like enums.
+func discriminatorKey(v any) string {
+	if n, ok := exactNumber(v); ok {
+		return "number:" + n.RatString()
+	}
+	return fmt.Sprintf("%T:%v", v, v)
+}
+
+func newUnionDiscriminator(anyOf []*BaseShape) *unionDiscriminator {
+	d := &unionDiscriminator{}
+	members := make(map[string]int, len(anyOf))
+	property := ""
+	for i, member := range anyOf {
+		obj, ok := member.Shape.(*ObjectShape)
+		if !ok || obj.Discriminator == nil || property != "" && *obj.Discriminator != property {
+			return d
+		}
+		property = *obj.Discriminator
+		key := discriminatorKey(obj.discriminatorValue())
+		if _, ok = members[key]; ok {
+			return d
+		}
+		members[key] = i
+	}
+	d.property, d.members = property, members
+	return d
+}
+
+// discriminatedMember returns the member selected by the discriminator value of the instance if all members are
+// object types with the same discriminator property. Nil is returned if the members must be tried one by one.
+func (s *UnionShape) discriminatedMember(v any) *BaseShape {
+	props, ok := v.(map[string]interface{})
+	d := s.discriminator
+	if !ok || d == nil || d.property == "" {
+		return nil
+	}
+	value, ok := props[d.property]
+	if !ok {
+		return nil
+	}
+	i, ok := d.members[discriminatorKey(value)]
+	if !ok || i >= len(s.AnyOf) {
+		return nil
+	}
+	return s.AnyOf[i]
+}
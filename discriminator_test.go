@@ -0,0 +1,239 @@
+package raml
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectShape_discriminatedSubtype(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Pet:
+    type: object
+    discriminator: kind
+    properties:
+      kind: string
+      name: string
+  Cat:
+    type: Pet
+    discriminatorValue: cat
+    additionalProperties: false
+    properties:
+      lives: integer
+  Kitten:
+    type: Cat
+    discriminatorValue: kitten
+    additionalProperties: false
+    properties:
+      age:
+        type: integer
+        maximum: 1
+  Dog:
+    type: Pet
+    properties:
+      goodBoy: boolean
+  Owner:
+    type: object
+    properties:
+      pet: Pet
+    example:
+      pet:
+        kind: cat
+        name: Tom
+        lives: 9
+`
+	tests := []struct {
+		name     string
+		typeName string
+		value    map[string]any
+		wantErr  string
+	}{
+		{
+			name:     "parent itself",
+			typeName: "Pet",
+			value:    map[string]any{"kind": "Pet", "name": "Generic", "extra": true},
+		},
+		{
+			name:     "dispatch to subtype",
+			typeName: "Pet",
+			value:    map[string]any{"kind": "cat", "name": "Tom", "lives": 9},
+		},
+		{
+			name:     "subtype constraint is enforced",
+			typeName: "Pet",
+			value:    map[string]any{"kind": "cat", "name": "Tom", "lives": "nine"},
+			wantErr:  "validate property $.lives",
+		},
+		{
+			name:     "subtype additional properties are enforced",
+			typeName: "Pet",
+			value:    map[string]any{"kind": "cat", "name": "Tom", "goodBoy": true},
+			wantErr:  "unexpected additional property \"goodBoy\"",
+		},
+		{
+			name:     "dispatch to indirect subtype",
+			typeName: "Pet",
+			value:    map[string]any{"kind": "kitten", "name": "Tom", "age": 2},
+			wantErr:  "value must be less than 1",
+		},
+		{
+			name:     "type name is the default discriminator value",
+			typeName: "Pet",
+			value:    map[string]any{"kind": "Dog", "name": "Rex", "goodBoy": "yes"},
+			wantErr:  "validate property $.goodBoy",
+		},
+		{
+			name:     "unknown discriminator value",
+			typeName: "Pet",
+			value:    map[string]any{"kind": "fish", "name": "Nemo"},
+			wantErr:  "discriminator value fish does not match Pet or any of its subtypes",
+		},
+		{
+			name:     "sibling is not a subtype",
+			typeName: "Cat",
+			value:    map[string]any{"kind": "Dog", "name": "Rex"},
+			wantErr:  "does not match Cat or any of its subtypes",
+		},
+		{
+			name:     "property of parent type",
+			typeName: "Owner",
+			value:    map[string]any{"pet": map[string]any{"kind": "kitten", "name": "Tom", "lives": 9, "age": 0}},
+		},
+	}
+	for _, unwrap := range []bool{true, false} {
+		opts := []ParseOpt{OptWithValidate()}
+		if unwrap {
+			opts = append(opts, OptWithUnwrap())
+		}
+		rml, err := ParseFromString(content, "library.raml", t.TempDir(), opts...)
+		require.NoError(t, err)
+		lib := rml.EntryPoint().(*Library)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				s, ok := lib.Types.Get(tt.typeName)
+				require.True(t, ok)
+				if !unwrap {
+					s, err = rml.UnwrapShape(s.CloneDetached())
+					require.NoError(t, err)
+				}
+				err := s.Validate(tt.value)
+				if tt.wantErr != "" {
+					require.ErrorContains(t, err, tt.wantErr)
+					return
+				}
+				require.NoError(t, err)
+			})
+		}
+	}
+}
+
+func TestObjectShape_discriminatedSubtypeConcurrent(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Pet:
+    type: object
+    discriminator: kind
+    properties:
+      kind: string
+  Cat:
+    type: Pet
+    properties:
+      lives?: integer
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+	s, ok := rml.EntryPoint().(*Library).Types.Get("Pet")
+	require.True(t, ok)
+	pet, err := rml.UnwrapShape(s.CloneDetached())
+	require.NoError(t, err)
+	// Subtypes are unwrapped with the shape, validation does not register shapes.
+	shapes := len(rml.GetShapes())
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, pet.Validate(map[string]any{"kind": "Cat"}))
+		}()
+	}
+	wg.Wait()
+	require.Len(t, rml.GetShapes(), shapes)
+}
+
+func TestUnionShape_discriminatedMember(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Event:
+    type: object
+    discriminator: type
+    properties:
+      type: string
+  Created:
+    type: Event
+    discriminatorValue: created
+    properties:
+      id: integer
+  Deleted:
+    type: Event
+    discriminatorValue: deleted
+    properties:
+      reason: string
+  Moved:
+    type: Event
+    discriminatorValue: moved
+    properties:
+      to: string
+  Any: Created | Deleted | Moved
+  Mixed: Created | string
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	union, err := rml.LookupType("Any", "")
+	require.NoError(t, err)
+
+	member := union.Shape.(*UnionShape).discriminatedMember(map[string]any{"type": "moved", "to": "home"})
+	require.NotNil(t, member)
+	require.Equal(t, "moved", member.Shape.(*ObjectShape).DiscriminatorValue)
+	require.Nil(t, union.Shape.(*UnionShape).discriminatedMember(map[string]any{"to": "home"}))
+	mixed, err := rml.LookupType("Mixed", "")
+	require.NoError(t, err)
+	require.Nil(t, mixed.Shape.(*UnionShape).discriminatedMember(map[string]any{"type": "created", "id": 1}))
+
+	require.NoError(t, union.Validate(map[string]any{"type": "deleted", "reason": "spam"}))
+	err = union.Validate(map[string]any{"type": "created", "id": "one"})
+	require.ErrorContains(t, err, "value does not match any type")
+	require.ErrorContains(t, err, "validate property $.id")
+	require.ErrorContains(t, union.Validate(map[string]any{"type": "renamed"}), "value does not match any type")
+
+	// Only the selected member is consulted.
+	_, err = union.ValidateWithOptions(map[string]any{"type": "moved", "to": 1}, ValidateOptions{Explain: true})
+	var explained *ExplainedError
+	require.ErrorAs(t, err, &explained)
+	for _, step := range explained.Steps {
+		require.NotContains(t, step.Note, "union member")
+	}
+	require.Equal(t, "moved", explained.Steps[1].Shape.Shape.(*ObjectShape).DiscriminatorValue)
+	require.Equal(t, "discriminator type", explained.Steps[1].Note)
+
+	// Copies share the dispatch table of the union.
+	c := union.CloneDetached()
+	require.Same(t, union.Shape.(*UnionShape).discriminator, c.Shape.(*UnionShape).discriminator)
+	require.NoError(t, c.Validate(map[string]any{"type": "deleted", "reason": "spam"}))
+
+	// Dispatch tables are computed for restored models.
+	var buf bytes.Buffer
+	require.NoError(t, rml.SaveSnapshot(&buf))
+	restored, err := LoadSnapshot(&buf)
+	require.NoError(t, err)
+	union, err = restored.LookupType("Any", "")
+	require.NoError(t, err)
+	require.NotNil(t, union.Shape.(*UnionShape).discriminatedMember(map[string]any{"type": "moved"}))
+	event, err := restored.LookupType("Event", "")
+	require.NoError(t, err)
+	require.ErrorContains(t, event.Validate(map[string]any{"type": "created", "id": "one"}), "validate property $.id")
+}
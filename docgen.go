@@ -0,0 +1,583 @@
+package raml
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// DocFormat is the output format of the generated documentation.
+type DocFormat string
+
+const (
+	DocFormatMarkdown DocFormat = "markdown"
+	DocFormatHTML     DocFormat = "html"
+)
+
+const defaultDocTitle = "RAML types"
+
+type DocGeneratorOpt interface {
+	Apply(*DocGeneratorOptions)
+}
+
+type DocGeneratorOptions struct {
+	title     string
+	sourceURL string
+}
+
+type optDocTitle struct {
+	title string
+}
+
+func (o optDocTitle) Apply(opts *DocGeneratorOptions) {
+	opts.title = o.title
+}
+
+// WithDocTitle sets the title of the documentation. The usage of the entry point library, the title of the API or
+// the name of the entry point file is used by default.
+func WithDocTitle(title string) DocGeneratorOpt {
+	return optDocTitle{title: title}
+}
+
+type optDocSourceURL struct {
+	url string
+}
+
+func (o optDocSourceURL) Apply(opts *DocGeneratorOptions) {
+	opts.sourceURL = o.url
+}
+
+// WithDocSourceURL links declarations to their source lines. The link is the URL followed by the path of the file
+// relative to the entry point directory and the line anchor, e.g. "https://github.com/org/repo/blob/main/api" gives
+// "https://github.com/org/repo/blob/main/api/common.raml#L12".
+func WithDocSourceURL(url string) DocGeneratorOpt {
+	return optDocSourceURL{url: strings.TrimSuffix(url, "/")}
+}
+
+// DocGenerator builds the documentation of declared types.
+type DocGenerator struct {
+	opts DocGeneratorOptions
+
+	ms    *marshaller
+	names map[int64]string
+	// declarations maps IDs of property shapes to their declarations before unwrapping that keep type expressions.
+	declarations map[int64]*BaseShape
+}
+
+func NewDocGenerator(opts ...DocGeneratorOpt) *DocGenerator {
+	g := &DocGenerator{ms: newMarshaller()}
+	for _, opt := range opts {
+		opt.Apply(&g.opts)
+	}
+	return g
+}
+
+// Doc is the documentation of declared types that can be written in one of DocFormat formats.
+type Doc struct {
+	Title string
+	Usage string
+	// Documentation contains the user documentation of the API.
+	Documentation []DocPage
+	Types         []*DocType
+}
+
+// DocPage is an entry of the user documentation of the API, Content is Markdown.
+type DocPage struct {
+	Title   string
+	Content string
+}
+
+// DocType is the documentation of a declared type with facets resolved through inheritance.
+type DocType struct {
+	*TypeGraphNode
+	Anchor      string
+	Type        []DocTypeToken
+	Description string
+	// Notes are YAML comments above the declaration.
+	Notes      string
+	Facets     []DocFacet
+	Properties []DocProperty
+	Examples   []DocExample
+	Source     DocSource
+}
+
+// DocTypeToken is a part of a type expression. Target is set to the anchor of the documented type the token refers to.
+type DocTypeToken struct {
+	Text   string
+	Target string
+}
+
+type DocFacet struct {
+	Name  string
+	Value string
+}
+
+type DocProperty struct {
+	Name        string
+	Type        []DocTypeToken
+	Required    bool
+	Description string
+	Facets      []DocFacet
+}
+
+type DocExample struct {
+	Name string
+	// Value is the example value in YAML.
+	Value string
+}
+
+// DocSource is the position of the declaration, URL is set with WithDocSourceURL.
+type DocSource struct {
+	Path string
+	Line int
+	URL  string
+}
+
+// Generate builds the documentation of types declared by the entry point, libraries it uses and included data types.
+// Facets and properties are resolved through inheritance, type expressions keep references to declared types.
+func (g *DocGenerator) Generate(r *RAML) (*Doc, error) {
+	graph := r.TypeGraph()
+	g.names = make(map[int64]string, len(graph.Nodes))
+	g.declarations = make(map[int64]*BaseShape)
+	for _, n := range graph.Nodes {
+		g.names[n.Shape.ID] = n.Name
+		if obj, ok := n.Shape.Shape.(*ObjectShape); ok {
+			for pair := obj.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				g.declarations[pair.Value.Shape.ID] = pair.Value.Shape
+			}
+			for pair := obj.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+				g.declarations[pair.Value.Shape.ID] = pair.Value.Shape
+			}
+		}
+	}
+	doc := &Doc{Title: g.opts.title}
+	if lib, ok := r.EntryPoint().(*Library); ok {
+		doc.Usage = lib.Usage
+		if doc.Title == "" {
+			doc.Title = lib.Usage
+		}
+	}
+	if api := r.API(); api != nil {
+		doc.Usage = api.Description
+		if doc.Title == "" {
+			doc.Title = api.Title
+		}
+		for _, item := range api.Documentation {
+			doc.Documentation = append(doc.Documentation, DocPage{Title: item.Title, Content: item.Content})
+		}
+	}
+	if doc.Title == "" {
+		doc.Title = defaultDocTitle
+		if loc := r.GetLocation(); loc != "" {
+			doc.Title = filepath.Base(loc)
+		}
+	}
+	baseDir := filepath.Dir(r.GetLocation())
+	for _, n := range graph.Nodes {
+		t, err := g.docType(r, n, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("document type %s: %w", n.Name, err)
+		}
+		doc.Types = append(doc.Types, t)
+	}
+	return doc, nil
+}
+
+func (g *DocGenerator) docType(r *RAML, n *TypeGraphNode, baseDir string) (*DocType, error) {
+	s := n.Shape
+	if !s.IsUnwrapped() {
+		us, err := r.UnwrapShape(s.CloneDetached())
+		if err != nil {
+			return nil, fmt.Errorf("unwrap: %w", err)
+		}
+		s = us
+	}
+	t := &DocType{
+		TypeGraphNode: n,
+		Anchor:        docAnchor(n.Name),
+		Source:        g.docSource(n.Location, n.Shape.Position.Line, baseDir),
+	}
+	t.Type = g.typeTokens(r, TypeExpression(n.Shape), n.Location)
+	if s.Description != nil {
+		t.Description = *s.Description
+	}
+	if n.Shape.Comments != nil {
+		t.Notes = n.Shape.Comments.Head
+	}
+	facets, err := g.facets(s)
+	if err != nil {
+		return nil, err
+	}
+	t.Facets = facets
+	if obj, ok := s.Shape.(*ObjectShape); ok {
+		if t.Properties, err = g.properties(r, obj); err != nil {
+			return nil, err
+		}
+	}
+	t.Examples, err = docExamples(s)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (g *DocGenerator) docSource(location string, line int, baseDir string) DocSource {
+	path, err := filepath.Rel(baseDir, location)
+	if err != nil {
+		path = location
+	}
+	path = filepath.ToSlash(path)
+	src := DocSource{Path: path, Line: line}
+	if g.opts.sourceURL != "" {
+		src.URL = g.opts.sourceURL + "/" + path + "#L" + strconv.Itoa(line)
+	}
+	return src
+}
+
+func (g *DocGenerator) properties(r *RAML, obj *ObjectShape) ([]DocProperty, error) {
+	var props []DocProperty
+	add := func(name string, s *BaseShape, required bool) error {
+		p := DocProperty{Name: name, Required: required}
+		// Merged facets of unwrapped shapes prevent writing them as type expressions.
+		decl, ok := g.declarations[s.ID]
+		if !ok {
+			decl = s
+		}
+		expr, ok := g.ms.typeExpression(decl)
+		if !ok {
+			expr = s.Type
+		}
+		p.Type = g.typeTokens(r, expr, s.Location())
+		if s.Description != nil {
+			p.Description = *s.Description
+		}
+		facets, err := g.facets(s)
+		if err != nil {
+			return fmt.Errorf("property %s: %w", name, err)
+		}
+		p.Facets = facets
+		props = append(props, p)
+		return nil
+	}
+	for pair := obj.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		if err := add(pair.Key, pair.Value.Shape, pair.Value.Required); err != nil {
+			return nil, err
+		}
+	}
+	for pair := obj.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		if err := add(pair.Key, pair.Value.Shape, false); err != nil {
+			return nil, err
+		}
+	}
+	return props, nil
+}
+
+// facets returns facets of the shape except properties and items that are documented separately.
+func (g *DocGenerator) facets(s *BaseShape) ([]DocFacet, error) {
+	// Properties and items are detached from a copy of the shape so that they are not serialized.
+	c := *s
+	switch shape := s.Shape.(type) {
+	case *ObjectShape:
+		obj := *shape
+		obj.Properties, obj.PatternProperties = nil, nil
+		c.Shape = &obj
+	case *ArrayShape:
+		arr := *shape
+		arr.Items = nil
+		c.Shape = &arr
+	}
+	nodes, err := g.ms.facets(&c)
+	if err != nil {
+		return nil, fmt.Errorf("facets: %w", err)
+	}
+	var facets []DocFacet
+	for i := 0; i+1 < len(nodes); i += 2 {
+		key := nodes[i].Value
+		if key == FacetProperties || key == FacetItems {
+			continue
+		}
+		facets = append(facets, DocFacet{Name: key, Value: docNodeString(nodes[i+1])})
+	}
+	for pair := s.CustomShapeFacets.Oldest(); pair != nil; pair = pair.Next() {
+		n, err := newValueNode(pair.Value.Value)
+		if err != nil {
+			return nil, fmt.Errorf("custom facet %s: %w", pair.Key, err)
+		}
+		facets = append(facets, DocFacet{Name: "(" + pair.Key + ")", Value: docNodeString(n)})
+	}
+	return facets, nil
+}
+
+var typeExpressionName = regexp.MustCompile(`[A-Za-z_][\w.-]*`)
+
+// typeTokens splits the type expression into tokens and links names of documented types.
+func (g *DocGenerator) typeTokens(r *RAML, expr string, location string) []DocTypeToken {
+	var tokens []DocTypeToken
+	last := 0
+	for _, m := range typeExpressionName.FindAllStringIndex(expr, -1) {
+		name := expr[m[0]:m[1]]
+		ref, err := r.LookupType(name, location)
+		if err != nil {
+			continue
+		}
+		qualified, ok := g.names[ref.ID]
+		if !ok {
+			continue
+		}
+		if m[0] > last {
+			tokens = append(tokens, DocTypeToken{Text: expr[last:m[0]]})
+		}
+		tokens = append(tokens, DocTypeToken{Text: name, Target: docAnchor(qualified)})
+		last = m[1]
+	}
+	if last < len(expr) {
+		tokens = append(tokens, DocTypeToken{Text: expr[last:]})
+	}
+	return tokens
+}
+
+func docExamples(s *BaseShape) ([]DocExample, error) {
+	var examples []DocExample
+	add := func(name string, ex *Example) error {
+		var value any
+		if ex.Data != nil {
+			value = ex.Data.Value
+		}
+		var sb strings.Builder
+		enc := yaml.NewEncoder(&sb)
+		enc.SetIndent(marshalIndent)
+		if err := enc.Encode(value); err != nil {
+			return fmt.Errorf("marshal example %s: %w", name, err)
+		}
+		examples = append(examples, DocExample{Name: name, Value: strings.TrimSuffix(sb.String(), "\n")})
+		return nil
+	}
+	if s.Example != nil {
+		if err := add("", s.Example); err != nil {
+			return nil, err
+		}
+	}
+	if s.Examples == nil {
+		return examples, nil
+	}
+	var m *orderedmap.OrderedMap[string, *Example]
+	if s.Examples.Link != nil {
+		m = s.Examples.Link.Map
+	} else {
+		m = s.Examples.Map
+	}
+	for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+		if err := add(pair.Key, pair.Value); err != nil {
+			return nil, err
+		}
+	}
+	return examples, nil
+}
+
+// docNodeString returns the value of the scalar or the flow style YAML of the collection.
+func docNodeString(n *yaml.Node) string {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	c := *n
+	c.Style = yaml.FlowStyle
+	b, err := yaml.Marshal(&c)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(string(b), "\n")
+}
+
+var anchorReplacer = regexp.MustCompile(`[^a-z0-9]+`)
+
+func docAnchor(name string) string {
+	return "type-" + strings.Trim(anchorReplacer.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// Write renders the documentation in the format.
+func (d *Doc) Write(w io.Writer, format DocFormat) error {
+	switch format {
+	case DocFormatMarkdown:
+		return d.writeMarkdown(w)
+	case DocFormatHTML:
+		return d.writeHTML(w)
+	}
+	return fmt.Errorf("unknown documentation format %q", format)
+}
+
+func (d *Doc) writeMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", d.Title)
+	if d.Usage != "" && d.Usage != d.Title {
+		fmt.Fprintf(&sb, "%s\n\n", d.Usage)
+	}
+	for _, p := range d.Documentation {
+		fmt.Fprintf(&sb, "## %s\n\n%s\n\n", p.Title, strings.TrimSpace(p.Content))
+	}
+	sb.WriteString("## Types\n\n")
+	for _, t := range d.Types {
+		fmt.Fprintf(&sb, "* [%s](#%s)\n", t.Name, t.Anchor)
+	}
+	sb.WriteString("\n")
+	for _, t := range d.Types {
+		fmt.Fprintf(&sb, "### <a id=\"%s\"></a>%s\n\n", t.Anchor, t.Name)
+		fmt.Fprintf(&sb, "Type: %s\n\n", markdownType(t.Type))
+		source := fmt.Sprintf("%s:%d", t.Source.Path, t.Source.Line)
+		if t.Source.URL != "" {
+			fmt.Fprintf(&sb, "Source: [%s](%s)\n\n", source, t.Source.URL)
+		} else {
+			fmt.Fprintf(&sb, "Source: `%s`\n\n", source)
+		}
+		if t.Description != "" {
+			fmt.Fprintf(&sb, "%s\n\n", t.Description)
+		}
+		if t.Notes != "" {
+			fmt.Fprintf(&sb, "> %s\n\n", strings.ReplaceAll(t.Notes, "\n", "\n> "))
+		}
+		if len(t.Facets) > 0 {
+			sb.WriteString("| Facet | Value |\n| --- | --- |\n")
+			for _, f := range t.Facets {
+				fmt.Fprintf(&sb, "| %s | %s |\n", f.Name, markdownCell(f.Value))
+			}
+			sb.WriteString("\n")
+		}
+		if len(t.Properties) > 0 {
+			sb.WriteString("| Property | Type | Required | Facets | Description |\n| --- | --- | --- | --- | --- |\n")
+			for _, p := range t.Properties {
+				fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n", markdownCell(p.Name), markdownType(p.Type),
+					docYesNo(p.Required), markdownCell(docFacetsString(p.Facets)), markdownCell(p.Description))
+			}
+			sb.WriteString("\n")
+		}
+		for _, ex := range t.Examples {
+			if ex.Name != "" {
+				fmt.Fprintf(&sb, "Example `%s`:\n\n", ex.Name)
+			} else {
+				sb.WriteString("Example:\n\n")
+			}
+			fmt.Fprintf(&sb, "```yaml\n%s\n```\n\n", ex.Value)
+		}
+	}
+	if _, err := io.WriteString(w, strings.TrimSuffix(sb.String(), "\n")); err != nil {
+		return fmt.Errorf("write markdown: %w", err)
+	}
+	return nil
+}
+
+func markdownType(tokens []DocTypeToken) string {
+	var sb strings.Builder
+	for _, tok := range tokens {
+		text := strings.ReplaceAll(tok.Text, "|", `\|`)
+		if tok.Target != "" {
+			fmt.Fprintf(&sb, "[%s](#%s)", text, tok.Target)
+		} else {
+			sb.WriteString(text)
+		}
+	}
+	return sb.String()
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+func docFacetsString(facets []DocFacet) string {
+	parts := make([]string, len(facets))
+	for i, f := range facets {
+		parts[i] = f.Name + ": " + f.Value
+	}
+	return strings.Join(parts, ", ")
+}
+
+func docYesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+var docHTMLTemplate = template.Must(template.New("doc").Funcs(template.FuncMap{
+	"facets": docFacetsString,
+	"yesno":  docYesNo,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 0 auto; padding: 1em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+pre { background: #f5f5f5; padding: 0.6em; }
+.notes { color: #555; border-left: 3px solid #ccc; padding-left: 0.6em; white-space: pre-line; }
+.content { white-space: pre-line; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if and .Usage (ne .Usage .Title)}}
+<p>{{.Usage}}</p>
+{{- end}}
+{{- range .Documentation}}
+<section>
+<h2>{{.Title}}</h2>
+<div class="content">{{.Content}}</div>
+</section>
+{{- end}}
+<h2>Types</h2>
+<ul>
+{{- range .Types}}
+<li><a href="#{{.Anchor}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+{{- range .Types}}
+<section id="{{.Anchor}}">
+<h3>{{.Name}}</h3>
+<p>Type: {{template "type" .Type}}</p>
+<p>Source: {{if .Source.URL}}<a href="{{.Source.URL}}">{{.Source.Path}}:{{.Source.Line}}</a>{{else}}<code>{{.Source.Path}}:{{.Source.Line}}</code>{{end}}</p>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+{{- if .Notes}}
+<p class="notes">{{.Notes}}</p>
+{{- end}}
+{{- if .Facets}}
+<table>
+<tr><th>Facet</th><th>Value</th></tr>
+{{- range .Facets}}
+<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Properties}}
+<table>
+<tr><th>Property</th><th>Type</th><th>Required</th><th>Facets</th><th>Description</th></tr>
+{{- range .Properties}}
+<tr><td>{{.Name}}</td><td>{{template "type" .Type}}</td><td>{{yesno .Required}}</td><td>{{facets .Facets}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- range .Examples}}
+<p>Example{{if .Name}} <code>{{.Name}}</code>{{end}}:</p>
+<pre>{{.Value}}</pre>
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+{{define "type"}}<code>{{range .}}{{if .Target}}<a href="#{{.Target}}">{{.Text}}</a>{{else}}{{.Text}}{{end}}{{end}}</code>{{end}}`))
+
+func (d *Doc) writeHTML(w io.Writer) error {
+	if err := docHTMLTemplate.Execute(w, d); err != nil {
+		return fmt.Errorf("write html: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package raml
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocGenerator_Generate(t *testing.T) {
+	dir, err := filepath.Abs("./fixtures/docgen")
+	require.NoError(t, err)
+	content := `#%RAML 1.0 Library
+usage: Pet store
+uses:
+  common: common.raml
+types:
+  Base:
+    type: object
+    properties:
+      id: integer
+  # Pets are stored forever.
+  Pet:
+    type: Base
+    description: A <pet>.
+    properties:
+      name:
+        type: string
+        minLength: 1
+      tags: common.Tag[]
+      kind: string | common.Tag
+      parent?: Pet
+    example:
+      id: 1
+      name: Rex
+      tags: [small]
+      kind: dog
+`
+	rml, err := ParseFromString(content, "library.raml", dir)
+	require.NoError(t, err)
+	doc, err := NewDocGenerator(WithDocSourceURL("https://example.com/api/")).Generate(rml)
+	require.NoError(t, err)
+
+	require.Equal(t, "Pet store", doc.Title)
+	names := make([]string, len(doc.Types))
+	for i, dt := range doc.Types {
+		names[i] = dt.Name
+	}
+	require.Equal(t, []string{"Base", "Pet", "common.Tag"}, names)
+
+	pet := doc.Types[1]
+	require.Equal(t, []DocTypeToken{{Text: "Base", Target: "type-base"}}, pet.Type)
+	require.Equal(t, "Pets are stored forever.", pet.Notes)
+	require.Equal(t, DocSource{Path: "library.raml", Line: 12, URL: "https://example.com/api/library.raml#L12"},
+		pet.Source)
+	require.Len(t, pet.Properties, 5)
+	require.Equal(t, DocProperty{Name: "name", Type: []DocTypeToken{{Text: "string"}}, Required: true,
+		Facets: []DocFacet{{Name: "minLength", Value: "1"}}}, pet.Properties[0])
+	require.Equal(t, []DocTypeToken{{Text: "common.Tag", Target: "type-common-tag"}, {Text: "[]"}},
+		pet.Properties[1].Type)
+	require.Equal(t, []DocTypeToken{{Text: "string | "}, {Text: "common.Tag", Target: "type-common-tag"}},
+		pet.Properties[2].Type)
+	require.False(t, pet.Properties[3].Required)
+	require.Equal(t, "id", pet.Properties[4].Name)
+	require.Equal(t, []DocExample{{Value: "id: 1\nkind: dog\nname: Rex\ntags:\n  - small"}}, pet.Examples)
+
+	tag := doc.Types[2]
+	require.Equal(t, "common.raml", tag.Source.Path)
+	require.Equal(t, []DocFacet{{Name: "enum", Value: "[small, big]"}}, tag.Facets)
+
+	var md strings.Builder
+	require.NoError(t, doc.Write(&md, DocFormatMarkdown))
+	require.Contains(t, md.String(), "\n\n### <a id=\"type-pet\"></a>Pet\n\nType: [Base](#type-base)\n")
+	require.NotContains(t, md.String(), "\n\n\n")
+	require.Contains(t, md.String(), "| kind | string \\| [common.Tag](#type-common-tag) | yes |  |  |\n")
+	require.Contains(t, md.String(), "Source: [common.raml:4](https://example.com/api/common.raml#L4)")
+
+	var html strings.Builder
+	require.NoError(t, doc.Write(&html, DocFormatHTML))
+	require.Contains(t, html.String(), "<p>A &lt;pet&gt;.</p>")
+	require.Contains(t, html.String(), `<section id="type-common-tag">`)
+
+	require.Error(t, doc.Write(&html, "pdf"))
+}
@@ -0,0 +1,76 @@
+package raml
+
+import (
+	"fmt"
+
+	"github.com/acronis/go-stacktrace"
+	"gopkg.in/yaml.v3"
+)
+
+type parseOptWithStrictDuplicateKeys struct{}
+
+func (parseOptWithStrictDuplicateKeys) Apply(opt *parserOptions) {
+	opt.strictDuplicateKeys = true
+}
+
+// OptWithStrictDuplicateKeys makes duplicate keys in fragments parsing errors. By default, duplicate keys produce
+// warnings (see RAML.Warnings) and the last value of the key is used.
+func OptWithStrictDuplicateKeys() ParseOpt {
+	return parseOptWithStrictDuplicateKeys{}
+}
+
+// checkDuplicateKeys reports keys that occur more than once in the same mapping of the fragment, e.g. two types with
+// the same name. YAML requires keys to be unique, but mappings are decoded from nodes, so the last value would be
+// taken silently.
+func (r *RAML) checkDuplicateKeys(root *yaml.Node, location string) error {
+	var duplicates []*stacktrace.StackTrace
+	var walk func(node *yaml.Node, what string)
+	walk = func(node *yaml.Node, what string) {
+		switch node.Kind {
+		case yaml.DocumentNode, yaml.SequenceNode:
+			for _, item := range node.Content {
+				walk(item, "key")
+			}
+		case yaml.MappingNode:
+			seen := make(map[string]*yaml.Node, len(node.Content)/2)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				keyNode, valueNode := node.Content[i], node.Content[i+1]
+				if prev, ok := seen[keyNode.Value]; ok && keyNode.Kind == yaml.ScalarNode {
+					duplicates = append(duplicates, stacktrace.New(fmt.Sprintf("duplicate %s %q", what, keyNode.Value),
+						location, WithNodePosition(keyNode), stacktrace.WithType(stacktrace.TypeParsing),
+						stacktrace.WithInfo("previous", fmt.Sprintf("line %d", prev.Line))))
+				}
+				seen[keyNode.Value] = keyNode
+				walk(valueNode, childKeysKind(node == root, keyNode.Value))
+			}
+		}
+	}
+	walk(root, "key")
+	if len(duplicates) == 0 {
+		return nil
+	}
+	if r.strictDuplicateKeys {
+		st := duplicates[0]
+		for _, se := range duplicates[1:] {
+			st = st.Append(se)
+		}
+		return st
+	}
+	for _, se := range duplicates {
+		r.warnings = append(r.warnings, se.SetSeverity(stacktrace.SeverityWarning))
+	}
+	return nil
+}
+
+// childKeysKind returns what keys of the mapping under the key declare, used in messages.
+func childKeysKind(isRoot bool, key string) string {
+	switch {
+	case isRoot && key == "types":
+		return "type"
+	case isRoot && key == "annotationTypes":
+		return "annotation type"
+	case key == "properties":
+		return "property"
+	}
+	return "key"
+}
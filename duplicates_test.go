@@ -0,0 +1,48 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/acronis/go-stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+const duplicatesLibrary = `#%RAML 1.0 Library
+types:
+  Pet:
+    type: object
+    properties:
+      name: string
+      name: integer
+  Pet:
+    type: object
+    properties:
+      age: integer
+    description: first
+    description: second
+`
+
+func TestRAML_checkDuplicateKeys(t *testing.T) {
+	t.Run("warnings", func(t *testing.T) {
+		rml, err := ParseFromString(duplicatesLibrary, "library.raml", t.TempDir())
+		require.NoError(t, err)
+		warnings := rml.Warnings()
+		require.Len(t, warnings, 3)
+		require.Equal(t, stacktrace.SeverityWarning, warnings[0].Severity)
+		require.Equal(t, "duplicate property \"name\"", warnings[0].Message)
+		require.Equal(t, 7, warnings[0].Position.Line)
+		require.Equal(t, "duplicate type \"Pet\"", warnings[1].Message)
+		require.Equal(t, 8, warnings[1].Position.Line)
+		require.Equal(t, "duplicate key \"description\"", warnings[2].Message)
+
+		// The last value is used.
+		pet, _ := rml.EntryPoint().(*Library).Types.Get("Pet")
+		require.Equal(t, "second", *pet.Description)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		_, err := ParseFromString(duplicatesLibrary, "library.raml", t.TempDir(), OptWithStrictDuplicateKeys())
+		require.ErrorContains(t, err, "duplicate property \"name\"")
+		require.ErrorContains(t, err, "and more (2)")
+	})
+}
@@ -1,8 +1,11 @@
 package raml
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
+	"strings"
 
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 	"gopkg.in/yaml.v3"
@@ -40,13 +43,16 @@ func (ex *Example) decode(node *yaml.Node, valueNode *yaml.Node, location string
 
 func (ex *Example) fill(location string, value *yaml.Node) error {
 	var valueKey *yaml.Node
-	// First lookup for the "value" key.
+	// First lookup for the "value" key. Maps with other keys than facets of examples are values of objects that
+	// happen to have the "value" property.
 	for i := 0; i != len(value.Content); i += 2 {
 		node := value.Content[i]
 		valueNode := value.Content[i+1]
-		if node.Value == "value" {
+		switch {
+		case node.Value == "value":
 			valueKey = valueNode
-			break
+		case !isExampleFacet(node.Value):
+			return ErrValueKeyNotFound
 		}
 	}
 
@@ -70,6 +76,15 @@ func (ex *Example) fill(location string, value *yaml.Node) error {
 	return nil
 }
 
+// isExampleFacet reports whether the key is a facet of the example object form besides "value".
+func isExampleFacet(key string) bool {
+	switch key {
+	case "strict", "displayName", "description":
+		return true
+	}
+	return IsCustomDomainExtensionNode(key)
+}
+
 // makeExample creates an example from the given value node
 func (r *RAML) makeExample(value *yaml.Node, name string, location string) (*Example, error) {
 	ex := &Example{
@@ -115,3 +130,109 @@ type Example struct {
 	stacktrace.Position
 	raml *RAML
 }
+
+// ErrExampleNotFound is returned by LookupExample and ExampleJSON if the shape has no example with the name.
+var ErrExampleNotFound = errors.New("example not found")
+
+// Value returns the value of the example.
+func (ex *Example) Value() any {
+	if ex.Data == nil {
+		return nil
+	}
+	return ex.Data.Value
+}
+
+// JSON returns the value of the example encoded as JSON.
+func (ex *Example) JSON() ([]byte, error) {
+	b, err := json.Marshal(ex.Value())
+	if err != nil {
+		return nil, StacktraceNewWrapped("marshal example", err, ex.Location,
+			stacktrace.WithPosition(&ex.Position))
+	}
+	return b, nil
+}
+
+// Get returns the example by name. Examples of an included NamedExample fragment are looked up in the fragment.
+func (exs *Examples) Get(name string) (*Example, bool) {
+	m := exs.Map
+	if exs.Link != nil {
+		m = exs.Link.Map
+	}
+	if m == nil {
+		return nil, false
+	}
+	return m.Get(name)
+}
+
+// Names returns names of the examples in the order of declaration.
+func (exs *Examples) Names() []string {
+	m := exs.Map
+	if exs.Link != nil {
+		m = exs.Link.Map
+	}
+	if m == nil {
+		return nil
+	}
+	names := make([]string, 0, m.Len())
+	for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+		names = append(names, pair.Key)
+	}
+	return names
+}
+
+// LookupExample returns the example of the shape by name. The empty name refers to the single example declared
+// with the "example" facet or, if there is none, to the first one of the "examples" facet.
+func (s *BaseShape) LookupExample(name string) (*Example, error) {
+	if name == "" && s.Example != nil {
+		return s.Example, nil
+	}
+	if s.Examples != nil {
+		if name == "" {
+			if names := s.Examples.Names(); len(names) > 0 {
+				name = names[0]
+			}
+		}
+		if ex, ok := s.Examples.Get(name); ok {
+			return ex, nil
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: shape %s has no examples", ErrExampleNotFound, s.Name)
+	}
+	return nil, fmt.Errorf("%w: %s of shape %s", ErrExampleNotFound, name, s.Name)
+}
+
+// ExampleJSON returns the named example of the body selected for the media type, encoded as JSON. Bodies and
+// defaultMediaTypes are selected as in SelectBody. The media type must be JSON, e.g. "application/json" or
+// "application/vnd.api+json"; wildcards resolve to the media type of the selected body.
+//
+// Bodies of API definitions are Method.Body and Response.Body, and defaultMediaTypes are API.MediaType.
+func ExampleJSON(
+	bodies *orderedmap.OrderedMap[string, *BaseShape], defaultMediaTypes []string, mediaType string, name string,
+) ([]byte, error) {
+	body, declared, err := SelectBody(bodies, defaultMediaTypes, mediaType)
+	if err != nil {
+		return nil, fmt.Errorf("select body: %w", err)
+	}
+	effective, _, _ := mime.ParseMediaType(mediaType)
+	if strings.Contains(effective, "*") {
+		effective = declared
+	}
+	if effective != "" && !isJSONMediaType(effective) {
+		return nil, fmt.Errorf("render example as %s: media type is not JSON", effective)
+	}
+	ex, err := body.LookupExample(name)
+	if err != nil {
+		return nil, err
+	}
+	return ex.JSON()
+}
+
+// isJSONMediaType reports whether the media type is JSON or has the JSON structured syntax suffix.
+func isJSONMediaType(mediaType string) bool {
+	d, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return false
+	}
+	return d == "application/json" || mediaTypeSuffix(d) == "application/json"
+}
@@ -0,0 +1,137 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+func TestExample_objectForm(t *testing.T) {
+	content := `#%RAML 1.0 Library
+annotationTypes:
+  reviewed: boolean
+types:
+  Setting:
+    properties:
+      value: integer
+      unit?: string
+    examples:
+      typed:
+        displayName: Typed
+        description: A typed example.
+        (reviewed): true
+        value:
+          value: 2
+      loose:
+        strict: false
+        value:
+          value: unknown
+  Timeout:
+    type: Setting
+    example:
+      value: 1
+      unit: s
+  Count:
+    type: integer
+    example:
+      strict: false
+      value: many
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithValidate())
+	require.NoError(t, err)
+	setting, err := rml.LookupType("Setting", "")
+	require.NoError(t, err)
+
+	// The map has a key besides facets of examples, so it is the value itself.
+	timeout, err := rml.LookupType("Timeout", "")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"value": 1, "unit": "s"}, timeout.Example.Data.Value)
+	require.True(t, timeout.Example.Strict)
+
+	typed, ok := setting.Examples.Map.Get("typed")
+	require.True(t, ok)
+	require.Equal(t, "Typed", typed.DisplayName)
+	require.Equal(t, "A typed example.", typed.Description)
+	require.True(t, typed.Strict)
+	require.Equal(t, map[string]any{"value": 2}, typed.Data.Value)
+	_, ok = typed.CustomDomainProperties.Get("reviewed")
+	require.True(t, ok)
+
+	loose, ok := setting.Examples.Map.Get("loose")
+	require.True(t, ok)
+	require.False(t, loose.Strict)
+
+	count, err := rml.LookupType("Count", "")
+	require.NoError(t, err)
+	require.False(t, count.Example.Strict)
+	require.Equal(t, "many", count.Example.Data.Value)
+
+	_, err = ParseFromString(`#%RAML 1.0 Library
+types:
+  Count:
+    type: integer
+    example:
+      value: many
+`, "library.raml", t.TempDir(), OptWithValidate())
+	require.ErrorContains(t, err, "validate example")
+}
+
+func TestBaseShape_LookupExample(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Pet:
+    properties:
+      name: string
+    examples:
+      cat:
+        name: Tom
+      dog:
+        strict: false
+        value:
+          name: Rex
+  Tag:
+    type: string
+    example: small
+  Note: string
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+	lookup := func(name string) *BaseShape {
+		s, err := rml.LookupType(name, "")
+		require.NoError(t, err)
+		return s
+	}
+	pet := lookup("Pet")
+	require.Equal(t, []string{"cat", "dog"}, pet.Examples.Names())
+
+	ex, err := pet.LookupExample("dog")
+	require.NoError(t, err)
+	require.False(t, ex.Strict)
+	require.Equal(t, map[string]any{"name": "Rex"}, ex.Value())
+	ex, err = pet.LookupExample("")
+	require.NoError(t, err)
+	require.Equal(t, "cat", ex.Name)
+	_, err = pet.LookupExample("bird")
+	require.ErrorIs(t, err, ErrExampleNotFound)
+
+	ex, err = lookup("Tag").LookupExample("")
+	require.NoError(t, err)
+	require.Equal(t, "small", ex.Value())
+	_, err = lookup("Note").LookupExample("")
+	require.ErrorIs(t, err, ErrExampleNotFound)
+
+	bodies := orderedmap.New[string, *BaseShape]()
+	bodies.Set("application/json", pet)
+	bodies.Set("text/plain", lookup("Tag"))
+	b, err := ExampleJSON(bodies, nil, "application/vnd.api+json", "dog")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name": "Rex"}`, string(b))
+	b, err = ExampleJSON(bodies, nil, "*/*", "")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name": "Tom"}`, string(b))
+	_, err = ExampleJSON(bodies, nil, "text/plain", "")
+	require.ErrorContains(t, err, "media type is not JSON")
+	_, err = ExampleJSON(bodies, nil, "image/png", "")
+	require.ErrorIs(t, err, ErrBodyNotFound)
+}
@@ -0,0 +1,154 @@
+package raml
+
+import (
+	"fmt"
+
+	"github.com/acronis/go-stacktrace"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// CustomFacet is a user-defined facet declared under "facets" of a parent type and assigned on the shape.
+type CustomFacet struct {
+	Name string
+	// Definition is the declaration of the facet in the parent type.
+	Definition Property
+	// Value is the value assigned on the shape or one of its parents, nil if an optional facet is not assigned.
+	Value *Node
+}
+
+// inheritedFacetDefinitions returns custom facet definitions declared by all ancestors of the shape,
+// including all parents of multiple inheritance.
+func (s *BaseShape) inheritedFacetDefinitions() *orderedmap.OrderedMap[string, Property] {
+	defs := orderedmap.New[string, Property]()
+	visited := make(map[int64]struct{})
+	var visit func(parent *BaseShape)
+	visit = func(parent *BaseShape) {
+		if _, ok := visited[parent.ID]; ok {
+			return
+		}
+		visited[parent.ID] = struct{}{}
+		for pair := parent.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
+			if _, ok := defs.Get(pair.Key); !ok {
+				defs.Set(pair.Key, pair.Value)
+			}
+		}
+		// References to parents are resolved either to aliases or to links of included data types.
+		if parent.Alias != nil {
+			visit(parent.Alias)
+		}
+		if parent.Link != nil {
+			visit(parent.Link.Shape)
+		}
+		for _, p := range parent.Inherits {
+			visit(p)
+		}
+	}
+	for _, parent := range s.Inherits {
+		visit(parent)
+	}
+	return defs
+}
+
+// CustomFacets returns custom facets declared by the parents of the shape together with assigned values
+// in the order of declaration.
+func (s *BaseShape) CustomFacets() []CustomFacet {
+	defs := s.inheritedFacetDefinitions()
+	facets := make([]CustomFacet, 0, defs.Len())
+	for pair := defs.Oldest(); pair != nil; pair = pair.Next() {
+		value, _ := orderedGet(s.CustomShapeFacets, pair.Key)
+		facets = append(facets, CustomFacet{Name: pair.Key, Definition: pair.Value, Value: value})
+	}
+	return facets
+}
+
+// CustomFacet returns the custom facet declared by the parents of the shape.
+func (s *BaseShape) CustomFacet(name string) (CustomFacet, bool) {
+	def, ok := s.inheritedFacetDefinitions().Get(name)
+	if !ok {
+		return CustomFacet{}, false
+	}
+	value, _ := orderedGet(s.CustomShapeFacets, name)
+	return CustomFacet{Name: name, Definition: def, Value: value}, true
+}
+
+// CustomFacetValue returns the value of the custom facet converted to T.
+// The default value of the facet definition is used if the facet is not assigned.
+// Values that are not of type T are converted through YAML, e.g. integers to float64 or maps to structs.
+func CustomFacetValue[T any](s *BaseShape, name string) (T, error) {
+	var v T
+	f, ok := s.CustomFacet(name)
+	if !ok {
+		return v, fmt.Errorf("custom facet %s is not declared", name)
+	}
+	value := f.Value
+	if value == nil {
+		value = f.Definition.Shape.Default
+	}
+	if value == nil {
+		return v, fmt.Errorf("custom facet %s is not assigned", name)
+	}
+	if typed, isT := value.Value.(T); isT {
+		return typed, nil
+	}
+	b, err := yaml.Marshal(value.Value)
+	if err != nil {
+		return v, fmt.Errorf("marshal custom facet %s: %w", name, err)
+	}
+	if err = yaml.Unmarshal(b, &v); err != nil {
+		return v, fmt.Errorf("convert custom facet %s to %T: %w", name, v, err)
+	}
+	return v, nil
+}
+
+// FacetValidator checks a shape that carries the custom facet or the annotation the validator is registered for.
+// Value is the value of the facet or the annotation.
+type FacetValidator func(shape Shape, value *Node) error
+
+// RegisterFacetValidator registers the validator that is called during validation (see OptWithValidate) for every
+// shape that has the custom facet or the annotation with the given name. Annotations of used libraries are named
+// with the namespace, e.g. "lib.pii". Validators are called in the order of registration.
+func (r *RAML) RegisterFacetValidator(name string, validator FacetValidator) {
+	if r.facetValidators == nil {
+		r.facetValidators = make(map[string][]FacetValidator)
+	}
+	r.facetValidators[name] = append(r.facetValidators[name], validator)
+}
+
+type parseOptWithFacetValidator struct {
+	name      string
+	validator FacetValidator
+}
+
+func (o parseOptWithFacetValidator) Apply(opt *parserOptions) {
+	opt.facetValidators = append(opt.facetValidators, o)
+}
+
+// OptWithFacetValidator registers the facet validator, see RAML.RegisterFacetValidator.
+func OptWithFacetValidator(name string, validator FacetValidator) ParseOpt {
+	return parseOptWithFacetValidator{name: name, validator: validator}
+}
+
+// runFacetValidators calls registered validators for custom facets and annotations of the shape.
+func (r *RAML) runFacetValidators(base *BaseShape) error {
+	if len(r.facetValidators) == 0 {
+		return nil
+	}
+	for pair := base.CustomShapeFacets.Oldest(); pair != nil; pair = pair.Next() {
+		for _, validator := range r.facetValidators[pair.Key] {
+			if err := validator(base.Shape, pair.Value); err != nil {
+				return StacktraceNewWrapped("validate facet", err, base.Location(),
+					stacktrace.WithPosition(&base.Position), stacktrace.WithInfo("facet", pair.Key))
+			}
+		}
+	}
+	for pair := base.CustomDomainProperties.Oldest(); pair != nil; pair = pair.Next() {
+		for _, validator := range r.facetValidators[pair.Key] {
+			if err := validator(base.Shape, pair.Value.Extension); err != nil {
+				return StacktraceNewWrapped("validate annotation", err, base.Location(),
+					stacktrace.WithPosition(&base.Position), stacktrace.WithInfo("annotation", pair.Key))
+			}
+		}
+	}
+	return nil
+}
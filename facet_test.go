@@ -0,0 +1,212 @@
+package raml
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAML_validateShapeFacets(t *testing.T) {
+	tests := []struct {
+		name    string
+		types   string
+		wantErr string
+	}{
+		{
+			name: "required facet of the second parent is missing",
+			types: `
+  P:
+    type: object
+    facets:
+      unit: string
+  Q:
+    type: object
+    facets:
+      scale: integer
+  C:
+    type: [P, Q]
+    unit: m
+`,
+			wantErr: "required custom facet is missing: facet: scale",
+		},
+		{
+			name: "facet of the second parent is known",
+			types: `
+  P:
+    type: object
+    facets:
+      unit?: string
+  Q:
+    type: object
+    facets:
+      scale: integer
+  C:
+    type: [P, Q]
+    scale: 2
+`,
+		},
+		{
+			name: "facet is assigned on the intermediate type",
+			types: `
+  P:
+    type: string
+    facets:
+      unit: string
+  C:
+    type: P
+    unit: m
+  G:
+    type: C
+    minLength: 2
+`,
+		},
+		{
+			name: "facet is redeclared",
+			types: `
+  P:
+    type: string
+    facets:
+      unit?: string
+  C:
+    type: P
+    facets:
+      unit: string
+`,
+			wantErr: "duplicate custom facet",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFromString("#%RAML 1.0 Library\ntypes:"+tt.types, "library.raml", t.TempDir(),
+				OptWithValidate())
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCustomFacetValue(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Measure:
+    type: number
+    facets:
+      unit: string
+      scale?:
+        type: number
+        default: 1
+      range?:
+        properties:
+          from: integer
+          to: integer
+  Length:
+    type: Measure
+    unit: m
+    range:
+      from: 0
+      to: 10
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithValidate())
+	require.NoError(t, err)
+	s, ok := rml.EntryPoint().(*Library).Types.Get("Length")
+	require.True(t, ok)
+
+	facets := s.CustomFacets()
+	require.Len(t, facets, 3)
+	require.Equal(t, "unit", facets[0].Name)
+	require.True(t, facets[0].Definition.Required)
+	require.Nil(t, facets[1].Value)
+
+	unit, err := CustomFacetValue[string](s, "unit")
+	require.NoError(t, err)
+	require.Equal(t, "m", unit)
+
+	scale, err := CustomFacetValue[float64](s, "scale")
+	require.NoError(t, err)
+	require.InDelta(t, 1.0, scale, 0)
+
+	type rangeFacet struct {
+		From int `yaml:"from"`
+		To   int `yaml:"to"`
+	}
+	r, err := CustomFacetValue[rangeFacet](s, "range")
+	require.NoError(t, err)
+	require.Equal(t, rangeFacet{From: 0, To: 10}, r)
+
+	_, err = CustomFacetValue[int](s, "unit")
+	require.ErrorContains(t, err, "convert custom facet unit")
+	_, err = CustomFacetValue[string](s, "unknown")
+	require.ErrorContains(t, err, "custom facet unknown is not declared")
+}
+
+func TestRAML_RegisterFacetValidator(t *testing.T) {
+	content := `#%RAML 1.0 Library
+annotationTypes:
+  pii: boolean
+types:
+  Secret:
+    type: string
+    facets:
+      classification: string
+  Person:
+    properties:
+      email:
+        (pii): true
+        type: string
+        description: Contact email.
+      phone:
+        (pii): true
+        type: string
+      token:
+        type: Secret
+        classification: public
+`
+	requireDescription := func(shape Shape, value *Node) error {
+		if value.Value == true && shape.Base().Description == nil {
+			return fmt.Errorf("pii requires description")
+		}
+		return nil
+	}
+	denyPublic := func(_ Shape, value *Node) error {
+		if value.Value == "public" {
+			return fmt.Errorf("secrets must not be public")
+		}
+		return nil
+	}
+	tests := []struct {
+		name    string
+		opts    []ParseOpt
+		wantErr []string
+	}{
+		{
+			name: "no validators",
+		},
+		{
+			name:    "annotation validator",
+			opts:    []ParseOpt{OptWithFacetValidator("pii", requireDescription)},
+			wantErr: []string{"pii requires description", "annotation: pii"},
+		},
+		{
+			name:    "custom facet validator",
+			opts:    []ParseOpt{OptWithFacetValidator("classification", denyPublic)},
+			wantErr: []string{"secrets must not be public", "facet: classification"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]ParseOpt{OptWithValidate()}, tt.opts...)
+			_, err := ParseFromString(content, "library.raml", t.TempDir(), opts...)
+			if len(tt.wantErr) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			for _, want := range tt.wantErr {
+				require.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
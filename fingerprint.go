@@ -0,0 +1,79 @@
+package raml
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"slices"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// Fingerprint returns a stable SHA-256 digest (hex-encoded) of the canonical form of the shape (see Canonicalize).
+// Shapes that differ only in documentation, examples, declaration order or the way they are composed, e.g. through
+// inheritance or an included data type, have the same fingerprint, so it changes only when the type changes
+// semantically. Names of nested types do not affect the fingerprint.
+func (s *BaseShape) Fingerprint() (string, error) {
+	h := sha256.New()
+	if err := writeFingerprint(h, s); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Fingerprint returns a stable SHA-256 digest (hex-encoded) of the names and canonical forms of types and annotation
+// types declared in the library. Types of used libraries affect the fingerprint as far as declared types refer to
+// them, while the order of declarations, documentation and examples do not.
+func (l *Library) Fingerprint() (string, error) {
+	h := sha256.New()
+	if err := writeDeclarationsFingerprint(h, "types", l.Types); err != nil {
+		return "", fmt.Errorf("library %s: %w", l.Location, err)
+	}
+	if err := writeDeclarationsFingerprint(h, "annotationTypes", l.AnnotationTypes); err != nil {
+		return "", fmt.Errorf("library %s: %w", l.Location, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Fingerprint returns a stable SHA-256 digest (hex-encoded) of the canonical form of the declared type.
+// It is equal to the fingerprint of the shape.
+func (dt *DataType) Fingerprint() (string, error) {
+	fp, err := dt.Shape.Fingerprint()
+	if err != nil {
+		return "", fmt.Errorf("data type %s: %w", dt.Location, err)
+	}
+	return fp, nil
+}
+
+func writeDeclarationsFingerprint(h hash.Hash, section string, m *orderedmap.OrderedMap[string, *BaseShape]) error {
+	names := make([]string, 0, m.Len())
+	for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+		names = append(names, pair.Key)
+	}
+	slices.Sort(names)
+	// Sections and names are separated by zero bytes that are not allowed in YAML.
+	h.Write([]byte(section + "\x00"))
+	for _, name := range names {
+		s, _ := m.Get(name)
+		h.Write([]byte(name + "\x00"))
+		if err := writeFingerprint(h, s); err != nil {
+			return fmt.Errorf("type %s: %w", name, err)
+		}
+		h.Write([]byte{0})
+	}
+	return nil
+}
+
+func writeFingerprint(h hash.Hash, s *BaseShape) error {
+	c, err := s.Canonicalize()
+	if err != nil {
+		return fmt.Errorf("fingerprint: %w", err)
+	}
+	b, err := c.signature()
+	if err != nil {
+		return fmt.Errorf("fingerprint: %w", err)
+	}
+	h.Write(b)
+	return nil
+}
@@ -0,0 +1,69 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	v1 := `#%RAML 1.0 Library
+types:
+  Base:
+    properties:
+      id: integer
+  Pet:
+    type: Base
+    description: A pet.
+    properties:
+      name: string
+`
+	v2 := `#%RAML 1.0 Library
+types:
+  Pet:
+    properties:
+      name: string
+      id: integer
+    example:
+      id: 1
+      name: Rex
+  Base:
+    properties:
+      id:
+        type: integer
+        description: Identifier.
+`
+	v3 := `#%RAML 1.0 Library
+types:
+  Base:
+    properties:
+      id: integer
+  Pet:
+    type: Base
+    properties:
+      name:
+        type: string
+        maxLength: 10
+`
+	fingerprints := func(content string) (string, string) {
+		r, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+		require.NoError(t, err)
+		pet, err := r.LookupType("Pet", r.GetLocation())
+		require.NoError(t, err)
+		petFP, err := pet.Fingerprint()
+		require.NoError(t, err)
+		require.Len(t, petFP, 64)
+		libFP, err := r.EntryPoint().(*Library).Fingerprint()
+		require.NoError(t, err)
+		return petFP, libFP
+	}
+
+	pet1, lib1 := fingerprints(v1)
+	pet2, lib2 := fingerprints(v2)
+	pet3, lib3 := fingerprints(v3)
+	require.Equal(t, pet1, pet2)
+	require.Equal(t, lib1, lib2)
+	require.NotEqual(t, pet1, pet3)
+	require.NotEqual(t, lib1, lib3)
+	require.NotEqual(t, pet1, lib1)
+}
@@ -0,0 +1,313 @@
+package raml
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFlattenSeparator is used to join library namespaces and type names of flattened types.
+const DefaultFlattenSeparator = "_"
+
+// FlattenNamingFunc returns the name of the type in the flattened document.
+// Namespace contains names of the libraries through which the type is used, it is empty for types of the entry point.
+type FlattenNamingFunc func(namespace []string, name string) string
+
+type flattenOptions struct {
+	naming FlattenNamingFunc
+	// collisionSeparator renames only colliding types if set, see OptFlattenWithCollisionRenaming.
+	collisionSeparator *string
+}
+
+type FlattenOpt interface {
+	Apply(*flattenOptions)
+}
+
+type flattenOptWithSeparator struct {
+	separator string
+}
+
+func (o flattenOptWithSeparator) Apply(opt *flattenOptions) {
+	opt.naming = joinNamespace(o.separator)
+}
+
+// OptFlattenWithSeparator sets the separator that joins namespaces and type names, e.g. "common_Parent".
+func OptFlattenWithSeparator(separator string) FlattenOpt {
+	return flattenOptWithSeparator{separator: separator}
+}
+
+type flattenOptWithNaming struct {
+	naming FlattenNamingFunc
+}
+
+func (o flattenOptWithNaming) Apply(opt *flattenOptions) {
+	opt.naming = o.naming
+}
+
+// OptFlattenWithNaming sets the function that names the types in the flattened document.
+func OptFlattenWithNaming(naming FlattenNamingFunc) FlattenOpt {
+	return flattenOptWithNaming{naming: naming}
+}
+
+type flattenOptWithCollisionRenaming struct {
+	separator string
+}
+
+func (o flattenOptWithCollisionRenaming) Apply(opt *flattenOptions) {
+	opt.collisionSeparator = &o.separator
+}
+
+// OptFlattenWithCollisionRenaming keeps names of types of used libraries and only qualifies types whose names collide
+// with the separator, see RAML.CollisionFreeNames.
+func OptFlattenWithCollisionRenaming(separator string) FlattenOpt {
+	return flattenOptWithCollisionRenaming{separator: separator}
+}
+
+// collisionNaming names types by RAML.CollisionFreeNames. Types missing from the type graph, e.g. annotation types,
+// are qualified with their namespaces.
+func (r *RAML) collisionNaming(separator string) FlattenNamingFunc {
+	names := r.CollisionFreeNames(separator)
+	join := joinNamespace(separator)
+	return func(namespace []string, name string) string {
+		if n, ok := names[strings.Join(append(slices.Clone(namespace), name), ".")]; ok {
+			return n
+		}
+		return join(namespace, name)
+	}
+}
+
+func joinNamespace(separator string) FlattenNamingFunc {
+	return func(namespace []string, name string) string {
+		return strings.Join(append(slices.Clone(namespace), name), separator)
+	}
+}
+
+// flattener assigns names to the types and annotation types declared in the flattened document.
+type flattener struct {
+	naming FlattenNamingFunc
+
+	types           *yaml.Node
+	annotationTypes *yaml.Node
+	// names and annotationNames map shape IDs to the names in the flattened document.
+	names           map[int64]string
+	annotationNames map[int64]string
+	// declared is a set of names used in the flattened document to detect collisions.
+	declared           map[string]string
+	declaredAnnotation map[string]string
+
+	visitedFragments map[string]struct{}
+	pending          []*BaseShape
+	// err is the first error that occurred while included data types were declared.
+	err error
+}
+
+// Flatten bundles the entry point with all used libraries and included data types into a single self-contained
+// RAML 1.0 Library.
+// Types of used libraries are declared with names qualified by their namespaces (see OptFlattenWithSeparator and
+// OptFlattenWithNaming), included data types are declared with names derived from their file names.
+// A DataType entry point is declared as a type named after its file.
+// NOTE: Traits and resource types are not supported by the parser yet.
+func (r *RAML) Flatten(opts ...FlattenOpt) ([]byte, error) {
+	fOpts := &flattenOptions{naming: joinNamespace(DefaultFlattenSeparator)}
+	for _, opt := range opts {
+		opt.Apply(fOpts)
+	}
+	if fOpts.collisionSeparator != nil {
+		fOpts.naming = r.collisionNaming(*fOpts.collisionSeparator)
+	}
+	f := &flattener{
+		naming:             fOpts.naming,
+		types:              newMappingNode(),
+		annotationTypes:    newMappingNode(),
+		names:              make(map[int64]string),
+		annotationNames:    make(map[int64]string),
+		declared:           make(map[string]string),
+		declaredAnnotation: make(map[string]string),
+		visitedFragments:   make(map[string]struct{}),
+	}
+	ms := &marshaller{
+		reference:      f.reference,
+		annotation:     f.annotation,
+		inlineExamples: true,
+		skipUses:       true,
+	}
+
+	doc := newMappingNode()
+	var annotations *yaml.Node
+	switch frag := r.EntryPoint().(type) {
+	case *Library:
+		if err := f.collectLibrary(frag, nil); err != nil {
+			return nil, fmt.Errorf("flatten: %w", err)
+		}
+		if frag.Usage != "" {
+			doc.Content = append(doc.Content, newStrNode("usage"), newStrNode(frag.Usage))
+		}
+		annotations = newMappingNode()
+		if err := ms.annotations(annotations, frag.CustomDomainProperties); err != nil {
+			return nil, fmt.Errorf("flatten: %w", err)
+		}
+	case *DataType:
+		if err := f.collectDataType(frag); err != nil {
+			return nil, fmt.Errorf("flatten: %w", err)
+		}
+		if frag.Usage != "" {
+			doc.Content = append(doc.Content, newStrNode("usage"), newStrNode(frag.Usage))
+		}
+	default:
+		return nil, fmt.Errorf("flatten: unsupported entry point %T", frag)
+	}
+
+	// Included data types are discovered while types are written.
+	for len(f.pending) > 0 {
+		s := f.pending[0]
+		f.pending = f.pending[1:]
+		n, err := ms.shape(s)
+		if err != nil {
+			return nil, fmt.Errorf("flatten: marshal type %s: %w", f.nameOf(s), err)
+		}
+		if f.isAnnotationType(s) {
+			f.annotationTypes.Content = append(f.annotationTypes.Content, newStrNode(f.annotationNames[s.ID]), n)
+		} else {
+			f.types.Content = append(f.types.Content, newStrNode(f.names[s.ID]), n)
+		}
+	}
+
+	if f.err != nil {
+		return nil, fmt.Errorf("flatten: %w", f.err)
+	}
+	if len(f.annotationTypes.Content) > 0 {
+		doc.Content = append(doc.Content, newStrNode("annotationTypes"), f.annotationTypes)
+	}
+	if len(f.types.Content) > 0 {
+		doc.Content = append(doc.Content, newStrNode("types"), f.types)
+	}
+	if annotations != nil {
+		doc.Content = append(doc.Content, annotations.Content...)
+	}
+	return encodeRAML(HeaderLibrary, doc)
+}
+
+func (f *flattener) collectLibrary(lib *Library, namespace []string) error {
+	if _, ok := f.visitedFragments[lib.Location]; ok {
+		return nil
+	}
+	f.visitedFragments[lib.Location] = struct{}{}
+	for pair := lib.AnnotationTypes.Oldest(); pair != nil; pair = pair.Next() {
+		if err := f.declareAnnotationType(f.naming(namespace, pair.Key), pair.Value); err != nil {
+			return err
+		}
+	}
+	for pair := lib.Types.Oldest(); pair != nil; pair = pair.Next() {
+		if err := f.declareType(f.naming(namespace, pair.Key), pair.Value); err != nil {
+			return err
+		}
+	}
+	for pair := lib.Uses.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Value.Link == nil {
+			continue
+		}
+		if err := f.collectLibrary(pair.Value.Link, append(slices.Clone(namespace), pair.Key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *flattener) collectDataType(dt *DataType) error {
+	if _, ok := f.visitedFragments[dt.Location]; ok {
+		return nil
+	}
+	f.visitedFragments[dt.Location] = struct{}{}
+	// Data types are not named by the user, so the name is made unique instead of reporting a collision.
+	base := f.naming(nil, strings.TrimSuffix(filepath.Base(dt.Location), filepath.Ext(dt.Location)))
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := f.declared[name]; !ok {
+			break
+		}
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	if err := f.declareType(name, dt.Shape); err != nil {
+		return err
+	}
+	for pair := dt.Uses.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Value.Link == nil {
+			continue
+		}
+		if err := f.collectLibrary(pair.Value.Link, []string{pair.Key}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *flattener) declareType(name string, s *BaseShape) error {
+	if other, ok := f.declared[name]; ok {
+		return fmt.Errorf("type name collision: %s is declared in %s and %s", name, other, s.Location())
+	}
+	f.declared[name] = s.Location()
+	f.names[s.ID] = name
+	f.pending = append(f.pending, s)
+	return nil
+}
+
+func (f *flattener) declareAnnotationType(name string, s *BaseShape) error {
+	if other, ok := f.declaredAnnotation[name]; ok {
+		return fmt.Errorf("annotation type name collision: %s is declared in %s and %s", name, other, s.Location())
+	}
+	f.declaredAnnotation[name] = s.Location()
+	f.annotationNames[s.ID] = name
+	f.pending = append(f.pending, s)
+	return nil
+}
+
+func (f *flattener) isAnnotationType(s *BaseShape) bool {
+	_, ok := f.annotationNames[s.ID]
+	return ok
+}
+
+func (f *flattener) nameOf(s *BaseShape) string {
+	if name, ok := f.annotationNames[s.ID]; ok {
+		return name
+	}
+	return f.names[s.ID]
+}
+
+// reference returns the flattened name of the type referenced by the shape.
+func (f *flattener) reference(s *BaseShape) (string, bool) {
+	if s.TypeLabel == "" {
+		return "", false
+	}
+	var ref *BaseShape
+	switch {
+	case s.Link != nil:
+		if err := f.collectDataType(s.Link); err != nil {
+			if f.err == nil {
+				f.err = err
+			}
+			return "", false
+		}
+		ref = s.Link.Shape
+	case s.Alias != nil:
+		ref = s.Alias
+	case len(s.Inherits) > 0:
+		ref = s.Inherits[0]
+	default:
+		return "", false
+	}
+	name, ok := f.names[ref.ID]
+	return name, ok
+}
+
+func (f *flattener) annotation(key string, de *DomainExtension) string {
+	if de.DefinedBy == nil {
+		return key
+	}
+	if name, ok := f.annotationNames[de.DefinedBy.ID]; ok {
+		return name
+	}
+	return key
+}
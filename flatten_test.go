@@ -0,0 +1,129 @@
+package raml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAML_Flatten(t *testing.T) {
+	files := map[string]string{
+		"common.raml": `#%RAML 1.0 Library
+uses:
+  base: ./base.raml
+annotationTypes:
+  Tag: string
+types:
+  Parent:
+    type: base.Root
+    minLength: 2
+`,
+		"base.raml": `#%RAML 1.0 Library
+types:
+  Root: string
+`,
+		"item.raml": `#%RAML 1.0 DataType
+uses:
+  common: ./common.raml
+type: object
+properties:
+  name: common.Parent
+`,
+	}
+	tests := []struct {
+		name    string
+		entry   string
+		opts    []FlattenOpt
+		want    string
+		wantErr string
+	}{
+		{
+			name: "library",
+			entry: `#%RAML 1.0 Library
+uses:
+  common: ./common.raml
+types:
+  Child:
+    type: common.Parent
+    (common.Tag): child
+  Items: !include item.raml
+`,
+			want: `#%RAML 1.0 Library
+annotationTypes:
+  common_Tag: string
+types:
+  Child:
+    type: common_Parent
+    (common_Tag): child
+  Items: item
+  common_Parent:
+    type: common_base_Root
+    minLength: 2
+  common_base_Root: string
+  item:
+    type: object
+    properties:
+      name: common_Parent
+`,
+		},
+		{
+			name: "custom naming",
+			entry: `#%RAML 1.0 Library
+uses:
+  common: ./common.raml
+types:
+  Child: common.Parent[]
+`,
+			opts: []FlattenOpt{OptFlattenWithNaming(func(namespace []string, name string) string {
+				if len(namespace) == 0 {
+					return name
+				}
+				return namespace[len(namespace)-1] + name
+			})},
+			want: `#%RAML 1.0 Library
+annotationTypes:
+  commonTag: string
+types:
+  Child: commonParent[]
+  commonParent:
+    type: baseRoot
+    minLength: 2
+  baseRoot: string
+`,
+		},
+		{
+			name: "name collision",
+			entry: `#%RAML 1.0 Library
+uses:
+  common: ./common.raml
+types:
+  common.Parent: string
+`,
+			opts:    []FlattenOpt{OptFlattenWithSeparator(".")},
+			wantErr: "type name collision: common.Parent",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+			}
+			rml, err := ParseFromString(tt.entry, "library.raml", dir, OptWithValidate())
+			require.NoError(t, err)
+			got, err := rml.Flatten(tt.opts...)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+
+			// Flattened document must be self-contained.
+			_, err = ParseFromString(string(got), "flat.raml", t.TempDir(), OptWithValidate())
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package raml
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+)
+
+// ErrNotForm is returned by ParseForm if the media type is not a form.
+var ErrNotForm = errors.New("media type is not a form")
+
+// Media types of form bodies.
+const (
+	MediaTypeMultipartForm  = "multipart/form-data"
+	MediaTypeURLEncodedForm = "application/x-www-form-urlencoded"
+)
+
+// IsFormMediaType reports whether bodies of the media type are forms, whose fields are properties of the body type.
+func IsFormMediaType(mediaType string) bool {
+	d, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return false
+	}
+	return d == MediaTypeMultipartForm || d == MediaTypeURLEncodedForm
+}
+
+// ValidateForm validates the form against the object type of a form body. The form is url.Values of
+// "application/x-www-form-urlencoded" bodies or *multipart.Form of "multipart/form-data" bodies, e.g.
+// http.Request.PostForm or http.Request.MultipartForm.
+//
+// Fields are properties of the type: values are converted to the types of properties as ParameterValue does,
+// fields of array properties may be repeated, and uploaded files are validated as contents of file properties.
+// Undeclared fields are strings, or arrays of strings if repeated.
+func (s *BaseShape) ValidateForm(form any) error {
+	obj, ok := s.Shape.(*ObjectShape)
+	if !ok {
+		return fmt.Errorf("form body must be an object type, got %s", s.Type)
+	}
+	var (
+		values url.Values
+		files  map[string][]*multipart.FileHeader
+	)
+	switch f := form.(type) {
+	case url.Values:
+		values = f
+	case *multipart.Form:
+		if f == nil {
+			return fmt.Errorf("form is nil")
+		}
+		values, files = f.Value, f.File
+	default:
+		return fmt.Errorf("invalid form type, got %T, expected url.Values or *multipart.Form", form)
+	}
+
+	v := make(map[string]any, len(values)+len(files))
+	var closers []io.Closer
+	defer func() {
+		for _, c := range closers {
+			// Uploaded files are only read, so errors of closing them do not affect validation.
+			_ = c.Close()
+		}
+	}()
+	for name, headers := range files {
+		contents := make([]any, len(headers))
+		for i, h := range headers {
+			f, err := h.Open()
+			if err != nil {
+				return fmt.Errorf("open file %s of field %s: %w", h.Filename, name, err)
+			}
+			closers = append(closers, f)
+			contents[i] = FileContent{MediaType: h.Header.Get("Content-Type"), Reader: f}
+		}
+		v[name] = formFieldValue(obj.formFieldShape(name), contents)
+	}
+	for name, fieldValues := range values {
+		shape := obj.formFieldShape(name)
+		if _, ok := v[name]; ok {
+			return fmt.Errorf("field %s is both a value and a file", name)
+		}
+		if shape == nil || len(fieldValues) > 1 && !isArrayShape(shape) {
+			// Repeated fields of scalar properties are arrays, so validation reports the type mismatch.
+			v[name] = formFieldValue(shape, stringsToAny(fieldValues))
+			continue
+		}
+		v[name] = ParameterValue(shape, fieldValues)
+	}
+	return s.Validate(v)
+}
+
+// formFieldShape returns the shape of the property or the first pattern property that matches the field name.
+func (s *ObjectShape) formFieldShape(name string) *BaseShape {
+	if p, ok := orderedGet(s.Properties, name); ok {
+		return p.Shape
+	}
+	if s.PatternProperties == nil {
+		return nil
+	}
+	for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Value.Pattern.MatchString(name) {
+			return pair.Value.Shape
+		}
+	}
+	return nil
+}
+
+// formFieldValue returns the values as an array if the field is an array or is repeated, the single value otherwise.
+func formFieldValue(shape *BaseShape, values []any) any {
+	if shape != nil && isArrayShape(shape) {
+		return values
+	}
+	if len(values) == 1 {
+		return values[0]
+	}
+	return values
+}
+
+func isArrayShape(shape *BaseShape) bool {
+	_, ok := shape.Shape.(*ArrayShape)
+	return ok
+}
+
+func stringsToAny(values []string) []any {
+	items := make([]any, len(values))
+	for i, value := range values {
+		items[i] = value
+	}
+	return items
+}
+
+// ParseForm parses the body of the form media type into url.Values or *multipart.Form that ValidateForm accepts.
+// Parts of multipart forms exceeding maxMemory bytes in total are stored in temporary files, which the caller
+// removes with (*multipart.Form).RemoveAll.
+func ParseForm(contentType string, body io.Reader, maxMemory int64) (any, error) {
+	d, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("parse media type: %w", err)
+	}
+	switch d {
+	case MediaTypeURLEncodedForm:
+		b, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("read form: %w", err)
+		}
+		values, err := url.ParseQuery(string(b))
+		if err != nil {
+			return nil, fmt.Errorf("parse form: %w", err)
+		}
+		return values, nil
+	case MediaTypeMultipartForm:
+		boundary, ok := params["boundary"]
+		if !ok {
+			return nil, fmt.Errorf("parse form: boundary is missing")
+		}
+		form, err := multipart.NewReader(body, boundary).ReadForm(maxMemory)
+		if err != nil {
+			return nil, fmt.Errorf("parse form: %w", err)
+		}
+		return form, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotForm, d)
+}
@@ -0,0 +1,90 @@
+package raml
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseShape_ValidateForm(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Upload:
+    properties:
+      title:
+        type: string
+        minLength: 2
+      count?: integer
+      tags?: string[]
+      avatar?:
+        type: file
+        fileTypes: [image/png]
+        maxLength: 16
+      /^x-/: boolean
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	upload, err := rml.LookupType("Upload", "")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		form    url.Values
+		wantErr string
+	}{
+		{name: "valid", form: url.Values{"title": {"Rex"}, "count": {"2"}, "tags": {"a", "b"}, "x-big": {"true"}}},
+		{name: "single tag", form: url.Values{"title": {"Rex"}, "tags": {"a"}}},
+		{name: "missing title", form: url.Values{"count": {"2"}}, wantErr: `missing required property "title"`},
+		{name: "short title", form: url.Values{"title": {"R"}}, wantErr: "length must be greater than 2"},
+		{name: "not integer", form: url.Values{"title": {"Rex"}, "count": {"two"}}, wantErr: "count"},
+		{name: "repeated scalar", form: url.Values{"title": {"Rex", "Tom"}}, wantErr: "title"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := upload.ValidateForm(tt.form)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	multipartForm := func(avatarType string, avatar []byte) (string, *bytes.Buffer) {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		require.NoError(t, w.WriteField("title", "Rex"))
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", `form-data; name="avatar"; filename="avatar.png"`)
+		h.Set("Content-Type", avatarType)
+		part, err := w.CreatePart(h)
+		require.NoError(t, err)
+		_, err = part.Write(avatar)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return w.FormDataContentType(), &buf
+	}
+	parse := func(contentType string, body *bytes.Buffer) any {
+		form, err := ParseForm(contentType, body, 1<<20)
+		require.NoError(t, err)
+		return form
+	}
+	require.NoError(t, upload.ValidateForm(parse(multipartForm("image/png", []byte("png")))))
+	require.ErrorContains(t, upload.ValidateForm(parse(multipartForm("image/gif", []byte("gif")))), "image/gif")
+	require.ErrorContains(t, upload.ValidateForm(parse(multipartForm("image/png", make([]byte, 17)))),
+		"length must be less than 16")
+
+	form := parse(MediaTypeURLEncodedForm, bytes.NewBufferString("title=Rex&tags=a&tags=b"))
+	require.Equal(t, url.Values{"title": {"Rex"}, "tags": {"a", "b"}}, form)
+	require.NoError(t, upload.ValidateForm(form))
+
+	_, err = ParseForm("application/json", nil, 0)
+	require.ErrorIs(t, err, ErrNotForm)
+	require.ErrorContains(t, upload.ValidateForm(map[string]any{}), "invalid form type")
+	require.True(t, IsFormMediaType("multipart/form-data; boundary=x"))
+	require.False(t, IsFormMediaType("application/json"))
+}
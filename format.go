@@ -0,0 +1,148 @@
+package raml
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Keys of fragments and type declarations in the canonical order. Keys that are not listed keep their relative order
+// after the listed ones, except for type declarations, where annotations and the tail keys come last.
+var (
+	libraryKeyOrder  = []string{"usage", "uses", "annotationTypes", "types", "schemas"}
+	dataTypeKeyOrder = []string{"usage", "uses"}
+	typeKeyOrder     = []string{"type", "displayName", "description", "required", "default"}
+	typeTailKeyOrder = []string{"facets", "properties", "items", "example", "examples"}
+)
+
+// Format rewrites the RAML fragment in the canonical form: two-space indentation, keys ordered by spec sections
+// (usage, uses, annotation types and types of libraries; type, display name and description first and properties
+// and examples last in type declarations) and type expressions with normalized spacing, e.g. "Pet[] | string".
+// Comments and styles of scalars are preserved. Fragments other than libraries, data types and named examples
+// are only reindented.
+func Format(data []byte) ([]byte, error) {
+	head, body, _ := bytes.Cut(data, []byte("\n"))
+	head = bytes.TrimRight(head, "\r ")
+	kind, err := IdentifyFragment(string(head))
+	if err != nil && !bytes.HasPrefix(head, []byte("#%RAML")) {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("decode fragment: %w", err)
+	}
+	var buf bytes.Buffer
+	buf.Write(head)
+	buf.WriteByte('\n')
+	if doc.Kind == 0 {
+		return buf.Bytes(), nil
+	}
+	if root := doc.Content[0]; root.Kind == yaml.MappingNode {
+		switch kind {
+		case FragmentLibrary:
+			orderKeys(root, libraryKeyOrder, nil)
+			for i := 0; i+1 < len(root.Content); i += 2 {
+				switch root.Content[i].Value {
+				case "types", "annotationTypes", "schemas":
+					formatDeclarations(root.Content[i+1])
+				}
+			}
+		case FragmentDataType:
+			formatTypeDeclaration(root)
+			orderKeys(root, dataTypeKeyOrder, nil)
+		}
+	}
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encode fragment: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode fragment: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatDeclarations formats type declarations of the mapping, e.g. types or properties.
+func formatDeclarations(node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 1; i < len(node.Content); i += 2 {
+		formatTypeDeclaration(node.Content[i])
+	}
+}
+
+// formatTypeDeclaration formats the type declaration that is either a type expression, a list of parents or a map.
+func formatTypeDeclaration(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == TagStr && node.Value != "" && node.Value[0] != '{' && node.Value[0] != '<' {
+			node.Value = normalizeTypeExpression(node.Value)
+		}
+	case yaml.SequenceNode:
+		for _, n := range node.Content {
+			formatTypeDeclaration(n)
+		}
+	case yaml.MappingNode:
+		orderKeys(node, typeKeyOrder, typeTailKeyOrder)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			switch node.Content[i].Value {
+			case "type", "items":
+				formatTypeDeclaration(node.Content[i+1])
+			case "properties", "facets":
+				formatDeclarations(node.Content[i+1])
+			}
+		}
+	}
+}
+
+// orderKeys moves the head keys to the beginning of the mapping and the tail keys with annotations to the end in
+// the given order. Other keys keep their relative order.
+func orderKeys(node *yaml.Node, head []string, tail []string) {
+	type pair struct{ key, value *yaml.Node }
+	rank := func(key string) int {
+		if i := slices.Index(head, key); i >= 0 {
+			return i - len(head)
+		}
+		if i := slices.Index(tail, key); i >= 0 {
+			return i + 1
+		}
+		if tail != nil && strings.HasPrefix(key, "(") {
+			return len(tail) + 1
+		}
+		return 0
+	}
+	pairs := make([]pair, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, pair{node.Content[i], node.Content[i+1]})
+	}
+	slices.SortStableFunc(pairs, func(a, b pair) int {
+		return rank(a.key.Value) - rank(b.key.Value)
+	})
+	for i, p := range pairs {
+		node.Content[2*i], node.Content[2*i+1] = p.key, p.value
+	}
+}
+
+// normalizeTypeExpression removes spaces of the type expression except for single spaces around "|". Expressions
+// with characters that are not allowed in type expressions are returned as is.
+func normalizeTypeExpression(expr string) string {
+	var sb strings.Builder
+	for _, c := range expr {
+		switch {
+		case c == ' ' || c == '\t':
+		case c == '|':
+			sb.WriteString(" | ")
+		case c == '[' || c == ']' || c == '(' || c == ')' || c == '?' || c == '.' || c == '-' || c == '_' ||
+			c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9':
+			sb.WriteRune(c)
+		default:
+			return expr
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,61 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+    # Pets are stored forever.
+    Pet:
+        properties:
+            name:   string
+            tags: Tag []|  string
+        (internal): true
+        description: A pet.
+        example: {name: Rex}
+        type: object
+    Tag: !include tag.raml
+    Owner:
+        type: [ Base , Pet ]
+uses:
+    common: common.raml
+usage: 'Pet store'
+`
+	want := `#%RAML 1.0 Library
+usage: 'Pet store'
+uses:
+  common: common.raml
+types:
+  # Pets are stored forever.
+  Pet:
+    type: object
+    description: A pet.
+    properties:
+      name: string
+      tags: Tag[] | string
+    example: {name: Rex}
+    (internal): true
+  Tag: !include tag.raml
+  Owner:
+    type: [Base, Pet]
+`
+	got, err := Format([]byte(content))
+	require.NoError(t, err)
+	require.Equal(t, want, string(got))
+
+	// Formatting is idempotent and keeps the model.
+	again, err := Format(got)
+	require.NoError(t, err)
+	require.Equal(t, want, string(again))
+
+	got, err = Format([]byte("#%RAML 1.0 DataType\nproperties:\n    id: integer\nuses:\n    c: c.raml\ntype: object\n"))
+	require.NoError(t, err)
+	require.Equal(t, "#%RAML 1.0 DataType\nuses:\n  c: c.raml\ntype: object\nproperties:\n  id: integer\n", string(got))
+
+	_, err = Format([]byte("types:\n  A: string\n"))
+	require.ErrorContains(t, err, "unknown fragment kind")
+}
@@ -18,8 +18,24 @@ const (
 	FragmentLibrary
 	FragmentDataType
 	FragmentNamedExample
+	FragmentAPI
 )
 
+func (k FragmentKind) String() string {
+	switch k {
+	case FragmentLibrary:
+		return "Library"
+	case FragmentDataType:
+		return "DataType"
+	case FragmentNamedExample:
+		return "NamedExample"
+	case FragmentAPI:
+		return "API"
+	default:
+		return "Unknown"
+	}
+}
+
 // CutReferenceName cuts a reference name into two parts: before and after the dot.
 func CutReferenceName(refName string) (string, string, bool) {
 	// External ref - <fragment>.<identifier>
@@ -59,60 +75,60 @@ type Library struct {
 
 	Location string
 	raml     *RAML
+	rawNode  *yaml.Node
 }
 
 // GetReferenceType returns a reference type by name, implementing the ReferenceTypeGetter interface
 func (l *Library) GetReferenceType(refName string) (*BaseShape, error) {
-	before, after, found := CutReferenceName(refName)
-
-	var ref *BaseShape
-
-	if !found {
-		rr, ok := l.Types.Get(refName)
-		if !ok {
-			return nil, fmt.Errorf("reference \"%s\" not found", refName)
-		}
-		ref = rr
-	} else {
-		lib, ok := l.Uses.Get(before)
-		if !ok {
-			return nil, fmt.Errorf("library \"%s\" not found", before)
-		}
-		rr, ok := lib.Link.Types.Get(after)
-		if !ok {
-			return nil, fmt.Errorf("reference \"%s\" not found", after)
-		}
-		ref = rr
-	}
-
-	return ref, nil
+	return referencedDeclaration(refName, l.Types, l.Uses, libraryTypes)
 }
 
 // GetReferenceAnnotationType returns a reference annotation type by name,
 // implementing the ReferenceAnnotationTypeGetter interface
 func (l *Library) GetReferenceAnnotationType(refName string) (*BaseShape, error) {
-	before, after, found := CutReferenceName(refName)
+	return referencedDeclaration(refName, l.AnnotationTypes, l.Uses, libraryAnnotationTypes)
+}
 
-	var ref *BaseShape
+func libraryTypes(l *Library) *orderedmap.OrderedMap[string, *BaseShape] {
+	return l.Types
+}
 
+func libraryAnnotationTypes(l *Library) *orderedmap.OrderedMap[string, *BaseShape] {
+	return l.AnnotationTypes
+}
+
+// referencedDeclaration resolves a reference, e.g. "Pet" or "lib.Pet", in a fragment of any kind. Unqualified
+// references are looked up in the declarations of the fragment, nil if the fragment cannot declare them, and
+// qualified references in the declarations of the used library that are selected by libraryDeclarations.
+func referencedDeclaration(refName string, declarations *orderedmap.OrderedMap[string, *BaseShape],
+	uses *orderedmap.OrderedMap[string, *LibraryLink],
+	libraryDeclarations func(*Library) *orderedmap.OrderedMap[string, *BaseShape]) (*BaseShape, error) {
+	before, after, found := CutReferenceName(refName)
 	if !found {
-		rr, ok := l.AnnotationTypes.Get(refName)
-		if !ok {
-			return nil, fmt.Errorf("reference \"%s\" not found", refName)
-		}
-		ref = rr
-	} else {
-		lib, ok := l.Uses.Get(before)
-		if !ok {
-			return nil, fmt.Errorf("library \"%s\" not found", before)
+		if declarations == nil {
+			return nil, fmt.Errorf("reference \"%s\" not found: fragment has no declarations, "+
+				"use a library alias", refName)
 		}
-		rr, ok := lib.Link.AnnotationTypes.Get(after)
+		ref, ok := declarations.Get(refName)
 		if !ok {
-			return nil, fmt.Errorf("reference \"%s\" not found", after)
+			return nil, fmt.Errorf("reference \"%s\" not found", refName)
 		}
-		ref = rr
+		return ref, nil
+	}
+	var lib *LibraryLink
+	if uses != nil {
+		lib, _ = uses.Get(before)
+	}
+	if lib == nil {
+		return nil, fmt.Errorf("library \"%s\" not found", before)
+	}
+	if lib.Link == nil {
+		return nil, fmt.Errorf("library \"%s\" is not loaded", before)
+	}
+	ref, ok := libraryDeclarations(lib.Link).Get(after)
+	if !ok {
+		return nil, fmt.Errorf("reference \"%s\" not found", after)
 	}
-
 	return ref, nil
 }
 
@@ -148,9 +164,9 @@ func (l *Library) unmarshalUses(valueNode *yaml.Node) {
 	}
 }
 
-func (l *Library) unmarshalTypes(valueNode *yaml.Node) {
+func (l *Library) unmarshalTypes(valueNode *yaml.Node) error {
 	if valueNode.Tag == TagNull {
-		return
+		return nil
 	}
 
 	l.Types = orderedmap.New[string, *BaseShape](len(valueNode.Content) / 2)
@@ -160,11 +176,14 @@ func (l *Library) unmarshalTypes(valueNode *yaml.Node) {
 		data := valueNode.Content[j+1]
 		shape, err := l.raml.makeNewShapeYAML(data, name, l.Location)
 		if err != nil {
-			panic(StacktraceNewWrapped("parse types: make shape", err, l.Location, WithNodePosition(data)))
+			return StacktraceNewWrapped("parse types: make shape", err, l.Location, WithNodePosition(data))
 		}
+		shape.Comments = makeComments(valueNode.Content[j], data)
 		l.Types.Set(name, shape)
 		l.raml.PutTypeIntoFragment(name, l.Location, shape)
 	}
+
+	return nil
 }
 
 func (l *Library) unmarshalAnnotationTypes(valueNode *yaml.Node) error {
@@ -181,6 +200,7 @@ func (l *Library) unmarshalAnnotationTypes(valueNode *yaml.Node) error {
 		if err != nil {
 			return StacktraceNewWrapped("parse annotation types: make shape", err, l.Location, WithNodePosition(data))
 		}
+		shape.Comments = makeComments(valueNode.Content[j], data)
 		l.AnnotationTypes.Set(name, shape)
 		l.raml.PutAnnotationTypeIntoFragment(name, l.Location, shape)
 	}
@@ -193,6 +213,10 @@ func (l *Library) UnmarshalYAML(value *yaml.Node) error {
 	if value.Kind != yaml.MappingNode {
 		return stacktrace.New("must be map", l.Location, WithNodePosition(value))
 	}
+	l.rawNode = l.raml.rawNode(value)
+	if err := l.raml.checkDuplicateKeys(value, l.Location); err != nil {
+		return err
+	}
 
 	for i := 0; i != len(value.Content); i += 2 {
 		node := value.Content[i]
@@ -200,8 +224,13 @@ func (l *Library) UnmarshalYAML(value *yaml.Node) error {
 		switch node.Value {
 		case "uses":
 			l.unmarshalUses(valueNode)
-		case "types":
-			l.unmarshalTypes(valueNode)
+		case "types", "schemas":
+			if node.Value == "schemas" {
+				l.raml.addWarning("schemas is deprecated, use types", l.Location, WithNodePosition(node))
+			}
+			if err := l.unmarshalTypes(valueNode); err != nil {
+				return fmt.Errorf("unmarshall types: %w", err)
+			}
 		case "annotationTypes":
 			if err := l.unmarshalAnnotationTypes(valueNode); err != nil {
 				return fmt.Errorf("unmarshall annotation types: %w", err)
@@ -245,49 +274,19 @@ type DataType struct {
 
 	Location string
 	raml     *RAML
+	rawNode  *yaml.Node
 }
 
-// GetReferenceType returns a reference type by name, implementing the ReferenceTypeGetter interface
+// GetReferenceType returns a reference type by name, implementing the ReferenceTypeGetter interface.
+// Data type fragments do not declare types, so references must be qualified with aliases of used libraries.
 func (dt *DataType) GetReferenceType(refName string) (*BaseShape, error) {
-	before, after, found := CutReferenceName(refName)
-
-	var ref *BaseShape
-
-	if !found {
-		return nil, fmt.Errorf("invalid reference %s", refName)
-	}
-	lib, ok := dt.Uses.Get(before)
-	if !ok {
-		return nil, fmt.Errorf("library \"%s\" not found", before)
-	}
-	ref, ok = lib.Link.Types.Get(after)
-	if !ok {
-		return nil, fmt.Errorf("reference \"%s\" not found", after)
-	}
-
-	return ref, nil
+	return referencedDeclaration(refName, nil, dt.Uses, libraryTypes)
 }
 
 // GetReferenceAnnotationType returns a reference annotation type by name,
 // implementing the ReferenceAnnotationTypeGetter interface
 func (dt *DataType) GetReferenceAnnotationType(refName string) (*BaseShape, error) {
-	before, after, found := CutReferenceName(refName)
-
-	var ref *BaseShape
-
-	if !found {
-		return nil, fmt.Errorf("invalid reference %s", refName)
-	}
-	lib, ok := dt.Uses.Get(before)
-	if !ok {
-		return nil, fmt.Errorf("library \"%s\" not found", before)
-	}
-	ref, ok = lib.Link.AnnotationTypes.Get(after)
-	if !ok {
-		return nil, fmt.Errorf("reference \"%s\" not found", after)
-	}
-
-	return ref, nil
+	return referencedDeclaration(refName, nil, dt.Uses, libraryAnnotationTypes)
 }
 
 func (dt *DataType) GetLocation() string {
@@ -298,6 +297,10 @@ func (dt *DataType) UnmarshalYAML(value *yaml.Node) error {
 	if value.Kind != yaml.MappingNode {
 		return stacktrace.New("must be map", dt.Location, WithNodePosition(value))
 	}
+	dt.rawNode = dt.raml.rawNode(value)
+	if err := dt.raml.checkDuplicateKeys(value, dt.Location); err != nil {
+		return err
+	}
 
 	shapeValue := &yaml.Node{
 		Kind: yaml.MappingNode,
@@ -376,6 +379,7 @@ type NamedExample struct {
 
 	Location string
 	raml     *RAML
+	rawNode  *yaml.Node
 }
 
 // GetReferenceAnnotationType returns a reference annotation type by name,
@@ -404,6 +408,10 @@ func (ne *NamedExample) UnmarshalYAML(value *yaml.Node) error {
 	if value.Kind != yaml.MappingNode {
 		return stacktrace.New("must be map", ne.Location, WithNodePosition(value))
 	}
+	ne.rawNode = ne.raml.rawNode(value)
+	if err := ne.raml.checkDuplicateKeys(value, ne.Location); err != nil {
+		return err
+	}
 	examples := orderedmap.New[string, *Example](len(value.Content) / 2)
 	for i := 0; i != len(value.Content); i += 2 {
 		node := value.Content[i]
@@ -0,0 +1,589 @@
+package raml
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultGenerateMaxRecursionDepth = 2
+	// generateSpan limits random lengths, counts and numbers when the facets do not limit them.
+	generateSpan = 16
+	// generateAttempts limits retries of values that must be unique or must satisfy several facets at once.
+	generateAttempts = 64
+)
+
+var errGenerateRecursion = errors.New("maximum recursion depth exceeded")
+
+// GenerateOpt configures Generate.
+type GenerateOpt interface {
+	Apply(*GenerateOptions)
+}
+
+type GenerateOptions struct {
+	rand               *rand.Rand
+	optionalProperties bool
+	examples           bool
+	maxRecursionDepth  int
+}
+
+type optGenerateSeed struct {
+	seed int64
+}
+
+func (o optGenerateSeed) Apply(opts *GenerateOptions) {
+	opts.rand = rand.New(rand.NewSource(o.seed)) //nolint:gosec // Generated values are not secrets.
+}
+
+// WithGenerateSeed makes Generate produce random instances. The same seed gives the same instances.
+// By default, Generate produces the canonical instance, i.e. the simplest one.
+func WithGenerateSeed(seed int64) GenerateOpt {
+	return optGenerateSeed{seed: seed}
+}
+
+type optGenerateOptionalProperties struct{}
+
+func (o optGenerateOptionalProperties) Apply(opts *GenerateOptions) {
+	opts.optionalProperties = true
+}
+
+// WithGenerateOptionalProperties makes Generate include all optional properties of objects. By default, canonical
+// instances contain only required properties and random instances contain random optional properties.
+func WithGenerateOptionalProperties() GenerateOpt {
+	return optGenerateOptionalProperties{}
+}
+
+type optGenerateExamples struct{}
+
+func (o optGenerateExamples) Apply(opts *GenerateOptions) {
+	opts.examples = true
+}
+
+// WithGenerateExamples makes Generate prefer declared examples and default values of shapes to synthesized values.
+func WithGenerateExamples() GenerateOpt {
+	return optGenerateExamples{}
+}
+
+type optGenerateMaxRecursionDepth struct {
+	depth int
+}
+
+func (o optGenerateMaxRecursionDepth) Apply(opts *GenerateOptions) {
+	opts.maxRecursionDepth = o.depth
+}
+
+// WithGenerateMaxRecursionDepth limits how many times recursive types are expanded. Optional properties, array items
+// beyond minItems and union members that exceed the limit are omitted. The default limit is 2.
+func WithGenerateMaxRecursionDepth(depth int) GenerateOpt {
+	return optGenerateMaxRecursionDepth{depth: depth}
+}
+
+// Generate produces an instance of the unwrapped shape that satisfies all its facets: enums, patterns, lengths,
+// bounds, required properties, etc. The result has the same types as decoded JSON, except that integers are int.
+// By default, the canonical instance is produced: the first enum value, the shortest string, the number closest to
+// zero, only required properties and the first union member. Use WithGenerateSeed to produce random instances, e.g.
+// for fuzzing.
+//
+// Patterns are generated with the RE2 syntax. An error is returned if the facets cannot be satisfied together or
+// the generated value does not pass validation, e.g. for patterns with lookarounds.
+func Generate(shape Shape, opts ...GenerateOpt) (interface{}, error) {
+	if !shape.Base().IsUnwrapped() {
+		return nil, fmt.Errorf("shape must be unwrapped")
+	}
+	g := &generator{opts: GenerateOptions{maxRecursionDepth: defaultGenerateMaxRecursionDepth}}
+	for _, opt := range opts {
+		opt.Apply(&g.opts)
+	}
+	v, err := g.generate(shape.Base(), "$")
+	if err != nil {
+		return nil, err
+	}
+	st := newValidationState(ValidateOptions{})
+	defer st.release()
+	if err = validateValue(shape, v, st); err != nil {
+		return nil, fmt.Errorf("generated value does not satisfy the shape: %w", err)
+	}
+	return v, nil
+}
+
+type generator struct {
+	opts  GenerateOptions
+	depth int
+}
+
+func (g *generator) random() bool {
+	return g.opts.rand != nil
+}
+
+// intn returns a random number in [0, n) or 0 for canonical instances.
+func (g *generator) intn(n int) int {
+	if !g.random() || n <= 0 {
+		return 0
+	}
+	return g.opts.rand.Intn(n)
+}
+
+// variant returns the generator that produces a different instance for each attempt, even if the generator is
+// canonical. It is used for values that must be unique.
+func (g *generator) variant(attempt int) *generator {
+	v := *g
+	seed := int64(attempt)
+	if g.random() {
+		seed = g.opts.rand.Int63()
+	}
+	v.opts.rand = rand.New(rand.NewSource(seed)) //nolint:gosec // Generated values are not secrets.
+	return &v
+}
+
+func (g *generator) generate(base *BaseShape, path string) (any, error) {
+	if g.opts.examples {
+		if v, ok := g.example(base); ok {
+			return v, nil
+		}
+	}
+	switch s := base.Shape.(type) {
+	case *StringShape:
+		if len(s.Enum) > 0 {
+			return g.enum(s.Enum), nil
+		}
+		v, err := g.generateString(s.Pattern, s.MinLength, s.MaxLength)
+		if err != nil {
+			return nil, fmt.Errorf("generate %s: %w", path, err)
+		}
+		return v, nil
+	case *IntegerShape:
+		if len(s.Enum) > 0 {
+			return g.enum(s.Enum), nil
+		}
+		v, err := g.generateInteger(s)
+		if err != nil {
+			return nil, fmt.Errorf("generate %s: %w", path, err)
+		}
+		return v, nil
+	case *NumberShape:
+		if len(s.Enum) > 0 {
+			return g.enum(s.Enum), nil
+		}
+		v, err := g.generateNumber(s)
+		if err != nil {
+			return nil, fmt.Errorf("generate %s: %w", path, err)
+		}
+		return v, nil
+	case *BooleanShape:
+		if len(s.Enum) > 0 {
+			return g.enum(s.Enum), nil
+		}
+		return g.intn(2) == 1, nil
+	case *DateTimeShape:
+		if len(s.Enum) > 0 {
+			return g.enum(s.Enum), nil
+		}
+		if s.Format != nil && *s.Format == DateTimeFormatRFC2616 {
+			return g.time().Format("Mon, 02 Jan 2006 15:04:05 GMT"), nil
+		}
+		return g.time().Format(time.RFC3339), nil
+	case *DateTimeOnlyShape:
+		if len(s.Enum) > 0 {
+			return g.enum(s.Enum), nil
+		}
+		return g.time().Format("2006-01-02T15:04:05"), nil
+	case *DateOnlyShape:
+		if len(s.Enum) > 0 {
+			return g.enum(s.Enum), nil
+		}
+		return g.time().Format(time.DateOnly), nil
+	case *TimeOnlyShape:
+		if len(s.Enum) > 0 {
+			return g.enum(s.Enum), nil
+		}
+		return g.time().Format(time.TimeOnly), nil
+	case *FileShape:
+		// File types are not checked for string contents.
+		v, err := g.generateString(nil, s.MinLength, s.MaxLength)
+		if err != nil {
+			return nil, fmt.Errorf("generate %s: %w", path, err)
+		}
+		return v, nil
+	case *NilShape, *AnyShape:
+		return nil, nil
+	case *ArrayShape:
+		return g.generateArray(s, path)
+	case *ObjectShape:
+		return g.generateObject(s, path)
+	case *UnionShape:
+		if len(s.Enum) > 0 {
+			return g.enum(s.Enum), nil
+		}
+		return g.generateUnion(s, path)
+	case *RecursiveShape:
+		if g.depth >= g.opts.maxRecursionDepth {
+			return nil, fmt.Errorf("generate %s: %w", path, errGenerateRecursion)
+		}
+		g.depth++
+		defer func() { g.depth-- }()
+		return g.generate(s.Head, path)
+	case *JSONShape:
+		return nil, fmt.Errorf("generate %s: values of JSON schemas are not supported", path)
+	}
+	return nil, fmt.Errorf("generate %s: unsupported shape %T", path, base.Shape)
+}
+
+// example returns a declared example or the default value of the shape.
+func (g *generator) example(base *BaseShape) (any, bool) {
+	// Examples with "strict: false" may not be valid against the shape.
+	var examples []*Example
+	if base.Example != nil && base.Example.Strict {
+		examples = append(examples, base.Example)
+	}
+	if base.Examples != nil {
+		m := base.Examples.Map
+		if base.Examples.Link != nil {
+			m = base.Examples.Link.Map
+		}
+		for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value.Strict {
+				examples = append(examples, pair.Value)
+			}
+		}
+	}
+	if len(examples) > 0 {
+		if ex := examples[g.intn(len(examples))]; ex.Data != nil {
+			return ex.Data.Value, true
+		}
+	}
+	if base.Default != nil {
+		return base.Default.Value, true
+	}
+	return nil, false
+}
+
+func (g *generator) enum(enum Nodes) any {
+	return enum[g.intn(len(enum))].Value
+}
+
+// time returns the canonical time or a random time between 2000 and 2030.
+func (g *generator) time() time.Time {
+	if !g.random() {
+		return time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	}
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+	end := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+	return time.Unix(start+g.opts.rand.Int63n(end-start), 0).UTC()
+}
+
+func (g *generator) generateInteger(s *IntegerShape) (any, error) {
+	lo, hi := intToRat(s.Minimum), intToRat(s.Maximum)
+	if s.Format != nil {
+		if bounds, ok := integerFormatBounds[*s.Format]; ok {
+			if lo == nil || lo.Cmp(big.NewRat(bounds[0], 1)) < 0 {
+				lo = big.NewRat(bounds[0], 1)
+			}
+			if hi == nil || hi.Cmp(big.NewRat(bounds[1], 1)) > 0 {
+				hi = big.NewRat(bounds[1], 1)
+			}
+		}
+	}
+	// An integer is a multiple of p/q if and only if it is a multiple of p.
+	step := big.NewRat(1, 1)
+	if s.MultipleOf != nil {
+		step.SetInt(floatToRat(*s.MultipleOf).Num())
+	}
+	v, err := g.generateRat(lo, hi, s.isExclusiveBound(AnnotationExclusiveMinimum),
+		s.isExclusiveBound(AnnotationExclusiveMaximum), step)
+	if err != nil {
+		return nil, err
+	}
+	n := v.Num()
+	if !n.IsInt64() || n.Int64() != int64(int(n.Int64())) {
+		return nil, fmt.Errorf("integer %s is out of range", n)
+	}
+	return int(n.Int64()), nil
+}
+
+func (g *generator) generateNumber(s *NumberShape) (any, error) {
+	var lo, hi, step *big.Rat
+	if s.Minimum != nil {
+		lo = floatToRat(*s.Minimum)
+	}
+	if s.Maximum != nil {
+		hi = floatToRat(*s.Maximum)
+	}
+	if s.MultipleOf != nil {
+		step = floatToRat(*s.MultipleOf)
+	}
+	v, err := g.generateRat(lo, hi, s.isExclusiveBound(AnnotationExclusiveMinimum),
+		s.isExclusiveBound(AnnotationExclusiveMaximum), step)
+	if err != nil {
+		return nil, err
+	}
+	f, _ := v.Float64()
+	return f, nil
+}
+
+func intToRat(i *big.Int) *big.Rat {
+	if i == nil {
+		return nil
+	}
+	return new(big.Rat).SetInt(i)
+}
+
+// generateRat returns a number within the bounds that is a multiple of step, any number if step is nil.
+// Bounds are optional.
+func (g *generator) generateRat(lo, hi *big.Rat, loExclusive, hiExclusive bool, step *big.Rat) (*big.Rat, error) {
+	within := func(v *big.Rat) bool {
+		if lo != nil {
+			if c := v.Cmp(lo); c < 0 || c == 0 && loExclusive {
+				return false
+			}
+		}
+		if hi != nil {
+			if c := v.Cmp(hi); c > 0 || c == 0 && hiExclusive {
+				return false
+			}
+		}
+		return true
+	}
+
+	// The target is zero for canonical instances or a random number within the bounds.
+	t := new(big.Rat)
+	if g.random() {
+		a, b := big.NewRat(-generateSpan, 1), big.NewRat(generateSpan, 1)
+		switch {
+		case lo != nil && hi != nil:
+			a, b = lo, hi
+		case lo != nil:
+			a, b = lo, new(big.Rat).Add(lo, big.NewRat(2*generateSpan, 1))
+		case hi != nil:
+			a, b = new(big.Rat).Sub(hi, big.NewRat(2*generateSpan, 1)), hi
+		}
+		t.Sub(b, a)
+		t.Mul(t, new(big.Rat).SetFloat64(g.opts.rand.Float64()))
+		t.Add(t, a)
+	}
+	if lo != nil && t.Cmp(lo) < 0 {
+		t.Set(lo)
+	}
+	if hi != nil && t.Cmp(hi) > 0 {
+		t.Set(hi)
+	}
+
+	if step == nil {
+		if within(t) {
+			return t, nil
+		}
+		// The target is at an exclusive bound.
+		if lo != nil && hi != nil {
+			t.Add(lo, hi)
+			t.Quo(t, big.NewRat(2, 1))
+		} else if lo != nil {
+			t.Add(lo, big.NewRat(1, 1))
+		} else {
+			t.Sub(hi, big.NewRat(1, 1))
+		}
+		if within(t) {
+			return t, nil
+		}
+		return nil, fmt.Errorf("no number within bounds")
+	}
+
+	// Multiples of the step around the target.
+	q := new(big.Rat).Quo(t, step)
+	k := new(big.Int).Div(q.Num(), q.Denom())
+	floor := new(big.Rat).Mul(new(big.Rat).SetInt(k), step)
+	candidates := []*big.Rat{floor, new(big.Rat).Add(floor, step), new(big.Rat).Sub(floor, step)}
+	// Random instances round the target either way, so every multiple within the bounds can be generated.
+	if g.intn(2) == 1 {
+		candidates[0], candidates[1] = candidates[1], candidates[0]
+	}
+	for _, v := range candidates {
+		if within(v) {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("no multiple of %s within bounds", step.RatString())
+}
+
+func (g *generator) generateArray(s *ArrayShape, path string) (any, error) {
+	n := 0
+	if s.MinItems != nil {
+		n = int(*s.MinItems)
+	}
+	minItems := n
+	if s.MaxItems != nil {
+		n += g.intn(min(int(*s.MaxItems)-n, generateSpan/4) + 1)
+	} else {
+		n += g.intn(generateSpan/4 + 1)
+	}
+	unique := s.UniqueItems != nil && *s.UniqueItems
+
+	items := make([]any, 0, n)
+	for i := 0; i < n; i++ {
+		itemPath := path + "[" + strconv.Itoa(i) + "]"
+		if s.Items == nil {
+			// Items of any type are unique integers.
+			items = append(items, i)
+			continue
+		}
+		item, err := g.generate(s.Items, itemPath)
+		for attempt := 1; err == nil && unique && containsValue(items, item); attempt++ {
+			if attempt > generateAttempts {
+				err = fmt.Errorf("generate %s: cannot generate unique item", itemPath)
+				break
+			}
+			item, err = g.variant(attempt).generate(s.Items, itemPath)
+		}
+		if err != nil {
+			if i >= minItems && errors.Is(err, errGenerateRecursion) {
+				break
+			}
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func containsValue(values []any, v any) bool {
+	for _, item := range values {
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *generator) generateObject(s *ObjectShape, path string) (any, error) {
+	value := make(map[string]any)
+	maxProperties := -1
+	if s.MaxProperties != nil {
+		maxProperties = int(*s.MaxProperties)
+	}
+	minProperties := 0
+	if s.MinProperties != nil {
+		minProperties = int(*s.MinProperties)
+	}
+
+	var skipped []Property
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		prop := pair.Value
+		if !prop.Required {
+			full := maxProperties >= 0 && len(value) >= maxProperties
+			if full || !g.opts.optionalProperties && g.intn(2) == 0 {
+				skipped = append(skipped, prop)
+				continue
+			}
+		}
+		if err := g.generateProperty(value, prop, path); err != nil {
+			return nil, err
+		}
+	}
+	// Skipped optional properties, pattern properties and additional properties complete minProperties.
+	for _, prop := range skipped {
+		if len(value) >= minProperties {
+			break
+		}
+		if err := g.generateProperty(value, prop, path); err != nil {
+			return nil, err
+		}
+	}
+	for attempt := 0; len(value) < minProperties && attempt < generateAttempts; attempt++ {
+		if err := g.generatePatternProperty(s, value, path, attempt); err != nil {
+			return nil, err
+		}
+	}
+	if len(value) < minProperties && (s.AdditionalProperties == nil || *s.AdditionalProperties) {
+		for i := 1; len(value) < minProperties; i++ {
+			if key := "property" + strconv.Itoa(i); !s.hasProperty(key, value) {
+				value[key] = ""
+			}
+		}
+	}
+
+	if s.Discriminator != nil {
+		value[*s.Discriminator] = s.discriminatorValue()
+	}
+	return value, nil
+}
+
+func (g *generator) generateProperty(value map[string]any, prop Property, path string) error {
+	v, err := g.generate(prop.Shape, path+"."+prop.Name)
+	if err != nil {
+		if !prop.Required && errors.Is(err, errGenerateRecursion) {
+			return nil
+		}
+		return err
+	}
+	value[prop.Name] = v
+	return nil
+}
+
+// generatePatternProperty adds a property that matches one of pattern properties if its name does not clash with
+// other properties.
+func (g *generator) generatePatternProperty(s *ObjectShape, value map[string]any, path string, attempt int) error {
+	if s.PatternProperties.Len() == 0 {
+		return nil
+	}
+	pair := s.PatternProperties.Oldest()
+	for i := 0; i < attempt%s.PatternProperties.Len(); i++ {
+		pair = pair.Next()
+	}
+	pattern := pair.Value
+	gv := g.variant(attempt)
+	key, err := gv.generateString(pattern.Pattern, nil, nil)
+	if err != nil {
+		return fmt.Errorf("generate %s: pattern property: %w", path, err)
+	}
+	if s.hasProperty(key, value) {
+		return nil
+	}
+	v, err := gv.generate(pattern.Shape, path+"."+key)
+	if err != nil {
+		if errors.Is(err, errGenerateRecursion) {
+			return nil
+		}
+		return err
+	}
+	value[key] = v
+	return nil
+}
+
+// hasProperty reports whether the key is a declared property or is already set.
+func (s *ObjectShape) hasProperty(key string, value map[string]any) bool {
+	if _, ok := value[key]; ok {
+		return true
+	}
+	if s.Properties == nil {
+		return false
+	}
+	_, ok := s.Properties.Get(key)
+	return ok
+}
+
+func (g *generator) generateUnion(s *UnionShape, path string) (any, error) {
+	members := s.AnyOf
+	if g.random() {
+		members = make([]*BaseShape, len(s.AnyOf))
+		for i, j := range g.opts.rand.Perm(len(s.AnyOf)) {
+			members[i] = s.AnyOf[j]
+		}
+	}
+	var firstErr error
+	for _, member := range members {
+		v, err := g.generate(member, path)
+		if err == nil {
+			return v, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		return nil, fmt.Errorf("generate %s: union has no members", path)
+	}
+	return nil, firstErr
+}
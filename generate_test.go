@@ -0,0 +1,174 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Code:
+    type: string
+    pattern: ^[A-Z]{2}-\d{3}$
+  Pet:
+    type: object
+    discriminator: kind
+    discriminatorValue: pet
+    properties:
+      kind: string
+      code: Code
+      name:
+        type: string
+        minLength: 3
+        maxLength: 5
+      size:
+        enum: [small, big]
+      age:
+        type: integer
+        minimum: 5
+        multipleOf: 3
+      weight:
+        type: number
+        minimum: -2.5
+        maximum: -0.5
+        multipleOf: 0.2
+      born: date-only
+      seen: datetime
+      tags:
+        type: array
+        items:
+          type: integer
+          minimum: 0
+          maximum: 9
+        minItems: 3
+        uniqueItems: true
+      owner?: string
+      parent?: Pet
+      /^x-[a-z]+$/: boolean
+  Named:
+    type: object
+    minProperties: 2
+    properties:
+      name: string
+      /^x-[a-z]+$/: boolean
+  Pair:
+    type: array
+    items:
+      type: integer
+      minimum: 3
+      maximum: 4
+    uniqueItems: true
+    minItems: 2
+    maxItems: 2
+  Impossible:
+    type: integer
+    minimum: 1
+    maximum: 2
+    multipleOf: 5
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	lib := rml.EntryPoint().(*Library)
+	pet, _ := lib.Types.Get("Pet")
+
+	v, err := Generate(pet.Shape)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"kind":   "pet",
+		"code":   "AA-000",
+		"name":   "aaa",
+		"size":   "small",
+		"age":    6,
+		"weight": -0.6,
+		"born":   "2006-01-02",
+		"seen":   "2006-01-02T15:04:05Z",
+		"tags":   v.(map[string]any)["tags"],
+	}, v)
+	require.Len(t, v.(map[string]any)["tags"], 3)
+
+	v, err = Generate(pet.Shape, WithGenerateOptionalProperties(), WithGenerateMaxRecursionDepth(1))
+	require.NoError(t, err)
+	parent := v.(map[string]any)["parent"].(map[string]any)
+	require.Contains(t, parent, "owner")
+	require.NotContains(t, parent, "parent")
+
+	for seed := int64(0); seed < 100; seed++ {
+		v, err = Generate(pet.Shape, WithGenerateSeed(seed))
+		require.NoError(t, err, "seed %d", seed)
+		require.NoError(t, pet.Validate(v), "seed %d", seed)
+	}
+	first, err := Generate(pet.Shape, WithGenerateSeed(1))
+	require.NoError(t, err)
+	second, err := Generate(pet.Shape, WithGenerateSeed(1))
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	named, _ := lib.Types.Get("Named")
+	v, err = Generate(named.Shape)
+	require.NoError(t, err)
+	require.Len(t, v, 2)
+
+	// Both multiples of a tight range are generated.
+	pair, _ := lib.Types.Get("Pair")
+	v, err = Generate(pair.Shape)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []any{3, 4}, v)
+	for seed := int64(0); seed < 20; seed++ {
+		v, err = Generate(pair.Shape, WithGenerateSeed(seed))
+		require.NoError(t, err, "seed %d", seed)
+		require.ElementsMatch(t, []any{3, 4}, v, "seed %d", seed)
+	}
+
+	impossible, _ := lib.Types.Get("Impossible")
+	_, err = Generate(impossible.Shape)
+	require.EqualError(t, err, "generate $: no multiple of 5 within bounds")
+
+	rml, err = ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+	pet, _ = rml.EntryPoint().(*Library).Types.Get("Pet")
+	_, err = Generate(pet.Shape)
+	require.EqualError(t, err, "shape must be unwrapped")
+}
+
+func TestGenerate_Examples(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Pet:
+    type: object
+    properties:
+      name:
+        type: string
+        default: Rex
+      age: integer
+    examples:
+      young:
+        name: Tom
+        age: 1
+      old:
+        name: Max
+        age: 15
+  Owner:
+    type: object
+    properties:
+      pet: Pet
+      nickname:
+        type: string
+        example: Bob
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	owner, _ := rml.EntryPoint().(*Library).Types.Get("Owner")
+
+	v, err := Generate(owner.Shape, WithGenerateExamples())
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"pet":      map[string]any{"name": "Tom", "age": 1},
+		"nickname": "Bob",
+	}, v)
+
+	v, err = Generate(owner.Shape)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"pet": map[string]any{"name": "", "age": 0}, "nickname": ""}, v)
+}
@@ -5,6 +5,7 @@ go 1.22.6
 require (
 	github.com/acronis/go-stacktrace v0.2.0
 	github.com/antlr4-go/antlr/v4 v4.13.1
+	github.com/dlclark/regexp2 v1.7.0
 	github.com/stretchr/testify v1.9.0
 	github.com/wk8/go-ordered-map/v2 v2.1.8
 	gopkg.in/yaml.v3 v3.0.1
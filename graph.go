@@ -0,0 +1,277 @@
+package raml
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TypeDependencyKind is a kind of the dependency between declared types.
+type TypeDependencyKind string
+
+const (
+	// TypeDependencyInherits means that the type inherits from the other type, e.g. "type: [A, B]".
+	TypeDependencyInherits TypeDependencyKind = "inherits"
+	// TypeDependencyAlias means that the type is declared as the other type, e.g. "A: B" or an included data type.
+	TypeDependencyAlias TypeDependencyKind = "alias"
+	// TypeDependencyReference means that a property, items, union member or facet of the type refers to
+	// the other type.
+	TypeDependencyReference TypeDependencyKind = "reference"
+)
+
+// TypeGraphNode is a declared type of the type dependency graph.
+type TypeGraphNode struct {
+	// Name is the name of the type qualified by library namespaces of the entry point, e.g. "common.Pet".
+	Name     string
+	Location string
+	Shape    *BaseShape
+}
+
+// TypeDependency is an edge of the type dependency graph.
+type TypeDependency struct {
+	From string
+	To   string
+	Kind TypeDependencyKind
+	// Path is the position of the reference within the type, e.g. "properties.pets.items".
+	// It is empty for dependencies of the type itself.
+	Path string
+}
+
+// TypeGraph is the dependency graph of declared types.
+type TypeGraph struct {
+	// Nodes contains declared types sorted by name.
+	Nodes []*TypeGraphNode
+	// Edges maps names of types to their dependencies in the order of declaration.
+	Edges map[string][]TypeDependency
+}
+
+// Dependents returns dependencies of other types on the type with the given name.
+func (g *TypeGraph) Dependents(name string) []TypeDependency {
+	var result []TypeDependency
+	for _, n := range g.Nodes {
+		for _, e := range g.Edges[n.Name] {
+			if e.To == name {
+				result = append(result, e)
+			}
+		}
+	}
+	return result
+}
+
+// TypeGraph returns the dependency graph of types declared by the entry point, libraries it uses transitively and
+// included data type fragments. Since unwrapping removes links and aliases, the graph is complete only for models
+// parsed without OptWithUnwrap. Converters, generators and analyses built on the graph have the same requirement.
+func (r *RAML) TypeGraph() *TypeGraph {
+	b := &typeGraphBuilder{
+		graph: &TypeGraph{Edges: make(map[string][]TypeDependency)},
+		names: make(map[int64]string),
+		edges: make(map[TypeDependency]struct{}),
+	}
+	b.collect(r)
+	slices.SortFunc(b.graph.Nodes, func(x, y *TypeGraphNode) int {
+		return strings.Compare(x.Name, y.Name)
+	})
+	for _, n := range b.graph.Nodes {
+		b.walk(n.Name, n.Shape, "", true, make(map[int64]struct{}))
+	}
+	return b.graph
+}
+
+type typeGraphBuilder struct {
+	graph *TypeGraph
+	// names maps IDs of declared types to their qualified names.
+	names map[int64]string
+	edges map[TypeDependency]struct{}
+}
+
+func (b *typeGraphBuilder) addNode(name string, location string, shape *BaseShape) {
+	if _, ok := b.names[shape.ID]; ok {
+		return
+	}
+	b.names[shape.ID] = name
+	b.graph.Nodes = append(b.graph.Nodes, &TypeGraphNode{Name: name, Location: location, Shape: shape})
+}
+
+// collect adds types of the entry point and used libraries with qualified names, then types of the remaining
+// fragments that are not reachable through uses.
+func (b *typeGraphBuilder) collect(r *RAML) {
+	visited := make(map[string]struct{})
+	var walkLibrary func(lib *Library, prefix string)
+	walkLibrary = func(lib *Library, prefix string) {
+		// The same library may be used under different namespaces, the first one is kept.
+		if _, ok := visited[lib.Location]; ok {
+			return
+		}
+		visited[lib.Location] = struct{}{}
+		for pair := lib.Types.Oldest(); pair != nil; pair = pair.Next() {
+			b.addNode(prefix+pair.Key, lib.Location, pair.Value)
+		}
+		for pair := lib.Uses.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value.Link != nil {
+				walkLibrary(pair.Value.Link, prefix+pair.Key+".")
+			}
+		}
+	}
+	if lib, ok := r.entryPoint.(*Library); ok {
+		walkLibrary(lib, "")
+	}
+	locations := make([]string, 0, len(r.fragmentsCache))
+	for loc := range r.fragmentsCache {
+		locations = append(locations, loc)
+	}
+	slices.Sort(locations)
+	for _, loc := range locations {
+		switch f := r.fragmentsCache[loc].(type) {
+		case *Library:
+			walkLibrary(f, "")
+		case *DataType:
+			if f.Shape == nil {
+				continue
+			}
+			name := f.Shape.Name
+			if name == "" {
+				name = strings.TrimSuffix(filepath.Base(f.Location), filepath.Ext(f.Location))
+			}
+			b.addNode(name, f.Location, f.Shape)
+		}
+	}
+}
+
+// declaredName returns the name of the declared type the shape refers to. Parents of multiple inheritance are
+// anonymous aliases of declared types.
+func (b *typeGraphBuilder) declaredName(s *BaseShape) (string, bool) {
+	for s != nil {
+		if name, ok := b.names[s.ID]; ok {
+			return name, true
+		}
+		switch {
+		case s.Alias != nil:
+			s = s.Alias
+		case s.Link != nil:
+			s = s.Link.Shape
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func (b *typeGraphBuilder) addEdge(from string, to *BaseShape, kind TypeDependencyKind, path string) {
+	name, ok := b.declaredName(to)
+	if !ok {
+		return
+	}
+	e := TypeDependency{From: from, To: name, Kind: kind, Path: path}
+	if _, ok = b.edges[e]; ok {
+		return
+	}
+	b.edges[e] = struct{}{}
+	b.graph.Edges[from] = append(b.graph.Edges[from], e)
+}
+
+// walk adds dependencies of the shape that belongs to the declared type. References to other types are not
+// followed, only inline shapes are walked.
+func (b *typeGraphBuilder) walk(from string, s *BaseShape, path string, root bool, visited map[int64]struct{}) {
+	if s == nil {
+		return
+	}
+	if _, ok := visited[s.ID]; ok {
+		return
+	}
+	visited[s.ID] = struct{}{}
+
+	kind, parentKind := TypeDependencyReference, TypeDependencyReference
+	if root {
+		kind, parentKind = TypeDependencyAlias, TypeDependencyInherits
+	}
+	if s.Alias != nil {
+		b.addEdge(from, s.Alias, kind, path)
+		return
+	}
+	if s.Link != nil {
+		b.addEdge(from, s.Link.Shape, kind, path)
+		return
+	}
+	for _, parent := range s.Inherits {
+		b.addEdge(from, parent, parentKind, path)
+	}
+	for pair := s.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
+		b.walk(from, pair.Value.Shape, joinGraphPath(path, "facets."+pair.Key), false, visited)
+	}
+	switch shape := s.Shape.(type) {
+	case *ObjectShape:
+		for pair := shape.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			b.walk(from, pair.Value.Shape, joinGraphPath(path, "properties."+pair.Key), false, visited)
+		}
+		for pair := shape.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+			b.walk(from, pair.Value.Shape, joinGraphPath(path, "properties."+pair.Key), false, visited)
+		}
+	case *ArrayShape:
+		b.walk(from, shape.Items, joinGraphPath(path, "items"), false, visited)
+	case *UnionShape:
+		for i, member := range shape.AnyOf {
+			b.walk(from, member, joinGraphPath(path, fmt.Sprintf("anyOf[%d]", i)), false, visited)
+		}
+	case *RecursiveShape:
+		b.addEdge(from, shape.Head, TypeDependencyReference, path)
+	}
+}
+
+func joinGraphPath(path string, elem string) string {
+	if path == "" {
+		return elem
+	}
+	return path + "." + elem
+}
+
+// WriteDOT renders the graph in the Graphviz DOT language. Types are grouped into clusters by files,
+// inheritance is drawn with hollow arrows, aliases with dashed lines and references are labeled with their paths.
+func (g *TypeGraph) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph types {\n  rankdir=LR;\n  node [shape=box];\n")
+
+	var locations []string
+	byLocation := make(map[string][]*TypeGraphNode)
+	for _, n := range g.Nodes {
+		if _, ok := byLocation[n.Location]; !ok {
+			locations = append(locations, n.Location)
+		}
+		byLocation[n.Location] = append(byLocation[n.Location], n)
+	}
+	slices.Sort(locations)
+	for i, loc := range locations {
+		fmt.Fprintf(&sb, "  subgraph %s {\n    label=%s;\n", strconv.Quote(fmt.Sprintf("cluster_%d", i)),
+			strconv.Quote(filepath.Base(loc)))
+		for _, n := range byLocation[loc] {
+			fmt.Fprintf(&sb, "    %s;\n", strconv.Quote(n.Name))
+		}
+		sb.WriteString("  }\n")
+	}
+	for _, n := range g.Nodes {
+		for _, e := range g.Edges[n.Name] {
+			var attrs []string
+			switch e.Kind {
+			case TypeDependencyInherits:
+				attrs = append(attrs, "arrowhead=empty")
+			case TypeDependencyAlias:
+				attrs = append(attrs, "style=dashed")
+			}
+			if e.Path != "" {
+				attrs = append(attrs, "label="+strconv.Quote(e.Path))
+			}
+			fmt.Fprintf(&sb, "  %s -> %s", strconv.Quote(e.From), strconv.Quote(e.To))
+			if len(attrs) > 0 {
+				fmt.Fprintf(&sb, " [%s]", strings.Join(attrs, ", "))
+			}
+			sb.WriteString(";\n")
+		}
+	}
+	sb.WriteString("}\n")
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("write dot: %w", err)
+	}
+	return nil
+}
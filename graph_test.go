@@ -0,0 +1,66 @@
+package raml
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAML_TypeGraph(t *testing.T) {
+	dir, err := filepath.Abs("./fixtures/graph")
+	require.NoError(t, err)
+	content := `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Cat:
+    type: common.Pet
+    properties:
+      friends: Cat[]
+  Tagged:
+    type: object
+  TaggedCat:
+    type: [Cat, Tagged]
+  Owner:
+    type: object
+    properties:
+      id: common.Id
+      pet: Cat | common.Pet
+  Key: common.Id
+`
+	rml, err := ParseFromString(content, "library.raml", dir)
+	require.NoError(t, err)
+	g := rml.TypeGraph()
+
+	names := make([]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		names[i] = n.Name
+	}
+	require.Equal(t, []string{"Cat", "Key", "Owner", "Tagged", "TaggedCat", "common.Id", "common.Pet"}, names)
+
+	require.Equal(t, []TypeDependency{
+		{From: "Cat", To: "common.Pet", Kind: TypeDependencyInherits},
+		{From: "Cat", To: "Cat", Kind: TypeDependencyReference, Path: "properties.friends.items"},
+	}, g.Edges["Cat"])
+	require.Equal(t, []TypeDependency{
+		{From: "TaggedCat", To: "Cat", Kind: TypeDependencyInherits},
+		{From: "TaggedCat", To: "Tagged", Kind: TypeDependencyInherits},
+	}, g.Edges["TaggedCat"])
+	require.Equal(t, []TypeDependency{
+		{From: "Owner", To: "common.Id", Kind: TypeDependencyReference, Path: "properties.id"},
+		{From: "Owner", To: "Cat", Kind: TypeDependencyReference, Path: "properties.pet.anyOf[0]"},
+		{From: "Owner", To: "common.Pet", Kind: TypeDependencyReference, Path: "properties.pet.anyOf[1]"},
+	}, g.Edges["Owner"])
+	require.Equal(t, []TypeDependency{{From: "Key", To: "common.Id", Kind: TypeDependencyAlias}}, g.Edges["Key"])
+	require.Len(t, g.Dependents("common.Pet"), 2)
+
+	var sb strings.Builder
+	require.NoError(t, g.WriteDOT(&sb))
+	dot := sb.String()
+	require.Contains(t, dot, `label="common.raml";`)
+	require.Contains(t, dot, `"Cat" -> "common.Pet" [arrowhead=empty];`)
+	require.Contains(t, dot, `"Key" -> "common.Id" [style=dashed];`)
+	require.Contains(t, dot, `"Owner" -> "Cat" [label="properties.pet.anyOf[0]"];`)
+}
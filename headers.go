@@ -0,0 +1,39 @@
+package raml
+
+import (
+	"net/http"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// HeaderValues returns values of the header, matching its name case-insensitively as HTTP does. Unlike
+// http.Header.Values, it also finds values set with non-canonical keys, e.g. by assigning to the map directly.
+func HeaderValues(header http.Header, name string) []string {
+	if values := header.Values(name); len(values) > 0 {
+		return values
+	}
+	for key, values := range header {
+		if strings.EqualFold(key, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+// LookupHeader returns the declared header parameter that matches the name case-insensitively and the name as
+// declared, which is kept for documentation and code generation.
+func LookupHeader(headers *orderedmap.OrderedMap[string, *BaseShape], name string) (string, *BaseShape, bool) {
+	if headers == nil {
+		return "", nil, false
+	}
+	if shape, ok := headers.Get(name); ok {
+		return name, shape, true
+	}
+	for pair := headers.Oldest(); pair != nil; pair = pair.Next() {
+		if strings.EqualFold(pair.Key, name) {
+			return pair.Key, pair.Value, true
+		}
+	}
+	return "", nil, false
+}
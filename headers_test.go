@@ -0,0 +1,34 @@
+package raml
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+func TestHeaderValues(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Request-Id", "a")
+	header["x-tenant"] = []string{"acme"}
+
+	require.Equal(t, []string{"a"}, HeaderValues(header, "x-request-id"))
+	require.Equal(t, []string{"acme"}, HeaderValues(header, "X-Tenant"))
+	require.Nil(t, HeaderValues(header, "X-Other"))
+}
+
+func TestLookupHeader(t *testing.T) {
+	shape := &BaseShape{Name: "X-Request-ID"}
+	headers := orderedmap.New[string, *BaseShape]()
+	headers.Set("X-Request-ID", shape)
+
+	name, found, ok := LookupHeader(headers, "x-request-id")
+	require.True(t, ok)
+	require.Equal(t, "X-Request-ID", name)
+	require.Same(t, shape, found)
+	_, _, ok = LookupHeader(headers, "X-Tenant")
+	require.False(t, ok)
+	_, _, ok = LookupHeader(nil, "X-Tenant")
+	require.False(t, ok)
+}
@@ -0,0 +1,382 @@
+// Package httpvalidate provides net/http middleware that validates requests and responses against RAML shapes.
+//
+// Operations (the shapes expected for a request and its responses) are matched to requests by a Resolver, e.g.
+// RouterResolver for operations of an API definition or a router of the application.
+package httpvalidate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/acronis/go-raml"
+)
+
+// Operation describes parameters and bodies of a request and responses of a method. Shapes must be unwrapped.
+// Parameters are required unless their shapes set "required: false". Headers are matched case-insensitively and
+// violations are reported with the names of headers as declared.
+type Operation struct {
+	URIParameters   map[string]*raml.BaseShape
+	QueryParameters map[string]*raml.BaseShape
+	Headers         map[string]*raml.BaseShape
+	// Body contains bodies keyed by media types, see raml.SelectBody.
+	Body *orderedmap.OrderedMap[string, *raml.BaseShape]
+	// Responses contains responses keyed by status codes.
+	Responses map[int]Response
+	// DefaultMediaTypes are media types of bodies declared without media type (the API-level mediaType).
+	DefaultMediaTypes []string
+}
+
+// Response describes headers and bodies of the response with the status code.
+type Response struct {
+	Headers map[string]*raml.BaseShape
+	Body    *orderedmap.OrderedMap[string, *raml.BaseShape]
+}
+
+// Resolver returns the operation of the request and values of URI parameters, false if the request is not described.
+type Resolver func(req *http.Request) (op *Operation, uriParams map[string]string, ok bool)
+
+// Location is the part of the request or the response a violation is found in.
+type Location string
+
+const (
+	LocationURI    Location = "uri"
+	LocationQuery  Location = "query"
+	LocationHeader Location = "header"
+	LocationBody   Location = "body"
+	LocationStatus Location = "status"
+)
+
+// Violation is a mismatch of the request or the response with its description.
+type Violation struct {
+	In Location `json:"in"`
+	// Name is the name of the parameter or the header, empty for bodies and statuses.
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error contains violations of the request or the response. It is written as the JSON body of rejected requests.
+type Error struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		if v.Name != "" {
+			msgs[i] = fmt.Sprintf("%s %s: %s", v.In, v.Name, v.Message)
+		} else {
+			msgs[i] = fmt.Sprintf("%s: %s", v.In, v.Message)
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+type Opt interface {
+	Apply(*Options)
+}
+
+type Options struct {
+	onResponseViolation func(req *http.Request, err *Error)
+	maxBodySize         int64
+}
+
+type optResponseValidation struct {
+	onViolation func(req *http.Request, err *Error)
+}
+
+func (o optResponseValidation) Apply(opts *Options) {
+	opts.onResponseViolation = o.onViolation
+}
+
+// WithResponseValidation validates responses and reports violations to the callback. Responses are passed to
+// the client unchanged.
+func WithResponseValidation(onViolation func(req *http.Request, err *Error)) Opt {
+	return optResponseValidation{onViolation: onViolation}
+}
+
+type optMaxBodySize struct {
+	size int64
+}
+
+func (o optMaxBodySize) Apply(opts *Options) {
+	opts.maxBodySize = o.size
+}
+
+// WithMaxBodySize limits the size of request and response bodies that are read for validation, 10 MB by default.
+func WithMaxBodySize(size int64) Opt {
+	return optMaxBodySize{size: size}
+}
+
+const defaultMaxBodySize = 10 << 20
+
+// Validator validates requests and responses of operations returned by the resolver.
+type Validator struct {
+	resolve Resolver
+	opts    Options
+}
+
+func New(resolve Resolver, opts ...Opt) *Validator {
+	v := &Validator{resolve: resolve, opts: Options{maxBodySize: defaultMaxBodySize}}
+	for _, opt := range opts {
+		opt.Apply(&v.opts)
+	}
+	return v
+}
+
+// Middleware rejects requests that do not match their operations with 400 Bad Request (415 Unsupported Media Type if
+// no body is declared for the media type) and the Error in the JSON body. Requests without operations are passed
+// to the handler as is.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		op, uriParams, ok := v.resolve(req)
+		if !ok {
+			next.ServeHTTP(w, req)
+			return
+		}
+		if err := v.ValidateRequest(req, op, uriParams); err != nil {
+			status := http.StatusBadRequest
+			var verr *Error
+			if !errors.As(err, &verr) {
+				verr = &Error{Violations: []Violation{{In: LocationBody, Message: err.Error()}}}
+			} else if isUnsupportedMediaType(verr) {
+				status = http.StatusUnsupportedMediaType
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			// The status is already written, so encoding errors cannot be reported to the client.
+			_ = json.NewEncoder(w).Encode(verr)
+			return
+		}
+		if v.opts.onResponseViolation == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK, limit: v.opts.maxBodySize}
+		next.ServeHTTP(rec, req)
+		if rec.truncated {
+			v.opts.onResponseViolation(req, &Error{Violations: []Violation{{In: LocationBody,
+				Message: bodyTooLargeMessage}}})
+			return
+		}
+		if err := v.ValidateResponse(op, rec.status, w.Header(), rec.body.Bytes()); err != nil {
+			v.opts.onResponseViolation(req, err)
+		}
+	})
+}
+
+const (
+	unsupportedMediaTypeMessage = "unsupported media type"
+	bodyTooLargeMessage         = "body is too large"
+)
+
+func isUnsupportedMediaType(err *Error) bool {
+	for _, v := range err.Violations {
+		if v.In == LocationBody && strings.HasPrefix(v.Message, unsupportedMediaTypeMessage) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateRequest checks parameters, headers and the body of the request. The body is read and replaced with
+// a reader of the same content. The returned error is *Error unless the body cannot be read.
+func (v *Validator) ValidateRequest(req *http.Request, op *Operation, uriParams map[string]string) error {
+	var violations []Violation
+	for _, name := range sortedNames(op.URIParameters) {
+		var values []string
+		if value, ok := uriParams[name]; ok {
+			values = []string{value}
+		}
+		violations = appendParamViolations(violations, LocationURI, name, op.URIParameters[name], values)
+	}
+	query := req.URL.Query()
+	for _, name := range sortedNames(op.QueryParameters) {
+		violations = appendParamViolations(violations, LocationQuery, name, op.QueryParameters[name], query[name])
+	}
+	for _, name := range sortedNames(op.Headers) {
+		violations = appendParamViolations(violations, LocationHeader, name, op.Headers[name],
+			raml.HeaderValues(req.Header, name))
+	}
+
+	if op.Body != nil && op.Body.Len() > 0 {
+		body, err := io.ReadAll(io.LimitReader(req.Body, v.opts.maxBodySize+1))
+		if err != nil {
+			return fmt.Errorf("read body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		if int64(len(body)) > v.opts.maxBodySize {
+			violations = append(violations, Violation{In: LocationBody, Message: bodyTooLargeMessage})
+		} else {
+			violations = appendBodyViolations(violations, op.Body, op.DefaultMediaTypes,
+				req.Header.Get("Content-Type"), body)
+		}
+	}
+	if len(violations) > 0 {
+		return &Error{Violations: violations}
+	}
+	return nil
+}
+
+// ValidateResponse checks the status code, headers and the body of the response.
+func (v *Validator) ValidateResponse(op *Operation, status int, header http.Header, body []byte) *Error {
+	resp, ok := op.Responses[status]
+	if !ok {
+		if len(op.Responses) == 0 {
+			return nil
+		}
+		return &Error{Violations: []Violation{{In: LocationStatus,
+			Message: fmt.Sprintf("status %d is not declared", status)}}}
+	}
+	var violations []Violation
+	for _, name := range sortedNames(resp.Headers) {
+		violations = appendParamViolations(violations, LocationHeader, name, resp.Headers[name],
+			raml.HeaderValues(header, name))
+	}
+	if resp.Body != nil && resp.Body.Len() > 0 {
+		violations = appendBodyViolations(violations, resp.Body, op.DefaultMediaTypes, header.Get("Content-Type"),
+			body)
+	}
+	if len(violations) > 0 {
+		return &Error{Violations: violations}
+	}
+	return nil
+}
+
+func sortedNames(params map[string]*raml.BaseShape) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func appendParamViolations(
+	violations []Violation, in Location, name string, shape *raml.BaseShape, values []string,
+) []Violation {
+	if len(values) == 0 {
+		if shape.Required == nil || *shape.Required {
+			violations = append(violations, Violation{In: in, Name: name, Message: "required parameter is missing"})
+		}
+		return violations
+	}
+	if err := shape.Validate(raml.ParameterValue(shape, values)); err != nil {
+		violations = append(violations, Violation{In: in, Name: name, Message: err.Error()})
+	}
+	return violations
+}
+
+func appendBodyViolations(
+	violations []Violation, bodies *orderedmap.OrderedMap[string, *raml.BaseShape], defaults []string,
+	contentType string, body []byte,
+) []Violation {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	shape, _, err := raml.SelectBody(bodies, defaults, contentType)
+	if err != nil {
+		return append(violations, Violation{In: LocationBody,
+			Message: fmt.Sprintf("%s: %s", unsupportedMediaTypeMessage, err)})
+	}
+	if raml.IsFormMediaType(contentType) {
+		if err = validateForm(shape, contentType, body); err != nil {
+			violations = append(violations, Violation{In: LocationBody, Message: err.Error()})
+		}
+		return violations
+	}
+	value, err := bodyValue(shape, contentType, body)
+	if err != nil {
+		return append(violations, Violation{In: LocationBody, Message: err.Error()})
+	}
+	if err = shape.Validate(value); err != nil {
+		violations = append(violations, Violation{In: LocationBody, Message: err.Error()})
+	}
+	return violations
+}
+
+// validateForm validates fields and files of form bodies. The body is already limited in size and read, so files
+// are kept in memory.
+func validateForm(shape *raml.BaseShape, contentType string, body []byte) error {
+	form, err := raml.ParseForm(contentType, bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return err
+	}
+	if mf, ok := form.(*multipart.Form); ok {
+		// Files fit into memory, so there are no temporary files to fail to remove.
+		defer func() { _ = mf.RemoveAll() }()
+	}
+	return shape.ValidateForm(form)
+}
+
+// bodyValue decodes JSON bodies, other bodies are validated as file contents or strings.
+func bodyValue(shape *raml.BaseShape, contentType string, body []byte) (any, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("parse media type: %w", err)
+	}
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		var value any
+		if err = json.Unmarshal(body, &value); err != nil {
+			return nil, fmt.Errorf("decode body: %w", err)
+		}
+		return value, nil
+	}
+	if _, ok := shape.Shape.(*raml.FileShape); ok {
+		return raml.FileContent{MediaType: contentType, Reader: bytes.NewReader(body)}, nil
+	}
+	return string(body), nil
+}
+
+// recorder passes the response to the client and keeps a copy of the status and the body for validation. Bodies
+// larger than the limit are not recorded.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	limit       int64
+	truncated   bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	if !r.truncated {
+		if int64(r.body.Len()+len(p)) > r.limit {
+			r.truncated = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(p)
+		}
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+// Flush sends buffered data to the client if the underlying writer supports it.
+func (r *recorder) Flush() {
+	r.wroteHeader = true
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,160 @@
+package httpvalidate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/acronis/go-raml"
+)
+
+func TestValidator_Middleware(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Id:
+    type: integer
+    minimum: 1
+  Limit:
+    type: integer
+    maximum: 100
+    required: false
+  Tags:
+    type: array
+    items: string
+    required: false
+  Pet:
+    type: object
+    properties:
+      name:
+        type: string
+        minLength: 3
+`
+	rml, err := raml.ParseFromString(content, "library.raml", t.TempDir(), raml.OptWithUnwrap())
+	require.NoError(t, err)
+	lookup := func(name string) *raml.BaseShape {
+		shape, errLookup := rml.LookupType(name, "")
+		require.NoError(t, errLookup)
+		return shape
+	}
+	body := orderedmap.New[string, *raml.BaseShape]()
+	body.Set("", lookup("Pet"))
+	body.Set("application/x-www-form-urlencoded", lookup("Pet"))
+	op := &Operation{
+		URIParameters:     map[string]*raml.BaseShape{"id": lookup("Id")},
+		QueryParameters:   map[string]*raml.BaseShape{"limit": lookup("Limit"), "tag": lookup("Tags")},
+		Body:              body,
+		DefaultMediaTypes: []string{"application/json"},
+		Responses:         map[int]Response{http.StatusOK: {Body: body}},
+	}
+	resolve := func(req *http.Request) (*Operation, map[string]string, bool) {
+		id, ok := strings.CutPrefix(req.URL.Path, "/pets/")
+		return op, map[string]string{"id": id}, ok
+	}
+	var responseErr *Error
+	v := New(resolve, WithResponseValidation(func(_ *http.Request, err *Error) { responseErr = err }))
+	handler := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(req.URL.Query().Get("reply")))
+	}))
+
+	serve := func(target string, contentType string, reqBody string) (*httptest.ResponseRecorder, *Error) {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(reqBody))
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code == http.StatusOK {
+			return rec, nil
+		}
+		var verr Error
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &verr))
+		return rec, &verr
+	}
+
+	rec, verr := serve(`/pets/1?limit=10&tag=a&tag=b&reply={"name":"Rex"}`, "application/json", `{"name":"Tom"}`)
+	require.Nil(t, verr)
+	require.Equal(t, `{"name":"Rex"}`, rec.Body.String())
+	require.Nil(t, responseErr)
+
+	_, _ = serve(`/pets/1?reply={"name":"R"}`, "application/json; charset=utf-8", `{"name":"Tom"}`)
+	require.NotNil(t, responseErr)
+	require.Equal(t, LocationBody, responseErr.Violations[0].In)
+
+	rec, verr = serve("/pets/0?limit=x", "application/json", `{"name":"T"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Len(t, verr.Violations, 3)
+	require.Equal(t, Violation{In: LocationURI, Name: "id", Message: verr.Violations[0].Message}, verr.Violations[0])
+	require.Equal(t, "limit", verr.Violations[1].Name)
+	require.Equal(t, LocationBody, verr.Violations[2].In)
+
+	rec, _ = serve("/pets/1", "application/x-www-form-urlencoded", "name=Tom")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec, verr = serve("/pets/1", "application/x-www-form-urlencoded", "name=T")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, LocationBody, verr.Violations[0].In)
+
+	rec, _ = serve("/pets/1", "text/plain", "Tom")
+	require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	rec, _ = serve("/other", "text/plain", "anything")
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestValidator_MiddlewareLargeResponse(t *testing.T) {
+	rml, err := raml.ParseFromString("#%RAML 1.0 Library\ntypes:\n  Name: string\n", "library.raml", t.TempDir(),
+		raml.OptWithUnwrap())
+	require.NoError(t, err)
+	name, err := rml.LookupType("Name", "")
+	require.NoError(t, err)
+	body := orderedmap.New[string, *raml.BaseShape]()
+	body.Set("", name)
+	op := &Operation{
+		DefaultMediaTypes: []string{"application/json"},
+		Responses:         map[int]Response{http.StatusOK: {Body: body}},
+	}
+	resolve := func(*http.Request) (*Operation, map[string]string, bool) { return op, nil, true }
+	var responseErr *Error
+	v := New(resolve, WithMaxBodySize(8),
+		WithResponseValidation(func(_ *http.Request, err *Error) { responseErr = err }))
+	handler := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`"Rex`))
+		require.NoError(t, http.NewResponseController(w).Flush())
+		_, _ = w.Write([]byte(req.URL.Query().Get("reply")))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?reply=%22", nil))
+	require.Nil(t, responseErr)
+	require.True(t, rec.Flushed)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?reply=ford%22", nil))
+	require.Equal(t, `"Rexford"`, rec.Body.String())
+	require.Equal(t, &Error{Violations: []Violation{{In: LocationBody, Message: "body is too large"}}}, responseErr)
+}
+
+func TestValidator_ValidateResponseHeaders(t *testing.T) {
+	rml, err := raml.ParseFromString("#%RAML 1.0 Library\ntypes:\n  Id:\n    type: integer\n    minimum: 1\n",
+		"library.raml", t.TempDir(), raml.OptWithUnwrap())
+	require.NoError(t, err)
+	id, err := rml.LookupType("Id", "")
+	require.NoError(t, err)
+	op := &Operation{Responses: map[int]Response{http.StatusOK: {
+		Headers: map[string]*raml.BaseShape{"X-Request-ID": id},
+	}}}
+	v := New(func(*http.Request) (*Operation, map[string]string, bool) { return op, nil, true })
+
+	// Handlers may assign headers with non-canonical keys to the map directly.
+	require.Nil(t, v.ValidateResponse(op, http.StatusOK, http.Header{"x-request-id": {"5"}}, nil))
+	verr := v.ValidateResponse(op, http.StatusOK, http.Header{"x-request-id": {"0"}}, nil)
+	require.NotNil(t, verr)
+	require.Equal(t, "X-Request-ID", verr.Violations[0].Name)
+	verr = v.ValidateResponse(op, http.StatusOK, http.Header{}, nil)
+	require.Equal(t, []Violation{{In: LocationHeader, Name: "X-Request-ID", Message: "required parameter is missing"}},
+		verr.Violations)
+}
@@ -0,0 +1,53 @@
+package httpvalidate
+
+import (
+	"net/http"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	"github.com/acronis/go-raml"
+)
+
+// FromOperation returns the description of the operation of an API definition. DefaultMediaTypes are usually
+// raml.API.MediaType. The API must be parsed with raml.OptWithUnwrap.
+func FromOperation(op *raml.Operation, defaultMediaTypes []string) *Operation {
+	out := &Operation{
+		URIParameters:     shapeMap(op.URIParameters),
+		QueryParameters:   shapeMap(op.QueryParameters),
+		Headers:           shapeMap(op.Headers),
+		Body:              op.Body,
+		Responses:         make(map[int]Response, op.Responses.Len()),
+		DefaultMediaTypes: defaultMediaTypes,
+	}
+	for pair := op.Responses.Oldest(); pair != nil; pair = pair.Next() {
+		out.Responses[pair.Key] = Response{Headers: shapeMap(pair.Value.Headers), Body: pair.Value.Body}
+	}
+	return out
+}
+
+func shapeMap(shapes *orderedmap.OrderedMap[string, *raml.BaseShape]) map[string]*raml.BaseShape {
+	m := make(map[string]*raml.BaseShape, shapes.Len())
+	for pair := shapes.Oldest(); pair != nil; pair = pair.Next() {
+		m[pair.Key] = pair.Value
+	}
+	return m
+}
+
+// RouterResolver returns a resolver that matches requests to operations of the API with the router. BasePath is
+// the path of the base URI that is trimmed from request paths, e.g. "/api/v1". Requests with invalid URI parameters
+// are resolved, so the validator reports the violations; requests of unknown resources or methods are not.
+func RouterResolver(api *raml.API, router *raml.Router, basePath string) Resolver {
+	basePath = strings.TrimSuffix(basePath, "/")
+	return func(req *http.Request) (*Operation, map[string]string, bool) {
+		path, ok := strings.CutPrefix(req.URL.EscapedPath(), basePath)
+		if !ok {
+			return nil, nil, false
+		}
+		op, params, _ := router.MatchOperation(req.Method, path)
+		if op == nil {
+			return nil, nil, false
+		}
+		return FromOperation(op, api.MediaType), params, true
+	}
+}
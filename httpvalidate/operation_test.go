@@ -0,0 +1,65 @@
+package httpvalidate
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-raml"
+)
+
+func TestRouterResolver(t *testing.T) {
+	content := `#%RAML 1.0
+title: Pets
+mediaType: application/json
+/pets/{id}:
+  uriParameters:
+    id:
+      type: integer
+      minimum: 1
+  put:
+    body:
+      properties:
+        name: string
+`
+	rml, err := raml.ParseFromString(content, "api.raml", t.TempDir(), raml.OptWithUnwrap())
+	require.NoError(t, err)
+	router, err := raml.NewRouter(rml.API())
+	require.NoError(t, err)
+	v := New(RouterResolver(rml.API(), router, "/api/"))
+	handler := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "valid", method: http.MethodPut, path: "/api/pets/1", body: `{"name":"Rex"}`,
+			wantStatus: http.StatusNoContent},
+		{name: "invalid uri parameter", method: http.MethodPut, path: "/api/pets/0", body: `{"name":"Rex"}`,
+			wantStatus: http.StatusBadRequest, wantBody: `"in":"uri","name":"id"`},
+		{name: "invalid body", method: http.MethodPut, path: "/api/pets/1", body: `{}`,
+			wantStatus: http.StatusBadRequest, wantBody: `"in":"body"`},
+		{name: "unknown method", method: http.MethodGet, path: "/api/pets/1", wantStatus: http.StatusNoContent},
+		{name: "unknown resource", method: http.MethodPut, path: "/api/owners/1", wantStatus: http.StatusNoContent},
+		{name: "outside base path", method: http.MethodPut, path: "/pets/0", wantStatus: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, tt.wantStatus, rec.Code)
+			require.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
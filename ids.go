@@ -0,0 +1,78 @@
+package raml
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+type parseOptWithDerivedShapeIDs struct{}
+
+func (parseOptWithDerivedShapeIDs) Apply(opt *parserOptions) {
+	opt.derivedShapeIDs = true
+}
+
+// OptWithDerivedShapeIDs derives IDs of shapes from their locations, names and positions instead of numbering shapes
+// in the order they are created. IDs of shapes declared by a fragment stay the same when other fragments change, so
+// snapshots and caches keyed by IDs remain valid. Shapes created by resolution derive IDs from the shapes they are
+// made of.
+func OptWithDerivedShapeIDs() ParseOpt {
+	return parseOptWithDerivedShapeIDs{}
+}
+
+// newShapeID returns the ID of a new shape declared at the position. IDs are unique within the RAML.
+func (r *RAML) newShapeID(name string, location string, position *stacktrace.Position) int64 {
+	if !r.derivedShapeIDs {
+		// Shapes are created concurrently when they are resolved in parallel.
+		return atomic.AddInt64(&r.lastShapeID, 1)
+	}
+	h := fnv.New64a()
+	h.Write([]byte(location))
+	h.Write([]byte{0})
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	if position != nil {
+		h.Write([]byte(strconv.Itoa(position.Line) + ":" + strconv.Itoa(position.Column)))
+	}
+	return r.reserveShapeID(h.Sum64())
+}
+
+// newCopyID returns the ID of a new shape that is made of the original shape and the source it inherits from.
+func (r *RAML) newCopyID(original int64, source int64) int64 {
+	if r == nil {
+		// Shapes that do not belong to a RAML are not registered, so the ID is only derived.
+		return int64(copyHash(original, source) & math.MaxInt64)
+	}
+	if !r.derivedShapeIDs {
+		return atomic.AddInt64(&r.lastShapeID, 1)
+	}
+	return r.reserveShapeID(copyHash(original, source))
+}
+
+func copyHash(original int64, source int64) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(strconv.FormatInt(original, 10) + "+" + strconv.FormatInt(source, 10)))
+	return h.Sum64()
+}
+
+// reserveShapeID reserves the first unused positive ID starting from the hash. Shapes declared at the same position,
+// e.g. members of "string?", take subsequent IDs in the order they are created.
+func (r *RAML) reserveShapeID(hash uint64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.usedShapeIDs == nil {
+		r.usedShapeIDs = make(map[int64]struct{})
+	}
+	id := int64(hash & math.MaxInt64)
+	for {
+		if _, ok := r.usedShapeIDs[id]; !ok && id != 0 {
+			break
+		}
+		id = (id + 1) & math.MaxInt64
+	}
+	r.usedShapeIDs[id] = struct{}{}
+	return id
+}
@@ -0,0 +1,58 @@
+package raml
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShapeIDs(t *testing.T) {
+	dir, err := filepath.Abs("./fixtures/ids")
+	require.NoError(t, err)
+	contents := []string{`#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Pet:
+    type: [common.Named, common.Tagged]
+  Cat: Pet | common.Named
+`, `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Pet:
+    type: [common.Named, common.Tagged]
+  Cat: Pet | common.Named
+  Dog:
+    type: Pet
+    properties:
+      breed: string
+`}
+	ids := func(content string, opts ...ParseOpt) map[string]int64 {
+		r, err := ParseFromString(content, "library.raml", dir, append(opts, OptWithUnwrap())...)
+		require.NoError(t, err)
+		seen := make(map[int64]struct{})
+		for _, s := range r.GetShapes() {
+			_, ok := seen[s.ID]
+			require.False(t, ok, "duplicate ID %d of %s", s.ID, s.Name)
+			seen[s.ID] = struct{}{}
+		}
+		result := make(map[string]int64)
+		for _, name := range []string{"Pet", "Cat", "common.Named", "common.Tagged"} {
+			s, err := r.LookupType(name, r.GetLocation())
+			require.NoError(t, err)
+			result[name] = s.ID
+		}
+		return result
+	}
+
+	// IDs are allocated per RAML, so parsing the same specification gives the same IDs.
+	first := ids(contents[0])
+	require.Equal(t, first, ids(contents[0]))
+	require.NotEqual(t, first, ids(contents[1]))
+
+	derived := ids(contents[0], OptWithDerivedShapeIDs())
+	require.Equal(t, derived, ids(contents[1], OptWithDerivedShapeIDs()))
+	require.Equal(t, derived, ids(contents[1], OptWithDerivedShapeIDs(), OptWithParallelResolution(4)))
+}
@@ -0,0 +1,142 @@
+package raml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// IncludeResolver loads fragments referenced by !include and uses, e.g. from the filesystem, an artifact registry,
+// S3 or a database.
+//
+// ResolveInclude returns the content of the fragment referenced by ref from the fragment at baseLocation and
+// the canonical location of the fragment. Canonical locations identify fragments: a fragment referenced from several
+// places is parsed once, and references inside the fragment are resolved against its location. BaseLocation is
+// empty for the entry point passed to ParseFromPath. The caller closes the content.
+type IncludeResolver interface {
+	ResolveInclude(baseLocation string, ref string) (content io.ReadCloser, canonicalLocation string, err error)
+}
+
+// IncludeLocator is implemented by resolvers that return canonical locations without loading content, so
+// fragments that are already parsed are not loaded again.
+type IncludeLocator interface {
+	LocateInclude(baseLocation string, ref string) (canonicalLocation string, err error)
+}
+
+// FileResolver is the default IncludeResolver that reads fragments from the filesystem. References are paths
+// relative to the directory of the including fragment, the entry point is relative to the working directory.
+type FileResolver struct{}
+
+// LocateInclude implements IncludeLocator.
+func (FileResolver) LocateInclude(baseLocation string, ref string) (string, error) {
+	if baseLocation != "" {
+		ref = filepath.Join(filepath.Dir(baseLocation), ref)
+	}
+	if filepath.IsAbs(ref) {
+		return ref, nil
+	}
+	workdir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("get workdir: %w", err)
+	}
+	return filepath.Join(workdir, ref), nil
+}
+
+// ResolveInclude implements IncludeResolver.
+func (fr FileResolver) ResolveInclude(baseLocation string, ref string) (io.ReadCloser, string, error) {
+	location, err := fr.LocateInclude(baseLocation, ref)
+	if err != nil {
+		return nil, "", err
+	}
+	f, err := os.Open(location)
+	if err != nil {
+		return nil, "", fmt.Errorf("open file: %w", err)
+	}
+	return f, location, nil
+}
+
+type parseOptWithIncludeResolver struct {
+	resolver IncludeResolver
+}
+
+func (o parseOptWithIncludeResolver) Apply(opt *parserOptions) {
+	opt.includeResolver = o.resolver
+}
+
+// OptWithIncludeResolver loads fragments with the resolver instead of reading them from the filesystem.
+func OptWithIncludeResolver(resolver IncludeResolver) ParseOpt {
+	return parseOptWithIncludeResolver{resolver: resolver}
+}
+
+func (r *RAML) includeResolverOrDefault() IncludeResolver {
+	if r.includeResolver == nil {
+		return FileResolver{}
+	}
+	return r.includeResolver
+}
+
+// resolveInclude returns the interned canonical location of the fragment and its content. The content is nil if
+// the resolver locates fragments and the fragment is already parsed.
+func (r *RAML) resolveInclude(baseLocation string, ref string) (string, io.ReadCloser, error) {
+	resolver := r.includeResolverOrDefault()
+	if locator, ok := resolver.(IncludeLocator); ok {
+		location, err := locator.LocateInclude(baseLocation, ref)
+		if err != nil {
+			r.logInclude(baseLocation, ref, "", false, err)
+			return "", nil, fmt.Errorf("locate include %s: %w", ref, err)
+		}
+		location = r.internLocation(location)
+		if r.GetFragment(location) != nil {
+			r.logInclude(baseLocation, ref, location, true, nil)
+			return location, nil, nil
+		}
+	}
+	return r.openInclude(baseLocation, ref)
+}
+
+// openInclude returns the interned canonical location and the content of a raw fragment, e.g. an included example.
+// Raw fragments are not cached, so the content is always loaded.
+func (r *RAML) openInclude(baseLocation string, ref string) (string, io.ReadCloser, error) {
+	content, location, err := r.includeResolverOrDefault().ResolveInclude(baseLocation, ref)
+	if err != nil {
+		r.logInclude(baseLocation, ref, "", false, err)
+		return "", nil, fmt.Errorf("resolve include %s: %w", ref, err)
+	}
+	if location == "" {
+		_ = content.Close()
+		r.logInclude(baseLocation, ref, "", false, errEmptyLocation)
+		return "", nil, fmt.Errorf("resolve include %s: %w", ref, errEmptyLocation)
+	}
+	location = r.internLocation(location)
+	r.logInclude(baseLocation, ref, location, false, nil)
+	return location, content, nil
+}
+
+var errEmptyLocation = errors.New("canonical location is empty")
+
+// seekableContent returns the content as io.ReadSeeker to identify fragments by their heads. Contents that cannot
+// seek are read into memory, at most limit bytes plus one if the limit is set, so the limit of the total size still
+// rejects them when they are decoded.
+func seekableContent(content io.Reader, limit int64) (io.ReadSeeker, error) {
+	if rs, ok := content.(io.ReadSeeker); ok {
+		return rs, nil
+	}
+	if limit > 0 {
+		content = io.LimitReader(content, limit+1)
+	}
+	b, err := io.ReadAll(content)
+	if err != nil {
+		return nil, fmt.Errorf("read content: %w", err)
+	}
+	return bytes.NewReader(b), nil
+}
+
+// closeContent closes the content of an included fragment. Contents are only read, so errors are ignored.
+func closeContent(content io.Closer) {
+	if content != nil {
+		_ = content.Close()
+	}
+}
@@ -0,0 +1,82 @@
+package raml
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mapResolver resolves includes from memory, e.g. as a resolver of an artifact registry would.
+type mapResolver struct {
+	files    map[string]string
+	resolved map[string]int
+}
+
+func (m *mapResolver) ResolveInclude(baseLocation string, ref string) (io.ReadCloser, string, error) {
+	location := ref
+	if baseLocation != "" {
+		location = path.Join(path.Dir(baseLocation), ref)
+	}
+	content, ok := m.files[location]
+	if !ok {
+		return nil, "", fmt.Errorf("%s not found", location)
+	}
+	m.resolved[location]++
+	return io.NopCloser(strings.NewReader(content)), location, nil
+}
+
+func TestOptWithIncludeResolver(t *testing.T) {
+	resolver := &mapResolver{
+		files: map[string]string{
+			"specs/api.raml": `#%RAML 1.0 Library
+uses:
+  common: libs/common.raml
+types:
+  Pet:
+    type: common.Named
+    properties:
+      tag: !include types/tag.raml
+    example: !include examples/pet.json
+`,
+			"specs/libs/common.raml": `#%RAML 1.0 Library
+uses:
+  tags: ../types/tags.raml
+types:
+  Named:
+    properties:
+      name: string
+      tags?: tags.Tag[]
+`,
+			"specs/types/tags.raml": `#%RAML 1.0 Library
+types:
+  Tag: string
+`,
+			"specs/types/tag.raml": `#%RAML 1.0 DataType
+type: string
+minLength: 2
+`,
+			"specs/examples/pet.json": `{"name": "Rex", "tag": "dog"}`,
+		},
+		resolved: make(map[string]int),
+	}
+
+	r, err := ParseFromPath("specs/api.raml", OptWithIncludeResolver(resolver), OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	require.Equal(t, "specs/api.raml", r.GetLocation())
+	require.NotNil(t, r.GetFragment("specs/libs/common.raml"))
+	require.NotNil(t, r.GetFragment("specs/types/tags.raml"))
+	require.Equal(t, 1, resolver.resolved["specs/types/tag.raml"])
+
+	pet := r.EntryPoint().(*Library).Types.Value("Pet")
+	require.NotNil(t, pet)
+	require.Error(t, pet.Validate(map[string]any{"name": "Rex", "tag": "d"}))
+	require.NoError(t, pet.Validate(map[string]any{"name": "Rex", "tag": "dog"}))
+
+	delete(resolver.files, "specs/types/tags.raml")
+	_, err = ParseFromPath("specs/api.raml", OptWithIncludeResolver(resolver))
+	require.ErrorContains(t, err, "specs/types/tags.raml not found")
+}
@@ -0,0 +1,81 @@
+package raml
+
+import (
+	"github.com/acronis/go-stacktrace"
+)
+
+// InheritancePolicy defines how constraints of a subtype that conflict with the constraints of its parent are
+// treated during inheritance, e.g. "minLength: 1" of a type that inherits "minLength: 5".
+type InheritancePolicy int
+
+const (
+	// InheritanceStrictSpec fails unwrapping on conflicting constraints. This is the default policy.
+	InheritanceStrictSpec InheritancePolicy = iota
+	// InheritanceNarrowAllowed merges conflicting constraints to their intersection, so the narrower constraint wins
+	// regardless of whether it is declared by the subtype or the parent. Constraints that have no intersection
+	// (e.g. different formats or disjoint enums) still fail unwrapping.
+	InheritanceNarrowAllowed
+	// InheritanceWarnOnly keeps constraints of the subtype as declared and reports conflicts by RAML.Warnings.
+	InheritanceWarnOnly
+)
+
+type parseOptWithInheritancePolicy struct {
+	policy InheritancePolicy
+}
+
+func (o parseOptWithInheritancePolicy) Apply(opt *parserOptions) {
+	opt.inheritancePolicy = o.policy
+}
+
+// OptWithInheritancePolicy sets how constraint conflicts during inheritance are treated, InheritanceStrictSpec by
+// default. RAML tools interpret inheritance rules differently, so relaxed policies help to parse specifications
+// written for other tools.
+func OptWithInheritancePolicy(policy InheritancePolicy) ParseOpt {
+	return parseOptWithInheritancePolicy{policy: policy}
+}
+
+// inheritConflict handles the constraint of the shape that conflicts with the inherited one according to
+// the inheritance policy. narrow replaces the constraint with the intersection of both constraints, it is nil if
+// there is no intersection.
+func (s *BaseShape) inheritConflict(st *stacktrace.StackTrace, narrow func()) error {
+	policy := InheritanceStrictSpec
+	if s.raml != nil {
+		policy = s.raml.inheritancePolicy
+	}
+	// Parents do not narrow each other, so their constraints are intersected unless conflicts are only reported.
+	if s.mergedParent && policy == InheritanceStrictSpec {
+		policy = InheritanceNarrowAllowed
+	}
+	switch {
+	case policy == InheritanceNarrowAllowed && narrow != nil:
+		s.raml.logInheritConflict(s, st.Message, "narrow")
+		narrow()
+		return nil
+	case policy == InheritanceWarnOnly:
+		s.raml.logInheritConflict(s, st.Message, "warn")
+		s.raml.addInheritanceWarning(st)
+		return nil
+	}
+	s.raml.logInheritConflict(s, st.Message, "fail")
+	return st
+}
+
+// addInheritanceWarning records the conflict once, since shapes may be inherited repeatedly, e.g. by unwrapping of
+// union members or of subtypes that are not unwrapped in-place.
+func (r *RAML) addInheritanceWarning(st *stacktrace.StackTrace) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, w := range r.warnings {
+		if w.Message == st.Message && w.Location == st.Location && samePosition(w.Position, st.Position) {
+			return
+		}
+	}
+	r.warnings = append(r.warnings, st.SetSeverity(stacktrace.SeverityWarning))
+}
+
+func samePosition(a, b *stacktrace.Position) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,153 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptWithInheritancePolicy(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Parent:
+    type: string
+    minLength: 5
+    maxLength: 10
+    enum: [alpha, bravo, charlie]
+  Child:
+    type: Parent
+    minLength: 1
+    enum: [alpha, delta]
+`
+	tests := []struct {
+		name      string
+		policy    InheritancePolicy
+		err       string
+		minLength uint64
+		enum      []any
+		warnings  []string
+	}{
+		{
+			name:   "strict spec",
+			policy: InheritanceStrictSpec,
+			err:    "minLength constraint violation",
+		},
+		{
+			name:      "narrow allowed",
+			policy:    InheritanceNarrowAllowed,
+			minLength: 5,
+			enum:      []any{"alpha"},
+		},
+		{
+			name:      "warn only",
+			policy:    InheritanceWarnOnly,
+			minLength: 1,
+			enum:      []any{"alpha", "delta"},
+			warnings:  []string{"minLength constraint violation", "enum constraint violation"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap(),
+				OptWithInheritancePolicy(tt.policy))
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			child, err := r.LookupType("Child", r.GetLocation())
+			require.NoError(t, err)
+			shape := child.Shape.(*StringShape)
+			require.Equal(t, tt.minLength, *shape.MinLength)
+			require.Equal(t, uint64(10), *shape.MaxLength)
+			enum := make([]any, len(shape.Enum))
+			for i, v := range shape.Enum {
+				enum[i] = v.Value
+			}
+			require.Equal(t, tt.enum, enum)
+
+			var warnings []string
+			for _, w := range r.Warnings() {
+				warnings = append(warnings, w.Message)
+			}
+			require.Equal(t, tt.warnings, warnings)
+		})
+	}
+}
+
+func TestOptWithInheritancePolicy_NoIntersection(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Parent:
+    type: integer
+    format: int8
+  Child:
+    type: Parent
+    format: int64
+`
+	_, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap(),
+		OptWithInheritancePolicy(InheritanceNarrowAllowed))
+	require.ErrorContains(t, err, "format constraint violation")
+}
+
+func TestOptWithInheritancePolicy_ArrayObject(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Parent:
+    type: array
+    items: string
+    minItems: 1
+    maxItems: 10
+  Narrowed:
+    type: Parent
+    minItems: 5
+    maxItems: 8
+  Widened:
+    type: Parent
+    minItems: 0
+    maxItems: 20
+  ParentObject:
+    minProperties: 1
+    maxProperties: 10
+  NarrowedObject:
+    type: ParentObject
+    minProperties: 5
+    maxProperties: 8
+  WidenedObject:
+    type: ParentObject
+    minProperties: 0
+    maxProperties: 20
+`
+	_, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap(),
+		OptWithInheritancePolicy(InheritanceStrictSpec))
+	require.ErrorContains(t, err, "constraint violation")
+
+	r, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap(),
+		OptWithInheritancePolicy(InheritanceWarnOnly))
+	require.NoError(t, err)
+	var warnings []string
+	for _, w := range r.Warnings() {
+		warnings = append(warnings, w.Message)
+	}
+	require.ElementsMatch(t, []string{
+		"minItems constraint violation", "maxItems constraint violation",
+		"minProperties constraint violation", "maxProperties constraint violation",
+	}, warnings)
+
+	r, err = ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap(),
+		OptWithInheritancePolicy(InheritanceNarrowAllowed))
+	require.NoError(t, err)
+	for name, want := range map[string][2]uint64{"Narrowed": {5, 8}, "Widened": {1, 10}} {
+		typ, err := r.LookupType(name, r.GetLocation())
+		require.NoError(t, err)
+		shape := typ.Shape.(*ArrayShape)
+		require.Equal(t, want, [2]uint64{*shape.MinItems, *shape.MaxItems}, name)
+	}
+	for name, want := range map[string][2]uint64{"NarrowedObject": {5, 8}, "WidenedObject": {1, 10}} {
+		typ, err := r.LookupType(name, r.GetLocation())
+		require.NoError(t, err)
+		shape := typ.Shape.(*ObjectShape)
+		require.Equal(t, want, [2]uint64{*shape.MinProperties, *shape.MaxProperties}, name)
+	}
+}
@@ -160,9 +160,21 @@ func (c *JSONSchemaConverter) VisitUnionShape(s *UnionShape) *JSONSchema {
 	for i, item := range s.AnyOf {
 		schema.AnyOf[i] = c.Visit(item.Shape)
 	}
+	schema.Enum = jsonSchemaEnum(s.Enum)
 	return schema
 }
 
+func jsonSchemaEnum(enum Nodes) []interface{} {
+	if enum == nil {
+		return nil
+	}
+	values := make([]interface{}, len(enum))
+	for i, v := range enum {
+		values[i] = v.Value
+	}
+	return values
+}
+
 func (c *JSONSchemaConverter) VisitStringShape(s *StringShape) *JSONSchema {
 	schema := c.makeSchemaFromBaseShape(s.Base())
 	schema.Type = TypeString
@@ -171,12 +183,7 @@ func (c *JSONSchemaConverter) VisitStringShape(s *StringShape) *JSONSchema {
 	if s.Pattern != nil {
 		schema.Pattern = s.Pattern.String()
 	}
-	if s.Enum != nil {
-		schema.Enum = make([]interface{}, len(s.Enum))
-		for i, v := range s.Enum {
-			schema.Enum[i] = v.Value
-		}
-	}
+	schema.Enum = jsonSchemaEnum(s.Enum)
 	return schema
 }
 
@@ -184,20 +191,23 @@ func (c *JSONSchemaConverter) VisitIntegerShape(s *IntegerShape) *JSONSchema {
 	schema := c.makeSchemaFromBaseShape(s.Base())
 	schema.Type = TypeInteger
 	if s.Minimum != nil {
-		schema.Minimum = json.Number(s.Minimum.String())
+		if s.isExclusiveBound(AnnotationExclusiveMinimum) {
+			schema.ExclusiveMinimum = json.Number(s.Minimum.String())
+		} else {
+			schema.Minimum = json.Number(s.Minimum.String())
+		}
 	}
 	if s.Maximum != nil {
-		schema.Maximum = json.Number(s.Maximum.String())
+		if s.isExclusiveBound(AnnotationExclusiveMaximum) {
+			schema.ExclusiveMaximum = json.Number(s.Maximum.String())
+		} else {
+			schema.Maximum = json.Number(s.Maximum.String())
+		}
 	}
 	if s.MultipleOf != nil {
 		schema.MultipleOf = json.Number(strconv.FormatFloat(*s.MultipleOf, 'f', -1, 64))
 	}
-	if s.Enum != nil {
-		schema.Enum = make([]interface{}, len(s.Enum))
-		for i, v := range s.Enum {
-			schema.Enum[i] = v.Value
-		}
-	}
+	schema.Enum = jsonSchemaEnum(s.Enum)
 	// TODO: JSON Schema does not have a format for numbers
 	return schema
 }
@@ -206,20 +216,23 @@ func (c *JSONSchemaConverter) VisitNumberShape(s *NumberShape) *JSONSchema {
 	schema := c.makeSchemaFromBaseShape(s.Base())
 	schema.Type = TypeNumber
 	if s.Minimum != nil {
-		schema.Minimum = json.Number(strconv.FormatFloat(*s.Minimum, 'f', -1, 64))
+		if s.isExclusiveBound(AnnotationExclusiveMinimum) {
+			schema.ExclusiveMinimum = json.Number(strconv.FormatFloat(*s.Minimum, 'f', -1, 64))
+		} else {
+			schema.Minimum = json.Number(strconv.FormatFloat(*s.Minimum, 'f', -1, 64))
+		}
 	}
 	if s.Maximum != nil {
-		schema.Maximum = json.Number(strconv.FormatFloat(*s.Maximum, 'f', -1, 64))
+		if s.isExclusiveBound(AnnotationExclusiveMaximum) {
+			schema.ExclusiveMaximum = json.Number(strconv.FormatFloat(*s.Maximum, 'f', -1, 64))
+		} else {
+			schema.Maximum = json.Number(strconv.FormatFloat(*s.Maximum, 'f', -1, 64))
+		}
 	}
 	if s.MultipleOf != nil {
 		schema.MultipleOf = json.Number(strconv.FormatFloat(*s.MultipleOf, 'f', -1, 64))
 	}
-	if s.Enum != nil {
-		schema.Enum = make([]interface{}, len(s.Enum))
-		for i, v := range s.Enum {
-			schema.Enum[i] = v.Value
-		}
-	}
+	schema.Enum = jsonSchemaEnum(s.Enum)
 	// TODO: JSON Schema does not have a format for numbers
 	return schema
 }
@@ -246,12 +259,7 @@ func (c *JSONSchemaConverter) VisitBooleanShape(s *BooleanShape) *JSONSchema {
 	schema := c.makeSchemaFromBaseShape(s.Base())
 	schema.Type = TypeBoolean
 
-	if s.Enum != nil {
-		schema.Enum = make([]interface{}, len(s.Enum))
-		for i, v := range s.Enum {
-			schema.Enum[i] = v.Value
-		}
-	}
+	schema.Enum = jsonSchemaEnum(s.Enum)
 	return schema
 }
 
@@ -271,6 +279,7 @@ func (c *JSONSchemaConverter) VisitDateTimeShape(s *DateTimeShape) *JSONSchema {
 	} else {
 		schema.Format = "date-time"
 	}
+	schema.Enum = jsonSchemaEnum(s.Enum)
 	return schema
 }
 
@@ -278,6 +287,7 @@ func (c *JSONSchemaConverter) VisitDateTimeOnlyShape(s *DateTimeOnlyShape) *JSON
 	schema := c.makeSchemaFromBaseShape(s.Base())
 	schema.Type = TypeString
 	schema.Pattern = "^[0-9]{4}-(?:0[0-9]|1[0-2])-(?:[0-2][0-9]|3[01])T(?:[01][0-9]|2[0-3]):[0-5][0-9]:[0-5][0-9]$"
+	schema.Enum = jsonSchemaEnum(s.Enum)
 	return schema
 }
 
@@ -285,6 +295,7 @@ func (c *JSONSchemaConverter) VisitDateOnlyShape(s *DateOnlyShape) *JSONSchema {
 	schema := c.makeSchemaFromBaseShape(s.Base())
 	schema.Type = TypeString
 	schema.Format = "date"
+	schema.Enum = jsonSchemaEnum(s.Enum)
 	return schema
 }
 
@@ -292,6 +303,7 @@ func (c *JSONSchemaConverter) VisitTimeOnlyShape(s *TimeOnlyShape) *JSONSchema {
 	schema := c.makeSchemaFromBaseShape(s.Base())
 	schema.Type = TypeString
 	schema.Format = "time"
+	schema.Enum = jsonSchemaEnum(s.Enum)
 	return schema
 }
 
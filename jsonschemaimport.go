@@ -0,0 +1,544 @@
+package raml
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type parseOptWithImportJSONSchema struct{}
+
+func (parseOptWithImportJSONSchema) Apply(opt *parserOptions) {
+	opt.importJSONSchema = true
+}
+
+// OptWithImportJSONSchema converts JSON schemas of types into native shapes, so inheritance, validation and
+// converters work with them the same way as with types declared in RAML. Schemas with keywords that have no RAML
+// equivalent (e.g. not, if, allOf of several schemas or recursive references) are kept as JSON shapes and
+// reported by RAML.Warnings.
+func OptWithImportJSONSchema() ParseOpt {
+	return parseOptWithImportJSONSchema{}
+}
+
+// isJSONSchemaNode returns true if the type node contains an inline or included JSON schema.
+func isJSONSchemaNode(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == TagStr && strings.HasPrefix(node.Value, "{")
+}
+
+// importJSONSchemaType replaces the JSON schema of the type node with the equivalent RAML type declaration. Facets
+// of the declaration take precedence over keywords of the schema. The type node is kept if the schema cannot be
+// converted.
+func (r *RAML) importJSONSchemaType(
+	base *BaseShape, typeNode *yaml.Node, facets []*yaml.Node,
+) (*yaml.Node, []*yaml.Node, error) {
+	var schema *JSONSchema
+	// Numbers are kept as written, so integer values of enumerations and examples stay integers.
+	dec := json.NewDecoder(strings.NewReader(typeNode.Value))
+	dec.UseNumber()
+	if err := dec.Decode(&schema); err != nil || schema == nil {
+		// Invalid schemas are reported by MakeJSONShape.
+		return typeNode, facets, nil
+	}
+	im := &jsonSchemaImporter{root: schema, line: typeNode.Line, column: typeNode.Column,
+		refs: make(map[string]struct{})}
+	node, err := im.convert(schema)
+	if err != nil {
+		r.addWarning(fmt.Sprintf("JSON schema is not imported: %s", err.Error()), base.Location(),
+			WithNodePosition(typeNode))
+		return typeNode, facets, nil
+	}
+	if node.Kind == yaml.ScalarNode {
+		return node, facets, nil
+	}
+	declared := make(map[string]struct{}, len(facets)/2)
+	for i := 0; i < len(facets); i += 2 {
+		declared[facets[i].Value] = struct{}{}
+	}
+	content := make([]*yaml.Node, 0, len(node.Content))
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if _, ok := declared[key]; ok {
+			continue
+		}
+		switch {
+		case key == "displayName" && base.DisplayName != nil,
+			key == "description" && base.Description != nil,
+			key == "default" && base.Default != nil,
+			(key == "example" || key == "examples") && (base.Example != nil || base.Examples != nil):
+			continue
+		}
+		content = append(content, node.Content[i], node.Content[i+1])
+	}
+	node.Content = content
+	importedTypeNode, importedFacets, err := base.decode(node)
+	if err != nil {
+		return nil, nil, StacktraceNewWrapped("decode imported JSON schema", err, base.Location(),
+			WithNodePosition(typeNode))
+	}
+	return importedTypeNode, append(importedFacets, facets...), nil
+}
+
+// jsonSchemaImporter converts JSON schemas into RAML type declarations. Nodes of declarations get the position
+// of the schema, so issues found in imported types point to it.
+type jsonSchemaImporter struct {
+	root   *JSONSchema
+	line   int
+	column int
+	// refs contains references that are being converted to detect recursion.
+	refs map[string]struct{}
+	// typeName returns the name of the declared type that the reference refers to. If it is set, references are
+	// converted into type names instead of the declarations of the definitions, and allOf of several schemas into
+	// multiple inheritance.
+	typeName func(ref string) (string, bool)
+}
+
+func (im *jsonSchemaImporter) scalar(value string, tag string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value, Line: im.line, Column: im.column}
+}
+
+func (im *jsonSchemaImporter) str(value string) *yaml.Node {
+	return im.scalar(value, TagStr)
+}
+
+func (im *jsonSchemaImporter) mapping() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: im.line, Column: im.column}
+}
+
+func (im *jsonSchemaImporter) value(v any) (*yaml.Node, error) {
+	n := &yaml.Node{}
+	if err := n.Encode(jsonNumbersToValues(v)); err != nil {
+		return nil, fmt.Errorf("encode value: %w", err)
+	}
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		n.Line, n.Column = im.line, im.column
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(n)
+	return n, nil
+}
+
+// jsonNumbersToValues replaces numbers in the decoded JSON value with integers if possible or floats.
+func jsonNumbersToValues(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = jsonNumbersToValues(item)
+		}
+		return result
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for k, item := range v {
+			result[k] = jsonNumbersToValues(item)
+		}
+		return result
+	}
+	return v
+}
+
+func (im *jsonSchemaImporter) number(v json.Number) *yaml.Node {
+	if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+		return im.scalar(string(v), "!!int")
+	}
+	return im.scalar(string(v), "!!float")
+}
+
+func setNode(m *yaml.Node, key string, value *yaml.Node) {
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: TagStr, Value: key,
+		Line: value.Line, Column: value.Column}, value)
+}
+
+var errUnsupportedKeyword = errors.New("unsupported keyword")
+
+// convert returns the type declaration of the schema. Schemas that declare only the type are converted into scalar
+// nodes, e.g. "string", so they can be members of union type expressions.
+func (im *jsonSchemaImporter) convert(s *JSONSchema) (*yaml.Node, error) {
+	if s.boolean != nil {
+		if *s.boolean {
+			return im.str(TypeAny), nil
+		}
+		return nil, fmt.Errorf("%w: false schema", errUnsupportedKeyword)
+	}
+	if s.Ref != "" {
+		if im.typeName != nil {
+			name, ok := im.typeName(s.Ref)
+			if !ok {
+				return nil, fmt.Errorf("unknown reference %q", s.Ref)
+			}
+			return im.str(name), nil
+		}
+		return im.convertRef(s.Ref)
+	}
+	switch {
+	case s.Not != nil:
+		return nil, fmt.Errorf("%w: not", errUnsupportedKeyword)
+	case s.If != nil || s.Then != nil || s.Else != nil:
+		return nil, fmt.Errorf("%w: if", errUnsupportedKeyword)
+	case s.PropertyNames != nil:
+		return nil, fmt.Errorf("%w: propertyNames", errUnsupportedKeyword)
+	case s.MinContains != nil || s.MaxContains != nil:
+		return nil, fmt.Errorf("%w: minContains and maxContains", errUnsupportedKeyword)
+	case len(s.AllOf) > 1 && im.typeName != nil:
+		return im.convertAllOf(s)
+	case len(s.AllOf) > 1:
+		return nil, fmt.Errorf("%w: allOf with several schemas", errUnsupportedKeyword)
+	case len(s.AllOf) == 1:
+		return im.convert(s.AllOf[0])
+	}
+
+	m := im.mapping()
+	typ, err := im.typeOf(s)
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case TypeUnion:
+		members := s.AnyOf
+		if len(members) == 0 {
+			members = s.OneOf
+		}
+		names := make([]string, len(members))
+		for i, member := range members {
+			n, errConvert := im.convert(member)
+			if errConvert != nil {
+				return nil, fmt.Errorf("anyOf[%d]: %w", i, errConvert)
+			}
+			if n.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("%w: anyOf and oneOf with members that are not plain types",
+					errUnsupportedKeyword)
+			}
+			names[i] = n.Value
+		}
+		setNode(m, "type", im.str(strings.Join(names, " | ")))
+	case TypeObject:
+		setNode(m, "type", im.str(typ))
+		if err = im.convertObject(s, m); err != nil {
+			return nil, err
+		}
+	case TypeArray:
+		setNode(m, "type", im.str(typ))
+		if s.Items != nil {
+			items, errConvert := im.convert(s.Items)
+			if errConvert != nil {
+				return nil, fmt.Errorf("items: %w", errConvert)
+			}
+			setNode(m, "items", items)
+		}
+		im.setUint(m, "minItems", s.MinItems)
+		im.setUint(m, "maxItems", s.MaxItems)
+		if s.UniqueItems != nil {
+			setNode(m, "uniqueItems", im.scalar(strconv.FormatBool(*s.UniqueItems), "!!bool"))
+		}
+	case TypeString:
+		setNode(m, "type", im.str(typ))
+		im.setUint(m, "minLength", s.MinLength)
+		im.setUint(m, "maxLength", s.MaxLength)
+		if s.Pattern != "" {
+			setNode(m, "pattern", im.str(s.Pattern))
+		}
+	case TypeInteger, TypeNumber:
+		setNode(m, "type", im.str(typ))
+		if err = im.convertNumber(s, typ, m); err != nil {
+			return nil, err
+		}
+	default:
+		setNode(m, "type", im.str(typ))
+	}
+	if err = im.convertCommon(s, m); err != nil {
+		return nil, err
+	}
+	if len(m.Content) == 2 {
+		return m.Content[1], nil
+	}
+	return m, nil
+}
+
+// typeOf returns the RAML type of the schema.
+func (im *jsonSchemaImporter) typeOf(s *JSONSchema) (string, error) {
+	if len(s.AnyOf) > 0 || len(s.OneOf) > 0 {
+		if s.Type != "" || (len(s.AnyOf) > 0 && len(s.OneOf) > 0) {
+			return "", fmt.Errorf("%w: anyOf and oneOf combined with other types", errUnsupportedKeyword)
+		}
+		return TypeUnion, nil
+	}
+	switch s.Type {
+	case "object":
+		return TypeObject, nil
+	case "array":
+		return TypeArray, nil
+	case "boolean":
+		return TypeBoolean, nil
+	case "null":
+		return TypeNil, nil
+	case "integer":
+		return TypeInteger, nil
+	case "number":
+		return TypeNumber, nil
+	case "string":
+		// Length and pattern facets are not allowed for date and time types.
+		if s.MinLength != nil || s.MaxLength != nil || s.Pattern != "" {
+			return TypeString, nil
+		}
+		switch s.Format {
+		case "date-time":
+			return TypeDatetime, nil
+		case "date":
+			return TypeDateOnly, nil
+		}
+		return TypeString, nil
+	case "":
+	default:
+		return "", fmt.Errorf("%w: type %q", errUnsupportedKeyword, s.Type)
+	}
+	switch {
+	case s.Properties != nil, s.PatternProperties != nil, s.AdditionalProperties != nil, len(s.Required) > 0,
+		s.MinProperties != nil, s.MaxProperties != nil:
+		return TypeObject, nil
+	case s.Items != nil, s.MinItems != nil, s.MaxItems != nil, s.UniqueItems != nil:
+		return TypeArray, nil
+	case s.MinLength != nil, s.MaxLength != nil, s.Pattern != "":
+		return TypeString, nil
+	case s.Minimum != "", s.Maximum != "", s.MultipleOf != "", s.ExclusiveMinimum != "", s.ExclusiveMaximum != "":
+		return TypeNumber, nil
+	case len(s.Enum) > 0 || s.Const != nil:
+		return "", fmt.Errorf("%w: enum without type", errUnsupportedKeyword)
+	}
+	return TypeAny, nil
+}
+
+func (im *jsonSchemaImporter) convertRef(ref string) (*yaml.Node, error) {
+	name, ok := strings.CutPrefix(ref, "#/definitions/")
+	if !ok {
+		return nil, fmt.Errorf("%w: reference %q", errUnsupportedKeyword, ref)
+	}
+	def, ok := im.root.Definitions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown reference %q", ref)
+	}
+	if _, ok = im.refs[ref]; ok {
+		return nil, fmt.Errorf("%w: recursive reference %q", errUnsupportedKeyword, ref)
+	}
+	im.refs[ref] = struct{}{}
+	defer delete(im.refs, ref)
+	n, err := im.convert(def)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ref, err)
+	}
+	return n, nil
+}
+
+// convertAllOf converts allOf of references to declared types and schemas that extend them into a type that
+// inherits from the declared types, e.g. {"allOf": [{"$ref": "#/definitions/Pet"}, {"properties": {...}}]}.
+func (im *jsonSchemaImporter) convertAllOf(s *JSONSchema) (*yaml.Node, error) {
+	parents := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: im.line, Column: im.column}
+	rest := *s
+	rest.AllOf = nil
+	extensions := []*JSONSchema{&rest}
+	for i, member := range s.AllOf {
+		if member.Ref == "" {
+			extensions = append(extensions, member)
+			continue
+		}
+		n, err := im.convert(member)
+		if err != nil {
+			return nil, fmt.Errorf("allOf[%d]: %w", i, err)
+		}
+		parents.Content = append(parents.Content, n)
+	}
+	typ := parents
+	if len(parents.Content) == 1 {
+		typ = parents.Content[0]
+	}
+	m := im.mapping()
+	setNode(m, "type", typ)
+	for _, extension := range extensions {
+		n, err := im.convert(extension)
+		if err != nil {
+			return nil, fmt.Errorf("allOf: %w", err)
+		}
+		if n.Kind == yaml.ScalarNode {
+			n = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{im.str("type"), n}}
+		}
+		for i := 0; i < len(n.Content); i += 2 {
+			key, value := n.Content[i].Value, n.Content[i+1]
+			switch {
+			case key == "type":
+				if value.Value != TypeAny && value.Value != TypeObject {
+					return nil, fmt.Errorf("%w: allOf of references and type %q", errUnsupportedKeyword, value.Value)
+				}
+			case mappingIndex(m, key) >= 0:
+				return nil, fmt.Errorf("%w: allOf with several schemas that declare %s", errUnsupportedKeyword, key)
+			default:
+				m.Content = append(m.Content, n.Content[i], value)
+			}
+		}
+	}
+	return m, nil
+}
+
+func (im *jsonSchemaImporter) convertObject(s *JSONSchema, m *yaml.Node) error {
+	props := im.mapping()
+	required := make(map[string]struct{}, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = struct{}{}
+	}
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		name := pair.Key
+		// Such names would be read as optional or pattern properties.
+		if strings.HasSuffix(name, "?") || (len(name) > 1 && strings.HasPrefix(name, "/") &&
+			strings.HasSuffix(name, "/")) {
+			return fmt.Errorf("%w: property name %q", errUnsupportedKeyword, name)
+		}
+		n, err := im.convert(pair.Value)
+		if err != nil {
+			return fmt.Errorf("properties.%s: %w", name, err)
+		}
+		if n.Kind == yaml.ScalarNode {
+			t := im.mapping()
+			setNode(t, "type", n)
+			n = t
+		}
+		_, ok := required[name]
+		setNode(n, "required", im.scalar(strconv.FormatBool(ok), "!!bool"))
+		setNode(props, name, n)
+		delete(required, name)
+	}
+	for _, name := range s.Required {
+		if _, ok := required[name]; ok {
+			t := im.mapping()
+			setNode(t, "type", im.str(TypeAny))
+			setNode(t, "required", im.scalar("true", "!!bool"))
+			setNode(props, name, t)
+			delete(required, name)
+		}
+	}
+	for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		n, err := im.convert(pair.Value)
+		if err != nil {
+			return fmt.Errorf("patternProperties.%s: %w", pair.Key, err)
+		}
+		setNode(props, "/"+pair.Key+"/", n)
+	}
+	if len(props.Content) > 0 {
+		setNode(m, "properties", props)
+	}
+	if s.AdditionalProperties != nil {
+		setNode(m, "additionalProperties", im.scalar(strconv.FormatBool(*s.AdditionalProperties), "!!bool"))
+	}
+	im.setUint(m, "minProperties", s.MinProperties)
+	im.setUint(m, "maxProperties", s.MaxProperties)
+	return nil
+}
+
+func (im *jsonSchemaImporter) convertNumber(s *JSONSchema, typ string, m *yaml.Node) error {
+	minimum, maximum := s.Minimum, s.Maximum
+	// Exclusive bounds of integers are converted into inclusive ones, RAML has no exclusive bounds.
+	for _, b := range []struct {
+		value json.Number
+		bound *json.Number
+		delta int64
+	}{{s.ExclusiveMinimum, &minimum, 1}, {s.ExclusiveMaximum, &maximum, -1}} {
+		if b.value == "" {
+			continue
+		}
+		v, ok := new(big.Int).SetString(string(b.value), 10)
+		if typ != TypeInteger || !ok {
+			return fmt.Errorf("%w: exclusiveMinimum and exclusiveMaximum of numbers", errUnsupportedKeyword)
+		}
+		v.Add(v, big.NewInt(b.delta))
+		if *b.bound != "" {
+			return fmt.Errorf("%w: inclusive and exclusive bounds combined", errUnsupportedKeyword)
+		}
+		*b.bound = json.Number(v.String())
+	}
+	if minimum != "" {
+		setNode(m, "minimum", im.number(minimum))
+	}
+	if maximum != "" {
+		setNode(m, "maximum", im.number(maximum))
+	}
+	if s.MultipleOf != "" {
+		setNode(m, "multipleOf", im.number(s.MultipleOf))
+	}
+	switch s.Format {
+	case "int32", "int64":
+		if typ == TypeInteger {
+			setNode(m, "format", im.str(s.Format))
+		}
+	case "float", "double":
+		if typ == TypeNumber {
+			setNode(m, "format", im.str(s.Format))
+		}
+	}
+	return nil
+}
+
+// convertCommon converts annotation keywords and enumerations.
+func (im *jsonSchemaImporter) convertCommon(s *JSONSchema, m *yaml.Node) error {
+	if s.Title != "" {
+		setNode(m, "displayName", im.str(s.Title))
+	}
+	if s.Description != "" {
+		setNode(m, "description", im.str(s.Description))
+	}
+	enum := s.Enum
+	if s.Const != nil {
+		enum = []any{s.Const}
+	}
+	if len(enum) > 0 {
+		n, err := im.value(enum)
+		if err != nil {
+			return fmt.Errorf("enum: %w", err)
+		}
+		setNode(m, "enum", n)
+	}
+	if s.Default != nil {
+		n, err := im.value(s.Default)
+		if err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+		setNode(m, "default", n)
+	}
+	switch len(s.Examples) {
+	case 0:
+	case 1:
+		n, err := im.value(s.Examples[0])
+		if err != nil {
+			return fmt.Errorf("examples: %w", err)
+		}
+		setNode(m, "example", n)
+	default:
+		examples := im.mapping()
+		for i, e := range s.Examples {
+			n, err := im.value(e)
+			if err != nil {
+				return fmt.Errorf("examples: %w", err)
+			}
+			setNode(examples, "example"+strconv.Itoa(i+1), n)
+		}
+		setNode(m, "examples", examples)
+	}
+	return nil
+}
+
+func (im *jsonSchemaImporter) setUint(m *yaml.Node, key string, v *uint64) {
+	if v != nil {
+		setNode(m, key, im.scalar(strconv.FormatUint(*v, 10), "!!int"))
+	}
+}
@@ -0,0 +1,81 @@
+package raml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptWithImportJSONSchema(t *testing.T) {
+	dir := t.TempDir()
+	pet := `{
+  "$schema": "http://json-schema.org/draft-07/schema",
+  "title": "Pet",
+  "type": "object",
+  "required": ["name", "kind"],
+  "properties": {
+    "name": {"type": "string", "minLength": 1},
+    "kind": {"enum": ["cat", "dog"], "type": "string"},
+    "age": {"type": "integer", "exclusiveMinimum": 0, "maximum": 30},
+    "born": {"type": "string", "format": "date"},
+    "tags": {"type": "array", "items": {"$ref": "#/definitions/tag"}, "uniqueItems": true},
+    "owner": {"anyOf": [{"type": "string"}, {"type": "null"}]}
+  },
+  "additionalProperties": false,
+  "examples": [{"name": "Rex", "kind": "dog", "age": 3}],
+  "definitions": {
+    "tag": {"type": "string", "pattern": "^[a-z]+$"}
+  }
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pet.json"), []byte(pet), 0o600))
+	content := `#%RAML 1.0 Library
+types:
+  Pet: !include pet.json
+  Cat:
+    type: Pet
+    properties:
+      lives: integer
+  Node:
+    type: |
+      {"type": "object", "properties": {"next": {"$ref": "#"}}}
+`
+	rml, err := ParseFromString(content, "library.raml", dir, OptWithImportJSONSchema(), OptWithUnwrap(),
+		OptWithValidate())
+	require.NoError(t, err)
+
+	cat, err := rml.LookupType("Cat", "")
+	require.NoError(t, err)
+	obj, ok := cat.Shape.(*ObjectShape)
+	require.True(t, ok)
+	age, ok := obj.Properties.Get("age")
+	require.True(t, ok)
+	require.False(t, age.Required)
+	require.Equal(t, "1", age.Shape.Shape.(*IntegerShape).Minimum.String())
+	born, _ := obj.Properties.Get("born")
+	require.IsType(t, &DateOnlyShape{}, born.Shape.Shape)
+	require.False(t, *obj.AdditionalProperties)
+
+	require.NoError(t, cat.Validate(map[string]any{"name": "Tom", "kind": "cat", "lives": 9,
+		"tags": []any{"grey"}, "owner": nil}))
+	require.ErrorContains(t, cat.Validate(map[string]any{"name": "Tom", "kind": "cow", "lives": 9}), "must be one of")
+	require.Error(t, cat.Validate(map[string]any{"name": "Tom", "kind": "cat", "lives": 9, "age": 0}))
+	require.Error(t, cat.Validate(map[string]any{"name": "Tom", "kind": "cat", "lives": 9,
+		"tags": []any{"a", "a"}}))
+
+	node, err := rml.LookupType("Node", "")
+	require.NoError(t, err)
+	require.IsType(t, &JSONShape{}, node.Shape)
+	var messages []string
+	for _, d := range rml.Diagnostics() {
+		messages = append(messages, d.Message)
+	}
+	require.Contains(t, messages, "JSON schema is not imported: properties.next: unsupported keyword: reference \"#\"")
+
+	rml, err = ParseFromString(content, "library.raml", dir)
+	require.NoError(t, err)
+	pet2, err := rml.LookupType("Pet", "")
+	require.NoError(t, err)
+	require.IsType(t, &JSONShape{}, pet2.Link.Shape.Shape)
+}
@@ -0,0 +1,91 @@
+package raml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// JUnit XML structures as understood by common CI test reporters.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes diagnostics as a JUnit XML report for CI test reporters. Every report is a test suite and every
+// diagnostic is a failed test case named after its type and position. A report without diagnostics contains one
+// passed test case, so checked files are listed even if they have no issues.
+func WriteJUnit(w io.Writer, reports []DiagnosticReport) error {
+	suites := junitTestSuites{Name: "raml"}
+	for _, report := range reports {
+		suite := junitTestSuite{Name: report.Path}
+		for _, d := range report.Diagnostics {
+			location := d.Location
+			if location == "" {
+				location = report.Path
+			}
+			if d.HasPosition() {
+				location += ":" + strconv.Itoa(d.Line) + ":" + strconv.Itoa(d.Column)
+			}
+			text := strings.Join(d.Trace, "\n")
+			if text == "" {
+				text = d.String()
+			}
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      fmt.Sprintf("%s %s", d.Type, location),
+				ClassName: report.Path,
+				Failure: &junitFailure{
+					Message: d.Message,
+					Type:    string(d.Severity),
+					Text:    text,
+				},
+			})
+			suite.Failures++
+		}
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{Name: report.Path, ClassName: report.Path})
+		}
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("write junit header: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return fmt.Errorf("encode junit: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("write junit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package raml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/acronis/go-stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	reports := []DiagnosticReport{
+		{Path: "a.raml", Diagnostics: []Diagnostic{
+			{Severity: stacktrace.SeverityError, Type: "parsing", Message: "boom", Location: "a.raml",
+				Position: stacktrace.Position{Line: 2, Column: 3}, Trace: []string{"parse", "boom"}},
+		}},
+		{Path: "b.raml"},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, WriteJUnit(&buf, reports))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="raml" tests="2" failures="1">
+  <testsuite name="a.raml" tests="1" failures="1">
+    <testcase name="parsing a.raml:2:3" classname="a.raml">
+      <failure message="boom" type="error">parse&#xA;boom</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="b.raml" tests="1" failures="0">
+    <testcase name="b.raml" classname="b.raml"></testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
@@ -0,0 +1,400 @@
+package raml
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// LibraryVersions designates annotation types that version libraries and constrain versions of used libraries.
+// Annotations are matched by names of their annotation types, so the types may be declared in a shared library and
+// applied with its alias, e.g. "(meta.version): 2.1.0".
+type LibraryVersions struct {
+	// Version is the name of the annotation type whose string value on a library is its semantic version,
+	// e.g. "2.1.0".
+	Version string
+	// Requires is the name of the annotation type whose value on a library or API maps aliases of used libraries to
+	// version constraints, e.g. {common: ">=2.0.0 <3.0.0"}. Constraints are comparisons (=, !=, >, >=, <, <=),
+	// tilde (~1.2) and caret (^1.2) ranges and exact versions, separated by spaces or commas and alternated with "||".
+	Requires string
+}
+
+type parseOptWithLibraryVersions struct {
+	versions LibraryVersions
+}
+
+func (o parseOptWithLibraryVersions) Apply(opt *parserOptions) {
+	opt.libraryVersions = o.versions
+}
+
+// OptWithLibraryVersions checks versions of used libraries against constraints of the libraries that use them when
+// the model is linked. Incompatible versions, constraints on libraries without versions and constraints on unknown
+// aliases fail linking.
+func OptWithLibraryVersions(versions LibraryVersions) ParseOpt {
+	return parseOptWithLibraryVersions{versions: versions}
+}
+
+// LibraryVersion returns the version of the library at the location declared by the annotation designated with
+// OptWithLibraryVersions, false if the library does not declare a version. The model must be linked.
+func (r *RAML) LibraryVersion(location string) (string, bool) {
+	lib := r.libraryAt(location)
+	if lib == nil {
+		return "", false
+	}
+	de := r.libraryAnnotation(lib, r.libraryVersions.Version)
+	if de == nil {
+		return "", false
+	}
+	v, ok := de.Extension.Value.(string)
+	return v, ok
+}
+
+func (r *RAML) libraryAt(location string) *Library {
+	lib, _ := r.fragmentsCache[location].(*Library)
+	return lib
+}
+
+// libraryAnnotation returns the annotation of the library whose annotation type is named typeName.
+func (r *RAML) libraryAnnotation(lib *Library, typeName string) *DomainExtension {
+	if typeName == "" {
+		return nil
+	}
+	for pair := lib.CustomDomainProperties.Oldest(); pair != nil; pair = pair.Next() {
+		de := pair.Value
+		if de.DefinedBy != nil && de.DefinedBy.Name == typeName {
+			return de
+		}
+	}
+	return nil
+}
+
+// checkLibraryVersions reports versions of used libraries that do not satisfy constraints of the libraries that
+// use them.
+func (r *RAML) checkLibraryVersions() error {
+	lv := r.libraryVersions
+	if lv.Version == "" && lv.Requires == "" {
+		return nil
+	}
+	locations := make([]string, 0, len(r.fragmentsCache))
+	for loc, frag := range r.fragmentsCache {
+		if _, ok := frag.(*Library); ok {
+			locations = append(locations, loc)
+		}
+	}
+	slices.Sort(locations)
+
+	var st *stacktrace.StackTrace
+	appendErr := func(se *stacktrace.StackTrace) {
+		if st == nil {
+			st = se
+		} else {
+			st = st.Append(se)
+		}
+	}
+	versions := make(map[string]semVersion, len(locations))
+	for _, loc := range locations {
+		de := r.libraryAnnotation(r.libraryAt(loc), lv.Version)
+		if de == nil {
+			continue
+		}
+		s, ok := de.Extension.Value.(string)
+		if !ok {
+			appendErr(stacktrace.New("library version must be a string", loc, stacktrace.WithPosition(&de.Position),
+				stacktrace.WithType(stacktrace.TypeResolving)))
+			continue
+		}
+		v, err := parseSemVersion(s)
+		if err != nil {
+			appendErr(StacktraceNewWrapped("invalid library version", err, loc, stacktrace.WithPosition(&de.Position),
+				stacktrace.WithType(stacktrace.TypeResolving)))
+			continue
+		}
+		versions[loc] = v
+	}
+	for _, loc := range locations {
+		if se := r.checkRequiredVersions(r.libraryAt(loc), versions); se != nil {
+			appendErr(se)
+		}
+	}
+	if st != nil {
+		return st
+	}
+	return nil
+}
+
+// checkRequiredVersions checks versions of libraries used by the library against its constraints.
+func (r *RAML) checkRequiredVersions(lib *Library, versions map[string]semVersion) *stacktrace.StackTrace {
+	de := r.libraryAnnotation(lib, r.libraryVersions.Requires)
+	if de == nil {
+		return nil
+	}
+	opts := func(alias string) []stacktrace.Option {
+		return []stacktrace.Option{
+			stacktrace.WithPosition(&de.Position), stacktrace.WithType(stacktrace.TypeResolving),
+			stacktrace.WithInfo("library", alias),
+		}
+	}
+	required, ok := de.Extension.Value.(map[string]any)
+	if !ok {
+		return stacktrace.New("library requirements must be a map of aliases to constraints", lib.Location,
+			stacktrace.WithPosition(&de.Position), stacktrace.WithType(stacktrace.TypeResolving))
+	}
+	aliases := make([]string, 0, len(required))
+	for alias := range required {
+		aliases = append(aliases, alias)
+	}
+	slices.Sort(aliases)
+
+	var st *stacktrace.StackTrace
+	for _, alias := range aliases {
+		se := r.checkRequiredVersion(lib, alias, required[alias], versions, opts(alias))
+		if se == nil {
+			continue
+		}
+		if st == nil {
+			st = se
+		} else {
+			st = st.Append(se)
+		}
+	}
+	return st
+}
+
+func (r *RAML) checkRequiredVersion(
+	lib *Library, alias string, value any, versions map[string]semVersion, opts []stacktrace.Option,
+) *stacktrace.StackTrace {
+	s, ok := value.(string)
+	if !ok {
+		return stacktrace.New("version constraint must be a string", lib.Location, opts...)
+	}
+	c, err := parseVersionConstraint(s)
+	if err != nil {
+		return StacktraceNewWrapped("invalid version constraint", err, lib.Location, opts...)
+	}
+	link, ok := lib.Uses.Get(alias)
+	if !ok || link.Link == nil {
+		return stacktrace.New("version constraint of unknown library", lib.Location, opts...)
+	}
+	v, ok := versions[link.Link.Location]
+	if !ok {
+		return stacktrace.New("used library does not declare a version", lib.Location,
+			append(opts, stacktrace.WithInfo("location", link.Link.Location))...)
+	}
+	if !c.matches(v) {
+		return stacktrace.New("used library version is incompatible", lib.Location,
+			append(opts, stacktrace.WithInfo("version", v.String()), stacktrace.WithInfo("constraint", s),
+				stacktrace.WithInfo("location", link.Link.Location))...)
+	}
+	return nil
+}
+
+// semVersion is a semantic version. Build metadata is ignored.
+type semVersion struct {
+	major, minor, patch int
+	pre                 []string
+}
+
+func parseSemVersion(s string) (semVersion, error) {
+	v, n, err := parsePartialVersion(s)
+	if err != nil {
+		return semVersion{}, err
+	}
+	if n != 3 {
+		return semVersion{}, fmt.Errorf("version %q must have major, minor and patch numbers", s)
+	}
+	return v, nil
+}
+
+// parsePartialVersion parses versions with omitted minor and patch numbers, e.g. "2" or "2.1", and returns the
+// number of numbers given.
+func parsePartialVersion(s string) (semVersion, int, error) {
+	var v semVersion
+	s = strings.TrimPrefix(s, "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, pre, hasPre := strings.Cut(s, "-")
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, 0, fmt.Errorf("version %q has too many numbers", s)
+	}
+	nums := []*int{&v.major, &v.minor, &v.patch}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, 0, fmt.Errorf("version %q has invalid number %q", s, p)
+		}
+		*nums[i] = n
+	}
+	if hasPre {
+		if pre == "" {
+			return v, 0, fmt.Errorf("version %q has empty pre-release", s)
+		}
+		v.pre = strings.Split(pre, ".")
+	}
+	return v, len(parts), nil
+}
+
+func (v semVersion) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+	if len(v.pre) > 0 {
+		s += "-" + strings.Join(v.pre, ".")
+	}
+	return s
+}
+
+// compare compares versions by the precedence of semantic versioning.
+func (v semVersion) compare(o semVersion) int {
+	for _, d := range [][2]int{{v.major, o.major}, {v.minor, o.minor}, {v.patch, o.patch}} {
+		if d[0] != d[1] {
+			if d[0] < d[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(v.pre) == 0 && len(o.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(o.pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.pre) && i < len(o.pre); i++ {
+		if c := comparePreRelease(v.pre[i], o.pre[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(v.pre), len(o.pre))
+}
+
+func comparePreRelease(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// versionConstraint is a disjunction of conjunctions of comparisons.
+type versionConstraint [][]versionComparison
+
+type versionComparison struct {
+	op      string
+	version semVersion
+}
+
+func parseVersionConstraint(s string) (versionConstraint, error) {
+	var c versionConstraint
+	for _, alt := range strings.Split(s, "||") {
+		var all []versionComparison
+		for _, term := range strings.FieldsFunc(alt, func(r rune) bool { return r == ' ' || r == ',' }) {
+			comparisons, err := parseVersionTerm(term)
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, comparisons...)
+		}
+		if len(all) == 0 {
+			return nil, fmt.Errorf("constraint %q has an empty alternative", s)
+		}
+		c = append(c, all)
+	}
+	return c, nil
+}
+
+// parseVersionTerm parses a comparison or expands a range into comparisons.
+func parseVersionTerm(term string) ([]versionComparison, error) {
+	op := strings.TrimRight(term, "0123456789.v-+abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	v, n, err := parsePartialVersion(term[len(op):])
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "=", "", "!=", ">", ">=", "<", "<=":
+		if n < 3 && (op == "" || op == "=") {
+			// Partial exact versions match all versions with the given numbers, e.g. "2.1" matches "2.1.5".
+			return []versionComparison{{op: ">=", version: v}, {op: "<", version: bumpVersion(v, n-1)}}, nil
+		}
+		if op == "" {
+			op = "="
+		}
+		return []versionComparison{{op: op, version: v}}, nil
+	case "~":
+		// ~1.2.3 allows patches and ~1 allows minors.
+		return []versionComparison{{op: ">=", version: v}, {op: "<", version: bumpVersion(v, min(n-1, 1))}}, nil
+	case "^":
+		// ^1.2.3 allows changes that do not modify the leftmost non-zero number.
+		i := 0
+		for i < n-1 && []int{v.major, v.minor, v.patch}[i] == 0 {
+			i++
+		}
+		return []versionComparison{{op: ">=", version: v}, {op: "<", version: bumpVersion(v, i)}}, nil
+	default:
+		return nil, fmt.Errorf("unknown version operator %q", op)
+	}
+}
+
+// bumpVersion increments the number at index i of the version and resets the following numbers.
+func bumpVersion(v semVersion, i int) semVersion {
+	switch i {
+	case 0:
+		return semVersion{major: v.major + 1}
+	case 1:
+		return semVersion{major: v.major, minor: v.minor + 1}
+	default:
+		return semVersion{major: v.major, minor: v.minor, patch: v.patch + 1}
+	}
+}
+
+func (c versionConstraint) matches(v semVersion) bool {
+	for _, all := range c {
+		matched := true
+		for _, cmp := range all {
+			if !cmp.matches(v) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (c versionComparison) matches(v semVersion) bool {
+	d := v.compare(c.version)
+	switch c.op {
+	case "=":
+		return d == 0
+	case "!=":
+		return d != 0
+	case ">":
+		return d > 0
+	case ">=":
+		return d >= 0
+	case "<":
+		return d < 0
+	default:
+		return d <= 0
+	}
+}
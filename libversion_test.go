@@ -0,0 +1,114 @@
+package raml
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptWithLibraryVersions(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, content string) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+		return p
+	}
+	writeFile("meta.raml", `#%RAML 1.0 Library
+annotationTypes:
+  version: string
+  requires: object
+`)
+	common := writeFile("common.raml", `#%RAML 1.0 Library
+usage: Types shared by all services.
+uses:
+  meta: meta.raml
+(meta.version): 2.1.0
+types:
+  Id: string
+`)
+	writeFile("unversioned.raml", "#%RAML 1.0 Library\ntypes:\n  Tag: string\n")
+	versions := LibraryVersions{Version: "version", Requires: "requires"}
+
+	tests := []struct {
+		name     string
+		requires string
+		wantErr  string
+	}{
+		{name: "range", requires: `common: ">=2.0.0 <3.0.0"`},
+		{name: "caret", requires: `common: ^2.1`},
+		{name: "partial", requires: `common: "2"`},
+		{name: "alternatives", requires: `common: ">=3, <4 || 2.1.0"`},
+		{name: "incompatible", requires: `common: ~2.0`, wantErr: "used library version is incompatible"},
+		{name: "prerelease", requires: `common: 2.1.0-beta`, wantErr: "used library version is incompatible"},
+		{name: "unknown alias", requires: `other: ^1`, wantErr: "version constraint of unknown library"},
+		{name: "unversioned", requires: `tags: ^1`, wantErr: "used library does not declare a version"},
+		{name: "invalid", requires: `common: "=>2"`, wantErr: "invalid version constraint"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := writeFile("library.raml", fmt.Sprintf(`#%%RAML 1.0 Library
+uses:
+  meta: meta.raml
+  common: common.raml
+  tags: unversioned.raml
+(meta.requires):
+  %s
+types:
+  Pet:
+    properties:
+      id: common.Id
+`, tt.requires))
+			rml, err := ParseFromPath(entry, OptWithLibraryVersions(versions))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			v, ok := rml.LibraryVersion(common)
+			require.True(t, ok)
+			require.Equal(t, "2.1.0", v)
+			_, ok = rml.LibraryVersion(entry)
+			require.False(t, ok)
+			require.Equal(t, "Types shared by all services.", rml.GetFragment(common).(*Library).Usage)
+		})
+	}
+}
+
+func Test_versionConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"1.2.3", "1.2.3", true},
+		{"1.2", "1.2.9", true},
+		{"1.2", "1.3.0", false},
+		{"!=1.2.3", "1.2.3", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1", "1.9.0", true},
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "2.0.0", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{">1.0.0-alpha", "1.0.0-beta", true},
+		{">1.0.0-alpha.1", "1.0.0-alpha", false},
+		{"<1.0.0", "1.0.0-rc.1", true},
+		{">=1.0.0 <2.0.0 || >=3.0.0", "2.5.0", false},
+		{">=1.0.0 <2.0.0 || >=3.0.0", "3.0.0", true},
+		{"v1.0.0", "1.0.0+build.5", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			c, err := parseVersionConstraint(tt.constraint)
+			require.NoError(t, err)
+			v, err := parseSemVersion(tt.version)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, c.matches(v))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package raml
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp/syntax"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// Limits bounds the resources that parsing of a specification may use. Zero fields mean no limit.
+// Limits are required to parse untrusted specifications, see SafeLimits.
+type Limits struct {
+	// MaxIncludeDepth is the maximum depth of fragments included or used from the entry point, which is at depth 0.
+	MaxIncludeDepth int
+	// MaxFragments is the maximum number of fragments read, including the entry point and included files.
+	MaxFragments int
+	// MaxTotalSize is the maximum total size in bytes of the fragments read.
+	MaxTotalSize int64
+	// MaxTypeExpressionLength is the maximum length of type expressions, e.g. "Pet[] | string".
+	MaxTypeExpressionLength int
+	// MaxPatternComplexity is the maximum number of instructions of compiled patterns. Patterns that RE2 cannot
+	// parse, e.g. with lookarounds, are measured by their length.
+	MaxPatternComplexity int
+}
+
+// SafeLimits returns limits that are large enough for real-world specifications, but prevent untrusted ones from
+// exhausting memory and CPU.
+func SafeLimits() Limits {
+	return Limits{
+		MaxIncludeDepth:         32,
+		MaxFragments:            10000,
+		MaxTotalSize:            256 << 20,
+		MaxTypeExpressionLength: 1024,
+		MaxPatternComplexity:    10000,
+	}
+}
+
+type parseOptWithLimits struct {
+	limits Limits
+}
+
+func (o parseOptWithLimits) Apply(opt *parserOptions) {
+	opt.limits = o.limits
+}
+
+// OptWithLimits fails parsing with an error when the specification exceeds the limits.
+func OptWithLimits(limits Limits) ParseOpt {
+	return parseOptWithLimits{limits: limits}
+}
+
+// resetLimits resets the usage of limits before the entry point is parsed.
+func (r *RAML) resetLimits() {
+	r.fragmentsRead = 0
+	r.bytesRead = 0
+	r.fragmentDepths = nil
+}
+
+// setFragmentDepth records the depth of the dependency the first time it is reached from the dependent.
+func (r *RAML) setFragmentDepth(dependent string, dependency string) {
+	if r.fragmentDepths == nil {
+		r.fragmentDepths = make(map[string]int)
+	}
+	if _, ok := r.fragmentDepths[dependency]; !ok {
+		r.fragmentDepths[dependency] = r.fragmentDepths[dependent] + 1
+	}
+}
+
+// admitFragment counts the fragment at the path and returns an error if it exceeds the include depth or
+// the number of fragments.
+func (r *RAML) admitFragment(path string) error {
+	r.fragmentsRead++
+	if limit := r.limits.MaxFragments; limit > 0 && r.fragmentsRead > limit {
+		return stacktrace.New(fmt.Sprintf("number of fragments exceeds limit of %d", limit), path,
+			stacktrace.WithType(stacktrace.TypeLoading))
+	}
+	if limit := r.limits.MaxIncludeDepth; limit > 0 && r.fragmentDepths[filepath.Clean(path)] > limit {
+		return stacktrace.New(fmt.Sprintf("include depth exceeds limit of %d", limit), path,
+			stacktrace.WithType(stacktrace.TypeLoading))
+	}
+	return nil
+}
+
+// limitedReader fails reading when the total size of fragments read by the RAML exceeds the limit.
+type limitedReader struct {
+	r    *RAML
+	rd   io.Reader
+	path string
+}
+
+func (l limitedReader) Read(p []byte) (int, error) {
+	n, err := l.rd.Read(p)
+	l.r.bytesRead += int64(n)
+	if limit := l.r.limits.MaxTotalSize; limit > 0 && l.r.bytesRead > limit {
+		return n, fmt.Errorf("total size of fragments exceeds limit of %d bytes at %s", limit, l.path)
+	}
+	return n, err
+}
+
+// sizeLimited returns the reader of the fragment at the path that counts bytes against the total size limit.
+func (r *RAML) sizeLimited(rd io.Reader, path string) io.Reader {
+	if r.limits.MaxTotalSize <= 0 {
+		return rd
+	}
+	return limitedReader{r: r, rd: rd, path: path}
+}
+
+// checkTypeExpression returns an error if the type expression is longer than the limit.
+func (r *RAML) checkTypeExpression(expr string) error {
+	if limit := r.limits.MaxTypeExpressionLength; limit > 0 && len(expr) > limit {
+		return fmt.Errorf("type expression length %d exceeds limit of %d", len(expr), limit)
+	}
+	return nil
+}
+
+// checkPatternComplexity returns an error if the pattern is more complex than the limit.
+func (r *RAML) checkPatternComplexity(pattern string) error {
+	limit := r.limits.MaxPatternComplexity
+	if limit <= 0 {
+		return nil
+	}
+	if complexity := patternComplexity(pattern); complexity > limit {
+		return fmt.Errorf("pattern complexity %d exceeds limit of %d", complexity, limit)
+	}
+	return nil
+}
+
+// patternComplexity returns the number of instructions of the compiled pattern or the length of the pattern if
+// RE2 cannot compile it.
+func patternComplexity(pattern string) int {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return len(pattern)
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return len(pattern)
+	}
+	return len(prog.Inst)
+}
@@ -0,0 +1,52 @@
+package raml
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptWithLimits(t *testing.T) {
+	dir, err := filepath.Abs("./fixtures/limits")
+	require.NoError(t, err)
+	content := `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Cat:
+    type: common.Pet
+    properties:
+      tag: !include tag.raml
+      code:
+        pattern: ^[a-z]{1,8}$
+  Owner: Cat | common.Named
+`
+	parse := func(limits Limits) error {
+		_, err := ParseFromString(content, "library.raml", dir, OptWithLimits(limits), OptWithUnwrap())
+		return err
+	}
+	require.NoError(t, parse(SafeLimits()))
+	require.NoError(t, parse(Limits{MaxIncludeDepth: 1, MaxFragments: 3}))
+
+	require.ErrorContains(t, parse(Limits{MaxFragments: 2}), "number of fragments exceeds limit of 2")
+	require.ErrorContains(t, parse(Limits{MaxTotalSize: 64}), "total size of fragments exceeds limit of 64 bytes")
+	require.ErrorContains(t, parse(Limits{MaxTypeExpressionLength: 10}),
+		"type expression length 18 exceeds limit of 10")
+	require.ErrorContains(t, parse(Limits{MaxPatternComplexity: 10}), "exceeds limit of 10")
+
+	nested := t.TempDir()
+	for i := range 3 {
+		lib := "#%RAML 1.0 Library\ntypes:\n  Id: string\n"
+		if i < 2 {
+			lib += fmt.Sprintf("uses:\n  next: lib%d.raml\n", i+1)
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(nested, fmt.Sprintf("lib%d.raml", i)), []byte(lib), 0o600))
+	}
+	_, err = ParseFromPath(filepath.Join(nested, "lib0.raml"), OptWithLimits(Limits{MaxIncludeDepth: 2}))
+	require.NoError(t, err)
+	_, err = ParseFromPath(filepath.Join(nested, "lib0.raml"), OptWithLimits(Limits{MaxIncludeDepth: 1}))
+	require.ErrorContains(t, err, "include depth exceeds limit of 1")
+}
@@ -0,0 +1,241 @@
+package raml
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/acronis/go-stacktrace"
+	"gopkg.in/yaml.v3"
+)
+
+// SeverityOff disables the lint rule.
+const SeverityOff stacktrace.Severity = "off"
+
+// LintRule checks the parsed model and reports issues with LintContext.Report.
+type LintRule interface {
+	// Name identifies the rule in diagnostics and in the configuration, e.g. "type-naming".
+	Name() string
+	Check(ctx *LintContext)
+}
+
+// LintContext gives the rule access to the model and collects issues reported by the rule.
+type LintContext struct {
+	RAML *RAML
+	// Graph contains declared types of the model with their qualified names.
+	Graph *TypeGraph
+
+	names       map[int64]string
+	rule        string
+	severity    stacktrace.Severity
+	diagnostics []Diagnostic
+}
+
+// Report records the issue found at the position of the file.
+func (c *LintContext) Report(message string, location string, position stacktrace.Position) {
+	c.diagnostics = append(c.diagnostics, Diagnostic{
+		Severity: c.severity,
+		Type:     stacktrace.Type(c.rule),
+		Message:  message,
+		Location: location,
+		Position: position,
+	})
+}
+
+// DeclaredName returns the qualified name of the declared type the shape is or refers to.
+func (c *LintContext) DeclaredName(s *BaseShape) (string, bool) {
+	for s != nil {
+		if name, ok := c.names[s.ID]; ok {
+			return name, true
+		}
+		switch {
+		case s.Alias != nil:
+			s = s.Alias
+		case s.Link != nil:
+			s = s.Link.Shape
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// LintConfig configures rules of the linter. Keys of Rules are names of rules.
+type LintConfig struct {
+	Rules map[string]LintRuleConfig `yaml:"rules"`
+}
+
+// LintRuleConfig configures the severity of the rule and options of built-in rules.
+type LintRuleConfig struct {
+	// Severity is one of "error", "warning", "critical" or "off". The severity of the rule is kept if empty.
+	Severity stacktrace.Severity `yaml:"severity"`
+	// Pattern is the regular expression of names for the type-naming and property-naming rules.
+	Pattern string `yaml:"pattern"`
+	// Types are names of types forbidden by the forbidden-types rule.
+	Types []string `yaml:"types"`
+}
+
+// ParseLintConfig reads the configuration from YAML, e.g.
+//
+//	rules:
+//	  type-naming:
+//	    severity: error
+//	    pattern: "^[A-Z][a-zA-Z0-9]*$"
+//	  forbidden-types:
+//	    types: [any]
+//	  description-required:
+//	    severity: off
+func ParseLintConfig(data []byte) (*LintConfig, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	cfg := &LintConfig{}
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("decode lint config: %w", err)
+	}
+	return cfg, nil
+}
+
+type LinterOpt interface {
+	Apply(*LinterOptions)
+}
+
+type LinterOptions struct {
+	rules  []LintRule
+	config *LintConfig
+}
+
+type optLintRule struct {
+	rule LintRule
+}
+
+func (o optLintRule) Apply(opts *LinterOptions) {
+	opts.rules = append(opts.rules, o.rule)
+}
+
+// WithLintRule adds the custom rule to the built-in rules. The rule replaces the built-in rule with the same name.
+func WithLintRule(rule LintRule) LinterOpt {
+	return optLintRule{rule: rule}
+}
+
+type optLintConfig struct {
+	config *LintConfig
+}
+
+func (o optLintConfig) Apply(opts *LinterOptions) {
+	opts.config = o.config
+}
+
+// WithLintConfig configures severities of rules and options of built-in rules.
+func WithLintConfig(config *LintConfig) LinterOpt {
+	return optLintConfig{config: config}
+}
+
+type lintRuleEntry struct {
+	rule     LintRule
+	severity stacktrace.Severity
+}
+
+// Linter checks the parsed model with the set of rules. All rules report warnings by default.
+type Linter struct {
+	rules []lintRuleEntry
+}
+
+// NewLinter creates the linter with built-in rules (see DefaultLintRules) and custom rules. Rules and their options
+// are configured with WithLintConfig.
+func NewLinter(opts ...LinterOpt) (*Linter, error) {
+	var options LinterOptions
+	for _, opt := range opts {
+		opt.Apply(&options)
+	}
+	rules := DefaultLintRules()
+	for _, rule := range options.rules {
+		i := slices.IndexFunc(rules, func(r LintRule) bool { return r.Name() == rule.Name() })
+		if i >= 0 {
+			rules[i] = rule
+		} else {
+			rules = append(rules, rule)
+		}
+	}
+
+	l := &Linter{rules: make([]lintRuleEntry, len(rules))}
+	for i, rule := range rules {
+		l.rules[i] = lintRuleEntry{rule: rule, severity: stacktrace.SeverityWarning}
+	}
+	if options.config == nil {
+		return l, nil
+	}
+	names := make([]string, 0, len(options.config.Rules))
+	for name := range options.config.Rules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if err := l.configure(name, options.config.Rules[name]); err != nil {
+			return nil, fmt.Errorf("configure rule %s: %w", name, err)
+		}
+	}
+	return l, nil
+}
+
+func (l *Linter) configure(name string, cfg LintRuleConfig) error {
+	i := slices.IndexFunc(l.rules, func(e lintRuleEntry) bool { return e.rule.Name() == name })
+	if i < 0 {
+		return fmt.Errorf("unknown rule")
+	}
+	switch cfg.Severity {
+	case "":
+	case stacktrace.SeverityError, stacktrace.SeverityWarning, stacktrace.SeverityCritical, SeverityOff:
+		l.rules[i].severity = cfg.Severity
+	default:
+		return fmt.Errorf("unknown severity %q", cfg.Severity)
+	}
+
+	switch name {
+	case LintRuleTypeNaming, LintRulePropertyNaming:
+		if cfg.Pattern == "" {
+			break
+		}
+		re, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return fmt.Errorf("compile pattern: %w", err)
+		}
+		if name == LintRuleTypeNaming {
+			l.rules[i].rule = NewTypeNamingRule(re)
+		} else {
+			l.rules[i].rule = NewPropertyNamingRule(re)
+		}
+	case LintRuleForbiddenTypes:
+		if cfg.Types != nil {
+			l.rules[i].rule = NewForbiddenTypesRule(cfg.Types...)
+		}
+	default:
+		if cfg.Pattern != "" || cfg.Types != nil {
+			return fmt.Errorf("rule has no options")
+		}
+	}
+	return nil
+}
+
+// Lint runs enabled rules and returns reported issues sorted by position. The model must be parsed without
+// OptWithUnwrap, since unwrapping removes references.
+func (l *Linter) Lint(r *RAML) []Diagnostic {
+	ctx := &LintContext{RAML: r, Graph: r.TypeGraph()}
+	ctx.names = make(map[int64]string, len(ctx.Graph.Nodes))
+	for _, n := range ctx.Graph.Nodes {
+		ctx.names[n.Shape.ID] = n.Name
+	}
+	for _, e := range l.rules {
+		if e.severity == SeverityOff {
+			continue
+		}
+		ctx.rule, ctx.severity = e.rule.Name(), e.severity
+		e.rule.Check(ctx)
+	}
+	slices.SortStableFunc(ctx.diagnostics, func(x, y Diagnostic) int {
+		return cmp.Or(cmp.Compare(x.Location, y.Location), cmp.Compare(x.Line, y.Line),
+			cmp.Compare(x.Column, y.Column))
+	})
+	return ctx.diagnostics
+}
@@ -0,0 +1,107 @@
+package raml
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/acronis/go-stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+type noDisplayNameRule struct{}
+
+func (noDisplayNameRule) Name() string {
+	return "display-name-required"
+}
+
+func (noDisplayNameRule) Check(ctx *LintContext) {
+	for _, n := range ctx.Graph.Nodes {
+		if n.Shape.DisplayName == nil {
+			ctx.Report(fmt.Sprintf("type %q has no display name", n.Name), n.Shape.Location(), n.Shape.Position)
+		}
+	}
+}
+
+func TestLinter_Lint(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Pet:
+    description: A pet.
+    example:
+      name: Rex
+    properties:
+      name:
+        description: The name.
+        type: string
+      Owner_Name:
+        description: The owner.
+        type: any
+  cat:
+    type: Pet
+  Tags:
+    description: Tags.
+    type: array
+    items: any
+    example: []
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+
+	lint := func(opts ...LinterOpt) []string {
+		l, err := NewLinter(opts...)
+		require.NoError(t, err)
+		var messages []string
+		for _, d := range l.Lint(rml) {
+			messages = append(messages, fmt.Sprintf("%d %s %s: %s", d.Line, d.Severity, d.Type, d.Message))
+		}
+		return messages
+	}
+
+	require.Equal(t, []string{
+		"12 warning property-naming: property name \"Owner_Name\" does not match \"^[a-z][a-zA-Z0-9_]*$\"",
+		"15 warning type-naming: type name \"cat\" does not match \"^[A-Z][a-zA-Z0-9]*$\"",
+		"15 warning description-required: type \"cat\" has no description",
+	}, lint())
+
+	cfg, err := ParseLintConfig([]byte(`rules:
+  type-naming:
+    pattern: "^[a-zA-Z]+$"
+  property-naming:
+    severity: off
+  description-required:
+    severity: error
+  forbidden-types:
+    types: [any]
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"12 warning forbidden-types: type \"Pet\" uses forbidden type \"any\" at properties.Owner_Name",
+		"15 error description-required: type \"cat\" has no description",
+		"19 warning forbidden-types: type \"Tags\" uses forbidden type \"any\" at items",
+	}, lint(WithLintConfig(cfg)))
+
+	messages := lint(WithLintRule(noDisplayNameRule{}), WithLintRule(NewForbiddenTypesRule("Pet")))
+	require.Contains(t, messages, "15 warning forbidden-types: type \"cat\" uses forbidden type \"Pet\"")
+	require.Contains(t, messages, "4 warning display-name-required: type \"Pet\" has no display name")
+}
+
+func TestNewLinter_Config(t *testing.T) {
+	for _, tc := range []struct {
+		config string
+		err    string
+	}{
+		{config: "rules:\n  unknown: {}\n", err: "configure rule unknown: unknown rule"},
+		{config: "rules:\n  type-naming:\n    severity: fatal\n", err: "unknown severity \"fatal\""},
+		{config: "rules:\n  type-naming:\n    pattern: \"[\"\n", err: "compile pattern"},
+		{config: "rules:\n  example-required:\n    types: [any]\n", err: "rule has no options"},
+	} {
+		cfg, err := ParseLintConfig([]byte(tc.config))
+		require.NoError(t, err)
+		_, err = NewLinter(WithLintConfig(cfg))
+		require.ErrorContains(t, err, tc.err)
+	}
+
+	_, err := ParseLintConfig([]byte("rules:\n  type-naming:\n    level: error\n"))
+	require.ErrorContains(t, err, "field level not found")
+	require.Equal(t, stacktrace.Severity("off"), SeverityOff)
+}
@@ -0,0 +1,305 @@
+package raml
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Names of built-in lint rules.
+const (
+	LintRuleUnusedDeclaration   = "unused-declaration"
+	LintRuleTypeNaming          = "type-naming"
+	LintRulePropertyNaming      = "property-naming"
+	LintRuleDescriptionRequired = "description-required"
+	LintRuleExampleRequired     = "example-required"
+	LintRuleForbiddenTypes      = "forbidden-types"
+	LintRuleNameCollision       = "name-collision"
+)
+
+var (
+	defaultTypeNamePattern     = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	defaultPropertyNamePattern = regexp.MustCompile(`^[a-z][a-zA-Z0-9_]*$`)
+)
+
+// DefaultLintRules returns built-in rules with default options. The forbidden-types rule forbids no types unless
+// it is configured.
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		NewUnusedDeclarationRule(),
+		NewTypeNamingRule(defaultTypeNamePattern),
+		NewPropertyNamingRule(defaultPropertyNamePattern),
+		NewDescriptionRule(),
+		NewExampleRule(),
+		NewForbiddenTypesRule(),
+		NewNameCollisionRule(),
+	}
+}
+
+type unusedDeclarationRule struct{}
+
+// NewUnusedDeclarationRule reports declarations returned by RAML.UnusedDeclarations.
+func NewUnusedDeclarationRule() LintRule {
+	return unusedDeclarationRule{}
+}
+
+func (unusedDeclarationRule) Name() string {
+	return LintRuleUnusedDeclaration
+}
+
+func (unusedDeclarationRule) Check(ctx *LintContext) {
+	for _, d := range ctx.RAML.UnusedDeclarations() {
+		ctx.Report(d.String(), d.Location, d.Position)
+	}
+}
+
+type typeNamingRule struct {
+	pattern *regexp.Regexp
+}
+
+// NewTypeNamingRule reports declared types with names that do not match the pattern. The default pattern requires
+// PascalCase names.
+func NewTypeNamingRule(pattern *regexp.Regexp) LintRule {
+	return typeNamingRule{pattern: pattern}
+}
+
+func (typeNamingRule) Name() string {
+	return LintRuleTypeNaming
+}
+
+func (r typeNamingRule) Check(ctx *LintContext) {
+	for _, n := range ctx.Graph.Nodes {
+		name := n.Name[strings.LastIndex(n.Name, ".")+1:]
+		if !r.pattern.MatchString(name) {
+			ctx.Report(fmt.Sprintf("type name %q does not match %q", name, r.pattern), n.Shape.Location(),
+				n.Shape.Position)
+		}
+	}
+}
+
+type propertyNamingRule struct {
+	pattern *regexp.Regexp
+}
+
+// NewPropertyNamingRule reports properties of declared and inline object types with names that do not match
+// the pattern. The default pattern requires camelCase or snake_case names.
+func NewPropertyNamingRule(pattern *regexp.Regexp) LintRule {
+	return propertyNamingRule{pattern: pattern}
+}
+
+func (propertyNamingRule) Name() string {
+	return LintRulePropertyNaming
+}
+
+func (r propertyNamingRule) Check(ctx *LintContext) {
+	for _, n := range ctx.Graph.Nodes {
+		walkInlineShapes(n.Shape, "", func(s *BaseShape, _ string) {
+			obj, ok := s.Shape.(*ObjectShape)
+			if !ok || s.Alias != nil || s.Link != nil {
+				return
+			}
+			for pair := obj.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				if !r.pattern.MatchString(pair.Value.Name) {
+					ctx.Report(fmt.Sprintf("property name %q does not match %q", pair.Value.Name, r.pattern),
+						pair.Value.Shape.Location(), pair.Value.Shape.Position)
+				}
+			}
+		})
+	}
+}
+
+type descriptionRule struct{}
+
+// NewDescriptionRule reports declared types and their properties without descriptions.
+func NewDescriptionRule() LintRule {
+	return descriptionRule{}
+}
+
+func (descriptionRule) Name() string {
+	return LintRuleDescriptionRequired
+}
+
+func (descriptionRule) Check(ctx *LintContext) {
+	for _, n := range ctx.Graph.Nodes {
+		if n.Shape.Description == nil {
+			ctx.Report(fmt.Sprintf("type %q has no description", n.Name), n.Shape.Location(), n.Shape.Position)
+		}
+		obj, ok := n.Shape.Shape.(*ObjectShape)
+		if !ok || n.Shape.Alias != nil || n.Shape.Link != nil {
+			continue
+		}
+		for pair := obj.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value.Shape.Description == nil {
+				ctx.Report(fmt.Sprintf("property %q of type %q has no description", pair.Value.Name, n.Name),
+					pair.Value.Shape.Location(), pair.Value.Shape.Position)
+			}
+		}
+	}
+}
+
+type exampleRule struct{}
+
+// NewExampleRule reports declared types that have no example and do not inherit one.
+func NewExampleRule() LintRule {
+	return exampleRule{}
+}
+
+func (exampleRule) Name() string {
+	return LintRuleExampleRequired
+}
+
+func (exampleRule) Check(ctx *LintContext) {
+	for _, n := range ctx.Graph.Nodes {
+		if !hasExample(n.Shape, make(map[int64]struct{})) {
+			ctx.Report(fmt.Sprintf("type %q has no examples", n.Name), n.Shape.Location(), n.Shape.Position)
+		}
+	}
+}
+
+func hasExample(s *BaseShape, visited map[int64]struct{}) bool {
+	if s == nil {
+		return false
+	}
+	if _, ok := visited[s.ID]; ok {
+		return false
+	}
+	visited[s.ID] = struct{}{}
+	if s.Example != nil || s.Examples != nil {
+		return true
+	}
+	if s.Link != nil && hasExample(s.Link.Shape, visited) {
+		return true
+	}
+	if hasExample(s.Alias, visited) {
+		return true
+	}
+	for _, parent := range s.Inherits {
+		if hasExample(parent, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+type forbiddenTypesRule struct {
+	types []string
+}
+
+// NewForbiddenTypesRule reports declared types, properties, items and union members of the given types. Types are
+// built-in types, e.g. "any", or qualified names of declared types, e.g. "common.Legacy".
+func NewForbiddenTypesRule(types ...string) LintRule {
+	return forbiddenTypesRule{types: types}
+}
+
+func (forbiddenTypesRule) Name() string {
+	return LintRuleForbiddenTypes
+}
+
+func (r forbiddenTypesRule) Check(ctx *LintContext) {
+	if len(r.types) == 0 {
+		return
+	}
+	for _, n := range ctx.Graph.Nodes {
+		walkInlineShapes(n.Shape, "", func(s *BaseShape, path string) {
+			for _, name := range r.baseTypes(ctx, s) {
+				if !slices.Contains(r.types, name) {
+					continue
+				}
+				msg := fmt.Sprintf("type %q uses forbidden type %q", n.Name, name)
+				if path != "" {
+					msg = fmt.Sprintf("type %q uses forbidden type %q at %s", n.Name, name, path)
+				}
+				ctx.Report(msg, s.Location(), s.Position)
+			}
+		})
+	}
+}
+
+// baseTypes returns names of types the shape is declared with.
+func (r forbiddenTypesRule) baseTypes(ctx *LintContext, s *BaseShape) []string {
+	var base []*BaseShape
+	switch {
+	case s.Alias != nil:
+		base = []*BaseShape{s.Alias}
+	case s.Link != nil:
+		base = []*BaseShape{s.Link.Shape}
+	case len(s.Inherits) > 0:
+		base = s.Inherits
+	default:
+		if rs, ok := s.Shape.(*RecursiveShape); ok {
+			base = []*BaseShape{rs.Head}
+		} else {
+			return []string{s.Type}
+		}
+	}
+	names := make([]string, 0, len(base))
+	for _, b := range base {
+		if name, ok := ctx.DeclaredName(b); ok {
+			names = append(names, name)
+		} else {
+			names = append(names, b.Type)
+		}
+	}
+	return names
+}
+
+type nameCollisionRule struct{}
+
+// NewNameCollisionRule reports declared types whose canonical names collide with types of other namespaces, see
+// RAML.NameCollisions. The first type of each collision is not reported.
+func NewNameCollisionRule() LintRule {
+	return nameCollisionRule{}
+}
+
+func (nameCollisionRule) Name() string {
+	return LintRuleNameCollision
+}
+
+func (nameCollisionRule) Check(ctx *LintContext) {
+	for _, c := range nameCollisions(ctx.Graph) {
+		first := c.Types[0]
+		for _, n := range c.Types[1:] {
+			ctx.Report(fmt.Sprintf("type %q collides with %q as %q", n.Name, first.Name, c.Name), n.Shape.Location(),
+				n.Shape.Position)
+		}
+	}
+}
+
+// walkInlineShapes calls fn for the shape and its inline shapes (facet definitions, properties, items and union
+// members) with their paths. References to declared types are not followed.
+func walkInlineShapes(s *BaseShape, path string, fn func(s *BaseShape, path string)) {
+	var walk func(s *BaseShape, path string, visited map[int64]struct{})
+	walk = func(s *BaseShape, path string, visited map[int64]struct{}) {
+		if s == nil {
+			return
+		}
+		if _, ok := visited[s.ID]; ok {
+			return
+		}
+		visited[s.ID] = struct{}{}
+		fn(s, path)
+		if s.Alias != nil || s.Link != nil {
+			return
+		}
+		for pair := s.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
+			walk(pair.Value.Shape, joinGraphPath(path, "facets."+pair.Key), visited)
+		}
+		switch shape := s.Shape.(type) {
+		case *ObjectShape:
+			for pair := shape.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				walk(pair.Value.Shape, joinGraphPath(path, "properties."+pair.Key), visited)
+			}
+			for pair := shape.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+				walk(pair.Value.Shape, joinGraphPath(path, "properties."+pair.Key), visited)
+			}
+		case *ArrayShape:
+			walk(shape.Items, joinGraphPath(path, "items"), visited)
+		case *UnionShape:
+			for i, member := range shape.AnyOf {
+				walk(member, joinGraphPath(path, fmt.Sprintf("anyOf[%d]", i)), visited)
+			}
+		}
+	}
+	walk(s, path, make(map[int64]struct{}))
+}
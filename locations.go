@@ -0,0 +1,103 @@
+package raml
+
+// LocationID is a handle of a fragment location in the location table of RAML. The zero handle refers to no location.
+// Handles are never reused: a fragment that is read again after Reparse gets a new handle, while shapes, errors and
+// stacktraces kept from before the reparse still resolve their handles to the original location.
+type LocationID uint32
+
+// locationTable returns fragment locations indexed by their handles minus one. The table is append-only, so it is
+// read without holding r.mu.
+func (r *RAML) locationTable() []string {
+	if p := r.locations.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// internLocation registers the fragment location in the location table and returns its canonical copy. Fragments,
+// nodes and stacktraces of the fragment share the memory of the canonical copy instead of holding a string per path
+// join.
+func (r *RAML) internLocation(location string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := r.registerLocation(location)
+	return r.locationTable()[id-1]
+}
+
+// locationHandle registers the location in the location table and returns its handle. Shapes store handles
+// instead of strings.
+func (r *RAML) locationHandle(location string) LocationID {
+	if location == "" {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.registerLocation(location)
+}
+
+// registerLocation returns the handle of the location, adding it to the table if necessary. The caller must hold
+// r.mu.
+func (r *RAML) registerLocation(location string) LocationID {
+	if id, ok := r.locationIDs[location]; ok {
+		return id
+	}
+	if r.locationIDs == nil {
+		r.locationIDs = make(map[string]LocationID)
+	}
+	// Readers hold shorter slices of the same array, so appending does not race with them.
+	locations := append(r.locationTable(), location)
+	r.locations.Store(&locations)
+	id := LocationID(len(locations))
+	r.locationIDs[location] = id
+	return id
+}
+
+// dropLocation removes the location of a dropped fragment from the index of locations. The handle keeps resolving
+// to the location.
+func (r *RAML) dropLocation(location string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.locationIDs, location)
+}
+
+// LocationID returns the handle of the fragment location, or false if no fragment is read from the location.
+func (r *RAML) LocationID(location string) (LocationID, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, ok := r.locationIDs[location]
+	return id, ok
+}
+
+// LocationByID returns the fragment location of the handle, or an empty string if the handle is unknown.
+// The returned string shares memory with locations of the fragment model.
+func (r *RAML) LocationByID(id LocationID) string {
+	if r == nil {
+		return ""
+	}
+	locations := r.locationTable()
+	if id == 0 || int(id) > len(locations) {
+		return ""
+	}
+	return locations[id-1]
+}
+
+// Locations returns locations of all fragments in the order of their handles. Locations of dropped fragments are
+// omitted.
+func (r *RAML) Locations() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	locations := make([]string, 0, len(r.locationIDs))
+	for i, loc := range r.locationTable() {
+		if r.isActiveLocation(LocationID(i + 1)) {
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
+
+// isActiveLocation reports whether the handle refers to a fragment of the model rather than to a dropped one. The
+// caller must hold r.mu.
+func (r *RAML) isActiveLocation(id LocationID) bool {
+	loc := r.locationTable()[id-1]
+	return loc != "" && r.locationIDs[loc] == id
+}
@@ -0,0 +1,92 @@
+package raml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocations(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"common.raml": `#%RAML 1.0 Library
+types:
+  Named:
+    properties:
+      name: string
+`,
+		"a.raml": `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  A: common.Named
+`,
+		"b.raml": `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  B: common.Named
+`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	r, err := ParseFromString(`#%RAML 1.0 Library
+uses:
+  a: a.raml
+  b: b.raml
+`, "library.raml", dir, OptWithUnwrap())
+	require.NoError(t, err)
+
+	locations := r.Locations()
+	require.Equal(t, []string{
+		filepath.Join(dir, "library.raml"),
+		filepath.Join(dir, "a.raml"),
+		filepath.Join(dir, "common.raml"),
+		filepath.Join(dir, "b.raml"),
+	}, locations)
+
+	for _, loc := range locations {
+		id, ok := r.LocationID(loc)
+		require.True(t, ok)
+		require.Equal(t, loc, r.LocationByID(id))
+	}
+	_, ok := r.LocationID(filepath.Join(dir, "missing.raml"))
+	require.False(t, ok)
+	require.Empty(t, r.LocationByID(0))
+
+	// Shapes refer to locations by handles.
+	id, _ := r.LocationID(filepath.Join(dir, "common.raml"))
+	named, err := r.LookupType("Named", filepath.Join(dir, "common.raml"))
+	require.NoError(t, err)
+	require.Equal(t, id, named.LocationID)
+	require.Equal(t, filepath.Join(dir, "common.raml"), named.Location())
+
+	// Locations of fragments that are dropped by reparsing are removed from the table, but handles are not reused.
+	aID, _ := r.LocationID(filepath.Join(dir, "a.raml"))
+	oldB, _ := r.LocationID(filepath.Join(dir, "b.raml"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.raml"), []byte(`#%RAML 1.0 Library
+types:
+  B: string
+`), 0o600))
+	r.Invalidate(filepath.Join(dir, "common.raml"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "library.raml"), []byte(`#%RAML 1.0 Library
+uses:
+  b: b.raml
+`), 0o600))
+	require.NoError(t, r.Reparse(OptWithUnwrap()))
+	require.ElementsMatch(t, []string{
+		filepath.Join(dir, "library.raml"),
+		filepath.Join(dir, "b.raml"),
+	}, r.Locations())
+	b, err := r.LookupType("B", filepath.Join(dir, "b.raml"))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "b.raml"), b.Location())
+	require.NotEqual(t, oldB, b.LocationID)
+	require.Equal(t, filepath.Join(dir, "common.raml"), named.Location())
+	require.Equal(t, filepath.Join(dir, "a.raml"), r.LocationByID(aID))
+	_, ok = r.LocationID(filepath.Join(dir, "a.raml"))
+	require.False(t, ok)
+}
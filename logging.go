@@ -0,0 +1,114 @@
+package raml
+
+import (
+	"log/slog"
+	"time"
+)
+
+// LevelTrace is the level of verbose logs of the pipeline, e.g. of every inheritance of shapes. It is below
+// slog.LevelDebug, so handlers must be configured with it explicitly.
+const LevelTrace = slog.LevelDebug - 4
+
+type parseOptWithLogger struct {
+	logger *slog.Logger
+}
+
+func (o parseOptWithLogger) Apply(opt *parserOptions) {
+	opt.logger = o.logger
+}
+
+// OptWithLogger emits logs of the parsing pipeline to the logger to diagnose why a specification takes long to parse
+// or fails: loading of fragments and includes with their durations, durations of the pipeline stages and check
+// failures at slog.LevelDebug, and inheritance decisions at LevelTrace. Nothing is logged by default.
+func OptWithLogger(logger *slog.Logger) ParseOpt {
+	return parseOptWithLogger{logger: logger}
+}
+
+// logEnabled returns true if the logger of the RAML emits records of the level. The receiver may be nil for shapes
+// that do not belong to a RAML.
+func (r *RAML) logEnabled(level slog.Level) bool {
+	return r != nil && r.logger != nil && r.logger.Enabled(r.context(), level)
+}
+
+func (r *RAML) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	if !r.logEnabled(level) {
+		return
+	}
+	r.logger.LogAttrs(r.context(), level, msg, attrs...)
+}
+
+// logFragmentStart remembers when decoding of the fragment started. Fragments are decoded recursively, so they are
+// done in the reverse order.
+func (r *RAML) logFragmentStart(location string) {
+	if !r.logEnabled(slog.LevelDebug) {
+		return
+	}
+	r.fragmentStarts = append(r.fragmentStarts, time.Now())
+	r.log(slog.LevelDebug, "load fragment", slog.String("location", location))
+}
+
+func (r *RAML) logFragmentDone(location string, err error) {
+	if !r.logEnabled(slog.LevelDebug) || len(r.fragmentStarts) == 0 {
+		return
+	}
+	start := r.fragmentStarts[len(r.fragmentStarts)-1]
+	r.fragmentStarts = r.fragmentStarts[:len(r.fragmentStarts)-1]
+	attrs := []slog.Attr{slog.String("location", location), slog.Duration("duration", time.Since(start))}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	r.log(slog.LevelDebug, "fragment loaded", attrs...)
+}
+
+func (r *RAML) logInclude(baseLocation string, ref string, location string, cached bool, err error) {
+	if !r.logEnabled(slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{slog.String("from", baseLocation), slog.String("ref", ref)}
+	if err != nil {
+		r.log(slog.LevelDebug, "include failed", append(attrs, slog.Any("error", err))...)
+		return
+	}
+	attrs = append(attrs, slog.String("location", location), slog.Bool("cached", cached))
+	r.log(slog.LevelDebug, "include resolved", attrs...)
+}
+
+func (r *RAML) logStage(stage string, start time.Time, err error) {
+	if !r.logEnabled(slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{slog.String("stage", stage), slog.Duration("duration", time.Since(start))}
+	if err != nil {
+		r.log(slog.LevelDebug, "stage failed", append(attrs, slog.Any("error", err))...)
+		return
+	}
+	r.log(slog.LevelDebug, "stage done", attrs...)
+}
+
+func shapeLogAttrs(s *BaseShape) []slog.Attr {
+	return []slog.Attr{
+		slog.String("shape", s.Name),
+		slog.String("location", s.Location()),
+		slog.Int("line", s.Position.Line),
+	}
+}
+
+func (r *RAML) logInherit(s *BaseShape, source *BaseShape, strategy string) {
+	if !r.logEnabled(LevelTrace) {
+		return
+	}
+	parent := source.Name
+	if parent == "" {
+		parent = source.TypeLabel
+	}
+	attrs := append(shapeLogAttrs(s), slog.String("parent", parent), slog.String("strategy", strategy))
+	r.log(LevelTrace, "inherit", attrs...)
+}
+
+func (r *RAML) logInheritConflict(s *BaseShape, conflict string, decision string) {
+	if !r.logEnabled(LevelTrace) {
+		return
+	}
+	attrs := append(shapeLogAttrs(s), slog.String("conflict", conflict), slog.String("decision", decision))
+	r.log(LevelTrace, "inheritance conflict", attrs...)
+}
@@ -0,0 +1,86 @@
+package raml
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptWithLogger(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common.raml"), []byte(`#%RAML 1.0 Library
+types:
+  Name:
+    type: string
+    minLength: 5
+`), 0o600))
+	content := `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Short:
+    type: common.Name
+    minLength: 1
+    example: abc
+`
+	parse := func(level slog.Level, opts ...ParseOpt) ([]map[string]any, error) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level}))
+		_, err := ParseFromString(content, "library.raml", dir, append(opts, OptWithLogger(logger))...)
+		var records []map[string]any
+		dec := json.NewDecoder(&buf)
+		for dec.More() {
+			var rec map[string]any
+			require.NoError(t, dec.Decode(&rec))
+			records = append(records, rec)
+		}
+		return records, err
+	}
+	find := func(records []map[string]any, msg string, attrs map[string]any) map[string]any {
+		for _, rec := range records {
+			if rec["msg"] != msg {
+				continue
+			}
+			matched := true
+			for k, v := range attrs {
+				matched = matched && rec[k] == v
+			}
+			if matched {
+				return rec
+			}
+		}
+		return nil
+	}
+
+	records, err := parse(LevelTrace, OptWithInheritancePolicy(InheritanceNarrowAllowed), OptWithUnwrap(),
+		OptWithValidate())
+	require.Error(t, err)
+	require.NotNil(t, find(records, "load fragment", map[string]any{"location": filepath.Join(dir, "common.raml")}))
+	loaded := find(records, "fragment loaded", map[string]any{"location": filepath.Join(dir, "library.raml")})
+	require.NotNil(t, loaded)
+	require.Contains(t, loaded, "duration")
+	require.NotNil(t, find(records, "include resolved", map[string]any{
+		"ref": "common.raml", "location": filepath.Join(dir, "common.raml"), "cached": false,
+	}))
+	require.NotNil(t, find(records, "inherit", map[string]any{
+		"shape": "Short", "parent": "Name", "strategy": "merge",
+	}))
+	require.NotNil(t, find(records, "inheritance conflict", map[string]any{"shape": "Short", "decision": "narrow"}))
+	for _, stage := range []string{"parse", "link", "resolve"} {
+		require.NotNil(t, find(records, "stage done", map[string]any{"stage": stage}), stage)
+	}
+	failed := find(records, "stage failed", map[string]any{"stage": "check"})
+	require.NotNil(t, failed)
+	require.Contains(t, failed["error"], "validate shapes")
+
+	// Inheritance decisions are only logged at the trace level.
+	records, err = parse(slog.LevelDebug, OptWithInheritancePolicy(InheritanceWarnOnly), OptWithUnwrap())
+	require.NoError(t, err)
+	require.NotNil(t, find(records, "stage done", map[string]any{"stage": "resolve"}))
+	require.Nil(t, find(records, "inheritance conflict", nil))
+}
@@ -0,0 +1,94 @@
+package raml
+
+import (
+	"fmt"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// fragmentAt returns the fragment at the location or the entry point if the location is empty.
+func (r *RAML) fragmentAt(location string) (Fragment, error) {
+	if location == "" {
+		if r.entryPoint == nil {
+			return nil, fmt.Errorf("entry point is not set")
+		}
+		return r.entryPoint, nil
+	}
+	frag := r.GetFragment(location)
+	if frag == nil {
+		return nil, fmt.Errorf("fragment %s not found", location)
+	}
+	return frag, nil
+}
+
+// Uses returns libraries used by the fragment at the location by their aliases.
+// The entry point is used if the location is empty. Nil is returned for fragments that cannot use libraries.
+func (r *RAML) Uses(location string) *orderedmap.OrderedMap[string, *LibraryLink] {
+	frag, err := r.fragmentAt(location)
+	if err != nil {
+		return nil
+	}
+	switch f := frag.(type) {
+	case *Library:
+		return f.Uses
+	case *DataType:
+		return f.Uses
+	}
+	return nil
+}
+
+// LookupType resolves a reference to a declared type, e.g. "Pet" or "lib.Pet", as it is written in the fragment at
+// fromLocation. References are resolved exactly like type expressions: library aliases are looked up in uses of the
+// fragment. The entry point is used if fromLocation is empty.
+func (r *RAML) LookupType(ref string, fromLocation string) (*BaseShape, error) {
+	frag, err := r.fragmentAt(fromLocation)
+	if err != nil {
+		return nil, err
+	}
+	shape, err := frag.GetReferenceType(ref)
+	if err != nil {
+		return nil, fmt.Errorf("lookup type %s: %w", ref, err)
+	}
+	return shape, nil
+}
+
+// LookupAnnotationType resolves a reference to a declared annotation type like LookupType does.
+func (r *RAML) LookupAnnotationType(ref string, fromLocation string) (*BaseShape, error) {
+	frag, err := r.fragmentAt(fromLocation)
+	if err != nil {
+		return nil, err
+	}
+	shape, err := frag.GetReferenceAnnotationType(ref)
+	if err != nil {
+		return nil, fmt.Errorf("lookup annotation type %s: %w", ref, err)
+	}
+	return shape, nil
+}
+
+// ReferenceName returns the reference to the declared type as it may be written in the fragment at fromLocation,
+// i.e. the inverse of LookupType. False is returned if the type is neither declared by the fragment nor by a library
+// it uses directly.
+func (r *RAML) ReferenceName(shape *BaseShape, fromLocation string) (string, bool) {
+	frag, err := r.fragmentAt(fromLocation)
+	if err != nil {
+		return "", false
+	}
+	if lib, ok := frag.(*Library); ok {
+		if t, ok := lib.Types.Get(shape.Name); ok && t == shape {
+			return shape.Name, true
+		}
+	}
+	uses := r.Uses(fromLocation)
+	if uses == nil {
+		return "", false
+	}
+	for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Value.Link == nil {
+			continue
+		}
+		if t, ok := pair.Value.Link.Types.Get(shape.Name); ok && t == shape {
+			return pair.Key + "." + shape.Name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,131 @@
+package raml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAML_LookupType(t *testing.T) {
+	dir, err := filepath.Abs("./fixtures/lookup")
+	require.NoError(t, err)
+	content := `#%RAML 1.0 Library
+uses:
+  c: common.raml
+types:
+  Cat:
+    type: c.Pet
+`
+	rml, err := ParseFromString(content, "library.raml", dir)
+	require.NoError(t, err)
+	entry := rml.EntryPoint().GetLocation()
+	commonLocation := filepath.Join(dir, "common.raml")
+
+	uses := rml.Uses("")
+	require.Equal(t, 1, uses.Len())
+	link, _ := uses.Get("c")
+	require.Equal(t, commonLocation, link.Link.Location)
+
+	cat, err := rml.LookupType("Cat", "")
+	require.NoError(t, err)
+	require.Equal(t, "Cat", cat.Name)
+
+	pet, err := rml.LookupType("c.Pet", entry)
+	require.NoError(t, err)
+	require.Equal(t, commonLocation, pet.Location())
+	require.Same(t, pet, cat.Inherits[0])
+
+	samePet, err := rml.LookupType("Pet", commonLocation)
+	require.NoError(t, err)
+	require.Same(t, pet, samePet)
+
+	internal, err := rml.LookupAnnotationType("c.internal", "")
+	require.NoError(t, err)
+	require.Equal(t, "internal", internal.Name)
+
+	_, err = rml.LookupType("common.Pet", entry)
+	require.ErrorContains(t, err, "library \"common\" not found")
+	_, err = rml.LookupType("Dog", entry)
+	require.ErrorContains(t, err, "reference \"Dog\" not found")
+	_, err = rml.LookupType("Pet", filepath.Join(dir, "missing.raml"))
+	require.ErrorContains(t, err, "not found")
+
+	name, ok := rml.ReferenceName(pet, entry)
+	require.True(t, ok)
+	require.Equal(t, "c.Pet", name)
+	name, ok = rml.ReferenceName(pet, commonLocation)
+	require.True(t, ok)
+	require.Equal(t, "Pet", name)
+	_, ok = rml.ReferenceName(cat, commonLocation)
+	require.False(t, ok)
+}
+
+func TestRAML_LookupType_FragmentKinds(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, content string) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+		return p
+	}
+	writeFile("common.raml", `#%RAML 1.0 Library
+annotationTypes:
+  internal: nil
+types:
+  Named:
+    properties:
+      name: string
+`)
+	pet := writeFile("pet.raml", `#%RAML 1.0 DataType
+uses:
+  c: common.raml
+type: c.Named
+(c.internal):
+properties:
+  friends: c.Named[]
+`)
+	pets := writeFile("pets.raml", "#%RAML 1.0 NamedExample\nrex:\n  name: Rex\n")
+	api := writeFile("api.raml", `#%RAML 1.0
+title: Pets
+uses:
+  common: common.raml
+types:
+  Pet: !include pet.raml
+  Owner:
+    type: common.Named
+    properties:
+      pets: Pet[]
+    examples: !include pets.raml
+`)
+	rml, err := ParseFromPath(api)
+	require.NoError(t, err)
+
+	owner, err := rml.LookupType("Owner", api)
+	require.NoError(t, err)
+	named, err := rml.LookupType("common.Named", api)
+	require.NoError(t, err)
+	require.Same(t, named, owner.Inherits[0])
+
+	sameNamed, err := rml.LookupType("c.Named", pet)
+	require.NoError(t, err)
+	require.Same(t, named, sameNamed)
+	internal, err := rml.LookupAnnotationType("c.internal", pet)
+	require.NoError(t, err)
+	require.Equal(t, "internal", internal.Name)
+	_, err = rml.LookupType("Owner", pet)
+	require.ErrorContains(t, err, "fragment has no declarations")
+	_, err = rml.LookupType("common.Named", pet)
+	require.ErrorContains(t, err, `library "common" not found`)
+
+	_, err = rml.LookupType("Named", pets)
+	require.ErrorContains(t, err, "named example does not have references")
+
+	s, err := ParseTypeExpression("c.Named[] | nil", ResolutionContext{RAML: rml, Location: pet})
+	require.NoError(t, err)
+	require.Equal(t, "c.Named[]?", TypeExpression(s.Base()))
+
+	writeFile("pet.raml", "#%RAML 1.0 DataType\ntype: object\nproperties:\n  owner: Owner\n")
+	_, err = ParseFromPath(api)
+	require.ErrorContains(t, err, `reference "Owner" not found: fragment has no declarations`)
+}
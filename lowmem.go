@@ -0,0 +1,91 @@
+package raml
+
+import (
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// shapeBlockSize is the number of base shapes allocated at once in low-memory mode.
+const shapeBlockSize = 256
+
+type parseOptWithLowMemory struct{}
+
+func (parseOptWithLowMemory) Apply(opt *parserOptions) {
+	opt.lowMemory = true
+}
+
+// OptWithLowMemory reduces the memory used by the model of very large specifications: names of shapes, properties
+// and custom facets are interned, base shapes are allocated in blocks, and maps of custom facets, facet definitions
+// and annotations are allocated only for shapes that declare them. YAML trees of fragments are released after they
+// are decoded in any mode.
+//
+// NOTE: Shapes that do not declare custom facets, facet definitions or annotations share one empty map of each kind
+// in this mode. The maps can be read as usual, but must not be modified: assign a new map to the field instead.
+func OptWithLowMemory() ParseOpt {
+	return parseOptWithLowMemory{}
+}
+
+// intern returns the canonical copy of the string in low-memory mode, so repeated names share memory.
+func (r *RAML) intern(s string) string {
+	if r == nil || !r.lowMemory {
+		return s
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.interned[s]; ok {
+		return v
+	}
+	if r.interned == nil {
+		r.interned = make(map[string]string)
+	}
+	r.interned[s] = s
+	return s
+}
+
+// allocBaseShape returns a new zero base shape. In low-memory mode, shapes are allocated in blocks to avoid
+// the overhead of individual allocations.
+func (r *RAML) allocBaseShape() *BaseShape {
+	if !r.lowMemory {
+		return &BaseShape{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.shapeBlock) == 0 {
+		r.shapeBlock = make([]BaseShape, shapeBlockSize)
+	}
+	b := &r.shapeBlock[0]
+	r.shapeBlock = r.shapeBlock[1:]
+	return b
+}
+
+// Empty maps shared by shapes in low-memory mode.
+var (
+	noShapeFacets           = orderedmap.New[string, *Node]()
+	noShapeFacetDefinitions = orderedmap.New[string, Property]()
+	noDomainProperties      = orderedmap.New[string, *DomainExtension]()
+)
+
+// isSharedEmpty reports whether the map is one of the empty maps shared by shapes in low-memory mode.
+func isSharedEmpty(m any) bool {
+	switch m {
+	case any(noShapeFacets), any(noShapeFacetDefinitions), any(noDomainProperties):
+		return true
+	}
+	return false
+}
+
+// orderedGet returns the value of the key, nil maps are empty.
+func orderedGet[K comparable, V any](m *orderedmap.OrderedMap[K, V], key K) (V, bool) {
+	if m == nil {
+		var zero V
+		return zero, false
+	}
+	return m.Get(key)
+}
+
+// orderedSet sets the value of the key, allocating the map if it is nil or shared.
+func orderedSet[K comparable, V any](m **orderedmap.OrderedMap[K, V], key K, value V) {
+	if *m == nil || isSharedEmpty(*m) {
+		*m = orderedmap.New[K, V]()
+	}
+	(*m).Set(key, value)
+}
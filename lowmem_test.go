@@ -0,0 +1,76 @@
+package raml
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptWithLowMemory(t *testing.T) {
+	content := `#%RAML 1.0 Library
+annotationTypes:
+  internal: boolean
+types:
+  Base:
+    facets:
+      unit: string
+    properties:
+      id: integer
+  Pet:
+    type: Base
+    unit: kg
+    (internal): true
+    properties:
+      name: string
+  Owner:
+    properties:
+      id: integer
+      pets: Pet[]
+    example:
+      id: 1
+      pets:
+        - id: 2
+          name: Rex
+`
+	fingerprints := func(opts ...ParseOpt) (*RAML, map[string]string) {
+		r, err := ParseFromString(content, "library.raml", t.TempDir(), append(opts, OptWithValidate(),
+			OptWithUnwrap())...)
+		require.NoError(t, err)
+		result := make(map[string]string)
+		for _, n := range r.TypeGraph().Nodes {
+			result[n.Name], err = n.Shape.Fingerprint()
+			require.NoError(t, err)
+		}
+		return r, result
+	}
+	_, want := fingerprints()
+	r, got := fingerprints(OptWithLowMemory())
+	require.Equal(t, want, got)
+
+	lookup := func(name string) *BaseShape {
+		s, err := r.LookupType(name, r.GetLocation())
+		require.NoError(t, err)
+		return s
+	}
+	pet := lookup("Pet")
+	unit, ok := pet.CustomFacet("unit")
+	require.True(t, ok)
+	require.Equal(t, "kg", unit.Value.Value)
+	require.Equal(t, 1, pet.CustomDomainProperties.Len())
+
+	owner := lookup("Owner")
+	require.Equal(t, 0, owner.CustomDomainProperties.Len())
+	_, ok = owner.CustomShapeFacets.Get("unit")
+	require.False(t, ok)
+	_, ok = owner.CustomShapeFacetDefinitions.Get("unit")
+	require.False(t, ok)
+	// Clones keep sharing the empty maps.
+	require.Same(t, owner.CustomShapeFacets, owner.CloneDetached().CustomShapeFacets)
+	require.NoError(t, owner.Validate(map[string]any{"id": 1, "pets": []any{}}))
+
+	// Names of properties are interned.
+	ownerID, _ := owner.Shape.(*ObjectShape).Properties.Get("id")
+	petID, _ := pet.Shape.(*ObjectShape).Properties.Get("id")
+	require.Equal(t, unsafe.StringData(ownerID.Name), unsafe.StringData(petID.Name))
+}
@@ -0,0 +1,114 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/acronis/go-raml"
+)
+
+// describeShape renders a markdown summary of the type for hover.
+func describeShape(name string, shape *raml.BaseShape) string {
+	// Unwrapping is done on a detached copy in a scratch model to keep the document model intact.
+	if unwrapped, err := raml.New(context.Background()).UnwrapShape(shape.CloneDetached()); err == nil {
+		shape = unwrapped
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**%s**: `%s`\n", name, typeLabel(shape))
+	if shape.Description != nil {
+		fmt.Fprintf(&sb, "\n%s\n", *shape.Description)
+	}
+
+	switch s := shape.Shape.(type) {
+	case *raml.ObjectShape:
+		if s.Properties == nil || s.Properties.Len() == 0 {
+			break
+		}
+		sb.WriteString("\n")
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			prop := pair.Value
+			required := ""
+			if prop.Required {
+				required = " (required)"
+			}
+			fmt.Fprintf(&sb, "- `%s`: `%s`%s\n", prop.Name, typeLabel(prop.Shape), required)
+		}
+	case *raml.ArrayShape:
+		if s.Items != nil {
+			fmt.Fprintf(&sb, "\nitems: `%s`\n", typeLabel(s.Items))
+		}
+	case *raml.UnionShape:
+		members := make([]string, 0, len(s.AnyOf))
+		for _, item := range s.AnyOf {
+			members = append(members, typeLabel(item))
+		}
+		fmt.Fprintf(&sb, "\n`%s`\n", strings.Join(members, " | "))
+	case *raml.StringShape:
+		writeFacets(&sb,
+			facet("minLength", s.MinLength), facet("maxLength", s.MaxLength), patternFacet(s),
+			enumFacet(s.Enum))
+	case *raml.NumberShape:
+		writeFacets(&sb,
+			facet("minimum", s.Minimum), facet("maximum", s.Maximum), facet("multipleOf", s.MultipleOf),
+			facet("format", s.Format), enumFacet(s.Enum))
+	case *raml.IntegerShape:
+		writeFacets(&sb,
+			facet("minimum", s.Minimum), facet("maximum", s.Maximum), facet("multipleOf", s.MultipleOf),
+			facet("format", s.Format), enumFacet(s.Enum))
+	}
+	return sb.String()
+}
+
+func typeLabel(shape *raml.BaseShape) string {
+	if shape == nil {
+		return ""
+	}
+	if shape.TypeLabel != "" {
+		return shape.TypeLabel
+	}
+	if shape.Type != "" {
+		return shape.Type
+	}
+	return shape.Name
+}
+
+func facet[T any](name string, v *T) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s: `%v`", name, *v)
+}
+
+func patternFacet(s *raml.StringShape) string {
+	if s.Pattern == nil {
+		return ""
+	}
+	return fmt.Sprintf("pattern: `%s`", s.Pattern.String())
+}
+
+func enumFacet(enum raml.Nodes) string {
+	if len(enum) == 0 {
+		return ""
+	}
+	values := make([]string, 0, len(enum))
+	for _, n := range enum {
+		values = append(values, fmt.Sprintf("%v", n.Value))
+	}
+	return fmt.Sprintf("enum: `%s`", strings.Join(values, ", "))
+}
+
+func writeFacets(sb *strings.Builder, facets ...string) {
+	first := true
+	for _, f := range facets {
+		if f == "" {
+			continue
+		}
+		if first {
+			sb.WriteString("\n")
+			first = false
+		}
+		fmt.Fprintf(sb, "- %s\n", f)
+	}
+}
@@ -0,0 +1,128 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JSON-RPC 2.0 error codes used by the server.
+const (
+	CodeParseError     = -32700
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// defaultMaxContentLength limits the size of a message body, so a malformed header cannot make the server allocate
+// arbitrary amounts of memory.
+const defaultMaxContentLength = 64 << 20
+
+// ResponseError is a JSON-RPC 2.0 error object.
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("jsonrpc: code %d: %s", e.Code, e.Message)
+}
+
+// message is a JSON-RPC 2.0 request, notification or response.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  *json.RawMessage `json:"result,omitempty"`
+	Error   *ResponseError   `json:"error,omitempty"`
+}
+
+// conn reads and writes JSON-RPC messages using the LSP base protocol framing.
+type conn struct {
+	r *bufio.Reader
+	w io.Writer
+
+	mu sync.Mutex
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{r: bufio.NewReader(r), w: w}
+}
+
+// read reads the next message. Bodies longer than maxLength bytes are skipped and reported as parse errors.
+func (c *conn) read(maxLength int) (*message, error) {
+	tp := textproto.NewReader(c.r)
+	header, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil {
+		return nil, fmt.Errorf("parse content length: %w", err)
+	}
+	switch {
+	case length < 0:
+		return nil, &ResponseError{Code: CodeParseError, Message: fmt.Sprintf("invalid content length %d", length)}
+	case length > maxLength:
+		// The body is skipped to keep the stream in sync with the next message.
+		if _, err = io.CopyN(io.Discard, c.r, int64(length)); err != nil {
+			return nil, fmt.Errorf("skip body: %w", err)
+		}
+		return nil, &ResponseError{Code: CodeParseError,
+			Message: fmt.Sprintf("content length %d exceeds the limit of %d bytes", length, maxLength)}
+	}
+	body := make([]byte, length)
+	if _, err = io.ReadFull(c.r, body); err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+	var msg message
+	if err = json.Unmarshal(body, &msg); err != nil {
+		return nil, &ResponseError{Code: CodeParseError, Message: err.Error()}
+	}
+	return &msg, nil
+}
+
+func (c *conn) write(msg *message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	if _, err = c.w.Write(body); err != nil {
+		return fmt.Errorf("write body: %w", err)
+	}
+	return nil
+}
+
+func (c *conn) reply(id *json.RawMessage, result any, respErr *ResponseError) error {
+	msg := &message{ID: id, Error: respErr}
+	if respErr == nil {
+		// Result must be present in successful responses even if it is null.
+		raw, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("marshal result: %w", err)
+		}
+		rawMsg := json.RawMessage(raw)
+		msg.Result = &rawMsg
+	}
+	return c.write(msg)
+}
+
+func (c *conn) notify(method string, params any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("marshal params: %w", err)
+	}
+	return c.write(&message{Method: method, Params: raw})
+}
@@ -0,0 +1,443 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+
+	"github.com/acronis/go-raml"
+)
+
+const serverName = "raml-lsp"
+
+// document is an open text document and the RAML model parsed from it.
+type document struct {
+	path string
+	text string
+	rml  *raml.RAML
+	// published contains URIs that have non-empty diagnostics published for the document.
+	published map[string]struct{}
+}
+
+// Server is a Language Server Protocol server for RAML 1.0 fragments.
+// It provides diagnostics, go-to-definition and hover for types.
+// WARNING: Not thread-safe, messages are processed sequentially.
+type Server struct {
+	conn *conn
+	docs map[string]*document
+	// maxContentLength limits the size of a message body, see defaultMaxContentLength.
+	maxContentLength int
+
+	shutdown bool
+}
+
+// ErrExitWithoutShutdown is returned by Run if the exit notification is received before the shutdown request, in
+// which case the client expects the server process to exit with status 1.
+var ErrExitWithoutShutdown = errors.New("exit notification received before shutdown request")
+
+// NewServer creates a new server that reads requests from r and writes responses to w.
+func NewServer(r io.Reader, w io.Writer) *Server {
+	return &Server{
+		conn:             newConn(r, w),
+		docs:             make(map[string]*document),
+		maxContentLength: defaultMaxContentLength,
+	}
+}
+
+// Run processes incoming messages until the exit notification is received or the input is closed.
+// ErrExitWithoutShutdown is returned if the client did not request shutdown before exit.
+func (s *Server) Run(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("context: %w", err)
+		}
+		msg, err := s.conn.read(s.maxContentLength)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			var respErr *ResponseError
+			if errors.As(err, &respErr) {
+				if errReply := s.conn.reply(nil, nil, respErr); errReply != nil {
+					return fmt.Errorf("reply: %w", errReply)
+				}
+				continue
+			}
+			return fmt.Errorf("read message: %w", err)
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		if err = s.handle(ctx, msg); err != nil {
+			return fmt.Errorf("handle %s: %w", msg.Method, err)
+		}
+	}
+}
+
+func (s *Server) handle(ctx context.Context, msg *message) error {
+	result, respErr := s.dispatch(ctx, msg)
+	// Notifications do not have IDs and must not be answered.
+	if msg.ID == nil {
+		if respErr != nil {
+			slog.Warn("notification failed", slog.String("method", msg.Method), slog.String("error", respErr.Message))
+		}
+		return nil
+	}
+	return s.conn.reply(msg.ID, result, respErr)
+}
+
+func (s *Server) dispatch(ctx context.Context, msg *message) (any, *ResponseError) {
+	switch msg.Method {
+	case "initialize":
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync: TextDocumentSyncOptions{
+					OpenClose: true,
+					Change:    TextDocumentSyncKindFull,
+					Save:      true,
+				},
+				HoverProvider:      true,
+				DefinitionProvider: true,
+			},
+			ServerInfo: ServerInfo{Name: serverName},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didOpen(ctx, params)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didChange(ctx, params)
+	case "textDocument/didSave":
+		var params DidSaveTextDocumentParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didSave(ctx, params)
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didClose(params)
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(params)
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params)
+	default:
+		return nil, &ResponseError{Code: CodeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+}
+
+func decodeParams(msg *message, v any) *ResponseError {
+	if err := json.Unmarshal(msg.Params, v); err != nil {
+		return &ResponseError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) didOpen(ctx context.Context, params DidOpenTextDocumentParams) *ResponseError {
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return &ResponseError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	doc := &document{path: path, text: params.TextDocument.Text, published: make(map[string]struct{})}
+	s.docs[path] = doc
+	return s.analyze(ctx, doc)
+}
+
+func (s *Server) didChange(ctx context.Context, params DidChangeTextDocumentParams) *ResponseError {
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return &ResponseError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	doc, ok := s.docs[path]
+	if !ok || len(params.ContentChanges) == 0 {
+		return nil
+	}
+	// Full synchronization is used, so the last change contains the whole document.
+	doc.text = params.ContentChanges[len(params.ContentChanges)-1].Text
+	return s.analyze(ctx, doc)
+}
+
+func (s *Server) didSave(ctx context.Context, params DidSaveTextDocumentParams) *ResponseError {
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return &ResponseError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	// Saved file may be used by other open documents, so all of them must be re-analyzed.
+	for _, doc := range s.docs {
+		if doc.path != path && (doc.rml == nil || !dependsOn(doc.rml, path)) {
+			continue
+		}
+		if respErr := s.analyze(ctx, doc); respErr != nil {
+			return respErr
+		}
+	}
+	return nil
+}
+
+func dependsOn(rml *raml.RAML, path string) bool {
+	return rml.GetFragment(path) != nil || len(rml.GetDependents(path)) > 0
+}
+
+func (s *Server) didClose(params DidCloseTextDocumentParams) *ResponseError {
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return &ResponseError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	doc, ok := s.docs[path]
+	if !ok {
+		return nil
+	}
+	delete(s.docs, path)
+	return s.publish(doc, map[string][]Diagnostic{pathToURI(doc.path): nil})
+}
+
+// parse parses the document content and recovers from parser panics.
+func parse(ctx context.Context, doc *document) (rml *raml.RAML, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("parser panic: %v", r)
+			}
+		}
+	}()
+	return raml.ParseFromStringCtx(ctx, doc.text, filepath.Base(doc.path), filepath.Dir(doc.path),
+		raml.OptWithValidate())
+}
+
+func (s *Server) analyze(ctx context.Context, doc *document) *ResponseError {
+	byURI := map[string][]Diagnostic{pathToURI(doc.path): nil}
+	if !strings.HasPrefix(doc.text, "#%RAML") {
+		doc.rml = nil
+		return s.publish(doc, byURI)
+	}
+	rml, err := parse(ctx, doc)
+	if rml != nil {
+		doc.rml = rml
+	}
+	diags := raml.DiagnosticsFromError(err)
+	if rml != nil {
+		diags = append(diags, rml.Diagnostics()...)
+	}
+	for _, d := range diags {
+		location := d.Location
+		if location == "" {
+			location = doc.path
+		}
+		uri := pathToURI(location)
+		byURI[uri] = append(byURI[uri], s.toDiagnostic(d))
+	}
+	return s.publish(doc, byURI)
+}
+
+func (s *Server) toDiagnostic(d raml.Diagnostic) Diagnostic {
+	severity := SeverityError
+	if d.Severity == stacktrace.SeverityWarning {
+		severity = SeverityWarning
+	}
+	start := Position{}
+	if d.HasPosition() {
+		start = Position{Line: d.Line - 1, Character: max(d.Column-1, 0)}
+	}
+	end := start
+	if doc, ok := s.docs[d.Location]; ok {
+		if _, r, found := wordAt(doc.text, start); found {
+			end = r.End
+		}
+	}
+	return Diagnostic{
+		Range:    Range{Start: start, End: end},
+		Severity: severity,
+		Source:   serverName,
+		Message:  d.Message,
+	}
+}
+
+func (s *Server) publish(doc *document, byURI map[string][]Diagnostic) *ResponseError {
+	// Diagnostics published previously for other files must be cleared if they are gone.
+	for uri := range doc.published {
+		if _, ok := byURI[uri]; !ok {
+			byURI[uri] = nil
+		}
+	}
+	doc.published = make(map[string]struct{})
+	for uri, diags := range byURI {
+		if diags == nil {
+			diags = []Diagnostic{}
+		} else {
+			doc.published[uri] = struct{}{}
+		}
+		err := s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+			URI:         uri,
+			Diagnostics: diags,
+		})
+		if err != nil {
+			return &ResponseError{Code: CodeInternalError, Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// lookup returns the document and the word under the given position.
+func (s *Server) lookup(params TextDocumentPositionParams) (*document, string, Range, *ResponseError) {
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return nil, "", Range{}, &ResponseError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	doc, ok := s.docs[path]
+	if !ok {
+		return nil, "", Range{}, nil
+	}
+	word, r, found := wordAt(doc.text, params.Position)
+	if !found {
+		return nil, "", Range{}, nil
+	}
+	return doc, word, r, nil
+}
+
+func (s *Server) definition(params TextDocumentPositionParams) (any, *ResponseError) {
+	doc, word, _, respErr := s.lookup(params)
+	if doc == nil {
+		return nil, respErr
+	}
+	// Paths of !include and uses are resolved relative to the document.
+	if isPathLike(word) {
+		target := filepath.Join(filepath.Dir(doc.path), word)
+		if _, err := os.Stat(target); err == nil {
+			return Location{URI: pathToURI(target)}, nil
+		}
+	}
+	shape := findType(doc, word)
+	if shape == nil {
+		return nil, nil
+	}
+	pos := Position{Line: max(shape.Line-1, 0), Character: max(shape.Column-1, 0)}
+	return Location{URI: pathToURI(shape.Location()), Range: Range{Start: pos, End: pos}}, nil
+}
+
+func (s *Server) hover(params TextDocumentPositionParams) (any, *ResponseError) {
+	doc, word, r, respErr := s.lookup(params)
+	if doc == nil {
+		return nil, respErr
+	}
+	shape := findType(doc, word)
+	if shape == nil {
+		return nil, nil
+	}
+	return Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: describeShape(word, shape)},
+		Range:    &r,
+	}, nil
+}
+
+// findType resolves a type or annotation type reference in the context of the document fragment.
+func findType(doc *document, name string) *raml.BaseShape {
+	if doc.rml == nil {
+		return nil
+	}
+	frag := doc.rml.GetFragment(doc.path)
+	if frag == nil {
+		return nil
+	}
+	if ref, err := frag.GetReferenceType(name); err == nil && ref != nil {
+		return ref
+	}
+	if ref, err := frag.GetReferenceAnnotationType(name); err == nil && ref != nil {
+		return ref
+	}
+	return nil
+}
+
+func isPathLike(word string) bool {
+	if strings.Contains(word, "/") {
+		return true
+	}
+	switch filepath.Ext(word) {
+	case ".raml", ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-' || c == '.' || c == '/' || c == '~'
+}
+
+// wordAt returns a type name or a path located at the given position.
+func wordAt(text string, pos Position) (string, Range, bool) {
+	lines := strings.Split(text, "\n")
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return "", Range{}, false
+	}
+	line := strings.TrimRight(lines[pos.Line], "\r")
+	if pos.Character < 0 || pos.Character > len(line) {
+		return "", Range{}, false
+	}
+	start, end := pos.Character, pos.Character
+	for start > 0 && isWordChar(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isWordChar(line[end]) {
+		end++
+	}
+	if start == end {
+		return "", Range{}, false
+	}
+	return line[start:end], Range{
+		Start: Position{Line: pos.Line, Character: start},
+		End:   Position{Line: pos.Line, Character: end},
+	}, true
+}
+
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("parse uri: %w", err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported uri scheme: %s", u.Scheme)
+	}
+	return filepath.Clean(filepath.FromSlash(u.Path)), nil
+}
+
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,221 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func frame(t *testing.T, buf *bytes.Buffer, id int, method string, params any) {
+	t.Helper()
+	msg := map[string]any{"jsonrpc": "2.0", "method": method, "params": params}
+	if id > 0 {
+		msg["id"] = id
+	}
+	c := newConn(nil, buf)
+	raw, err := json.Marshal(msg)
+	require.NoError(t, err)
+	var m message
+	require.NoError(t, json.Unmarshal(raw, &m))
+	require.NoError(t, c.write(&m))
+}
+
+func readAll(t *testing.T, out *bytes.Buffer) []*message {
+	t.Helper()
+	c := &conn{r: bufio.NewReader(out)}
+	var result []*message
+	for {
+		msg, err := c.read(defaultMaxContentLength)
+		if errors.Is(err, io.EOF) {
+			return result
+		}
+		require.NoError(t, err)
+		result = append(result, msg)
+	}
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	commonPath := filepath.Join(dir, "common.raml")
+	require.NoError(t, os.WriteFile(commonPath, []byte(`#%RAML 1.0 Library
+types:
+  Parent:
+    type: string
+    minLength: 1
+`), 0o600))
+	libPath := filepath.Join(dir, "library.raml")
+	libText := `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Child:
+    type: common.Parent
+    maxLength: 10
+`
+	require.NoError(t, os.WriteFile(libPath, []byte(libText), 0o600))
+	libURI := pathToURI(libPath)
+
+	tests := []struct {
+		name   string
+		method string
+		params any
+		check  func(t *testing.T, result json.RawMessage)
+	}{
+		{
+			name:   "definition of library type",
+			method: "textDocument/definition",
+			params: TextDocumentPositionParams{
+				TextDocument: TextDocumentIdentifier{URI: libURI},
+				Position:     Position{Line: 5, Character: 14},
+			},
+			check: func(t *testing.T, result json.RawMessage) {
+				var loc Location
+				require.NoError(t, json.Unmarshal(result, &loc))
+				require.Equal(t, pathToURI(commonPath), loc.URI)
+				require.Equal(t, 3, loc.Range.Start.Line)
+			},
+		},
+		{
+			name:   "definition of uses path",
+			method: "textDocument/definition",
+			params: TextDocumentPositionParams{
+				TextDocument: TextDocumentIdentifier{URI: libURI},
+				Position:     Position{Line: 2, Character: 12},
+			},
+			check: func(t *testing.T, result json.RawMessage) {
+				var loc Location
+				require.NoError(t, json.Unmarshal(result, &loc))
+				require.Equal(t, pathToURI(commonPath), loc.URI)
+			},
+		},
+		{
+			name:   "hover of local type",
+			method: "textDocument/hover",
+			params: TextDocumentPositionParams{
+				TextDocument: TextDocumentIdentifier{URI: libURI},
+				Position:     Position{Line: 4, Character: 3},
+			},
+			check: func(t *testing.T, result json.RawMessage) {
+				var hover Hover
+				require.NoError(t, json.Unmarshal(result, &hover))
+				require.Contains(t, hover.Contents.Value, "**Child**")
+				require.Contains(t, hover.Contents.Value, "minLength: `1`")
+				require.Contains(t, hover.Contents.Value, "maxLength: `10`")
+			},
+		},
+		{
+			name:   "hover of unknown word",
+			method: "textDocument/hover",
+			params: TextDocumentPositionParams{
+				TextDocument: TextDocumentIdentifier{URI: libURI},
+				Position:     Position{Line: 6, Character: 5},
+			},
+			check: func(t *testing.T, result json.RawMessage) {
+				require.Equal(t, "null", string(result))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, out := &bytes.Buffer{}, &bytes.Buffer{}
+			frame(t, in, 1, "initialize", map[string]any{})
+			frame(t, in, 0, "textDocument/didOpen", DidOpenTextDocumentParams{
+				TextDocument: TextDocumentItem{URI: libURI, LanguageID: "raml", Text: libText},
+			})
+			frame(t, in, 2, tt.method, tt.params)
+			frame(t, in, 3, "shutdown", nil)
+			frame(t, in, 0, "exit", nil)
+			require.NoError(t, NewServer(in, out).Run(context.Background()))
+
+			msgs := readAll(t, out)
+			// initialize response, published diagnostics, the response to the request and to shutdown.
+			require.Len(t, msgs, 4)
+			require.Equal(t, "textDocument/publishDiagnostics", msgs[1].Method)
+			var diags PublishDiagnosticsParams
+			require.NoError(t, json.Unmarshal(msgs[1].Params, &diags))
+			require.Empty(t, diags.Diagnostics)
+			require.Nil(t, msgs[2].Error)
+			// Null result is decoded as nil.
+			result := json.RawMessage("null")
+			if msgs[2].Result != nil {
+				result = *msgs[2].Result
+			}
+			tt.check(t, result)
+		})
+	}
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	dir := t.TempDir()
+	libPath := filepath.Join(dir, "library.raml")
+	libURI := pathToURI(libPath)
+	in, out := &bytes.Buffer{}, &bytes.Buffer{}
+	frame(t, in, 0, "textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: libURI, LanguageID: "raml", Text: `#%RAML 1.0 Library
+types:
+  Child:
+    type: string
+    minLength: 10
+    maxLength: 1
+`},
+	})
+	frame(t, in, 0, "textDocument/didClose", DidCloseTextDocumentParams{
+		TextDocument: TextDocumentIdentifier{URI: libURI},
+	})
+	require.NoError(t, NewServer(in, out).Run(context.Background()))
+
+	msgs := readAll(t, out)
+	require.Len(t, msgs, 2)
+	var diags PublishDiagnosticsParams
+	require.NoError(t, json.Unmarshal(msgs[0].Params, &diags))
+	require.Equal(t, libURI, diags.URI)
+	require.NotEmpty(t, diags.Diagnostics)
+	require.Equal(t, SeverityError, diags.Diagnostics[0].Severity)
+	// Diagnostics are cleared when the document is closed.
+	require.NoError(t, json.Unmarshal(msgs[1].Params, &diags))
+	require.Empty(t, diags.Diagnostics)
+}
+
+func TestServer_InvalidContentLength(t *testing.T) {
+	for name, header := range map[string]string{
+		"negative":  "Content-Length: -5\r\n\r\n",
+		"too large": "Content-Length: 17\r\n\r\n" + strings.Repeat(" ", 17),
+	} {
+		t.Run(name, func(t *testing.T) {
+			in, out := bytes.NewBufferString(header), &bytes.Buffer{}
+			s := NewServer(in, out)
+			s.maxContentLength = 16
+			require.NoError(t, s.Run(context.Background()))
+
+			msgs := readAll(t, out)
+			require.Len(t, msgs, 1)
+			require.NotNil(t, msgs[0].Error)
+			require.Equal(t, CodeParseError, msgs[0].Error.Code)
+		})
+	}
+}
+
+func TestServer_Exit(t *testing.T) {
+	in, out := &bytes.Buffer{}, &bytes.Buffer{}
+	frame(t, in, 0, "exit", nil)
+	require.ErrorIs(t, NewServer(in, out).Run(context.Background()), ErrExitWithoutShutdown)
+
+	in, out = &bytes.Buffer{}, &bytes.Buffer{}
+	frame(t, in, 1, "shutdown", nil)
+	frame(t, in, 0, "exit", nil)
+	// Messages after exit are not read.
+	frame(t, in, 2, "initialize", map[string]any{})
+	require.NoError(t, NewServer(in, out).Run(context.Background()))
+	msgs := readAll(t, out)
+	require.Len(t, msgs, 1)
+	require.Nil(t, msgs[0].Error)
+}
@@ -0,0 +1,690 @@
+package raml
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Headers of RAML fragments.
+const (
+	HeaderAPI          = "#%RAML 1.0"
+	HeaderLibrary      = "#%RAML 1.0 Library"
+	HeaderDataType     = "#%RAML 1.0 DataType"
+	HeaderNamedExample = "#%RAML 1.0 NamedExample"
+)
+
+const marshalIndent = 2
+
+// facetAnyOf is the facet of union members in signatures of canonical shapes.
+const facetAnyOf = "anyOf"
+
+// marshaller serializes the model to YAML nodes.
+type marshaller struct {
+	// reference returns the name to be written instead of the label of the shape that references another type or
+	// includes a data type. If false is returned, the label is written as is.
+	reference func(s *BaseShape) (string, bool)
+	// annotation returns the name of the annotation to be written instead of the key it was declared with.
+	annotation func(key string, de *DomainExtension) string
+	// inlineExamples writes included named examples in place.
+	inlineExamples bool
+	// skipUses omits "uses" of fragments.
+	skipUses bool
+	// anyOf writes members of unions that cannot be written as type expressions to the "anyOf" facet.
+	// NOTE: This is not RAML, it is only used to write signatures of canonical shapes.
+	anyOf bool
+}
+
+func newMarshaller() *marshaller {
+	return &marshaller{
+		reference: func(*BaseShape) (string, bool) { return "", false },
+		annotation: func(key string, _ *DomainExtension) string {
+			return key
+		},
+	}
+}
+
+// MarshalRAML serializes the library to RAML 1.0 YAML.
+// Keys are written in a stable order: declarations keep the order of the source, facets follow a fixed order.
+// NOTE: Unwrapped shapes are serialized with merged facets of their parents.
+func (l *Library) MarshalRAML() ([]byte, error) {
+	node, err := newMarshaller().library(l)
+	if err != nil {
+		return nil, fmt.Errorf("marshal library: %w", err)
+	}
+	return encodeRAML(HeaderLibrary, node)
+}
+
+func (ms *marshaller) library(l *Library) (*yaml.Node, error) {
+	m := newMappingNode()
+	if l.Usage != "" {
+		m.Content = append(m.Content, newStrNode("usage"), newStrNode(l.Usage))
+	}
+	if uses := ms.uses(l.Uses); uses != nil {
+		m.Content = append(m.Content, newStrNode("uses"), uses)
+	}
+	for _, decl := range []struct {
+		key   string
+		types *orderedmap.OrderedMap[string, *BaseShape]
+	}{
+		{key: "annotationTypes", types: l.AnnotationTypes},
+		{key: "types", types: l.Types},
+	} {
+		if decl.types == nil || decl.types.Len() == 0 {
+			continue
+		}
+		types := newMappingNode()
+		for pair := decl.types.Oldest(); pair != nil; pair = pair.Next() {
+			n, err := ms.shape(pair.Value)
+			if err != nil {
+				return nil, fmt.Errorf("marshal type %s: %w", pair.Key, err)
+			}
+			types.Content = append(types.Content, newStrNode(pair.Key), n)
+		}
+		m.Content = append(m.Content, newStrNode(decl.key), types)
+	}
+	if err := ms.annotations(m, l.CustomDomainProperties); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MarshalRAML serializes the data type fragment to RAML 1.0 YAML.
+func (dt *DataType) MarshalRAML() ([]byte, error) {
+	node, err := newMarshaller().dataType(dt)
+	if err != nil {
+		return nil, fmt.Errorf("marshal data type: %w", err)
+	}
+	return encodeRAML(HeaderDataType, node)
+}
+
+func (ms *marshaller) dataType(dt *DataType) (*yaml.Node, error) {
+	m := newMappingNode()
+	if dt.Usage != "" {
+		m.Content = append(m.Content, newStrNode("usage"), newStrNode(dt.Usage))
+	}
+	if uses := ms.uses(dt.Uses); uses != nil {
+		m.Content = append(m.Content, newStrNode("uses"), uses)
+	}
+	if dt.Shape == nil {
+		return m, nil
+	}
+	shapeNode, err := ms.shape(dt.Shape)
+	if err != nil {
+		return nil, fmt.Errorf("marshal shape: %w", err)
+	}
+	// Data type fragment always declares the shape in the expanded form.
+	if shapeNode.Kind != yaml.MappingNode {
+		shapeNode = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{newStrNode("type"), shapeNode}}
+	}
+	m.Content = append(m.Content, shapeNode.Content...)
+	return m, nil
+}
+
+// MarshalRAML serializes the named example fragment to RAML 1.0 YAML.
+func (ne *NamedExample) MarshalRAML() ([]byte, error) {
+	m, err := newMarshaller().exampleMap(ne.Map)
+	if err != nil {
+		return nil, fmt.Errorf("marshal named example: %w", err)
+	}
+	return encodeRAML(HeaderNamedExample, m)
+}
+
+// MarshalRAML serializes the shape declaration to RAML 1.0 YAML.
+// Shapes without facets are written in the short form of type expression, e.g. "string[]".
+func (s *BaseShape) MarshalRAML() ([]byte, error) {
+	node, err := newMarshaller().shape(s)
+	if err != nil {
+		return nil, fmt.Errorf("marshal shape: %w", err)
+	}
+	return encodeRAML("", node)
+}
+
+func encodeRAML(header string, node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if header != "" {
+		buf.WriteString(header + "\n")
+	}
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(marshalIndent)
+	if err := enc.Encode(node); err != nil {
+		return nil, fmt.Errorf("encode yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("close encoder: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func newMappingNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+func newStrNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: TagStr, Value: value}
+}
+
+func newValueNode(value any) (*yaml.Node, error) {
+	var n yaml.Node
+	if err := n.Encode(value); err != nil {
+		return nil, fmt.Errorf("encode value: %w", err)
+	}
+	return &n, nil
+}
+
+func (ms *marshaller) uses(uses *orderedmap.OrderedMap[string, *LibraryLink]) *yaml.Node {
+	if ms.skipUses || uses == nil || uses.Len() == 0 {
+		return nil
+	}
+	m := newMappingNode()
+	for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+		m.Content = append(m.Content, newStrNode(pair.Key), newStrNode(pair.Value.Value))
+	}
+	return m
+}
+
+func (ms *marshaller) annotations(m *yaml.Node, annotations *orderedmap.OrderedMap[string, *DomainExtension]) error {
+	for pair := annotations.Oldest(); pair != nil; pair = pair.Next() {
+		var value any
+		if pair.Value.Extension != nil {
+			value = pair.Value.Extension.Value
+		}
+		n, err := newValueNode(value)
+		if err != nil {
+			return fmt.Errorf("marshal annotation %s: %w", pair.Key, err)
+		}
+		m.Content = append(m.Content, newStrNode("("+ms.annotation(pair.Key, pair.Value)+")"), n)
+	}
+	return nil
+}
+
+func (ms *marshaller) exampleMap(examples *orderedmap.OrderedMap[string, *Example]) (*yaml.Node, error) {
+	m := newMappingNode()
+	for pair := examples.Oldest(); pair != nil; pair = pair.Next() {
+		n, err := ms.example(pair.Value)
+		if err != nil {
+			return nil, fmt.Errorf("example %s: %w", pair.Key, err)
+		}
+		m.Content = append(m.Content, newStrNode(pair.Key), n)
+	}
+	return m, nil
+}
+
+func (ms *marshaller) example(ex *Example) (*yaml.Node, error) {
+	var value any
+	if ex.Data != nil {
+		value = ex.Data.Value
+	}
+	data, err := newValueNode(value)
+	if err != nil {
+		return nil, err
+	}
+	// The value of the example is ambiguous if it is a map with the "value" key.
+	valueMap, _ := value.(map[string]any)
+	_, hasValueKey := valueMap["value"]
+	if ex.Strict && ex.DisplayName == "" && ex.Description == "" && ex.CustomDomainProperties.Len() == 0 &&
+		!hasValueKey {
+		return data, nil
+	}
+	m := newMappingNode()
+	if ex.DisplayName != "" {
+		m.Content = append(m.Content, newStrNode("displayName"), newStrNode(ex.DisplayName))
+	}
+	if ex.Description != "" {
+		m.Content = append(m.Content, newStrNode("description"), newStrNode(ex.Description))
+	}
+	if !ex.Strict {
+		m.Content = append(m.Content, newStrNode("strict"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool",
+			Value: "false"})
+	}
+	m.Content = append(m.Content, newStrNode("value"), data)
+	if err = ms.annotations(m, ex.CustomDomainProperties); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// typeExpression returns the type expression of the shape if it can be written in the short form.
+func (ms *marshaller) typeExpression(s *BaseShape) (string, bool) {
+	if name, ok := ms.reference(s); ok {
+		return name, true
+	}
+	if s.Link != nil {
+		return "", false
+	}
+	if s.TypeLabel != "" {
+		return s.TypeLabel, true
+	}
+	switch shape := s.Shape.(type) {
+	case *ArrayShape:
+		if shape.Items == nil {
+			return TypeArray, true
+		}
+		if !ms.isItemsExpression(shape) {
+			return "", false
+		}
+		items, _ := ms.typeExpression(shape.Items)
+		if _, isUnion := shape.Items.Shape.(*UnionShape); isUnion && strings.Contains(items, "|") {
+			// Postfix notations bind tighter than unions.
+			items = "(" + items + ")"
+		}
+		return items + "[]", true
+	case *UnionShape:
+		members := make([]string, len(shape.AnyOf))
+		for i, member := range shape.AnyOf {
+			if !ms.isTypeExpression(member) {
+				return "", false
+			}
+			members[i], _ = ms.typeExpression(member)
+		}
+		return strings.Join(members, " | "), true
+	case *RecursiveShape:
+		return shape.Head.Name, true
+	case *JSONShape:
+		return shape.Raw, true
+	}
+	if len(s.Inherits) > 0 || s.Type == TypeComposite {
+		return "", false
+	}
+	return s.Type, true
+}
+
+// isTypeExpression returns true if the shape can be fully written as a type expression.
+func (ms *marshaller) isTypeExpression(s *BaseShape) bool {
+	if ms.hasFacets(s) {
+		return false
+	}
+	_, ok := ms.typeExpression(s)
+	return ok
+}
+
+// isAnyOf returns true if members of the union are written to the "anyOf" facet.
+func (ms *marshaller) isAnyOf(s *BaseShape) bool {
+	if !ms.anyOf || s.TypeLabel != "" {
+		return false
+	}
+	if _, ok := s.Shape.(*UnionShape); !ok {
+		return false
+	}
+	_, ok := ms.typeExpression(s)
+	return !ok
+}
+
+// isItemsExpression returns true if the items can be written in the array type expression, e.g. "string[]" or
+// "(string | integer)[]".
+func (ms *marshaller) isItemsExpression(s *ArrayShape) bool {
+	return ms.isTypeExpression(s.Items)
+}
+
+// hasFacets returns true if the shape declares anything besides its type.
+func (ms *marshaller) hasFacets(s *BaseShape) bool {
+	if s.DisplayName != nil || s.Description != nil || s.Required != nil || s.Default != nil || s.XML != nil ||
+		s.Example != nil || s.Examples != nil || s.CustomShapeFacets.Len() > 0 ||
+		s.CustomShapeFacetDefinitions.Len() > 0 || s.CustomDomainProperties.Len() > 0 {
+		return true
+	}
+	switch shape := s.Shape.(type) {
+	case *StringShape:
+		return shape.Enum != nil || shape.MinLength != nil || shape.MaxLength != nil || shape.Pattern != nil
+	case *NumberShape:
+		return shape.Enum != nil || shape.Format != nil || shape.Minimum != nil || shape.Maximum != nil ||
+			shape.MultipleOf != nil
+	case *IntegerShape:
+		return shape.Enum != nil || shape.Format != nil || shape.Minimum != nil || shape.Maximum != nil ||
+			shape.MultipleOf != nil
+	case *BooleanShape:
+		return shape.Enum != nil
+	case *DateTimeShape:
+		return shape.Enum != nil || shape.Format != nil
+	case *DateTimeOnlyShape:
+		return shape.Enum != nil
+	case *DateOnlyShape:
+		return shape.Enum != nil
+	case *TimeOnlyShape:
+		return shape.Enum != nil
+	case *FileShape:
+		return shape.FileTypes != nil || shape.MinLength != nil || shape.MaxLength != nil
+	case *UnionShape:
+		return shape.Enum != nil
+	case *ArrayShape:
+		return shape.MinItems != nil || shape.MaxItems != nil || shape.UniqueItems != nil ||
+			shape.Items != nil && s.TypeLabel == "" && !ms.isItemsExpression(shape)
+	case *ObjectShape:
+		return shape.Properties.Len() > 0 || shape.PatternProperties.Len() > 0 ||
+			shape.AdditionalProperties != nil || shape.MinProperties != nil || shape.MaxProperties != nil ||
+			shape.Discriminator != nil || shape.DiscriminatorValue != nil
+	}
+	return false
+}
+
+func (ms *marshaller) shape(s *BaseShape) (*yaml.Node, error) {
+	if s.Shape == nil {
+		return nil, fmt.Errorf("shape is nil")
+	}
+	if !ms.hasFacets(s) {
+		if expr, ok := ms.typeExpression(s); ok {
+			return newStrNode(expr), nil
+		}
+		if s.Link != nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: TagInclude, Value: s.TypeLabel}, nil
+		}
+	}
+
+	m := newMappingNode()
+	typeNode, err := ms.typeNode(s)
+	if err != nil {
+		return nil, err
+	}
+	if typeNode != nil {
+		m.Content = append(m.Content, newStrNode("type"), typeNode)
+	}
+	if s.DisplayName != nil {
+		m.Content = append(m.Content, newStrNode("displayName"), newStrNode(*s.DisplayName))
+	}
+	if s.Description != nil {
+		m.Content = append(m.Content, newStrNode("description"), newStrNode(*s.Description))
+	}
+	if s.Required != nil {
+		m.Content = append(m.Content, newStrNode("required"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool",
+			Value: fmt.Sprint(*s.Required)})
+	}
+	if s.Default != nil {
+		n, errDefault := newValueNode(s.Default.Value)
+		if errDefault != nil {
+			return nil, fmt.Errorf("marshal default: %w", errDefault)
+		}
+		m.Content = append(m.Content, newStrNode("default"), n)
+	}
+	if s.XML != nil {
+		f := &facetWriter{ms: ms}
+		f.value(XMLFacetAttribute, s.XML.Attribute)
+		f.value(XMLFacetWrapped, s.XML.Wrapped)
+		f.value(XMLFacetName, s.XML.Name)
+		f.value(XMLFacetNamespace, s.XML.Namespace)
+		f.value(XMLFacetPrefix, s.XML.Prefix)
+		if f.err != nil {
+			return nil, fmt.Errorf("marshal xml: %w", f.err)
+		}
+		m.Content = append(m.Content, newStrNode("xml"), &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map",
+			Content: f.content})
+	}
+	facets, err := ms.facets(s)
+	if err != nil {
+		return nil, err
+	}
+	m.Content = append(m.Content, facets...)
+	if err = ms.examples(m, s); err != nil {
+		return nil, err
+	}
+	if s.CustomShapeFacetDefinitions.Len() > 0 {
+		defs := newMappingNode()
+		for pair := s.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
+			n, errProp := ms.shape(pair.Value.Shape)
+			if errProp != nil {
+				return nil, fmt.Errorf("marshal facet %s: %w", pair.Key, errProp)
+			}
+			defs.Content = append(defs.Content, newStrNode(pair.Value.marshalKey()), n)
+		}
+		m.Content = append(m.Content, newStrNode("facets"), defs)
+	}
+	for pair := s.CustomShapeFacets.Oldest(); pair != nil; pair = pair.Next() {
+		n, errFacet := newValueNode(pair.Value.Value)
+		if errFacet != nil {
+			return nil, fmt.Errorf("marshal custom facet %s: %w", pair.Key, errFacet)
+		}
+		m.Content = append(m.Content, newStrNode(pair.Key), n)
+	}
+	if err = ms.annotations(m, s.CustomDomainProperties); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (ms *marshaller) typeNode(s *BaseShape) (*yaml.Node, error) {
+	if s.Link != nil {
+		if name, ok := ms.reference(s); ok {
+			return newStrNode(name), nil
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: TagInclude, Value: s.TypeLabel}, nil
+	}
+	if s.TypeLabel == "" && len(s.Inherits) > 0 {
+		// Multiple inheritance
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+		for _, parent := range s.Inherits {
+			expr, ok := ms.typeExpression(parent)
+			if !ok {
+				return nil, fmt.Errorf("parent type of %s cannot be written as type expression", s.Name)
+			}
+			seq.Content = append(seq.Content, newStrNode(expr))
+		}
+		return seq, nil
+	}
+	if arr, ok := s.Shape.(*ArrayShape); ok && s.TypeLabel == "" {
+		// Items are written as a separate facet.
+		if arr.Items != nil && !ms.isItemsExpression(arr) {
+			return newStrNode(TypeArray), nil
+		}
+	}
+	expr, ok := ms.typeExpression(s)
+	if !ok && ms.isAnyOf(s) {
+		return newStrNode(TypeUnion), nil
+	}
+	if !ok {
+		return nil, fmt.Errorf("type of %s cannot be written as type expression", s.Name)
+	}
+	return newStrNode(expr), nil
+}
+
+func (ms *marshaller) examples(m *yaml.Node, s *BaseShape) error {
+	if s.Example != nil {
+		n, err := ms.example(s.Example)
+		if err != nil {
+			return fmt.Errorf("marshal example: %w", err)
+		}
+		m.Content = append(m.Content, newStrNode("example"), n)
+	}
+	if s.Examples == nil {
+		return nil
+	}
+	if s.Examples.Link != nil && !ms.inlineExamples {
+		path, err := filepath.Rel(filepath.Dir(s.Location()), s.Examples.Link.Location)
+		if err != nil {
+			return fmt.Errorf("marshal examples: relative path: %w", err)
+		}
+		m.Content = append(m.Content, newStrNode("examples"),
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: TagInclude, Value: filepath.ToSlash(path)})
+		return nil
+	}
+	examples, err := ms.exampleMap(s.Examples.Map)
+	if err != nil {
+		return fmt.Errorf("marshal examples: %w", err)
+	}
+	m.Content = append(m.Content, newStrNode("examples"), examples)
+	return nil
+}
+
+// marshalFacets returns key-value nodes of facets specific to the shape type.
+func (ms *marshaller) facets(s *BaseShape) ([]*yaml.Node, error) {
+	f := &facetWriter{ms: ms}
+	switch shape := s.Shape.(type) {
+	case *StringShape:
+		f.enum(shape.Enum)
+		f.value(FacetMinLength, shape.MinLength)
+		f.value(FacetMaxLength, shape.MaxLength)
+		if shape.Pattern != nil {
+			f.value(FacetPattern, &[]string{shape.Pattern.String()}[0])
+		}
+	case *NumberShape:
+		f.enum(shape.Enum)
+		f.value(FacetFormat, shape.Format)
+		f.value(FacetMinimum, shape.Minimum)
+		f.value(FacetMaximum, shape.Maximum)
+		f.value(FacetMultipleOf, shape.MultipleOf)
+	case *IntegerShape:
+		f.enum(shape.Enum)
+		f.value(FacetFormat, shape.Format)
+		f.bigInt(FacetMinimum, shape.Minimum)
+		f.bigInt(FacetMaximum, shape.Maximum)
+		f.value(FacetMultipleOf, shape.MultipleOf)
+	case *BooleanShape:
+		f.enum(shape.Enum)
+	case *DateTimeShape:
+		f.enum(shape.Enum)
+		f.value(FacetFormat, shape.Format)
+	case *DateTimeOnlyShape:
+		f.enum(shape.Enum)
+	case *DateOnlyShape:
+		f.enum(shape.Enum)
+	case *TimeOnlyShape:
+		f.enum(shape.Enum)
+	case *FileShape:
+		f.nodes(FacetFileTypes, shape.FileTypes)
+		f.value(FacetMinLength, shape.MinLength)
+		f.value(FacetMaxLength, shape.MaxLength)
+	case *UnionShape:
+		if ms.isAnyOf(s) {
+			seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			for _, member := range shape.AnyOf {
+				n, err := ms.shape(member)
+				if err != nil {
+					return nil, fmt.Errorf("marshal union member: %w", err)
+				}
+				seq.Content = append(seq.Content, n)
+			}
+			f.content = append(f.content, newStrNode(facetAnyOf), seq)
+		}
+		f.enum(shape.Enum)
+	case *ArrayShape:
+		if shape.Items != nil && s.TypeLabel == "" && !ms.isItemsExpression(shape) {
+			n, err := ms.shape(shape.Items)
+			if err != nil {
+				return nil, fmt.Errorf("marshal items: %w", err)
+			}
+			f.content = append(f.content, newStrNode(FacetItems), n)
+		}
+		f.value(FacetMinItems, shape.MinItems)
+		f.value(FacetMaxItems, shape.MaxItems)
+		f.value(FacetUniqueItems, shape.UniqueItems)
+	case *ObjectShape:
+		if err := f.properties(shape); err != nil {
+			return nil, err
+		}
+		f.value(FacetAdditionalProperties, shape.AdditionalProperties)
+		f.value(FacetMinProperties, shape.MinProperties)
+		f.value(FacetMaxProperties, shape.MaxProperties)
+		f.value(FacetDiscriminator, shape.Discriminator)
+		if shape.DiscriminatorValue != nil {
+			f.value(FacetDiscriminatorValue, &shape.DiscriminatorValue)
+		}
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.content, nil
+}
+
+// facetWriter accumulates key-value nodes of facets and the first error that occurred.
+type facetWriter struct {
+	ms      *marshaller
+	content []*yaml.Node
+	err     error
+}
+
+func (f *facetWriter) add(key string, value any) {
+	if f.err != nil {
+		return
+	}
+	n, err := newValueNode(value)
+	if err != nil {
+		f.err = fmt.Errorf("marshal facet %s: %w", key, err)
+		return
+	}
+	f.content = append(f.content, newStrNode(key), n)
+}
+
+func (f *facetWriter) value(key string, value any) {
+	// Typed nil pointers are skipped.
+	switch v := value.(type) {
+	case *uint64:
+		if v != nil {
+			f.add(key, *v)
+		}
+	case *float64:
+		if v != nil {
+			f.add(key, *v)
+		}
+	case *bool:
+		if v != nil {
+			f.add(key, *v)
+		}
+	case *string:
+		if v != nil {
+			f.add(key, *v)
+		}
+	case *any:
+		if v != nil {
+			f.add(key, *v)
+		}
+	default:
+		f.err = fmt.Errorf("marshal facet %s: unsupported type %T", key, value)
+	}
+}
+
+func (f *facetWriter) bigInt(key string, value *big.Int) {
+	if value == nil {
+		return
+	}
+	f.content = append(f.content, newStrNode(key), &yaml.Node{Kind: yaml.ScalarNode, Tag: TagInt,
+		Value: value.String()})
+}
+
+func (f *facetWriter) nodes(key string, nodes Nodes) {
+	if nodes == nil {
+		return
+	}
+	values := make([]any, len(nodes))
+	for i, n := range nodes {
+		values[i] = n.Value
+	}
+	f.add(key, values)
+}
+
+func (f *facetWriter) enum(nodes Nodes) {
+	f.nodes(FacetEnum, nodes)
+}
+
+func (f *facetWriter) properties(shape *ObjectShape) error {
+	if shape.Properties.Len() == 0 && shape.PatternProperties.Len() == 0 {
+		return nil
+	}
+	m := newMappingNode()
+	for pair := shape.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		n, err := f.ms.shape(pair.Value.Shape)
+		if err != nil {
+			return fmt.Errorf("marshal property %s: %w", pair.Key, err)
+		}
+		m.Content = append(m.Content, newStrNode(pair.Value.marshalKey()), n)
+	}
+	for pair := shape.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		n, err := f.ms.shape(pair.Value.Shape)
+		if err != nil {
+			return fmt.Errorf("marshal pattern property %s: %w", pair.Key, err)
+		}
+		m.Content = append(m.Content, newStrNode(pair.Key), n)
+	}
+	f.content = append(f.content, newStrNode(FacetProperties), m)
+	return nil
+}
+
+// marshalKey returns the property key, optional properties without explicit "required" facet are marked with "?".
+func (p Property) marshalKey() string {
+	if p.Shape.Required == nil && !p.Required {
+		return p.Name + "?"
+	}
+	return p.Name
+}
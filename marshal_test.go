@@ -0,0 +1,136 @@
+package raml
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibrary_MarshalRAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name: "type expressions",
+			content: `#%RAML 1.0 Library
+types:
+  A: string
+  B: A[]
+  C:
+    type: array
+    items: string | integer
+  D: string?
+  E:
+    type: array
+    items:
+      type: string
+      minLength: 1
+  F: (A | integer[])[][]?
+`,
+			want: `#%RAML 1.0 Library
+types:
+  A: string
+  B: A[]
+  C: (string | integer)[]
+  D: string | nil
+  E:
+    type: array
+    items:
+      type: string
+      minLength: 1
+  F: (A | integer[])[][] | nil
+`,
+		},
+		{
+			name: "facets in stable order",
+			content: `#%RAML 1.0 Library
+usage: Test
+annotationTypes:
+  Tag: string
+types:
+  Obj:
+    (Tag): value
+    maxProperties: 3
+    additionalProperties: false
+    description: Object
+    properties:
+      /^x-/: string
+      name:
+        maxLength: 10
+        type: string
+      age?: integer
+      id:
+        type: integer
+        required: false
+    example:
+      name: test
+  Int:
+    type: integer
+    maximum: 10
+    minimum: -1
+    format: int8
+  Parents: [Obj, Int]
+`,
+			want: `#%RAML 1.0 Library
+usage: Test
+annotationTypes:
+  Tag: string
+types:
+  Obj:
+    type: object
+    description: Object
+    properties:
+      name:
+        type: string
+        maxLength: 10
+      age?: integer
+      id:
+        type: integer
+        required: false
+      /^x-/: string
+    additionalProperties: false
+    maxProperties: 3
+    example:
+      name: test
+    (Tag): value
+  Int:
+    type: integer
+    format: int8
+    minimum: -1
+    maximum: 10
+  Parents:
+    type: [Obj, Int]
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rml, err := ParseFromString(tt.content, "library.raml", t.TempDir())
+			require.NoError(t, err)
+			lib, ok := rml.EntryPoint().(*Library)
+			require.True(t, ok)
+			got, err := lib.MarshalRAML()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestLibrary_MarshalRAML_RoundTrip(t *testing.T) {
+	rml, err := ParseFromPath("./fixtures/library.raml", OptWithValidate())
+	require.NoError(t, err)
+	first, err := rml.EntryPoint().(*Library).MarshalRAML()
+	require.NoError(t, err)
+
+	// Serialized library must be valid and produce the same output.
+	baseDir, err := filepath.Abs("./fixtures")
+	require.NoError(t, err)
+	rml, err = ParseFromString(string(first), "library.raml", baseDir, OptWithValidate())
+	require.NoError(t, err)
+	second, err := rml.EntryPoint().(*Library).MarshalRAML()
+	require.NoError(t, err)
+	require.Equal(t, string(first), string(second))
+}
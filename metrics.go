@@ -0,0 +1,100 @@
+package raml
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Counter is a monotonically increasing metric. prometheus.Counter implements it.
+type Counter interface {
+	Add(float64)
+}
+
+// Observer records observations of a metric, e.g. durations in seconds. prometheus.Histogram and prometheus.Summary
+// implement it.
+type Observer interface {
+	Observe(float64)
+}
+
+// Metrics are instrumentation points of the parsing pipeline and of validation, so services that embed runtime
+// validation can monitor the costs of processing specifications. Nil fields are not collected. Metrics of validation
+// are updated concurrently if shapes are validated concurrently, so implementations must be safe for concurrent use.
+type Metrics struct {
+	// FragmentsParsed counts decoded fragments.
+	FragmentsParsed Counter
+	// ShapesResolved counts shapes whose references are resolved by linking.
+	ShapesResolved Counter
+	// FragmentCacheHits counts fragments of uses and !include that are already decoded and are reused.
+	FragmentCacheHits Counter
+	// PatternCacheHits counts patterns that share a compiled regular expression with identical patterns.
+	PatternCacheHits Counter
+	// Validations counts values validated by BaseShape.Validate and BaseShape.ValidateWithOptions, including
+	// examples, defaults and annotations validated by RAML.Check, and ValidationFailures counts the invalid ones.
+	Validations        Counter
+	ValidationFailures Counter
+	// ValidationDuration observes durations of validations in seconds.
+	ValidationDuration Observer
+	// ParseDuration, LinkDuration, ResolveDuration and CheckDuration observe durations of the pipeline stages in
+	// seconds, see Timings.
+	ParseDuration   Observer
+	LinkDuration    Observer
+	ResolveDuration Observer
+	CheckDuration   Observer
+}
+
+type parseOptWithMetrics struct {
+	metrics Metrics
+}
+
+func (o parseOptWithMetrics) Apply(opt *parserOptions) {
+	opt.metrics = o.metrics
+}
+
+// OptWithMetrics collects the metrics of parsing and of validation by shapes of the parsed model, e.g. into
+// Prometheus collectors:
+//
+//	validations := prometheus.NewCounter(prometheus.CounterOpts{Name: "raml_validations_total"})
+//	raml.OptWithMetrics(raml.Metrics{Validations: validations})
+func OptWithMetrics(m Metrics) ParseOpt {
+	return parseOptWithMetrics{metrics: m}
+}
+
+func incCounter(c Counter) {
+	if c != nil {
+		c.Add(1)
+	}
+}
+
+func observeDuration(o Observer, start time.Time) {
+	if o != nil {
+		o.Observe(time.Since(start).Seconds())
+	}
+}
+
+// fragmentReused counts the hit of the fragment cache.
+func (r *RAML) fragmentReused(location string) {
+	r.log(slog.LevelDebug, "reusing fragment", slog.String("location", location))
+	incCounter(r.metrics.FragmentCacheHits)
+}
+
+// validationStart returns the start time of validation by the shape if its duration is observed. The receiver may be
+// nil for shapes that do not belong to a RAML.
+func (r *RAML) validationStart() time.Time {
+	if r == nil || r.metrics.ValidationDuration == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+func (r *RAML) validationDone(start time.Time, err error) {
+	if r == nil {
+		return
+	}
+	incCounter(r.metrics.Validations)
+	if err != nil {
+		incCounter(r.metrics.ValidationFailures)
+	}
+	if !start.IsZero() {
+		observeDuration(r.metrics.ValidationDuration, start)
+	}
+}
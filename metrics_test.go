@@ -0,0 +1,94 @@
+package raml
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCounter struct {
+	mu    sync.Mutex
+	value float64
+	count int
+}
+
+func (c *testCounter) Add(v float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value += v
+}
+
+func (c *testCounter) Observe(float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count++
+}
+
+func TestOptWithMetrics(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common.raml"), []byte(`#%RAML 1.0 Library
+types:
+  Id:
+    type: string
+    pattern: ^[a-z]+$
+`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pet.raml"), []byte(`#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Pet:
+    properties:
+      id: common.Id
+      name:
+        pattern: ^[a-z]+$
+`), 0o600))
+	content := `#%RAML 1.0 Library
+uses:
+  common: common.raml
+  pet: pet.raml
+types:
+  Owner:
+    properties:
+      id: common.Id
+      pets: pet.Pet[]
+`
+	var (
+		fragments, resolved, fragmentHits, patternHits = &testCounter{}, &testCounter{}, &testCounter{}, &testCounter{}
+		validations, failures, durations, stages       = &testCounter{}, &testCounter{}, &testCounter{}, &testCounter{}
+	)
+	rml, err := ParseFromString(content, "library.raml", dir, OptWithUnwrap(), OptWithMetrics(Metrics{
+		FragmentsParsed:    fragments,
+		ShapesResolved:     resolved,
+		FragmentCacheHits:  fragmentHits,
+		PatternCacheHits:   patternHits,
+		Validations:        validations,
+		ValidationFailures: failures,
+		ValidationDuration: durations,
+		ParseDuration:      stages,
+		LinkDuration:       stages,
+		ResolveDuration:    stages,
+		CheckDuration:      stages,
+	}))
+	require.NoError(t, err)
+	require.Equal(t, 3.0, fragments.value)
+	require.Positive(t, resolved.value)
+	require.Equal(t, 1.0, fragmentHits.value)
+	require.Equal(t, 1.0, patternHits.value)
+	require.Equal(t, 3, stages.count)
+	require.Zero(t, validations.value)
+
+	owner, err := rml.LookupType("Owner", "")
+	require.NoError(t, err)
+	require.NoError(t, owner.Validate(map[string]any{"id": "abc", "pets": []any{}}))
+	_, err = owner.ValidateWithOptions(map[string]any{"id": "ABC", "pets": []any{}}, ValidateOptions{})
+	require.Error(t, err)
+	require.Equal(t, 2.0, validations.value)
+	require.Equal(t, 1.0, failures.value)
+	require.Equal(t, 2, durations.count)
+
+	require.NoError(t, rml.Check())
+	require.Equal(t, 4, stages.count)
+}
@@ -0,0 +1,159 @@
+// Package mock provides an HTTP server that mocks data types declared in a RAML model.
+//
+// The server exposes declared types rather than resources of API definitions:
+//
+//	GET  /types          lists names of declared types
+//	GET  /types/{name}   returns an example of the type, ?example={example} selects a named example
+//	POST /types/{name}   validates the JSON request body against the type
+//
+// Types are named as in the entry point, e.g. "Pet" or "common.Pet" for a type of the used library.
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/acronis/go-raml"
+)
+
+const typesPath = "/types"
+
+// Server is an http.Handler that serves examples of declared types and validates request bodies against them.
+// It is safe for concurrent use.
+type Server struct {
+	rml *raml.RAML
+
+	mu sync.Mutex
+	// shapes contains unwrapped shapes by the name of the type.
+	shapes map[string]*raml.BaseShape
+}
+
+// NewServer creates a new mock server for the parsed RAML model.
+func NewServer(rml *raml.RAML) *Server {
+	return &Server{
+		rml:    rml,
+		shapes: make(map[string]*raml.BaseShape),
+	}
+}
+
+// ErrorResponse is the body of responses with error status codes.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == typesPath {
+		if req.Method != http.MethodGet {
+			writeMethodNotAllowed(w, http.MethodGet)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.typeNames())
+		return
+	}
+
+	name, ok := strings.CutPrefix(req.URL.Path, typesPath+"/")
+	if !ok || name == "" {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not found"})
+		return
+	}
+	shape, err := s.shape(name)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		s.example(w, shape, req.URL.Query().Get("example"))
+	case http.MethodPost:
+		s.validate(w, req, shape)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (s *Server) example(w http.ResponseWriter, shape *raml.BaseShape, name string) {
+	if name != "" {
+		ex, err := shape.LookupExample(name)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, ex.Value())
+		return
+	}
+	v, err := raml.Generate(shape.Shape, raml.WithGenerateExamples())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) validate(w http.ResponseWriter, req *http.Request, shape *raml.BaseShape) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("read body: %s", err)})
+		return
+	}
+	var value any
+	if err = json.Unmarshal(body, &value); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("decode body: %s", err)})
+		return
+	}
+	if err = shape.Validate(value); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) typeNames() []string {
+	nodes := s.rml.TypeGraph().Nodes
+	names := make([]string, len(nodes))
+	for i, node := range nodes {
+		names[i] = node.Name
+	}
+	return names
+}
+
+// shape returns the unwrapped shape of the type. Unwrapping is done on a detached copy in a scratch model to keep
+// the served model intact.
+func (s *Server) shape(name string) (*raml.BaseShape, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if shape, ok := s.shapes[name]; ok {
+		return shape, nil
+	}
+	shape, err := s.rml.LookupType(name, "")
+	if err != nil {
+		return nil, fmt.Errorf("lookup type: %w", err)
+	}
+	if !shape.IsUnwrapped() {
+		shape, err = raml.New(context.Background()).UnwrapShape(shape.CloneDetached())
+		if err != nil {
+			return nil, fmt.Errorf("unwrap type %s: %w", name, err)
+		}
+	}
+	s.shapes[name] = shape
+	return shape, nil
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// The status is already written, so encoding errors cannot be reported to the client.
+	_ = json.NewEncoder(w).Encode(v)
+}
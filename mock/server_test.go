@@ -0,0 +1,107 @@
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-raml"
+)
+
+func TestServer(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Tag:
+    type: string
+    enum: [small, big]
+    examples:
+      first: small
+      second: big
+  Node:
+    type: object
+    properties:
+      children?: Node[]
+  Pet:
+    type: object
+    discriminator: kind
+    properties:
+      kind: string
+      name:
+        type: string
+        minLength: 3
+      age:
+        type: integer
+        minimum: 1
+      tags:
+        type: array
+        items: Tag
+        minItems: 1
+      owner?: string
+      parent: Node
+  Cat:
+    type: Pet
+    example:
+      kind: Cat
+      name: Tom
+      age: 3
+      tags: [small]
+      parent: {}
+`
+	rml, err := raml.ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+	srv := httptest.NewServer(NewServer(rml))
+	defer srv.Close()
+
+	do := func(method, path, body string) (int, any) {
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var v any
+		if resp.StatusCode != http.StatusNoContent {
+			require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&v))
+		}
+		return resp.StatusCode, v
+	}
+
+	status, v := do(http.MethodGet, "/types", "")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, []any{"Cat", "Node", "Pet", "Tag"}, v)
+
+	status, v = do(http.MethodGet, "/types/Pet", "")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, map[string]any{
+		"kind": "Pet", "name": "aaa", "age": 1.0, "tags": []any{"small"}, "parent": map[string]any{},
+	}, v)
+	raw, err := json.Marshal(v)
+	require.NoError(t, err)
+	status, _ = do(http.MethodPost, "/types/Pet", string(raw))
+	require.Equal(t, http.StatusNoContent, status)
+
+	status, v = do(http.MethodGet, "/types/Cat", "")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "Tom", v.(map[string]any)["name"])
+
+	status, v = do(http.MethodPost, "/types/Tag", `"huge"`)
+	require.Equal(t, http.StatusUnprocessableEntity, status)
+	require.Contains(t, v.(map[string]any)["error"], "must be one of")
+
+	status, v = do(http.MethodGet, "/types/Tag?example=second", "")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "big", v)
+	status, _ = do(http.MethodGet, "/types/Tag?example=third", "")
+	require.Equal(t, http.StatusNotFound, status)
+
+	status, _ = do(http.MethodPost, "/types/Tag", `{`)
+	require.Equal(t, http.StatusBadRequest, status)
+	status, _ = do(http.MethodGet, "/types/Dog", "")
+	require.Equal(t, http.StatusNotFound, status)
+	status, _ = do(http.MethodDelete, "/types/Tag", "")
+	require.Equal(t, http.StatusMethodNotAllowed, status)
+}
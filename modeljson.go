@@ -0,0 +1,519 @@
+package raml
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+type modelJSONOptions struct {
+	positions bool
+}
+
+type ModelJSONOpt interface {
+	Apply(*modelJSONOptions)
+}
+
+type modelJSONOptWithPositions struct{}
+
+func (modelJSONOptWithPositions) Apply(opt *modelJSONOptions) {
+	opt.positions = true
+}
+
+// OptModelJSONWithPositions writes locations, lines and columns of shapes and examples.
+func OptModelJSONWithPositions() ModelJSONOpt {
+	return modelJSONOptWithPositions{}
+}
+
+// jsonObject is a JSON object that keeps the order of its keys.
+type jsonObject = orderedmap.OrderedMap[string, any]
+
+// modelWriter converts the model to JSON objects.
+type modelWriter struct {
+	positions bool
+	// visiting contains shapes being written to detect cycles.
+	visiting map[*BaseShape]struct{}
+}
+
+func newModelWriter(opts ...ModelJSONOpt) *modelWriter {
+	o := &modelJSONOptions{}
+	for _, opt := range opts {
+		opt.Apply(o)
+	}
+	return &modelWriter{positions: o.positions, visiting: make(map[*BaseShape]struct{})}
+}
+
+// MarshalJSON serializes the RAML to the JSON model without positions.
+func (r *RAML) MarshalJSON() ([]byte, error) {
+	return r.ModelJSON()
+}
+
+// ModelJSON serializes the entry point and all fragments of the RAML to the JSON model, a stable representation of
+// the parsed model for tools that do not link Go. Keys of objects are written in a fixed order, declarations,
+// properties and annotations keep the order of the source, fragments are sorted by location and absent facets are
+// omitted, so parsing the same files twice yields the same bytes.
+//
+// The RAML is written as {"entryPoint": location, "fragments": [fragment...]}.
+//
+// A fragment has the keys "kind" (Library, API, DataType or NamedExample), "location", "usage",
+// "uses" ({alias: location}), "annotationTypes" and "types" ({name: shape}), "annotations" ({name: value}),
+// "shape" of data types and "examples" ({name: example}) of named examples.
+//
+// A shape has the keys "name", "type" (the kind of the shape, e.g. "string", "object" or "union"), "declaredType"
+// (the referenced type or included file as written, e.g. "common.Id"), "inherits" (names or type expressions of parents), "link"
+// (location of an included data type), "displayName", "description",
+// "required", "default", "xml", the facets of its type, "facets" (definitions of custom facets, {name: property}),
+// "customFacets" ({name: value}), "example", "examples" and "annotations". Facets keep their RAML names, "items" and
+// "anyOf" contain shapes and "properties" map names to {"required": bool, "shape": shape}. A JSON schema is written
+// to "schema" and a recursive reference is written as {"type": "recursive", "head": type the recursion returns to}.
+//
+// An example has the keys "name", "displayName", "description", "strict", "value" and "annotations".
+//
+// Parse with OptWithUnwrap to write the effective facets of types, i.e. the facets merged with their parents.
+// Otherwise shapes only contain the facets they declare. Positions are written to "location", "line" and "column"
+// of shapes and examples if the model is serialized with OptModelJSONWithPositions.
+func (r *RAML) ModelJSON(opts ...ModelJSONOpt) ([]byte, error) {
+	mw := newModelWriter(opts...)
+	locations := make([]string, 0, len(r.fragmentsCache))
+	for loc := range r.fragmentsCache {
+		locations = append(locations, loc)
+	}
+	slices.Sort(locations)
+	fragments := make([]any, 0, len(locations))
+	for _, loc := range locations {
+		fragment := r.fragmentsCache[loc]
+		o, err := mw.fragment(r.fragmentKind(fragment), fragment)
+		if err != nil {
+			return nil, fmt.Errorf("marshal fragment %s: %w", loc, err)
+		}
+		fragments = append(fragments, o)
+	}
+	o := orderedmap.New[string, any]()
+	o.Set("entryPoint", r.GetLocation())
+	o.Set("fragments", fragments)
+	return json.Marshal(o)
+}
+
+// MarshalJSON serializes the library to the JSON model.
+func (l *Library) MarshalJSON() ([]byte, error) {
+	o, err := newModelWriter().fragment(FragmentLibrary, l)
+	if err != nil {
+		return nil, fmt.Errorf("marshal library: %w", err)
+	}
+	return json.Marshal(o)
+}
+
+// MarshalJSON serializes the data type fragment to the JSON model.
+func (dt *DataType) MarshalJSON() ([]byte, error) {
+	o, err := newModelWriter().fragment(FragmentDataType, dt)
+	if err != nil {
+		return nil, fmt.Errorf("marshal data type: %w", err)
+	}
+	return json.Marshal(o)
+}
+
+// MarshalJSON serializes the named example fragment to the JSON model.
+func (ne *NamedExample) MarshalJSON() ([]byte, error) {
+	o, err := newModelWriter().fragment(FragmentNamedExample, ne)
+	if err != nil {
+		return nil, fmt.Errorf("marshal named example: %w", err)
+	}
+	return json.Marshal(o)
+}
+
+// MarshalJSON serializes the shape to the JSON model without positions.
+func (s *BaseShape) MarshalJSON() ([]byte, error) {
+	return s.ModelJSON()
+}
+
+// ModelJSON serializes the shape to the JSON model.
+func (s *BaseShape) ModelJSON(opts ...ModelJSONOpt) ([]byte, error) {
+	o, err := newModelWriter(opts...).shape(s)
+	if err != nil {
+		return nil, fmt.Errorf("marshal shape: %w", err)
+	}
+	return json.Marshal(o)
+}
+
+func (mw *modelWriter) fragment(kind FragmentKind, fragment Fragment) (*jsonObject, error) {
+	o := orderedmap.New[string, any]()
+	o.Set("kind", kind.String())
+	o.Set("location", fragment.GetLocation())
+	switch f := fragment.(type) {
+	case *Library:
+		setNonEmpty(o, "usage", f.Usage)
+		mw.uses(o, f.Uses)
+		for _, decl := range []struct {
+			key   string
+			types *orderedmap.OrderedMap[string, *BaseShape]
+		}{
+			{key: "annotationTypes", types: f.AnnotationTypes},
+			{key: "types", types: f.Types},
+		} {
+			if decl.types.Len() == 0 {
+				continue
+			}
+			types := orderedmap.New[string, any]()
+			for pair := decl.types.Oldest(); pair != nil; pair = pair.Next() {
+				s, err := mw.shape(pair.Value)
+				if err != nil {
+					return nil, fmt.Errorf("type %s: %w", pair.Key, err)
+				}
+				types.Set(pair.Key, s)
+			}
+			o.Set(decl.key, types)
+		}
+		mw.annotations(o, f.CustomDomainProperties)
+	case *DataType:
+		setNonEmpty(o, "usage", f.Usage)
+		mw.uses(o, f.Uses)
+		if f.Shape != nil {
+			s, err := mw.shape(f.Shape)
+			if err != nil {
+				return nil, fmt.Errorf("shape: %w", err)
+			}
+			o.Set("shape", s)
+		}
+	case *NamedExample:
+		o.Set("examples", mw.exampleMap(f.Map))
+	}
+	return o, nil
+}
+
+func (mw *modelWriter) uses(o *jsonObject, uses *orderedmap.OrderedMap[string, *LibraryLink]) {
+	if uses.Len() == 0 {
+		return
+	}
+	m := orderedmap.New[string, any]()
+	for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+		location := pair.Value.Value
+		if pair.Value.Link != nil {
+			location = pair.Value.Link.Location
+		}
+		m.Set(pair.Key, location)
+	}
+	o.Set("uses", m)
+}
+
+func (mw *modelWriter) annotations(o *jsonObject, annotations *orderedmap.OrderedMap[string, *DomainExtension]) {
+	if annotations.Len() == 0 {
+		return
+	}
+	m := orderedmap.New[string, any]()
+	for pair := annotations.Oldest(); pair != nil; pair = pair.Next() {
+		m.Set(pair.Key, nodeValue(pair.Value.Extension))
+	}
+	o.Set("annotations", m)
+}
+
+func (mw *modelWriter) exampleMap(examples *orderedmap.OrderedMap[string, *Example]) *jsonObject {
+	m := orderedmap.New[string, any]()
+	for pair := examples.Oldest(); pair != nil; pair = pair.Next() {
+		m.Set(pair.Key, mw.example(pair.Value))
+	}
+	return m
+}
+
+func (mw *modelWriter) example(ex *Example) *jsonObject {
+	o := orderedmap.New[string, any]()
+	setNonEmpty(o, "name", ex.Name)
+	setNonEmpty(o, "displayName", ex.DisplayName)
+	setNonEmpty(o, "description", ex.Description)
+	o.Set("strict", ex.Strict)
+	o.Set("value", nodeValue(ex.Data))
+	mw.annotations(o, ex.CustomDomainProperties)
+	if mw.positions {
+		o.Set("location", ex.Location)
+		o.Set("line", ex.Line)
+		o.Set("column", ex.Column)
+	}
+	return o
+}
+
+func (mw *modelWriter) shape(s *BaseShape) (*jsonObject, error) {
+	if s.Shape == nil {
+		return nil, fmt.Errorf("shape %s is nil", s.Name)
+	}
+	if _, ok := mw.visiting[s]; ok {
+		return nil, fmt.Errorf("shape %s refers to itself", s.Name)
+	}
+	mw.visiting[s] = struct{}{}
+	defer delete(mw.visiting, s)
+
+	o := orderedmap.New[string, any]()
+	setNonEmpty(o, "name", s.Name)
+	o.Set("type", shapeKind(s))
+	setNonEmpty(o, "declaredType", s.TypeLabel)
+	if len(s.Inherits) > 0 {
+		ms := newMarshaller()
+		inherits := make([]any, len(s.Inherits))
+		for i, parent := range s.Inherits {
+			name := parent.Name
+			if expr, ok := ms.typeExpression(parent); ok && name == "" {
+				name = expr
+			}
+			inherits[i] = name
+		}
+		o.Set("inherits", inherits)
+	}
+	if s.Link != nil {
+		o.Set("link", s.Link.Location)
+	}
+	setNonNil(o, "displayName", s.DisplayName)
+	setNonNil(o, "description", s.Description)
+	setNonNil(o, "required", s.Required)
+	if s.Default != nil {
+		o.Set("default", s.Default.Value)
+	}
+	if s.XML != nil {
+		x := orderedmap.New[string, any]()
+		setNonNil(x, XMLFacetAttribute, s.XML.Attribute)
+		setNonNil(x, XMLFacetWrapped, s.XML.Wrapped)
+		setNonNil(x, XMLFacetName, s.XML.Name)
+		setNonNil(x, XMLFacetNamespace, s.XML.Namespace)
+		setNonNil(x, XMLFacetPrefix, s.XML.Prefix)
+		o.Set("xml", x)
+	}
+	if err := mw.facets(o, s); err != nil {
+		return nil, err
+	}
+	if s.CustomShapeFacetDefinitions.Len() > 0 {
+		defs := orderedmap.New[string, any]()
+		for pair := s.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
+			p, err := mw.property(pair.Value.Required, pair.Value.Shape)
+			if err != nil {
+				return nil, fmt.Errorf("facet %s: %w", pair.Key, err)
+			}
+			defs.Set(pair.Key, p)
+		}
+		o.Set("facets", defs)
+	}
+	if s.CustomShapeFacets.Len() > 0 {
+		values := orderedmap.New[string, any]()
+		for pair := s.CustomShapeFacets.Oldest(); pair != nil; pair = pair.Next() {
+			values.Set(pair.Key, nodeValue(pair.Value))
+		}
+		o.Set("customFacets", values)
+	}
+	if s.Example != nil {
+		o.Set("example", mw.example(s.Example))
+	}
+	if s.Examples != nil {
+		o.Set("examples", mw.exampleMap(s.Examples.Map))
+	}
+	mw.annotations(o, s.CustomDomainProperties)
+	if mw.positions {
+		if s.raml != nil {
+			o.Set("location", s.Location())
+		}
+		o.Set("line", s.Line)
+		o.Set("column", s.Column)
+	}
+	return o, nil
+}
+
+// shapeKind returns the kind of the shape written to the "type" key.
+func shapeKind(s *BaseShape) string {
+	switch s.Shape.(type) {
+	case *AnyShape:
+		return TypeAny
+	case *StringShape:
+		return TypeString
+	case *IntegerShape:
+		return TypeInteger
+	case *NumberShape:
+		return TypeNumber
+	case *BooleanShape:
+		return TypeBoolean
+	case *DateTimeShape:
+		return TypeDatetime
+	case *DateTimeOnlyShape:
+		return TypeDatetimeOnly
+	case *DateOnlyShape:
+		return TypeDateOnly
+	case *TimeOnlyShape:
+		return TypeTimeOnly
+	case *FileShape:
+		return TypeFile
+	case *NilShape:
+		return TypeNil
+	case *ArrayShape:
+		return TypeArray
+	case *ObjectShape:
+		return TypeObject
+	case *UnionShape:
+		return TypeUnion
+	case *JSONShape:
+		return TypeJSON
+	case *RecursiveShape:
+		return TypeRecursive
+	default:
+		return s.Type
+	}
+}
+
+// facets sets facets specific to the shape type.
+func (mw *modelWriter) facets(o *jsonObject, s *BaseShape) error {
+	switch shape := s.Shape.(type) {
+	case *StringShape:
+		setEnum(o, shape.Enum)
+		setNonNil(o, FacetMinLength, shape.MinLength)
+		setNonNil(o, FacetMaxLength, shape.MaxLength)
+		if shape.Pattern != nil {
+			o.Set(FacetPattern, shape.Pattern.String())
+		}
+	case *NumberShape:
+		setEnum(o, shape.Enum)
+		setNonNil(o, FacetFormat, shape.Format)
+		setNonNil(o, FacetMinimum, shape.Minimum)
+		setNonNil(o, FacetMaximum, shape.Maximum)
+		setNonNil(o, FacetMultipleOf, shape.MultipleOf)
+	case *IntegerShape:
+		setEnum(o, shape.Enum)
+		setNonNil(o, FacetFormat, shape.Format)
+		// Integer bounds are written as JSON numbers of arbitrary size.
+		if shape.Minimum != nil {
+			o.Set(FacetMinimum, shape.Minimum)
+		}
+		if shape.Maximum != nil {
+			o.Set(FacetMaximum, shape.Maximum)
+		}
+		setNonNil(o, FacetMultipleOf, shape.MultipleOf)
+	case *BooleanShape:
+		setEnum(o, shape.Enum)
+	case *DateTimeShape:
+		setEnum(o, shape.Enum)
+		setNonNil(o, FacetFormat, shape.Format)
+	case *DateTimeOnlyShape:
+		setEnum(o, shape.Enum)
+	case *DateOnlyShape:
+		setEnum(o, shape.Enum)
+	case *TimeOnlyShape:
+		setEnum(o, shape.Enum)
+	case *FileShape:
+		if shape.FileTypes != nil {
+			o.Set(FacetFileTypes, nodeValues(shape.FileTypes))
+		}
+		setNonNil(o, FacetMinLength, shape.MinLength)
+		setNonNil(o, FacetMaxLength, shape.MaxLength)
+	case *UnionShape:
+		members := make([]any, len(shape.AnyOf))
+		for i, member := range shape.AnyOf {
+			m, err := mw.shape(member)
+			if err != nil {
+				return fmt.Errorf("union member: %w", err)
+			}
+			members[i] = m
+		}
+		o.Set(facetAnyOf, members)
+		setEnum(o, shape.Enum)
+	case *ArrayShape:
+		if shape.Items != nil {
+			items, err := mw.shape(shape.Items)
+			if err != nil {
+				return fmt.Errorf("items: %w", err)
+			}
+			o.Set(FacetItems, items)
+		}
+		setNonNil(o, FacetMinItems, shape.MinItems)
+		setNonNil(o, FacetMaxItems, shape.MaxItems)
+		setNonNil(o, FacetUniqueItems, shape.UniqueItems)
+	case *ObjectShape:
+		if err := mw.properties(o, shape); err != nil {
+			return err
+		}
+		setNonNil(o, FacetAdditionalProperties, shape.AdditionalProperties)
+		setNonNil(o, FacetMinProperties, shape.MinProperties)
+		setNonNil(o, FacetMaxProperties, shape.MaxProperties)
+		setNonNil(o, FacetDiscriminator, shape.Discriminator)
+		if shape.DiscriminatorValue != nil {
+			o.Set(FacetDiscriminatorValue, shape.DiscriminatorValue)
+		}
+	case *JSONShape:
+		if json.Valid([]byte(shape.Raw)) {
+			o.Set("schema", json.RawMessage(shape.Raw))
+		} else {
+			o.Set("schema", shape.Raw)
+		}
+	case *RecursiveShape:
+		head := shape.Head.TypeLabel
+		if head == "" {
+			head = shape.Head.Name
+		}
+		o.Set("head", head)
+	}
+	return nil
+}
+
+func (mw *modelWriter) properties(o *jsonObject, shape *ObjectShape) error {
+	if shape.Properties.Len() > 0 {
+		props := orderedmap.New[string, any]()
+		for pair := shape.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			p, err := mw.property(pair.Value.Required, pair.Value.Shape)
+			if err != nil {
+				return fmt.Errorf("property %s: %w", pair.Key, err)
+			}
+			props.Set(pair.Key, p)
+		}
+		o.Set(FacetProperties, props)
+	}
+	if shape.PatternProperties.Len() > 0 {
+		props := orderedmap.New[string, any]()
+		for pair := shape.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+			p, err := mw.shape(pair.Value.Shape)
+			if err != nil {
+				return fmt.Errorf("pattern property %s: %w", pair.Key, err)
+			}
+			props.Set(pair.Key, p)
+		}
+		o.Set("patternProperties", props)
+	}
+	return nil
+}
+
+func (mw *modelWriter) property(required bool, s *BaseShape) (*jsonObject, error) {
+	ps, err := mw.shape(s)
+	if err != nil {
+		return nil, err
+	}
+	p := orderedmap.New[string, any]()
+	p.Set("required", required)
+	p.Set("shape", ps)
+	return p, nil
+}
+
+func setNonEmpty(o *jsonObject, key string, value string) {
+	if value != "" {
+		o.Set(key, value)
+	}
+}
+
+// setNonNil sets the value of the pointer unless it is nil.
+func setNonNil[T any](o *jsonObject, key string, value *T) {
+	if value != nil {
+		o.Set(key, *value)
+	}
+}
+
+func setEnum(o *jsonObject, enum Nodes) {
+	if enum != nil {
+		o.Set(FacetEnum, nodeValues(enum))
+	}
+}
+
+func nodeValue(n *Node) any {
+	if n == nil {
+		return nil
+	}
+	return n.Value
+}
+
+func nodeValues(nodes Nodes) []any {
+	values := make([]any, len(nodes))
+	for i, n := range nodes {
+		values[i] = n.Value
+	}
+	return values
+}
@@ -0,0 +1,116 @@
+package raml
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAML_ModelJSON(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common.raml"), []byte(`#%RAML 1.0 Library
+types:
+  Id:
+    type: string
+    pattern: ^[a-z]+$
+`), 0o600))
+	content := `#%RAML 1.0 Library
+uses:
+  common: common.raml
+annotationTypes:
+  pii: nil
+types:
+  Base:
+    properties:
+      id: common.Id
+  Pet:
+    type: Base
+    description: A pet.
+    properties:
+      name:
+        type: string
+        maxLength: 10
+      tags?: string[]
+      age: integer | nil
+    example:
+      id: abc
+      name: Rex
+      age: 3
+  Node:
+    properties:
+      next?: Node
+`
+	parse := func(opts ...ParseOpt) *RAML {
+		rml, err := ParseFromString(content, "library.raml", dir, opts...)
+		require.NoError(t, err)
+		return rml
+	}
+	out, err := json.Marshal(parse(OptWithUnwrap()))
+	require.NoError(t, err)
+	again, err := json.Marshal(parse(OptWithUnwrap()))
+	require.NoError(t, err)
+	require.Equal(t, string(out), string(again))
+
+	var model struct {
+		EntryPoint string `json:"entryPoint"`
+		Fragments  []struct {
+			Kind     string                     `json:"kind"`
+			Location string                     `json:"location"`
+			Uses     map[string]string          `json:"uses"`
+			Types    map[string]json.RawMessage `json:"types"`
+		} `json:"fragments"`
+	}
+	require.NoError(t, json.Unmarshal(out, &model))
+	require.Equal(t, filepath.Join(dir, "library.raml"), model.EntryPoint)
+	require.Len(t, model.Fragments, 2)
+	require.Equal(t, filepath.Join(dir, "common.raml"), model.Fragments[0].Location)
+	entry := model.Fragments[1]
+	require.Equal(t, "Library", entry.Kind)
+	require.Equal(t, map[string]string{"common": filepath.Join(dir, "common.raml")}, entry.Uses)
+
+	// Unwrapped types contain the effective facets of their parents.
+	require.JSONEq(t, `{
+		"name": "Pet",
+		"type": "object",
+		"declaredType": "Base",
+		"inherits": ["Base"],
+		"description": "A pet.",
+		"properties": {
+			"id": {"required": true, "shape": {"name": "id", "type": "string", "declaredType": "common.Id", "pattern": "^[a-z]+$"}},
+			"name": {"required": true, "shape": {"name": "name", "type": "string", "maxLength": 10}},
+			"tags": {"required": false, "shape": {"name": "tags?", "type": "array", "items": {"type": "string"}}},
+			"age": {"required": true, "shape": {"name": "age", "type": "union", "anyOf": [
+				{"type": "integer"}, {"type": "nil"}
+			]}}
+		},
+		"example": {"strict": true, "value": {"id": "abc", "name": "Rex", "age": 3}}
+	}`, string(entry.Types["Pet"]))
+	require.JSONEq(t, `{"name": "Node", "type": "object", "properties": {
+		"next": {"required": false, "shape": {"name": "next?", "type": "recursive", "head": "Node"}}
+	}}`, string(entry.Types["Node"]))
+
+	// Without unwrapping, shapes only contain the facets they declare.
+	pet, err := json.Marshal(parse().EntryPoint().(*Library).Types.Value("Pet"))
+	require.NoError(t, err)
+	var declared struct {
+		Inherits   []string                   `json:"inherits"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(pet, &declared))
+	require.Equal(t, []string{"Base"}, declared.Inherits)
+	require.NotContains(t, declared.Properties, "id")
+	node := parse().EntryPoint().(*Library).Types.Value("Node")
+	next, err := json.Marshal(node.Shape.(*ObjectShape).Properties.Value("next").Shape)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name": "next?", "type": "object", "declaredType": "Node"}`, string(next))
+
+	withPositions, err := parse().ModelJSON(OptModelJSONWithPositions())
+	require.NoError(t, err)
+	require.Contains(t, string(withPositions),
+		`"name":"Pet","type":"object","declaredType":"Base","inherits":["Base"],"description":"A pet."`)
+	require.Contains(t, string(withPositions),
+		`"location":"`+filepath.Join(dir, "library.raml")+`","line":11,"column":5}`)
+}
@@ -0,0 +1,98 @@
+package raml
+
+import (
+	"fmt"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// mergeParents merges unwrapped parents of the type declared with multiple inheritance, e.g. "type: [A, B]", into
+// a new shape that is inherited by the type. Parents are not modified. Properties of object parents are united and
+// a property is required if any parent requires it. Other constraints are intersected, so parents of different kinds
+// and constraints without intersection (e.g. different formats or types of the same property) are errors.
+func (r *RAML) mergeParents(base *BaseShape, parents []*BaseShape) (*BaseShape, error) {
+	// The policy is changed for copies of parents rather than for the RAML since other types may be resolved
+	// concurrently. Copies are unmarked when merging is done.
+	var copies []*BaseShape
+	defer func() {
+		for _, c := range copies {
+			c.mergedParent = false
+		}
+	}()
+	clonedMap := make(map[int64]*BaseShape)
+	merged := parents[0].clone(clonedMap)
+	merged.ID = r.newCopyID(parents[0].ID, base.ID)
+	copies = markMergedParent(copies, clonedMap)
+	for _, parent := range parents[1:] {
+		if !isMergeableParent(merged, parent) {
+			return nil, stacktrace.New("cannot inherit from parents of different types", base.Location(),
+				stacktrace.WithPosition(&base.Position),
+				stacktrace.WithInfo("first", parentName(parents[0])),
+				stacktrace.WithInfo("parent", parentName(parent)),
+				stacktrace.WithType(stacktrace.TypeUnwrapping))
+		}
+		sourceMap := make(map[int64]*BaseShape)
+		source := parent.clone(sourceMap)
+		copies = markMergedParent(copies, sourceMap)
+		if target, ok := merged.Shape.(*ObjectShape); ok {
+			if ss, isObject := source.Shape.(*ObjectShape); isObject {
+				target.uniteParent(ss)
+			}
+		}
+		if _, err := merged.Inherit(source); err != nil {
+			return nil, StacktraceNewWrapped("merge parents", err, base.Location(),
+				stacktrace.WithPosition(&base.Position),
+				stacktrace.WithInfo("parent", parentName(parent)),
+				stacktrace.WithType(stacktrace.TypeUnwrapping))
+		}
+	}
+	return merged, nil
+}
+
+// isMergeableParent reports whether the parent is of the same kind as the merged parents. Unions and any are
+// merged by the rules of inheritance.
+func isMergeableParent(merged *BaseShape, parent *BaseShape) bool {
+	switch parent.Shape.(type) {
+	case *UnionShape, *AnyShape:
+		return true
+	}
+	switch merged.Shape.(type) {
+	case *UnionShape, *AnyShape:
+		return true
+	}
+	return fmt.Sprintf("%T", merged.Shape) == fmt.Sprintf("%T", parent.Shape)
+}
+
+func parentName(s *BaseShape) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Type
+}
+
+// uniteParent prepares the merged object to inherit another parent: properties required by the parent become
+// required and "additionalProperties: false" of the parent applies to the merged object. Property types and other
+// constraints are intersected by inheritance.
+func (s *ObjectShape) uniteParent(parent *ObjectShape) {
+	if parent.AdditionalProperties != nil && !*parent.AdditionalProperties {
+		s.AdditionalProperties = parent.AdditionalProperties
+	}
+	if s.Properties == nil || parent.Properties == nil {
+		return
+	}
+	for pair := parent.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		if prop, ok := s.Properties.Get(pair.Key); ok && pair.Value.Required && !prop.Required {
+			prop.Required = true
+			s.Properties.Set(pair.Key, prop)
+		}
+	}
+}
+
+// markMergedParent marks copies of the merged parent (see BaseShape.inheritConflict) and appends them to copies.
+func markMergedParent(copies []*BaseShape, clonedMap map[int64]*BaseShape) []*BaseShape {
+	for _, c := range clonedMap {
+		c.mergedParent = true
+		copies = append(copies, c)
+	}
+	return copies
+}
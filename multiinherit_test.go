@@ -0,0 +1,127 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipleInheritance(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  A:
+    properties:
+      id:
+        type: integer
+        minimum: 1
+      name?:
+        type: string
+        maxLength: 10
+  B:
+    additionalProperties: false
+    properties:
+      name:
+        type: string
+        minLength: 2
+        maxLength: 5
+      extra: boolean
+  C:
+    type: [A, B]
+    properties:
+      own: string
+  Short:
+    type: string
+    maxLength: 5
+  Long:
+    type: string
+    minLength: 2
+  Code:
+    type: [Short, Long]
+`
+	r, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+
+	lookup := func(name string) *BaseShape {
+		s, err := r.LookupType(name, r.GetLocation())
+		require.NoError(t, err)
+		return s
+	}
+
+	c := lookup("C").Shape.(*ObjectShape)
+	var names []string
+	for pair := c.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		names = append(names, pair.Key)
+	}
+	require.ElementsMatch(t, []string{"own", "id", "name", "extra"}, names)
+	name, _ := c.Properties.Get("name")
+	require.True(t, name.Required)
+	nameShape := name.Shape.Shape.(*StringShape)
+	require.Equal(t, uint64(2), *nameShape.MinLength)
+	require.Equal(t, uint64(5), *nameShape.MaxLength)
+	require.False(t, *c.AdditionalProperties)
+
+	// Parents are not modified by merging.
+	a := lookup("A").Shape.(*ObjectShape)
+	require.Equal(t, 2, a.Properties.Len())
+	aName, _ := a.Properties.Get("name")
+	require.False(t, aName.Required)
+	require.Equal(t, uint64(10), *aName.Shape.Shape.(*StringShape).MaxLength)
+	require.Nil(t, a.AdditionalProperties)
+
+	code := lookup("Code").Shape.(*StringShape)
+	require.Equal(t, uint64(2), *code.MinLength)
+	require.Equal(t, uint64(5), *code.MaxLength)
+	require.Error(t, lookup("Code").Validate("x"))
+	require.NoError(t, lookup("Code").Validate("xyz"))
+}
+
+func TestMultipleInheritance_Conflicts(t *testing.T) {
+	tests := []struct {
+		name  string
+		types string
+		err   string
+	}{
+		{
+			name: "different kinds",
+			types: `
+  S: string
+  O:
+    properties:
+      a: string
+  C:
+    type: [O, S]`,
+			err: "cannot inherit from parents of different types",
+		},
+		{
+			name: "different property types",
+			types: `
+  A:
+    properties:
+      x: string
+  B:
+    properties:
+      x: integer
+  C:
+    type: [A, B]`,
+			err: "cannot inherit from different type",
+		},
+		{
+			name: "disjoint enums",
+			types: `
+  A:
+    enum: [a, b]
+  B:
+    enum: [c]
+  C:
+    type: [A, B]`,
+			err: "enum constraint violation",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFromString("#%RAML 1.0 Library\ntypes:"+tt.types+"\n", "library.raml", t.TempDir(),
+				OptWithUnwrap())
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package raml
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Case is the letter case of identifiers produced by Naming.
+type Case int
+
+const (
+	// CasePascal joins capitalized words, e.g. "OwnerId".
+	CasePascal Case = iota
+	// CaseCamel is CasePascal with the first word in lower case, e.g. "ownerId".
+	CaseCamel
+	// CaseSnake joins lower-case words with underscores, e.g. "owner_id".
+	CaseSnake
+	// CaseScreamingSnake joins upper-case words with underscores, e.g. "OWNER_ID".
+	CaseScreamingSnake
+	// CaseKebab joins lower-case words with hyphens, e.g. "owner-id".
+	CaseKebab
+)
+
+// digitPrefixes are prepended to identifiers that are empty or start with a digit.
+var digitPrefixes = map[Case]string{
+	CasePascal:         "T",
+	CaseCamel:          "t",
+	CaseSnake:          "f_",
+	CaseScreamingSnake: "F_",
+	CaseKebab:          "f-",
+}
+
+// Naming converts names of types, properties and enum values to identifiers of a target language. Converters and
+// generators accept it with options, e.g. WithTypeScriptNaming, so generated names follow the conventions of the
+// project. The zero value converts names without initialisms and reserved words.
+type Naming struct {
+	// Initialisms are words written in upper case by CasePascal and CaseCamel, e.g. with "ID" the name "ownerId"
+	// becomes "OwnerID". The first word of CaseCamel is written in lower case.
+	Initialisms []string
+	// Reserved are keywords of the target language that cannot be used as identifiers. They are escaped with Escape.
+	Reserved []string
+	// Escape formats reserved identifiers, "%s_" by default.
+	Escape string
+}
+
+// Built-in naming strategies of target languages.
+var (
+	NamingGo = Naming{
+		Initialisms: []string{
+			"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID", "IP", "JSON",
+			"LHS", "QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP", "UI", "UID",
+			"UUID", "URI", "URL", "UTF8", "VM", "XML", "XMPP", "XSRF", "XSS",
+		},
+		Reserved: []string{
+			"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func",
+			"go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select", "struct",
+			"switch", "type", "var",
+		},
+	}
+	NamingTypeScript = Naming{
+		Reserved: []string{
+			"break", "case", "catch", "class", "const", "continue", "debugger", "default", "delete", "do", "else",
+			"enum", "export", "extends", "false", "finally", "for", "function", "if", "implements", "import", "in",
+			"instanceof", "interface", "let", "new", "null", "package", "private", "protected", "public", "return",
+			"static", "super", "switch", "this", "throw", "true", "try", "typeof", "var", "void", "while", "with",
+			"yield",
+		},
+	}
+)
+
+// Identifier converts the name to the identifier in the case. Words are split at separators and case changes, e.g.
+// "ownerID_list" gives "OwnerIDList" in CasePascal and "owner_id_list" in CaseSnake.
+func (n Naming) Identifier(name string, c Case) string {
+	words := identifierWords(name)
+	var result string
+	switch c {
+	case CasePascal, CaseCamel:
+		var sb strings.Builder
+		for i, w := range words {
+			switch {
+			case c == CaseCamel && i == 0 && (n.isInitialism(w) || strings.ToUpper(w) == w):
+				sb.WriteString(strings.ToLower(w))
+			case c == CaseCamel && i == 0:
+				r := []rune(w)
+				sb.WriteString(strings.ToLower(string(r[0])) + string(r[1:]))
+			case n.isInitialism(w):
+				sb.WriteString(strings.ToUpper(w))
+			default:
+				r := []rune(w)
+				sb.WriteString(strings.ToUpper(string(r[0])) + string(r[1:]))
+			}
+		}
+		result = sb.String()
+	case CaseSnake, CaseScreamingSnake, CaseKebab:
+		sep := "_"
+		if c == CaseKebab {
+			sep = "-"
+		}
+		for i, w := range words {
+			words[i] = strings.ToLower(w)
+		}
+		result = strings.Join(words, sep)
+		if c == CaseScreamingSnake {
+			result = strings.ToUpper(result)
+		}
+	}
+	if result == "" || unicode.IsDigit([]rune(result)[0]) {
+		return digitPrefixes[c] + result
+	}
+	return n.escape(result)
+}
+
+func (n Naming) Pascal(name string) string {
+	return n.Identifier(name, CasePascal)
+}
+
+func (n Naming) Camel(name string) string {
+	return n.Identifier(name, CaseCamel)
+}
+
+func (n Naming) Snake(name string) string {
+	return n.Identifier(name, CaseSnake)
+}
+
+func (n Naming) ScreamingSnake(name string) string {
+	return n.Identifier(name, CaseScreamingSnake)
+}
+
+func (n Naming) Kebab(name string) string {
+	return n.Identifier(name, CaseKebab)
+}
+
+func (n Naming) isInitialism(word string) bool {
+	for _, i := range n.Initialisms {
+		if strings.EqualFold(i, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n Naming) escape(id string) string {
+	for _, r := range n.Reserved {
+		if r == id {
+			if n.Escape == "" {
+				return id + "_"
+			}
+			return fmt.Sprintf(n.Escape, id)
+		}
+	}
+	return id
+}
+
+// NameScope resolves collisions of identifiers declared in the same scope, e.g. of types of a generated file or of
+// members of an enum. Names are unique in the order they are declared, so the result is deterministic for the same
+// order of declarations.
+type NameScope struct {
+	taken map[string]struct{}
+}
+
+func NewNameScope() *NameScope {
+	return &NameScope{taken: make(map[string]struct{})}
+}
+
+// Unique returns the identifier if it is not taken yet, otherwise the identifier with the smallest number suffix
+// starting from 2 that is not taken, e.g. "Pet2". The result is taken.
+func (s *NameScope) Unique(id string) string {
+	unique := id
+	for i := 2; ; i++ {
+		if _, ok := s.taken[unique]; !ok {
+			break
+		}
+		unique = fmt.Sprintf("%s%d", id, i)
+	}
+	s.taken[unique] = struct{}{}
+	return unique
+}
+
+// identifierWords splits the name into words at separators and case changes, e.g. "ownerID_list" gives
+// ["owner", "ID", "list"].
+func identifierWords(name string) []string {
+	var words []string
+	var cur []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(cur) > 0 {
+				words = append(words, string(cur))
+				cur = nil
+			}
+			continue
+		}
+		if len(cur) > 0 && unicode.IsUpper(r) {
+			prev := cur[len(cur)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(cur))
+				cur = nil
+			}
+		}
+		cur = append(cur, r)
+	}
+	if len(cur) > 0 {
+		words = append(words, string(cur))
+	}
+	return words
+}
+
+// pascalIdentifier converts the name to CasePascal without initialisms and reserved words.
+func pascalIdentifier(name string) string {
+	return Naming{}.Pascal(name)
+}
@@ -0,0 +1,76 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNaming_Identifier(t *testing.T) {
+	cases := []Case{CasePascal, CaseCamel, CaseSnake, CaseScreamingSnake, CaseKebab}
+	for name, want := range map[string][5]string{
+		"ownerID":     {"OwnerID", "ownerID", "owner_id", "OWNER_ID", "owner-id"},
+		"HTTPServer":  {"HTTPServer", "httpServer", "http_server", "HTTP_SERVER", "http-server"},
+		"created_at":  {"CreatedAt", "createdAt", "created_at", "CREATED_AT", "created-at"},
+		"ID":          {"ID", "id", "id", "ID", "id"},
+		"common.Name": {"CommonName", "commonName", "common_name", "COMMON_NAME", "common-name"},
+		"2fa":         {"T2fa", "t2fa", "f_2fa", "F_2FA", "f-2fa"},
+		"":            {"T", "t", "f_", "F_", "f-"},
+	} {
+		var got [5]string
+		for i, c := range cases {
+			got[i] = Naming{}.Identifier(name, c)
+		}
+		require.Equal(t, want, got, name)
+	}
+
+	require.Equal(t, "OwnerID", NamingGo.Pascal("ownerId"))
+	require.Equal(t, "apiURL", NamingGo.Camel("api_url"))
+	require.Equal(t, "type_", NamingGo.Camel("type"))
+	require.Equal(t, "Type", NamingGo.Pascal("type"))
+	require.Equal(t, "default_", NamingTypeScript.Camel("Default"))
+	custom := Naming{Initialisms: []string{"Id"}, Reserved: []string{"select"}, Escape: "`%s`"}
+	require.Equal(t, "userID", custom.Camel("user_id"))
+	require.Equal(t, "`select`", custom.Snake("Select"))
+}
+
+func TestNameScope(t *testing.T) {
+	scope := NewNameScope()
+	require.Equal(t, "Pet", scope.Unique("Pet"))
+	require.Equal(t, "Pet2", scope.Unique("Pet"))
+	require.Equal(t, "Pet22", scope.Unique("Pet2"))
+	require.Equal(t, "Pet3", scope.Unique("Pet"))
+}
+
+func TestNaming_Converters(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  common_pet:
+    properties:
+      type: string
+  CommonPet:
+    properties:
+      ownerId: integer
+      status:
+        enum: [active, Active]
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+
+	ts, err := NewTypeScriptConverter().Convert(rml)
+	require.NoError(t, err)
+	require.Contains(t, string(ts), "export interface CommonPet {")
+	require.Contains(t, string(ts), "export interface CommonPet2 {")
+	require.Contains(t, string(ts), "status: \"active\" | \"Active\";")
+
+	proto, err := NewProtoConverter(WithProtoNaming(Naming{Initialisms: []string{"ID"}})).Convert(rml)
+	require.NoError(t, err)
+	require.Equal(t, "CommonPet", proto.Messages[0].Name)
+	require.Equal(t, "owner_id", proto.Messages[0].Fields[0].Name)
+	require.Equal(t, "CommonPet2", proto.Messages[1].Name)
+
+	data, err := NewTemplateGenerator(WithTemplateNaming(NamingGo)).Data(rml)
+	require.NoError(t, err)
+	require.Equal(t, "CommonPet", data.Types[0].Identifier)
+	require.Equal(t, "CommonPet2", data.Types[1].Identifier)
+}
@@ -0,0 +1,202 @@
+package raml
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// integerValue sets val to the integer instance value. Besides Go integers and floats decoded by encoding/json,
+// json.Number (see json.Decoder.UseNumber) and *big.Int are accepted, so integers beyond the precision of float64
+// are validated exactly. Floats with a fractional part are rejected.
+func integerValue(v any, val *big.Int) error {
+	switch v := v.(type) {
+	case int:
+		val.SetInt64(int64(v))
+	case int8:
+		val.SetInt64(int64(v))
+	case int16:
+		val.SetInt64(int64(v))
+	case int32:
+		val.SetInt64(int64(v))
+	case int64:
+		val.SetInt64(v)
+	case uint:
+		val.SetUint64(uint64(v))
+	case uint8:
+		val.SetUint64(uint64(v))
+	case uint16:
+		val.SetUint64(uint64(v))
+	case uint32:
+		val.SetUint64(uint64(v))
+	case uint64:
+		val.SetUint64(v)
+	// json unmarshals numbers as float64
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("invalid value %v, expected integer", v)
+		}
+		if _, acc := big.NewFloat(v).Int(val); acc != big.Exact {
+			return fmt.Errorf("invalid value %v, expected integer", v)
+		}
+	case json.Number:
+		if _, ok := val.SetString(string(v), 10); ok {
+			return nil
+		}
+		r, ok := new(big.Rat).SetString(string(v))
+		if !ok || !r.IsInt() {
+			return fmt.Errorf("invalid value %s, expected integer", v)
+		}
+		val.Set(r.Num())
+	case *big.Int:
+		val.Set(v)
+	default:
+		return fmt.Errorf("invalid type, got %T, expected int, uint, float64, json.Number or *big.Int", v)
+	}
+	return nil
+}
+
+// numberValue returns the number instance value. Exact values are returned for json.Number (see
+// json.Decoder.UseNumber), math/big numbers and integers that may exceed the precision of float64, the float is set
+// for other numbers.
+func numberValue(v any) (float64, *big.Rat, error) {
+	switch v := v.(type) {
+	// go-yaml unmarshals integers as int
+	case int:
+		return 0, new(big.Rat).SetInt64(int64(v)), nil
+	case int8:
+		return float64(v), nil, nil
+	case int16:
+		return float64(v), nil, nil
+	case int32:
+		return float64(v), nil, nil
+	case int64:
+		return 0, new(big.Rat).SetInt64(v), nil
+	case uint:
+		return 0, new(big.Rat).SetUint64(uint64(v)), nil
+	case uint8:
+		return float64(v), nil, nil
+	case uint16:
+		return float64(v), nil, nil
+	case uint32:
+		return float64(v), nil, nil
+	case uint64:
+		return 0, new(big.Rat).SetUint64(v), nil
+	case float32:
+		return float64(v), nil, nil
+	case float64:
+		return v, nil, nil
+	case json.Number:
+		r, ok := new(big.Rat).SetString(string(v))
+		if !ok {
+			return 0, nil, fmt.Errorf("invalid value %s, expected number", v)
+		}
+		return 0, r, nil
+	case *big.Int:
+		return 0, new(big.Rat).SetInt(v), nil
+	case *big.Rat:
+		return 0, v, nil
+	case *big.Float:
+		if v.IsInf() {
+			return 0, nil, fmt.Errorf("invalid value %s, expected number", v.String())
+		}
+		r, _ := v.Rat(nil)
+		return 0, r, nil
+	default:
+		return 0, nil, fmt.Errorf("invalid type, got %T, expected int, uint, float64, json.Number or math/big number", v)
+	}
+}
+
+// exactNumber returns the exact value of the number, floats are converted by floatToRat. False is returned if the
+// value is not a finite number.
+func exactNumber(v any) (*big.Rat, bool) {
+	f, r, err := numberValue(v)
+	if err != nil {
+		return nil, false
+	}
+	if r == nil {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, false
+		}
+		r = floatToRat(f)
+	}
+	return r, true
+}
+
+// integerFormatBounds contains bounds of integer formats, see SetOfIntegerFormats.
+var integerFormatBounds = map[string][2]int64{
+	"int8":  {math.MinInt8, math.MaxInt8},
+	"int16": {math.MinInt16, math.MaxInt16},
+	"int32": {math.MinInt32, math.MaxInt32},
+	"int":   {math.MinInt32, math.MaxInt32},
+	"int64": {math.MinInt64, math.MaxInt64},
+	"long":  {math.MinInt64, math.MaxInt64},
+}
+
+// validateIntegerFormat returns an error if the value does not fit the integer format.
+func validateIntegerFormat(val *big.Int, format *string) error {
+	if format == nil {
+		return nil
+	}
+	bounds, ok := integerFormatBounds[*format]
+	if !ok {
+		return nil
+	}
+	if !val.IsInt64() || val.Int64() < bounds[0] || val.Int64() > bounds[1] {
+		return fmt.Errorf("value %s is out of range of format %s", val.String(), *format)
+	}
+	return nil
+}
+
+// validateNumberFormat returns an error if the value does not fit the number format. Values of the decimal format
+// are not limited.
+func validateNumberFormat(f float64, r *big.Rat, format *string) error {
+	if format == nil || *format == NumberFormatDecimal {
+		return nil
+	}
+	limit := math.MaxFloat64
+	if *format == "float" {
+		limit = math.MaxFloat32
+	}
+	if r != nil {
+		f, _ = r.Float64()
+	}
+	if math.IsInf(f, 0) || math.Abs(f) > limit {
+		return fmt.Errorf("value is out of range of format %s", *format)
+	}
+	return nil
+}
+
+// compareNumber compares the value with the bound exactly if the value is not a float.
+func compareNumber(f float64, r *big.Rat, bound float64) int {
+	if r != nil {
+		return r.Cmp(floatToRat(bound))
+	}
+	switch {
+	case f < bound:
+		return -1
+	case f > bound:
+		return 1
+	}
+	return 0
+}
+
+// isEnumNumber reports whether the enum value equals the number, numbers are compared by value regardless of their
+// types.
+func isEnumNumber(e any, f float64, r *big.Rat) bool {
+	ef, er, err := numberValue(e)
+	if err != nil {
+		return false
+	}
+	if r == nil && er == nil {
+		return ef == f
+	}
+	if r == nil {
+		r = floatToRat(f)
+	}
+	if er == nil {
+		er = floatToRat(ef)
+	}
+	return r.Cmp(er) == 0
+}
@@ -0,0 +1,83 @@
+package raml
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumbers_precision(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Id:
+    type: integer
+    maximum: 9007199254740992
+  Small:
+    type: integer
+    format: int8
+  Long:
+    type: integer
+    format: long
+    enum: [1, 9223372036854775807]
+  Price:
+    type: number
+    format: decimal
+    minimum: 0.1
+    multipleOf: 0.01
+  Ratio:
+    type: number
+    format: float
+    enum: [1, 0.5]
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	lookup := func(name string) *BaseShape {
+		s, err := rml.LookupType(name, "")
+		require.NoError(t, err)
+		return s
+	}
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+
+	tests := []struct {
+		name    string
+		typ     string
+		value   any
+		wantErr string
+	}{
+		{name: "max safe integer", typ: "Id", value: json.Number("9007199254740992")},
+		{name: "beyond float64", typ: "Id", value: json.Number("9007199254740993"),
+			wantErr: "value must be less than 9007199254740992"},
+		{name: "integer in exponent notation", typ: "Id", value: json.Number("1e3")},
+		{name: "fractional json number", typ: "Id", value: json.Number("1.5"),
+			wantErr: "invalid value 1.5, expected integer"},
+		{name: "big integer", typ: "Id", value: huge, wantErr: "value must be less than"},
+		{name: "fractional float", typ: "Id", value: 1.5, wantErr: "invalid value 1.5, expected integer"},
+		{name: "integral float", typ: "Id", value: 42.0},
+		{name: "int64", typ: "Id", value: int64(42)},
+		{name: "int8", typ: "Small", value: 127},
+		{name: "int8 overflow", typ: "Small", value: 128, wantErr: "value 128 is out of range of format int8"},
+		{name: "long enum", typ: "Long", value: json.Number("9223372036854775807")},
+		{name: "long overflow", typ: "Long", value: huge, wantErr: "is out of range of format long"},
+		{name: "decimal", typ: "Price", value: json.Number("12345678901234567890.12")},
+		{name: "decimal below minimum", typ: "Price", value: json.Number("0.09999999999999999999"),
+			wantErr: "value must be greater than"},
+		{name: "decimal not multiple", typ: "Price", value: json.Number("0.123"),
+			wantErr: "value must be a multiple of 0.01"},
+		{name: "float", typ: "Ratio", value: 1.0},
+		{name: "float enum by value", typ: "Ratio", value: json.Number("0.5")},
+		{name: "float enum by json number", typ: "Ratio", value: json.Number("1.0")},
+		{name: "float overflow", typ: "Ratio", value: 1e300, wantErr: "value is out of range of format float"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := lookup(tt.typ).Validate(tt.value)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
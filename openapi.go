@@ -0,0 +1,807 @@
+package raml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// ImportOpenAPI converts the Swagger 2.0 or OpenAPI 3.0 document in JSON or YAML into a RAML 1.0 API definition
+// (see ConvertOpenAPI) and parses it as the API root at fileName in baseDir. Parts of the document that have no
+// RAML equivalent are reported by RAML.Warnings. Positions in the model refer to the converted definition.
+func ImportOpenAPI(content []byte, fileName string, baseDir string, opts ...ParseOpt) (*RAML, error) {
+	converted, warnings, err := ConvertOpenAPI(content, filepath.Join(baseDir, fileName))
+	if err != nil {
+		return nil, err
+	}
+	rml, err := ParseFromString(string(converted), fileName, baseDir, opts...)
+	if rml != nil {
+		rml.warnings = append(rml.warnings, warnings...)
+	}
+	return rml, err
+}
+
+// ImportOpenAPIFromPath imports the Swagger 2.0 or OpenAPI 3.0 document at path, see ImportOpenAPI.
+func ImportOpenAPIFromPath(path string, opts ...ParseOpt) (*RAML, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("absolute path: %w", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, StacktraceNewWrapped("read file", err, path, stacktrace.WithType(stacktrace.TypeReading))
+	}
+	return ImportOpenAPI(content, filepath.Base(path), filepath.Dir(path), opts...)
+}
+
+// ConvertOpenAPI converts the Swagger 2.0 or OpenAPI 3.0 document in JSON or YAML into a RAML 1.0 API definition.
+// location is the location of the document in errors and warnings.
+//
+// Schemas of definitions and components become types, paths become resources with URI parameters, and operations
+// become methods with query parameters, headers, bodies by media types and responses. Summaries of operations become
+// display names of methods. Schemas are converted like JSON schemas of OptWithImportJSONSchema; references to
+// schemas become type names, allOf of references becomes multiple inheritance and nullable becomes a union with nil.
+// Schemas that cannot be converted become any, and parts that have no RAML equivalent, e.g. cookie parameters,
+// default responses and security requirements, are skipped. Both are reported by the returned warnings.
+func ConvertOpenAPI(content []byte, location string) ([]byte, []*stacktrace.StackTrace, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, nil, StacktraceNewWrapped("decode OpenAPI document", err, location,
+			stacktrace.WithType(stacktrace.TypeParsing))
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, stacktrace.New("OpenAPI document must be a mapping", location,
+			stacktrace.WithType(stacktrace.TypeParsing))
+	}
+	im := &openAPIImporter{root: doc.Content[0], location: location}
+	node, err := im.convert()
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := encodeRAML(HeaderAPI, node)
+	if err != nil {
+		return nil, nil, StacktraceNewWrapped("encode RAML", err, location)
+	}
+	return out, im.warnings, nil
+}
+
+// openAPIMethods are the operations of path items in the order of RAML methods.
+var openAPIMethods = []string{"get", "patch", "put", "post", "delete", "head", "options", "trace"}
+
+// openAPIImporter converts an OpenAPI document into a RAML API definition.
+type openAPIImporter struct {
+	root     *yaml.Node
+	location string
+	// version is the major version of the document: 2 for Swagger 2.0 and 3 for OpenAPI 3.0.
+	version  int
+	warnings []*stacktrace.StackTrace
+}
+
+func (im *openAPIImporter) warn(msg string, n *yaml.Node) {
+	im.warnings = append(im.warnings, stacktrace.New(msg, im.location, WithNodePosition(n),
+		stacktrace.WithSeverity(stacktrace.SeverityWarning)))
+}
+
+// get returns the value of the key of the mapping, nil if the node is not a mapping or there is no key.
+func (im *openAPIImporter) get(n *yaml.Node, key string) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	if i := mappingIndex(n, key); i >= 0 {
+		if v := n.Content[i+1]; v.Kind == yaml.AliasNode {
+			return v.Alias
+		}
+		return n.Content[i+1]
+	}
+	return nil
+}
+
+func (im *openAPIImporter) value(n *yaml.Node, key string) string {
+	if v := im.get(n, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+// pairs returns keys and values of the mapping, nil if the node is not a mapping.
+func (im *openAPIImporter) pairs(n *yaml.Node) [][2]*yaml.Node {
+	if n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	result := make([][2]*yaml.Node, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		v := n.Content[i+1]
+		if v.Kind == yaml.AliasNode {
+			v = v.Alias
+		}
+		result = append(result, [2]*yaml.Node{n.Content[i], v})
+	}
+	return result
+}
+
+// resolve follows local references of parameters, request bodies, responses and headers, e.g.
+// {"$ref": "#/components/parameters/limit"}. It returns nil if the reference cannot be resolved.
+func (im *openAPIImporter) resolve(n *yaml.Node) *yaml.Node {
+	for depth := 0; n != nil; depth++ {
+		ref := im.get(n, "$ref")
+		if ref == nil {
+			return n
+		}
+		target := im.pointer(ref.Value)
+		if target == nil || depth > maxOpenAPIRefDepth {
+			im.warn(fmt.Sprintf("reference %q is not resolved", ref.Value), ref)
+			return nil
+		}
+		n = target
+	}
+	return nil
+}
+
+const maxOpenAPIRefDepth = 32
+
+// pointer returns the node of the document that the local JSON pointer refers to, e.g. "#/definitions/Pet".
+func (im *openAPIImporter) pointer(ref string) *yaml.Node {
+	path, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil
+	}
+	n := im.root
+	for _, token := range strings.Split(path, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch n.Kind {
+		case yaml.MappingNode:
+			n = im.get(n, token)
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n.Content) {
+				return nil
+			}
+			n = n.Content[i]
+		default:
+			return nil
+		}
+		if n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// schemasPrefix returns the prefix of references to schemas that are converted into types.
+func (im *openAPIImporter) schemasPrefix() string {
+	if im.version == 2 {
+		return "#/definitions/"
+	}
+	return "#/components/schemas/"
+}
+
+func (im *openAPIImporter) schemas() *yaml.Node {
+	if im.version == 2 {
+		return im.get(im.root, "definitions")
+	}
+	return im.get(im.get(im.root, "components"), "schemas")
+}
+
+// typeName returns the name of the type of the referenced schema.
+func (im *openAPIImporter) typeName(ref string) (string, bool) {
+	name, ok := strings.CutPrefix(ref, im.schemasPrefix())
+	if !ok {
+		return "", false
+	}
+	name = strings.ReplaceAll(strings.ReplaceAll(name, "~1", "/"), "~0", "~")
+	return name, im.get(im.schemas(), name) != nil
+}
+
+func (im *openAPIImporter) convert() (*yaml.Node, error) {
+	switch {
+	case strings.HasPrefix(im.value(im.root, "swagger"), "2."):
+		im.version = 2
+	case strings.HasPrefix(im.value(im.root, "openapi"), "3.0"):
+		im.version = 3
+	default:
+		return nil, stacktrace.New("unsupported OpenAPI version, Swagger 2.0 or OpenAPI 3.0 is expected",
+			im.location, stacktrace.WithType(stacktrace.TypeParsing))
+	}
+	out := newMappingNode()
+	info := im.get(im.root, "info")
+	title := im.value(info, "title")
+	if title == "" {
+		title = "API"
+	}
+	setNode(out, "title", newStrNode(title))
+	if description := im.value(info, "description"); description != "" {
+		setNode(out, "description", newStrNode(description))
+	}
+	if version := im.value(info, "version"); version != "" {
+		setNode(out, "version", newStrNode(version))
+	}
+	im.convertBaseURI(out)
+	if security := im.get(im.root, "security"); security != nil {
+		im.warn("security requirements are not imported", security)
+	}
+
+	types := newMappingNode()
+	for _, pair := range im.pairs(im.schemas()) {
+		setNode(types, pair[0].Value, im.schema(pair[1]))
+	}
+	if len(types.Content) > 0 {
+		setNode(out, "types", types)
+	}
+	for _, pair := range im.pairs(im.get(im.root, "paths")) {
+		path := pair[0].Value
+		if !strings.HasPrefix(path, "/") {
+			continue
+		}
+		item := im.resolve(pair[1])
+		if item == nil {
+			continue
+		}
+		setNode(out, path, im.resource(item))
+	}
+	return out, nil
+}
+
+func (im *openAPIImporter) convertBaseURI(out *yaml.Node) {
+	if im.version == 2 {
+		schemes := im.get(im.root, "schemes")
+		var protocols []string
+		if schemes != nil {
+			for _, s := range schemes.Content {
+				if p := strings.ToUpper(s.Value); p == "HTTP" || p == "HTTPS" {
+					protocols = append(protocols, p)
+				}
+			}
+		}
+		host := im.value(im.root, "host")
+		if host == "" {
+			return
+		}
+		scheme := "https"
+		if len(protocols) > 0 {
+			scheme = strings.ToLower(protocols[0])
+		}
+		setNode(out, "baseUri", newStrNode(scheme+"://"+host+im.value(im.root, "basePath")))
+		if len(protocols) > 0 {
+			seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+			for _, p := range protocols {
+				seq.Content = append(seq.Content, newStrNode(p))
+			}
+			setNode(out, "protocols", seq)
+		}
+		return
+	}
+	servers := im.get(im.root, "servers")
+	if servers == nil || servers.Kind != yaml.SequenceNode || len(servers.Content) == 0 {
+		return
+	}
+	server := servers.Content[0]
+	if len(servers.Content) > 1 {
+		im.warn("only the first server is imported as the base URI", servers.Content[1])
+	}
+	uri := im.value(server, "url")
+	if uri == "" {
+		return
+	}
+	setNode(out, "baseUri", newStrNode(uri))
+	params := newMappingNode()
+	for _, pair := range im.pairs(im.get(server, "variables")) {
+		p := newMappingNode()
+		setNode(p, "type", newStrNode(TypeString))
+		if enum := im.get(pair[1], "enum"); enum != nil {
+			setNode(p, "enum", enum)
+		}
+		if def := im.get(pair[1], "default"); def != nil {
+			setNode(p, "default", def)
+		}
+		if description := im.value(pair[1], "description"); description != "" {
+			setNode(p, "description", newStrNode(description))
+		}
+		setNode(params, pair[0].Value, p)
+	}
+	if len(params.Content) > 0 {
+		setNode(out, "baseUriParameters", params)
+	}
+}
+
+// resource converts the path item into a resource.
+func (im *openAPIImporter) resource(item *yaml.Node) *yaml.Node {
+	res := newMappingNode()
+	if summary := im.value(item, "summary"); summary != "" {
+		setNode(res, "displayName", newStrNode(summary))
+	}
+	if description := im.value(item, "description"); description != "" {
+		setNode(res, "description", newStrNode(description))
+	}
+	uriParameters := newMappingNode()
+	common := im.parameters(item, nil, uriParameters)
+	methods := newMappingNode()
+	for _, method := range openAPIMethods {
+		if op := im.get(item, method); op != nil {
+			setNode(methods, method, im.method(op, common, uriParameters))
+		}
+	}
+	if len(uriParameters.Content) > 0 {
+		setNode(res, "uriParameters", uriParameters)
+	}
+	res.Content = append(res.Content, methods.Content...)
+	return res
+}
+
+// openAPIParameter is a resolved parameter of a path item or an operation.
+type openAPIParameter struct {
+	name string
+	in   string
+	node *yaml.Node
+}
+
+// parameters returns parameters of the path item or the operation that override the inherited ones. Path
+// parameters are declared as URI parameters of the resource.
+func (im *openAPIImporter) parameters(
+	n *yaml.Node, inherited []openAPIParameter, uriParameters *yaml.Node,
+) []openAPIParameter {
+	params := append([]openAPIParameter(nil), inherited...)
+	list := im.get(n, "parameters")
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return params
+	}
+	for _, item := range list.Content {
+		p := im.resolve(item)
+		if p == nil {
+			continue
+		}
+		param := openAPIParameter{name: im.value(p, "name"), in: im.value(p, "in"), node: p}
+		overridden := false
+		for i := range params {
+			if params[i].name == param.name && params[i].in == param.in {
+				params[i] = param
+				overridden = true
+			}
+		}
+		if !overridden {
+			params = append(params, param)
+		}
+		if param.in == "path" && mappingIndex(uriParameters, param.name) < 0 {
+			setNode(uriParameters, param.name, im.parameter(param))
+		}
+	}
+	return params
+}
+
+// parameter converts the parameter into a type declaration.
+func (im *openAPIImporter) parameter(p openAPIParameter) *yaml.Node {
+	schema := im.get(p.node, "schema")
+	if im.version == 3 && schema == nil {
+		for _, pair := range im.pairs(im.get(p.node, "content")) {
+			schema = im.get(pair[1], "schema")
+			break
+		}
+	}
+	if im.version == 2 && p.in != "body" {
+		// Parameters of Swagger 2.0 are schemas themselves.
+		schema = newMappingNode()
+		schema.Line, schema.Column = p.node.Line, p.node.Column
+		for _, pair := range im.pairs(p.node) {
+			switch pair[0].Value {
+			case "name", "in", "required", "description", "collectionFormat", "allowEmptyValue":
+			default:
+				schema.Content = append(schema.Content, pair[0], pair[1])
+			}
+		}
+	}
+	decl := im.declaration(im.schema(schema))
+	im.setDescription(decl, im.value(p.node, "description"))
+	im.setExample(decl, im.get(p.node, "example"))
+	required := p.in == "path" || im.value(p.node, "required") == "true"
+	if i := mappingIndex(decl, "required"); i >= 0 {
+		decl.Content = append(decl.Content[:i], decl.Content[i+2:]...)
+	}
+	setNode(decl, "required", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(required)})
+	return decl
+}
+
+// method converts the operation into a method.
+func (im *openAPIImporter) method(op *yaml.Node, inherited []openAPIParameter, uriParameters *yaml.Node) *yaml.Node {
+	m := newMappingNode()
+	if summary := im.value(op, "summary"); summary != "" {
+		setNode(m, "displayName", newStrNode(summary))
+	}
+	if description := im.value(op, "description"); description != "" {
+		setNode(m, "description", newStrNode(description))
+	}
+	if security := im.get(op, "security"); security != nil {
+		im.warn("security requirements are not imported", security)
+	}
+	queryParameters, headers := newMappingNode(), newMappingNode()
+	form := newMappingNode()
+	hasFiles := false
+	var body *yaml.Node
+	for _, p := range im.parameters(op, inherited, uriParameters) {
+		switch p.in {
+		case "query":
+			setNode(queryParameters, p.name, im.parameter(p))
+		case "header":
+			setNode(headers, p.name, im.parameter(p))
+		case "path":
+		case "body":
+			body = newMappingNode()
+			for _, mediaType := range im.mediaTypes(op, "consumes") {
+				decl := im.declaration(im.schema(im.get(p.node, "schema")))
+				im.setDescription(decl, im.value(p.node, "description"))
+				setNode(body, mediaType, decl)
+			}
+		case "formData":
+			hasFiles = hasFiles || im.value(p.node, "type") == "file"
+			setNode(form, p.name, im.parameter(p))
+		default:
+			im.warn(fmt.Sprintf("%s parameter %q is not imported", p.in, p.name), p.node)
+		}
+	}
+	if len(queryParameters.Content) > 0 {
+		setNode(m, "queryParameters", queryParameters)
+	}
+	if len(headers.Content) > 0 {
+		setNode(m, "headers", headers)
+	}
+	if len(form.Content) > 0 {
+		mediaType := "application/x-www-form-urlencoded"
+		if hasFiles {
+			mediaType = "multipart/form-data"
+		}
+		decl := newMappingNode()
+		setNode(decl, "type", newStrNode(TypeObject))
+		setNode(decl, "properties", form)
+		body = newMappingNode()
+		setNode(body, mediaType, decl)
+	}
+	if requestBody := im.resolve(im.get(op, "requestBody")); requestBody != nil {
+		body = im.content(requestBody)
+	}
+	if body != nil && len(body.Content) > 0 {
+		setNode(m, "body", body)
+	}
+	responses := newMappingNode()
+	for _, pair := range im.pairs(im.get(op, "responses")) {
+		code := pair[0].Value
+		if strings.HasPrefix(code, "x-") {
+			continue
+		}
+		if n, err := strconv.Atoi(code); err != nil || n < 100 || n > 599 {
+			im.warn(fmt.Sprintf("response %q is not imported, RAML responses have status codes", code), pair[0])
+			continue
+		}
+		if resp := im.resolve(pair[1]); resp != nil {
+			key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: code}
+			responses.Content = append(responses.Content, key, im.response(op, resp))
+		}
+	}
+	if len(responses.Content) > 0 {
+		setNode(m, "responses", responses)
+	}
+	return m
+}
+
+// mediaTypes returns media types of Swagger 2.0 bodies of the operation: its consumes or produces, the ones of the
+// document or application/json.
+func (im *openAPIImporter) mediaTypes(op *yaml.Node, key string) []string {
+	for _, n := range []*yaml.Node{im.get(op, key), im.get(im.root, key)} {
+		if n == nil || n.Kind != yaml.SequenceNode || len(n.Content) == 0 {
+			continue
+		}
+		result := make([]string, len(n.Content))
+		for i, mt := range n.Content {
+			result[i] = mt.Value
+		}
+		return result
+	}
+	return []string{"application/json"}
+}
+
+// content converts the content of the OpenAPI 3.0 request body or response into bodies by media types.
+func (im *openAPIImporter) content(n *yaml.Node) *yaml.Node {
+	body := newMappingNode()
+	for _, pair := range im.pairs(im.get(n, "content")) {
+		decl := im.declaration(im.schema(im.get(pair[1], "schema")))
+		im.setExample(decl, im.get(pair[1], "example"))
+		setNode(body, pair[0].Value, decl)
+	}
+	return body
+}
+
+// response converts the response of the operation.
+func (im *openAPIImporter) response(op *yaml.Node, resp *yaml.Node) *yaml.Node {
+	r := newMappingNode()
+	if description := im.value(resp, "description"); description != "" {
+		setNode(r, "description", newStrNode(description))
+	}
+	headers := newMappingNode()
+	for _, pair := range im.pairs(im.get(resp, "headers")) {
+		h := im.resolve(pair[1])
+		if h == nil {
+			continue
+		}
+		if im.version == 3 {
+			// Headers of OpenAPI 3.0 responses are optional by default, unlike RAML ones.
+			h = im.withDefault(h, "required", "false")
+		}
+		setNode(headers, pair[0].Value, im.parameter(openAPIParameter{name: pair[0].Value, in: "header", node: h}))
+	}
+	if len(headers.Content) > 0 {
+		setNode(r, "headers", headers)
+	}
+	var body *yaml.Node
+	if im.version == 2 {
+		if schema := im.get(resp, "schema"); schema != nil {
+			body = newMappingNode()
+			examples := im.get(resp, "examples")
+			for _, mediaType := range im.mediaTypes(op, "produces") {
+				decl := im.declaration(im.schema(schema))
+				im.setExample(decl, im.get(examples, mediaType))
+				setNode(body, mediaType, decl)
+			}
+		}
+	} else {
+		body = im.content(resp)
+	}
+	if body != nil && len(body.Content) > 0 {
+		setNode(r, "body", body)
+	}
+	return r
+}
+
+// withDefault returns the mapping with the key set to the value if it is not set.
+func (im *openAPIImporter) withDefault(n *yaml.Node, key string, value string) *yaml.Node {
+	if im.get(n, key) != nil {
+		return n
+	}
+	c := *n
+	c.Content = append(append([]*yaml.Node(nil), n.Content...), newStrNode(key),
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: value})
+	return &c
+}
+
+// declaration returns the type declaration as a mapping, e.g. {type: Pet} for Pet.
+func (im *openAPIImporter) declaration(n *yaml.Node) *yaml.Node {
+	if n.Kind == yaml.MappingNode {
+		return n
+	}
+	m := newMappingNode()
+	setNode(m, "type", n)
+	return m
+}
+
+func (im *openAPIImporter) setDescription(decl *yaml.Node, description string) {
+	if description != "" && mappingIndex(decl, "description") < 0 {
+		setNode(decl, "description", newStrNode(description))
+	}
+}
+
+func (im *openAPIImporter) setExample(decl *yaml.Node, example *yaml.Node) {
+	if example != nil && mappingIndex(decl, "example") < 0 && mappingIndex(decl, "examples") < 0 {
+		setNode(decl, "example", example)
+	}
+}
+
+// schema converts the schema into a type declaration. Schemas that cannot be converted are reported and become any.
+func (im *openAPIImporter) schema(n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return newStrNode(TypeAny)
+	}
+	if im.value(n, "type") == "file" {
+		return newStrNode(TypeFile)
+	}
+	data, err := yamlNodeJSON(im.normalizeSchema(n))
+	if err != nil {
+		im.warn(fmt.Sprintf("schema is not imported: %s", err.Error()), n)
+		return newStrNode(TypeAny)
+	}
+	var s *JSONSchema
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err = dec.Decode(&s); err != nil || s == nil {
+		im.warn(fmt.Sprintf("schema is not imported: %v", err), n)
+		return newStrNode(TypeAny)
+	}
+	conv := &jsonSchemaImporter{root: s, line: n.Line, column: n.Column, refs: make(map[string]struct{}),
+		typeName: im.typeName}
+	decl, err := conv.convert(s)
+	if err != nil {
+		im.warn(fmt.Sprintf("schema is not imported: %s", err.Error()), n)
+		return newStrNode(TypeAny)
+	}
+	return decl
+}
+
+// openAPIAnnotationKeys are keywords of schemas that are kept on the union of nullable schemas.
+var openAPIAnnotationKeys = map[string]struct{}{
+	"title": {}, "description": {}, "default": {}, "examples": {},
+}
+
+// normalizeSchema rewrites keywords of OpenAPI schemas into their JSON schema equivalents: nullable into anyOf with
+// null, example into examples, boolean exclusiveMinimum and exclusiveMaximum into numbers and additionalProperties
+// schemas into pattern properties.
+func (im *openAPIImporter) normalizeSchema(n *yaml.Node) *yaml.Node {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!bool" {
+		if n.Value == "false" {
+			im.warn("false schema is not imported", n)
+		}
+		return newMappingNode()
+	}
+	if n.Kind != yaml.MappingNode {
+		return n
+	}
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: n.Line, Column: n.Column}
+	var nullable, exclusiveMinimum, exclusiveMaximum bool
+	var example, additional *yaml.Node
+	for _, pair := range im.pairs(n) {
+		key, v := pair[0].Value, pair[1]
+		switch key {
+		case "properties", "patternProperties":
+			m := newMappingNode()
+			for _, prop := range im.pairs(v) {
+				setNode(m, prop[0].Value, im.normalizeSchema(prop[1]))
+			}
+			setNode(out, key, m)
+		case "items", "not":
+			setNode(out, key, im.normalizeSchema(v))
+		case "allOf", "anyOf", "oneOf":
+			seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			for _, member := range v.Content {
+				seq.Content = append(seq.Content, im.normalizeSchema(member))
+			}
+			setNode(out, key, seq)
+		case "additionalProperties":
+			switch {
+			case v.Kind == yaml.MappingNode && len(v.Content) > 0:
+				additional = im.normalizeSchema(v)
+			case v.Kind == yaml.ScalarNode:
+				setNode(out, key, v)
+			}
+		case "nullable":
+			nullable = v.Value == "true"
+		case "example":
+			example = v
+		case "exclusiveMinimum":
+			exclusiveMinimum = v.Value == "true"
+		case "exclusiveMaximum":
+			exclusiveMaximum = v.Value == "true"
+		case "discriminator", "readOnly", "writeOnly", "xml", "externalDocs", "deprecated":
+		default:
+			setNode(out, key, v)
+		}
+	}
+	if exclusiveMinimum {
+		if i := mappingIndex(out, "minimum"); i >= 0 {
+			out.Content[i] = newStrNode("exclusiveMinimum")
+		}
+	}
+	if exclusiveMaximum {
+		if i := mappingIndex(out, "maximum"); i >= 0 {
+			out.Content[i] = newStrNode("exclusiveMaximum")
+		}
+	}
+	if example != nil && mappingIndex(out, "examples") < 0 {
+		setNode(out, "examples", &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{example}})
+	}
+	if additional != nil {
+		patterns := im.get(out, "patternProperties")
+		if patterns == nil {
+			patterns = newMappingNode()
+			setNode(out, "patternProperties", patterns)
+		}
+		setNode(patterns, "", additional)
+	}
+	if !nullable {
+		return out
+	}
+	return im.nullable(n, out)
+}
+
+// nullable returns the union of the schema and null. RAML unions are type expressions, so schemas with facets other
+// than annotations are kept non-nullable.
+func (im *openAPIImporter) nullable(n *yaml.Node, schema *yaml.Node) *yaml.Node {
+	union, member := newMappingNode(), newMappingNode()
+	union.Line, union.Column = schema.Line, schema.Column
+	typ := im.value(schema, "type")
+	for i := 0; i < len(schema.Content); i += 2 {
+		key := schema.Content[i].Value
+		switch _, annotation := openAPIAnnotationKeys[key]; {
+		case annotation:
+			union.Content = append(union.Content, schema.Content[i], schema.Content[i+1])
+		case key == "type", key == "$ref", key == "format" && typ == "string":
+			member.Content = append(member.Content, schema.Content[i], schema.Content[i+1])
+		default:
+			im.warn(fmt.Sprintf("nullable is not imported for a schema with %s", key), n)
+			return schema
+		}
+	}
+	null := newMappingNode()
+	setNode(null, "type", newStrNode("null"))
+	setNode(union, "anyOf", &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{member, null}})
+	return union
+}
+
+// yamlNodeJSON encodes the YAML node as JSON, keeping the order of keys.
+func yamlNodeJSON(n *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeYAMLNodeJSON(&buf, n); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeYAMLNodeJSON(buf *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeYAMLNodeJSON(buf, n.Content[0])
+	case yaml.AliasNode:
+		return writeYAMLNodeJSON(buf, n.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(n.Content[i].Value)
+			if err != nil {
+				return fmt.Errorf("encode key: %w", err)
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err = writeYAMLNodeJSON(buf, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeYAMLNodeJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		// Numbers are written as is to keep their precision.
+		if (n.Tag == "!!int" || n.Tag == "!!float") && json.Valid([]byte(n.Value)) {
+			buf.WriteString(n.Value)
+			return nil
+		}
+		var v any
+		if err := n.Decode(&v); err != nil {
+			return fmt.Errorf("decode scalar: %w", err)
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("encode scalar: %w", err)
+		}
+		buf.Write(data)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package raml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportOpenAPI(t *testing.T) {
+	content := `openapi: 3.0.3
+info:
+  title: Pets
+  version: v1
+servers:
+  - url: https://{tenant}.example.com/api
+    variables:
+      tenant:
+        default: acme
+paths:
+  /pets:
+    get:
+      summary: List pets
+      parameters:
+        - $ref: '#/components/parameters/limit'
+        - name: X-Request-Id
+          in: header
+          required: true
+          schema:
+            type: string
+        - name: session
+          in: cookie
+          schema:
+            type: string
+      responses:
+        '200':
+          description: Pets.
+          headers:
+            X-Total:
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+        default:
+          description: Error.
+    post:
+      requestBody:
+        $ref: '#/components/requestBodies/Pet'
+      responses:
+        '201':
+          description: Created.
+  /pets/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+          minimum: 1
+    get:
+      responses:
+        '200':
+          description: A pet.
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Cat'
+components:
+  parameters:
+    limit:
+      name: limit
+      in: query
+      description: Page size.
+      schema:
+        type: integer
+        maximum: 100
+        exclusiveMaximum: true
+  requestBodies:
+    Pet:
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Pet'
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          maxLength: 10
+        tag:
+          type: string
+          nullable: true
+          description: A tag.
+        labels:
+          type: object
+          additionalProperties:
+            type: string
+      example:
+        name: Rex
+    Cat:
+      allOf:
+        - $ref: '#/components/schemas/Pet'
+        - type: object
+          properties:
+            lives:
+              type: integer
+`
+	rml, err := ImportOpenAPI([]byte(content), "openapi.yaml", t.TempDir(), OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	api := rml.API()
+	require.Equal(t, "Pets", api.Title)
+	require.Equal(t, "https://{tenant}.example.com/api", api.BaseURI)
+	require.NotNil(t, api.BaseURIParameters.Value("tenant"))
+
+	pets := api.Resources.Value("/pets")
+	list := pets.Methods.Value("get")
+	require.Equal(t, "List pets", list.DisplayName)
+	limit := list.QueryParameters.Value("limit")
+	require.NotNil(t, limit)
+	require.NoError(t, limit.Validate(99))
+	require.Error(t, limit.Validate(100))
+	require.True(t, *list.Headers.Value("X-Request-Id").Required)
+	ok := list.Responses.Value(200)
+	require.NotNil(t, ok)
+	require.False(t, *ok.Headers.Value("X-Total").Required)
+	items := ok.Body.Value("application/json")
+	require.NoError(t, items.Validate([]any{map[string]any{"name": "Rex", "tag": nil, "labels": map[string]any{
+		"a": "b",
+	}}}))
+	require.Error(t, items.Validate([]any{map[string]any{"name": "Rex with a long name"}}))
+	labels := items.Shape.(*ArrayShape).Items.Shape.(*ObjectShape).Properties.Value("labels").Shape
+	require.NotNil(t, labels.Shape.(*ObjectShape).PatternProperties.Value("//").Shape)
+	require.NotNil(t, pets.Methods.Value("post").Body.Value("application/json"))
+
+	byID := api.Resources.Value("/pets/{id}")
+	require.Error(t, byID.URIParameters.Value("id").Validate(0))
+	cat := byID.Methods.Value("get").Responses.Value(200).Body.Value("application/json")
+	require.NoError(t, cat.Validate(map[string]any{"name": "Tom", "lives": 9}))
+	require.Error(t, cat.Validate(map[string]any{"lives": 9}))
+
+	var warnings []string
+	for _, w := range rml.Warnings() {
+		warnings = append(warnings, w.Message)
+	}
+	require.Contains(t, warnings, `cookie parameter "session" is not imported`)
+	require.Contains(t, warnings, `response "default" is not imported, RAML responses have status codes`)
+}
+
+func TestImportOpenAPI_Swagger(t *testing.T) {
+	content := `{
+  "swagger": "2.0",
+  "info": {"title": "Files", "version": "1"},
+  "host": "files.example.com",
+  "basePath": "/v1",
+  "schemes": ["https"],
+  "consumes": ["application/json"],
+  "produces": ["application/json"],
+  "paths": {
+    "/files": {
+      "post": {
+        "consumes": ["multipart/form-data"],
+        "parameters": [
+          {"name": "file", "in": "formData", "type": "file", "required": true},
+          {"name": "name", "in": "formData", "type": "string"}
+        ],
+        "responses": {"201": {"description": "Uploaded.", "schema": {"$ref": "#/definitions/File"}}}
+      },
+      "get": {
+        "parameters": [
+          {"name": "tags", "in": "query", "type": "array", "items": {"type": "string"}, "maxItems": 2}
+        ],
+        "responses": {
+          "200": {
+            "description": "Files.",
+            "schema": {"type": "array", "items": {"$ref": "#/definitions/File"}},
+            "examples": {"application/json": [{"id": 1}]}
+          }
+        }
+      }
+    },
+    "/files/{id}": {
+      "put": {
+        "parameters": [
+          {"name": "id", "in": "path", "type": "integer", "required": true},
+          {"name": "body", "in": "body", "schema": {"$ref": "#/definitions/File"}}
+        ],
+        "responses": {"204": {"description": "Updated."}}
+      }
+    }
+  },
+  "definitions": {
+    "File": {
+      "type": "object",
+      "required": ["id"],
+      "properties": {"id": {"type": "integer", "format": "int64"}, "name": {"type": "string"}}
+    }
+  }
+}`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "swagger.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	rml, err := ImportOpenAPIFromPath(path, OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	api := rml.API()
+	require.Equal(t, "https://files.example.com/v1", api.BaseURI)
+	require.Equal(t, []string{"HTTPS"}, api.Protocols)
+
+	files := api.Resources.Value("/files")
+	upload := files.Methods.Value("post").Body.Value("multipart/form-data")
+	require.NotNil(t, upload)
+	file := upload.Shape.(*ObjectShape).Properties.Value("file")
+	require.Equal(t, TypeFile, file.Shape.Type)
+	require.True(t, file.Required)
+	require.False(t, upload.Shape.(*ObjectShape).Properties.Value("name").Required)
+
+	tags := files.Methods.Value("get").QueryParameters.Value("tags")
+	require.False(t, *tags.Required)
+	require.Error(t, tags.Validate([]any{"a", "b", "c"}))
+	list := files.Methods.Value("get").Responses.Value(200).Body.Value("application/json")
+	require.NotNil(t, list.Example)
+	require.Error(t, list.Validate([]any{map[string]any{"name": "a"}}))
+
+	update := api.Resources.Value("/files/{id}").Methods.Value("put")
+	require.NoError(t, update.Body.Value("application/json").Validate(map[string]any{"id": 1}))
+
+	converted, warnings, err := ConvertOpenAPI([]byte(content), path)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+	require.Contains(t, string(converted), "#%RAML 1.0\ntitle: Files\n")
+
+	_, _, err = ConvertOpenAPI([]byte(`{"openapi": "3.1.0"}`), path)
+	require.ErrorContains(t, err, "unsupported OpenAPI version")
+}
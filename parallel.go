@@ -0,0 +1,272 @@
+package raml
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/acronis/go-stacktrace"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+type parseOptWithParallelResolution struct {
+	workers int
+}
+
+func (o parseOptWithParallelResolution) Apply(opt *parserOptions) {
+	opt.resolveWorkers = o.workers
+	if opt.resolveWorkers <= 0 {
+		opt.resolveWorkers = runtime.GOMAXPROCS(0)
+	}
+}
+
+// OptWithParallelResolution unwraps declared types by the number of concurrent workers (GOMAXPROCS if not positive)
+// when the model is resolved. Types are scheduled by their dependency graph: a type is unwrapped after the types it
+// refers to, and types that refer to each other are unwrapped together by one worker. The order of shapes returned
+// by RAML.GetShapes is not deterministic with this option, neither are IDs of shapes created by resolution unless
+// they are derived (see OptWithDerivedShapeIDs).
+func OptWithParallelResolution(workers int) ParseOpt {
+	return parseOptWithParallelResolution{workers: workers}
+}
+
+// declaration is a type or an annotation type declared by a fragment.
+type declaration struct {
+	location string
+	// types is the map of the library the type is declared in, nil for data type fragments.
+	types        *orderedmap.OrderedMap[string, *BaseShape]
+	key          string
+	isAnnotation bool
+	dataType     *DataType
+	base         *BaseShape
+}
+
+// unwrapFragmentsParallel unwraps declared types like unwrapFragments does, but independent types are unwrapped
+// concurrently.
+func (r *RAML) unwrapFragmentsParallel(ctx context.Context) *stacktrace.StackTrace {
+	var st *stacktrace.StackTrace
+	appendErr := func(se *stacktrace.StackTrace) {
+		if st == nil {
+			st = se
+		} else {
+			st = st.Append(se)
+		}
+	}
+
+	var decls []declaration
+	for _, loc := range r.fragmentLocations() {
+		switch f := r.fragmentsCache[loc].(type) {
+		case *Library:
+			for _, section := range []struct {
+				types        *orderedmap.OrderedMap[string, *BaseShape]
+				isAnnotation bool
+			}{{f.AnnotationTypes, true}, {f.Types, false}} {
+				for pair := section.types.Oldest(); pair != nil; pair = pair.Next() {
+					if pair.Value == nil {
+						appendErr(stacktrace.New("shape is nil", f.Location,
+							stacktrace.WithType(stacktrace.TypeUnwrapping)))
+						continue
+					}
+					decls = append(decls, declaration{location: f.Location, types: section.types, key: pair.Key,
+						isAnnotation: section.isAnnotation, base: pair.Value})
+				}
+			}
+		case *DataType:
+			if f.Shape == nil {
+				appendErr(stacktrace.New("shape is nil", f.Location,
+					stacktrace.WithType(stacktrace.TypeUnwrapping)))
+				continue
+			}
+			decls = append(decls, declaration{location: f.Location, dataType: f, base: f.Shape})
+		}
+	}
+
+	shapes := make([]*BaseShape, len(decls))
+	for i, d := range decls {
+		shapes[i] = d.base
+	}
+	results, errs := r.unwrapScheduled(ctx, shapes)
+	for i, d := range decls {
+		if errs[i] != nil {
+			appendErr(StacktraceNewWrapped("unwrap shape", errs[i], d.location,
+				stacktrace.WithType(stacktrace.TypeUnwrapping), stacktrace.WithPosition(&d.base.Position)))
+			continue
+		}
+		us := results[i]
+		switch {
+		case d.dataType != nil:
+			d.dataType.Shape = us
+			r.PutTypeIntoFragment(us.Name, d.location, us)
+		case d.isAnnotation:
+			d.types.Set(d.key, us)
+			r.PutAnnotationTypeIntoFragment(us.Name, d.location, d.base)
+		default:
+			d.types.Set(d.key, us)
+			r.PutTypeIntoFragment(us.Name, d.location, d.base)
+		}
+	}
+	return st
+}
+
+// unwrapScheduled unwraps the shapes by r.resolveWorkers workers and returns unwrapped shapes and errors in the
+// order of the shapes. Strongly connected components of the dependency graph of the shapes are unwrapped by one
+// worker after all components they depend on.
+func (r *RAML) unwrapScheduled(ctx context.Context, shapes []*BaseShape) ([]*BaseShape, []error) {
+	// The type graph builder collects dependencies between the shapes named by their indexes.
+	b := &typeGraphBuilder{
+		graph: &TypeGraph{Edges: make(map[string][]TypeDependency)},
+		names: make(map[int64]string),
+		edges: make(map[TypeDependency]struct{}),
+	}
+	for i, s := range shapes {
+		b.addNode(strconv.Itoa(i), "", s)
+	}
+	// The same shape may be declared more than once, e.g. by a data type fragment that is included by a library.
+	node := make([]int, len(shapes))
+	deps := make([][]int, len(shapes))
+	for i, s := range shapes {
+		node[i], _ = strconv.Atoi(b.names[s.ID])
+		if node[i] != i {
+			// Repeated declarations wait for the first one and reuse its result.
+			deps[i] = []int{node[i]}
+		}
+	}
+	for _, n := range b.graph.Nodes {
+		i, _ := strconv.Atoi(n.Name)
+		b.walk(n.Name, n.Shape, "", true, make(map[int64]struct{}))
+		for _, e := range b.graph.Edges[n.Name] {
+			j, _ := strconv.Atoi(e.To)
+			deps[i] = append(deps[i], j)
+		}
+	}
+
+	components := stronglyConnected(len(shapes), deps)
+	component := make([]int, len(shapes))
+	for c, nodes := range components {
+		for _, i := range nodes {
+			component[i] = c
+		}
+	}
+	// pending counts components that the component depends on and that are not unwrapped yet.
+	pending := make([]int, len(components))
+	dependents := make([][]int, len(components))
+	for c, nodes := range components {
+		seen := make(map[int]struct{})
+		for _, i := range nodes {
+			for _, j := range deps[i] {
+				dc := component[j]
+				if _, ok := seen[dc]; ok || dc == c {
+					continue
+				}
+				seen[dc] = struct{}{}
+				pending[c]++
+				dependents[dc] = append(dependents[dc], c)
+			}
+		}
+	}
+
+	results := make([]*BaseShape, len(shapes))
+	errs := make([]error, len(shapes))
+	ready := make(chan int, len(components))
+	for c := range components {
+		if pending[c] == 0 {
+			ready <- c
+		}
+	}
+	if len(components) == 0 {
+		close(ready)
+	}
+	var (
+		mu   sync.Mutex
+		done int
+		wg   sync.WaitGroup
+	)
+	for range r.resolveWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range ready {
+				for _, i := range components[c] {
+					// The rest of components are drained without unwrapping once the context is done.
+					if errs[i] = ctx.Err(); errs[i] == nil {
+						results[i], errs[i] = r.unwrapBaseShape(shapes[i])
+					}
+				}
+				mu.Lock()
+				for _, dc := range dependents[c] {
+					pending[dc]--
+					if pending[dc] == 0 {
+						ready <- dc
+					}
+				}
+				done++
+				if done == len(components) {
+					close(ready)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range shapes {
+		if node[i] != i {
+			results[i], errs[i] = results[node[i]], errs[node[i]]
+		}
+	}
+	return results, errs
+}
+
+// stronglyConnected returns strongly connected components of the graph found by Tarjan's algorithm, dependencies
+// first. Nodes of components are sorted.
+func stronglyConnected(n int, edges [][]int) [][]int {
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+	var (
+		stack      []int
+		components [][]int
+		counter    int
+	)
+	var visit func(v int)
+	visit = func(v int) {
+		index[v], low[v] = counter, counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range edges[v] {
+			switch {
+			case index[w] == -1:
+				visit(w)
+				low[v] = min(low[v], low[w])
+			case onStack[w]:
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var component []int
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		slices.Sort(component)
+		components = append(components, component)
+	}
+	for v := range n {
+		if index[v] == -1 {
+			visit(v)
+		}
+	}
+	return components
+}
@@ -0,0 +1,122 @@
+package raml
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptWithParallelResolution(t *testing.T) {
+	dir, err := filepath.Abs("./fixtures/parallel")
+	require.NoError(t, err)
+	content := `#%RAML 1.0 Library
+uses:
+  common: common.raml
+annotationTypes:
+  owner: common.Id
+types:
+  Cat:
+    type: common.Pet
+    properties:
+      name:
+        type: string
+        maxLength: 10
+      friends?: Cat[]
+      tag: !include tag.raml
+  Tagged:
+    properties:
+      tags: string[]
+  TaggedCat:
+    type: [Cat, Tagged]
+  Owner:
+    properties:
+      id: common.Id
+      pet: Cat | common.Pet
+  Key: common.Id
+  Code:
+    type: Key
+    minLength: 3
+`
+	parse := func(opts ...ParseOpt) map[string]string {
+		r, err := ParseFromString(content, "library.raml", dir, append(opts, OptWithUnwrap())...)
+		require.NoError(t, err)
+		fingerprints := make(map[string]string)
+		for _, n := range r.TypeGraph().Nodes {
+			fingerprints[n.Name], err = n.Shape.Fingerprint()
+			require.NoError(t, err)
+		}
+		owner, err := r.GetAnnotationTypeFromFragmentPtr(r.GetLocation(), "owner")
+		require.NoError(t, err)
+		require.True(t, owner.IsUnwrapped())
+		fingerprints["(owner)"], err = owner.Fingerprint()
+		require.NoError(t, err)
+		return fingerprints
+	}
+
+	want := parse()
+	require.Len(t, want, 10)
+	for _, workers := range []int{0, 1, 4} {
+		t.Run(fmt.Sprint(workers), func(t *testing.T) {
+			require.Equal(t, want, parse(OptWithParallelResolution(workers)))
+		})
+	}
+
+	_, err = ParseFromString(`#%RAML 1.0 Library
+types:
+  A:
+    type: string
+    maxLength: 5
+  B:
+    type: A
+    maxLength: 10
+`, "library.raml", dir, OptWithUnwrap(), OptWithParallelResolution(4))
+	require.ErrorContains(t, err, "maxLength constraint violation")
+}
+
+func TestStronglyConnected(t *testing.T) {
+	// 0 -> 1 -> 2 -> 1, 3 -> 0, 4
+	components := stronglyConnected(5, [][]int{{1}, {2}, {1}, {0}, nil})
+	require.Equal(t, [][]int{{1, 2}, {0}, {3}, {4}}, components)
+}
+
+// BenchmarkUnwrapShapes compares sequential and parallel resolution of a library with thousands of types that form
+// independent inheritance chains.
+func BenchmarkUnwrapShapes(b *testing.B) {
+	const chains, depth = 200, 10
+	var sb strings.Builder
+	sb.WriteString("#%RAML 1.0 Library\ntypes:\n")
+	for c := range chains {
+		for d := range depth {
+			fmt.Fprintf(&sb, "  T%d_%d:\n", c, d)
+			if d > 0 {
+				fmt.Fprintf(&sb, "    type: T%d_%d\n", c, d-1)
+			}
+			sb.WriteString("    properties:\n")
+			for p := range 10 {
+				fmt.Fprintf(&sb, "      p%d_%d:\n        type: string\n        maxLength: %d\n", d, p, 100-d)
+			}
+			fmt.Fprintf(&sb, "      items%d: T%d_0[]\n", d, c)
+		}
+	}
+	content := sb.String()
+	dir := b.TempDir()
+
+	for name, opts := range map[string][]ParseOpt{
+		"sequential": nil,
+		"parallel":   {OptWithParallelResolution(0)},
+	} {
+		b.Run(name, func(b *testing.B) {
+			for range b.N {
+				b.StopTimer()
+				r, err := ParseFromString(content, "library.raml", dir,
+					append(opts, OptWithUnwrap(), OptWithStopAfter(StageLinked))...)
+				require.NoError(b, err)
+				b.StartTimer()
+				require.NoError(b, r.Resolve())
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package raml
+
+import "strconv"
+
+// ParameterValue converts string values of a URI parameter, a query parameter or a header to the type of the shape,
+// so they can be validated against it. Values that cannot be converted are kept as strings, so validation reports
+// the type mismatch.
+func ParameterValue(shape *BaseShape, values []string) any {
+	switch s := shape.Shape.(type) {
+	case *ArrayShape:
+		items := make([]any, len(values))
+		for i, value := range values {
+			items[i] = value
+			if s.Items != nil {
+				items[i] = ParameterValue(s.Items, []string{value})
+			}
+		}
+		return items
+	case *UnionShape:
+		for _, member := range s.AnyOf {
+			if value := ParameterValue(member, values); member.Validate(value) == nil {
+				return value
+			}
+		}
+	case *IntegerShape:
+		if i, err := strconv.Atoi(values[0]); err == nil {
+			return i
+		}
+	case *NumberShape:
+		if f, err := strconv.ParseFloat(values[0], 64); err == nil {
+			return f
+		}
+	case *BooleanShape:
+		if b, err := strconv.ParseBool(values[0]); err == nil {
+			return b
+		}
+	case *NilShape:
+		if values[0] == "" {
+			return nil
+		}
+	}
+	return values[0]
+}
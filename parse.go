@@ -5,13 +5,11 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
-
-	"gopkg.in/yaml.v3"
+	"time"
 
 	"github.com/acronis/go-stacktrace"
 )
@@ -42,6 +40,8 @@ func IdentifyFragment(head string) (FragmentKind, error) {
 		return FragmentDataType, nil
 	case "#%RAML 1.0 NamedExample":
 		return FragmentNamedExample, nil
+	case "#%RAML 1.0":
+		return FragmentAPI, nil
 	default:
 		return FragmentUnknown, fmt.Errorf("unknown fragment kind: head: %s", head)
 	}
@@ -58,7 +58,13 @@ func ReadRawFile(path string) (io.ReadCloser, error) {
 	return f, nil
 }
 
-func (r *RAML) decodeDataType(f io.Reader, path string) (*DataType, error) {
+func (r *RAML) decodeDataType(f io.Reader, path string) (_ *DataType, err error) {
+	r.fragmentStarted(path)
+	defer func() { r.fragmentDone(path, err) }()
+	if err = r.admitFragment(path); err != nil {
+		return nil, err
+	}
+	f = r.sizeLimited(f, path)
 	// TODO: This is a temporary workaround for JSON data types.
 	if strings.HasSuffix(path, ".json") {
 		data, err := io.ReadAll(f)
@@ -75,20 +81,17 @@ func (r *RAML) decodeDataType(f io.Reader, path string) (*DataType, error) {
 		return dt, nil
 	}
 
-	decoder := yaml.NewDecoder(f)
-
 	dt := r.MakeDataType(path)
-	if err := decoder.Decode(&dt); err != nil {
-		return nil, StacktraceNewWrapped("decode fragment", err, path,
-			stacktrace.WithType(stacktrace.TypeParsing))
-	}
-
+	// The fragment is registered before decoding, so fragments that include it back link to it.
 	r.PutFragment(path, dt)
+	if err := r.decodeDocument(f, path, &dt); err != nil {
+		delete(r.fragmentsCache, path)
+		return nil, err
+	}
 
-	baseDir := filepath.Dir(dt.Location)
 	for pair := dt.Uses.Oldest(); pair != nil; pair = pair.Next() {
 		include := pair.Value
-		sublib, err := r.parseLibrary(filepath.Join(baseDir, include.Value))
+		sublib, err := r.parseLibrary(dt.Location, include.Value)
 		if err != nil {
 			return nil, StacktraceNewWrapped("parse library", err, dt.Location,
 				stacktrace.WithType(stacktrace.TypeParsing))
@@ -100,8 +103,12 @@ func (r *RAML) decodeDataType(f io.Reader, path string) (*DataType, error) {
 }
 
 func CheckFragmentKind(f *os.File, kind FragmentKind) error {
+	return checkFragmentKind(f, f.Name(), kind)
+}
+
+func checkFragmentKind(f io.ReadSeeker, location string, kind FragmentKind) error {
 	// Allow JSON data types.
-	if kind == FragmentDataType && strings.HasSuffix(f.Name(), ".json") {
+	if kind == FragmentDataType && strings.HasSuffix(location, ".json") {
 		return nil
 	}
 	head, err := ReadHead(f)
@@ -118,36 +125,36 @@ func CheckFragmentKind(f *os.File, kind FragmentKind) error {
 	return nil
 }
 
-func (r *RAML) parseDataType(path string) (*DataType, error) {
+// parseDataType parses the DataType fragment referenced by ref from the fragment at baseLocation.
+func (r *RAML) parseDataType(baseLocation string, ref string) (*DataType, error) {
 	// IMPORTANT: May generate recursive structure.
 	// Consumers (resolvers, validators, external clients) must implement recursion detection when traversing links.
-
-	// Fragment paths must be normalized to absolute paths to simplify dependent libraries resolution.
+	if se := canceledTrace(r.context(), baseLocation, stacktrace.TypeReading); se != nil {
+		return nil, se
+	}
+	path, f, err := r.resolveInclude(baseLocation, ref)
+	if err != nil {
+		return nil, StacktraceNewWrapped("resolve include", err, baseLocation,
+			stacktrace.WithType(stacktrace.TypeLoading), stacktrace.WithInfo("ref", ref))
+	}
+	defer closeContent(f)
+	r.addDependency(baseLocation, path)
 
 	if dt := r.GetFragment(path); dt != nil {
-		// log.Printf("reusing fragment %s", path)
+		r.fragmentReused(path)
 		return dt.(*DataType), nil
 	}
 
-	f, err := openFragmentFile(path)
+	rs, err := seekableContent(f, r.limits.MaxTotalSize)
 	if err != nil {
-		return nil, StacktraceNewWrapped("open fragment file", err, path,
-			stacktrace.WithType(stacktrace.TypeReading))
+		return nil, StacktraceNewWrapped("read fragment", err, path, stacktrace.WithType(stacktrace.TypeReading))
 	}
-
-	defer func(f *os.File) {
-		err = f.Close()
-		if err != nil {
-			log.Fatalf("close file error: %v", err)
-		}
-	}(f)
-
-	if err = CheckFragmentKind(f, FragmentDataType); err != nil {
+	if err = checkFragmentKind(rs, path, FragmentDataType); err != nil {
 		return nil, StacktraceNewWrapped("check fragment kind", err, path,
 			stacktrace.WithType(stacktrace.TypeReading))
 	}
 
-	dt, err := r.decodeDataType(f, path)
+	dt, err := r.decodeDataType(rs, path)
 	if err != nil {
 		return nil, StacktraceNewWrapped("decode data type", err, path,
 			stacktrace.WithType(stacktrace.TypeParsing))
@@ -173,27 +180,57 @@ func openFragmentFile(path string) (*os.File, error) {
 	return f, nil
 }
 
-func (r *RAML) decodeLibrary(f io.Reader, path string) (*Library, error) {
-	decoder := yaml.NewDecoder(f)
-
+func (r *RAML) decodeLibrary(f io.Reader, path string) (_ *Library, err error) {
+	r.fragmentStarted(path)
+	defer func() { r.fragmentDone(path, err) }()
+	if err = r.admitFragment(path); err != nil {
+		return nil, err
+	}
+	f = r.sizeLimited(f, path)
 	lib := r.MakeLibrary(path)
-	if err := decoder.Decode(&lib); err != nil {
-		return nil, StacktraceNewWrapped("decode fragment", err, path,
-			stacktrace.WithType(stacktrace.TypeParsing))
+	if err := r.decodeDocument(f, path, &lib); err != nil {
+		return nil, err
 	}
 
-	var st *stacktrace.StackTrace
-
 	r.PutFragment(path, lib)
 
+	if st := r.parseUses(lib); st != nil {
+		return nil, st
+	}
+	return lib, nil
+}
+
+func (r *RAML) decodeAPI(f io.Reader, path string) (_ *API, err error) {
+	r.fragmentStarted(path)
+	defer func() { r.fragmentDone(path, err) }()
+	if err = r.admitFragment(path); err != nil {
+		return nil, err
+	}
+	f = r.sizeLimited(f, path)
+	api := r.MakeAPI(path)
+	if err := r.decodeDocument(f, path, &api); err != nil {
+		return nil, err
+	}
+
+	r.putFragment(path, api.Library, FragmentAPI)
+
+	if st := r.parseUses(api.Library); st != nil {
+		return nil, st
+	}
+	return api, nil
+}
+
+// parseUses parses libraries used by the library.
+func (r *RAML) parseUses(lib *Library) *stacktrace.StackTrace {
+	var st *stacktrace.StackTrace
+
 	// Resolve included libraries in a separate stage.
-	baseDir := filepath.Dir(lib.Location)
 	for pair := lib.Uses.Oldest(); pair != nil; pair = pair.Next() {
 		include := pair.Value
 
-		sublib, err := r.parseLibrary(filepath.Join(baseDir, include.Value))
+		sublib, err := r.parseLibrary(lib.Location, include.Value)
 		if err != nil {
-			se := StacktraceNewWrapped("parse uses library", err, path,
+			se := StacktraceNewWrapped("parse uses library", err, lib.Location,
 				stacktrace.WithType(stacktrace.TypeParsing), stacktrace.WithPosition(&include.Position))
 			if st == nil {
 				st = se
@@ -203,44 +240,39 @@ func (r *RAML) decodeLibrary(f io.Reader, path string) (*Library, error) {
 		}
 		include.Link = sublib
 	}
-	if st != nil {
-		return nil, st
-	}
-	return lib, nil
+	return st
 }
 
-func (r *RAML) parseLibrary(path string) (*Library, error) {
+// parseLibrary parses the Library fragment referenced by ref from the fragment at baseLocation.
+func (r *RAML) parseLibrary(baseLocation string, ref string) (*Library, error) {
 	// IMPORTANT: May generate recursive structure.
 	// Consumers (resolvers, validators, external clients) must implement recursion detection when traversing links.
-
-	// Fragment paths must be normalized to absolute paths to simplify dependent libraries resolution.
-	// TODO: Add support for URI
-	var err error
+	if se := canceledTrace(r.context(), baseLocation, stacktrace.TypeReading); se != nil {
+		return nil, se
+	}
+	path, f, err := r.resolveInclude(baseLocation, ref)
+	if err != nil {
+		return nil, StacktraceNewWrapped("resolve include", err, baseLocation,
+			stacktrace.WithType(stacktrace.TypeLoading), stacktrace.WithInfo("ref", ref))
+	}
+	defer closeContent(f)
+	r.addDependency(baseLocation, path)
 
 	if lib := r.GetFragment(path); lib != nil {
-		slog.Debug("reusing fragment", slog.String("path", path))
+		r.fragmentReused(path)
 		return lib.(*Library), nil
 	}
 
-	f, err := openFragmentFile(path)
+	rs, err := seekableContent(f, r.limits.MaxTotalSize)
 	if err != nil {
-		return nil, StacktraceNewWrapped("open fragment file", err, path,
-			stacktrace.WithType(stacktrace.TypeLoading))
+		return nil, StacktraceNewWrapped("read fragment", err, path, stacktrace.WithType(stacktrace.TypeReading))
 	}
-
-	defer func(f *os.File) {
-		err = f.Close()
-		if err != nil {
-			log.Fatalf("close file error: %v", err)
-		}
-	}(f)
-
-	if err = CheckFragmentKind(f, FragmentLibrary); err != nil {
+	if err = checkFragmentKind(rs, path, FragmentLibrary); err != nil {
 		return nil, StacktraceNewWrapped("check fragment kind", err, path,
 			stacktrace.WithType(stacktrace.TypeReading))
 	}
 
-	lib, err := r.decodeLibrary(f, path)
+	lib, err := r.decodeLibrary(rs, path)
 	if err != nil {
 		return nil, StacktraceNewWrapped("decode library", err, path,
 			stacktrace.WithType(stacktrace.TypeParsing))
@@ -249,13 +281,16 @@ func (r *RAML) parseLibrary(path string) (*Library, error) {
 	return lib, nil
 }
 
-func (r *RAML) decodeNamedExample(f io.Reader, path string) (*NamedExample, error) {
-	decoder := yaml.NewDecoder(f)
-
+func (r *RAML) decodeNamedExample(f io.Reader, path string) (_ *NamedExample, err error) {
+	r.fragmentStarted(path)
+	defer func() { r.fragmentDone(path, err) }()
+	if err = r.admitFragment(path); err != nil {
+		return nil, err
+	}
+	f = r.sizeLimited(f, path)
 	ne := r.MakeNamedExample(path)
-	if err := decoder.Decode(&ne); err != nil {
-		return nil, StacktraceNewWrapped("decode fragment", err, path,
-			stacktrace.WithType(stacktrace.TypeParsing))
+	if err := r.decodeDocument(f, path, &ne); err != nil {
+		return nil, err
 	}
 
 	r.PutFragment(path, ne)
@@ -263,33 +298,33 @@ func (r *RAML) decodeNamedExample(f io.Reader, path string) (*NamedExample, erro
 	return ne, nil
 }
 
-func (r *RAML) parseNamedExample(path string) (*NamedExample, error) {
-	// Library paths must be normalized to simplify dependent libraries resolution.
-	// Convert rel to abs relative to current workdir if necessary.
+// parseNamedExample parses the NamedExample fragment referenced by ref from the fragment at baseLocation.
+func (r *RAML) parseNamedExample(baseLocation string, ref string) (*NamedExample, error) {
+	if se := canceledTrace(r.context(), baseLocation, stacktrace.TypeReading); se != nil {
+		return nil, se
+	}
+	path, f, err := r.resolveInclude(baseLocation, ref)
+	if err != nil {
+		return nil, fmt.Errorf("resolve include: %w", err)
+	}
+	defer closeContent(f)
+	r.addDependency(baseLocation, path)
 
 	if lib := r.GetFragment(path); lib != nil {
-		slog.Debug("reusing fragment", slog.String("path", path))
+		r.fragmentReused(path)
 		return lib.(*NamedExample), nil
 	}
 
-	f, err := openFragmentFile(path)
+	rs, err := seekableContent(f, r.limits.MaxTotalSize)
 	if err != nil {
-		return nil, fmt.Errorf("open fragment file: %w", err)
+		return nil, StacktraceNewWrapped("read fragment", err, path, stacktrace.WithType(stacktrace.TypeReading))
 	}
-
-	defer func(f *os.File) {
-		err = f.Close()
-		if err != nil {
-			log.Fatalf("close file error: %v", err)
-		}
-	}(f)
-
-	if err = CheckFragmentKind(f, FragmentNamedExample); err != nil {
+	if err = checkFragmentKind(rs, path, FragmentNamedExample); err != nil {
 		return nil, StacktraceNewWrapped("check fragment kind", err, path,
 			stacktrace.WithType(stacktrace.TypeReading))
 	}
 
-	ne, err := r.decodeNamedExample(f, path)
+	ne, err := r.decodeNamedExample(rs, path)
 	if err != nil {
 		return nil, StacktraceNewWrapped("decode named example", err, path,
 			stacktrace.WithType(stacktrace.TypeParsing))
@@ -307,20 +342,22 @@ func (r *RAML) ParseFromPath(path string, opts ...ParseOpt) error {
 		opt.Apply(pOpts)
 	}
 
-	f, err := openFragmentFile(path)
+	resolver := pOpts.includeResolver
+	if resolver == nil {
+		resolver = FileResolver{}
+	}
+	f, location, err := resolver.ResolveInclude("", path)
 	if err != nil {
-		return StacktraceNewWrapped("open fragment file", err, path,
+		return StacktraceNewWrapped("resolve entry point", err, path,
 			stacktrace.WithType(stacktrace.TypeReading))
 	}
+	defer closeContent(f)
 
-	defer func(f *os.File) {
-		err = f.Close()
-		if err != nil {
-			log.Fatalf("close file error: %v", err)
-		}
-	}(f)
-
-	return r.parseFragment(f, f.Name(), pOpts)
+	rs, err := seekableContent(f, pOpts.limits.MaxTotalSize)
+	if err != nil {
+		return StacktraceNewWrapped("read fragment", err, location, stacktrace.WithType(stacktrace.TypeReading))
+	}
+	return r.parseFragment(rs, location, pOpts)
 }
 
 func (r *RAML) ParseFromString(content string, fileName string, baseDir string, opts ...ParseOpt) error {
@@ -334,7 +371,51 @@ func (r *RAML) ParseFromString(content string, fileName string, baseDir string,
 	return r.parseFragment(f, filepath.Join(baseDir, fileName), pOpts)
 }
 
+// applyParserOptions sets the options of the RAML that the pipeline stages and validation use.
+func (r *RAML) applyParserOptions(pOpts *parserOptions) {
+	r.fractionalSeconds = pOpts.fractionalSeconds
+	r.stringLength = pOpts.stringLength
+	if r.regexEngine != pOpts.regexEngine {
+		r.resetPatterns()
+	}
+	r.regexEngine = pOpts.regexEngine
+	r.lenientPatterns = pOpts.lenientPatterns
+	r.maxRecursionDepth = pOpts.maxRecursionDepth
+	r.restrictedPatternProperties = pOpts.restrictedPatternProperties
+	r.strictDuplicateKeys = pOpts.strictDuplicateKeys
+	r.strictAny = pOpts.strictAny
+	r.importJSONSchema = pOpts.importJSONSchema
+	r.inheritancePolicy = pOpts.inheritancePolicy
+	r.preserveDeclared = pOpts.preserveDeclared
+	r.resolveWorkers = pOpts.resolveWorkers
+	r.derivedShapeIDs = pOpts.derivedShapeIDs
+	r.lowMemory = pOpts.lowMemory
+	r.progress = pOpts.progress
+	r.metrics = pOpts.metrics
+	r.logger = pOpts.logger
+	r.fragmentStarts = nil
+	r.limits = pOpts.limits
+	r.yamlAliases = pOpts.yamlAliases
+	r.includeResolver = pOpts.includeResolver
+	r.libraryVersions = pOpts.libraryVersions
+	r.plugins = pOpts.plugins
+	r.parsedFragments = nil
+	r.resetLimits()
+	for _, fv := range pOpts.facetValidators {
+		r.RegisterFacetValidator(fv.name, fv.validator)
+	}
+}
+
 func (r *RAML) parseFragment(f io.ReadSeeker, fragmentPath string, pOpts *parserOptions) error {
+	start := time.Now()
+	ctx := r.context()
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("parse: %w", err)
+	}
+	fragmentPath = r.internLocation(fragmentPath)
+	r.applyParserOptions(pOpts)
+	r.stage = StageNone
+	r.api = nil
 	head, err := ReadHead(f)
 	if err != nil {
 		return StacktraceNewWrapped("read head", err, fragmentPath,
@@ -352,53 +433,69 @@ func (r *RAML) parseFragment(f io.ReadSeeker, fragmentPath string, pOpts *parser
 	case FragmentLibrary:
 		lib, errDecode := r.decodeLibrary(f, fragmentPath)
 		if errDecode != nil {
-			return StacktraceNewWrapped("parse library", errDecode, fragmentPath,
-				stacktrace.WithType(stacktrace.TypeParsing))
+			return abortErr(ctx, "parse", StacktraceNewWrapped("parse library", errDecode, fragmentPath,
+				stacktrace.WithType(stacktrace.TypeParsing)))
 		}
 		r.SetEntryPoint(lib)
 	case FragmentDataType:
 		dt, errDecode := r.decodeDataType(f, fragmentPath)
 		if errDecode != nil {
-			return StacktraceNewWrapped("parse data type", errDecode, fragmentPath,
-				stacktrace.WithType(stacktrace.TypeParsing))
+			return abortErr(ctx, "parse", StacktraceNewWrapped("parse data type", errDecode, fragmentPath,
+				stacktrace.WithType(stacktrace.TypeParsing)))
 		}
 		r.SetEntryPoint(dt)
 	case FragmentNamedExample:
 		ne, errDecode := r.decodeNamedExample(f, fragmentPath)
 		if errDecode != nil {
-			return StacktraceNewWrapped("parse named example", errDecode, fragmentPath,
-				stacktrace.WithType(stacktrace.TypeParsing))
+			return abortErr(ctx, "parse", StacktraceNewWrapped("parse named example", errDecode, fragmentPath,
+				stacktrace.WithType(stacktrace.TypeParsing)))
 		}
 		r.SetEntryPoint(ne)
+	case FragmentAPI:
+		api, errDecode := r.decodeAPI(f, fragmentPath)
+		if errDecode != nil {
+			return abortErr(ctx, "parse", StacktraceNewWrapped("parse api", errDecode, fragmentPath,
+				stacktrace.WithType(stacktrace.TypeParsing)))
+		}
+		r.api = api
+		r.SetEntryPoint(api.Library)
 	default:
 		return stacktrace.New("unknown fragment kind", fragmentPath,
 			stacktrace.WithInfo("head", head), stacktrace.WithType(stacktrace.TypeParsing))
 	}
 
-	err = r.resolveShapes()
-	if err != nil {
-		return StacktraceNewWrapped("resolve shapes", err, fragmentPath,
-			stacktrace.WithType(stacktrace.TypeParsing))
+	return r.runStages(pOpts, start)
+}
+
+// runStages completes parsing that started at start and runs the pipeline stages requested by the options.
+func (r *RAML) runStages(pOpts *parserOptions, start time.Time) error {
+	if err := r.runFragmentParsedHooks(); err != nil {
+		return err
 	}
-	err = r.resolveDomainExtensions()
-	if err != nil {
-		return StacktraceNewWrapped("resolve domain extensions", err, fragmentPath,
-			stacktrace.WithType(stacktrace.TypeParsing))
+	r.stage = StageParsed
+	r.timings = Timings{Parse: time.Since(start)}
+	observeDuration(r.metrics.ParseDuration, start)
+	r.logStage("parse", start, nil)
+	if pOpts.stopAfter == StageParsed {
+		return nil
+	}
+
+	if err := r.Link(); err != nil {
+		return err
+	}
+	if pOpts.stopAfter == StageLinked {
+		return nil
 	}
 
 	if pOpts.withUnwrapOpt {
-		err = r.UnwrapShapes()
-		if err != nil {
-			return StacktraceNewWrapped("unwrap shapes", err, fragmentPath,
-				stacktrace.WithType(stacktrace.TypeParsing))
+		if err := r.Resolve(); err != nil {
+			return err
 		}
 	}
 
 	if pOpts.withValidateOpt {
-		err = r.ValidateShapes()
-		if err != nil {
-			return StacktraceNewWrapped("validate shapes", err, fragmentPath,
-				stacktrace.WithType(stacktrace.TypeParsing))
+		if err := r.Check(); err != nil {
+			return err
 		}
 	}
 
@@ -439,8 +536,33 @@ func ParseFromString(content string, fileName string, baseDir string, opts ...Pa
 }
 
 type parserOptions struct {
-	withUnwrapOpt   bool
-	withValidateOpt bool
+	withUnwrapOpt     bool
+	withValidateOpt   bool
+	fractionalSeconds FractionalSeconds
+	stringLength      StringLength
+	facetValidators   []parseOptWithFacetValidator
+	regexEngine       RegexEngine
+	lenientPatterns   bool
+	maxRecursionDepth int
+
+	restrictedPatternProperties bool
+	strictDuplicateKeys         bool
+	strictAny                   bool
+	importJSONSchema            bool
+	inheritancePolicy           InheritancePolicy
+	stopAfter                   PipelineStage
+	preserveDeclared            bool
+	resolveWorkers              int
+	derivedShapeIDs             bool
+	lowMemory                   bool
+	progress                    ProgressHandler
+	logger                      *slog.Logger
+	metrics                     Metrics
+	limits                      Limits
+	yamlAliases                 bool
+	includeResolver             IncludeResolver
+	libraryVersions             LibraryVersions
+	plugins                     []Plugin
 }
 
 type ParseOpt interface {
@@ -466,3 +588,16 @@ func (parseOptWithValidate) Apply(opt *parserOptions) {
 func OptWithValidate() ParseOpt {
 	return parseOptWithValidate{}
 }
+
+type parseOptWithRestrictedPatternProperties struct{}
+
+func (parseOptWithRestrictedPatternProperties) Apply(opt *parserOptions) {
+	opt.restrictedPatternProperties = true
+}
+
+// OptWithRestrictedPatternProperties allows pattern properties together with "additionalProperties: false" as in
+// JSON Schema: properties that match neither explicit nor pattern properties are rejected.
+// RAML 1.0 forbids this combination, so the option is not strictly compliant.
+func OptWithRestrictedPatternProperties() ParseOpt {
+	return parseOptWithRestrictedPatternProperties{}
+}
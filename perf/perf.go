@@ -0,0 +1,226 @@
+// Package perf provides generated specifications and benchmark helpers to measure performance of the parser.
+//
+// Specifications are libraries of object types with inheritance chains, scalar facets, unions, arrays and references
+// to types of used libraries, which are representative of large multi-fragment projects:
+//
+//	func BenchmarkParse(b *testing.B) {
+//		perf.ParseBench(b, perf.Generate(perf.Large))
+//	}
+package perf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/acronis/go-raml"
+)
+
+// Config describes the size of a generated specification.
+type Config struct {
+	// Libraries is the number of libraries. Every library uses the previous one.
+	Libraries int
+	// Types is the number of object types declared by every library.
+	Types int
+	// Properties is the number of properties of every base type.
+	Properties int
+	// Depth is the length of inheritance chains of types.
+	Depth int
+}
+
+var (
+	// Small is a single library with tens of types.
+	Small = Config{Libraries: 1, Types: 50, Properties: 5, Depth: 3}
+	// Medium is a project with a thousand types.
+	Medium = Config{Libraries: 10, Types: 100, Properties: 10, Depth: 5}
+	// Large is a project with thousands of types in tens of libraries.
+	Large = Config{Libraries: 50, Types: 150, Properties: 10, Depth: 5}
+)
+
+// Spec is a generated specification.
+type Spec struct {
+	// Entry is the file name of the entry point.
+	Entry string
+	// Files maps file names to their contents.
+	Files map[string]string
+}
+
+// Generate generates the specification of the given size. The entry point is a library that uses all generated
+// libraries as l0, l1, etc. Every library also declares the Code and Ref types.
+func Generate(cfg Config) *Spec {
+	spec := &Spec{Entry: "api.raml", Files: make(map[string]string, cfg.Libraries+1)}
+	var entry strings.Builder
+	entry.WriteString("#%RAML 1.0 Library\nuses:\n")
+	for i := range cfg.Libraries {
+		name := libraryName(i)
+		spec.Files[name] = generateLibrary(cfg, i)
+		fmt.Fprintf(&entry, "  l%d: %s\n", i, name)
+	}
+	spec.Files[spec.Entry] = entry.String()
+	return spec
+}
+
+// Write writes files of the specification to the directory and returns the path of the entry point.
+func (s *Spec) Write(dir string) (string, error) {
+	for name, content := range s.Files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			return "", fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	return filepath.Join(dir, s.Entry), nil
+}
+
+func libraryName(i int) string {
+	return fmt.Sprintf("lib%03d.raml", i)
+}
+
+// scalarProperties are declarations of properties of base types, used in turn.
+var scalarProperties = []string{
+	"type: string\n        minLength: 1\n        maxLength: 64",
+	"type: integer\n        minimum: 0\n        maximum: 1000",
+	"type: number\n        multipleOf: 0.5",
+	"type: boolean",
+	"type: string\n        enum: [new, active, archived]",
+	"type: datetime",
+	"type: string\n        pattern: ^[a-z][a-z0-9-]*$",
+	"type: string[]\n        maxItems: 10",
+	"type: string | integer",
+	"type: Code",
+}
+
+func generateLibrary(cfg Config, i int) string {
+	var sb strings.Builder
+	sb.WriteString("#%RAML 1.0 Library\n")
+	if i > 0 {
+		fmt.Fprintf(&sb, "uses:\n  prev: %s\n", libraryName(i-1))
+	}
+	sb.WriteString("types:\n  Code:\n    type: string\n    pattern: ^[A-Z]{3}$\n    example: ABC\n")
+	sb.WriteString("  Ref:\n    properties:\n      code: Code\n      name?: string\n")
+	depth := max(cfg.Depth, 1)
+	for k := range cfg.Types {
+		fmt.Fprintf(&sb, "  T%d:\n", k)
+		if d := k % depth; d > 0 {
+			fmt.Fprintf(&sb, "    type: T%d\n    properties:\n      level%d: integer\n", k-1, d)
+			continue
+		}
+		sb.WriteString("    type: object\n    properties:\n")
+		for p := range cfg.Properties {
+			fmt.Fprintf(&sb, "      p%d:\n        %s\n", p, scalarProperties[p%len(scalarProperties)])
+		}
+		// References to used libraries are kept shallow, so the size of unwrapped types does not grow with the
+		// number of libraries.
+		if i > 0 {
+			sb.WriteString("      ref?: prev.Ref\n      refs?: prev.Ref[]\n")
+		}
+	}
+	return sb.String()
+}
+
+// ParseBench benchmarks reading and decoding of the specification, stopping after raml.StageParsed.
+func ParseBench(b *testing.B, spec *Spec, opts ...raml.ParseOpt) {
+	path := writeSpec(b, spec)
+	opts = append(opts, raml.OptWithStopAfter(raml.StageParsed))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err := raml.ParseFromPath(path, opts...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// ResolveBench benchmarks linking and unwrapping of the parsed specification (see RAML.Resolve).
+func ResolveBench(b *testing.B, spec *Spec, opts ...raml.ParseOpt) {
+	path := writeSpec(b, spec)
+	opts = append(opts, raml.OptWithStopAfter(raml.StageParsed))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		b.StopTimer()
+		r, err := raml.ParseFromPath(path, opts...)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		if err = r.Resolve(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// ValidateBench benchmarks validation of instances of all declared types of the resolved specification. Instances
+// are generated with all optional properties (see raml.Generate).
+func ValidateBench(b *testing.B, spec *Spec, opts ...raml.ParseOpt) {
+	instances := generateInstances(b, spec, opts...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		for _, inst := range instances {
+			if err := inst.shape.Validate(inst.value); err != nil {
+				b.Fatalf("validate %s: %v", inst.shape.Name, err)
+			}
+		}
+	}
+	b.ReportMetric(float64(len(instances)), "instances/op")
+}
+
+// CompiledValidateBench is like ValidateBench, but validates instances with validators compiled by
+// raml.BaseShape.Compile before the benchmark.
+func CompiledValidateBench(b *testing.B, spec *Spec, opts ...raml.ParseOpt) {
+	instances := generateInstances(b, spec, opts...)
+	validators := make([]*raml.CompiledValidator, len(instances))
+	for i, inst := range instances {
+		var err error
+		if validators[i], err = inst.shape.Compile(); err != nil {
+			b.Fatalf("compile %s: %v", inst.shape.Name, err)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		for i, inst := range instances {
+			if err := validators[i].Validate(inst.value); err != nil {
+				b.Fatalf("validate %s: %v", inst.shape.Name, err)
+			}
+		}
+	}
+	b.ReportMetric(float64(len(instances)), "instances/op")
+}
+
+type instance struct {
+	shape *raml.BaseShape
+	value any
+}
+
+// generateInstances returns instances of all declared types of the resolved specification.
+func generateInstances(b *testing.B, spec *Spec, opts ...raml.ParseOpt) []instance {
+	path := writeSpec(b, spec)
+	r, err := raml.ParseFromPath(path, append(opts, raml.OptWithUnwrap())...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var instances []instance
+	for _, node := range r.TypeGraph().Nodes {
+		shape, errLookup := r.LookupType(node.Name, "")
+		if errLookup != nil {
+			b.Fatal(errLookup)
+		}
+		value, errGenerate := raml.Generate(shape.Shape, raml.WithGenerateOptionalProperties())
+		if errGenerate != nil {
+			b.Fatalf("generate %s: %v", node.Name, errGenerate)
+		}
+		instances = append(instances, instance{shape: shape, value: value})
+	}
+	return instances
+}
+
+func writeSpec(b *testing.B, spec *Spec) string {
+	b.Helper()
+	path, err := spec.Write(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+	return path
+}
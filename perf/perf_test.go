@@ -0,0 +1,49 @@
+package perf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-raml"
+)
+
+func TestGenerate(t *testing.T) {
+	spec := Generate(Config{Libraries: 3, Types: 10, Properties: 12, Depth: 3})
+	require.Len(t, spec.Files, 4)
+	path, err := spec.Write(t.TempDir())
+	require.NoError(t, err)
+
+	r, err := raml.ParseFromPath(path, raml.OptWithUnwrap(), raml.OptWithValidate())
+	require.NoError(t, err)
+	timings := r.Timings()
+	require.Positive(t, timings.Parse)
+	require.Positive(t, timings.Link)
+	require.Positive(t, timings.Resolve)
+	require.Positive(t, timings.Check)
+	require.Equal(t, timings.Parse+timings.Link+timings.Resolve+timings.Check, timings.Total())
+
+	// Every library declares Code, Ref and 10 object types.
+	require.Len(t, r.TypeGraph().Nodes, 3*12)
+	shape, err := r.LookupType("l2.T5", "")
+	require.NoError(t, err)
+	value, err := raml.Generate(shape.Shape, raml.WithGenerateOptionalProperties())
+	require.NoError(t, err)
+	require.NoError(t, shape.Validate(value))
+}
+
+func BenchmarkParse(b *testing.B) {
+	ParseBench(b, Generate(Medium))
+}
+
+func BenchmarkResolve(b *testing.B) {
+	ResolveBench(b, Generate(Medium))
+}
+
+func BenchmarkValidate(b *testing.B) {
+	ValidateBench(b, Generate(Medium))
+}
+
+func BenchmarkCompiledValidate(b *testing.B) {
+	CompiledValidateBench(b, Generate(Medium))
+}
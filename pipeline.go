@@ -0,0 +1,183 @@
+package raml
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// PipelineStage is a stage of the parsing pipeline: Parse → Link → Resolve. Every stage requires the previous one.
+// Check validates the model after linking and does not change its stage.
+type PipelineStage int
+
+const (
+	// StageNone means that nothing is parsed yet.
+	StageNone PipelineStage = iota
+	// StageParsed means that the entry point and fragments it uses or includes are decoded. Types are not resolved
+	// yet: their shapes are UnknownShape and references are kept as written.
+	StageParsed
+	// StageLinked means that references and type expressions are resolved into links, aliases and parents,
+	// annotations are bound to their types and the model is analyzed for warnings. Models parsed without
+	// OptWithUnwrap stay at this stage.
+	StageLinked
+	// StageResolved means that inheritance chains and links are unwrapped into complete types in-place.
+	StageResolved
+)
+
+func (s PipelineStage) String() string {
+	switch s {
+	case StageNone:
+		return "none"
+	case StageParsed:
+		return "parsed"
+	case StageLinked:
+		return "linked"
+	case StageResolved:
+		return "resolved"
+	default:
+		return fmt.Sprintf("PipelineStage(%d)", int(s))
+	}
+}
+
+type parseOptWithStopAfter struct {
+	stage PipelineStage
+}
+
+func (o parseOptWithStopAfter) Apply(opt *parserOptions) {
+	opt.stopAfter = o.stage
+}
+
+// OptWithStopAfter stops parsing after the stage, e.g. StageParsed for fast syntax checks or StageLinked to
+// inspect the model before unwrapping even if OptWithUnwrap or OptWithValidate are set. The rest of the pipeline
+// can be resumed with RAML.Link, RAML.Resolve and RAML.Check.
+func OptWithStopAfter(stage PipelineStage) ParseOpt {
+	return parseOptWithStopAfter{stage: stage}
+}
+
+// Stage returns the last completed stage of the parsing pipeline.
+func (r *RAML) Stage() PipelineStage {
+	return r.stage
+}
+
+// Timings are durations of the stages of the parsing pipeline that were run on the model last time. Stages that were
+// not run are zero.
+type Timings struct {
+	// Parse is the time of reading and decoding the entry point and fragments it uses or includes.
+	Parse time.Duration
+	// Link is the time of RAML.Link.
+	Link time.Duration
+	// Resolve is the time of unwrapping by RAML.Resolve, not including linking.
+	Resolve time.Duration
+	// Check is the time of validation by RAML.Check, not including linking.
+	Check time.Duration
+}
+
+// Total returns the sum of durations of all stages.
+func (t Timings) Total() time.Duration {
+	return t.Parse + t.Link + t.Resolve + t.Check
+}
+
+// Timings returns durations of the pipeline stages, e.g. to log them or to export them as metrics.
+func (r *RAML) Timings() Timings {
+	return r.timings
+}
+
+// Link resolves references and type expressions of the parsed model, binds annotations to their types and analyzes
+// the model for warnings (see RAML.Warnings). It does nothing if the model is already linked.
+func (r *RAML) Link() error {
+	return r.link(r.context())
+}
+
+func (r *RAML) link(ctx context.Context) (err error) {
+	switch {
+	case r.stage == StageNone:
+		return fmt.Errorf("link: nothing is parsed")
+	case r.stage >= StageLinked:
+		return nil
+	}
+	start := time.Now()
+	defer func() {
+		r.timings.Link = time.Since(start)
+		observeDuration(r.metrics.LinkDuration, start)
+		r.logStage("link", start, err)
+	}()
+	location := r.GetLocation()
+	if err := r.resolveShapes(ctx); err != nil {
+		return abortErr(ctx, "link", StacktraceNewWrapped("resolve shapes", err, location,
+			stacktrace.WithType(stacktrace.TypeParsing)))
+	}
+	if err := r.resolveDomainExtensions(); err != nil {
+		return abortErr(ctx, "link", StacktraceNewWrapped("resolve domain extensions", err, location,
+			stacktrace.WithType(stacktrace.TypeParsing)))
+	}
+	if err := r.runTypeResolvedHooks(); err != nil {
+		return err
+	}
+	if err := r.checkLibraryVersions(); err != nil {
+		return StacktraceNewWrapped("check library versions", err, location)
+	}
+	r.analyze()
+	if r.strictAny {
+		if err := r.checkAccidentalAny(); err != nil {
+			return StacktraceNewWrapped("check accidental any", err, location)
+		}
+	}
+	r.stage = StageLinked
+	return nil
+}
+
+// Resolve unwraps inheritance chains and links of the model in-place (see RAML.UnwrapShapes), linking it first if
+// necessary. It does nothing if the model is already resolved.
+func (r *RAML) Resolve() error {
+	return r.resolve(r.context())
+}
+
+func (r *RAML) resolve(ctx context.Context) (err error) {
+	if err = r.link(ctx); err != nil {
+		return err
+	}
+	if r.stage >= StageResolved {
+		return nil
+	}
+	start := time.Now()
+	defer func() {
+		r.timings.Resolve = time.Since(start)
+		observeDuration(r.metrics.ResolveDuration, start)
+		r.logStage("resolve", start, err)
+	}()
+	if err = r.unwrapShapes(ctx); err != nil {
+		return abortErr(ctx, "resolve", StacktraceNewWrapped("unwrap shapes", err, r.GetLocation(),
+			stacktrace.WithType(stacktrace.TypeParsing)))
+	}
+	r.stage = StageResolved
+	return nil
+}
+
+// Check validates types, facets, examples, defaults and annotations of the model (see RAML.ValidateShapes), linking
+// it first if necessary. Models that are not resolved are validated on unwrapped copies and are left intact.
+// BeforeCheckHook of plugins is called before validation.
+func (r *RAML) Check() error {
+	return r.check(r.context())
+}
+
+func (r *RAML) check(ctx context.Context) (err error) {
+	if err = r.link(ctx); err != nil {
+		return err
+	}
+	if err = r.runBeforeCheckHooks(); err != nil {
+		return err
+	}
+	start := time.Now()
+	defer func() {
+		r.timings.Check = time.Since(start)
+		observeDuration(r.metrics.CheckDuration, start)
+		r.logStage("check", start, err)
+	}()
+	if err = r.validateShapes(ctx); err != nil {
+		return abortErr(ctx, "check", StacktraceNewWrapped("validate shapes", err, r.GetLocation(),
+			stacktrace.WithType(stacktrace.TypeParsing)))
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package raml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAML_Pipeline(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Base:
+    properties:
+      id: integer
+  Pet:
+    type: Base
+    properties:
+      name: string
+    example:
+      id: 1
+      name: Rex
+`
+	r, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap(), OptWithValidate(),
+		OptWithStopAfter(StageParsed))
+	require.NoError(t, err)
+	require.Equal(t, StageParsed, r.Stage())
+	pet, err := r.LookupType("Pet", r.GetLocation())
+	require.NoError(t, err)
+	require.IsType(t, &UnknownShape{}, pet.Shape)
+
+	require.NoError(t, r.Link())
+	require.Equal(t, StageLinked, r.Stage())
+	pet, err = r.LookupType("Pet", r.GetLocation())
+	require.NoError(t, err)
+	require.Len(t, pet.Inherits, 1)
+	require.Equal(t, 1, pet.Shape.(*ObjectShape).Properties.Len())
+
+	require.NoError(t, r.Check())
+	require.Equal(t, StageLinked, r.Stage())
+
+	require.NoError(t, r.Resolve())
+	require.Equal(t, StageResolved, r.Stage())
+	pet, err = r.LookupType("Pet", r.GetLocation())
+	require.NoError(t, err)
+	require.Equal(t, 2, pet.Shape.(*ObjectShape).Properties.Len())
+	require.NoError(t, r.Resolve())
+}
+
+func TestRAML_Pipeline_Errors(t *testing.T) {
+	require.EqualError(t, New(context.Background()).Link(), "link: nothing is parsed")
+
+	content := `#%RAML 1.0 Library
+types:
+  Pet:
+    type: Missing
+`
+	r, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithStopAfter(StageParsed))
+	require.NoError(t, err)
+	require.ErrorContains(t, r.Resolve(), "resolve shapes")
+	require.Equal(t, StageParsed, r.Stage())
+}
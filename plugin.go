@@ -0,0 +1,129 @@
+package raml
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// Plugin extends the parsing pipeline with custom checks or changes of the model, e.g. injecting company-standard
+// headers into every method, without forking the parser. A plugin implements any of the hook interfaces
+// FragmentParsedHook, TypeResolvedHook and BeforeCheckHook. Plugins are registered with OptWithPlugin and their
+// hooks are called in the order of registration. An error returned by a hook aborts the stage.
+type Plugin interface {
+	// Name identifies the plugin in errors.
+	Name() string
+}
+
+// FragmentParsedHook is called for every fragment decoded by the parse stage, in the order of registration of the
+// fragments, once all of them are decoded. Types of fragments are not resolved yet, so shapes added to the model
+// are linked and unwrapped together with the declared ones. The API definition is available with RAML.API when the
+// hook is called for the fragment of the FragmentAPI kind.
+type FragmentParsedHook interface {
+	AfterFragmentParsed(r *RAML, kind FragmentKind, location string, fragment Fragment) error
+}
+
+// TypeResolvedHook is called by the link stage for every type declared by libraries and data type fragments once
+// references and type expressions of the model are resolved, sorted by location of the fragments. The hook is called
+// before unwrapping, so changes of parent types are inherited by unwrapped subtypes.
+type TypeResolvedHook interface {
+	AfterTypeResolved(r *RAML, shape *BaseShape) error
+}
+
+// BeforeCheckHook is called by RAML.Check before validation of the model, e.g. to enforce custom rules.
+type BeforeCheckHook interface {
+	BeforeCheck(r *RAML) error
+}
+
+type parseOptWithPlugin struct {
+	plugin Plugin
+}
+
+func (o parseOptWithPlugin) Apply(opt *parserOptions) {
+	opt.plugins = append(opt.plugins, o.plugin)
+}
+
+// OptWithPlugin registers the plugin to be called by the parsing pipeline. The option may be repeated.
+func OptWithPlugin(p Plugin) ParseOpt {
+	return parseOptWithPlugin{plugin: p}
+}
+
+func pluginErr(p Plugin, hook string, err error, location string) error {
+	return StacktraceNewWrapped(fmt.Sprintf("plugin %s: %s", p.Name(), hook), err, location,
+		stacktrace.WithType(stacktrace.TypeParsing))
+}
+
+// runFragmentParsedHooks calls FragmentParsedHook of plugins for fragments registered during the parse stage.
+func (r *RAML) runFragmentParsedHooks() error {
+	parsed := r.parsedFragments
+	r.parsedFragments = nil
+	for _, p := range r.plugins {
+		hook, ok := p.(FragmentParsedHook)
+		if !ok {
+			continue
+		}
+		for _, location := range parsed {
+			fragment, ok := r.fragmentsCache[location]
+			if !ok {
+				continue
+			}
+			if err := hook.AfterFragmentParsed(r, r.fragmentKind(fragment), location, fragment); err != nil {
+				return pluginErr(p, "after fragment parsed", err, location)
+			}
+		}
+	}
+	return nil
+}
+
+// runTypeResolvedHooks calls TypeResolvedHook of plugins for declared types of libraries and data type fragments.
+func (r *RAML) runTypeResolvedHooks() error {
+	var hooks []Plugin
+	for _, p := range r.plugins {
+		if _, ok := p.(TypeResolvedHook); ok {
+			hooks = append(hooks, p)
+		}
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	locations := make([]string, 0, len(r.fragmentsCache))
+	for loc := range r.fragmentsCache {
+		locations = append(locations, loc)
+	}
+	slices.Sort(locations)
+	var types []*BaseShape
+	for _, loc := range locations {
+		switch f := r.fragmentsCache[loc].(type) {
+		case *Library:
+			for pair := f.Types.Oldest(); pair != nil; pair = pair.Next() {
+				types = append(types, pair.Value)
+			}
+		case *DataType:
+			if f.Shape != nil {
+				types = append(types, f.Shape)
+			}
+		}
+	}
+	for _, p := range hooks {
+		hook := p.(TypeResolvedHook)
+		for _, shape := range types {
+			if err := hook.AfterTypeResolved(r, shape); err != nil {
+				return pluginErr(p, "after type resolved", err, shape.Location())
+			}
+		}
+	}
+	return nil
+}
+
+// runBeforeCheckHooks calls BeforeCheckHook of plugins.
+func (r *RAML) runBeforeCheckHooks() error {
+	for _, p := range r.plugins {
+		if hook, ok := p.(BeforeCheckHook); ok {
+			if err := hook.BeforeCheck(r); err != nil {
+				return pluginErr(p, "before check", err, r.GetLocation())
+			}
+		}
+	}
+	return nil
+}
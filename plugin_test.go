@@ -0,0 +1,120 @@
+package raml
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// requestIDPlugin injects the X-Request-Id header into every method of the API.
+type requestIDPlugin struct{}
+
+func (requestIDPlugin) Name() string { return "request-id" }
+
+func (requestIDPlugin) AfterFragmentParsed(r *RAML, kind FragmentKind, location string, _ Fragment) error {
+	if kind != FragmentAPI {
+		return nil
+	}
+	var inject func(res *Resource) error
+	inject = func(res *Resource) error {
+		for pair := res.Methods.Oldest(); pair != nil; pair = pair.Next() {
+			m := pair.Value
+			if _, _, ok := LookupHeader(m.Headers, "X-Request-Id"); ok {
+				continue
+			}
+			header, _, err := r.MakeNewShape("X-Request-Id", TypeString, location, &m.Position)
+			if err != nil {
+				return err
+			}
+			m.Headers.Set("X-Request-Id", header)
+		}
+		for pair := res.Resources.Oldest(); pair != nil; pair = pair.Next() {
+			if err := inject(pair.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for pair := r.API().Resources.Oldest(); pair != nil; pair = pair.Next() {
+		if err := inject(pair.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type recordingPlugin struct {
+	calls []string
+	err   error
+}
+
+func (p *recordingPlugin) Name() string { return "recording" }
+
+func (p *recordingPlugin) AfterFragmentParsed(_ *RAML, kind FragmentKind, _ string, _ Fragment) error {
+	p.calls = append(p.calls, fmt.Sprintf("parsed %v", kind))
+	return nil
+}
+
+func (p *recordingPlugin) AfterTypeResolved(_ *RAML, shape *BaseShape) error {
+	p.calls = append(p.calls, "resolved "+shape.Name)
+	if obj, ok := shape.Shape.(*ObjectShape); ok && shape.Name == "Base" {
+		// Changes of parent types are inherited by unwrapped subtypes.
+		obj.Properties.Value("id").Shape.Shape.(*StringShape).MinLength = uint64Ptr(3)
+	}
+	return p.err
+}
+
+func (p *recordingPlugin) BeforeCheck(_ *RAML) error {
+	p.calls = append(p.calls, "check")
+	return nil
+}
+
+func uint64Ptr(v uint64) *uint64 { return &v }
+
+func TestOptWithPlugin(t *testing.T) {
+	content := `#%RAML 1.0
+title: Pets
+/pets:
+  get:
+  /{id}:
+    get:
+      headers:
+        X-Request-Id:
+          pattern: ^[0-9]+$
+`
+	rml, err := ParseFromString(content, "api.raml", t.TempDir(), OptWithPlugin(requestIDPlugin{}),
+		OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	pets := rml.API().Resources.Value("/pets")
+	header, ok := pets.Methods.Value("get").Headers.Get("X-Request-Id")
+	require.True(t, ok)
+	require.NoError(t, header.Validate("abc"))
+	// Declared headers are kept.
+	header, ok = pets.Resources.Value("/{id}").Methods.Value("get").Headers.Get("X-Request-Id")
+	require.True(t, ok)
+	require.Error(t, header.Validate("abc"))
+
+	lib := `#%RAML 1.0 Library
+types:
+  Base:
+    properties:
+      id: string
+  Pet:
+    type: Base
+`
+	p := &recordingPlugin{}
+	rml, err = ParseFromString(lib, "library.raml", t.TempDir(), OptWithPlugin(p), OptWithUnwrap(),
+		OptWithValidate())
+	require.NoError(t, err)
+	require.Equal(t, []string{"parsed Library", "resolved Base", "resolved Pet", "check"}, p.calls)
+	pet, err := rml.LookupType("Pet", rml.GetLocation())
+	require.NoError(t, err)
+	require.ErrorContains(t, pet.Validate(map[string]any{"id": "ab"}), "length")
+
+	p = &recordingPlugin{err: errors.New("forbidden")}
+	_, err = ParseFromString(lib, "library.raml", t.TempDir(), OptWithPlugin(p))
+	require.ErrorContains(t, err, "plugin recording: after type resolved")
+	require.ErrorContains(t, err, "forbidden")
+}
@@ -0,0 +1,50 @@
+package raml
+
+// ProgressHandler receives progress of parsing and resolution, e.g. to display progress bars or emit metrics for
+// specifications of many fragments. Methods are called from the goroutine that runs the pipeline.
+type ProgressHandler interface {
+	// OnFragmentStart is called before the fragment at location is decoded.
+	OnFragmentStart(location string)
+	// OnFragmentDone is called after the fragment and the libraries it uses are decoded, err is the error of decoding.
+	OnFragmentDone(location string, err error)
+	// OnShapeResolved is called after the reference of the shape is resolved, remaining is the number of shapes left
+	// to resolve.
+	OnShapeResolved(shape *BaseShape, remaining int)
+}
+
+type parseOptWithProgress struct {
+	handler ProgressHandler
+}
+
+func (o parseOptWithProgress) Apply(opt *parserOptions) {
+	opt.progress = o.handler
+}
+
+// OptWithProgress reports progress of parsing and resolution to the handler.
+func OptWithProgress(handler ProgressHandler) ParseOpt {
+	return parseOptWithProgress{handler: handler}
+}
+
+func (r *RAML) fragmentStarted(location string) {
+	r.logFragmentStart(location)
+	if r.progress != nil {
+		r.progress.OnFragmentStart(location)
+	}
+}
+
+func (r *RAML) fragmentDone(location string, err error) {
+	r.logFragmentDone(location, err)
+	if err == nil {
+		incCounter(r.metrics.FragmentsParsed)
+	}
+	if r.progress != nil {
+		r.progress.OnFragmentDone(location, err)
+	}
+}
+
+func (r *RAML) shapeResolved(shape *BaseShape) {
+	incCounter(r.metrics.ShapesResolved)
+	if r.progress != nil {
+		r.progress.OnShapeResolved(shape, r.unresolvedShapes.Len())
+	}
+}
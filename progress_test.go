@@ -0,0 +1,67 @@
+package raml
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordedProgress struct {
+	events    []string
+	resolved  []string
+	remaining []int
+}
+
+func (p *recordedProgress) OnFragmentStart(location string) {
+	p.events = append(p.events, "start "+filepath.Base(location))
+}
+
+func (p *recordedProgress) OnFragmentDone(location string, err error) {
+	event := "done " + filepath.Base(location)
+	if err != nil {
+		event += " with error"
+	}
+	p.events = append(p.events, event)
+}
+
+func (p *recordedProgress) OnShapeResolved(shape *BaseShape, remaining int) {
+	p.resolved = append(p.resolved, shape.Name)
+	p.remaining = append(p.remaining, remaining)
+}
+
+func TestOptWithProgress(t *testing.T) {
+	dir, err := filepath.Abs("./fixtures/progress")
+	require.NoError(t, err)
+	content := `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Cat:
+    type: common.Pet
+    properties:
+      tag: !include tag.raml
+  Owner:
+    properties:
+      id: common.Id
+`
+	var progress recordedProgress
+	_, err = ParseFromString(content, "library.raml", dir, OptWithProgress(&progress), OptWithUnwrap())
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"start library.raml", "start common.raml", "done common.raml", "done library.raml",
+		// Included fragments are decoded when the model is linked.
+		"start tag.raml", "done tag.raml",
+	}, progress.events)
+	require.Contains(t, progress.resolved, "Cat")
+	require.Equal(t, 0, progress.remaining[len(progress.remaining)-1])
+	for i := 1; i < len(progress.remaining); i++ {
+		require.Less(t, progress.remaining[i], progress.remaining[i-1])
+	}
+
+	progress = recordedProgress{}
+	_, err = ParseFromString("#%RAML 1.0 Library\nuses:\n  missing: missing.raml\n", "library.raml", dir,
+		OptWithProgress(&progress))
+	require.Error(t, err)
+	require.Equal(t, []string{"start library.raml", "done library.raml with error"}, progress.events)
+}
@@ -0,0 +1,591 @@
+package raml
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// AnnotationProtoField sets the number of the field generated for the property by ProtoConverter, e.g.
+// "(protoField): 3". The annotation type must be declared by the user. Annotations of used libraries are recognized
+// by the name without the namespace, e.g. "(lib.protoField): 3".
+const AnnotationProtoField = "protoField"
+
+const (
+	protoTimestamp       = "google.protobuf.Timestamp"
+	protoValue           = "google.protobuf.Value"
+	protoTimestampImport = "google/protobuf/timestamp.proto"
+	protoStructImport    = "google/protobuf/struct.proto"
+	// Field numbers reserved for the protocol buffers implementation.
+	protoReservedFirst = 19000
+	protoReservedLast  = 19999
+	protoMaxField      = 1<<29 - 1
+)
+
+type ProtoConverterOpt interface {
+	Apply(*ProtoConverterOptions)
+}
+
+type ProtoConverterOptions struct {
+	pkg       string
+	goPackage string
+	naming    Naming
+}
+
+type optProtoPackage struct {
+	pkg string
+}
+
+func (o optProtoPackage) Apply(opts *ProtoConverterOptions) {
+	opts.pkg = o.pkg
+}
+
+// WithProtoPackage sets the package of the proto file.
+func WithProtoPackage(pkg string) ProtoConverterOpt {
+	return optProtoPackage{pkg: pkg}
+}
+
+type optProtoGoPackage struct {
+	goPackage string
+}
+
+func (o optProtoGoPackage) Apply(opts *ProtoConverterOptions) {
+	opts.goPackage = o.goPackage
+}
+
+// WithProtoGoPackage sets the go_package option of the proto file.
+func WithProtoGoPackage(goPackage string) ProtoConverterOpt {
+	return optProtoGoPackage{goPackage: goPackage}
+}
+
+type optProtoNaming struct {
+	naming Naming
+}
+
+func (o optProtoNaming) Apply(opts *ProtoConverterOptions) {
+	opts.naming = o.naming
+}
+
+// WithProtoNaming sets the naming of messages, fields and enum values, the zero Naming by default.
+func WithProtoNaming(naming Naming) ProtoConverterOpt {
+	return optProtoNaming{naming: naming}
+}
+
+// ProtoFile is a proto3 file with messages and enums converted from declared types.
+type ProtoFile struct {
+	Package   string
+	GoPackage string
+	Imports   []string
+	Messages  []*ProtoMessage
+	Enums     []*ProtoEnum
+}
+
+type ProtoMessage struct {
+	Name    string
+	Comment string
+	// Fields contains fields in the order of declaration, fields of oneofs included.
+	Fields   []*ProtoField
+	Messages []*ProtoMessage
+	Enums    []*ProtoEnum
+}
+
+type ProtoField struct {
+	Name     string
+	Type     string
+	Number   int
+	Repeated bool
+	Optional bool
+	// Oneof is the name of the oneof the field belongs to.
+	Oneof   string
+	Comment string
+}
+
+type ProtoEnum struct {
+	Name    string
+	Comment string
+	Values  []ProtoEnumValue
+}
+
+type ProtoEnumValue struct {
+	Name   string
+	Number int
+}
+
+// ProtoConverter converts declared types to proto3 messages. It is experimental.
+//
+// Object types become messages with fields in the order of declaration, inherited properties first. Field numbers
+// are assigned in the same order unless they are set with AnnotationProtoField. String types with enums become
+// enums. Inline objects and enums of properties become nested messages and enums. Arrays become repeated fields,
+// nullable unions ("T | nil") become optional fields and other unions become oneofs. Other declared types are
+// inlined where they are used. Names of declared types are converted to PascalCase, e.g. "common.Pet" becomes
+// "CommonPet", property names are converted to snake_case, see WithProtoNaming. Colliding names of declared types
+// get number suffixes.
+type ProtoConverter struct {
+	opts ProtoConverterOptions
+
+	names   map[int64]string
+	imports map[string]struct{}
+}
+
+func NewProtoConverter(opts ...ProtoConverterOpt) *ProtoConverter {
+	c := &ProtoConverter{}
+	for _, opt := range opts {
+		opt.Apply(&c.opts)
+	}
+	return c
+}
+
+// Convert converts types of the type graph (see RAML.TypeGraph) to messages and enums.
+func (c *ProtoConverter) Convert(r *RAML) (*ProtoFile, error) {
+	graph := r.TypeGraph()
+	c.names = make(map[int64]string, len(graph.Nodes))
+	c.imports = make(map[string]struct{})
+	scope := NewNameScope()
+	for _, n := range graph.Nodes {
+		c.names[n.Shape.ID] = scope.Unique(c.opts.naming.Pascal(n.Name))
+	}
+	f := &ProtoFile{Package: c.opts.pkg, GoPackage: c.opts.goPackage}
+	for _, n := range graph.Nodes {
+		target := protoTarget(n.Shape)
+		switch shape := target.Shape.(type) {
+		case *ObjectShape, *UnionShape:
+			m, err := c.message(c.names[n.Shape.ID], n.Shape)
+			if err != nil {
+				return nil, fmt.Errorf("convert type %s: %w", n.Name, err)
+			}
+			f.Messages = append(f.Messages, m)
+		case *StringShape:
+			if shape.Enum != nil {
+				f.Enums = append(f.Enums, c.enum(c.names[n.Shape.ID], n.Shape, shape.Enum))
+			}
+		}
+	}
+	for imp := range c.imports {
+		f.Imports = append(f.Imports, imp)
+	}
+	slices.Sort(f.Imports)
+	return f, nil
+}
+
+// protoTarget follows aliases and links to the shape that defines the type.
+func protoTarget(s *BaseShape) *BaseShape {
+	for {
+		switch {
+		case s.Alias != nil:
+			s = s.Alias
+		case s.Link != nil:
+			s = s.Link.Shape
+		default:
+			return s
+		}
+	}
+}
+
+// declaredName returns the name of the declared message or enum the shape refers to.
+func (c *ProtoConverter) declaredName(s *BaseShape) (string, bool) {
+	for s != nil {
+		if name, ok := c.names[s.ID]; ok {
+			switch shape := protoTarget(s).Shape.(type) {
+			case *ObjectShape, *UnionShape:
+				return name, true
+			case *StringShape:
+				if shape.Enum != nil {
+					return name, true
+				}
+			}
+			return "", false
+		}
+		switch {
+		case s.Alias != nil:
+			s = s.Alias
+		case s.Link != nil:
+			s = s.Link.Shape
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// message converts the object type or the union type. Unions become messages with the single oneof "value".
+func (c *ProtoConverter) message(name string, s *BaseShape) (*ProtoMessage, error) {
+	m := &ProtoMessage{Name: name, Comment: protoComment(s)}
+	if union, ok := protoTarget(s).Shape.(*UnionShape); ok {
+		if err := c.addOneof(m, "value", nonNilMembers(union), ""); err != nil {
+			return nil, err
+		}
+		return m, c.numberFields(m, nil)
+	}
+	props := protoProperties(s)
+	explicit := make(map[string]int)
+	for _, p := range props {
+		if n, ok := protoFieldNumber(p.Shape); ok {
+			explicit[c.opts.naming.Snake(p.Name)] = n
+		}
+		if err := c.addField(m, p.Name, p.Shape, p.Required); err != nil {
+			return nil, fmt.Errorf("property %s: %w", p.Name, err)
+		}
+	}
+	if err := c.numberFields(m, explicit); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// protoProperties returns properties of the object type, inherited properties first. Properties redeclared by
+// the type keep the position of the inherited ones.
+func protoProperties(s *BaseShape) []Property {
+	var props []Property
+	index := make(map[string]int)
+	visited := make(map[int64]struct{})
+	var collect func(s *BaseShape)
+	collect = func(s *BaseShape) {
+		if s == nil {
+			return
+		}
+		if _, ok := visited[s.ID]; ok {
+			return
+		}
+		visited[s.ID] = struct{}{}
+		collect(s.Alias)
+		if s.Link != nil {
+			collect(s.Link.Shape)
+		}
+		for _, parent := range s.Inherits {
+			collect(parent)
+		}
+		obj, ok := s.Shape.(*ObjectShape)
+		if !ok || s.Alias != nil || s.Link != nil || obj.Properties == nil {
+			return
+		}
+		for pair := obj.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			if i, found := index[pair.Value.Name]; found {
+				props[i] = pair.Value
+				continue
+			}
+			index[pair.Value.Name] = len(props)
+			props = append(props, pair.Value)
+		}
+	}
+	collect(s)
+	return props
+}
+
+// addField adds the field for the property. Unions that are not nullable types become oneofs.
+func (c *ProtoConverter) addField(m *ProtoMessage, propName string, s *BaseShape, required bool) error {
+	fieldName := c.opts.naming.Snake(propName)
+	if _, declared := c.declaredName(s); !declared {
+		if union, ok := protoTarget(s).Shape.(*UnionShape); ok {
+			if members := nonNilMembers(union); len(members) > 1 {
+				return c.addOneof(m, propName, members, protoComment(s))
+			}
+		}
+	}
+	typ, repeated, err := c.fieldType(m, propName, s)
+	if err != nil {
+		return err
+	}
+	f := &ProtoField{Name: fieldName, Type: typ, Repeated: repeated, Comment: protoComment(s)}
+	// Scalars get explicit presence unless they are required, messages always have it.
+	if !repeated && !strings.HasPrefix(typ, "map<") && protoScalarTypes[typ] {
+		_, nullable := protoTarget(s).Shape.(*UnionShape)
+		f.Optional = !required || nullable
+	}
+	m.Fields = append(m.Fields, f)
+	return nil
+}
+
+// addOneof adds the oneof with a field for every member of the union.
+func (c *ProtoConverter) addOneof(m *ProtoMessage, propName string, members []*BaseShape, comment string) error {
+	oneof := c.opts.naming.Snake(propName)
+	for _, member := range members {
+		typ, repeated, err := c.fieldType(m, propName, member)
+		if err != nil {
+			return err
+		}
+		if repeated {
+			return fmt.Errorf("repeated fields cannot be members of oneof")
+		}
+		m.Fields = append(m.Fields, &ProtoField{
+			Name:    oneof + "_" + c.opts.naming.Snake(typ),
+			Type:    typ,
+			Oneof:   oneof,
+			Comment: comment,
+		})
+	}
+	return nil
+}
+
+var protoScalarTypes = map[string]bool{
+	"string": true, "bool": true, "int32": true, "int64": true, "float": true, "double": true, "bytes": true,
+}
+
+func nonNilMembers(u *UnionShape) []*BaseShape {
+	var members []*BaseShape
+	for _, member := range u.AnyOf {
+		if _, ok := protoTarget(member).Shape.(*NilShape); !ok {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// fieldType returns the type of the field for the shape. Inline objects and enums are added to the message as nested
+// types named after the property.
+func (c *ProtoConverter) fieldType(m *ProtoMessage, propName string, s *BaseShape) (string, bool, error) {
+	if name, ok := c.declaredName(s); ok {
+		return name, false, nil
+	}
+	target := protoTarget(s)
+	switch shape := target.Shape.(type) {
+	case *ObjectShape:
+		if len(protoProperties(target)) == 0 && shape.PatternProperties != nil && shape.PatternProperties.Len() == 1 {
+			valueType, repeated, err := c.fieldType(m, propName+"Value", shape.PatternProperties.Oldest().Value.Shape)
+			if err != nil {
+				return "", false, err
+			}
+			if repeated {
+				return "", false, fmt.Errorf("repeated values of maps are not supported")
+			}
+			return "map<string, " + valueType + ">", false, nil
+		}
+		nested, err := c.message(c.opts.naming.Pascal(propName), target)
+		if err != nil {
+			return "", false, err
+		}
+		m.Messages = append(m.Messages, nested)
+		return nested.Name, false, nil
+	case *ArrayShape:
+		if shape.Items == nil {
+			c.imports[protoStructImport] = struct{}{}
+			return protoValue, true, nil
+		}
+		typ, repeated, err := c.fieldType(m, propName+"Item", shape.Items)
+		if err != nil {
+			return "", false, err
+		}
+		if repeated {
+			return "", false, fmt.Errorf("nested arrays are not supported")
+		}
+		return typ, true, nil
+	case *UnionShape:
+		members := nonNilMembers(shape)
+		if len(members) != 1 {
+			return "", false, fmt.Errorf("unions are supported only as types of properties and declared types")
+		}
+		return c.fieldType(m, propName, members[0])
+	case *RecursiveShape:
+		if name, ok := c.declaredName(shape.Head); ok {
+			return name, false, nil
+		}
+		return "", false, fmt.Errorf("recursive type is not declared")
+	case *StringShape:
+		if shape.Enum != nil {
+			e := c.enum(c.opts.naming.Pascal(propName), target, shape.Enum)
+			m.Enums = append(m.Enums, e)
+			return e.Name, false, nil
+		}
+		return "string", false, nil
+	case *IntegerShape:
+		if shape.Format != nil {
+			switch *shape.Format {
+			case "int8", "int16", "int32":
+				return "int32", false, nil
+			}
+		}
+		return "int64", false, nil
+	case *NumberShape:
+		if shape.Format != nil {
+			switch *shape.Format {
+			case "float":
+				return "float", false, nil
+			case NumberFormatDecimal:
+				// Protocol buffers have no decimal type, the decimal string keeps the precision.
+				return "string", false, nil
+			}
+		}
+		return "double", false, nil
+	case *BooleanShape:
+		return "bool", false, nil
+	case *DateTimeShape, *DateTimeOnlyShape:
+		c.imports[protoTimestampImport] = struct{}{}
+		return protoTimestamp, false, nil
+	case *DateOnlyShape, *TimeOnlyShape:
+		return "string", false, nil
+	case *FileShape:
+		return "bytes", false, nil
+	case *AnyShape, *JSONShape:
+		c.imports[protoStructImport] = struct{}{}
+		return protoValue, false, nil
+	default:
+		return "", false, fmt.Errorf("type %s is not supported", target.Type)
+	}
+}
+
+// numberFields assigns explicit numbers and numbers the rest of fields in the order of declaration.
+func (c *ProtoConverter) numberFields(m *ProtoMessage, explicit map[string]int) error {
+	used := make(map[int]string)
+	for _, f := range m.Fields {
+		n, ok := explicit[f.Name]
+		if !ok {
+			continue
+		}
+		if n < 1 || n > protoMaxField || (n >= protoReservedFirst && n <= protoReservedLast) {
+			return fmt.Errorf("field %s: invalid field number %d", f.Name, n)
+		}
+		if other, dup := used[n]; dup {
+			return fmt.Errorf("field %s: field number %d is used by %s", f.Name, n, other)
+		}
+		used[n] = f.Name
+		f.Number = n
+	}
+	next := 1
+	for _, f := range m.Fields {
+		if f.Number != 0 {
+			continue
+		}
+		for {
+			if next == protoReservedFirst {
+				next = protoReservedLast + 1
+			}
+			if _, ok := used[next]; !ok {
+				break
+			}
+			next++
+		}
+		f.Number = next
+		used[next] = f.Name
+	}
+	return nil
+}
+
+// protoFieldNumber returns the value of the protoField annotation of the property.
+func protoFieldNumber(s *BaseShape) (int, bool) {
+	switch v := s.annotationValue(AnnotationProtoField).(type) {
+	case int:
+		return v, true
+	case uint64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+// annotationValue returns the value of the annotation recognized by the name without the namespace, nil if the shape
+// has no such annotation.
+func (s *BaseShape) annotationValue(annotation string) any {
+	for pair := s.CustomDomainProperties.Oldest(); pair != nil; pair = pair.Next() {
+		name := pair.Key
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			name = name[i+1:]
+		}
+		if name == annotation && pair.Value.Extension != nil {
+			return pair.Value.Extension.Value
+		}
+	}
+	return nil
+}
+
+func (c *ProtoConverter) enum(name string, s *BaseShape, values Nodes) *ProtoEnum {
+	prefix := c.opts.naming.ScreamingSnake(name)
+	e := &ProtoEnum{Name: name, Comment: protoComment(s)}
+	e.Values = append(e.Values, ProtoEnumValue{Name: prefix + "_UNSPECIFIED", Number: 0})
+	for i, v := range values {
+		e.Values = append(e.Values, ProtoEnumValue{
+			Name:   prefix + "_" + c.opts.naming.ScreamingSnake(fmt.Sprint(v.Value)),
+			Number: i + 1,
+		})
+	}
+	return e
+}
+
+func protoComment(s *BaseShape) string {
+	if s.Description == nil {
+		return ""
+	}
+	return strings.TrimSpace(*s.Description)
+}
+
+// Marshal renders the file in the proto3 syntax.
+func (f *ProtoFile) Marshal() ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString("syntax = \"proto3\";\n")
+	if f.Package != "" {
+		fmt.Fprintf(&sb, "\npackage %s;\n", f.Package)
+	}
+	if len(f.Imports) > 0 {
+		sb.WriteString("\n")
+		for _, imp := range f.Imports {
+			fmt.Fprintf(&sb, "import %q;\n", imp)
+		}
+	}
+	if f.GoPackage != "" {
+		fmt.Fprintf(&sb, "\noption go_package = %q;\n", f.GoPackage)
+	}
+	for _, e := range f.Enums {
+		sb.WriteString("\n")
+		writeProtoEnum(&sb, e, "")
+	}
+	for _, m := range f.Messages {
+		sb.WriteString("\n")
+		writeProtoMessage(&sb, m, "")
+	}
+	return []byte(sb.String()), nil
+}
+
+func writeProtoComment(sb *strings.Builder, comment string, indent string) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		sb.WriteString(strings.TrimRight(indent+"// "+line, " ") + "\n")
+	}
+}
+
+func writeProtoEnum(sb *strings.Builder, e *ProtoEnum, indent string) {
+	writeProtoComment(sb, e.Comment, indent)
+	fmt.Fprintf(sb, "%senum %s {\n", indent, e.Name)
+	for _, v := range e.Values {
+		fmt.Fprintf(sb, "%s  %s = %d;\n", indent, v.Name, v.Number)
+	}
+	sb.WriteString(indent + "}\n")
+}
+
+func writeProtoMessage(sb *strings.Builder, m *ProtoMessage, indent string) {
+	writeProtoComment(sb, m.Comment, indent)
+	fmt.Fprintf(sb, "%smessage %s {\n", indent, m.Name)
+	inner := indent + "  "
+	for _, e := range m.Enums {
+		writeProtoEnum(sb, e, inner)
+	}
+	for _, nested := range m.Messages {
+		writeProtoMessage(sb, nested, inner)
+	}
+	for i := 0; i < len(m.Fields); i++ {
+		f := m.Fields[i]
+		if f.Oneof == "" {
+			writeProtoField(sb, f, inner)
+			continue
+		}
+		fmt.Fprintf(sb, "%soneof %s {\n", inner, f.Oneof)
+		for ; i < len(m.Fields) && m.Fields[i].Oneof == f.Oneof; i++ {
+			writeProtoField(sb, m.Fields[i], inner+"  ")
+		}
+		i--
+		sb.WriteString(inner + "}\n")
+	}
+	sb.WriteString(indent + "}\n")
+}
+
+func writeProtoField(sb *strings.Builder, f *ProtoField, indent string) {
+	if f.Oneof == "" {
+		writeProtoComment(sb, f.Comment, indent)
+	}
+	label := ""
+	switch {
+	case f.Repeated:
+		label = "repeated "
+	case f.Optional:
+		label = "optional "
+	}
+	fmt.Fprintf(sb, "%s%s%s %s = %d;\n", indent, label, f.Type, f.Name, f.Number)
+}
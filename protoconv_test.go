@@ -0,0 +1,172 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtoConverter_Convert(t *testing.T) {
+	content := `#%RAML 1.0 Library
+annotationTypes:
+  protoField: integer
+types:
+  Base:
+    properties:
+      id:
+        type: integer
+        format: int32
+  Pet:
+    type: Base
+    description: A pet.
+    properties:
+      name:
+        type: string
+        (protoField): 5
+      tag?: string
+      born: datetime
+      status: Status
+      size:
+        enum: [small, large]
+      owner:
+        properties:
+          email: string
+      labels:
+        properties:
+          //: string
+      photos: file[]
+      parent: Pet | nil
+      kind: Dog | Cat
+  Status:
+    type: string
+    enum: [active, sold-out]
+  Dog:
+    properties:
+      bark: boolean
+  Cat:
+    properties:
+      weight:
+        type: number
+        format: float
+  Animal: Dog | Cat
+`
+	r, err := ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+
+	f, err := NewProtoConverter(WithProtoPackage("pets.v1"), WithProtoGoPackage("example.com/pets")).Convert(r)
+	require.NoError(t, err)
+	got, err := f.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, `syntax = "proto3";
+
+package pets.v1;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "example.com/pets";
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+  STATUS_SOLD_OUT = 2;
+}
+
+message Animal {
+  oneof value {
+    Dog value_dog = 1;
+    Cat value_cat = 2;
+  }
+}
+
+message Base {
+  int32 id = 1;
+}
+
+message Cat {
+  float weight = 1;
+}
+
+message Dog {
+  bool bark = 1;
+}
+
+// A pet.
+message Pet {
+  enum Size {
+    SIZE_UNSPECIFIED = 0;
+    SIZE_SMALL = 1;
+    SIZE_LARGE = 2;
+  }
+  message Owner {
+    string email = 1;
+  }
+  int32 id = 1;
+  string name = 5;
+  optional string tag = 2;
+  google.protobuf.Timestamp born = 3;
+  Status status = 4;
+  Size size = 6;
+  Owner owner = 7;
+  map<string, string> labels = 8;
+  repeated bytes photos = 9;
+  Pet parent = 10;
+  oneof kind {
+    Dog kind_dog = 11;
+    Cat kind_cat = 12;
+  }
+}
+`, string(got))
+}
+
+func TestProtoConverter_Convert_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		types string
+		err   string
+	}{
+		{
+			name: "duplicate field number",
+			types: `
+  A:
+    properties:
+      a:
+        type: string
+        (protoField): 1
+      b:
+        type: string
+        (protoField): 1`,
+			err: "convert type A: field b: field number 1 is used by a",
+		},
+		{
+			name: "reserved field number",
+			types: `
+  A:
+    properties:
+      a:
+        type: string
+        (protoField): 19000`,
+			err: "convert type A: field a: invalid field number 19000",
+		},
+		{
+			name: "nested arrays",
+			types: `
+  A:
+    properties:
+      a:
+        type: array
+        items:
+          type: array
+          items: string`,
+			err: "convert type A: property a: nested arrays are not supported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "#%RAML 1.0 Library\nannotationTypes:\n  protoField: integer\ntypes:" + tt.types + "\n"
+			r, err := ParseFromString(content, "library.raml", t.TempDir())
+			require.NoError(t, err)
+			_, err = NewProtoConverter().Convert(r)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
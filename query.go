@@ -0,0 +1,367 @@
+package raml
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// QueryMatch is a shape or a property matched by a selector.
+type QueryMatch struct {
+	// Path is the path of the match within its fragment, e.g. "types.Pet.properties.name".
+	Path string
+	// Shape is the matched shape or the shape of the matched property.
+	Shape *BaseShape
+	// Property is the matched property, nil if a shape is matched. Pattern properties are named by their patterns.
+	Property *Property
+
+	Location string
+	stacktrace.Position
+}
+
+// queryStep is a step of the selector, e.g. "properties" or "name[type=string]".
+type queryStep struct {
+	name       string
+	predicates []queryPredicate
+}
+
+// queryPredicate tests the attribute of the matched element, e.g. "[required=true]", "[type!=string]" or
+// "[(internal)]".
+type queryPredicate struct {
+	attr   string
+	value  string
+	negate bool
+	// exists is set if the predicate has no value and tests presence of the attribute.
+	exists bool
+}
+
+// Query finds declared types, properties and nested shapes matching the selector across all fragments. A selector
+// is a dot-separated path of steps, where "types" and "annotationTypes" select declarations of fragments, and
+// "properties", "facets" and "anyOf" select named properties, facet definitions and union members of shapes,
+// followed by a name pattern with "*" wildcards. A collection step without a name selects all elements, "items"
+// selects items of arrays and "**" selects the shape with all nested properties, items and members. Steps may be
+// followed by predicates on "name", "type", "required", "displayName", "description" and annotations, e.g.
+// "types.*.properties[required=true]", "types.Pet*.**.properties[type=string][(internal)]". Values of predicates
+// are patterns like names, "!=" negates a predicate and a predicate without a value tests presence.
+// References to declared types are followed when steps go into shapes and properties of parents are included.
+// Matches are returned in the order of locations and declarations.
+func (r *RAML) Query(selector string) ([]QueryMatch, error) {
+	steps, err := parseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	if steps[0].name != "types" && steps[0].name != "annotationTypes" {
+		return nil, fmt.Errorf("selector %q must start with types or annotationTypes", selector)
+	}
+	var current []QueryMatch
+	for i := 0; i < len(steps); i++ {
+		step := steps[i]
+		var next []QueryMatch
+		switch step.name {
+		case "types", "annotationTypes":
+			if i > 0 {
+				return nil, fmt.Errorf("unexpected step %q of selector %q", step.name, selector)
+			}
+			next = r.queryDeclarations(step.name)
+		case "items":
+			for _, m := range current {
+				if arr, ok := queryTarget(m.Shape).Shape.(*ArrayShape); ok && arr.Items != nil {
+					next = append(next, shapeMatch(m.Path+".items", arr.Items))
+				}
+			}
+			current = filterMatches(next, step.predicates, "")
+			continue
+		case "**":
+			for _, m := range current {
+				next = append(next, m)
+				next = appendNested(next, m, make(map[int64]struct{}))
+			}
+			current = filterMatches(next, step.predicates, "")
+			continue
+		case "properties", "facets", "anyOf":
+			for _, m := range current {
+				next = append(next, queryCollection(m, step.name)...)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected step %q of selector %q", step.name, selector)
+		}
+		// The collection is followed by a name pattern unless it has predicates or ends the selector.
+		pattern := "*"
+		if len(step.predicates) == 0 && i+1 < len(steps) {
+			i++
+			step = steps[i]
+			pattern = step.name
+		}
+		current = filterMatches(next, step.predicates, pattern)
+	}
+	return current, nil
+}
+
+// parseSelector splits the selector into steps with predicates.
+func parseSelector(selector string) ([]queryStep, error) {
+	var steps []queryStep
+	rest := selector
+	for {
+		end := 0
+		for end < len(rest) && rest[end] != '.' && rest[end] != '[' {
+			end++
+		}
+		step := queryStep{name: rest[:end]}
+		if step.name == "" {
+			return nil, fmt.Errorf("selector %q has an empty step", selector)
+		}
+		rest = rest[end:]
+		for strings.HasPrefix(rest, "[") {
+			end = strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("selector %q has an unterminated predicate", selector)
+			}
+			p := queryPredicate{attr: rest[1:end], exists: true}
+			if attr, value, ok := strings.Cut(p.attr, "="); ok {
+				p.attr, p.value, p.exists = attr, strings.TrimSpace(value), false
+				p.attr, p.negate = strings.CutSuffix(p.attr, "!")
+			} else {
+				p.attr, p.negate = strings.CutPrefix(p.attr, "!")
+			}
+			p.attr = strings.TrimSpace(p.attr)
+			if p.attr == "" {
+				return nil, fmt.Errorf("selector %q has an empty predicate", selector)
+			}
+			step.predicates = append(step.predicates, p)
+			rest = rest[end+1:]
+		}
+		steps = append(steps, step)
+		if rest == "" {
+			return steps, nil
+		}
+		if rest[0] != '.' {
+			return nil, fmt.Errorf("selector %q has an unexpected character at %q", selector, rest)
+		}
+		rest = rest[1:]
+	}
+}
+
+// queryDeclarations returns declared types or annotation types of all fragments in the order of locations.
+// Data type fragments declare their types named like in the type graph.
+func (r *RAML) queryDeclarations(kind string) []QueryMatch {
+	locations := make([]string, 0, len(r.fragmentsCache))
+	for loc := range r.fragmentsCache {
+		locations = append(locations, loc)
+	}
+	slices.Sort(locations)
+	var result []QueryMatch
+	for _, loc := range locations {
+		switch f := r.fragmentsCache[loc].(type) {
+		case *Library:
+			decls := f.Types
+			if kind == "annotationTypes" {
+				decls = f.AnnotationTypes
+			}
+			for pair := decls.Oldest(); pair != nil; pair = pair.Next() {
+				result = append(result, shapeMatch(kind+"."+pair.Key, pair.Value))
+			}
+		case *DataType:
+			if f.Shape == nil || kind != "types" {
+				continue
+			}
+			name := f.Shape.Name
+			if name == "" {
+				name = strings.TrimSuffix(filepath.Base(f.Location), filepath.Ext(f.Location))
+			}
+			result = append(result, shapeMatch(kind+"."+name, f.Shape))
+		}
+	}
+	return result
+}
+
+func shapeMatch(path string, s *BaseShape) QueryMatch {
+	return QueryMatch{Path: path, Shape: s, Location: s.Location(), Position: s.Position}
+}
+
+// queryTarget follows references to declared types and included data types.
+func queryTarget(s *BaseShape) *BaseShape {
+	for s != nil {
+		switch {
+		case s.Alias != nil && s.Alias != s:
+			s = s.Alias
+		case s.Link != nil && s.Link.Shape != nil:
+			s = s.Link.Shape
+		default:
+			if rec, ok := s.Shape.(*RecursiveShape); ok && rec.Head != nil && rec.Head != s {
+				s = rec.Head
+				continue
+			}
+			return s
+		}
+	}
+	return s
+}
+
+// queryCollection returns properties, facet definitions or union members of the matched shape.
+func queryCollection(m QueryMatch, kind string) []QueryMatch {
+	s := queryTarget(m.Shape)
+	var result []QueryMatch
+	propertyMatch := func(prop Property) QueryMatch {
+		pm := shapeMatch(m.Path+"."+kind+"."+prop.Name, prop.Shape)
+		pm.Property = &prop
+		return pm
+	}
+	switch kind {
+	case "properties":
+		// Properties of parents are not merged until unwrapping, overridden ones are skipped.
+		seen := make(map[string]struct{})
+		var collect func(s *BaseShape)
+		collect = func(s *BaseShape) {
+			obj, ok := s.Shape.(*ObjectShape)
+			if !ok {
+				return
+			}
+			for pair := obj.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				if _, ok = seen[pair.Key]; !ok {
+					seen[pair.Key] = struct{}{}
+					result = append(result, propertyMatch(pair.Value))
+				}
+			}
+			for pair := obj.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+				if _, ok = seen[pair.Key]; !ok {
+					seen[pair.Key] = struct{}{}
+					result = append(result, propertyMatch(Property{Name: pair.Key, Shape: pair.Value.Shape}))
+				}
+			}
+			for _, parent := range s.Inherits {
+				collect(queryTarget(parent))
+			}
+		}
+		collect(s)
+	case "facets":
+		for pair := s.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
+			result = append(result, propertyMatch(pair.Value))
+		}
+	case "anyOf":
+		if union, ok := s.Shape.(*UnionShape); ok {
+			for i, member := range union.AnyOf {
+				result = append(result, shapeMatch(m.Path+".anyOf."+strconv.Itoa(i), member))
+			}
+		}
+	}
+	return result
+}
+
+// appendNested appends properties, items and union members nested in the matched shape. Shapes of declared types
+// are followed only once.
+func appendNested(result []QueryMatch, m QueryMatch, visited map[int64]struct{}) []QueryMatch {
+	s := queryTarget(m.Shape)
+	if s == nil {
+		return result
+	}
+	if _, ok := visited[s.ID]; ok {
+		return result
+	}
+	visited[s.ID] = struct{}{}
+	var nested []QueryMatch
+	switch shape := s.Shape.(type) {
+	case *ObjectShape:
+		nested = queryCollection(m, "properties")
+	case *ArrayShape:
+		if shape.Items != nil {
+			nested = []QueryMatch{shapeMatch(m.Path+".items", shape.Items)}
+		}
+	case *UnionShape:
+		nested = queryCollection(m, "anyOf")
+	}
+	for _, n := range nested {
+		result = append(result, n)
+		result = appendNested(result, n, visited)
+	}
+	return result
+}
+
+// filterMatches returns matches with names matching the pattern and satisfying the predicates. An empty pattern
+// matches any name.
+func filterMatches(matches []QueryMatch, predicates []queryPredicate, pattern string) []QueryMatch {
+	result := matches[:0]
+	for _, m := range matches {
+		if pattern != "" && !globMatch(pattern, matchName(m)) {
+			continue
+		}
+		ok := true
+		for _, p := range predicates {
+			if p.test(m) == p.negate {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// matchName returns the last element of the path of the match.
+func matchName(m QueryMatch) string {
+	return m.Path[strings.LastIndexByte(m.Path, '.')+1:]
+}
+
+// globMatch reports whether the name matches the pattern, where "*" matches any sequence of characters.
+func globMatch(pattern string, name string) bool {
+	prefix, rest, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return pattern == name
+	}
+	name, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return false
+	}
+	for i := 0; i <= len(name); i++ {
+		if globMatch(rest, name[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// test reports whether the match satisfies the predicate without negation.
+func (p queryPredicate) test(m QueryMatch) bool {
+	var value string
+	present := true
+	switch p.attr {
+	case "name":
+		value = matchName(m)
+	case "type":
+		value = m.Shape.Type
+	case "required":
+		required := m.Property != nil && m.Property.Required
+		value, present = strconv.FormatBool(required), required
+	case "displayName":
+		if m.Shape.DisplayName != nil {
+			value = *m.Shape.DisplayName
+		}
+		present = value != ""
+	case "description":
+		if m.Shape.Description != nil {
+			value = *m.Shape.Description
+		}
+		present = value != ""
+	default:
+		annotation, ok := strings.CutPrefix(p.attr, "(")
+		if annotation, ok = strings.CutSuffix(annotation, ")"); !ok {
+			return false
+		}
+		de, found := m.Shape.CustomDomainProperties.Get(annotation)
+		if !found {
+			return false
+		}
+		if de.Extension != nil {
+			value = fmt.Sprint(de.Extension.Value)
+		}
+	}
+	if p.exists {
+		return present
+	}
+	return globMatch(p.value, value)
+}
@@ -0,0 +1,70 @@
+package raml
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAML_Query(t *testing.T) {
+	dir, err := filepath.Abs("./fixtures/query")
+	require.NoError(t, err)
+	content := `#%RAML 1.0 Library
+uses:
+  c: common.raml
+types:
+  Owner:
+    properties:
+      id: c.Id
+      pet:
+        type: c.Pet
+        (c.internal): true
+      nickname?: string
+      /^x-/: string
+  Owners: Owner[]
+  Choice: Owner | c.Pet
+`
+	rml, err := ParseFromString(content, "library.raml", dir)
+	require.NoError(t, err)
+
+	paths := func(selector string) []string {
+		t.Helper()
+		matches, err := rml.Query(selector)
+		require.NoError(t, err)
+		var result []string
+		for _, m := range matches {
+			result = append(result, m.Path)
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"types.Owner", "types.Owners"}, paths("types.*[name=Own*]"))
+	require.Equal(t, []string{"types.Owner.properties.id", "types.Owner.properties.pet"},
+		paths("types.Owner.properties[required=true]"))
+	require.Equal(t, []string{"types.Owner.properties.nickname", "types.Owner.properties./^x-/"},
+		paths("types.Owner.properties[required!=true]"))
+	require.Equal(t, []string{"types.Owner.properties.pet"}, paths("types.*.properties[(c.internal)]"))
+	require.Equal(t, []string{"types.Pet.properties.name", "types.Named.properties.name"},
+		paths("types.*.properties.name"))
+	require.Equal(t, []string{"types.Owner.properties.pet.properties.name"},
+		paths("types.Owner.properties.pet.properties.name"))
+	require.Equal(t, []string{"types.Owners.items"}, paths("types.Owners.items"))
+	require.Equal(t, []string{"types.Choice.anyOf.1"}, paths("types.Choice.anyOf.1"))
+	require.Equal(t, []string{"types.Choice.anyOf.0.properties.pet.properties.name",
+		"types.Choice.anyOf.1.properties.name"}, paths("types.Choice.**.properties.name"))
+	require.Equal(t, []string{"annotationTypes.internal"}, paths("annotationTypes"))
+
+	matches, err := rml.Query("types.Owner.properties.nickname")
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.Equal(t, "nickname", matches[0].Property.Name)
+	require.Equal(t, "string", matches[0].Shape.Type)
+	require.Equal(t, rml.EntryPoint().GetLocation(), matches[0].Location)
+	require.Equal(t, 11, matches[0].Line)
+
+	for _, selector := range []string{"", "properties", "types..x", "types[name", "types.*.items[a]b", "types.x[y"} {
+		_, err = rml.Query(selector)
+		require.Error(t, err, selector)
+	}
+}
@@ -4,6 +4,12 @@ import (
 	"container/list"
 	"context"
 	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/acronis/go-stacktrace"
 )
 
 // RAML is a store for all fragments and shapes.
@@ -14,16 +20,124 @@ type RAML struct {
 	fragmentAnnotationTypes map[string]map[string]*BaseShape
 	// entryPoint is a Library, NamedExample or DataType fragment that is used as an entry point for the resolution.
 	entryPoint Fragment
+	// api is the API definition if the entry point is an API root. Its library is the entry point.
+	api *API
 	// basePath   string
 
 	// May be reused for both validation and resolution.
 	domainExtensions []*DomainExtension
 
 	shapes []*BaseShape
+	// lastShapeID is the ID of the last created shape.
+	lastShapeID int64
+	// derivedShapeIDs derives IDs of shapes from their locations instead of numbering them.
+	derivedShapeIDs bool
+	// usedShapeIDs contains derived IDs of shapes.
+	usedShapeIDs map[int64]struct{}
+	// lowMemory interns names, allocates shapes in blocks and allocates maps of shapes lazily.
+	lowMemory bool
+	// interned contains interned strings in low-memory mode.
+	interned map[string]string
+	// shapeBlock is the rest of the block that base shapes are allocated from in low-memory mode.
+	shapeBlock []BaseShape
+	// locations contains fragment locations indexed by their handles minus one, see locationTable.
+	locations atomic.Pointer[[]string]
+	// locationIDs maps locations of fragments of the model to their handles.
+	locationIDs map[string]LocationID
+	// mu guards shapes, derived shape IDs, interned strings, fragment locations and warnings while shapes are resolved in parallel.
+	mu sync.Mutex
 	// Temporary storage for unresolved shapes.
 	unresolvedShapes list.List
 
-	// ctx is a context of the RAML, for future use.
+	// fragmentDependents maps a location to the set of fragment locations that depend on it
+	// either through uses or !include.
+	fragmentDependents map[string]map[string]struct{}
+	// invalidatedFragments is a set of locations that must be re-read on the next Reparse call.
+	invalidatedFragments map[string]struct{}
+	// fragmentListeners receive changes of fragmentsCache, lastSubscriptionID identifies their subscriptions.
+	fragmentListeners  []fragmentSubscription
+	lastSubscriptionID int
+	// plugins extend the pipeline stages, parsedFragments are locations of fragments registered since the last call
+	// of their FragmentParsedHook.
+	plugins         []Plugin
+	parsedFragments []string
+
+	// timings are durations of the pipeline stages.
+	timings Timings
+
+	// fractionalSeconds is a policy of fractional seconds in date-time instances.
+	fractionalSeconds FractionalSeconds
+	// stringLength is the mode of counting minLength and maxLength of string instances.
+	stringLength StringLength
+	// facetValidators maps names of custom facets and annotations to registered validators.
+	facetValidators map[string][]FacetValidator
+	// includeResolver loads included fragments, FileResolver is used if nil.
+	includeResolver IncludeResolver
+	// libraryVersions designates annotation types that version libraries, see OptWithLibraryVersions.
+	libraryVersions LibraryVersions
+	// regexEngine compiles patterns, RE2Engine is used if nil.
+	regexEngine RegexEngine
+	// lenientPatterns turns patterns that regexEngine cannot compile into warnings.
+	lenientPatterns bool
+	// patterns caches compiled patterns by their sources, so identical patterns share one regular expression.
+	patterns   map[string]Regexp
+	patternsMu sync.Mutex
+	// warnings contains non-fatal issues found while reading fragments.
+	warnings []*stacktrace.StackTrace
+	// analysisWarnings contains non-fatal issues found by the analysis of the resolved model.
+	analysisWarnings []*stacktrace.StackTrace
+	// usedLibraries maps fragment locations to aliases of libraries referenced by the fragments.
+	usedLibraries map[string]map[string]struct{}
+	// subtypesMu guards subtypes and unwrappedSubtypes since copies of shapes may be unwrapped concurrently.
+	subtypesMu sync.Mutex
+	// subtypes maps IDs of object types with discriminator to their declared subtypes.
+	subtypes map[int64][]*BaseShape
+	// unwrappedSubtypes caches unwrapped copies of subtypes that were not unwrapped in-place.
+	unwrappedSubtypes map[int64]*BaseShape
+	// recursionCycles contains recursion cycles found while marking recursive shapes.
+	recursionCycles    []*RecursionCycle
+	recursionCycleKeys map[string]struct{}
+	// recursionStack contains shapes that are being marked, from the declared type to the current shape.
+	recursionStack []*BaseShape
+	// restrictedPatternProperties allows pattern properties together with "additionalProperties: false".
+	restrictedPatternProperties bool
+	// strictDuplicateKeys turns duplicate keys in fragments into errors instead of warnings.
+	strictDuplicateKeys bool
+	// strictAny turns accidental uses of the any type into errors, see OptWithStrictAny.
+	strictAny bool
+	// importJSONSchema converts JSON schemas of types into native shapes.
+	importJSONSchema bool
+	// preserveDeclared keeps copies of declared shapes when the model is resolved.
+	preserveDeclared bool
+	// declaredShapes maps IDs of shapes to their copies made before resolution.
+	declaredShapes map[int64]*BaseShape
+	// stage is the last completed stage of the parsing pipeline.
+	stage PipelineStage
+	// inheritancePolicy defines how constraint conflicts during inheritance are treated.
+	inheritancePolicy InheritancePolicy
+	// resolveWorkers is the number of workers that unwrap types concurrently, zero means sequential unwrapping.
+	resolveWorkers int
+	// maxRecursionDepth limits the depth of values validated against recursive shapes, zero means no limit.
+	maxRecursionDepth int
+	// yamlAliases expands YAML aliases and merge keys instead of rejecting them.
+	yamlAliases bool
+	// resolvingShapes contains shapes that are being resolved, from the outermost one.
+	resolvingShapes []*BaseShape
+	// progress receives progress of parsing and resolution, nil if not reported.
+	progress ProgressHandler
+	// logger receives logs of the pipeline, nil if not logged. fragmentStarts are start times of fragments that are
+	// being decoded.
+	logger         *slog.Logger
+	fragmentStarts []time.Time
+	// metrics are instrumentation points of the pipeline and of validation.
+	metrics Metrics
+	// limits bounds the resources used by parsing, fragmentsRead, bytesRead and fragmentDepths track their usage.
+	limits         Limits
+	fragmentsRead  int
+	bytesRead      int64
+	fragmentDepths map[string]int
+
+	// ctx is the context of the RAML that parsing and the pipeline stages honor by default.
 	ctx context.Context
 }
 
@@ -68,6 +182,8 @@ func New(ctx context.Context) *RAML {
 		fragmentAnnotationTypes: make(map[string]map[string]*BaseShape),
 		fragmentsCache:          make(map[string]Fragment),
 		domainExtensions:        make([]*DomainExtension, 0),
+		fragmentDependents:      make(map[string]map[string]struct{}),
+		invalidatedFragments:    make(map[string]struct{}),
 		ctx:                     ctx,
 	}
 }
@@ -78,6 +194,8 @@ func (r *RAML) GetShapes() []*BaseShape {
 }
 
 func (r *RAML) PutShape(shape *BaseShape) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.shapes = append(r.shapes, shape)
 }
 
@@ -131,31 +249,31 @@ func (r *RAML) GetFragment(location string) Fragment {
 
 // PutFragment puts a fragment.
 func (r *RAML) PutFragment(location string, fragment Fragment) {
-	if _, ok := r.fragmentsCache[location]; !ok {
-		r.fragmentsCache[location] = fragment
-	}
+	r.putFragment(location, fragment, r.fragmentKind(fragment))
 }
 
 func (r *RAML) GetReferencedType(refName string, location string) (*BaseShape, error) {
 	frag := r.GetFragment(location)
 	if frag == nil {
-		return nil, fmt.Errorf("fragment not found")
+		return nil, fmt.Errorf("fragment %s not found", location)
 	}
 	ref, err := frag.GetReferenceType(refName)
 	if err != nil {
 		return nil, fmt.Errorf("get reference type: %s: %w", refName, err)
 	}
+	r.markLibraryUsed(refName, location)
 	return ref, nil
 }
 
 func (r *RAML) GetReferencedAnnotationType(refName string, location string) (*BaseShape, error) {
 	frag := r.GetFragment(location)
 	if frag == nil {
-		return nil, fmt.Errorf("fragment not found")
+		return nil, fmt.Errorf("fragment %s not found", location)
 	}
 	ref, err := frag.GetReferenceAnnotationType(refName)
 	if err != nil {
 		return nil, fmt.Errorf("get reference annotation type: %s: %w", refName, err)
 	}
+	r.markLibraryUsed(refName, location)
 	return ref, nil
 }
@@ -0,0 +1,43 @@
+package raml
+
+import "gopkg.in/yaml.v3"
+
+// rawNode returns the node to keep on the model element, nil in low-memory mode.
+func (r *RAML) rawNode(node *yaml.Node) *yaml.Node {
+	if r == nil || r.lowMemory {
+		return nil
+	}
+	return node
+}
+
+// RawNode returns the YAML node the shape was decoded from, nil for shapes created by resolution or in low-memory
+// mode. The node gives access to styles, comments and exact scalar values that the model does not keep.
+func (s *BaseShape) RawNode() *yaml.Node {
+	return s.rawNode
+}
+
+// RawNode returns the YAML key node of the property declaration, while the value is the raw node of its shape.
+func (p Property) RawNode() *yaml.Node {
+	return p.rawNode
+}
+
+// RawNode returns the YAML key node of the pattern property declaration, while the value is the raw node of its
+// shape.
+func (p PatternProperty) RawNode() *yaml.Node {
+	return p.rawNode
+}
+
+// RawNode returns the YAML mapping node the library was decoded from, nil in low-memory mode.
+func (l *Library) RawNode() *yaml.Node {
+	return l.rawNode
+}
+
+// RawNode returns the YAML mapping node the data type fragment was decoded from, nil in low-memory mode.
+func (dt *DataType) RawNode() *yaml.Node {
+	return dt.rawNode
+}
+
+// RawNode returns the YAML mapping node the named example fragment was decoded from, nil in low-memory mode.
+func (ne *NamedExample) RawNode() *yaml.Node {
+	return ne.rawNode
+}
@@ -0,0 +1,44 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRawNode(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Pet:
+    type: object
+    properties:
+      # The name of the pet.
+      name: 'string'
+      /^x-/: string
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+	lib := rml.EntryPoint().(*Library)
+	require.Equal(t, yaml.MappingNode, lib.RawNode().Kind)
+	require.Equal(t, 2, lib.RawNode().Line)
+
+	pet, _ := lib.Types.Get("Pet")
+	require.Equal(t, yaml.MappingNode, pet.RawNode().Kind)
+	require.Equal(t, 4, pet.RawNode().Line)
+
+	obj := pet.Shape.(*ObjectShape)
+	name, _ := obj.Properties.Get("name")
+	require.Equal(t, "name", name.RawNode().Value)
+	require.Equal(t, "# The name of the pet.", name.RawNode().HeadComment)
+	require.Equal(t, yaml.SingleQuotedStyle, name.Shape.RawNode().Style)
+	pattern, _ := obj.PatternProperties.Get("/^x-/")
+	require.Equal(t, "/^x-/", pattern.RawNode().Value)
+
+	rml, err = ParseFromString(content, "library.raml", t.TempDir(), OptWithLowMemory())
+	require.NoError(t, err)
+	lib = rml.EntryPoint().(*Library)
+	require.Nil(t, lib.RawNode())
+	pet, _ = lib.Types.Get("Pet")
+	require.Nil(t, pet.RawNode())
+}
@@ -8,7 +8,9 @@ entrypoint: expression EOF;
 
 expression: type | union;
 
-type: primitive | group | reference | array | optional;
+// Postfix notations bind tighter than unions and apply from left to right, e.g. "A[]?" is "(A[])?" and "A | B[]" is
+// "A | (B[])".
+type: (primitive | group | reference) (ARRAY_NOTATION | OPTIONAL_NOTATION)*;
 
 primitive:
 	STRING_TYPE
@@ -26,10 +28,6 @@ primitive:
 	| OBJECT_TYPE
 	| UNION_TYPE;
 
-optional: (primitive | group | reference) OPTIONAL_NOTATION;
-
-array: (primitive | group | reference) ARRAY_NOTATION;
-
 union: type WS* (PIPE WS* type WS*)+;
 
 group: LPAREN expression RPAREN;
@@ -45,45 +45,36 @@ func rdtparserParserInit() {
 		"IDENTIFIER", "WS",
 	}
 	staticData.RuleNames = []string{
-		"entrypoint", "expression", "type", "primitive", "optional", "array",
-		"union", "group", "reference",
+		"entrypoint", "expression", "type", "primitive", "union", "group", "reference",
 	}
 	staticData.PredictionContextCache = antlr.NewPredictionContextCache()
 	staticData.serializedATN = []int32{
-		4, 1, 22, 83, 2, 0, 7, 0, 2, 1, 7, 1, 2, 2, 7, 2, 2, 3, 7, 3, 2, 4, 7,
-		4, 2, 5, 7, 5, 2, 6, 7, 6, 2, 7, 7, 7, 2, 8, 7, 8, 1, 0, 1, 0, 1, 0, 1,
-		1, 1, 1, 3, 1, 24, 8, 1, 1, 2, 1, 2, 1, 2, 1, 2, 1, 2, 3, 2, 31, 8, 2,
-		1, 3, 1, 3, 1, 4, 1, 4, 1, 4, 3, 4, 38, 8, 4, 1, 4, 1, 4, 1, 5, 1, 5, 1,
-		5, 3, 5, 45, 8, 5, 1, 5, 1, 5, 1, 6, 1, 6, 5, 6, 51, 8, 6, 10, 6, 12, 6,
-		54, 9, 6, 1, 6, 1, 6, 5, 6, 58, 8, 6, 10, 6, 12, 6, 61, 9, 6, 1, 6, 1,
-		6, 5, 6, 65, 8, 6, 10, 6, 12, 6, 68, 9, 6, 4, 6, 70, 8, 6, 11, 6, 12, 6,
-		71, 1, 7, 1, 7, 1, 7, 1, 7, 1, 8, 1, 8, 1, 8, 3, 8, 81, 8, 8, 1, 8, 0,
-		0, 9, 0, 2, 4, 6, 8, 10, 12, 14, 16, 0, 1, 1, 0, 7, 20, 87, 0, 18, 1, 0,
-		0, 0, 2, 23, 1, 0, 0, 0, 4, 30, 1, 0, 0, 0, 6, 32, 1, 0, 0, 0, 8, 37, 1,
-		0, 0, 0, 10, 44, 1, 0, 0, 0, 12, 48, 1, 0, 0, 0, 14, 73, 1, 0, 0, 0, 16,
-		77, 1, 0, 0, 0, 18, 19, 3, 2, 1, 0, 19, 20, 5, 0, 0, 1, 20, 1, 1, 0, 0,
-		0, 21, 24, 3, 4, 2, 0, 22, 24, 3, 12, 6, 0, 23, 21, 1, 0, 0, 0, 23, 22,
-		1, 0, 0, 0, 24, 3, 1, 0, 0, 0, 25, 31, 3, 6, 3, 0, 26, 31, 3, 14, 7, 0,
-		27, 31, 3, 16, 8, 0, 28, 31, 3, 10, 5, 0, 29, 31, 3, 8, 4, 0, 30, 25, 1,
-		0, 0, 0, 30, 26, 1, 0, 0, 0, 30, 27, 1, 0, 0, 0, 30, 28, 1, 0, 0, 0, 30,
-		29, 1, 0, 0, 0, 31, 5, 1, 0, 0, 0, 32, 33, 7, 0, 0, 0, 33, 7, 1, 0, 0,
-		0, 34, 38, 3, 6, 3, 0, 35, 38, 3, 14, 7, 0, 36, 38, 3, 16, 8, 0, 37, 34,
-		1, 0, 0, 0, 37, 35, 1, 0, 0, 0, 37, 36, 1, 0, 0, 0, 38, 39, 1, 0, 0, 0,
-		39, 40, 5, 5, 0, 0, 40, 9, 1, 0, 0, 0, 41, 45, 3, 6, 3, 0, 42, 45, 3, 14,
-		7, 0, 43, 45, 3, 16, 8, 0, 44, 41, 1, 0, 0, 0, 44, 42, 1, 0, 0, 0, 44,
-		43, 1, 0, 0, 0, 45, 46, 1, 0, 0, 0, 46, 47, 5, 4, 0, 0, 47, 11, 1, 0, 0,
-		0, 48, 52, 3, 4, 2, 0, 49, 51, 5, 22, 0, 0, 50, 49, 1, 0, 0, 0, 51, 54,
-		1, 0, 0, 0, 52, 50, 1, 0, 0, 0, 52, 53, 1, 0, 0, 0, 53, 69, 1, 0, 0, 0,
-		54, 52, 1, 0, 0, 0, 55, 59, 5, 3, 0, 0, 56, 58, 5, 22, 0, 0, 57, 56, 1,
-		0, 0, 0, 58, 61, 1, 0, 0, 0, 59, 57, 1, 0, 0, 0, 59, 60, 1, 0, 0, 0, 60,
-		62, 1, 0, 0, 0, 61, 59, 1, 0, 0, 0, 62, 66, 3, 4, 2, 0, 63, 65, 5, 22,
-		0, 0, 64, 63, 1, 0, 0, 0, 65, 68, 1, 0, 0, 0, 66, 64, 1, 0, 0, 0, 66, 67,
-		1, 0, 0, 0, 67, 70, 1, 0, 0, 0, 68, 66, 1, 0, 0, 0, 69, 55, 1, 0, 0, 0,
-		70, 71, 1, 0, 0, 0, 71, 69, 1, 0, 0, 0, 71, 72, 1, 0, 0, 0, 72, 13, 1,
-		0, 0, 0, 73, 74, 5, 1, 0, 0, 74, 75, 3, 2, 1, 0, 75, 76, 5, 2, 0, 0, 76,
-		15, 1, 0, 0, 0, 77, 80, 5, 21, 0, 0, 78, 79, 5, 6, 0, 0, 79, 81, 5, 21,
-		0, 0, 80, 78, 1, 0, 0, 0, 80, 81, 1, 0, 0, 0, 81, 17, 1, 0, 0, 0, 9, 23,
-		30, 37, 44, 52, 59, 66, 71, 80,
+		4, 1, 22, 69, 2, 0, 7, 0, 2, 1, 7, 1, 2, 2, 7, 2, 2, 3, 7, 3, 2, 4, 7, 4, 2,
+		5, 7, 5, 2, 6, 7, 6, 1, 0, 1, 0, 1, 0, 1, 1, 1, 1, 3, 1, 20, 8, 1, 1, 2, 1,
+		2, 1, 2, 3, 2, 25, 8, 2, 1, 2, 5, 2, 28, 8, 2, 10, 2, 12, 2, 31, 9, 2, 1, 3,
+		1, 3, 1, 4, 1, 4, 5, 4, 37, 8, 4, 10, 4, 12, 4, 40, 9, 4, 1, 4, 1, 4, 5, 4,
+		44, 8, 4, 10, 4, 12, 4, 47, 9, 4, 1, 4, 1, 4, 5, 4, 51, 8, 4, 10, 4, 12, 4,
+		54, 9, 4, 4, 4, 56, 8, 4, 11, 4, 12, 4, 57, 1, 5, 1, 5, 1, 5, 1, 5, 1, 6, 1,
+		6, 1, 6, 3, 6, 67, 8, 6, 1, 6, 0, 0, 7, 0, 2, 4, 6, 8, 10, 12, 0, 2, 1, 0, 4,
+		5, 1, 0, 7, 20, 70, 0, 14, 1, 0, 0, 0, 2, 19, 1, 0, 0, 0, 4, 24, 1, 0, 0, 0,
+		6, 32, 1, 0, 0, 0, 8, 34, 1, 0, 0, 0, 10, 59, 1, 0, 0, 0, 12, 63, 1, 0, 0, 0,
+		14, 15, 3, 2, 1, 0, 15, 16, 5, 0, 0, 1, 16, 1, 1, 0, 0, 0, 17, 20, 3, 4, 2,
+		0, 18, 20, 3, 8, 4, 0, 19, 17, 1, 0, 0, 0, 19, 18, 1, 0, 0, 0, 20, 3, 1, 0,
+		0, 0, 21, 25, 3, 6, 3, 0, 22, 25, 3, 10, 5, 0, 23, 25, 3, 12, 6, 0, 24, 21,
+		1, 0, 0, 0, 24, 22, 1, 0, 0, 0, 24, 23, 1, 0, 0, 0, 25, 29, 1, 0, 0, 0, 26,
+		28, 7, 0, 0, 0, 27, 26, 1, 0, 0, 0, 28, 31, 1, 0, 0, 0, 29, 27, 1, 0, 0, 0,
+		29, 30, 1, 0, 0, 0, 30, 5, 1, 0, 0, 0, 31, 29, 1, 0, 0, 0, 32, 33, 7, 1, 0,
+		0, 33, 7, 1, 0, 0, 0, 34, 38, 3, 4, 2, 0, 35, 37, 5, 22, 0, 0, 36, 35, 1, 0,
+		0, 0, 37, 40, 1, 0, 0, 0, 38, 36, 1, 0, 0, 0, 38, 39, 1, 0, 0, 0, 39, 55, 1,
+		0, 0, 0, 40, 38, 1, 0, 0, 0, 41, 45, 5, 3, 0, 0, 42, 44, 5, 22, 0, 0, 43, 42,
+		1, 0, 0, 0, 44, 47, 1, 0, 0, 0, 45, 43, 1, 0, 0, 0, 45, 46, 1, 0, 0, 0, 46,
+		48, 1, 0, 0, 0, 47, 45, 1, 0, 0, 0, 48, 52, 3, 4, 2, 0, 49, 51, 5, 22, 0, 0,
+		50, 49, 1, 0, 0, 0, 51, 54, 1, 0, 0, 0, 52, 50, 1, 0, 0, 0, 52, 53, 1, 0, 0,
+		0, 53, 56, 1, 0, 0, 0, 54, 52, 1, 0, 0, 0, 55, 41, 1, 0, 0, 0, 56, 57, 1, 0,
+		0, 0, 57, 55, 1, 0, 0, 0, 57, 58, 1, 0, 0, 0, 58, 9, 1, 0, 0, 0, 59, 60, 5,
+		1, 0, 0, 60, 61, 3, 2, 1, 0, 61, 62, 5, 2, 0, 0, 62, 11, 1, 0, 0, 0, 63, 66,
+		5, 21, 0, 0, 64, 65, 5, 6, 0, 0, 65, 67, 5, 21, 0, 0, 66, 64, 1, 0, 0, 0, 66,
+		67, 1, 0, 0, 0, 67, 13, 1, 0, 0, 0, 8, 19, 24, 29, 38, 45, 52, 57, 66,
 	}
 	deserializer := antlr.NewATNDeserializer(nil)
 	staticData.atn = deserializer.Deserialize(staticData.serializedATN)
@@ -152,11 +143,9 @@ const (
 	rdtParserRULE_expression = 1
 	rdtParserRULE_type       = 2
 	rdtParserRULE_primitive  = 3
-	rdtParserRULE_optional   = 4
-	rdtParserRULE_array      = 5
-	rdtParserRULE_union      = 6
-	rdtParserRULE_group      = 7
-	rdtParserRULE_reference  = 8
+	rdtParserRULE_union      = 4
+	rdtParserRULE_group      = 5
+	rdtParserRULE_reference  = 6
 )
 
 // IEntrypointContext is an interface to support dynamic dispatch.
@@ -249,11 +238,11 @@ func (p *rdtParser) Entrypoint() (localctx IEntrypointContext) {
 	p.EnterRule(localctx, 0, rdtParserRULE_entrypoint)
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(18)
+		p.SetState(14)
 		p.Expression()
 	}
 	{
-		p.SetState(19)
+		p.SetState(15)
 		p.Match(rdtParserEOF)
 		if p.HasError() {
 			// Recognition error - abort rule
@@ -374,7 +363,7 @@ func (s *ExpressionContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
 func (p *rdtParser) Expression() (localctx IExpressionContext) {
 	localctx = NewExpressionContext(p, p.GetParserRuleContext(), p.GetState())
 	p.EnterRule(localctx, 2, rdtParserRULE_expression)
-	p.SetState(23)
+	p.SetState(19)
 	p.GetErrorHandler().Sync(p)
 	if p.HasError() {
 		goto errorExit
@@ -384,14 +373,14 @@ func (p *rdtParser) Expression() (localctx IExpressionContext) {
 	case 1:
 		p.EnterOuterAlt(localctx, 1)
 		{
-			p.SetState(21)
+			p.SetState(17)
 			p.Type_()
 		}
 
 	case 2:
 		p.EnterOuterAlt(localctx, 2)
 		{
-			p.SetState(22)
+			p.SetState(18)
 			p.Union()
 		}
 
@@ -423,8 +412,10 @@ type ITypeContext interface {
 	Primitive() IPrimitiveContext
 	Group() IGroupContext
 	Reference() IReferenceContext
-	Array() IArrayContext
-	Optional() IOptionalContext
+	AllARRAY_NOTATION() []antlr.TerminalNode
+	ARRAY_NOTATION(i int) antlr.TerminalNode
+	AllOPTIONAL_NOTATION() []antlr.TerminalNode
+	OPTIONAL_NOTATION(i int) antlr.TerminalNode
 
 	// IsTypeContext differentiates from other interfaces.
 	IsTypeContext()
@@ -510,36 +501,20 @@ func (s *TypeContext) Reference() IReferenceContext {
 	return t.(IReferenceContext)
 }
 
-func (s *TypeContext) Array() IArrayContext {
-	var t antlr.RuleContext
-	for _, ctx := range s.GetChildren() {
-		if _, ok := ctx.(IArrayContext); ok {
-			t = ctx.(antlr.RuleContext)
-			break
-		}
-	}
-
-	if t == nil {
-		return nil
-	}
-
-	return t.(IArrayContext)
+func (s *TypeContext) AllARRAY_NOTATION() []antlr.TerminalNode {
+	return s.GetTokens(rdtParserARRAY_NOTATION)
 }
 
-func (s *TypeContext) Optional() IOptionalContext {
-	var t antlr.RuleContext
-	for _, ctx := range s.GetChildren() {
-		if _, ok := ctx.(IOptionalContext); ok {
-			t = ctx.(antlr.RuleContext)
-			break
-		}
-	}
+func (s *TypeContext) ARRAY_NOTATION(i int) antlr.TerminalNode {
+	return s.GetToken(rdtParserARRAY_NOTATION, i)
+}
 
-	if t == nil {
-		return nil
-	}
+func (s *TypeContext) AllOPTIONAL_NOTATION() []antlr.TerminalNode {
+	return s.GetTokens(rdtParserOPTIONAL_NOTATION)
+}
 
-	return t.(IOptionalContext)
+func (s *TypeContext) OPTIONAL_NOTATION(i int) antlr.TerminalNode {
+	return s.GetToken(rdtParserOPTIONAL_NOTATION, i)
 }
 
 func (s *TypeContext) GetRuleContext() antlr.RuleContext {
@@ -563,50 +538,64 @@ func (s *TypeContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
 func (p *rdtParser) Type_() (localctx ITypeContext) {
 	localctx = NewTypeContext(p, p.GetParserRuleContext(), p.GetState())
 	p.EnterRule(localctx, 4, rdtParserRULE_type)
-	p.SetState(30)
+	var _la int
+
+	p.EnterOuterAlt(localctx, 1)
+	p.SetState(24)
 	p.GetErrorHandler().Sync(p)
 	if p.HasError() {
 		goto errorExit
 	}
 
-	switch p.GetInterpreter().AdaptivePredict(p.BaseParser, p.GetTokenStream(), 1, p.GetParserRuleContext()) {
-	case 1:
-		p.EnterOuterAlt(localctx, 1)
+	switch p.GetTokenStream().LA(1) {
+	case rdtParserSTRING_TYPE, rdtParserINTEGER_TYPE, rdtParserNUMBER_TYPE, rdtParserBOOLEAN_TYPE, rdtParserDATETIME_TYPE, rdtParserTIME_ONLY_TYPE, rdtParserDATETIME_ONLY_TYPE, rdtParserDATE_ONLY_TYPE, rdtParserFILE_TYPE, rdtParserNIL_TYPE, rdtParserANY_TYPE, rdtParserARRAY_TYPE, rdtParserOBJECT_TYPE, rdtParserUNION_TYPE:
 		{
-			p.SetState(25)
+			p.SetState(21)
 			p.Primitive()
 		}
 
-	case 2:
-		p.EnterOuterAlt(localctx, 2)
+	case rdtParserLPAREN:
 		{
-			p.SetState(26)
+			p.SetState(22)
 			p.Group()
 		}
 
-	case 3:
-		p.EnterOuterAlt(localctx, 3)
+	case rdtParserIDENTIFIER:
 		{
-			p.SetState(27)
+			p.SetState(23)
 			p.Reference()
 		}
 
-	case 4:
-		p.EnterOuterAlt(localctx, 4)
-		{
-			p.SetState(28)
-			p.Array()
-		}
+	default:
+		p.SetError(antlr.NewNoViableAltException(p, nil, nil, nil, nil, nil))
+		goto errorExit
+	}
+	p.SetState(29)
+	p.GetErrorHandler().Sync(p)
+	if p.HasError() {
+		goto errorExit
+	}
+	_la = p.GetTokenStream().LA(1)
 
-	case 5:
-		p.EnterOuterAlt(localctx, 5)
+	for _la == rdtParserARRAY_NOTATION || _la == rdtParserOPTIONAL_NOTATION {
 		{
-			p.SetState(29)
-			p.Optional()
+			p.SetState(26)
+			_la = p.GetTokenStream().LA(1)
+
+			if !(_la == rdtParserARRAY_NOTATION || _la == rdtParserOPTIONAL_NOTATION) {
+				p.GetErrorHandler().RecoverInline(p)
+			} else {
+				p.GetErrorHandler().ReportMatch(p)
+				p.Consume()
+			}
 		}
 
-	case antlr.ATNInvalidAltNumber:
-		goto errorExit
+		p.SetState(31)
+		p.GetErrorHandler().Sync(p)
+		if p.HasError() {
+			goto errorExit
+		}
+		_la = p.GetTokenStream().LA(1)
 	}
 
 errorExit:
@@ -786,354 +775,6 @@ errorExit:
 	goto errorExit // Trick to prevent compiler error if the label is not used
 }
 
-// IOptionalContext is an interface to support dynamic dispatch.
-type IOptionalContext interface {
-	antlr.ParserRuleContext
-
-	// GetParser returns the parser.
-	GetParser() antlr.Parser
-
-	// Getter signatures
-	OPTIONAL_NOTATION() antlr.TerminalNode
-	Primitive() IPrimitiveContext
-	Group() IGroupContext
-	Reference() IReferenceContext
-
-	// IsOptionalContext differentiates from other interfaces.
-	IsOptionalContext()
-}
-
-type OptionalContext struct {
-	antlr.BaseParserRuleContext
-	parser antlr.Parser
-}
-
-func NewEmptyOptionalContext() *OptionalContext {
-	var p = new(OptionalContext)
-	antlr.InitBaseParserRuleContext(&p.BaseParserRuleContext, nil, -1)
-	p.RuleIndex = rdtParserRULE_optional
-	return p
-}
-
-func InitEmptyOptionalContext(p *OptionalContext) {
-	antlr.InitBaseParserRuleContext(&p.BaseParserRuleContext, nil, -1)
-	p.RuleIndex = rdtParserRULE_optional
-}
-
-func (*OptionalContext) IsOptionalContext() {}
-
-func NewOptionalContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *OptionalContext {
-	var p = new(OptionalContext)
-
-	antlr.InitBaseParserRuleContext(&p.BaseParserRuleContext, parent, invokingState)
-
-	p.parser = parser
-	p.RuleIndex = rdtParserRULE_optional
-
-	return p
-}
-
-func (s *OptionalContext) GetParser() antlr.Parser { return s.parser }
-
-func (s *OptionalContext) OPTIONAL_NOTATION() antlr.TerminalNode {
-	return s.GetToken(rdtParserOPTIONAL_NOTATION, 0)
-}
-
-func (s *OptionalContext) Primitive() IPrimitiveContext {
-	var t antlr.RuleContext
-	for _, ctx := range s.GetChildren() {
-		if _, ok := ctx.(IPrimitiveContext); ok {
-			t = ctx.(antlr.RuleContext)
-			break
-		}
-	}
-
-	if t == nil {
-		return nil
-	}
-
-	return t.(IPrimitiveContext)
-}
-
-func (s *OptionalContext) Group() IGroupContext {
-	var t antlr.RuleContext
-	for _, ctx := range s.GetChildren() {
-		if _, ok := ctx.(IGroupContext); ok {
-			t = ctx.(antlr.RuleContext)
-			break
-		}
-	}
-
-	if t == nil {
-		return nil
-	}
-
-	return t.(IGroupContext)
-}
-
-func (s *OptionalContext) Reference() IReferenceContext {
-	var t antlr.RuleContext
-	for _, ctx := range s.GetChildren() {
-		if _, ok := ctx.(IReferenceContext); ok {
-			t = ctx.(antlr.RuleContext)
-			break
-		}
-	}
-
-	if t == nil {
-		return nil
-	}
-
-	return t.(IReferenceContext)
-}
-
-func (s *OptionalContext) GetRuleContext() antlr.RuleContext {
-	return s
-}
-
-func (s *OptionalContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
-	return antlr.TreesStringTree(s, ruleNames, recog)
-}
-
-func (s *OptionalContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
-	switch t := visitor.(type) {
-	case rdtParserVisitor:
-		return t.VisitOptional(s)
-
-	default:
-		return t.VisitChildren(s)
-	}
-}
-
-func (p *rdtParser) Optional() (localctx IOptionalContext) {
-	localctx = NewOptionalContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 8, rdtParserRULE_optional)
-	p.EnterOuterAlt(localctx, 1)
-	p.SetState(37)
-	p.GetErrorHandler().Sync(p)
-	if p.HasError() {
-		goto errorExit
-	}
-
-	switch p.GetTokenStream().LA(1) {
-	case rdtParserSTRING_TYPE, rdtParserINTEGER_TYPE, rdtParserNUMBER_TYPE, rdtParserBOOLEAN_TYPE, rdtParserDATETIME_TYPE, rdtParserTIME_ONLY_TYPE, rdtParserDATETIME_ONLY_TYPE, rdtParserDATE_ONLY_TYPE, rdtParserFILE_TYPE, rdtParserNIL_TYPE, rdtParserANY_TYPE, rdtParserARRAY_TYPE, rdtParserOBJECT_TYPE, rdtParserUNION_TYPE:
-		{
-			p.SetState(34)
-			p.Primitive()
-		}
-
-	case rdtParserLPAREN:
-		{
-			p.SetState(35)
-			p.Group()
-		}
-
-	case rdtParserIDENTIFIER:
-		{
-			p.SetState(36)
-			p.Reference()
-		}
-
-	default:
-		p.SetError(antlr.NewNoViableAltException(p, nil, nil, nil, nil, nil))
-		goto errorExit
-	}
-	{
-		p.SetState(39)
-		p.Match(rdtParserOPTIONAL_NOTATION)
-		if p.HasError() {
-			// Recognition error - abort rule
-			goto errorExit
-		}
-	}
-
-errorExit:
-	if p.HasError() {
-		v := p.GetError()
-		localctx.SetException(v)
-		p.GetErrorHandler().ReportError(p, v)
-		p.GetErrorHandler().Recover(p, v)
-		p.SetError(nil)
-	}
-	p.ExitRule()
-	return localctx
-	goto errorExit // Trick to prevent compiler error if the label is not used
-}
-
-// IArrayContext is an interface to support dynamic dispatch.
-type IArrayContext interface {
-	antlr.ParserRuleContext
-
-	// GetParser returns the parser.
-	GetParser() antlr.Parser
-
-	// Getter signatures
-	ARRAY_NOTATION() antlr.TerminalNode
-	Primitive() IPrimitiveContext
-	Group() IGroupContext
-	Reference() IReferenceContext
-
-	// IsArrayContext differentiates from other interfaces.
-	IsArrayContext()
-}
-
-type ArrayContext struct {
-	antlr.BaseParserRuleContext
-	parser antlr.Parser
-}
-
-func NewEmptyArrayContext() *ArrayContext {
-	var p = new(ArrayContext)
-	antlr.InitBaseParserRuleContext(&p.BaseParserRuleContext, nil, -1)
-	p.RuleIndex = rdtParserRULE_array
-	return p
-}
-
-func InitEmptyArrayContext(p *ArrayContext) {
-	antlr.InitBaseParserRuleContext(&p.BaseParserRuleContext, nil, -1)
-	p.RuleIndex = rdtParserRULE_array
-}
-
-func (*ArrayContext) IsArrayContext() {}
-
-func NewArrayContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *ArrayContext {
-	var p = new(ArrayContext)
-
-	antlr.InitBaseParserRuleContext(&p.BaseParserRuleContext, parent, invokingState)
-
-	p.parser = parser
-	p.RuleIndex = rdtParserRULE_array
-
-	return p
-}
-
-func (s *ArrayContext) GetParser() antlr.Parser { return s.parser }
-
-func (s *ArrayContext) ARRAY_NOTATION() antlr.TerminalNode {
-	return s.GetToken(rdtParserARRAY_NOTATION, 0)
-}
-
-func (s *ArrayContext) Primitive() IPrimitiveContext {
-	var t antlr.RuleContext
-	for _, ctx := range s.GetChildren() {
-		if _, ok := ctx.(IPrimitiveContext); ok {
-			t = ctx.(antlr.RuleContext)
-			break
-		}
-	}
-
-	if t == nil {
-		return nil
-	}
-
-	return t.(IPrimitiveContext)
-}
-
-func (s *ArrayContext) Group() IGroupContext {
-	var t antlr.RuleContext
-	for _, ctx := range s.GetChildren() {
-		if _, ok := ctx.(IGroupContext); ok {
-			t = ctx.(antlr.RuleContext)
-			break
-		}
-	}
-
-	if t == nil {
-		return nil
-	}
-
-	return t.(IGroupContext)
-}
-
-func (s *ArrayContext) Reference() IReferenceContext {
-	var t antlr.RuleContext
-	for _, ctx := range s.GetChildren() {
-		if _, ok := ctx.(IReferenceContext); ok {
-			t = ctx.(antlr.RuleContext)
-			break
-		}
-	}
-
-	if t == nil {
-		return nil
-	}
-
-	return t.(IReferenceContext)
-}
-
-func (s *ArrayContext) GetRuleContext() antlr.RuleContext {
-	return s
-}
-
-func (s *ArrayContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
-	return antlr.TreesStringTree(s, ruleNames, recog)
-}
-
-func (s *ArrayContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
-	switch t := visitor.(type) {
-	case rdtParserVisitor:
-		return t.VisitArray(s)
-
-	default:
-		return t.VisitChildren(s)
-	}
-}
-
-func (p *rdtParser) Array() (localctx IArrayContext) {
-	localctx = NewArrayContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 10, rdtParserRULE_array)
-	p.EnterOuterAlt(localctx, 1)
-	p.SetState(44)
-	p.GetErrorHandler().Sync(p)
-	if p.HasError() {
-		goto errorExit
-	}
-
-	switch p.GetTokenStream().LA(1) {
-	case rdtParserSTRING_TYPE, rdtParserINTEGER_TYPE, rdtParserNUMBER_TYPE, rdtParserBOOLEAN_TYPE, rdtParserDATETIME_TYPE, rdtParserTIME_ONLY_TYPE, rdtParserDATETIME_ONLY_TYPE, rdtParserDATE_ONLY_TYPE, rdtParserFILE_TYPE, rdtParserNIL_TYPE, rdtParserANY_TYPE, rdtParserARRAY_TYPE, rdtParserOBJECT_TYPE, rdtParserUNION_TYPE:
-		{
-			p.SetState(41)
-			p.Primitive()
-		}
-
-	case rdtParserLPAREN:
-		{
-			p.SetState(42)
-			p.Group()
-		}
-
-	case rdtParserIDENTIFIER:
-		{
-			p.SetState(43)
-			p.Reference()
-		}
-
-	default:
-		p.SetError(antlr.NewNoViableAltException(p, nil, nil, nil, nil, nil))
-		goto errorExit
-	}
-	{
-		p.SetState(46)
-		p.Match(rdtParserARRAY_NOTATION)
-		if p.HasError() {
-			// Recognition error - abort rule
-			goto errorExit
-		}
-	}
-
-errorExit:
-	if p.HasError() {
-		v := p.GetError()
-		localctx.SetException(v)
-		p.GetErrorHandler().ReportError(p, v)
-		p.GetErrorHandler().Recover(p, v)
-		p.SetError(nil)
-	}
-	p.ExitRule()
-	return localctx
-	goto errorExit // Trick to prevent compiler error if the label is not used
-}
-
 // IUnionContext is an interface to support dynamic dispatch.
 type IUnionContext interface {
 	antlr.ParserRuleContext
@@ -1262,15 +903,15 @@ func (s *UnionContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
 
 func (p *rdtParser) Union() (localctx IUnionContext) {
 	localctx = NewUnionContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 12, rdtParserRULE_union)
+	p.EnterRule(localctx, 8, rdtParserRULE_union)
 	var _la int
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(48)
+		p.SetState(34)
 		p.Type_()
 	}
-	p.SetState(52)
+	p.SetState(38)
 	p.GetErrorHandler().Sync(p)
 	if p.HasError() {
 		goto errorExit
@@ -1279,7 +920,7 @@ func (p *rdtParser) Union() (localctx IUnionContext) {
 
 	for _la == rdtParserWS {
 		{
-			p.SetState(49)
+			p.SetState(35)
 			p.Match(rdtParserWS)
 			if p.HasError() {
 				// Recognition error - abort rule
@@ -1287,14 +928,14 @@ func (p *rdtParser) Union() (localctx IUnionContext) {
 			}
 		}
 
-		p.SetState(54)
+		p.SetState(40)
 		p.GetErrorHandler().Sync(p)
 		if p.HasError() {
 			goto errorExit
 		}
 		_la = p.GetTokenStream().LA(1)
 	}
-	p.SetState(69)
+	p.SetState(55)
 	p.GetErrorHandler().Sync(p)
 	if p.HasError() {
 		goto errorExit
@@ -1303,14 +944,14 @@ func (p *rdtParser) Union() (localctx IUnionContext) {
 
 	for ok := true; ok; ok = _la == rdtParserPIPE {
 		{
-			p.SetState(55)
+			p.SetState(41)
 			p.Match(rdtParserPIPE)
 			if p.HasError() {
 				// Recognition error - abort rule
 				goto errorExit
 			}
 		}
-		p.SetState(59)
+		p.SetState(45)
 		p.GetErrorHandler().Sync(p)
 		if p.HasError() {
 			goto errorExit
@@ -1319,7 +960,7 @@ func (p *rdtParser) Union() (localctx IUnionContext) {
 
 		for _la == rdtParserWS {
 			{
-				p.SetState(56)
+				p.SetState(42)
 				p.Match(rdtParserWS)
 				if p.HasError() {
 					// Recognition error - abort rule
@@ -1327,7 +968,7 @@ func (p *rdtParser) Union() (localctx IUnionContext) {
 				}
 			}
 
-			p.SetState(61)
+			p.SetState(47)
 			p.GetErrorHandler().Sync(p)
 			if p.HasError() {
 				goto errorExit
@@ -1335,10 +976,10 @@ func (p *rdtParser) Union() (localctx IUnionContext) {
 			_la = p.GetTokenStream().LA(1)
 		}
 		{
-			p.SetState(62)
+			p.SetState(48)
 			p.Type_()
 		}
-		p.SetState(66)
+		p.SetState(52)
 		p.GetErrorHandler().Sync(p)
 		if p.HasError() {
 			goto errorExit
@@ -1347,7 +988,7 @@ func (p *rdtParser) Union() (localctx IUnionContext) {
 
 		for _la == rdtParserWS {
 			{
-				p.SetState(63)
+				p.SetState(49)
 				p.Match(rdtParserWS)
 				if p.HasError() {
 					// Recognition error - abort rule
@@ -1355,7 +996,7 @@ func (p *rdtParser) Union() (localctx IUnionContext) {
 				}
 			}
 
-			p.SetState(68)
+			p.SetState(54)
 			p.GetErrorHandler().Sync(p)
 			if p.HasError() {
 				goto errorExit
@@ -1363,7 +1004,7 @@ func (p *rdtParser) Union() (localctx IUnionContext) {
 			_la = p.GetTokenStream().LA(1)
 		}
 
-		p.SetState(71)
+		p.SetState(57)
 		p.GetErrorHandler().Sync(p)
 		if p.HasError() {
 			goto errorExit
@@ -1476,10 +1117,10 @@ func (s *GroupContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
 
 func (p *rdtParser) Group() (localctx IGroupContext) {
 	localctx = NewGroupContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 14, rdtParserRULE_group)
+	p.EnterRule(localctx, 10, rdtParserRULE_group)
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(73)
+		p.SetState(59)
 		p.Match(rdtParserLPAREN)
 		if p.HasError() {
 			// Recognition error - abort rule
@@ -1487,11 +1128,11 @@ func (p *rdtParser) Group() (localctx IGroupContext) {
 		}
 	}
 	{
-		p.SetState(74)
+		p.SetState(60)
 		p.Expression()
 	}
 	{
-		p.SetState(75)
+		p.SetState(61)
 		p.Match(rdtParserRPAREN)
 		if p.HasError() {
 			// Recognition error - abort rule
@@ -1592,19 +1233,19 @@ func (s *ReferenceContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
 
 func (p *rdtParser) Reference() (localctx IReferenceContext) {
 	localctx = NewReferenceContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 16, rdtParserRULE_reference)
+	p.EnterRule(localctx, 12, rdtParserRULE_reference)
 	var _la int
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(77)
+		p.SetState(63)
 		p.Match(rdtParserIDENTIFIER)
 		if p.HasError() {
 			// Recognition error - abort rule
 			goto errorExit
 		}
 	}
-	p.SetState(80)
+	p.SetState(66)
 	p.GetErrorHandler().Sync(p)
 	if p.HasError() {
 		goto errorExit
@@ -1613,7 +1254,7 @@ func (p *rdtParser) Reference() (localctx IReferenceContext) {
 
 	if _la == rdtParserDOT {
 		{
-			p.SetState(78)
+			p.SetState(64)
 			p.Match(rdtParserDOT)
 			if p.HasError() {
 				// Recognition error - abort rule
@@ -1621,7 +1262,7 @@ func (p *rdtParser) Reference() (localctx IReferenceContext) {
 			}
 		}
 		{
-			p.SetState(79)
+			p.SetState(65)
 			p.Match(rdtParserIDENTIFIER)
 			if p.HasError() {
 				// Recognition error - abort rule
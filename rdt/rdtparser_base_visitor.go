@@ -24,14 +24,6 @@ func (v *BaserdtParserVisitor) VisitPrimitive(ctx *PrimitiveContext) interface{}
 	return v.VisitChildren(ctx)
 }
 
-func (v *BaserdtParserVisitor) VisitOptional(ctx *OptionalContext) interface{} {
-	return v.VisitChildren(ctx)
-}
-
-func (v *BaserdtParserVisitor) VisitArray(ctx *ArrayContext) interface{} {
-	return v.VisitChildren(ctx)
-}
-
 func (v *BaserdtParserVisitor) VisitUnion(ctx *UnionContext) interface{} {
 	return v.VisitChildren(ctx)
 }
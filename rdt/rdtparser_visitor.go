@@ -20,12 +20,6 @@ type rdtParserVisitor interface {
 	// Visit a parse tree produced by rdtParser#primitive.
 	VisitPrimitive(ctx *PrimitiveContext) interface{}
 
-	// Visit a parse tree produced by rdtParser#optional.
-	VisitOptional(ctx *OptionalContext) interface{}
-
-	// Visit a parse tree produced by rdtParser#array.
-	VisitArray(ctx *ArrayContext) interface{}
-
 	// Visit a parse tree produced by rdtParser#union.
 	VisitUnion(ctx *UnionContext) interface{}
 
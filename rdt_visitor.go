@@ -30,10 +30,6 @@ func (visitor *RdtVisitor) Visit(tree antlr.ParseTree, target *UnknownShape) (Sh
 		return visitor.VisitType(t, target)
 	case *rdt.PrimitiveContext:
 		return visitor.VisitPrimitive(t, target)
-	case *rdt.OptionalContext:
-		return visitor.VisitOptional(t, target)
-	case *rdt.ArrayContext:
-		return visitor.VisitArray(t, target)
 	case *rdt.UnionContext:
 		return visitor.VisitUnion(t, target)
 	case *rdt.GroupContext:
@@ -51,7 +47,7 @@ func (visitor *RdtVisitor) VisitChildren(node antlr.RuleNode, target *UnknownSha
 		if _, ok := n.(*antlr.TerminalNodeImpl); ok {
 			continue
 		}
-		baseResolved, implicitAnonShape, _ := visitor.raml.MakeNewShape("", "", target.Location, &target.Position)
+		baseResolved, implicitAnonShape, _ := visitor.raml.MakeNewShape("", "", target.Location(), &target.Position)
 		s, err := visitor.Visit(n.(antlr.ParseTree), implicitAnonShape.(*UnknownShape))
 		if err != nil {
 			return nil, fmt.Errorf("visit children: %w", err)
@@ -71,7 +67,33 @@ func (visitor *RdtVisitor) VisitExpression(ctx *rdt.ExpressionContext, target *U
 }
 
 func (visitor *RdtVisitor) VisitType(ctx *rdt.TypeContext, target *UnknownShape) (Shape, error) {
-	return visitor.Visit(ctx.GetChildren()[0].(antlr.ParseTree), target)
+	children := ctx.GetChildren()
+	// The first child is the operand, the others are postfix notations applied from left to right.
+	notations := make([]string, 0, len(children)-1)
+	for _, n := range children[1:] {
+		notations = append(notations, n.(antlr.TerminalNode).GetText())
+	}
+	return visitor.visitPostfix(children[0].(antlr.ParseTree), notations, target)
+}
+
+// visitPostfix builds the shape of the operand with the postfix notations, e.g. "[]" and "?". The last notation
+// is the outermost shape, so "A[]?" is a union of an array of A and nil.
+func (visitor *RdtVisitor) visitPostfix(operand antlr.ParseTree, notations []string, target *UnknownShape) (Shape,
+	error) {
+	if len(notations) == 0 {
+		return visitor.Visit(operand, target)
+	}
+	last := len(notations) - 1
+	inner := func(innerTarget *UnknownShape) (Shape, error) {
+		return visitor.visitPostfix(operand, notations[:last], innerTarget)
+	}
+	switch notations[last] {
+	case "[]":
+		return visitor.visitArray(inner, target)
+	case "?":
+		return visitor.visitOptional(inner, target)
+	}
+	return nil, fmt.Errorf("unknown postfix notation %q", notations[last])
 }
 
 func (visitor *RdtVisitor) VisitPrimitive(ctx *rdt.PrimitiveContext, target *UnknownShape) (Shape, error) {
@@ -82,10 +104,12 @@ func (visitor *RdtVisitor) VisitPrimitive(ctx *rdt.PrimitiveContext, target *Unk
 	return s, nil
 }
 
-func (visitor *RdtVisitor) VisitOptional(ctx *rdt.OptionalContext, target *UnknownShape) (Shape, error) {
+// visitOptional makes the target a union of the operand and nil.
+func (visitor *RdtVisitor) visitOptional(operand func(*UnknownShape) (Shape, error), target *UnknownShape) (Shape,
+	error) {
 	// Passed target shape becomes anonymous here because union shape takes its place later.
-	baseResolved, anonResolvedShape, _ := visitor.raml.MakeNewShape("", "", target.Location, &target.Position)
-	s, err := visitor.Visit(ctx.GetChildren()[0].(antlr.ParseTree), anonResolvedShape.(*UnknownShape))
+	baseResolved, anonResolvedShape, _ := visitor.raml.MakeNewShape("", "", target.Location(), &target.Position)
+	s, err := operand(anonResolvedShape.(*UnknownShape))
 	if err != nil {
 		return nil, fmt.Errorf("visit: %w", err)
 	}
@@ -93,7 +117,7 @@ func (visitor *RdtVisitor) VisitOptional(ctx *rdt.OptionalContext, target *Unkno
 	baseResolved.SetShape(s)
 
 	// Nil shape is also anonymous here and doesn't share the base shape with the target.
-	baseNil, _, _ := visitor.raml.MakeNewShape("", TypeNil, target.Location, &target.Position)
+	baseNil, _, _ := visitor.raml.MakeNewShape("", TypeNil, target.Location(), &target.Position)
 
 	// We transfer base to new shape
 	// TODO: Need some kind of conversion interface.
@@ -107,10 +131,12 @@ func (visitor *RdtVisitor) VisitOptional(ctx *rdt.OptionalContext, target *Unkno
 	}, nil
 }
 
-func (visitor *RdtVisitor) VisitArray(ctx *rdt.ArrayContext, target *UnknownShape) (Shape, error) {
-	// Passed target shape becomes anonymous here because union shape takes its place later.
-	baseResolved, anonResolvedShape, _ := visitor.raml.MakeNewShape("", "", target.Location, &target.Position)
-	s, err := visitor.Visit(ctx.GetChildren()[0].(antlr.ParseTree), anonResolvedShape.(*UnknownShape))
+// visitArray makes the target an array of the operand.
+func (visitor *RdtVisitor) visitArray(operand func(*UnknownShape) (Shape, error), target *UnknownShape) (Shape,
+	error) {
+	// Passed target shape becomes anonymous here because array shape takes its place later.
+	baseResolved, anonResolvedShape, _ := visitor.raml.MakeNewShape("", "", target.Location(), &target.Position)
+	s, err := operand(anonResolvedShape.(*UnknownShape))
 	if err != nil {
 		return nil, fmt.Errorf("visit: %w", err)
 	}
@@ -136,21 +162,27 @@ func (visitor *RdtVisitor) VisitUnion(ctx *rdt.UnionContext, target *UnknownShap
 	}
 	base := target.Base()
 	base.Type = TypeUnion
-	return &UnionShape{
+	s := &UnionShape{
 		BaseShape: base,
 		UnionFacets: UnionFacets{
 			AnyOf: ss,
 		},
-	}, nil
+	}
+	// Facets of the type expression belong to the union, e.g. enum.
+	if err := s.unmarshalYAMLNodes(target.facets); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml nodes: %w", err)
+	}
+	return s, nil
 }
 
 func (visitor *RdtVisitor) VisitGroup(ctx *rdt.GroupContext, target *UnknownShape) (Shape, error) {
-	return visitor.Visit(ctx.GetChildren()[0].(antlr.ParseTree), target)
+	// Parentheses are terminal nodes around the expression.
+	return visitor.Visit(ctx.Expression(), target)
 }
 
 func (visitor *RdtVisitor) VisitReference(ctx *rdt.ReferenceContext, target *UnknownShape) (Shape, error) {
 	shapeType := ctx.GetText()
-	ref, err := visitor.raml.GetReferencedType(shapeType, target.Location)
+	ref, err := visitor.raml.GetReferencedType(shapeType, target.Location())
 	if err != nil {
 		return nil, fmt.Errorf("get referenced shape: %w", err)
 	}
@@ -160,7 +192,8 @@ func (visitor *RdtVisitor) VisitReference(ctx *rdt.ReferenceContext, target *Unk
 	if errResolveShape := visitor.raml.resolveShape(ref); errResolveShape != nil {
 		return nil, fmt.Errorf("resolve: %w", errResolveShape)
 	}
-	s, err := visitor.raml.MakeConcreteShapeYAML(target.Base(), ref.Type, target.facets)
+	s, err := visitor.raml.MakeConcreteShapeYAML(target.Base(), inheritedType(ref.Type, target.facets),
+		target.facets)
 	if err != nil {
 		return nil, fmt.Errorf("make concrete shape: %w", err)
 	}
@@ -0,0 +1,147 @@
+package raml
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RecursionCycle is a cycle of shapes found while marking recursions of unwrapped shapes.
+type RecursionCycle struct {
+	// Type is the declared type whose shape contains the cycle.
+	Type *BaseShape
+	// Path contains shapes from the declared type to the shape that refers back to the head of the recursion.
+	Path []*BaseShape
+	// Steps contains positions of shapes of Path within their parents in the format of TypeDependency.Path,
+	// e.g. "properties.children" or "items". The last step is the position of the reference to the head.
+	// The first step is the name of the declared type.
+	Steps []string
+	// Start is the index of the head of the recursion in Path.
+	Start int
+}
+
+// Head returns the shape where the recursion begins.
+func (c *RecursionCycle) Head() *BaseShape {
+	return c.Path[c.Start]
+}
+
+// String returns the path of the recursive reference and the path of the head it refers to,
+// e.g. "Node.properties.children.items.properties.children -> Node.properties.children".
+func (c *RecursionCycle) String() string {
+	var path, head string
+	for i, step := range c.Steps {
+		path = joinGraphPath(path, step)
+		if i == c.Start {
+			head = path
+		}
+	}
+	return path + " -> " + head
+}
+
+// recursionStepLabel returns the position of the child within the parent shape in the format of TypeDependency.Path.
+func recursionStepLabel(parent *BaseShape, child *BaseShape) string {
+	switch s := parent.Shape.(type) {
+	case *ArrayShape:
+		return "items"
+	case *ObjectShape:
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value.Shape == child {
+				return "properties." + pair.Key
+			}
+		}
+		for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value.Shape == child {
+				return "properties." + pair.Key
+			}
+		}
+	case *UnionShape:
+		if i := slices.Index(s.AnyOf, child); i >= 0 {
+			return fmt.Sprintf("anyOf[%d]", i)
+		}
+	}
+	return child.Type
+}
+
+// RecursionCycles returns recursion cycles found during unwrapping in the order of discovery.
+// A cycle that is reachable from several declared types is reported once.
+func (r *RAML) RecursionCycles() []*RecursionCycle {
+	return r.recursionCycles
+}
+
+// addRecursionCycle records the cycle that starts at the head on the stack of shapes being marked.
+func (r *RAML) addRecursionCycle(head *BaseShape) {
+	start := slices.Index(r.recursionStack, head)
+	if start < 0 {
+		return
+	}
+	key := recursionCycleKey(r.recursionStack[start:])
+	if _, ok := r.recursionCycleKeys[key]; ok {
+		return
+	}
+	if r.recursionCycleKeys == nil {
+		r.recursionCycleKeys = make(map[string]struct{})
+	}
+	r.recursionCycleKeys[key] = struct{}{}
+	// Labels are resolved now because marking replaces references to heads with recursive shapes.
+	steps := []string{r.recursionStack[0].Name}
+	for i := 1; i < len(r.recursionStack); i++ {
+		steps = append(steps, recursionStepLabel(r.recursionStack[i-1], r.recursionStack[i]))
+	}
+	steps = append(steps, recursionStepLabel(r.recursionStack[len(r.recursionStack)-1], head))
+	r.recursionCycles = append(r.recursionCycles, &RecursionCycle{
+		Type:  r.recursionStack[0],
+		Path:  slices.Clone(r.recursionStack),
+		Steps: steps,
+		Start: start,
+	})
+}
+
+// recursionCycleKey identifies the cycle regardless of the shape it was entered from.
+func recursionCycleKey(cycle []*BaseShape) string {
+	first := 0
+	for i, s := range cycle {
+		if s.ID < cycle[first].ID {
+			first = i
+		}
+	}
+	var sb strings.Builder
+	for i := range cycle {
+		fmt.Fprintf(&sb, "%d,", cycle[(first+i)%len(cycle)].ID)
+	}
+	return sb.String()
+}
+
+// resetRecursionCycles drops recursion cycles before the shapes are marked again.
+func (r *RAML) resetRecursionCycles() {
+	r.recursionCycles = nil
+	r.recursionCycleKeys = nil
+	r.recursionStack = nil
+}
+
+type parseOptWithMaxRecursionDepth struct {
+	depth int
+}
+
+func (o parseOptWithMaxRecursionDepth) Apply(opt *parserOptions) {
+	opt.maxRecursionDepth = o.depth
+}
+
+// OptWithMaxRecursionDepth limits the depth of values that are validated against recursive shapes.
+// The depth is the number of recursive references followed from the root of the validated value, so values of
+// non-recursive shapes are not limited.
+// Deeper values are rejected instead of being validated, so crafted payloads cannot exhaust the stack.
+// Zero means no limit.
+func OptWithMaxRecursionDepth(depth int) ParseOpt {
+	return parseOptWithMaxRecursionDepth{depth: depth}
+}
+
+// checkRecursionDepth returns an error if the value at the path is nested deeper than the configured limit.
+func (r *RAML) checkRecursionDepth(path fmt.Stringer, depth int) error {
+	if r == nil || r.maxRecursionDepth <= 0 {
+		return nil
+	}
+	if depth > r.maxRecursionDepth {
+		return fmt.Errorf("maximum recursion depth %d exceeded at %s", r.maxRecursionDepth, path)
+	}
+	return nil
+}
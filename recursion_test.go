@@ -0,0 +1,120 @@
+package raml
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const recursionLibrary = `#%RAML 1.0 Library
+types:
+  Node:
+    type: object
+    properties:
+      value: string
+      children?: Node[]
+  Folder:
+    type: object
+    properties:
+      files?: File[]
+  File:
+    type: object
+    properties:
+      parent?: Folder
+  Flat:
+    type: object
+    properties:
+      name: string
+`
+
+func TestRAML_RecursionCycles(t *testing.T) {
+	rml, err := ParseFromString(recursionLibrary, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+
+	cycles := rml.RecursionCycles()
+	require.Len(t, cycles, 2)
+
+	require.Equal(t, "Node", cycles[0].Type.Name)
+	require.Equal(t, "Node.properties.children.items.properties.children -> Node.properties.children",
+		cycles[0].String())
+	require.Equal(t, 7, cycles[0].Head().Position.Line)
+
+	require.Equal(t, "Folder", cycles[1].Type.Name)
+	require.Equal(t, "Folder.properties.files.items.properties.parent.properties.files -> Folder.properties.files",
+		cycles[1].String())
+	require.Contains(t, cycles[1].Head().Location(), "library.raml")
+
+	rml, err = ParseFromString(recursionLibrary, "library.raml", t.TempDir())
+	require.NoError(t, err)
+	require.Empty(t, rml.RecursionCycles())
+}
+
+func TestOptWithMaxRecursionDepth(t *testing.T) {
+	nested := func(depth int) map[string]any {
+		v := map[string]any{"value": "leaf"}
+		for range depth {
+			v = map[string]any{"value": "node", "children": []any{v}}
+		}
+		return v
+	}
+
+	rml, err := ParseFromString(recursionLibrary, "library.raml", t.TempDir(), OptWithUnwrap(),
+		OptWithMaxRecursionDepth(5))
+	require.NoError(t, err)
+	node, _ := rml.EntryPoint().(*Library).Types.Get("Node")
+	require.NoError(t, node.Validate(nested(5)))
+	require.ErrorContains(t, node.Validate(nested(6)), "maximum recursion depth 5 exceeded at $.children[0]")
+
+	// Only recursive references count, whatever the path of the value looks like.
+	rml, err = ParseFromString(`#%RAML 1.0 Library
+types:
+  Tree:
+    properties:
+      a.b.c?: Tree
+      items?:
+        properties:
+          list: string[]
+`, "library.raml", t.TempDir(), OptWithUnwrap(), OptWithMaxRecursionDepth(1))
+	require.NoError(t, err)
+	tree, _ := rml.EntryPoint().(*Library).Types.Get("Tree")
+	require.NoError(t, tree.Validate(map[string]any{"a.b.c": map[string]any{
+		"items": map[string]any{"list": []any{"x"}}}}))
+	require.ErrorContains(t, tree.Validate(map[string]any{"a.b.c": map[string]any{"a.b.c": map[string]any{}}}),
+		"maximum recursion depth 1 exceeded")
+
+	rml, err = ParseFromString(recursionLibrary, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	node, _ = rml.EntryPoint().(*Library).Types.Get("Node")
+	require.NoError(t, node.Validate(nested(100)))
+}
+
+func TestRAML_ReferenceCycles(t *testing.T) {
+	dir, err := filepath.Abs("./fixtures/cycles")
+	require.NoError(t, err)
+
+	// Fragments may include or use each other back as long as the cycle goes through properties or items.
+	rml, err := ParseFromPath(filepath.Join(dir, "node.raml"), OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	node := rml.EntryPoint().(*DataType).Shape
+	require.NoError(t, node.Validate(map[string]any{"value": "root", "children": []any{
+		map[string]any{"value": "leaf"},
+	}}))
+	require.Error(t, node.Validate(map[string]any{"value": "root", "children": []any{map[string]any{}}}))
+
+	rml, err = ParseFromPath(filepath.Join(dir, "lib_a.raml"), OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	a, err := rml.LookupType("A", "")
+	require.NoError(t, err)
+	require.NoError(t, a.Validate(map[string]any{"b": map[string]any{"a": map[string]any{}}}))
+
+	// Cycles of aliases cannot be resolved and are reported with the whole chain.
+	_, err = ParseFromString("#%RAML 1.0 Library\ntypes:\n  A: B\n  B: C\n  C: A\n", "library.raml", dir)
+	require.ErrorContains(t, err, "cyclic reference: A ("+filepath.Join(dir, "library.raml")+":3) -> B (")
+	require.ErrorContains(t, err, ":5) -> A (")
+
+	_, err = ParseFromPath(filepath.Join(dir, "alias_a.raml"))
+	require.ErrorContains(t, err, "cyclic reference: ")
+	require.ErrorContains(t, err, "alias_a.raml ("+filepath.Join(dir, "alias_a.raml")+")")
+	require.ErrorContains(t, err, "alias_b.raml ("+filepath.Join(dir, "alias_b.raml")+")")
+}
@@ -0,0 +1,409 @@
+package refactor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/acronis/go-raml"
+)
+
+// ExtractType lifts the inline type declaration at path in the fragment at location into the declaration with
+// the name in the library at targetLibrary and replaces the inline declaration with a reference to it. The path is
+// written like a JSON path of the fragment document, e.g. "$.types.Pet.properties.owner" or "$.types.Pets.items",
+// keys with dots can be quoted like "$['types']['a.b']". The target library must be the
+// fragment itself or be used by it, type references of the extracted declaration are rewritten to be resolved from
+// the library. The entry point is used if the location is empty.
+// Returns new contents of the changed files by their locations, files are not written.
+func ExtractType(
+	rml *raml.RAML, location string, path string, targetLibrary string, name string,
+) (map[string][]byte, error) {
+	if !identifier.MatchString(name) {
+		return nil, fmt.Errorf("invalid type name %q", name)
+	}
+	if location == "" {
+		if rml.EntryPoint() == nil {
+			return nil, fmt.Errorf("entry point is not set")
+		}
+		location = rml.EntryPoint().GetLocation()
+	}
+	if rml.GetFragment(location) == nil {
+		return nil, fmt.Errorf("fragment %s not found", location)
+	}
+	lib, ok := rml.GetFragment(targetLibrary).(*raml.Library)
+	if !ok {
+		return nil, fmt.Errorf("library %s not found", targetLibrary)
+	}
+	if _, ok = lib.Types.Get(name); ok {
+		return nil, fmt.Errorf("type %s is already declared in %s", name, targetLibrary)
+	}
+	ref := name
+	if location != targetLibrary {
+		alias := usedAlias(rml, location, targetLibrary)
+		if alias == "" {
+			return nil, fmt.Errorf("library %s is not used by %s", targetLibrary, location)
+		}
+		ref = alias + "." + name
+	}
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if !isTypePosition(segments) {
+		return nil, fmt.Errorf("%s is not an inline type declaration", path)
+	}
+
+	content, doc, err := readDocument(location)
+	if err != nil {
+		return nil, err
+	}
+	node, parent, err := lookupPath(doc, segments)
+	if err != nil {
+		return nil, fmt.Errorf("lookup %s: %w", path, err)
+	}
+	if node.Kind != yaml.MappingNode || node.Style&yaml.FlowStyle != 0 || parent.Style&yaml.FlowStyle != 0 {
+		return nil, fmt.Errorf("%s is not an inline type declaration in block style", path)
+	}
+	if hasInclude(node) && filepath.Dir(location) != filepath.Dir(targetLibrary) {
+		return nil, fmt.Errorf("%s includes files relative to %s", path, filepath.Dir(location))
+	}
+	source, err := replaceDeclaration(content, doc, node, parent, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// References of the extracted declaration must be resolved from the target library.
+	var refErr error
+	r := renamer{ref: func(ref string) (string, bool) {
+		shape, err := rml.LookupType(ref, location)
+		if err != nil {
+			// Built-in types and references that are not declared are kept.
+			return "", false
+		}
+		newRef := shape.Name
+		if shape.Location() != targetLibrary {
+			alias := usedAlias(rml, targetLibrary, shape.Location())
+			if alias == "" {
+				refErr = fmt.Errorf("type %s is not accessible from %s", ref, targetLibrary)
+				return "", false
+			}
+			newRef = alias + "." + shape.Name
+		}
+		return newRef, newRef != ref
+	}}
+	r.typeDeclaration(node)
+	if refErr != nil {
+		return nil, refErr
+	}
+	for _, e := range r.edits {
+		e.node.Value = e.value
+	}
+
+	result := make(map[string][]byte)
+	if location == targetLibrary {
+		result[location], err = insertDeclaration(content, doc, []replacement{source}, name, node)
+	} else {
+		result[location] = applyReplacements(content, []replacement{source})
+		var libContent []byte
+		var libDoc *yaml.Node
+		libContent, libDoc, err = readDocument(targetLibrary)
+		if err != nil {
+			return nil, err
+		}
+		result[targetLibrary], err = insertDeclaration(libContent, libDoc, nil, name, node)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// usedAlias returns the alias of the library at libLocation in uses of the fragment at location, empty if the
+// library is not used.
+func usedAlias(rml *raml.RAML, location string, libLocation string) string {
+	uses := rml.Uses(location)
+	if uses == nil {
+		return ""
+	}
+	for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Value.Link != nil && pair.Value.Link.Location == libLocation {
+			return pair.Key
+		}
+	}
+	return ""
+}
+
+// readDocument reads the fragment at location and returns its content and the root mapping of the document.
+func readDocument(location string) ([]byte, *yaml.Node, error) {
+	content, err := os.ReadFile(location)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read file: %w", err)
+	}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(content, &doc); err != nil {
+		return nil, nil, fmt.Errorf("decode file %s: %w", location, err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("fragment %s is not a mapping", location)
+	}
+	return content, doc.Content[0], nil
+}
+
+// pathSegment is either a key of a mapping or an index of a sequence.
+type pathSegment struct {
+	key   string
+	index int
+}
+
+// parsePath parses the path like "$.types.Pet['a.b'][0]".
+func parsePath(path string) ([]pathSegment, error) {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil, fmt.Errorf("path %q must start with $", path)
+	}
+	var segments []pathSegment
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("path %q has an empty key", path)
+			}
+			segments = append(segments, pathSegment{key: rest[1:end], index: -1})
+			rest = rest[end:]
+		case strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest, "']")
+			if end < 0 {
+				return nil, fmt.Errorf("path %q has an unterminated key", path)
+			}
+			segments = append(segments, pathSegment{key: rest[2:end], index: -1})
+			rest = rest[end+2:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q has an unterminated index", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("path %q has an invalid index %q", path, rest[1:end])
+			}
+			segments = append(segments, pathSegment{index: index})
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("path %q has an unexpected character at %q", path, rest)
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("path %q points to the fragment", path)
+	}
+	return segments, nil
+}
+
+// isTypePosition reports whether the path points to a type declaration: a declaration of types or properties or
+// a value of a facet that is a type.
+func isTypePosition(segments []pathSegment) bool {
+	last := segments[len(segments)-1]
+	switch last.key {
+	case "type", "items", "schema":
+		return true
+	}
+	if len(segments) < 2 || last.index >= 0 {
+		return false
+	}
+	switch segments[len(segments)-2].key {
+	case "types", "schemas", "properties", "facets":
+		return true
+	}
+	return false
+}
+
+// lookupPath returns the node at the path and its parent.
+func lookupPath(root *yaml.Node, segments []pathSegment) (node *yaml.Node, parent *yaml.Node, err error) {
+	node = root
+	for _, seg := range segments {
+		parent = node
+		node = nil
+		switch {
+		case seg.index < 0 && parent.Kind == yaml.MappingNode:
+			for i := 0; i+1 < len(parent.Content); i += 2 {
+				if parent.Content[i].Value == seg.key {
+					node = parent.Content[i+1]
+					break
+				}
+			}
+			if node == nil {
+				return nil, nil, fmt.Errorf("key %q not found", seg.key)
+			}
+		case seg.index >= 0 && parent.Kind == yaml.SequenceNode:
+			if seg.index >= len(parent.Content) {
+				return nil, nil, fmt.Errorf("index %d is out of range", seg.index)
+			}
+			node = parent.Content[seg.index]
+		default:
+			return nil, nil, fmt.Errorf("%d:%d: unexpected node", parent.Line, parent.Column)
+		}
+	}
+	return node, parent, nil
+}
+
+func hasInclude(node *yaml.Node) bool {
+	if node.Tag == "!include" {
+		return true
+	}
+	for _, n := range node.Content {
+		if hasInclude(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceDeclaration returns the replacement of the block node in the parent with the reference.
+func replaceDeclaration(content []byte, doc, node, parent *yaml.Node, ref string) (replacement, error) {
+	l := newLines(content)
+	start, err := l.offset(node.Line, node.Column)
+	if err != nil {
+		return replacement{}, err
+	}
+	end, err := blockEnd(l, doc, node)
+	if err != nil {
+		return replacement{}, err
+	}
+	// The value of a mapping starts after the colon of the key.
+	var key *yaml.Node
+	for i := 1; i < len(parent.Content); i += 2 {
+		if parent.Content[i] == node {
+			key = parent.Content[i-1]
+		}
+	}
+	keyStart, err := l.offset(key.Line, key.Column)
+	if err != nil {
+		return replacement{}, err
+	}
+	colon := bytes.IndexByte(content[keyStart:start], ':')
+	if colon < 0 {
+		return replacement{}, fmt.Errorf("%d:%d: cannot find the value of the key %q", key.Line, key.Column, key.Value)
+	}
+	return replacement{start: keyStart + colon + 1, end: end, text: " " + ref}, nil
+}
+
+// blockEnd returns the offset of the end of the last line of the block node, skipping trailing empty and comment
+// lines that belong to the next node.
+func blockEnd(l lines, doc, node *yaml.Node) (int, error) {
+	var nodes []*yaml.Node
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		nodes = append(nodes, n)
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(doc)
+	// Descendants follow the node in the document order, the next node is the first one after them.
+	var next *yaml.Node
+	for i, n := range nodes {
+		if n == node {
+			if j := i + countNodes(node); j < len(nodes) {
+				next = nodes[j]
+			}
+			break
+		}
+	}
+	line := len(l.starts) + 1
+	if next != nil {
+		if next.Line <= node.Line {
+			return 0, fmt.Errorf("%d:%d: node is not in block style", node.Line, node.Column)
+		}
+		line = next.Line
+	}
+	// Step back over empty and comment lines.
+	for line-1 > node.Line {
+		start := l.starts[line-2]
+		end := len(l.content)
+		if line-1 < len(l.starts) {
+			end = l.starts[line-1]
+		}
+		text := strings.TrimSpace(string(l.content[start:end]))
+		if text != "" && !strings.HasPrefix(text, "#") {
+			break
+		}
+		line--
+	}
+	end := len(l.content)
+	if line-1 < len(l.starts) {
+		end = l.starts[line-1]
+	}
+	return len(bytes.TrimRight(l.content[:end], "\r\n")), nil
+}
+
+func countNodes(node *yaml.Node) int {
+	count := 1
+	for _, c := range node.Content {
+		count += countNodes(c)
+	}
+	return count
+}
+
+// insertDeclaration applies the replacements to the library content and appends the declaration of the node with
+// the name to its types.
+func insertDeclaration(
+	content []byte, doc *yaml.Node, replacements []replacement, name string, node *yaml.Node,
+) ([]byte, error) {
+	var types *yaml.Node
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == "types" {
+			types = doc.Content[i+1]
+		}
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, fmt.Errorf("encode declaration: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode declaration: %w", err)
+	}
+	declaration := func(indent int) string {
+		var sb strings.Builder
+		sb.WriteString(strings.Repeat(" ", indent) + name + ":\n")
+		for _, line := range strings.SplitAfter(strings.TrimRight(buf.String(), "\n"), "\n") {
+			if strings.TrimSpace(line) != "" {
+				sb.WriteString(strings.Repeat(" ", 2*indent))
+			}
+			sb.WriteString(line)
+		}
+		return sb.String()
+	}
+
+	l := newLines(content)
+	switch {
+	case types == nil:
+		prefix := ""
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			prefix = "\n"
+		}
+		replacements = append(replacements, replacement{
+			start: len(content), end: len(content), text: prefix + "types:\n" + declaration(2) + "\n",
+		})
+	case types.Kind == yaml.MappingNode && types.Style&yaml.FlowStyle == 0 && len(types.Content) > 0:
+		end, err := blockEnd(l, doc, types)
+		if err != nil {
+			return nil, err
+		}
+		replacements = append(replacements, replacement{
+			start: end, end: end, text: "\n" + declaration(types.Content[0].Column-1),
+		})
+	default:
+		return nil, fmt.Errorf("%d:%d: types are not a mapping in block style", types.Line, types.Column)
+	}
+	return applyReplacements(content, replacements), nil
+}
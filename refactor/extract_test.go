@@ -0,0 +1,139 @@
+package refactor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-raml"
+)
+
+func TestExtractType(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"common.raml": `#%RAML 1.0 Library
+uses:
+  base: base.raml
+types:
+  Id: string
+`,
+		"base.raml": `#%RAML 1.0 Library
+types:
+  Named:
+    properties:
+      name: string
+`,
+		"main.raml": `#%RAML 1.0 Library
+uses:
+  c: common.raml
+  b: base.raml
+types:
+  Pet:
+    properties:
+      owner:
+        # the owner
+        type: b.Named
+        properties:
+          id: c.Id
+          age?: integer
+
+      kind: string
+  Local: string
+`,
+	})
+	mainLocation := filepath.Join(dir, "main.raml")
+	commonLocation := filepath.Join(dir, "common.raml")
+
+	rml, err := raml.ParseFromPath(mainLocation)
+	require.NoError(t, err)
+	_, err = ExtractType(rml, "", "$.types.Pet.properties.kind", commonLocation, "Kind")
+	require.ErrorContains(t, err, "is not an inline type declaration")
+	_, err = ExtractType(rml, "", "$.types.Pet.properties.owner", commonLocation, "Id")
+	require.ErrorContains(t, err, "already declared")
+	_, err = ExtractType(rml, "", "types.Pet", commonLocation, "Owner")
+	require.ErrorContains(t, err, "must start with $")
+	_, err = ExtractType(rml, "", "$.types.Pet.properties.owner", filepath.Join(dir, "base.raml"), "Owner")
+	require.ErrorContains(t, err, "c.Id is not accessible")
+
+	files, err := ExtractType(rml, "", "$.types.Pet.properties.owner", commonLocation, "Owner")
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, `#%RAML 1.0 Library
+uses:
+  c: common.raml
+  b: base.raml
+types:
+  Pet:
+    properties:
+      owner: c.Owner
+
+      kind: string
+  Local: string
+`, string(files[mainLocation]))
+	require.Equal(t, `#%RAML 1.0 Library
+uses:
+  base: base.raml
+types:
+  Id: string
+  Owner:
+    # the owner
+    type: base.Named
+    properties:
+      id: Id
+      age?: integer
+`, string(files[commonLocation]))
+
+	for location, content := range files {
+		require.NoError(t, os.WriteFile(location, content, 0o600))
+	}
+	rml, err = raml.ParseFromPath(mainLocation)
+	require.NoError(t, err)
+	pet, err := rml.LookupType("Pet", "")
+	require.NoError(t, err)
+	owner, err := rml.LookupType("c.Owner", "")
+	require.NoError(t, err)
+	prop, ok := pet.Shape.(*raml.ObjectShape).Properties.Get("owner")
+	require.True(t, ok)
+	require.Same(t, owner, prop.Shape.Alias)
+
+	files, err = ExtractType(rml, "", "$['types'].Pet.properties", mainLocation, "PetProperties")
+	require.ErrorContains(t, err, "is not an inline type declaration")
+	require.Nil(t, files)
+}
+
+func TestExtractType_SameLibrary(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.raml": `#%RAML 1.0 Library
+types:
+  Base: object
+  Pets:
+    type: array
+    items:
+      type: Base
+      properties:
+        id: string
+    minItems: 1
+`,
+	})
+	mainLocation := filepath.Join(dir, "main.raml")
+	rml, err := raml.ParseFromPath(mainLocation)
+	require.NoError(t, err)
+
+	files, err := ExtractType(rml, mainLocation, "$.types.Pets.items", mainLocation, "WithId")
+	require.NoError(t, err)
+	require.Equal(t, `#%RAML 1.0 Library
+types:
+  Base: object
+  Pets:
+    type: array
+    items: WithId
+    minItems: 1
+  WithId:
+    type: Base
+    properties:
+      id: string
+`, string(files[mainLocation]))
+}
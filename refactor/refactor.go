@@ -0,0 +1,296 @@
+// Package refactor implements refactorings of RAML specifications that edit the source files while keeping their
+// formatting and comments.
+package refactor
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/antlr4-go/antlr/v4"
+	"gopkg.in/yaml.v3"
+
+	"github.com/acronis/go-raml"
+	"github.com/acronis/go-raml/rdt"
+)
+
+var identifier = regexp.MustCompile(`^[0-9A-Za-z_-]+$`)
+
+// RenameType renames the type declared in a library and updates all references to it in the fragments of the RAML:
+// type expressions of declarations, properties, items and facets, including library-qualified references, union
+// members and parents of multiple inheritance. oldName is resolved like a type expression in the entry point, e.g.
+// "Pet" or "common.Pet", while newName is the new name of the declaration without a library alias.
+// Returns new contents of the changed files by their locations, files are not written.
+func RenameType(rml *raml.RAML, oldName string, newName string) (map[string][]byte, error) {
+	if !identifier.MatchString(newName) {
+		return nil, fmt.Errorf("invalid type name %q", newName)
+	}
+	shape, err := rml.LookupType(oldName, "")
+	if err != nil {
+		return nil, err
+	}
+	declLocation := shape.Location()
+	lib, ok := rml.GetFragment(declLocation).(*raml.Library)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not declared in a library", oldName)
+	}
+	if declared, ok := lib.Types.Get(shape.Name); !ok || declared != shape {
+		return nil, fmt.Errorf("type %s is not declared in a library", oldName)
+	}
+	if _, ok = lib.Types.Get(newName); ok {
+		return nil, fmt.Errorf("type %s is already declared in %s", newName, declLocation)
+	}
+
+	result := make(map[string][]byte)
+	for _, location := range rml.Locations() {
+		frag := rml.GetFragment(location)
+		if frag == nil {
+			continue
+		}
+		// References to the type as they are written in the fragment mapped to their new text.
+		refs := make(map[string]string)
+		if location == declLocation {
+			refs[shape.Name] = newName
+		}
+		if uses := rml.Uses(location); uses != nil {
+			for pair := uses.Oldest(); pair != nil; pair = pair.Next() {
+				if pair.Value.Link != nil && pair.Value.Link.Location == declLocation {
+					refs[pair.Key+"."+shape.Name] = pair.Key + "." + newName
+				}
+			}
+		}
+		if len(refs) == 0 {
+			continue
+		}
+		r := renamer{ref: func(ref string) (string, bool) {
+			newRef, ok := refs[ref]
+			return newRef, ok
+		}}
+		if location == declLocation {
+			r.declared, r.newName = shape.Name, newName
+		}
+		content, changed, err := r.rename(location, frag)
+		if err != nil {
+			return nil, fmt.Errorf("rename in %s: %w", location, err)
+		}
+		if changed {
+			result[location] = content
+		}
+	}
+	return result, nil
+}
+
+// renamer collects edits of references in a fragment.
+type renamer struct {
+	// ref returns the new text of the reference, false if the reference is kept.
+	ref func(ref string) (string, bool)
+	// declared is the name of the declaration to rename in the fragment, empty if it is declared elsewhere.
+	declared string
+	newName  string
+	edits    []edit
+}
+
+// edit replaces the text of the scalar node with the value.
+type edit struct {
+	node  *yaml.Node
+	value string
+}
+
+func (r *renamer) rename(location string, frag raml.Fragment) ([]byte, bool, error) {
+	content, root, err := readDocument(location)
+	if err != nil {
+		return nil, false, err
+	}
+	switch frag.(type) {
+	case *raml.Library:
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			switch key := root.Content[i].Value; key {
+			case "types", "schemas", "annotationTypes":
+				r.declarations(root.Content[i+1], key != "annotationTypes")
+			}
+		}
+	case *raml.DataType:
+		r.typeDeclaration(root)
+	}
+	if len(r.edits) == 0 {
+		return content, false, nil
+	}
+	content, err = applyEdits(content, r.edits)
+	if err != nil {
+		return nil, false, err
+	}
+	return content, true, nil
+}
+
+// declarations collects edits in the mapping of declarations. Keys are renamed if the mapping declares types.
+func (r *renamer) declarations(node *yaml.Node, types bool) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i]; types && r.declared != "" && key.Value == r.declared {
+			r.edits = append(r.edits, edit{node: key, value: r.newName})
+		}
+		r.typeDeclaration(node.Content[i+1])
+	}
+}
+
+// typeDeclaration collects edits in the type declaration that is either a type expression, a list of parents or
+// a map of facets.
+func (r *renamer) typeDeclaration(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag != "!!str" {
+			return
+		}
+		if value, ok := r.expression(node.Value); ok {
+			r.edits = append(r.edits, edit{node: node, value: value})
+		}
+	case yaml.SequenceNode:
+		for _, n := range node.Content {
+			r.typeDeclaration(n)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			switch node.Content[i].Value {
+			case "type", "schema", "items":
+				r.typeDeclaration(node.Content[i+1])
+			case "properties", "facets":
+				r.declarations(node.Content[i+1], false)
+			}
+		}
+	}
+}
+
+// expression returns the type expression with references replaced, false if there are no references to replace
+// or the value is not a type expression.
+func (r *renamer) expression(expr string) (string, bool) {
+	lexer := rdt.NewrdtLexer(antlr.NewInputStream(expr))
+	lexer.RemoveErrorListeners()
+	parser := rdt.NewrdtParser(antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel))
+	parser.RemoveErrorListeners()
+	errs := &errorCounter{}
+	lexer.AddErrorListener(errs)
+	parser.AddErrorListener(errs)
+	tree := parser.Entrypoint()
+	if errs.count > 0 {
+		return "", false
+	}
+	var refs []*rdt.ReferenceContext
+	collectReferences(tree, &refs)
+	// Character indexes of the stream are rune indexes.
+	runes := []rune(expr)
+	changed := false
+	for i := len(refs) - 1; i >= 0; i-- {
+		ref := refs[i]
+		newRef, ok := r.ref(ref.GetText())
+		if !ok {
+			continue
+		}
+		start, stop := ref.GetStart().GetStart(), ref.GetStop().GetStop()
+		runes = append(runes[:start], append([]rune(newRef), runes[stop+1:]...)...)
+		changed = true
+	}
+	return string(runes), changed
+}
+
+func collectReferences(tree antlr.Tree, refs *[]*rdt.ReferenceContext) {
+	if ref, ok := tree.(*rdt.ReferenceContext); ok {
+		*refs = append(*refs, ref)
+		return
+	}
+	for _, child := range tree.GetChildren() {
+		collectReferences(child, refs)
+	}
+}
+
+type errorCounter struct {
+	*antlr.DefaultErrorListener
+	count int
+}
+
+func (e *errorCounter) SyntaxError(antlr.Recognizer, interface{}, int, int, string, antlr.RecognitionException) {
+	e.count++
+}
+
+// replacement replaces the bytes between start and end with the text.
+type replacement struct {
+	start, end int
+	text       string
+}
+
+// applyReplacements applies non-overlapping replacements to the copy of the content.
+func applyReplacements(content []byte, replacements []replacement) []byte {
+	sort.Slice(replacements, func(i, j int) bool { return replacements[i].start > replacements[j].start })
+	result := bytes.Clone(content)
+	for _, r := range replacements {
+		result = append(result[:r.start], append([]byte(r.text), result[r.end:]...)...)
+	}
+	return result
+}
+
+// lines maps positions of YAML nodes to byte offsets of the content.
+type lines struct {
+	content []byte
+	starts  []int
+}
+
+func newLines(content []byte) lines {
+	starts := []int{0}
+	for i, c := range content {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return lines{content: content, starts: starts}
+}
+
+// offset returns the byte offset of the line and the column, both 1-based. Columns count runes.
+func (l lines) offset(line, column int) (int, error) {
+	if line < 1 || line > len(l.starts) {
+		return 0, fmt.Errorf("line %d is out of the file", line)
+	}
+	start := l.starts[line-1]
+	for col := 1; col < column; col++ {
+		if start >= len(l.content) || l.content[start] == '\n' {
+			return 0, fmt.Errorf("column %d is out of the line %d", column, line)
+		}
+		_, size := utf8.DecodeRune(l.content[start:])
+		start += size
+	}
+	return start, nil
+}
+
+// applyEdits replaces texts of scalar nodes in the content. Only single-line plain and quoted scalars without escape
+// sequences can be edited.
+func applyEdits(content []byte, edits []edit) ([]byte, error) {
+	l := newLines(content)
+	replacements := make([]replacement, 0, len(edits))
+	for _, e := range edits {
+		n := e.node
+		start, err := l.offset(n.Line, n.Column)
+		if err != nil {
+			return nil, err
+		}
+		var raw string
+		switch n.Style {
+		case 0:
+			raw = n.Value
+		case yaml.SingleQuotedStyle:
+			raw = "'" + n.Value + "'"
+			e.value = "'" + e.value + "'"
+		case yaml.DoubleQuotedStyle:
+			raw = `"` + n.Value + `"`
+			e.value = `"` + e.value + `"`
+		default:
+			return nil, fmt.Errorf("%d:%d: cannot edit scalar of style %v", n.Line, n.Column, n.Style)
+		}
+		if !bytes.HasPrefix(content[start:], []byte(raw)) {
+			return nil, fmt.Errorf("%d:%d: cannot edit scalar %q", n.Line, n.Column, n.Value)
+		}
+		replacements = append(replacements, replacement{start: start, end: start + len(raw), text: e.value})
+	}
+	return applyReplacements(content, replacements), nil
+}
@@ -0,0 +1,115 @@
+package refactor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/acronis/go-raml"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+}
+
+func TestRenameType(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"common.raml": `#%RAML 1.0 Library
+types:
+  Pet: # a pet
+    type: object
+    properties:
+      name: string
+  Pets: Pet[]
+  PetOrString: Pet | string
+  PetName: string
+`,
+		"cat.raml": `#%RAML 1.0 DataType
+uses:
+  c: common.raml
+type: c.Pet
+properties:
+  friend?: c.Pet
+`,
+		"main.raml": `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Base:
+    type: object
+  Cat: !include cat.raml
+  Dog:
+    type: [common.Pet, Base]
+    properties:
+      owner: "common.Pet"
+      pets:
+        type: array
+        items: common.Pet
+      other: common.Pet[] | string
+      petName: common.PetName
+`,
+	})
+
+	rml, err := raml.ParseFromPath(filepath.Join(dir, "main.raml"))
+	require.NoError(t, err)
+	_, err = RenameType(rml, "common.Pet", "Base")
+	require.NoError(t, err)
+	_, err = RenameType(rml, "common.Pet", "Pets")
+	require.ErrorContains(t, err, "already declared")
+	_, err = RenameType(rml, "common.Pet", "common.Animal")
+	require.ErrorContains(t, err, "invalid type name")
+
+	files, err := RenameType(rml, "common.Pet", "Animal")
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	require.Equal(t, `#%RAML 1.0 Library
+types:
+  Animal: # a pet
+    type: object
+    properties:
+      name: string
+  Pets: Animal[]
+  PetOrString: Animal | string
+  PetName: string
+`, string(files[filepath.Join(dir, "common.raml")]))
+	require.Equal(t, `#%RAML 1.0 DataType
+uses:
+  c: common.raml
+type: c.Animal
+properties:
+  friend?: c.Animal
+`, string(files[filepath.Join(dir, "cat.raml")]))
+	require.Equal(t, `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Base:
+    type: object
+  Cat: !include cat.raml
+  Dog:
+    type: [common.Animal, Base]
+    properties:
+      owner: "common.Animal"
+      pets:
+        type: array
+        items: common.Animal
+      other: common.Animal[] | string
+      petName: common.PetName
+`, string(files[filepath.Join(dir, "main.raml")]))
+
+	for location, content := range files {
+		require.NoError(t, os.WriteFile(location, content, 0o600))
+	}
+	rml, err = raml.ParseFromPath(filepath.Join(dir, "main.raml"))
+	require.NoError(t, err)
+	_, err = rml.LookupType("common.Animal", "")
+	require.NoError(t, err)
+	_, err = rml.LookupType("common.Pet", "")
+	require.Error(t, err)
+}
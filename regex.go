@@ -0,0 +1,164 @@
+package raml
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/acronis/go-stacktrace"
+	"github.com/dlclark/regexp2"
+	"gopkg.in/yaml.v3"
+)
+
+// Regexp is a compiled regular expression of the pattern facet or of a pattern property.
+type Regexp interface {
+	// MatchString reports whether the string contains any match of the regular expression.
+	MatchString(s string) bool
+	// String returns the source text of the regular expression.
+	String() string
+}
+
+// RegexEngine compiles regular expressions of the pattern facet and of pattern properties.
+// RAML patterns are ECMA-262 regular expressions, while the default engine (see RE2Engine) supports only
+// the RE2 syntax that lacks lookarounds and backreferences.
+type RegexEngine interface {
+	Compile(pattern string) (Regexp, error)
+}
+
+// RE2Engine compiles regular expressions with the regexp package. This is the default engine.
+type RE2Engine struct{}
+
+// Compile compiles the pattern with regexp.Compile.
+func (RE2Engine) Compile(pattern string) (Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re, nil
+}
+
+// ECMAEngine compiles regular expressions with ECMAScript compatible syntax and semantics including
+// lookarounds and backreferences. Unlike RE2, matching is backtracking, so a timeout is recommended for
+// untrusted patterns or data.
+type ECMAEngine struct {
+	// MatchTimeout limits the duration of a single match, the string is considered not matching on timeout.
+	// Zero means no timeout.
+	MatchTimeout time.Duration
+}
+
+// Compile compiles the pattern with ECMAScript options.
+func (e ECMAEngine) Compile(pattern string) (Regexp, error) {
+	re, err := regexp2.Compile(pattern, regexp2.ECMAScript)
+	if err != nil {
+		return nil, err
+	}
+	if e.MatchTimeout > 0 {
+		re.MatchTimeout = e.MatchTimeout
+	}
+	return ecmaRegexp{re: re}, nil
+}
+
+type ecmaRegexp struct {
+	re *regexp2.Regexp
+}
+
+func (r ecmaRegexp) MatchString(s string) bool {
+	ok, err := r.re.MatchString(s)
+	return err == nil && ok
+}
+
+func (r ecmaRegexp) String() string {
+	return r.re.String()
+}
+
+// unsupportedRegexp keeps the source of the pattern that the engine failed to compile in lenient mode.
+// It matches any string, so the pattern is not enforced.
+type unsupportedRegexp struct {
+	pattern string
+}
+
+func (r unsupportedRegexp) MatchString(_ string) bool {
+	return true
+}
+
+func (r unsupportedRegexp) String() string {
+	return r.pattern
+}
+
+type parseOptWithRegexEngine struct {
+	engine RegexEngine
+}
+
+func (o parseOptWithRegexEngine) Apply(opt *parserOptions) {
+	opt.regexEngine = o.engine
+}
+
+// OptWithRegexEngine sets the engine that compiles patterns, e.g. ECMAEngine.
+func OptWithRegexEngine(engine RegexEngine) ParseOpt {
+	return parseOptWithRegexEngine{engine: engine}
+}
+
+type parseOptWithLenientPatterns struct{}
+
+func (parseOptWithLenientPatterns) Apply(opt *parserOptions) {
+	opt.lenientPatterns = true
+}
+
+// OptWithLenientPatterns makes patterns that the regex engine cannot compile produce warnings
+// (see RAML.Warnings) instead of parsing errors. Such patterns are kept as is but match any string.
+func OptWithLenientPatterns() ParseOpt {
+	return parseOptWithLenientPatterns{}
+}
+
+// compilePattern compiles the pattern with the regex engine of the RAML. Patterns are compiled once when
+// the fragment is decoded, and identical patterns share the compiled regular expression.
+func (r *RAML) compilePattern(pattern string, location string, node *yaml.Node) (Regexp, error) {
+	var engine RegexEngine = RE2Engine{}
+	if r != nil && r.regexEngine != nil {
+		engine = r.regexEngine
+	}
+	if r != nil {
+		if re := r.cachedPattern(pattern); re != nil {
+			incCounter(r.metrics.PatternCacheHits)
+			return re, nil
+		}
+		if err := r.checkPatternComplexity(pattern); err != nil {
+			return nil, err
+		}
+	}
+	re, err := engine.Compile(pattern)
+	if err == nil {
+		if r != nil {
+			r.cachePattern(pattern, re)
+		}
+		return re, nil
+	}
+	if r == nil || !r.lenientPatterns {
+		return nil, err
+	}
+	r.warnings = append(r.warnings, StacktraceNewWrapped("unsupported pattern is not enforced", err, location,
+		WithNodePosition(node), stacktrace.WithSeverity(stacktrace.SeverityWarning),
+		stacktrace.WithInfo("pattern", pattern)))
+	return unsupportedRegexp{pattern: pattern}, nil
+}
+
+func (r *RAML) cachedPattern(pattern string) Regexp {
+	r.patternsMu.Lock()
+	defer r.patternsMu.Unlock()
+	return r.patterns[pattern]
+}
+
+func (r *RAML) cachePattern(pattern string, re Regexp) {
+	r.patternsMu.Lock()
+	defer r.patternsMu.Unlock()
+	if r.patterns == nil {
+		r.patterns = make(map[string]Regexp)
+	}
+	r.patterns[pattern] = re
+}
+
+// resetPatterns drops compiled patterns when the regex engine changes.
+func (r *RAML) resetPatterns() {
+	r.patternsMu.Lock()
+	defer r.patternsMu.Unlock()
+	r.patterns = nil
+}
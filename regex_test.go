@@ -0,0 +1,94 @@
+package raml
+
+import (
+	"testing"
+	"time"
+
+	"github.com/acronis/go-stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+const regexLibrary = `#%RAML 1.0 Library
+types:
+  Password:
+    type: string
+    pattern: ^(?=.*[0-9])(?=.*[a-z]).{8,}$
+  Doubled:
+    type: string
+    pattern: ^(\w)\1$
+  Headers:
+    type: object
+    properties:
+      /^x-(?!internal-).+$/: string
+`
+
+func TestRAML_compilePattern(t *testing.T) {
+	t.Run("RE2 rejects lookaheads", func(t *testing.T) {
+		_, err := ParseFromString(regexLibrary, "library.raml", t.TempDir(), OptWithValidate())
+		require.ErrorContains(t, err, "decode pattern")
+	})
+
+	t.Run("ECMA engine", func(t *testing.T) {
+		rml, err := ParseFromString(regexLibrary, "library.raml", t.TempDir(),
+			OptWithRegexEngine(ECMAEngine{MatchTimeout: time.Second}), OptWithUnwrap(), OptWithValidate())
+		require.NoError(t, err)
+		lib := rml.EntryPoint().(*Library)
+
+		password, _ := lib.Types.Get("Password")
+		require.NoError(t, password.Validate("secret123"))
+		require.ErrorContains(t, password.Validate("secretpass"), "must match pattern")
+
+		doubled, _ := lib.Types.Get("Doubled")
+		require.NoError(t, doubled.Validate("aa"))
+		require.Error(t, doubled.Validate("ab"))
+
+		headers, _ := lib.Types.Get("Headers")
+		pp := headers.Shape.(*ObjectShape).PatternProperties.Oldest().Value
+		require.True(t, pp.Pattern.MatchString("x-request-id"))
+		require.False(t, pp.Pattern.MatchString("x-internal-id"))
+	})
+
+	t.Run("lenient patterns", func(t *testing.T) {
+		rml, err := ParseFromString(regexLibrary, "library.raml", t.TempDir(),
+			OptWithLenientPatterns(), OptWithUnwrap(), OptWithValidate())
+		require.NoError(t, err)
+		warnings := rml.Warnings()
+		require.Len(t, warnings, 3)
+		require.Equal(t, stacktrace.SeverityWarning, warnings[0].Severity)
+		require.Equal(t, 5, warnings[0].Position.Line)
+
+		lib := rml.EntryPoint().(*Library)
+		password, _ := lib.Types.Get("Password")
+		require.NoError(t, password.Validate("secretpass"))
+		require.Equal(t, "^(?=.*[0-9])(?=.*[a-z]).{8,}$", password.Shape.(*StringShape).Pattern.String())
+	})
+}
+
+func TestRAML_compilePatternShared(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Code:
+    type: string
+    pattern: ^[A-Z]{3}$
+  Other:
+    type: string
+    pattern: ^[A-Z]{3}$
+  Headers:
+    type: object
+    properties:
+      /^[A-Z]{3}$/: Code
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+	code, err := rml.LookupType("Code", "")
+	require.NoError(t, err)
+	other, err := rml.LookupType("Other", "")
+	require.NoError(t, err)
+	headers, err := rml.LookupType("Headers", "")
+	require.NoError(t, err)
+
+	re := code.Shape.(*StringShape).Pattern
+	require.True(t, re == other.Shape.(*StringShape).Pattern)
+	require.True(t, re == headers.Shape.(*ObjectShape).PatternProperties.Oldest().Value.Pattern)
+	require.Len(t, rml.patterns, 1)
+}
@@ -0,0 +1,150 @@
+package raml
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// generateString returns a string that matches the pattern and has a length within the bounds. All bounds and
+// the pattern are optional.
+func (g *generator) generateString(pattern Regexp, minLength, maxLength *uint64) (string, error) {
+	lo, hi := 0, -1
+	if minLength != nil {
+		lo = int(*minLength)
+	}
+	if maxLength != nil {
+		hi = int(*maxLength)
+	}
+	if pattern == nil {
+		n := lo
+		if hi >= 0 {
+			n += g.intn(min(hi-lo, generateSpan) + 1)
+		} else {
+			n += g.intn(generateSpan + 1)
+		}
+		var sb strings.Builder
+		for i := 0; i < n; i++ {
+			sb.WriteRune(g.letter())
+		}
+		return sb.String(), nil
+	}
+	if _, ok := pattern.(unsupportedRegexp); ok {
+		// The pattern is not enforced.
+		return g.generateString(nil, minLength, maxLength)
+	}
+
+	re, err := syntax.Parse(pattern.String(), syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("parse pattern %s: %w", pattern.String(), err)
+	}
+	re = re.Simplify()
+	// Canonical strings grow with each attempt by repeating unbounded repetitions more times.
+	for attempt := 0; attempt < generateAttempts; attempt++ {
+		var sb strings.Builder
+		g.writeRegexp(&sb, re, attempt)
+		// Patterns that are not anchored at the end also match padded strings.
+		for sb.Len() < lo {
+			sb.WriteRune(g.letter())
+		}
+		s := sb.String()
+		if len(s) >= lo && (hi < 0 || len(s) <= hi) && pattern.MatchString(s) {
+			return s, nil
+		}
+	}
+	if hi < 0 {
+		return "", fmt.Errorf("cannot generate string of length %d or more matching pattern %s", lo, pattern.String())
+	}
+	return "", fmt.Errorf("cannot generate string of length between %d and %d matching pattern %s",
+		lo, hi, pattern.String())
+}
+
+// writeRegexp writes a string that matches the regular expression. Empty-width assertions like anchors are skipped.
+func (g *generator) writeRegexp(sb *strings.Builder, re *syntax.Regexp, extra int) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		sb.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		if r, ok := g.classRune(re.Rune); ok {
+			sb.WriteRune(r)
+		}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(g.letter())
+	case syntax.OpCapture:
+		g.writeRegexp(sb, re.Sub[0], extra)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.writeRegexp(sb, sub, extra)
+		}
+	case syntax.OpAlternate:
+		g.writeRegexp(sb, re.Sub[g.intn(len(re.Sub))], extra)
+	case syntax.OpStar:
+		g.writeRepeat(sb, re.Sub[0], 0, -1, extra)
+	case syntax.OpPlus:
+		g.writeRepeat(sb, re.Sub[0], 1, -1, extra)
+	case syntax.OpQuest:
+		g.writeRepeat(sb, re.Sub[0], 0, 1, extra)
+	case syntax.OpRepeat:
+		g.writeRepeat(sb, re.Sub[0], re.Min, re.Max, extra)
+	}
+}
+
+// writeRepeat repeats the regular expression from minimum to maximum times, maximum is -1 if unbounded.
+// Canonical strings repeat it minimum times plus extra.
+func (g *generator) writeRepeat(sb *strings.Builder, re *syntax.Regexp, minimum, maximum int, extra int) {
+	n := minimum
+	if g.random() {
+		span := generateSpan / 4
+		if maximum >= 0 {
+			span = maximum - minimum
+		}
+		n += g.intn(span + 1)
+	} else {
+		n += extra
+		if maximum >= 0 && n > maximum {
+			n = maximum
+		}
+	}
+	for i := 0; i < n; i++ {
+		g.writeRegexp(sb, re, extra)
+	}
+}
+
+// classRune returns a rune of the character class given as pairs of inclusive ranges.
+// Printable ASCII characters are preferred, canonical strings use letters and digits if possible.
+func (g *generator) classRune(ranges []rune) (rune, bool) {
+	if len(ranges) == 0 {
+		return 0, false
+	}
+	contains := func(r rune) bool {
+		for i := 0; i < len(ranges); i += 2 {
+			if r >= ranges[i] && r <= ranges[i+1] {
+				return true
+			}
+		}
+		return false
+	}
+	if !g.random() {
+		for _, r := range "aA0" {
+			if contains(r) {
+				return r, true
+			}
+		}
+	}
+	var printable []rune
+	for r := rune(' '); r <= '~'; r++ {
+		if contains(r) {
+			printable = append(printable, r)
+		}
+	}
+	if len(printable) > 0 {
+		return printable[g.intn(len(printable))], true
+	}
+	i := 2 * g.intn(len(ranges)/2)
+	return ranges[i] + rune(g.intn(int(ranges[i+1]-ranges[i])+1)), true
+}
+
+// letter returns "a" for canonical strings or a random lowercase letter.
+func (g *generator) letter() rune {
+	return 'a' + rune(g.intn(26))
+}
@@ -0,0 +1,190 @@
+package raml
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FragmentInfo describes a fragment loaded into the registry of the RAML.
+type FragmentInfo struct {
+	Kind     FragmentKind
+	Location string
+	// Dependencies are locations of libraries the fragment uses and files it includes, sorted.
+	Dependencies []string
+	// Declarations are names the fragment declares, prefixed with their kind like in query selectors, e.g.
+	// "types.Pet", "annotationTypes.pii" or "traits.paged". Data type fragments declare their type named like in
+	// the type graph and named example fragments declare "examples.<name>".
+	Declarations []string
+	Fragment     Fragment
+}
+
+// FragmentEventType is the type of the change of the fragment registry.
+type FragmentEventType int
+
+const (
+	// FragmentRegistered is sent when a fragment is decoded and added to the registry.
+	FragmentRegistered FragmentEventType = iota
+	// FragmentDropped is sent when a fragment invalidated by Invalidate is removed from the registry by Reparse.
+	FragmentDropped
+)
+
+// FragmentEvent is a change of the fragment registry. Fragments are registered before the libraries they use are
+// parsed, so call FragmentInfo after parsing to get complete dependencies.
+type FragmentEvent struct {
+	Type     FragmentEventType
+	Kind     FragmentKind
+	Location string
+	Fragment Fragment
+}
+
+// FragmentListener receives changes of the fragment registry. It is called from the goroutine that parses.
+type FragmentListener func(event FragmentEvent)
+
+type fragmentSubscription struct {
+	id       int
+	listener FragmentListener
+}
+
+// SubscribeFragments calls the listener on every change of the fragment registry until the returned function is
+// called. Subscribe before ParseFromPath or ParseFromString of the RAML to observe all fragments.
+func (r *RAML) SubscribeFragments(listener FragmentListener) (unsubscribe func()) {
+	r.lastSubscriptionID++
+	id := r.lastSubscriptionID
+	r.fragmentListeners = append(r.fragmentListeners, fragmentSubscription{id: id, listener: listener})
+	return func() {
+		r.fragmentListeners = slices.DeleteFunc(r.fragmentListeners, func(s fragmentSubscription) bool {
+			return s.id == id
+		})
+	}
+}
+
+func (r *RAML) notifyFragment(eventType FragmentEventType, kind FragmentKind, location string, fragment Fragment) {
+	if len(r.fragmentListeners) == 0 {
+		return
+	}
+	event := FragmentEvent{Type: eventType, Kind: kind, Location: location, Fragment: fragment}
+	// Listeners may unsubscribe while they are notified.
+	for _, s := range slices.Clone(r.fragmentListeners) {
+		s.listener(event)
+	}
+}
+
+// putFragment adds the fragment to the registry and notifies listeners if it is not registered yet.
+func (r *RAML) putFragment(location string, fragment Fragment, kind FragmentKind) {
+	if _, ok := r.fragmentsCache[location]; ok {
+		return
+	}
+	r.fragmentsCache[location] = fragment
+	r.resetSubtypes()
+	if len(r.plugins) > 0 {
+		r.parsedFragments = append(r.parsedFragments, location)
+	}
+	r.notifyFragment(FragmentRegistered, kind, location, fragment)
+}
+
+// fragmentKind returns the kind of the registered fragment. The library of the API root has the API kind.
+func (r *RAML) fragmentKind(fragment Fragment) FragmentKind {
+	switch f := fragment.(type) {
+	case *Library:
+		if r.api != nil && r.api.Library == f {
+			return FragmentAPI
+		}
+		return FragmentLibrary
+	case *DataType:
+		return FragmentDataType
+	case *NamedExample:
+		return FragmentNamedExample
+	default:
+		return FragmentUnknown
+	}
+}
+
+// Fragments returns all fragments of the registry in the order of their locations.
+func (r *RAML) Fragments() []FragmentInfo {
+	locations := make([]string, 0, len(r.fragmentsCache))
+	for loc := range r.fragmentsCache {
+		locations = append(locations, loc)
+	}
+	slices.Sort(locations)
+	dependencies := r.fragmentDependencies()
+	result := make([]FragmentInfo, len(locations))
+	for i, loc := range locations {
+		result[i] = r.fragmentInfo(loc, r.fragmentsCache[loc], dependencies[filepath.Clean(loc)])
+	}
+	return result
+}
+
+// FragmentInfo returns the fragment at the location, false if the registry does not contain it.
+func (r *RAML) FragmentInfo(location string) (FragmentInfo, bool) {
+	f, ok := r.fragmentsCache[location]
+	if !ok {
+		return FragmentInfo{}, false
+	}
+	return r.fragmentInfo(location, f, r.fragmentDependencies()[filepath.Clean(location)]), true
+}
+
+// fragmentDependencies inverts dependents of fragments into their sorted dependencies.
+func (r *RAML) fragmentDependencies() map[string][]string {
+	result := make(map[string][]string)
+	for dependency, dependents := range r.fragmentDependents {
+		for dependent := range dependents {
+			result[dependent] = append(result[dependent], dependency)
+		}
+	}
+	for _, deps := range result {
+		slices.Sort(deps)
+	}
+	return result
+}
+
+func (r *RAML) fragmentInfo(location string, fragment Fragment, dependencies []string) FragmentInfo {
+	return FragmentInfo{
+		Kind:         r.fragmentKind(fragment),
+		Location:     location,
+		Dependencies: dependencies,
+		Declarations: r.fragmentDeclarations(fragment),
+		Fragment:     fragment,
+	}
+}
+
+func (r *RAML) fragmentDeclarations(fragment Fragment) []string {
+	var result []string
+	switch f := fragment.(type) {
+	case *Library:
+		for pair := f.Types.Oldest(); pair != nil; pair = pair.Next() {
+			result = append(result, "types."+pair.Key)
+		}
+		for pair := f.AnnotationTypes.Oldest(); pair != nil; pair = pair.Next() {
+			result = append(result, "annotationTypes."+pair.Key)
+		}
+		if api := r.api; api != nil && api.Library == f {
+			for pair := api.Traits.Oldest(); pair != nil; pair = pair.Next() {
+				result = append(result, "traits."+pair.Key)
+			}
+			for pair := api.ResourceTypes.Oldest(); pair != nil; pair = pair.Next() {
+				result = append(result, "resourceTypes."+pair.Key)
+			}
+			for pair := api.SecuritySchemes.Oldest(); pair != nil; pair = pair.Next() {
+				result = append(result, "securitySchemes."+pair.Key)
+			}
+		}
+	case *DataType:
+		if f.Shape == nil {
+			break
+		}
+		name := f.Shape.Name
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(f.Location), filepath.Ext(f.Location))
+		}
+		result = append(result, "types."+name)
+	case *NamedExample:
+		if f.Map == nil {
+			break
+		}
+		for pair := f.Map.Oldest(); pair != nil; pair = pair.Next() {
+			result = append(result, "examples."+pair.Key)
+		}
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package raml
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAML_Fragments(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, content string) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+		return p
+	}
+	entry := writeFile("api.raml", `#%RAML 1.0
+title: Pets
+uses:
+  common: common.raml
+traits:
+  paged:
+    queryParameters:
+      limit: integer
+types:
+  Pet:
+    type: common.Named
+    properties:
+      tag: !include tag.raml
+    examples: !include pets.raml
+`)
+	common := writeFile("common.raml", `#%RAML 1.0 Library
+annotationTypes:
+  pii: nil
+types:
+  Named:
+    properties:
+      name: string
+`)
+	tag := writeFile("tag.raml", "#%RAML 1.0 DataType\ntype: string\n")
+	pets := writeFile("pets.raml", "#%RAML 1.0 NamedExample\nrex:\n  name: Rex\n  tag: dog\n")
+
+	rml := New(context.Background())
+	var events []FragmentEvent
+	unsubscribe := rml.SubscribeFragments(func(event FragmentEvent) {
+		events = append(events, event)
+	})
+	require.NoError(t, rml.ParseFromPath(entry))
+
+	fragments := rml.Fragments()
+	require.Len(t, fragments, 4)
+	require.Equal(t, FragmentAPI, fragments[0].Kind)
+	require.Equal(t, entry, fragments[0].Location)
+	require.Equal(t, []string{common, pets, tag}, fragments[0].Dependencies)
+	require.Equal(t, []string{"types.Pet", "traits.paged"}, fragments[0].Declarations)
+	require.Equal(t, FragmentLibrary, fragments[1].Kind)
+	require.Equal(t, []string{"types.Named", "annotationTypes.pii"}, fragments[1].Declarations)
+	require.Empty(t, fragments[1].Dependencies)
+
+	info, ok := rml.FragmentInfo(pets)
+	require.True(t, ok)
+	require.Equal(t, FragmentNamedExample, info.Kind)
+	require.Equal(t, []string{"examples.rex"}, info.Declarations)
+	require.Same(t, rml.GetFragment(pets), info.Fragment)
+	info, ok = rml.FragmentInfo(tag)
+	require.True(t, ok)
+	require.Equal(t, "DataType", info.Kind.String())
+	require.Equal(t, []string{"types.tag.raml"}, info.Declarations)
+	_, ok = rml.FragmentInfo(filepath.Join(dir, "missing.raml"))
+	require.False(t, ok)
+
+	registered := make(map[string]FragmentKind)
+	for _, e := range events {
+		require.Equal(t, FragmentRegistered, e.Type)
+		registered[e.Location] = e.Kind
+	}
+	require.Equal(t, map[string]FragmentKind{
+		entry: FragmentAPI, common: FragmentLibrary, tag: FragmentDataType, pets: FragmentNamedExample,
+	}, registered)
+
+	events = nil
+	rml.Invalidate(common)
+	require.NoError(t, rml.Reparse())
+	require.ElementsMatch(t, []FragmentEvent{
+		{Type: FragmentDropped, Kind: FragmentAPI, Location: entry, Fragment: fragments[0].Fragment},
+		{Type: FragmentDropped, Kind: FragmentLibrary, Location: common, Fragment: fragments[1].Fragment},
+	}, events[:2])
+	require.Len(t, events, 4)
+
+	unsubscribe()
+	events = nil
+	rml.Invalidate(common)
+	require.NoError(t, rml.Reparse())
+	require.Empty(t, events)
+}
@@ -0,0 +1,227 @@
+package raml
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// addDependency records that the fragment at dependent location uses or includes the file at dependency location.
+func (r *RAML) addDependency(dependent string, dependency string) {
+	if r.fragmentDependents == nil {
+		r.fragmentDependents = make(map[string]map[string]struct{})
+	}
+	dependency = filepath.Clean(dependency)
+	deps, ok := r.fragmentDependents[dependency]
+	if !ok {
+		deps = make(map[string]struct{})
+		r.fragmentDependents[dependency] = deps
+	}
+	dependent = filepath.Clean(dependent)
+	deps[dependent] = struct{}{}
+	r.setFragmentDepth(dependent, dependency)
+}
+
+// GetDependents returns locations of fragments that directly use or include the file at the given location.
+func (r *RAML) GetDependents(location string) []string {
+	deps := r.fragmentDependents[filepath.Clean(location)]
+	result := make([]string, 0, len(deps))
+	for dep := range deps {
+		result = append(result, dep)
+	}
+	return result
+}
+
+// Invalidate marks the file at the given location and all fragments that transitively depend on it as stale.
+// Stale fragments are re-read on the next Reparse call, while the rest of the model is kept intact.
+// Returns the list of affected locations, including the given one.
+func (r *RAML) Invalidate(location string) []string {
+	if r.invalidatedFragments == nil {
+		r.invalidatedFragments = make(map[string]struct{})
+	}
+	location = filepath.Clean(location)
+	affected := []string{location}
+	visited := map[string]struct{}{location: {}}
+	// Breadth-first traversal over reverse dependencies.
+	for i := 0; i < len(affected); i++ {
+		for dep := range r.fragmentDependents[affected[i]] {
+			if _, ok := visited[dep]; ok {
+				continue
+			}
+			visited[dep] = struct{}{}
+			affected = append(affected, dep)
+		}
+	}
+	for _, loc := range affected {
+		r.invalidatedFragments[loc] = struct{}{}
+	}
+	return affected
+}
+
+// IsInvalidated returns true if the fragment at the given location is waiting for Reparse.
+func (r *RAML) IsInvalidated(location string) bool {
+	_, ok := r.invalidatedFragments[filepath.Clean(location)]
+	return ok
+}
+
+// dropInvalidatedFragments removes stale fragments and everything that belongs to them from the RAML.
+func (r *RAML) dropInvalidatedFragments() {
+	isStale := func(location string) bool {
+		_, ok := r.invalidatedFragments[filepath.Clean(location)]
+		return ok
+	}
+	for loc := range r.fragmentsCache {
+		if isStale(loc) {
+			frag := r.fragmentsCache[loc]
+			delete(r.fragmentsCache, loc)
+			// Shapes kept from before the reparse still resolve the handle, fragments that are read again get new ones.
+			r.dropLocation(loc)
+			r.notifyFragment(FragmentDropped, r.fragmentKind(frag), loc, frag)
+		}
+	}
+	for loc := range r.fragmentTypes {
+		if isStale(loc) {
+			delete(r.fragmentTypes, loc)
+		}
+	}
+	for loc := range r.fragmentAnnotationTypes {
+		if isStale(loc) {
+			delete(r.fragmentAnnotationTypes, loc)
+		}
+	}
+	shapes := r.shapes[:0]
+	for _, s := range r.shapes {
+		if !isStale(s.Location()) {
+			shapes = append(shapes, s)
+		}
+	}
+	r.shapes = shapes
+	for id, s := range r.declaredShapes {
+		if isStale(s.Location()) {
+			delete(r.declaredShapes, id)
+		}
+	}
+	domainExtensions := r.domainExtensions[:0]
+	for _, de := range r.domainExtensions {
+		if !isStale(de.Location) {
+			domainExtensions = append(domainExtensions, de)
+		}
+	}
+	r.domainExtensions = domainExtensions
+	warnings := r.warnings[:0]
+	for _, w := range r.warnings {
+		if !isStale(w.Location) {
+			warnings = append(warnings, w)
+		}
+	}
+	r.warnings = warnings
+	for loc := range r.usedLibraries {
+		if isStale(loc) {
+			delete(r.usedLibraries, loc)
+		}
+	}
+	// Stale fragments will register their dependencies again once they are parsed.
+	for dependency, dependents := range r.fragmentDependents {
+		for dep := range dependents {
+			if isStale(dep) {
+				delete(dependents, dep)
+			}
+		}
+		if len(dependents) == 0 {
+			delete(r.fragmentDependents, dependency)
+		}
+	}
+	r.invalidatedFragments = make(map[string]struct{})
+}
+
+// Reparse re-reads all fragments invalidated by Invalidate and rebuilds the parts of the model that depend on them.
+// Fragments that were not affected are reused as is, including their resolved shapes. The entry point is re-read
+// only if it is invalidated, i.e. the changed file is used or included by it transitively.
+//
+// NOTE: Options must match the options used for the initial parsing.
+func (r *RAML) Reparse(opts ...ParseOpt) error {
+	if len(r.invalidatedFragments) == 0 {
+		return nil
+	}
+	if r.entryPoint == nil {
+		return fmt.Errorf("entry point is not set")
+	}
+	entryPointLocation := r.entryPoint.GetLocation()
+	if r.IsInvalidated(entryPointLocation) {
+		r.dropInvalidatedFragments()
+		r.entryPoint = nil
+		if err := r.ParseFromPath(entryPointLocation, opts...); err != nil {
+			return fmt.Errorf("parse from path: %w", err)
+		}
+		return nil
+	}
+
+	pOpts := &parserOptions{}
+	for _, opt := range opts {
+		opt.Apply(pOpts)
+	}
+	start := time.Now()
+	// Locations that are not fragments, e.g. included examples, are re-read with fragments that include them.
+	var stale []string
+	for loc := range r.fragmentsCache {
+		if r.IsInvalidated(loc) {
+			stale = append(stale, loc)
+		}
+	}
+	slices.Sort(stale)
+	r.dropInvalidatedFragments()
+	r.applyParserOptions(pOpts)
+	r.stage = StageNone
+	for _, loc := range stale {
+		// The fragment may be read already as a dependency of another stale fragment.
+		if r.GetFragment(loc) != nil {
+			continue
+		}
+		if err := r.reparseFragment(loc); err != nil {
+			return fmt.Errorf("reparse fragment: %w", err)
+		}
+	}
+	return r.runStages(pOpts, start)
+}
+
+// reparseFragment reads the fragment at the location that is not used or included by the entry point.
+func (r *RAML) reparseFragment(location string) error {
+	if err := r.context().Err(); err != nil {
+		return fmt.Errorf("parse: %w", err)
+	}
+	location, f, err := r.openInclude("", location)
+	if err != nil {
+		return StacktraceNewWrapped("resolve fragment", err, location, stacktrace.WithType(stacktrace.TypeReading))
+	}
+	defer closeContent(f)
+	rs, err := seekableContent(f, r.limits.MaxTotalSize)
+	if err != nil {
+		return StacktraceNewWrapped("read fragment", err, location, stacktrace.WithType(stacktrace.TypeReading))
+	}
+	head, err := ReadHead(rs)
+	if err != nil {
+		return StacktraceNewWrapped("read head", err, location, stacktrace.WithType(stacktrace.TypeParsing))
+	}
+	kind, err := IdentifyFragment(head)
+	if err != nil {
+		return StacktraceNewWrapped("identify fragment", err, location, stacktrace.WithType(stacktrace.TypeParsing))
+	}
+	switch kind {
+	case FragmentLibrary:
+		_, err = r.decodeLibrary(rs, location)
+	case FragmentDataType:
+		_, err = r.decodeDataType(rs, location)
+	case FragmentNamedExample:
+		_, err = r.decodeNamedExample(rs, location)
+	default:
+		return stacktrace.New("unexpected fragment kind", location, stacktrace.WithInfo("kind", kind),
+			stacktrace.WithType(stacktrace.TypeParsing))
+	}
+	if err != nil {
+		return StacktraceNewWrapped("decode fragment", err, location, stacktrace.WithType(stacktrace.TypeParsing))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package raml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAML_Reparse(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, content string) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+		return p
+	}
+	entry := writeFile("library.raml", `#%RAML 1.0 Library
+uses:
+  common: ./common.raml
+  other: ./other.raml
+types:
+  Child:
+    type: common.Parent
+    minLength: 2
+`)
+	common := writeFile("common.raml", `#%RAML 1.0 Library
+types:
+  Parent:
+    type: string
+`)
+	other := writeFile("other.raml", `#%RAML 1.0 Library
+types:
+  Other: integer
+`)
+
+	rml, err := ParseFromPath(entry, OptWithValidate())
+	require.NoError(t, err)
+	otherFrag := rml.GetFragment(other)
+	require.NotNil(t, otherFrag)
+	require.ElementsMatch(t, []string{entry}, rml.GetDependents(common))
+
+	writeFile("common.raml", `#%RAML 1.0 Library
+types:
+  Parent:
+    type: string
+    maxLength: 10
+`)
+	affected := rml.Invalidate(common)
+	require.ElementsMatch(t, []string{common, entry}, affected)
+	require.True(t, rml.IsInvalidated(entry))
+	require.False(t, rml.IsInvalidated(other))
+
+	require.NoError(t, rml.Reparse(OptWithValidate()))
+	require.False(t, rml.IsInvalidated(entry))
+	// Unaffected fragment must be reused as is.
+	require.Same(t, otherFrag, rml.GetFragment(other))
+
+	lib, ok := rml.GetFragment(common).(*Library)
+	require.True(t, ok)
+	parent, ok := lib.Types.Get("Parent")
+	require.True(t, ok)
+	s, ok := parent.Shape.(*StringShape)
+	require.True(t, ok)
+	require.NotNil(t, s.MaxLength)
+	require.Equal(t, uint64(10), *s.MaxLength)
+}
+
+func TestRAML_Reparse_EntryPointNotAffected(t *testing.T) {
+	resolver := &mapResolver{
+		files: map[string]string{
+			"api.raml": `#%RAML 1.0 Library
+uses:
+  common: common.raml
+  other: other.raml
+types:
+  Pet: common.Named
+`,
+			"common.raml": `#%RAML 1.0 Library
+types:
+  Named: string
+`,
+			"other.raml": `#%RAML 1.0 Library
+types:
+  Other: integer
+`,
+		},
+		resolved: map[string]int{},
+	}
+	opts := []ParseOpt{OptWithIncludeResolver(resolver), OptWithValidate()}
+	rml, err := ParseFromPath("api.raml", opts...)
+	require.NoError(t, err)
+
+	// The entry point stops using the library, so the library is no longer related to it.
+	resolver.files["api.raml"] = `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Pet: common.Named
+`
+	rml.Invalidate("api.raml")
+	require.NoError(t, rml.Reparse(opts...))
+	entry := rml.EntryPoint()
+
+	resolver.files["other.raml"] = `#%RAML 1.0 Library
+types:
+  Other: number
+`
+	require.Equal(t, []string{"other.raml"}, rml.Invalidate("other.raml"))
+	clear(resolver.resolved)
+	require.NoError(t, rml.Reparse(opts...))
+	require.Equal(t, map[string]int{"other.raml": 1}, resolver.resolved)
+	require.Same(t, entry, rml.EntryPoint())
+
+	lib, ok := rml.GetFragment("other.raml").(*Library)
+	require.True(t, ok)
+	other, ok := lib.Types.Get("Other")
+	require.True(t, ok)
+	require.IsType(t, &NumberShape{}, other.Shape)
+}
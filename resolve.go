@@ -1,13 +1,13 @@
 package raml
 
 import (
+	"context"
+	"errors"
 	"fmt"
-
-	"github.com/antlr4-go/antlr/v4"
+	"slices"
+	"strings"
 
 	"github.com/acronis/go-stacktrace"
-
-	"github.com/acronis/go-raml/rdt"
 )
 
 /*
@@ -23,16 +23,19 @@ are stored in `unresolvedShapes` as UnknownShape. UnknownShape includes YAML nod
 This helps to avoid additional traversals of nested shapes since the traverse is already done by YAML parser and it will
 generate UnknownShapes and add them to `unresolvedShapes` recursively as they occur.
 */
-func (r *RAML) resolveShapes() error {
+func (r *RAML) resolveShapes(ctx context.Context) error {
 	var st *stacktrace.StackTrace
 	for r.unresolvedShapes.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		v := r.unresolvedShapes.Front()
 		base, ok := v.Value.(*BaseShape)
 		if !ok {
 			return fmt.Errorf("invalid unresolved shape: Value is not *BaseShape: %T", v.Value)
 		}
 		if err := r.resolveShape(base); err != nil {
-			se := StacktraceNewWrapped("resolve shape", err, base.Location,
+			se := StacktraceNewWrapped("resolve shape", err, base.Location(),
 				stacktrace.WithPosition(&base.Position),
 				stacktrace.WithType(stacktrace.TypeResolving))
 			if st == nil {
@@ -42,6 +45,7 @@ func (r *RAML) resolveShapes() error {
 			}
 		}
 		r.unresolvedShapes.Remove(v)
+		r.shapeResolved(base)
 	}
 	if st != nil {
 		return st
@@ -91,7 +95,7 @@ func (r *RAML) resolveMultipleInheritance(base *BaseShape, shape *UnknownShape)
 		}
 	}
 	// Multiple inheritance validation to be performed in a separate validation stage
-	s, err := r.MakeConcreteShapeYAML(base, inherits[0].Type, shape.facets)
+	s, err := r.MakeConcreteShapeYAML(base, inheritedType(inherits[0].Type, shape.facets), shape.facets)
 	if err != nil {
 		return nil, fmt.Errorf("make concrete shape: %w", err)
 	}
@@ -103,7 +107,7 @@ func (r *RAML) resolveLink(base *BaseShape, shape *UnknownShape) (Shape, error)
 	if err := r.resolveShape(linkShape); err != nil {
 		return nil, fmt.Errorf("resolve link shape: %w", err)
 	}
-	s, err := r.MakeConcreteShapeYAML(base, linkShape.Type, shape.facets)
+	s, err := r.MakeConcreteShapeYAML(base, inheritedType(linkShape.Type, shape.facets), shape.facets)
 	if err != nil {
 		return nil, fmt.Errorf("make concrete shape: %w", err)
 	}
@@ -123,11 +127,18 @@ func (r *RAML) resolveShape(base *BaseShape) error {
 	if !ok {
 		return nil
 	}
+	// A shape that is resolved again before its resolution completes refers to itself through aliases, parents or
+	// included fragments, so it can never be resolved.
+	if i := slices.Index(r.resolvingShapes, base); i >= 0 {
+		return fmt.Errorf("cyclic reference: %s", referenceChain(append(r.resolvingShapes[i:], base)))
+	}
+	r.resolvingShapes = append(r.resolvingShapes, base)
+	defer func() { r.resolvingShapes = r.resolvingShapes[:len(r.resolvingShapes)-1] }()
 
 	if base.Link != nil {
 		s, err := r.resolveLink(base, unknownShape)
 		if err != nil {
-			return StacktraceNewWrapped("resolve link", err, base.Location,
+			return StacktraceNewWrapped("resolve link", err, base.Location(),
 				stacktrace.WithPosition(&base.Position))
 		}
 		base.SetShape(s)
@@ -139,25 +150,42 @@ func (r *RAML) resolveShape(base *BaseShape) error {
 		// Special case for multiple inheritance
 		s, err := r.resolveMultipleInheritance(base, unknownShape)
 		if err != nil {
-			return StacktraceNewWrapped("resolve multiple inheritance", err, base.Location,
+			return StacktraceNewWrapped("resolve multiple inheritance", err, base.Location(),
 				stacktrace.WithPosition(&base.Position))
 		}
 		base.SetShape(s)
 		return nil
 	}
 
-	is := antlr.NewInputStream(shapeType)
-	lexer := rdt.NewrdtLexer(is)
-	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
-	rdtParser := rdt.NewrdtParser(tokens)
-	visitor := NewRdtVisitor(r)
-	tree := rdtParser.Entrypoint()
-
-	s, err := visitor.Visit(tree, unknownShape)
+	if err := r.checkTypeExpression(shapeType); err != nil {
+		return StacktraceNewWrapped("check type expression", err, base.Location(),
+			stacktrace.WithPosition(&base.Position))
+	}
+	s, err := r.visitTypeExpression(shapeType, unknownShape)
 	if err != nil {
-		return StacktraceNewWrapped("visit type expression", err, base.Location,
+		var exprErr *TypeExpressionError
+		if errors.As(err, &exprErr) {
+			pos := base.typeExpressionPosition(exprErr.Offset)
+			return StacktraceNewWrapped("parse type expression", exprErr, base.Location(),
+				stacktrace.WithPosition(&pos), stacktrace.WithInfo("expression", exprErr.Expression),
+				stacktrace.WithInfo("offset", exprErr.Offset))
+		}
+		return StacktraceNewWrapped("visit type expression", err, base.Location(),
 			stacktrace.WithPosition(&base.Position))
 	}
 	base.SetShape(s)
 	return nil
 }
+
+// referenceChain returns names and locations of the shapes that refer to each other in order.
+func referenceChain(chain []*BaseShape) string {
+	parts := make([]string, len(chain))
+	for i, s := range chain {
+		if s.Position.Line > 0 {
+			parts[i] = fmt.Sprintf("%s (%s:%d)", s.Name, s.Location(), s.Position.Line)
+		} else {
+			parts[i] = fmt.Sprintf("%s (%s)", s.Name, s.Location())
+		}
+	}
+	return strings.Join(parts, " -> ")
+}
@@ -0,0 +1,890 @@
+package raml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// Resource is a resource of the API with the resource type and traits applied.
+type Resource struct {
+	// Path is the URI of the resource relative to the parent resource, e.g. "/{id}".
+	Path string
+	// FullPath is the URI of the resource relative to the base URI, e.g. "/pets/{id}".
+	FullPath    string
+	DisplayName string
+	Description string
+	// Type and Is are names of the resource type and traits applied to the resource.
+	Type string
+	Is   []string
+	// URIParameters contains declared parameters of Path.
+	URIParameters *orderedmap.OrderedMap[string, *BaseShape]
+	// Methods contains methods by their names in lower case, e.g. "get".
+	Methods *orderedmap.OrderedMap[string, *Method]
+	// Resources contains nested resources by their relative paths.
+	Resources *orderedmap.OrderedMap[string, *Resource]
+	SecuredBy []string
+
+	CustomDomainProperties *orderedmap.OrderedMap[string, *DomainExtension]
+
+	// Parent is the parent resource, nil for top-level resources.
+	Parent   *Resource
+	Location string
+	stacktrace.Position
+}
+
+// Method is a method of a resource with traits, the resource type and security schemes applied.
+type Method struct {
+	// Name is the name of the method in lower case, e.g. "get".
+	Name        string
+	DisplayName string
+	Description string
+	// Is contains names of traits applied to the method, including the traits of the resource.
+	Is []string
+	// Headers are matched case-insensitively, see LookupHeader.
+	Headers         *orderedmap.OrderedMap[string, *BaseShape]
+	QueryParameters *orderedmap.OrderedMap[string, *BaseShape]
+	// Body contains bodies keyed by media types, see SelectBody.
+	Body *orderedmap.OrderedMap[string, *BaseShape]
+	// Responses contains responses by status codes.
+	Responses *orderedmap.OrderedMap[int, *Response]
+	// SecuredBy contains names of security schemes of the method, its resource or the API. "null" means that
+	// the method may be called without security.
+	SecuredBy []string
+	Protocols []string
+
+	CustomDomainProperties *orderedmap.OrderedMap[string, *DomainExtension]
+
+	Resource *Resource
+	Location string
+	stacktrace.Position
+}
+
+// Response is a response of a method.
+type Response struct {
+	Status      int
+	Description string
+	Headers     *orderedmap.OrderedMap[string, *BaseShape]
+	Body        *orderedmap.OrderedMap[string, *BaseShape]
+
+	CustomDomainProperties *orderedmap.OrderedMap[string, *DomainExtension]
+
+	Location string
+	stacktrace.Position
+}
+
+// SecurityScheme is a declaration of a security scheme. Headers, query parameters and responses that the scheme
+// is described by are applied to the methods secured by it.
+type SecurityScheme struct {
+	Name        string
+	Type        string
+	DisplayName string
+	Description string
+	// Settings are the settings of the scheme, e.g. authorizationUri of OAuth 2.0.
+	Settings map[string]any
+
+	Location string
+	stacktrace.Position
+	describedBy *yaml.Node
+}
+
+// methodNames are the names of HTTP methods that RAML allows.
+var methodNames = map[string]struct{}{
+	"get": {}, "patch": {}, "put": {}, "post": {}, "delete": {}, "options": {}, "head": {},
+}
+
+// securedByNull is the name of the security scheme that means that no security is required.
+const securedByNull = "null"
+
+func isMethodName(name string) bool {
+	_, ok := methodNames[name]
+	return ok
+}
+
+func (api *API) unmarshalTraits(valueNode *yaml.Node) error {
+	if valueNode.Tag == TagNull {
+		return nil
+	}
+	if valueNode.Kind != yaml.MappingNode {
+		return stacktrace.New("must be map", api.Location, WithNodePosition(valueNode))
+	}
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		name, node := valueNode.Content[j].Value, valueNode.Content[j+1]
+		t := &Trait{
+			Name:     name,
+			Location: api.Location,
+			Position: stacktrace.Position{Line: node.Line, Column: node.Column},
+			node:     node,
+		}
+		if i := mappingIndex(node, "usage"); i >= 0 {
+			t.Usage = node.Content[i+1].Value
+		}
+		api.Traits.Set(name, t)
+	}
+	return nil
+}
+
+func (api *API) unmarshalResourceTypes(valueNode *yaml.Node) error {
+	if valueNode.Tag == TagNull {
+		return nil
+	}
+	if valueNode.Kind != yaml.MappingNode {
+		return stacktrace.New("must be map", api.Location, WithNodePosition(valueNode))
+	}
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		name, node := valueNode.Content[j].Value, valueNode.Content[j+1]
+		rt := &ResourceType{
+			Name:     name,
+			Location: api.Location,
+			Position: stacktrace.Position{Line: node.Line, Column: node.Column},
+			node:     node,
+		}
+		if i := mappingIndex(node, "usage"); i >= 0 {
+			rt.Usage = node.Content[i+1].Value
+		}
+		api.ResourceTypes.Set(name, rt)
+	}
+	return nil
+}
+
+func (api *API) unmarshalSecuritySchemes(valueNode *yaml.Node) error {
+	if valueNode.Tag == TagNull {
+		return nil
+	}
+	if valueNode.Kind != yaml.MappingNode {
+		return stacktrace.New("must be map", api.Location, WithNodePosition(valueNode))
+	}
+	for j := 0; j != len(valueNode.Content); j += 2 {
+		name, node := valueNode.Content[j].Value, valueNode.Content[j+1]
+		if node.Kind != yaml.MappingNode {
+			return stacktrace.New("security scheme must be map", api.Location, WithNodePosition(node))
+		}
+		ss := &SecurityScheme{
+			Name:     name,
+			Location: api.Location,
+			Position: stacktrace.Position{Line: node.Line, Column: node.Column},
+		}
+		for i := 0; i != len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			var err error
+			switch key.Value {
+			case "type":
+				err = decodeScalar(value, &ss.Type, api.Location)
+			case "displayName":
+				err = decodeScalar(value, &ss.DisplayName, api.Location)
+			case "description":
+				err = decodeScalar(value, &ss.Description, api.Location)
+			case "settings":
+				err = value.Decode(&ss.Settings)
+			case "describedBy":
+				ss.describedBy = value
+			}
+			if err != nil {
+				return StacktraceNewWrapped("parse "+key.Value, err, api.Location, WithNodePosition(value))
+			}
+		}
+		if ss.Type == "" {
+			return stacktrace.New("security scheme type is required", api.Location, WithNodePosition(node),
+				stacktrace.WithInfo("scheme", name))
+		}
+		api.SecuritySchemes.Set(name, ss)
+	}
+	return nil
+}
+
+// decodeSecuredBy decodes names of security schemes. Parameters of schemes are not kept.
+func decodeSecuredBy(node *yaml.Node, location string) ([]string, error) {
+	items := []*yaml.Node{node}
+	if node.Kind == yaml.SequenceNode {
+		items = node.Content
+	}
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Tag == TagNull {
+			names = append(names, securedByNull)
+			continue
+		}
+		refs, err := decodeTemplateRefs(item, location)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			names = append(names, ref.name)
+		}
+	}
+	return names, nil
+}
+
+// unmarshalResources parses resources of the API root. It runs after traits, resource types and security schemes are
+// collected, since they may be declared after resources.
+func (api *API) unmarshalResources(value *yaml.Node) error {
+	for i := 0; i != len(value.Content); i += 2 {
+		key, node := value.Content[i], value.Content[i+1]
+		if !strings.HasPrefix(key.Value, "/") {
+			continue
+		}
+		res, err := api.makeResource(key.Value, node, nil)
+		if err != nil {
+			return err
+		}
+		api.Resources.Set(key.Value, res)
+	}
+	return nil
+}
+
+func (api *API) makeResource(path string, node *yaml.Node, parent *Resource) (*Resource, error) {
+	res := &Resource{
+		Path:                   path,
+		FullPath:               path,
+		URIParameters:          orderedmap.New[string, *BaseShape](0),
+		Methods:                orderedmap.New[string, *Method](0),
+		Resources:              orderedmap.New[string, *Resource](0),
+		CustomDomainProperties: orderedmap.New[string, *DomainExtension](0),
+		Parent:                 parent,
+		Location:               api.Location,
+		Position:               stacktrace.Position{Line: node.Line, Column: node.Column},
+	}
+	if parent != nil {
+		res.FullPath = parent.FullPath + path
+	}
+	if _, err := parseURITemplate(res.FullPath); err != nil {
+		return nil, StacktraceNewWrapped("parse uri template", err, api.Location, WithNodePosition(node))
+	}
+	own, err := api.expandResource(res, node)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i != len(own.Content); i += 2 {
+		key, value := own.Content[i], own.Content[i+1]
+		switch {
+		case strings.HasPrefix(key.Value, "/"):
+			child, err := api.makeResource(key.Value, value, res)
+			if err != nil {
+				return nil, err
+			}
+			res.Resources.Set(key.Value, child)
+		case isMethodName(key.Value):
+			m, err := api.makeMethod(key.Value, value, res)
+			if err != nil {
+				return nil, err
+			}
+			res.Methods.Set(key.Value, m)
+		case key.Value == "displayName":
+			err = decodeScalar(value, &res.DisplayName, api.Location)
+		case key.Value == "description":
+			err = decodeScalar(value, &res.Description, api.Location)
+		case key.Value == "uriParameters":
+			res.URIParameters, err = api.makeParameters(value, false)
+		case key.Value == "securedBy":
+			res.SecuredBy, err = decodeSecuredBy(value, api.Location)
+		case IsCustomDomainExtensionNode(key.Value):
+			err = api.unmarshalAnnotation(res.CustomDomainProperties, key, value)
+		}
+		if err != nil {
+			return nil, StacktraceNewWrapped("parse resource "+res.FullPath, err, api.Location,
+				WithNodePosition(key))
+		}
+	}
+	return res, nil
+}
+
+// expandResource returns a copy of the resource node with the resource type and traits applied to the resource and
+// its methods, and security schemes applied to its methods.
+func (api *API) expandResource(res *Resource, node *yaml.Node) (*yaml.Node, error) {
+	params := map[string]string{
+		"resourcePath":     res.FullPath,
+		"resourcePathName": resourcePathName(res.FullPath),
+	}
+	own, err := copyWithParams(node, nil, api.Location)
+	if err != nil {
+		return nil, err
+	}
+	if own.Tag == TagNull {
+		own = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: node.Line, Column: node.Column}
+	}
+	if own.Kind != yaml.MappingNode {
+		return nil, stacktrace.New("resource must be map", api.Location, WithNodePosition(node))
+	}
+
+	// Resource type and traits of the resource.
+	var typeNode *yaml.Node
+	if i := mappingIndex(own, "type"); i >= 0 {
+		refs, err := decodeTemplateRefs(own.Content[i+1], api.Location)
+		if err != nil {
+			return nil, StacktraceNewWrapped("parse type", err, api.Location, WithNodePosition(own.Content[i+1]))
+		}
+		if len(refs) != 1 {
+			return nil, stacktrace.New("resource must have one type", api.Location,
+				WithNodePosition(own.Content[i+1]))
+		}
+		res.Type = refs[0].name
+		typeNode, err = api.instantiateResourceType(refs[0], params, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var resourceTraits []templateRef
+	if i := mappingIndex(own, "is"); i >= 0 {
+		if resourceTraits, err = decodeTemplateRefs(own.Content[i+1], api.Location); err != nil {
+			return nil, StacktraceNewWrapped("parse is", err, api.Location, WithNodePosition(own.Content[i+1]))
+		}
+		for _, ref := range resourceTraits {
+			res.Is = append(res.Is, ref.name)
+		}
+	}
+	var typeTraits []templateRef
+	if typeNode != nil {
+		if i := mappingIndex(typeNode, "is"); i >= 0 {
+			if typeTraits, err = decodeTemplateRefs(typeNode.Content[i+1], api.Location); err != nil {
+				return nil, StacktraceNewWrapped("parse is", err, api.Location,
+					WithNodePosition(typeNode.Content[i+1]))
+			}
+		}
+		// Optional methods of the resource type apply only to methods the resource declares.
+		for i := 0; i != len(typeNode.Content); i += 2 {
+			name := typeNode.Content[i].Value
+			if method, ok := strings.CutSuffix(name, "?"); ok && isMethodName(method) {
+				if j := mappingIndex(own, method); j >= 0 {
+					typeNode.Content[i] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: method}
+				} else {
+					typeNode.Content[i+1] = nil
+				}
+			}
+		}
+		typeNode.Content = compactMapping(typeNode.Content)
+	}
+
+	// The methods own values take precedence over traits of the method, the traits of the resource, the method of
+	// the resource type and the traits of the resource type, in that order.
+	var methods []string
+	for i := 0; i != len(own.Content); i += 2 {
+		if isMethodName(own.Content[i].Value) {
+			methods = append(methods, own.Content[i].Value)
+		}
+	}
+	if typeNode != nil {
+		for i := 0; i != len(typeNode.Content); i += 2 {
+			name := typeNode.Content[i].Value
+			if isMethodName(name) && mappingIndex(own, name) < 0 {
+				own.Content = append(own.Content, typeNode.Content[i],
+					&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+				methods = append(methods, name)
+			}
+		}
+	}
+	for _, name := range methods {
+		i := mappingIndex(own, name)
+		method := own.Content[i+1]
+		if method.Tag == TagNull {
+			method = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: method.Line, Column: method.Column}
+			own.Content[i+1] = method
+		}
+		if method.Kind != yaml.MappingNode {
+			return nil, stacktrace.New("method must be map", api.Location, WithNodePosition(method))
+		}
+		methodParams := withParam(params, "methodName", name)
+		var methodTraits []templateRef
+		if j := mappingIndex(method, "is"); j >= 0 {
+			if methodTraits, err = decodeTemplateRefs(method.Content[j+1], api.Location); err != nil {
+				return nil, StacktraceNewWrapped("parse is", err, api.Location,
+					WithNodePosition(method.Content[j+1]))
+			}
+		}
+		traits := append(append([]templateRef{}, methodTraits...), resourceTraits...)
+		if err = api.applyTraits(method, traits, methodParams); err != nil {
+			return nil, err
+		}
+		var inherited []templateRef
+		if typeNode != nil {
+			if j := mappingIndex(typeNode, name); j >= 0 {
+				typeMethod, err := copyWithParams(typeNode.Content[j+1], map[string]string{"methodName": name},
+					api.Location)
+				if err != nil {
+					return nil, err
+				}
+				if k := mappingIndex(typeMethod, "is"); k >= 0 {
+					if inherited, err = decodeTemplateRefs(typeMethod.Content[k+1], api.Location); err != nil {
+						return nil, StacktraceNewWrapped("parse is", err, api.Location,
+							WithNodePosition(typeMethod.Content[k+1]))
+					}
+				}
+				if typeMethod.Kind == yaml.MappingNode {
+					mergeNodes(method, typeMethod)
+				}
+			}
+			inherited = append(inherited, typeTraits...)
+		}
+		if err = api.applyTraits(method, inherited, methodParams); err != nil {
+			return nil, err
+		}
+		setTraitNames(method, append(traits, inherited...))
+	}
+
+	// The rest of the resource type, e.g. uriParameters and nested facets.
+	if typeNode != nil {
+		for i := 0; i != len(typeNode.Content); i += 2 {
+			name := typeNode.Content[i].Value
+			if isMethodName(name) || name == "is" || name == "type" {
+				continue
+			}
+			mergeNodes(own, &yaml.Node{Kind: yaml.MappingNode, Content: typeNode.Content[i : i+2]})
+		}
+	}
+	return own, nil
+}
+
+// compactMapping removes pairs with nil values from the content of the mapping.
+func compactMapping(content []*yaml.Node) []*yaml.Node {
+	out := content[:0]
+	for i := 0; i != len(content); i += 2 {
+		if content[i+1] != nil {
+			out = append(out, content[i], content[i+1])
+		}
+	}
+	return out
+}
+
+// setTraitNames records names of the applied traits in the "is" node of the method, each name once.
+func setTraitNames(method *yaml.Node, traits []templateRef) {
+	names := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	seen := make(map[string]struct{}, len(traits))
+	for _, ref := range traits {
+		if _, ok := seen[ref.name]; ok {
+			continue
+		}
+		seen[ref.name] = struct{}{}
+		names.Content = append(names.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ref.name})
+	}
+	if i := mappingIndex(method, "is"); i >= 0 {
+		method.Content[i+1] = names
+	} else if len(names.Content) > 0 {
+		method.Content = append(method.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "is"}, names)
+	}
+}
+
+func withParam(params map[string]string, name string, value string) map[string]string {
+	out := make(map[string]string, len(params)+1)
+	for k, v := range params {
+		out[k] = v
+	}
+	out[name] = value
+	return out
+}
+
+// resourcePathName returns the rightmost segment of the path without parameters, e.g. "pets" for "/pets/{id}".
+func resourcePathName(path string) string {
+	segments := strings.Split(path, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if s := segments[i]; s != "" && !strings.HasPrefix(s, "{") {
+			return s
+		}
+	}
+	return ""
+}
+
+// instantiateResourceType returns the resource type with parameters substituted and the resource types it inherits
+// merged in.
+func (api *API) instantiateResourceType(
+	ref templateRef, params map[string]string, seen []string,
+) (*yaml.Node, error) {
+	for _, name := range seen {
+		if name == ref.name {
+			return nil, stacktrace.New("resource type inherits itself", api.Location, WithNodePosition(ref.node),
+				stacktrace.WithInfo("chain", strings.Join(append(seen, ref.name), " -> ")))
+		}
+	}
+	rt, ok := api.ResourceTypes.Get(ref.name)
+	if !ok {
+		return nil, stacktrace.New("resource type not found", api.Location, WithNodePosition(ref.node),
+			stacktrace.WithInfo("type", ref.name))
+	}
+	all := make(map[string]string, len(params)+len(ref.params))
+	for k, v := range ref.params {
+		all[k] = v
+	}
+	for k, v := range params {
+		all[k] = v
+	}
+	node, err := instantiateTemplateKeepMethods(rt.node, all, api.Location)
+	if err != nil {
+		return nil, StacktraceNewWrapped("apply resource type "+ref.name, err, api.Location,
+			WithNodePosition(ref.node))
+	}
+	if i := mappingIndex(node, "type"); i >= 0 {
+		refs, err := decodeTemplateRefs(node.Content[i+1], api.Location)
+		if err != nil || len(refs) != 1 {
+			return nil, stacktrace.New("resource type must have one type", api.Location,
+				WithNodePosition(node.Content[i+1]))
+		}
+		parent, err := api.instantiateResourceType(refs[0], params, append(seen, ref.name))
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		mergeResourceTypes(node, parent)
+	}
+	return node, nil
+}
+
+// instantiateTemplateKeepMethods instantiates the resource type leaving the methodName parameter of its methods to
+// be substituted when the methods are applied.
+func instantiateTemplateKeepMethods(node *yaml.Node, params map[string]string, location string) (*yaml.Node, error) {
+	if node.Kind != yaml.MappingNode {
+		return instantiateTemplate(node, params, location)
+	}
+	methods := &yaml.Node{Kind: yaml.MappingNode}
+	rest := &yaml.Node{Kind: yaml.MappingNode, Tag: node.Tag, Line: node.Line, Column: node.Column}
+	for i := 0; i != len(node.Content); i += 2 {
+		if isMethodName(strings.TrimSuffix(node.Content[i].Value, "?")) {
+			methods.Content = append(methods.Content, node.Content[i], node.Content[i+1])
+		} else {
+			rest.Content = append(rest.Content, node.Content[i], node.Content[i+1])
+		}
+	}
+	out, err := instantiateTemplate(rest, params, location)
+	if err != nil {
+		return nil, err
+	}
+	keep := withParam(params, "methodName", "<<methodName>>")
+	for i := 0; i != len(methods.Content); i += 2 {
+		v, err := copyWithParams(methods.Content[i+1], keep, location)
+		if err != nil {
+			return nil, err
+		}
+		out.Content = append(out.Content, methods.Content[i], v)
+	}
+	return out, nil
+}
+
+// mergeResourceTypes merges the inherited resource type into the resource type. Optional methods match methods with
+// or without the question mark.
+func mergeResourceTypes(dst *yaml.Node, src *yaml.Node) {
+	for i := 0; i != len(src.Content); i += 2 {
+		name := src.Content[i].Value
+		if method, ok := strings.CutSuffix(name, "?"); ok && isMethodName(method) {
+			if j := mappingIndex(dst, method); j >= 0 {
+				if dst.Content[j+1].Kind == yaml.MappingNode && src.Content[i+1].Kind == yaml.MappingNode {
+					mergeNodes(dst.Content[j+1], src.Content[i+1])
+				}
+				continue
+			}
+		}
+		mergeNodes(dst, &yaml.Node{Kind: yaml.MappingNode, Content: src.Content[i : i+2]})
+	}
+}
+
+// applyTraits merges the traits into the method in the order of precedence.
+func (api *API) applyTraits(method *yaml.Node, traits []templateRef, params map[string]string) error {
+	for _, ref := range traits {
+		t, ok := api.Traits.Get(ref.name)
+		if !ok {
+			return stacktrace.New("trait not found", api.Location, WithNodePosition(ref.node),
+				stacktrace.WithInfo("trait", ref.name))
+		}
+		all := make(map[string]string, len(params)+len(ref.params))
+		for k, v := range ref.params {
+			all[k] = v
+		}
+		for k, v := range params {
+			all[k] = v
+		}
+		node, err := instantiateTemplate(t.node, all, api.Location)
+		if err != nil {
+			return StacktraceNewWrapped("apply trait "+ref.name, err, api.Location, WithNodePosition(ref.node))
+		}
+		mergeNodes(method, node)
+	}
+	return nil
+}
+
+func (api *API) makeMethod(name string, node *yaml.Node, res *Resource) (*Method, error) {
+	m := &Method{
+		Name:                   name,
+		Headers:                orderedmap.New[string, *BaseShape](0),
+		QueryParameters:        orderedmap.New[string, *BaseShape](0),
+		Body:                   orderedmap.New[string, *BaseShape](0),
+		Responses:              orderedmap.New[int, *Response](0),
+		CustomDomainProperties: orderedmap.New[string, *DomainExtension](0),
+		Resource:               res,
+		Location:               api.Location,
+		Position:               stacktrace.Position{Line: node.Line, Column: node.Column},
+	}
+	m.SecuredBy = api.securedBy(node, res)
+	if err := api.applySecuritySchemes(node, m.SecuredBy); err != nil {
+		return nil, err
+	}
+	for i := 0; i != len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		var err error
+		switch key.Value {
+		case "displayName":
+			err = decodeScalar(value, &m.DisplayName, api.Location)
+		case "description":
+			err = decodeScalar(value, &m.Description, api.Location)
+		case "is":
+			m.Is, err = decodeScalars(value, api.Location)
+		case "headers":
+			m.Headers, err = api.makeParameters(value, true)
+		case "queryParameters":
+			m.QueryParameters, err = api.makeParameters(value, false)
+		case "body":
+			m.Body, err = api.makeBody(value)
+		case "responses":
+			m.Responses, err = api.makeResponses(value)
+		case "protocols":
+			m.Protocols, err = decodeScalars(value, api.Location)
+			for i, p := range m.Protocols {
+				m.Protocols[i] = strings.ToUpper(p)
+			}
+		default:
+			if IsCustomDomainExtensionNode(key.Value) {
+				err = api.unmarshalAnnotation(m.CustomDomainProperties, key, value)
+			}
+		}
+		if err != nil {
+			return nil, StacktraceNewWrapped(fmt.Sprintf("parse method %s %s", name, res.FullPath), err,
+				api.Location, WithNodePosition(key))
+		}
+	}
+	return m, nil
+}
+
+// securedBy returns names of security schemes of the method, its resources or the API.
+func (api *API) securedBy(node *yaml.Node, res *Resource) []string {
+	if i := mappingIndex(node, "securedBy"); i >= 0 {
+		if names, err := decodeSecuredBy(node.Content[i+1], api.Location); err == nil {
+			return names
+		}
+	}
+	for r := res; r != nil; r = r.Parent {
+		if r.SecuredBy != nil {
+			return r.SecuredBy
+		}
+	}
+	return api.SecuredBy
+}
+
+// applySecuritySchemes merges headers, query parameters and responses that the schemes are described by into
+// the method. The method takes precedence.
+func (api *API) applySecuritySchemes(method *yaml.Node, names []string) error {
+	for _, name := range names {
+		if name == securedByNull {
+			continue
+		}
+		ss, ok := api.SecuritySchemes.Get(name)
+		if !ok {
+			return stacktrace.New("security scheme not found", api.Location, WithNodePosition(method),
+				stacktrace.WithInfo("scheme", name))
+		}
+		if ss.describedBy == nil || ss.describedBy.Kind != yaml.MappingNode {
+			continue
+		}
+		described, err := copyWithParams(ss.describedBy, nil, api.Location)
+		if err != nil {
+			return err
+		}
+		mergeNodes(method, described)
+	}
+	return nil
+}
+
+// makeParameters makes shapes of URI parameters, query parameters or headers. Parameters are required unless their
+// names end with "?" or they set "required: false". Names of headers must be unique regardless of case.
+func (api *API) makeParameters(node *yaml.Node, headers bool) (*orderedmap.OrderedMap[string, *BaseShape], error) {
+	params := orderedmap.New[string, *BaseShape](len(node.Content) / 2)
+	if node.Tag == TagNull {
+		return params, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, stacktrace.New("must be map", api.Location, WithNodePosition(node))
+	}
+	for i := 0; i != len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		name, optional := api.raml.chompImplicitOptional(key.Value)
+		shape, err := api.raml.makeNewShapeYAML(value, name, api.Location)
+		if err != nil {
+			return nil, StacktraceNewWrapped("make shape", err, api.Location, WithNodePosition(value))
+		}
+		if shape.Required == nil && optional {
+			required := false
+			shape.Required = &required
+		} else if shape.Required != nil && optional {
+			name = key.Value
+		}
+		if headers {
+			if declared, _, ok := LookupHeader(params, name); ok {
+				return nil, stacktrace.New("duplicate header", api.Location, WithNodePosition(key),
+					stacktrace.WithInfo("header", name), stacktrace.WithInfo("declared", declared))
+			}
+		}
+		params.Set(name, shape)
+	}
+	return params, nil
+}
+
+// makeBody makes shapes of bodies keyed by media types. A body declared as a type without media types is keyed by
+// the empty string and applies to the default media types of the API.
+func (api *API) makeBody(node *yaml.Node) (*orderedmap.OrderedMap[string, *BaseShape], error) {
+	bodies := orderedmap.New[string, *BaseShape](0)
+	if node.Tag == TagNull {
+		return bodies, nil
+	}
+	byMediaType := node.Kind == yaml.MappingNode && len(node.Content) > 0
+	for i := 0; byMediaType && i != len(node.Content); i += 2 {
+		byMediaType = strings.Contains(node.Content[i].Value, "/")
+	}
+	if !byMediaType {
+		shape, err := api.raml.makeNewShapeYAML(node, "body", api.Location)
+		if err != nil {
+			return nil, StacktraceNewWrapped("make shape", err, api.Location, WithNodePosition(node))
+		}
+		bodies.Set("", shape)
+		return bodies, nil
+	}
+	for i := 0; i != len(node.Content); i += 2 {
+		mediaType, value := node.Content[i].Value, node.Content[i+1]
+		if value.Tag == TagNull {
+			// A body without a type accepts anything.
+			value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: TypeAny, Line: value.Line,
+				Column: value.Column}
+		}
+		shape, err := api.raml.makeNewShapeYAML(value, mediaType, api.Location)
+		if err != nil {
+			return nil, StacktraceNewWrapped("make shape", err, api.Location, WithNodePosition(value))
+		}
+		bodies.Set(mediaType, shape)
+	}
+	return bodies, nil
+}
+
+func (api *API) makeResponses(node *yaml.Node) (*orderedmap.OrderedMap[int, *Response], error) {
+	responses := orderedmap.New[int, *Response](len(node.Content) / 2)
+	if node.Tag == TagNull {
+		return responses, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, stacktrace.New("must be map", api.Location, WithNodePosition(node))
+	}
+	for i := 0; i != len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		status, err := strconv.Atoi(key.Value)
+		if err != nil || status < 100 || status > 599 {
+			return nil, stacktrace.New("invalid status code", api.Location, WithNodePosition(key),
+				stacktrace.WithInfo("status", key.Value))
+		}
+		resp := &Response{
+			Status:                 status,
+			Headers:                orderedmap.New[string, *BaseShape](0),
+			Body:                   orderedmap.New[string, *BaseShape](0),
+			CustomDomainProperties: orderedmap.New[string, *DomainExtension](0),
+			Location:               api.Location,
+			Position:               stacktrace.Position{Line: value.Line, Column: value.Column},
+		}
+		for j := 0; value.Kind == yaml.MappingNode && j != len(value.Content); j += 2 {
+			k, v := value.Content[j], value.Content[j+1]
+			switch k.Value {
+			case "description":
+				err = decodeScalar(v, &resp.Description, api.Location)
+			case "headers":
+				resp.Headers, err = api.makeParameters(v, true)
+			case "body":
+				resp.Body, err = api.makeBody(v)
+			default:
+				if IsCustomDomainExtensionNode(k.Value) {
+					err = api.unmarshalAnnotation(resp.CustomDomainProperties, k, v)
+				}
+			}
+			if err != nil {
+				return nil, StacktraceNewWrapped("parse response "+key.Value, err, api.Location,
+					WithNodePosition(k))
+			}
+		}
+		responses.Set(status, resp)
+	}
+	return responses, nil
+}
+
+func (api *API) unmarshalAnnotation(
+	annotations *orderedmap.OrderedMap[string, *DomainExtension], key *yaml.Node, value *yaml.Node,
+) error {
+	name, de, err := api.raml.unmarshalCustomDomainExtension(api.Location, key, value)
+	if err != nil {
+		return StacktraceNewWrapped("unmarshal custom domain extension", err, api.Location, WithNodePosition(value))
+	}
+	annotations.Set(name, de)
+	return nil
+}
+
+// Operation is a method of a resource with its effective metadata: traits, the resource type and security schemes
+// are applied and URI parameters of parent resources are included.
+type Operation struct {
+	// Method is the HTTP method in upper case, e.g. "GET".
+	Method string
+	// FullPath is the URI template of the resource relative to the base URI, e.g. "/pets/{id}".
+	FullPath        string
+	URIParameters   *orderedmap.OrderedMap[string, *BaseShape]
+	Headers         *orderedmap.OrderedMap[string, *BaseShape]
+	QueryParameters *orderedmap.OrderedMap[string, *BaseShape]
+	Body            *orderedmap.OrderedMap[string, *BaseShape]
+	Responses       *orderedmap.OrderedMap[int, *Response]
+	SecuredBy       []string
+	// CustomDomainProperties contains annotations of the method.
+	CustomDomainProperties *orderedmap.OrderedMap[string, *DomainExtension]
+
+	Resource    *Resource
+	Declaration *Method
+}
+
+// Operations returns operations of all resources of the API in the order of declaration, parent resources first.
+func (api *API) Operations() []*Operation {
+	var ops []*Operation
+	var walk func(resources *orderedmap.OrderedMap[string, *Resource])
+	walk = func(resources *orderedmap.OrderedMap[string, *Resource]) {
+		for pair := resources.Oldest(); pair != nil; pair = pair.Next() {
+			res := pair.Value
+			uriParams := uriParametersOf(res)
+			for m := res.Methods.Oldest(); m != nil; m = m.Next() {
+				ops = append(ops, makeOperation(m.Value, uriParams))
+			}
+			walk(res.Resources)
+		}
+	}
+	walk(api.Resources)
+	return ops
+}
+
+func makeOperation(method *Method, uriParams *orderedmap.OrderedMap[string, *BaseShape]) *Operation {
+	return &Operation{
+		Method:                 strings.ToUpper(method.Name),
+		FullPath:               method.Resource.FullPath,
+		URIParameters:          uriParams,
+		Headers:                method.Headers,
+		QueryParameters:        method.QueryParameters,
+		Body:                   method.Body,
+		Responses:              method.Responses,
+		SecuredBy:              method.SecuredBy,
+		CustomDomainProperties: method.CustomDomainProperties,
+		Resource:               method.Resource,
+		Declaration:            method,
+	}
+}
+
+// uriParametersOf returns URI parameters of the resource and its parents, parents first.
+func uriParametersOf(res *Resource) *orderedmap.OrderedMap[string, *BaseShape] {
+	var chain []*Resource
+	for r := res; r != nil; r = r.Parent {
+		chain = append(chain, r)
+	}
+	params := orderedmap.New[string, *BaseShape](0)
+	for i := len(chain) - 1; i >= 0; i-- {
+		for p := chain[i].URIParameters.Oldest(); p != nil; p = p.Next() {
+			params.Set(p.Key, p.Value)
+		}
+	}
+	return params
+}
@@ -0,0 +1,218 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_Operations(t *testing.T) {
+	content := `#%RAML 1.0
+title: Pets
+mediaType: application/json
+securedBy: [token]
+/pets:
+  type: { collection: { item: Pet } }
+  is: [ { paged: { max: 50 } } ]
+  get:
+    description: List pets.
+  /{id}:
+    uriParameters:
+      id: integer
+    get:
+      securedBy: [null]
+      responses:
+        200:
+          body: Pet
+    delete?:
+      description: Not a method of the resource.
+/health:
+  get:
+    headers:
+      X-Probe?: string
+types:
+  Pet:
+    properties:
+      name: string
+traits:
+  paged:
+    usage: Applies to collections.
+    queryParameters:
+      limit:
+        type: integer
+        maximum: <<max>>
+        required: false
+  tagged:
+    headers:
+      X-<<methodName | !uppercase>>-Tag: string
+resourceTypes:
+  base:
+    get?:
+      is: [tagged]
+  collection:
+    type: base
+    description: Collection of <<resourcePathName>>.
+    get:
+      is: [ { paged: { max: 100 } } ]
+      responses:
+        200:
+          body: <<item>>[]
+    post?:
+      body: <<item>>
+securitySchemes:
+  token:
+    type: Pass Through
+    describedBy:
+      headers:
+        Authorization: string
+      responses:
+        401:
+          description: Unauthorized.
+`
+	rml, err := ParseFromString(content, "api.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	api := rml.API()
+	require.NotNil(t, api)
+
+	ops := api.Operations()
+	var names []string
+	for _, op := range ops {
+		names = append(names, op.Method+" "+op.FullPath)
+	}
+	require.Equal(t, []string{"GET /pets", "GET /pets/{id}", "GET /health"}, names)
+
+	pets := ops[0]
+	require.Equal(t, "Collection of pets.", pets.Resource.Description)
+	require.Equal(t, "List pets.", pets.Declaration.Description)
+	require.Equal(t, []string{"paged", "tagged"}, pets.Declaration.Is)
+	require.Equal(t, []string{"token"}, pets.SecuredBy)
+	limit, ok := pets.QueryParameters.Get("limit")
+	require.True(t, ok)
+	// Traits of the resource take precedence over traits of the resource type.
+	require.NoError(t, limit.Validate(int64(50)))
+	require.Error(t, limit.Validate(int64(51)))
+	_, _, ok = LookupHeader(pets.Headers, "x-get-tag")
+	require.True(t, ok)
+	_, _, ok = LookupHeader(pets.Headers, "authorization")
+	require.True(t, ok)
+	resp, ok := pets.Responses.Get(200)
+	require.True(t, ok)
+	body, ok := resp.Body.Get("")
+	require.True(t, ok)
+	require.NoError(t, body.Validate([]any{map[string]any{"name": "Rex"}}))
+	require.Error(t, body.Validate(map[string]any{"name": "Rex"}))
+	_, ok = pets.Responses.Get(401)
+	require.True(t, ok)
+
+	pet := ops[1]
+	require.Equal(t, []string{securedByNull}, pet.SecuredBy)
+	id, ok := pet.URIParameters.Get("id")
+	require.True(t, ok)
+	require.NoError(t, id.Validate(int64(1)))
+	_, _, ok = LookupHeader(pet.Headers, "Authorization")
+	require.False(t, ok)
+	_, ok = pet.Resource.Methods.Get("delete")
+	require.False(t, ok)
+
+	health := ops[2]
+	probe, ok := health.Headers.Get("X-Probe")
+	require.True(t, ok)
+	require.NotNil(t, probe.Required)
+	require.False(t, *probe.Required)
+}
+
+func TestAPI_resourceErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "unknown trait",
+			content: `#%RAML 1.0
+title: API
+/pets:
+  get:
+    is: [paged]
+`,
+			wantErr: "trait not found",
+		},
+		{
+			name: "unknown resource type",
+			content: `#%RAML 1.0
+title: API
+/pets:
+  type: collection
+`,
+			wantErr: "resource type not found",
+		},
+		{
+			name: "resource type cycle",
+			content: `#%RAML 1.0
+title: API
+resourceTypes:
+  a:
+    type: b
+  b:
+    type: a
+/pets:
+  type: a
+`,
+			wantErr: "resource type inherits itself",
+		},
+		{
+			name: "missing parameter",
+			content: `#%RAML 1.0
+title: API
+traits:
+  paged:
+    queryParameters:
+      limit:
+        maximum: <<max>>
+/pets:
+  get:
+    is: [paged]
+`,
+			wantErr: "parameter max is not provided",
+		},
+		{
+			name: "unknown security scheme",
+			content: `#%RAML 1.0
+title: API
+securedBy: [oauth]
+/pets:
+  get:
+`,
+			wantErr: "security scheme not found",
+		},
+		{
+			name: "duplicate header",
+			content: `#%RAML 1.0
+title: API
+/pets:
+  get:
+    headers:
+      X-Tag: string
+      x-tag: string
+`,
+			wantErr: "duplicate header",
+		},
+		{
+			name: "invalid status code",
+			content: `#%RAML 1.0
+title: API
+/pets:
+  get:
+    responses:
+      ok:
+`,
+			wantErr: "invalid status code",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFromString(tt.content, "api.raml", t.TempDir())
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package raml
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+var (
+	// ErrRouteNotFound is returned by Router.Match if no resource matches the path.
+	ErrRouteNotFound = errors.New("route not found")
+	// ErrMethodNotAllowed is returned by Router.MatchOperation if the resource does not declare the method.
+	ErrMethodNotAllowed = errors.New("method not allowed")
+)
+
+// Router matches paths to resources of the API by their URI templates and builds paths of resources.
+type Router struct {
+	api    *API
+	routes []*route
+	// byPath contains routes by full paths of resources.
+	byPath map[string]*route
+}
+
+// route is a resource with its compiled URI template.
+type route struct {
+	resource *Resource
+	parts    []uriTemplatePart
+	pattern  *regexp.Regexp
+	// params contains URI parameters of the resource and its parents.
+	params *orderedmap.OrderedMap[string, *BaseShape]
+	// literals is the length of literals of the template. Templates with longer literals are more specific, e.g.
+	// "/pets/mine" wins over "/pets/{id}".
+	literals int
+}
+
+// Route is a resource matched by a path with values of its URI parameters.
+type Route struct {
+	Resource *Resource
+	// Params contains unescaped values of URI parameters.
+	Params map[string]string
+}
+
+// NewRouter returns a router of the resources of the API. Parse with OptWithUnwrap to validate values of URI
+// parameters against facets inherited by the parameters.
+func NewRouter(api *API) (*Router, error) {
+	rt := &Router{api: api, byPath: make(map[string]*route)}
+	if err := rt.addRoutes(api.Resources); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+func (rt *Router) addRoutes(resources *orderedmap.OrderedMap[string, *Resource]) error {
+	for pair := resources.Oldest(); pair != nil; pair = pair.Next() {
+		res := pair.Value
+		r, err := compileRoute(res)
+		if err != nil {
+			return fmt.Errorf("compile route %s: %w", res.FullPath, err)
+		}
+		rt.routes = append(rt.routes, r)
+		rt.byPath[res.FullPath] = r
+		if err := rt.addRoutes(res.Resources); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compileRoute(res *Resource) (*route, error) {
+	parts, err := parseURITemplate(res.FullPath)
+	if err != nil {
+		return nil, err
+	}
+	r := &route{resource: res, parts: parts, params: uriParametersOf(res)}
+	var b strings.Builder
+	b.WriteString("^")
+	for _, part := range parts {
+		if part.param == "" {
+			b.WriteString(regexp.QuoteMeta(part.literal))
+			r.literals += len(part.literal)
+			continue
+		}
+		// Values of URI parameters are single path segments.
+		b.WriteString("([^/]+)")
+	}
+	b.WriteString("$")
+	r.pattern, err = regexp.Compile(b.String())
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Match returns the resource whose URI template matches the path relative to the base URI, e.g. "/pets/1". If
+// several templates match, the one with the longest literals wins, then the first declared.
+//
+// Values of URI parameters are validated against their shapes. If a value is invalid, the matched route is returned
+// with the error, so callers can tell bad requests from unknown paths. ErrRouteNotFound is returned if no template
+// matches.
+func (rt *Router) Match(path string) (*Route, error) {
+	var (
+		best   *route
+		values []string
+	)
+	for _, r := range rt.routes {
+		m := r.pattern.FindStringSubmatch(path)
+		if m == nil || best != nil && r.literals <= best.literals {
+			continue
+		}
+		best, values = r, m[1:]
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%w: %s", ErrRouteNotFound, path)
+	}
+	match := &Route{Resource: best.resource, Params: make(map[string]string, len(values))}
+	i := 0
+	for _, part := range best.parts {
+		if part.param == "" {
+			continue
+		}
+		value, err := url.PathUnescape(values[i])
+		if err != nil {
+			return match, fmt.Errorf("uri parameter %s: %w", part.param, err)
+		}
+		i++
+		if prev, ok := match.Params[part.param]; ok && prev != value {
+			// The same parameter used twice in the template must have the same value.
+			return nil, fmt.Errorf("%w: %s", ErrRouteNotFound, path)
+		}
+		match.Params[part.param] = value
+	}
+	if err := best.validate(match.Params); err != nil {
+		return match, err
+	}
+	return match, nil
+}
+
+// MatchOperation returns the operation of the method, e.g. "GET", of the resource matched by the path, and values of
+// its URI parameters. Errors are those of Match and ErrMethodNotAllowed. As in Match, the operation is returned
+// with the error if values of URI parameters are invalid.
+func (rt *Router) MatchOperation(method string, path string) (*Operation, map[string]string, error) {
+	match, err := rt.Match(path)
+	if match == nil {
+		return nil, nil, err
+	}
+	m, ok := match.Resource.Methods.Get(strings.ToLower(method))
+	if !ok {
+		return nil, match.Params, fmt.Errorf("%w: %s %s", ErrMethodNotAllowed, method, match.Resource.FullPath)
+	}
+	return makeOperation(m, rt.byPath[match.Resource.FullPath].params), match.Params, err
+}
+
+// Path builds the path of the resource, e.g. "/pets/1" for "/pets/{id}". Values are validated against URI parameters
+// of the resource and its parents and escaped.
+func (rt *Router) Path(fullPath string, values map[string]string) (string, error) {
+	r, ok := rt.byPath[fullPath]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrRouteNotFound, fullPath)
+	}
+	for _, name := range uriTemplateParams(r.parts) {
+		if _, ok := values[name]; !ok {
+			return "", fmt.Errorf("uri parameter %s is missing", name)
+		}
+	}
+	if err := r.validate(values); err != nil {
+		return "", err
+	}
+	return expandURITemplate(r.parts, values), nil
+}
+
+// URL builds the absolute URL of the resource: the base URI expanded as API.ExpandBaseURI does followed by the path
+// of the resource. Values contain both base URI parameters and URI parameters.
+func (rt *Router) URL(fullPath string, values map[string]string) (string, error) {
+	path, err := rt.Path(fullPath, values)
+	if err != nil {
+		return "", err
+	}
+	base, err := rt.api.ExpandBaseURI(values)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(base, "/") + path, nil
+}
+
+// validate validates values of declared URI parameters used in the template. Undeclared parameters are strings.
+func (r *route) validate(values map[string]string) error {
+	for _, name := range uriTemplateParams(r.parts) {
+		shape, ok := r.params.Get(name)
+		if !ok {
+			continue
+		}
+		if err := shape.Validate(ParameterValue(shape, []string{values[name]})); err != nil {
+			return fmt.Errorf("uri parameter %s: %w", name, err)
+		}
+	}
+	return nil
+}
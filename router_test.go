@@ -0,0 +1,89 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter(t *testing.T) {
+	content := `#%RAML 1.0
+title: Pets
+version: v1
+baseUri: https://{tenant}.example.com/api/{version}/
+/pets:
+  get:
+  /mine:
+    get:
+  /{id}:
+    uriParameters:
+      id:
+        type: integer
+        minimum: 1
+    get:
+    /tags/{tag}:
+      delete:
+/files/{name}:
+  get:
+`
+	rml, err := ParseFromString(content, "api.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	rt, err := NewRouter(rml.API())
+	require.NoError(t, err)
+
+	tests := []struct {
+		path     string
+		want     string
+		params   map[string]string
+		wantErr  error
+		errMatch string
+	}{
+		{path: "/pets", want: "/pets", params: map[string]string{}},
+		{path: "/pets/mine", want: "/pets/mine", params: map[string]string{}},
+		{path: "/pets/7", want: "/pets/{id}", params: map[string]string{"id": "7"}},
+		{path: "/pets/7/tags/a%20b", want: "/pets/{id}/tags/{tag}", params: map[string]string{"id": "7", "tag": "a b"}},
+		{path: "/files/a%2Fb", want: "/files/{name}", params: map[string]string{"name": "a/b"}},
+		{path: "/pets/0", want: "/pets/{id}", params: map[string]string{"id": "0"}, errMatch: "uri parameter id"},
+		{path: "/pets/7/tags", wantErr: ErrRouteNotFound},
+		{path: "/owners", wantErr: ErrRouteNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			match, err := rt.Match(tt.path)
+			switch {
+			case tt.wantErr != nil:
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			case tt.errMatch != "":
+				require.ErrorContains(t, err, tt.errMatch)
+			default:
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.want, match.Resource.FullPath)
+			require.Equal(t, tt.params, match.Params)
+		})
+	}
+
+	op, params, err := rt.MatchOperation("DELETE", "/pets/7/tags/new")
+	require.NoError(t, err)
+	require.Equal(t, "DELETE", op.Method)
+	require.Equal(t, map[string]string{"id": "7", "tag": "new"}, params)
+	_, ok := op.URIParameters.Get("id")
+	require.True(t, ok)
+	_, _, err = rt.MatchOperation("POST", "/pets")
+	require.ErrorIs(t, err, ErrMethodNotAllowed)
+
+	path, err := rt.Path("/pets/{id}/tags/{tag}", map[string]string{"id": "7", "tag": "a/b"})
+	require.NoError(t, err)
+	require.Equal(t, "/pets/7/tags/a%2Fb", path)
+	_, err = rt.Path("/pets/{id}", map[string]string{"id": "x"})
+	require.ErrorContains(t, err, "uri parameter id")
+	_, err = rt.Path("/pets/{id}", nil)
+	require.ErrorContains(t, err, "uri parameter id is missing")
+	_, err = rt.Path("/owners", nil)
+	require.ErrorIs(t, err, ErrRouteNotFound)
+
+	u, err := rt.URL("/pets/{id}", map[string]string{"tenant": "acme", "id": "7"})
+	require.NoError(t, err)
+	require.Equal(t, "https://acme.example.com/api/v1/pets/7", u)
+}
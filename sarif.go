@@ -0,0 +1,141 @@
+package raml
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// DiagnosticReport contains diagnostics produced for a single checked file, e.g. by parsing (DiagnosticsFromError),
+// RAML.Diagnostics or Linter.Lint.
+type DiagnosticReport struct {
+	Path        string
+	Diagnostics []Diagnostic
+}
+
+// SARIF 2.1.0 structures, only the subset required to report results.
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+func sarifLevel(severity stacktrace.Severity) string {
+	if severity == stacktrace.SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+func sarifURI(location string) string {
+	return (&url.URL{Path: filepath.ToSlash(location)}).String()
+}
+
+// WriteSARIF writes diagnostics as a SARIF 2.1.0 log that can be uploaded to code scanning services, e.g. GitHub
+// code scanning. Types of diagnostics are used as rule IDs, diagnostics without location point to the report path.
+func WriteSARIF(w io.Writer, reports []DiagnosticReport) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "raml",
+			InformationURI: "https://github.com/acronis/go-raml",
+		}},
+		Results: []sarifResult{},
+	}
+	var ruleIDs []string
+	for _, report := range reports {
+		for _, d := range report.Diagnostics {
+			ruleID := string(d.Type)
+			if !slices.Contains(ruleIDs, ruleID) {
+				ruleIDs = append(ruleIDs, ruleID)
+			}
+			result := sarifResult{
+				RuleID:  ruleID,
+				Level:   sarifLevel(d.Severity),
+				Message: sarifMessage{Text: d.Message},
+			}
+			location := d.Location
+			if location == "" {
+				location = report.Path
+			}
+			loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: sarifURI(location)},
+			}}
+			if d.HasPosition() {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: d.Line, StartColumn: d.Column}
+			}
+			result.Locations = append(result.Locations, loc)
+			run.Results = append(run.Results, result)
+		}
+	}
+	slices.Sort(ruleIDs)
+	for _, id := range ruleIDs {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: id})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}}); err != nil {
+		return fmt.Errorf("encode sarif: %w", err)
+	}
+	return nil
+}
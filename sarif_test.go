@@ -0,0 +1,64 @@
+package raml
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/acronis/go-stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	reports := []DiagnosticReport{
+		{Path: "a.raml", Diagnostics: []Diagnostic{
+			{Severity: stacktrace.SeverityError, Type: "parsing", Message: "boom"},
+			{Severity: stacktrace.SeverityWarning, Type: LintRuleTypeNaming, Message: "bad name",
+				Location: "dir/common.raml", Position: stacktrace.Position{Line: 3, Column: 5}},
+		}},
+		{Path: "b.raml"},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, WriteSARIF(&buf, reports))
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region *struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	require.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	require.Len(t, run.Tool.Driver.Rules, 2)
+	require.Equal(t, "parsing", run.Tool.Driver.Rules[0].ID)
+	require.Len(t, run.Results, 2)
+	require.Equal(t, "error", run.Results[0].Level)
+	require.Equal(t, "a.raml", run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	require.Nil(t, run.Results[0].Locations[0].PhysicalLocation.Region)
+	require.Equal(t, "warning", run.Results[1].Level)
+	require.Equal(t, LintRuleTypeNaming, run.Results[1].RuleID)
+	require.Equal(t, "dir/common.raml", run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	require.Equal(t, 3, run.Results[1].Locations[0].PhysicalLocation.Region.StartLine)
+}
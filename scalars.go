@@ -1,10 +1,15 @@
 package raml
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"math/big"
-	"regexp"
-	"time"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 
@@ -39,24 +44,177 @@ func (r *RAML) MakeEnum(v *yaml.Node, location string) (Nodes, error) {
 func isCompatibleEnum(source Nodes, target Nodes) bool {
 	// Target enum must be a subset of source enum
 	for _, v := range target {
-		found := false
-		for _, e := range source {
-			if v.Value == e.Value {
-				found = true
-				break
-			}
-		}
-		if !found {
+		if !isEnumValue(source, v.Value) {
 			return false
 		}
 	}
 	return true
 }
 
+// isEnumValue reports whether the value is one of the enum values.
+// Numbers are compared by value since enum values and decoded data may have different numeric types.
+func isEnumValue(enum Nodes, v any) bool {
+	for _, e := range enum {
+		if isSameValue(e.Value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSameValue compares scalar values, numbers are compared by exact value regardless of their types, e.g. 1,
+// 1.0 and json.Number("1") are the same.
+func isSameValue(a, b any) bool {
+	if a == b {
+		return true
+	}
+	if x, ok := exactNumber(a); ok {
+		if y, isNum := exactNumber(b); isNum {
+			return x.Cmp(y) == 0
+		}
+	}
+	return false
+}
+
+// enumShape is implemented by shapes that support the enum facet.
+type enumShape interface {
+	enumFacets() *EnumFacets
+}
+
+func (f *EnumFacets) enumFacets() *EnumFacets {
+	return f
+}
+
+// inheritEnum narrows the enum to the enum of the parent. The enum of the child must be a subset of the enum
+// of the parent, otherwise the conflict points to the first value that is not allowed by the parent and is handled
+// by the inheritance policy of the shape.
+func (f *EnumFacets) inheritEnum(source *EnumFacets, base *BaseShape) error {
+	if f.Enum == nil {
+		f.Enum = source.Enum
+		return nil
+	}
+	if source.Enum == nil {
+		return nil
+	}
+	var common Nodes
+	var st *stacktrace.StackTrace
+	for _, v := range f.Enum {
+		if isEnumValue(source.Enum, v.Value) {
+			common = append(common, v)
+		} else if st == nil {
+			st = stacktrace.New("enum constraint violation", v.Location,
+				stacktrace.WithPosition(&v.Position),
+				stacktrace.WithInfo("value", v.String()),
+				stacktrace.WithInfo("source", source.Enum.String()),
+				stacktrace.WithInfo("target", f.Enum.String()))
+		}
+	}
+	if st == nil {
+		return nil
+	}
+	var narrow func()
+	if len(common) > 0 {
+		narrow = func() { f.Enum = common }
+	}
+	return base.inheritConflict(st, narrow)
+}
+
+// validateEnum checks that the value is one of the enum values if the enum is set.
+func (f *EnumFacets) validateEnum(v any) error {
+	if f.Enum != nil && !isEnumValue(f.Enum, v) {
+		return fmt.Errorf("value must be one of (%s)", f.Enum.String())
+	}
+	return nil
+}
+
+// checkEnumValues checks that every enum value is a valid value of the shape.
+func checkEnumValues(s Shape, enum Nodes) error {
+	for _, e := range enum {
+		if err := s.validate(e.Value, "$"); err != nil {
+			return StacktraceNewWrapped("invalid enum value", err, e.Location,
+				stacktrace.WithPosition(&e.Position), stacktrace.WithInfo("value", e.String()))
+		}
+	}
+	return nil
+}
+
+// intersectEnum restricts the enum of the shape to the values of the given enum that are valid for the shape.
+// It reports false if no value remains. Shapes without the enum facet are left as is.
+func (s *BaseShape) intersectEnum(enum Nodes) bool {
+	es, ok := s.Shape.(enumShape)
+	if !ok || enum == nil {
+		return true
+	}
+	var values Nodes
+	for _, e := range enum {
+		if s.Shape.validate(e.Value, "$") == nil {
+			values = append(values, e)
+		}
+	}
+	es.enumFacets().Enum = values
+	return len(values) != 0
+}
+
 type FormatFacets struct {
 	Format *string
 }
 
+// Annotations that make minimum and maximum of integer and number shapes exclusive, e.g. "(exclusiveMinimum): true".
+// RAML 1.0 has no facets for exclusive bounds, so the annotation types must be declared by the user. Annotations of
+// used libraries are recognized by the name without the namespace, e.g. "(lib.exclusiveMinimum): true".
+const (
+	AnnotationExclusiveMinimum = "exclusiveMinimum"
+	AnnotationExclusiveMaximum = "exclusiveMaximum"
+)
+
+// isExclusiveBound reports whether the shape has the annotation with the true value.
+func (s *BaseShape) isExclusiveBound(annotation string) bool {
+	for pair := s.CustomDomainProperties.Oldest(); pair != nil; pair = pair.Next() {
+		name := pair.Key
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			name = name[i+1:]
+		}
+		if name == annotation && pair.Value.Extension != nil && pair.Value.Extension.Value == true {
+			return true
+		}
+	}
+	return false
+}
+
+// floatToRat converts the float to the rational number with the shortest decimal representation,
+// e.g. 0.1 is converted to 1/10 rather than to the exact binary value of the float.
+func floatToRat(f float64) *big.Rat {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// isMultipleOf reports whether the value is an integer multiple of the divisor.
+// Rational arithmetic is used to avoid rounding errors of floats, e.g. 0.3 is a multiple of 0.1.
+func isMultipleOf(value *big.Rat, divisor float64) bool {
+	d := floatToRat(divisor)
+	if d.Sign() == 0 {
+		return false
+	}
+	return new(big.Rat).Quo(value, d).IsInt()
+}
+
+func checkMultipleOf(multipleOf *float64) error {
+	if multipleOf == nil {
+		return nil
+	}
+	if math.IsNaN(*multipleOf) || math.IsInf(*multipleOf, 0) || *multipleOf <= 0 {
+		return fmt.Errorf("multipleOf must be a finite number greater than 0")
+	}
+	return nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
 type IntegerFacets struct {
 	Minimum    *big.Int
 	Maximum    *big.Int
@@ -83,37 +241,35 @@ func (s *IntegerShape) clone(base *BaseShape, _ map[int64]*BaseShape) Shape {
 
 func (s *IntegerShape) validate(v interface{}, _ string) error {
 	var val big.Int
-	switch v := v.(type) {
-	case int:
-		val.SetInt64(int64(v))
-	case uint:
-		val.SetUint64(uint64(v))
-	// json unmarshals numbers as float64
-	case float64:
-		val.SetInt64(int64(v))
-	default:
-		return fmt.Errorf("invalid type, got %T, expected int, uint or float64", v)
+	if err := integerValue(v, &val); err != nil {
+		return err
 	}
 
-	if s.Minimum != nil && val.Cmp(s.Minimum) < 0 {
-		return fmt.Errorf("value must be greater than %s", s.Minimum.String())
+	if s.Minimum != nil {
+		if cmp := val.Cmp(s.Minimum); cmp < 0 {
+			return fmt.Errorf("value must be greater than %s", s.Minimum.String())
+		} else if cmp == 0 && s.isExclusiveBound(AnnotationExclusiveMinimum) {
+			return fmt.Errorf("value must be strictly greater than %s", s.Minimum.String())
+		}
 	}
-	if s.Maximum != nil && val.Cmp(s.Maximum) > 0 {
-		return fmt.Errorf("value must be less than %s", s.Maximum.String())
+	if s.Maximum != nil {
+		if cmp := val.Cmp(s.Maximum); cmp > 0 {
+			return fmt.Errorf("value must be less than %s", s.Maximum.String())
+		} else if cmp == 0 && s.isExclusiveBound(AnnotationExclusiveMaximum) {
+			return fmt.Errorf("value must be strictly less than %s", s.Maximum.String())
+		}
+	}
+	if s.MultipleOf != nil && !isMultipleOf(new(big.Rat).SetInt(&val), *s.MultipleOf) {
+		return fmt.Errorf("value must be a multiple of %s", formatFloat(*s.MultipleOf))
+	}
+	if err := validateIntegerFormat(&val, s.Format); err != nil {
+		return err
 	}
-	// TODO: Implement multipleOf validation
-	// TODO: Implement format validation
 	if s.Enum != nil {
-		// TODO: Probably enum values should be stored as big.Int to simplify validation
-		var num any
-		if val.IsInt64() {
-			num = int(val.Int64())
-		} else if val.IsUint64() {
-			num = uint(val.Uint64())
-		}
 		found := false
+		var num big.Int
 		for _, e := range s.Enum {
-			if e.Value == num {
+			if integerValue(e.Value, &num) == nil && num.Cmp(&val) == 0 {
 				found = true
 				break
 			}
@@ -129,7 +285,7 @@ func (s *IntegerShape) validate(v interface{}, _ string) error {
 func (s *IntegerShape) inherit(source Shape) (Shape, error) {
 	ss, ok := source.(*IntegerShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location,
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", source.Base().Type),
 			stacktrace.WithInfo("target", s.Base().Type))
@@ -137,54 +293,67 @@ func (s *IntegerShape) inherit(source Shape) (Shape, error) {
 	if s.Minimum == nil {
 		s.Minimum = ss.Minimum
 	} else if ss.Minimum != nil && s.Minimum.Cmp(ss.Minimum) < 0 {
-		return nil, stacktrace.New("minimum constraint violation", s.Location,
+		st := stacktrace.New("minimum constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", *ss.Minimum),
 			stacktrace.WithInfo("target", *s.Minimum))
+		if err := s.inheritConflict(st, func() { s.Minimum = ss.Minimum }); err != nil {
+			return nil, err
+		}
 	}
 	if s.Maximum == nil {
 		s.Maximum = ss.Maximum
 	} else if ss.Maximum != nil && s.Maximum.Cmp(ss.Maximum) > 0 {
-		return nil, stacktrace.New("maximum constraint violation", s.Location,
+		st := stacktrace.New("maximum constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", *ss.Maximum),
 			stacktrace.WithInfo("target", *s.Maximum))
+		if err := s.inheritConflict(st, func() { s.Maximum = ss.Maximum }); err != nil {
+			return nil, err
+		}
 	}
-	// TODO: multipleOf validation
 	if s.MultipleOf == nil {
-		// TODO: Disallow multipleOf 0 to avoid division by zero during validation
 		s.MultipleOf = ss.MultipleOf
-	}
-	if s.Enum == nil {
-		s.Enum = ss.Enum
-	} else if ss.Enum != nil && !isCompatibleEnum(ss.Enum, s.Enum) {
-		return nil, stacktrace.New("enum constraint violation", s.Location,
+	} else if ss.MultipleOf != nil && !isMultipleOf(floatToRat(*s.MultipleOf), *ss.MultipleOf) {
+		st := stacktrace.New("multipleOf constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
-			stacktrace.WithInfo("source", ss.Enum.String()),
-			stacktrace.WithInfo("target", s.Enum.String()))
+			stacktrace.WithInfo("source", *ss.MultipleOf),
+			stacktrace.WithInfo("target", *s.MultipleOf))
+		if err := s.inheritConflict(st, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.inheritEnum(&ss.EnumFacets, s.BaseShape); err != nil {
+		return nil, err
 	}
 	if s.Format == nil {
 		s.Format = ss.Format
 	} else if ss.Format != nil && SetOfIntegerFormats[*s.Format] != SetOfIntegerFormats[*ss.Format] {
-		return nil, stacktrace.New("format constraint violation", s.Location,
+		st := stacktrace.New("format constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", *ss.Format),
 			stacktrace.WithInfo("target", *s.Format))
+		if err := s.inheritConflict(st, nil); err != nil {
+			return nil, err
+		}
 	}
 	return s, nil
 }
 
 func (s *IntegerShape) check() error {
 	if s.Minimum != nil && s.Maximum != nil && s.Minimum.Cmp(s.Maximum) > 0 {
-		return stacktrace.New("minimum must be less than or equal to maximum", s.Location,
+		return stacktrace.New("minimum must be less than or equal to maximum", s.Location(),
 			stacktrace.WithPosition(&s.Position))
 	}
+	if err := checkMultipleOf(s.MultipleOf); err != nil {
+		return stacktrace.New(err.Error(), s.Location(), stacktrace.WithPosition(&s.Position))
+	}
 	if s.Enum != nil {
 		for _, e := range s.Enum {
 			switch e.Value.(type) {
 			case int, uint:
 			default:
-				return stacktrace.New("enum value must be int or uint", s.Location,
+				return stacktrace.New("enum value must be int or uint", s.Location(),
 					stacktrace.WithPosition(&e.Position))
 			}
 		}
@@ -192,7 +361,7 @@ func (s *IntegerShape) check() error {
 	// invalid format, found by copilot =)
 	if s.Format != nil {
 		if _, ok := SetOfIntegerFormats[*s.Format]; !ok {
-			return stacktrace.New("invalid format", s.Location, stacktrace.WithPosition(&s.Position))
+			return stacktrace.New("invalid format", s.Location(), stacktrace.WithPosition(&s.Position))
 		}
 	}
 	return nil
@@ -202,53 +371,53 @@ func (s *IntegerShape) unmarshalYAMLNode(node, valueNode *yaml.Node) error {
 	switch node.Value {
 	case FacetMinimum:
 		if valueNode.Tag != TagInt {
-			return stacktrace.New("minimum must be integer", s.Location, WithNodePosition(valueNode))
+			return stacktrace.New("minimum must be integer", s.Location(), WithNodePosition(valueNode))
 		}
 		num, ok := big.NewInt(0).SetString(valueNode.Value, 10)
 		if !ok {
-			return stacktrace.New("invalid minimum value", s.Location, WithNodePosition(valueNode))
+			return stacktrace.New("invalid minimum value", s.Location(), WithNodePosition(valueNode))
 		}
 		s.Minimum = num
 	case FacetMaximum:
 		if valueNode.Tag != TagInt {
-			return stacktrace.New("maximum must be integer", s.Location, WithNodePosition(valueNode))
+			return stacktrace.New("maximum must be integer", s.Location(), WithNodePosition(valueNode))
 		}
 		num, ok := big.NewInt(0).SetString(valueNode.Value, 10)
 		if !ok {
-			return stacktrace.New("invalid maximum value", s.Location, WithNodePosition(valueNode))
+			return stacktrace.New("invalid maximum value", s.Location(), WithNodePosition(valueNode))
 		}
 		s.Maximum = num
 	case FacetMultipleOf:
 		if err := valueNode.Decode(&s.MultipleOf); err != nil {
-			return StacktraceNewWrapped("decode multipleOf", err, s.Location, WithNodePosition(valueNode))
+			return StacktraceNewWrapped("decode multipleOf", err, s.Location(), WithNodePosition(valueNode))
 		}
 	case FacetFormat:
 		if _, ok := SetOfIntegerFormats[valueNode.Value]; !ok {
-			return stacktrace.New("invalid format", s.Location, WithNodePosition(valueNode),
+			return stacktrace.New("invalid format", s.Location(), WithNodePosition(valueNode),
 				stacktrace.WithInfo("allowed_formats", SetOfIntegerFormats))
 		}
 		if err := valueNode.Decode(&s.Format); err != nil {
-			return StacktraceNewWrapped("decode format", err, s.Location, WithNodePosition(valueNode))
+			return StacktraceNewWrapped("decode format", err, s.Location(), WithNodePosition(valueNode))
 		}
 	case FacetEnum:
-		enums, err := s.raml.MakeEnum(valueNode, s.Location)
+		enums, err := s.raml.MakeEnum(valueNode, s.Location())
 		if err != nil {
-			return StacktraceNewWrapped("make enum", err, s.Location, WithNodePosition(valueNode))
+			return StacktraceNewWrapped("make enum", err, s.Location(), WithNodePosition(valueNode))
 		}
 		s.Enum = enums
 	default:
-		n, err := s.raml.makeRootNode(valueNode, s.Location)
+		n, err := s.raml.makeRootNode(valueNode, s.Location())
 		if err != nil {
-			return StacktraceNewWrapped("make node", err, s.Location, WithNodePosition(valueNode))
+			return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
 		}
-		s.CustomShapeFacets.Set(node.Value, n)
+		s.setCustomShapeFacet(node.Value, n)
 	}
 	return nil
 }
 
 func (s *IntegerShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 	if len(v)%2 != 0 {
-		return stacktrace.New("odd number of nodes", s.Location)
+		return stacktrace.New("odd number of nodes", s.Location())
 	}
 	for i := 0; i != len(v); i += 2 {
 		node := v[i]
@@ -260,6 +429,9 @@ func (s *IntegerShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 	return nil
 }
 
+// NumberFacets are float64, values are compared with them exactly by their shortest decimal representation (see
+// floatToRat), so bounds with more than 17 significant digits are rounded. Exact bounds of large integers are
+// declared by IntegerFacets.
 type NumberFacets struct {
 	// Minimum and maximum are unset since there's no theoretical minimum and maximum for numbers by default
 	Minimum    *float64
@@ -286,31 +458,41 @@ func (s *NumberShape) clone(base *BaseShape, _ map[int64]*BaseShape) Shape {
 }
 
 func (s *NumberShape) validate(v interface{}, _ string) error {
-	var val float64
-	switch v := v.(type) {
-	// go-yaml unmarshals integers as int
-	case int:
-		val = float64(v)
-	case uint:
-		val = float64(v)
-	case float64:
-		val = v
-	default:
-		return fmt.Errorf("invalid type, got %T, expected int, uint, float64", v)
+	val, exact, err := numberValue(v)
+	if err != nil {
+		return err
 	}
 
-	if s.Minimum != nil && val < *s.Minimum {
-		return fmt.Errorf("value must be greater than %f", *s.Minimum)
+	if s.Minimum != nil {
+		if cmp := compareNumber(val, exact, *s.Minimum); cmp < 0 {
+			return fmt.Errorf("value must be greater than %f", *s.Minimum)
+		} else if cmp == 0 && s.isExclusiveBound(AnnotationExclusiveMinimum) {
+			return fmt.Errorf("value must be strictly greater than %f", *s.Minimum)
+		}
+	}
+	if s.Maximum != nil {
+		if cmp := compareNumber(val, exact, *s.Maximum); cmp > 0 {
+			return fmt.Errorf("value must be less than %f", *s.Maximum)
+		} else if cmp == 0 && s.isExclusiveBound(AnnotationExclusiveMaximum) {
+			return fmt.Errorf("value must be strictly less than %f", *s.Maximum)
+		}
+	}
+	if s.MultipleOf != nil {
+		r := exact
+		if r == nil {
+			r = floatToRat(val)
+		}
+		if !isMultipleOf(r, *s.MultipleOf) {
+			return fmt.Errorf("value must be a multiple of %s", formatFloat(*s.MultipleOf))
+		}
 	}
-	if s.Maximum != nil && val > *s.Maximum {
-		return fmt.Errorf("value must be less than %f", *s.Maximum)
+	if err = validateNumberFormat(val, exact, s.Format); err != nil {
+		return err
 	}
-	// TODO: Implement multipleOf validation
-	// TODO: Implement format validation
 	if s.Enum != nil {
 		found := false
 		for _, e := range s.Enum {
-			if e.Value == val {
+			if isEnumNumber(e.Value, val, exact) {
 				found = true
 				break
 			}
@@ -326,7 +508,7 @@ func (s *NumberShape) validate(v interface{}, _ string) error {
 func (s *NumberShape) inherit(source Shape) (Shape, error) {
 	ss, ok := source.(*NumberShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location,
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", source.Base().Type),
 			stacktrace.WithInfo("target", s.Base().Type))
@@ -334,54 +516,67 @@ func (s *NumberShape) inherit(source Shape) (Shape, error) {
 	if s.Minimum == nil {
 		s.Minimum = ss.Minimum
 	} else if ss.Minimum != nil && *s.Minimum < *ss.Minimum {
-		return nil, stacktrace.New("minimum constraint violation", s.Location,
+		st := stacktrace.New("minimum constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", *ss.Minimum),
 			stacktrace.WithInfo("target", *s.Minimum))
+		if err := s.inheritConflict(st, func() { s.Minimum = ss.Minimum }); err != nil {
+			return nil, err
+		}
 	}
 	if s.Maximum == nil {
 		s.Maximum = ss.Maximum
 	} else if ss.Maximum != nil && *s.Maximum > *ss.Maximum {
-		return nil, stacktrace.New("maximum constraint violation", s.Location,
+		st := stacktrace.New("maximum constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", *ss.Maximum),
 			stacktrace.WithInfo("target", *s.Maximum))
+		if err := s.inheritConflict(st, func() { s.Maximum = ss.Maximum }); err != nil {
+			return nil, err
+		}
 	}
-	// TODO: multipleOf validation
-	if ss.MultipleOf != nil {
-		// TODO: Disallow multipleOf 0 to avoid division by zero during validation
+	if s.MultipleOf == nil {
 		s.MultipleOf = ss.MultipleOf
-	}
-	if s.Enum == nil {
-		s.Enum = ss.Enum
-	} else if ss.Enum != nil && !isCompatibleEnum(ss.Enum, s.Enum) {
-		return nil, stacktrace.New("enum constraint violation", s.Location,
+	} else if ss.MultipleOf != nil && !isMultipleOf(floatToRat(*s.MultipleOf), *ss.MultipleOf) {
+		st := stacktrace.New("multipleOf constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
-			stacktrace.WithInfo("source", ss.Enum.String()),
-			stacktrace.WithInfo("target", s.Enum.String()))
+			stacktrace.WithInfo("source", *ss.MultipleOf),
+			stacktrace.WithInfo("target", *s.MultipleOf))
+		if err := s.inheritConflict(st, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.inheritEnum(&ss.EnumFacets, s.BaseShape); err != nil {
+		return nil, err
 	}
 	if s.Format == nil {
 		s.Format = ss.Format
 	} else if ss.Format != nil && *s.Format != *ss.Format {
-		return nil, stacktrace.New("format constraint violation", s.Location,
+		st := stacktrace.New("format constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", *ss.Format),
 			stacktrace.WithInfo("target", *s.Format))
+		if err := s.inheritConflict(st, nil); err != nil {
+			return nil, err
+		}
 	}
 	return s, nil
 }
 
 func (s *NumberShape) check() error {
 	if s.Minimum != nil && s.Maximum != nil && *s.Minimum > *s.Maximum {
-		return stacktrace.New("minimum must be less than or equal to maximum", s.Location,
+		return stacktrace.New("minimum must be less than or equal to maximum", s.Location(),
 			stacktrace.WithPosition(&s.Position))
 	}
+	if err := checkMultipleOf(s.MultipleOf); err != nil {
+		return stacktrace.New(err.Error(), s.Location(), stacktrace.WithPosition(&s.Position))
+	}
 	if s.Enum != nil {
 		for _, e := range s.Enum {
 			switch e.Value.(type) {
 			case int, uint, float64:
 			default:
-				return stacktrace.New("enum value must be int, uint, float64", s.Location,
+				return stacktrace.New("enum value must be int, uint, float64", s.Location(),
 					stacktrace.WithPosition(&e.Position))
 			}
 		}
@@ -391,7 +586,7 @@ func (s *NumberShape) check() error {
 
 func (s *NumberShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 	if len(v)%2 != 0 {
-		return stacktrace.New("odd number of nodes", s.Location)
+		return stacktrace.New("odd number of nodes", s.Location())
 	}
 	for i := 0; i != len(v); i += 2 {
 		node := v[i]
@@ -399,36 +594,36 @@ func (s *NumberShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 		switch node.Value {
 		case FacetMinimum:
 			if err := valueNode.Decode(&s.Minimum); err != nil {
-				return StacktraceNewWrapped("decode minimum", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("decode minimum", err, s.Location(), WithNodePosition(valueNode))
 			}
 		case FacetMaximum:
 			if err := valueNode.Decode(&s.Maximum); err != nil {
-				return StacktraceNewWrapped("decode maximum", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("decode maximum", err, s.Location(), WithNodePosition(valueNode))
 			}
 		case FacetFormat:
 			if _, ok := SetOfNumberFormats[valueNode.Value]; !ok {
-				return stacktrace.New("invalid format", s.Location, WithNodePosition(valueNode),
+				return stacktrace.New("invalid format", s.Location(), WithNodePosition(valueNode),
 					stacktrace.WithInfo("allowed_formats", SetOfNumberFormats))
 			}
 			if err := valueNode.Decode(&s.Format); err != nil {
-				return StacktraceNewWrapped("decode format", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("decode format", err, s.Location(), WithNodePosition(valueNode))
 			}
 		case FacetEnum:
-			enums, err := s.raml.MakeEnum(valueNode, s.Location)
+			enums, err := s.raml.MakeEnum(valueNode, s.Location())
 			if err != nil {
-				return StacktraceNewWrapped("make enum", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("make enum", err, s.Location(), WithNodePosition(valueNode))
 			}
 			s.Enum = enums
 		case FacetMultipleOf:
 			if err := valueNode.Decode(&s.MultipleOf); err != nil {
-				return StacktraceNewWrapped("decode multipleOf", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("decode multipleOf", err, s.Location(), WithNodePosition(valueNode))
 			}
 		default:
-			n, err := s.raml.makeRootNode(valueNode, s.Location)
+			n, err := s.raml.makeRootNode(valueNode, s.Location())
 			if err != nil {
-				return StacktraceNewWrapped("make node", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
 			}
-			s.CustomShapeFacets.Set(node.Value, n)
+			s.setCustomShapeFacet(node.Value, n)
 		}
 	}
 	return nil
@@ -441,7 +636,7 @@ type LengthFacets struct {
 
 type StringFacets struct {
 	LengthFacets
-	Pattern *regexp.Regexp
+	Pattern Regexp
 }
 
 type StringShape struct {
@@ -467,7 +662,7 @@ func (s *StringShape) validate(v interface{}, _ string) error {
 		return fmt.Errorf("invalid type, got %T, expected string", v)
 	}
 
-	strLen := uint64(len(i))
+	strLen := countLength(i, s.stringLength())
 	if s.MinLength != nil && strLen < *s.MinLength {
 		return fmt.Errorf("length must be greater than %d", *s.MinLength)
 	}
@@ -496,7 +691,7 @@ func (s *StringShape) validate(v interface{}, _ string) error {
 func (s *StringShape) inherit(source Shape) (Shape, error) {
 	ss, ok := source.(*StringShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location,
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", source.Base().Type),
 			stacktrace.WithInfo("target", s.Base().Type))
@@ -504,30 +699,31 @@ func (s *StringShape) inherit(source Shape) (Shape, error) {
 	if s.MinLength == nil {
 		s.MinLength = ss.MinLength
 	} else if ss.MinLength != nil && *s.MinLength < *ss.MinLength {
-		return nil, stacktrace.New("minLength constraint violation", s.Location,
+		st := stacktrace.New("minLength constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", *ss.MinLength),
 			stacktrace.WithInfo("target", *s.MinLength))
+		if err := s.inheritConflict(st, func() { s.MinLength = ss.MinLength }); err != nil {
+			return nil, err
+		}
 	}
 	if s.MaxLength == nil {
 		s.MaxLength = ss.MaxLength
 	} else if ss.MaxLength != nil && *s.MaxLength > *ss.MaxLength {
-		return nil, stacktrace.New("maxLength constraint violation", s.Location,
+		st := stacktrace.New("maxLength constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", *ss.MaxLength),
 			stacktrace.WithInfo("target", *s.MaxLength))
+		if err := s.inheritConflict(st, func() { s.MaxLength = ss.MaxLength }); err != nil {
+			return nil, err
+		}
 	}
 	// FIXME: Patterns are merged unconditionally, but ideally they should be validated against intersection of their DFAs
 	if s.Pattern == nil {
 		s.Pattern = ss.Pattern
 	}
-	if s.Enum == nil {
-		s.Enum = ss.Enum
-	} else if ss.Enum != nil && !isCompatibleEnum(ss.Enum, s.Enum) {
-		return nil, stacktrace.New("enum constraint violation", s.Location,
-			stacktrace.WithPosition(&s.Position),
-			stacktrace.WithInfo("source", ss.Enum.String()),
-			stacktrace.WithInfo("target", s.Enum.String()))
+	if err := s.inheritEnum(&ss.EnumFacets, s.BaseShape); err != nil {
+		return nil, err
 	}
 	return s, nil
 }
@@ -535,13 +731,13 @@ func (s *StringShape) inherit(source Shape) (Shape, error) {
 func (s *StringShape) check() error {
 	if s.MinLength != nil && s.MaxLength != nil && *s.MinLength > *s.MaxLength {
 		return stacktrace.New("minLength must be less than or equal to maxLength",
-			s.Location, stacktrace.WithPosition(&s.Position))
+			s.Location(), stacktrace.WithPosition(&s.Position))
 	}
 	if s.Enum != nil {
 		for _, e := range s.Enum {
 			if _, ok := e.Value.(string); !ok {
 				return stacktrace.New("enum value must be string",
-					s.Location, stacktrace.WithPosition(&e.Position))
+					s.Location(), stacktrace.WithPosition(&e.Position))
 			}
 		}
 	}
@@ -550,7 +746,7 @@ func (s *StringShape) check() error {
 
 func (s *StringShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 	if len(v)%2 != 0 {
-		return stacktrace.New("odd number of nodes", s.Location)
+		return stacktrace.New("odd number of nodes", s.Location())
 	}
 	for i := 0; i != len(v); i += 2 {
 		node := v[i]
@@ -559,34 +755,34 @@ func (s *StringShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 		switch node.Value {
 		case FacetMinLength:
 			if err := valueNode.Decode(&s.MinLength); err != nil {
-				return StacktraceNewWrapped("decode minLength", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("decode minLength", err, s.Location(), WithNodePosition(valueNode))
 			}
 		case FacetMaxLength:
 			if err := valueNode.Decode(&s.MaxLength); err != nil {
-				return StacktraceNewWrapped("decode maxLength", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("decode maxLength", err, s.Location(), WithNodePosition(valueNode))
 			}
 		case FacetPattern:
 			if valueNode.Tag != TagStr {
-				return stacktrace.New("pattern must be string", s.Location, WithNodePosition(valueNode))
+				return stacktrace.New("pattern must be string", s.Location(), WithNodePosition(valueNode))
 			}
 
-			re, err := regexp.Compile(valueNode.Value)
+			re, err := s.raml.compilePattern(valueNode.Value, s.Location(), valueNode)
 			if err != nil {
-				return StacktraceNewWrapped("decode pattern", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("decode pattern", err, s.Location(), WithNodePosition(valueNode))
 			}
 			s.Pattern = re
 		case FacetEnum:
-			enums, err := s.raml.MakeEnum(valueNode, s.Location)
+			enums, err := s.raml.MakeEnum(valueNode, s.Location())
 			if err != nil {
-				return StacktraceNewWrapped("make enum", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("make enum", err, s.Location(), WithNodePosition(valueNode))
 			}
 			s.Enum = enums
 		default:
-			n, err := s.raml.makeRootNode(valueNode, s.Location)
+			n, err := s.raml.makeRootNode(valueNode, s.Location())
 			if err != nil {
-				return StacktraceNewWrapped("make node", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
 			}
-			s.CustomShapeFacets.Set(node.Value, n)
+			s.setCustomShapeFacet(node.Value, n)
 		}
 	}
 	return nil
@@ -613,29 +809,114 @@ func (s *FileShape) clone(base *BaseShape, _ map[int64]*BaseShape) Shape {
 	return &c
 }
 
+// FileContent describes a binary instance of a file type, e.g. a part of a multipart upload body.
+type FileContent struct {
+	// MediaType is the declared media type of the content, e.g. Content-Type of the multipart part.
+	// If empty, the media type is detected from the content.
+	MediaType string
+	// Reader provides the content. It is read to the end to determine the size.
+	Reader io.Reader
+}
+
+// fileSniffLen is the number of bytes used to detect the media type of the content.
+const fileSniffLen = 512
+
 func (s *FileShape) validate(v interface{}, _ string) error {
-	i, ok := v.(string)
-	if !ok {
-		return fmt.Errorf("invalid type, got %T, expected string", v)
+	switch i := v.(type) {
+	case string:
+		// NOTE: String instances come from examples and defaults, their content type is unknown.
+		return s.validateLength(uint64(len(i)))
+	case []byte:
+		if err := s.validateLength(uint64(len(i))); err != nil {
+			return err
+		}
+		return s.validateFileType(http.DetectContentType(i))
+	case FileContent:
+		return s.validateContent(i)
+	case *FileContent:
+		if i == nil {
+			return fmt.Errorf("file content is nil")
+		}
+		return s.validateContent(*i)
+	default:
+		return fmt.Errorf("invalid type, got %T, expected string, []byte or FileContent", v)
 	}
+}
 
-	// TODO: What is compared, byte size or base64 string size?
-	strLen := uint64(len(i))
-	if s.MinLength != nil && strLen < *s.MinLength {
+func (s *FileShape) validateContent(c FileContent) error {
+	if c.Reader == nil {
+		return fmt.Errorf("file content reader is nil")
+	}
+	head := make([]byte, fileSniffLen)
+	n, err := io.ReadFull(c.Reader, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("read file content: %w", err)
+	}
+	head = head[:n]
+	mediaType := c.MediaType
+	if mediaType == "" {
+		mediaType = http.DetectContentType(head)
+	}
+	if err = s.validateFileType(mediaType); err != nil {
+		return err
+	}
+	// The rest of the content is only counted, reading stops as soon as maxLength is exceeded.
+	rest := c.Reader
+	if s.MaxLength != nil {
+		rest = io.LimitReader(rest, int64(*s.MaxLength)+1)
+	}
+	size, err := io.Copy(io.Discard, rest)
+	if err != nil {
+		return fmt.Errorf("read file content: %w", err)
+	}
+	return s.validateLength(uint64(n) + uint64(size))
+}
+
+func (s *FileShape) validateLength(size uint64) error {
+	if s.MinLength != nil && size < *s.MinLength {
 		return fmt.Errorf("length must be greater than %d", *s.MinLength)
 	}
-	if s.MaxLength != nil && strLen > *s.MaxLength {
+	if s.MaxLength != nil && size > *s.MaxLength {
 		return fmt.Errorf("length must be less than %d", *s.MaxLength)
 	}
-	// TODO: Validation against file types
-
 	return nil
 }
 
+// validateFileType checks that the media type matches one of fileTypes, wildcards like "image/*" are supported.
+func (s *FileShape) validateFileType(mediaType string) error {
+	if s.FileTypes == nil {
+		return nil
+	}
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return fmt.Errorf("invalid media type %q: %w", mediaType, err)
+	}
+	for _, ft := range s.FileTypes {
+		pattern, _ := ft.Value.(string)
+		if matchMediaType(pattern, mt) {
+			return nil
+		}
+	}
+	return fmt.Errorf("media type %s must be one of: %s", mt, s.FileTypes.String())
+}
+
+// matchMediaType matches the media type against the pattern, e.g. "image/png" matches "image/*" and "*/*".
+func matchMediaType(pattern string, mediaType string) bool {
+	pt, _, err := mime.ParseMediaType(pattern)
+	if err != nil {
+		return false
+	}
+	if pt == "*/*" || pt == mediaType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(pt, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
 func (s *FileShape) inherit(source Shape) (Shape, error) {
 	ss, ok := source.(*FileShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location,
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", source.Base().Type),
 			stacktrace.WithInfo("target", s.Base().Type))
@@ -643,23 +924,29 @@ func (s *FileShape) inherit(source Shape) (Shape, error) {
 	if s.MinLength == nil {
 		s.MinLength = ss.MinLength
 	} else if ss.MinLength != nil && *s.MinLength < *ss.MinLength {
-		return nil, stacktrace.New("minLength constraint violation", s.Location,
+		st := stacktrace.New("minLength constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", *ss.MinLength),
 			stacktrace.WithInfo("target", *s.MinLength))
+		if err := s.inheritConflict(st, func() { s.MinLength = ss.MinLength }); err != nil {
+			return nil, err
+		}
 	}
 	if s.MaxLength == nil {
 		s.MaxLength = ss.MaxLength
 	} else if ss.MaxLength != nil && *s.MaxLength > *ss.MaxLength {
-		return nil, stacktrace.New("maxLength constraint violation", s.Location,
+		st := stacktrace.New("maxLength constraint violation", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", *ss.MaxLength),
 			stacktrace.WithInfo("target", *s.MaxLength))
+		if err := s.inheritConflict(st, func() { s.MaxLength = ss.MaxLength }); err != nil {
+			return nil, err
+		}
 	}
 	if s.FileTypes == nil {
 		s.FileTypes = ss.FileTypes
 	} else if ss.FileTypes != nil && !isCompatibleEnum(ss.FileTypes, s.FileTypes) {
-		return nil, stacktrace.New("file types are incompatible", s.Location,
+		return nil, stacktrace.New("file types are incompatible", s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", ss.FileTypes.String()),
 			stacktrace.WithInfo("target", s.FileTypes.String()))
@@ -669,15 +956,20 @@ func (s *FileShape) inherit(source Shape) (Shape, error) {
 
 func (s *FileShape) check() error {
 	if s.MinLength != nil && s.MaxLength != nil && *s.MinLength > *s.MaxLength {
-		return stacktrace.New("minLength must be less than or equal to maxLength", s.Location,
+		return stacktrace.New("minLength must be less than or equal to maxLength", s.Location(),
 			stacktrace.WithPosition(&s.Position))
 	}
 	if s.FileTypes != nil {
 		for _, e := range s.FileTypes {
-			if _, ok := e.Value.(string); !ok {
-				return stacktrace.New("file type must be string", s.Location,
+			ft, ok := e.Value.(string)
+			if !ok {
+				return stacktrace.New("file type must be string", s.Location(),
 					stacktrace.WithPosition(&s.Position))
 			}
+			if _, _, err := mime.ParseMediaType(ft); err != nil {
+				return stacktrace.New("file type must be a valid media type", s.Location(),
+					stacktrace.WithPosition(&s.Position), stacktrace.WithInfo("file_type", ft))
+			}
 		}
 	}
 	return nil
@@ -685,7 +977,7 @@ func (s *FileShape) check() error {
 
 func (s *FileShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 	if len(v)%2 != 0 {
-		return stacktrace.New("odd number of nodes", s.Location)
+		return stacktrace.New("odd number of nodes", s.Location())
 	}
 	for i := 0; i != len(v); i += 2 {
 		node := v[i]
@@ -694,34 +986,34 @@ func (s *FileShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 		switch node.Value {
 		case FacetMinLength:
 			if err := valueNode.Decode(&s.MinLength); err != nil {
-				return StacktraceNewWrapped("decode minLength", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("decode minLength", err, s.Location(), WithNodePosition(valueNode))
 			}
 		case FacetMaxLength:
 			if err := valueNode.Decode(&s.MaxLength); err != nil {
-				return StacktraceNewWrapped("decode maxLength", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("decode maxLength", err, s.Location(), WithNodePosition(valueNode))
 			}
 		case FacetFileTypes:
 			if valueNode.Kind != yaml.SequenceNode {
-				return stacktrace.New("fileTypes must be sequence node", s.Location, WithNodePosition(valueNode))
+				return stacktrace.New("fileTypes must be sequence node", s.Location(), WithNodePosition(valueNode))
 			}
 			fileTypes := make(Nodes, len(valueNode.Content))
 			for i, v := range valueNode.Content {
 				if v.Tag != "!!str" {
-					return stacktrace.New("member of fileTypes must be string", s.Location, WithNodePosition(v))
+					return stacktrace.New("member of fileTypes must be string", s.Location(), WithNodePosition(v))
 				}
-				n, err := s.raml.makeRootNode(v, s.Location)
+				n, err := s.raml.makeRootNode(v, s.Location())
 				if err != nil {
-					return StacktraceNewWrapped("make node fileTypes", err, s.Location, WithNodePosition(v))
+					return StacktraceNewWrapped("make node fileTypes", err, s.Location(), WithNodePosition(v))
 				}
 				fileTypes[i] = n
 			}
 			s.FileTypes = fileTypes
 		default:
-			n, err := s.raml.makeRootNode(valueNode, s.Location)
+			n, err := s.raml.makeRootNode(valueNode, s.Location())
 			if err != nil {
-				return StacktraceNewWrapped("make node", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
 			}
-			s.CustomShapeFacets.Set(node.Value, n)
+			s.setCustomShapeFacet(node.Value, n)
 		}
 	}
 	return nil
@@ -768,14 +1060,11 @@ func (s *BooleanShape) validate(v interface{}, _ string) error {
 func (s *BooleanShape) inherit(source Shape) (Shape, error) {
 	ss, ok := source.(*BooleanShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location, stacktrace.WithPosition(&s.Position),
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(), stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", source.Base().Type), stacktrace.WithInfo("target", s.Base().Type))
 	}
-	if s.Enum == nil {
-		s.Enum = ss.Enum
-	} else if ss.Enum != nil && !isCompatibleEnum(ss.Enum, s.Enum) {
-		return nil, stacktrace.New("enum constraint violation", s.Location, stacktrace.WithPosition(&s.Position),
-			stacktrace.WithInfo("source", ss.Enum.String()), stacktrace.WithInfo("target", s.Enum.String()))
+	if err := s.inheritEnum(&ss.EnumFacets, s.BaseShape); err != nil {
+		return nil, err
 	}
 	return s, nil
 }
@@ -784,7 +1073,7 @@ func (s *BooleanShape) check() error {
 	if s.Enum != nil {
 		for _, e := range s.Enum {
 			if _, ok := e.Value.(bool); !ok {
-				return stacktrace.New("enum value must be boolean", s.Location, stacktrace.WithPosition(&e.Position))
+				return stacktrace.New("enum value must be boolean", s.Location(), stacktrace.WithPosition(&e.Position))
 			}
 		}
 	}
@@ -797,17 +1086,17 @@ func (s *BooleanShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 		valueNode := v[i+1]
 
 		if node.Value == "enum" {
-			enums, err := s.raml.MakeEnum(valueNode, s.Location)
+			enums, err := s.raml.MakeEnum(valueNode, s.Location())
 			if err != nil {
-				return StacktraceNewWrapped("make enum", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("make enum", err, s.Location(), WithNodePosition(valueNode))
 			}
 			s.Enum = enums
 		} else {
-			n, err := s.raml.makeRootNode(valueNode, s.Location)
+			n, err := s.raml.makeRootNode(valueNode, s.Location())
 			if err != nil {
-				return StacktraceNewWrapped("make node", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
 			}
-			s.CustomShapeFacets.Set(node.Value, n)
+			s.setCustomShapeFacet(node.Value, n)
 		}
 	}
 
@@ -817,6 +1106,7 @@ func (s *BooleanShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 type DateTimeShape struct {
 	*BaseShape
 
+	EnumFacets
 	FormatFacets
 }
 
@@ -836,69 +1126,70 @@ func (s *DateTimeShape) validate(v interface{}, _ string) error {
 		return fmt.Errorf("invalid type, got %T, expected string", v)
 	}
 
-	if s.Format == nil {
-		if _, err := time.Parse(time.RFC3339, i); err != nil {
-			return fmt.Errorf("value must match format %s", time.RFC3339)
-		}
-	} else {
-		switch *s.Format {
-		case DateTimeFormatRFC3339:
-			if _, err := time.Parse(time.RFC3339, i); err != nil {
-				return fmt.Errorf("value must match format %s", time.RFC3339)
-			}
-		// TODO: https://www.rfc-editor.org/rfc/rfc7231#section-7.1.1.1
-		case DateTimeFormatRFC2616:
-			if _, err := time.Parse(RFC2616, i); err != nil {
-				return fmt.Errorf("value must match format %s", RFC2616)
-			}
+	if s.Format != nil && *s.Format == DateTimeFormatRFC2616 {
+		if err := validateRFC2616(i, s.fractionalSeconds()); err != nil {
+			return err
 		}
+	} else if err := validateRFC3339(i, s.fractionalSeconds()); err != nil {
+		return err
 	}
-
-	return nil
+	return s.validateEnum(i)
 }
 
 func (s *DateTimeShape) inherit(source Shape) (Shape, error) {
 	ss, ok := source.(*DateTimeShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location, stacktrace.WithPosition(&s.Position),
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(), stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", source.Base().Type),
 			stacktrace.WithInfo("target", s.Base().Type))
 	}
 	if s.Format == nil {
 		s.Format = ss.Format
 	} else if ss.Format != nil && *s.Format != *ss.Format {
-		return nil, stacktrace.New("format constraint violation", s.Location, stacktrace.WithPosition(&s.Position),
+		st := stacktrace.New("format constraint violation", s.Location(), stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", *ss.Format), stacktrace.WithInfo("target", *s.Format))
+		if err := s.inheritConflict(st, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.inheritEnum(&ss.EnumFacets, s.BaseShape); err != nil {
+		return nil, err
 	}
 	return s, nil
 }
 
 func (s *DateTimeShape) check() error {
-	return nil
+	return checkEnumValues(s, s.Enum)
 }
 
 func (s *DateTimeShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 	for i := 0; i != len(v); i += 2 {
 		if i+1 >= len(v) {
-			return stacktrace.New("missing value", s.Location)
+			return stacktrace.New("missing value", s.Location())
 		}
 		node := v[i]
 		valueNode := v[i+1]
 		if node.Value == "format" {
 			if _, ok := SetOfDateTimeFormats[valueNode.Value]; !ok {
-				return stacktrace.New("invalid format", s.Location, WithNodePosition(valueNode),
-					stacktrace.WithInfo("allowed_formats", SetOfNumberFormats))
+				return stacktrace.New("invalid format", s.Location(), WithNodePosition(valueNode),
+					stacktrace.WithInfo("allowed_formats", SetOfDateTimeFormats))
 			}
 
 			if err := valueNode.Decode(&s.Format); err != nil {
-				return StacktraceNewWrapped("decode format", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("decode format", err, s.Location(), WithNodePosition(valueNode))
+			}
+		} else if node.Value == FacetEnum {
+			enums, err := s.raml.MakeEnum(valueNode, s.Location())
+			if err != nil {
+				return StacktraceNewWrapped("make enum", err, s.Location(), WithNodePosition(valueNode))
 			}
+			s.Enum = enums
 		} else {
-			n, err := s.raml.makeRootNode(valueNode, s.Location)
+			n, err := s.raml.makeRootNode(valueNode, s.Location())
 			if err != nil {
-				return StacktraceNewWrapped("make node", err, s.Location, WithNodePosition(valueNode))
+				return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
 			}
-			s.CustomShapeFacets.Set(node.Value, n)
+			s.setCustomShapeFacet(node.Value, n)
 		}
 	}
 	return nil
@@ -906,6 +1197,8 @@ func (s *DateTimeShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 
 type DateTimeOnlyShape struct {
 	*BaseShape
+
+	EnumFacets
 }
 
 func (s *DateTimeOnlyShape) Base() *BaseShape {
@@ -924,45 +1217,57 @@ func (s *DateTimeOnlyShape) validate(v interface{}, _ string) error {
 		return fmt.Errorf("invalid type, got %T, expected string", v)
 	}
 
-	if _, err := time.Parse(DateTime, i); err != nil {
-		return fmt.Errorf("value must match format %s", DateTime)
+	if err := validateDateTimeOnly(i, s.fractionalSeconds()); err != nil {
+		return err
 	}
-
-	return nil
+	return s.validateEnum(i)
 }
 
 func (s *DateTimeOnlyShape) inherit(source Shape) (Shape, error) {
-	_, ok := source.(*DateTimeOnlyShape)
+	ss, ok := source.(*DateTimeOnlyShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location, stacktrace.WithPosition(&s.Position),
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(), stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", source.Base().Type), stacktrace.WithInfo("target", s.Base().Type))
 	}
+	if err := s.inheritEnum(&ss.EnumFacets, s.BaseShape); err != nil {
+		return nil, err
+	}
 	return s, nil
 }
 
 func (s *DateTimeOnlyShape) check() error {
-	return nil
+	return checkEnumValues(s, s.Enum)
 }
 
 func (s *DateTimeOnlyShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 	if len(v)%2 != 0 {
-		return stacktrace.New("odd number of nodes", s.Location)
+		return stacktrace.New("odd number of nodes", s.Location())
 	}
 	for i := 0; i != len(v); i += 2 {
 		node := v[i]
 		valueNode := v[i+1]
 
-		n, err := s.raml.makeRootNode(valueNode, s.Location)
+		if node.Value == FacetEnum {
+			enums, err := s.raml.MakeEnum(valueNode, s.Location())
+			if err != nil {
+				return StacktraceNewWrapped("make enum", err, s.Location(), WithNodePosition(valueNode))
+			}
+			s.Enum = enums
+			continue
+		}
+		n, err := s.raml.makeRootNode(valueNode, s.Location())
 		if err != nil {
-			return StacktraceNewWrapped("make node", err, s.Location, WithNodePosition(valueNode))
+			return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
 		}
-		s.CustomShapeFacets.Set(node.Value, n)
+		s.setCustomShapeFacet(node.Value, n)
 	}
 	return nil
 }
 
 type DateOnlyShape struct {
 	*BaseShape
+
+	EnumFacets
 }
 
 func (s *DateOnlyShape) Base() *BaseShape {
@@ -981,24 +1286,26 @@ func (s *DateOnlyShape) validate(v interface{}, _ string) error {
 		return fmt.Errorf("invalid type, got %T, expected string", v)
 	}
 
-	if _, err := time.Parse(time.DateOnly, i); err != nil {
-		return fmt.Errorf("value must match format %s", time.DateOnly)
+	if err := validateDateOnly(i); err != nil {
+		return err
 	}
-
-	return nil
+	return s.validateEnum(i)
 }
 
 func (s *DateOnlyShape) inherit(source Shape) (Shape, error) {
-	_, ok := source.(*DateOnlyShape)
+	ss, ok := source.(*DateOnlyShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location, stacktrace.WithPosition(&s.Position),
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(), stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", source.Base().Type), stacktrace.WithInfo("target", s.Base().Type))
 	}
+	if err := s.inheritEnum(&ss.EnumFacets, s.BaseShape); err != nil {
+		return nil, err
+	}
 	return s, nil
 }
 
 func (s *DateOnlyShape) check() error {
-	return nil
+	return checkEnumValues(s, s.Enum)
 }
 
 func (s *DateOnlyShape) unmarshalYAMLNodes(v []*yaml.Node) error {
@@ -1006,17 +1313,27 @@ func (s *DateOnlyShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 		node := v[i]
 		valueNode := v[i+1]
 
-		n, err := s.raml.makeRootNode(valueNode, s.Location)
+		if node.Value == FacetEnum {
+			enums, err := s.raml.MakeEnum(valueNode, s.Location())
+			if err != nil {
+				return StacktraceNewWrapped("make enum", err, s.Location(), WithNodePosition(valueNode))
+			}
+			s.Enum = enums
+			continue
+		}
+		n, err := s.raml.makeRootNode(valueNode, s.Location())
 		if err != nil {
-			return StacktraceNewWrapped("make node", err, s.Location, WithNodePosition(valueNode))
+			return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
 		}
-		s.CustomShapeFacets.Set(node.Value, n)
+		s.setCustomShapeFacet(node.Value, n)
 	}
 	return nil
 }
 
 type TimeOnlyShape struct {
 	*BaseShape
+
+	EnumFacets
 }
 
 func (s *TimeOnlyShape) Base() *BaseShape {
@@ -1035,39 +1352,49 @@ func (s *TimeOnlyShape) validate(v interface{}, _ string) error {
 		return fmt.Errorf("invalid type, got %T, expected string", v)
 	}
 
-	if _, err := time.Parse(time.TimeOnly, i); err != nil {
-		return fmt.Errorf("value must match format %s", time.TimeOnly)
+	if err := validateTimeOnly(i, s.fractionalSeconds()); err != nil {
+		return err
 	}
-
-	return nil
+	return s.validateEnum(i)
 }
 
 func (s *TimeOnlyShape) inherit(source Shape) (Shape, error) {
-	_, ok := source.(*TimeOnlyShape)
+	ss, ok := source.(*TimeOnlyShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location, stacktrace.WithPosition(&s.Position),
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(), stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", source.Base().Type), stacktrace.WithInfo("target", s.Base().Type))
 	}
+	if err := s.inheritEnum(&ss.EnumFacets, s.BaseShape); err != nil {
+		return nil, err
+	}
 	return s, nil
 }
 
 func (s *TimeOnlyShape) check() error {
-	return nil
+	return checkEnumValues(s, s.Enum)
 }
 
 func (s *TimeOnlyShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 	if len(v)%2 != 0 {
-		return stacktrace.New("odd number of nodes", s.Location)
+		return stacktrace.New("odd number of nodes", s.Location())
 	}
 	for i := 0; i != len(v); i += 2 {
 		node := v[i]
 		valueNode := v[i+1]
 
-		n, err := s.raml.makeRootNode(valueNode, s.Location)
+		if node.Value == FacetEnum {
+			enums, err := s.raml.MakeEnum(valueNode, s.Location())
+			if err != nil {
+				return StacktraceNewWrapped("make enum", err, s.Location(), WithNodePosition(valueNode))
+			}
+			s.Enum = enums
+			continue
+		}
+		n, err := s.raml.makeRootNode(valueNode, s.Location())
 		if err != nil {
-			return StacktraceNewWrapped("make node", err, s.Location, WithNodePosition(valueNode))
+			return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
 		}
-		s.CustomShapeFacets.Set(node.Value, n)
+		s.setCustomShapeFacet(node.Value, n)
 	}
 	return nil
 }
@@ -1094,7 +1421,7 @@ func (s *AnyShape) validate(_ interface{}, _ string) error {
 func (s *AnyShape) inherit(source Shape) (Shape, error) {
 	_, ok := source.(*AnyShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location, stacktrace.WithPosition(&s.Position),
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(), stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", source.Base().Type), stacktrace.WithInfo("target", s.Base().Type))
 	}
 	return s, nil
@@ -1106,17 +1433,17 @@ func (s *AnyShape) check() error {
 
 func (s *AnyShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 	if len(v)%2 != 0 {
-		return stacktrace.New("odd number of nodes", s.Location)
+		return stacktrace.New("odd number of nodes", s.Location())
 	}
 	for i := 0; i != len(v); i += 2 {
 		node := v[i]
 		valueNode := v[i+1]
 
-		n, err := s.raml.makeRootNode(valueNode, s.Location)
+		n, err := s.raml.makeRootNode(valueNode, s.Location())
 		if err != nil {
-			return StacktraceNewWrapped("make node", err, s.Location, WithNodePosition(valueNode))
+			return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
 		}
-		s.CustomShapeFacets.Set(node.Value, n)
+		s.setCustomShapeFacet(node.Value, n)
 	}
 	return nil
 }
@@ -1146,7 +1473,7 @@ func (s *NilShape) validate(v interface{}, _ string) error {
 func (s *NilShape) inherit(source Shape) (Shape, error) {
 	_, ok := source.(*NilShape)
 	if !ok {
-		return nil, stacktrace.New("cannot inherit from different type", s.Location, stacktrace.WithPosition(&s.Position),
+		return nil, stacktrace.New("cannot inherit from different type", s.Location(), stacktrace.WithPosition(&s.Position),
 			stacktrace.WithInfo("source", source.Base().Type), stacktrace.WithInfo("target", s.Base().Type))
 	}
 	return s, nil
@@ -1158,17 +1485,17 @@ func (s *NilShape) check() error {
 
 func (s *NilShape) unmarshalYAMLNodes(v []*yaml.Node) error {
 	if len(v)%2 != 0 {
-		return stacktrace.New("odd number of nodes", s.Location)
+		return stacktrace.New("odd number of nodes", s.Location())
 	}
 	for i := 0; i != len(v); i += 2 {
 		node := v[i]
 		valueNode := v[i+1]
 
-		n, err := s.raml.makeRootNode(valueNode, s.Location)
+		n, err := s.raml.makeRootNode(valueNode, s.Location())
 		if err != nil {
-			return StacktraceNewWrapped("make node", err, s.Location, WithNodePosition(valueNode))
+			return StacktraceNewWrapped("make node", err, s.Location(), WithNodePosition(valueNode))
 		}
-		s.CustomShapeFacets.Set(node.Value, n)
+		s.setCustomShapeFacet(node.Value, n)
 	}
 	return nil
 }
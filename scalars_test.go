@@ -6,6 +6,7 @@ import (
 	"math/big"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 
 	orderedmap "github.com/wk8/go-ordered-map/v2"
@@ -2499,6 +2500,85 @@ func TestFileShape_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid bytes with file types",
+			fields: fields{
+				BaseShape: &BaseShape{},
+				FileFacets: FileFacets{
+					FileTypes: Nodes{{Value: "image/*"}, {Value: "application/pdf"}},
+				},
+			},
+			args: args{
+				v:   []byte("\x89PNG\r\n\x1a\n"),
+				in1: "test",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid bytes file type",
+			fields: fields{
+				BaseShape: &BaseShape{},
+				FileFacets: FileFacets{
+					FileTypes: Nodes{{Value: "image/*"}},
+				},
+			},
+			args: args{
+				v:   []byte("plain text"),
+				in1: "test",
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid file content with declared media type",
+			fields: fields{
+				BaseShape: &BaseShape{},
+				LengthFacets: LengthFacets{
+					MaxLength: func() *uint64 {
+						i := uint64(10)
+						return &i
+					}(),
+				},
+				FileFacets: FileFacets{
+					FileTypes: Nodes{{Value: "application/json"}},
+				},
+			},
+			args: args{
+				v:   FileContent{MediaType: "application/json; charset=utf-8", Reader: strings.NewReader(`{"a": 1}`)},
+				in1: "test",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid file content length",
+			fields: fields{
+				BaseShape: &BaseShape{},
+				LengthFacets: LengthFacets{
+					MaxLength: func() *uint64 {
+						i := uint64(1024)
+						return &i
+					}(),
+				},
+			},
+			args: args{
+				v:   &FileContent{Reader: strings.NewReader(strings.Repeat("a", 2048))},
+				in1: "test",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid file content media type",
+			fields: fields{
+				BaseShape: &BaseShape{},
+				FileFacets: FileFacets{
+					FileTypes: Nodes{{Value: "image/png"}},
+				},
+			},
+			args: args{
+				v:   FileContent{MediaType: "image/jpeg", Reader: strings.NewReader("")},
+				in1: "test",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -4829,3 +4909,250 @@ func TestNilShape_unmarshalYAMLNodes(t *testing.T) {
 		})
 	}
 }
+
+func TestNumericShapes_MultipleOfAndExclusiveBounds(t *testing.T) {
+	content := `#%RAML 1.0 Library
+annotationTypes:
+  exclusiveMinimum: boolean
+  exclusiveMaximum: boolean
+types:
+  Price:
+    type: number
+    multipleOf: 0.01
+  Percent:
+    type: number
+    minimum: 0
+    maximum: 100
+    (exclusiveMinimum): true
+  Even:
+    type: integer
+    multipleOf: 2
+    maximum: 10
+    (exclusiveMaximum): true
+  FourTimes:
+    type: Even
+    multipleOf: 4
+`
+	tests := []struct {
+		name     string
+		typeName string
+		value    any
+		wantErr  string
+	}{
+		{name: "float multiple", typeName: "Price", value: 19.99},
+		{name: "float multiple with rounding error", typeName: "Price", value: 0.3},
+		{name: "float not multiple", typeName: "Price", value: 0.001, wantErr: "value must be a multiple of 0.01"},
+		{name: "integer multiple of number", typeName: "Price", value: 3},
+		{name: "exclusive minimum", typeName: "Percent", value: 0.0, wantErr: "value must be strictly greater than"},
+		{name: "inclusive maximum", typeName: "Percent", value: 100},
+		{name: "integer multiple", typeName: "Even", value: 4},
+		{name: "integer not multiple", typeName: "Even", value: 3, wantErr: "value must be a multiple of 2"},
+		{name: "exclusive integer maximum", typeName: "Even", value: 10, wantErr: "value must be strictly less than 10"},
+		{name: "inherited multiple", typeName: "FourTimes", value: 6, wantErr: "value must be a multiple of 4"},
+	}
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap(), OptWithValidate())
+	if err != nil {
+		t.Fatalf("ParseFromString() error = %v", err)
+	}
+	lib := rml.EntryPoint().(*Library)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ok := lib.Types.Get(tt.typeName)
+			if !ok {
+				t.Fatalf("type %s not found", tt.typeName)
+			}
+			err := s.Validate(tt.value)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Validate() error = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNumericShapes_MultipleOfCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		types   string
+		wantErr string
+	}{
+		{
+			name: "zero multipleOf",
+			types: `
+  A:
+    type: number
+    multipleOf: 0`,
+			wantErr: "multipleOf must be a finite number greater than 0",
+		},
+		{
+			name: "negative integer multipleOf",
+			types: `
+  A:
+    type: integer
+    multipleOf: -2`,
+			wantErr: "multipleOf must be a finite number greater than 0",
+		},
+		{
+			name: "multipleOf is not a multiple of the parent",
+			types: `
+  A:
+    type: number
+    multipleOf: 0.5
+  B:
+    type: A
+    multipleOf: 0.75`,
+			wantErr: "multipleOf constraint violation",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFromString("#%RAML 1.0 Library\ntypes:"+tt.types, "library.raml", t.TempDir(),
+				OptWithValidate())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFromString() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnumShapes_Narrowing(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Status:
+    type: string | integer
+    enum: [active, 1, 2]
+  Active:
+    type: Status
+    enum: [active, 2]
+  Holiday:
+    type: date-only
+    enum: [2024-12-25, 2025-01-01]
+  Christmas:
+    type: Holiday
+  Level:
+    type: integer
+    enum: [1, 2, 3]
+  Mode:
+    type: string
+  Setting:
+    type: Level | Mode
+    enum: [2, auto]
+`
+	tests := []struct {
+		name     string
+		typeName string
+		value    any
+		wantErr  string
+	}{
+		{name: "union enum string", typeName: "Status", value: "active"},
+		{name: "union enum integer", typeName: "Status", value: 1},
+		{name: "union enum integer decoded from JSON", typeName: "Status", value: 2.0},
+		{name: "value of member is not in union enum", typeName: "Status", value: "inactive", wantErr: "value must be one of"},
+		{name: "narrowed union enum", typeName: "Active", value: 1, wantErr: "value must be one of (active, 2)"},
+		{name: "date enum", typeName: "Holiday", value: "2025-01-01"},
+		{name: "date not in enum", typeName: "Holiday", value: "2025-01-02", wantErr: "value must be one of"},
+		{name: "inherited date enum", typeName: "Christmas", value: "2025-01-02", wantErr: "value must be one of"},
+		{name: "union enum intersects member enum", typeName: "Setting", value: 2},
+		{name: "member enum value not in union enum", typeName: "Setting", value: 3, wantErr: "value must be one of"},
+	}
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap(), OptWithValidate())
+	if err != nil {
+		t.Fatalf("ParseFromString() error = %v", err)
+	}
+	lib := rml.EntryPoint().(*Library)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ok := lib.Types.Get(tt.typeName)
+			if !ok {
+				t.Fatalf("type %s not found", tt.typeName)
+			}
+			err := s.Validate(tt.value)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Validate() error = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnumShapes_NarrowingViolation(t *testing.T) {
+	tests := []struct {
+		name    string
+		types   string
+		wantErr string
+	}{
+		{
+			name: "string enum is not a subset",
+			types: `
+  A:
+    type: string
+    enum: [a, b]
+  B:
+    type: A
+    enum: [a, c]`,
+			wantErr: "library.raml:8:15: enum constraint violation",
+		},
+		{
+			name: "time enum is not a subset",
+			types: `
+  A:
+    type: time-only
+    enum: ["10:00:00"]
+  B:
+    type: A
+    enum: ["11:00:00"]`,
+			wantErr: "enum constraint violation",
+		},
+		{
+			name: "invalid date enum value",
+			types: `
+  A:
+    type: datetime
+    enum: [yesterday]`,
+			wantErr: "invalid enum value",
+		},
+		{
+			name: "union enum is not a subset",
+			types: `
+  A:
+    type: string | integer
+    enum: [a, 1]
+  B:
+    type: A
+    enum: [a, 2]`,
+			wantErr: "library.raml:8:15: enum constraint violation",
+		},
+		{
+			name: "union enum value matches no member",
+			types: `
+  A:
+    type: string | integer
+    enum: [a, true]`,
+			wantErr: "enum value does not match any union member",
+		},
+		{
+			name: "union enum value is not in member enum",
+			types: `
+  A:
+    type: integer
+    enum: [1, 2]
+  B:
+    type: A | nil
+    enum: [3]`,
+			wantErr: "enum value does not match any union member",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFromString("#%RAML 1.0 Library\ntypes:"+tt.types, "library.raml", t.TempDir(),
+				OptWithValidate())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseFromString() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
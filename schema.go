@@ -43,6 +43,8 @@ type JSONSchema struct {
 	MultipleOf       json.Number `json:"multipleOf,omitempty"`
 	Maximum          json.Number `json:"maximum,omitempty"`
 	Minimum          json.Number `json:"minimum,omitempty"`
+	ExclusiveMaximum json.Number `json:"exclusiveMaximum,omitempty"`
+	ExclusiveMinimum json.Number `json:"exclusiveMinimum,omitempty"`
 	MaxLength        *uint64     `json:"maxLength,omitempty"`
 	MinLength        *uint64     `json:"minLength,omitempty"`
 	Pattern          string      `json:"pattern,omitempty"`
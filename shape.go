@@ -3,7 +3,6 @@ package raml
 import (
 	"encoding/json"
 	"fmt"
-	"path/filepath"
 
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 	"gopkg.in/yaml.v3"
@@ -53,6 +52,10 @@ type BaseShape struct {
 	Shape     Shape
 	Default   *Node
 	Required  *bool
+	// XML describes the serialization of the instance to XML
+	XML *XML
+	// Comments are YAML comments adjacent to the declaration of the type or property, nil if there are none.
+	Comments *Comments
 
 	// To support !include of DataType fragment
 	Link *DataType
@@ -66,13 +69,23 @@ type BaseShape struct {
 
 	// Controlled by UnwrapShape
 	unwrapped bool
+	// mergedParent is set on copies of parents that are being merged by multiple inheritance.
+	mergedParent bool
 	// NOTE: Not thread safe and should be used only in one method simultaneously.
 	ShapeVisited bool
 
 	raml *RAML
 
-	Location string
+	// LocationID is the handle of the location of the fragment the shape is declared in, see Location.
+	LocationID LocationID
 	stacktrace.Position
+	// rawNode is the YAML node the shape was decoded from, see RawNode.
+	rawNode *yaml.Node
+}
+
+// Location returns the location of the fragment the shape is declared in.
+func (s *BaseShape) Location() string {
+	return s.raml.LocationByID(s.LocationID)
 }
 
 func (s *BaseShape) SetShape(shape Shape) {
@@ -80,20 +93,28 @@ func (s *BaseShape) SetShape(shape Shape) {
 }
 
 func (s *BaseShape) Validate(v interface{}) error {
-	return s.Shape.validate(v, "$")
+	start := s.raml.validationStart()
+	st := newValidationState(ValidateOptions{})
+	defer st.release()
+	err := validateValue(s.Shape, v, st)
+	s.raml.validationDone(start, err)
+	return err
 }
 
 func (s *BaseShape) Inherit(sourceBase *BaseShape) (*BaseShape, error) {
+	if s.XML == nil {
+		s.XML = sourceBase.XML
+	}
 	for pair := sourceBase.CustomShapeFacets.Oldest(); pair != nil; pair = pair.Next() {
 		k, item := pair.Key, pair.Value
-		if _, ok := s.CustomShapeFacets.Get(k); !ok {
-			s.CustomShapeFacets.Set(k, item)
+		if _, ok := orderedGet(s.CustomShapeFacets, k); !ok {
+			orderedSet(&s.CustomShapeFacets, k, item)
 		}
 	}
 	for pair := sourceBase.CustomDomainProperties.Oldest(); pair != nil; pair = pair.Next() {
 		k, item := pair.Key, pair.Value
-		if _, ok := s.CustomDomainProperties.Get(k); !ok {
-			s.CustomDomainProperties.Set(k, item)
+		if _, ok := orderedGet(s.CustomDomainProperties, k); !ok {
+			orderedSet(&s.CustomDomainProperties, k, item)
 		}
 	}
 
@@ -110,21 +131,32 @@ func (s *BaseShape) Inherit(sourceBase *BaseShape) (*BaseShape, error) {
 
 	switch {
 	case isSourceUnion && !isTargetUnion:
+		s.raml.logInherit(s, sourceBase, "union parent")
 		return s.inheritUnionSource(sourceUnion)
 
 	case isTargetUnion && !isSourceUnion:
+		s.raml.logInherit(s, sourceBase, "union subtype")
 		return s.inheritUnionTarget(targetUnion)
 	}
+	s.raml.logInherit(s, sourceBase, "merge")
 	// Homogenous types produce same type
 	_, err := target.inherit(source)
 	if err != nil {
-		return nil, StacktraceNewWrapped("merge shapes", err, target.Base().Location,
+		return nil, StacktraceNewWrapped("merge shapes", err, target.Base().Location(),
 			stacktrace.WithPosition(&target.Base().Position))
 	}
 	return s, nil
 }
 
 func (s *BaseShape) inheritUnionSource(sourceUnion *UnionShape) (*BaseShape, error) {
+	// Enum of the target must be a subset of the enum of the union.
+	enum := sourceUnion.Enum
+	if es, ok := s.Shape.(enumShape); ok && es.enumFacets().Enum != nil {
+		if err := es.enumFacets().inheritEnum(&sourceUnion.EnumFacets, s); err != nil {
+			return nil, err
+		}
+		enum = es.enumFacets().Enum
+	}
 	var filtered []*BaseShape
 	var st *stacktrace.StackTrace
 	for _, source := range sourceUnion.AnyOf {
@@ -135,12 +167,11 @@ func (s *BaseShape) inheritUnionSource(sourceUnion *UnionShape) (*BaseShape, err
 		if source.Type == s.Type {
 			// Deep copy with ID change is required since we create new union members from source members
 			tc := s.CloneDetached()
-			tc.ID = generateShapeID()
+			tc.ID = s.raml.newCopyID(s.ID, source.ID)
 			// TODO: Probably all copied shapes must change IDs since these are actually new shapes.
-			// tc.ID = generateShapeID()
 			is, err := tc.Inherit(source)
 			if err != nil {
-				se := StacktraceNewWrapped("merge shapes", err, s.Location,
+				se := StacktraceNewWrapped("merge shapes", err, s.Location(),
 					stacktrace.WithPosition(&s.Position))
 				if st == nil {
 					st = se
@@ -150,11 +181,15 @@ func (s *BaseShape) inheritUnionSource(sourceUnion *UnionShape) (*BaseShape, err
 				// Skip shapes that didn't pass inheritance check
 				continue
 			}
+			// Skip shapes that accept no value of the union enum
+			if !is.intersectEnum(enum) {
+				continue
+			}
 			filtered = append(filtered, is)
 		}
 	}
 	if len(filtered) == 0 {
-		se := stacktrace.New("failed to find compatible union member", s.Location,
+		se := stacktrace.New("failed to find compatible union member", s.Location(),
 			stacktrace.WithPosition(&s.Position))
 		if st != nil {
 			se = se.Append(st)
@@ -169,6 +204,9 @@ func (s *BaseShape) inheritUnionSource(sourceUnion *UnionShape) (*BaseShape, err
 	s.Type = TypeUnion
 	s.SetShape(&UnionShape{
 		BaseShape: s,
+		EnumFacets: EnumFacets{
+			Enum: enum,
+		},
 		UnionFacets: UnionFacets{
 			AnyOf: filtered,
 		},
@@ -177,12 +215,18 @@ func (s *BaseShape) inheritUnionSource(sourceUnion *UnionShape) (*BaseShape, err
 }
 
 func (s *BaseShape) inheritUnionTarget(targetUnion *UnionShape) (*BaseShape, error) {
+	// Enum of the union must be a subset of the enum of the source.
+	if es, ok := s.Shape.(enumShape); ok {
+		if err := targetUnion.inheritEnum(es.enumFacets(), targetUnion.BaseShape); err != nil {
+			return nil, err
+		}
+	}
 	var st *stacktrace.StackTrace
 	for _, item := range targetUnion.AnyOf {
 		// Merge will raise an error in case any of union members has incompatible type
 		_, err := item.Inherit(s)
 		if err != nil {
-			se := StacktraceNewWrapped("merge shapes", err, targetUnion.Base().Location,
+			se := StacktraceNewWrapped("merge shapes", err, targetUnion.Base().Location(),
 				stacktrace.WithPosition(&targetUnion.Base().Position))
 			if st == nil {
 				st = se
@@ -244,23 +288,30 @@ func (s *BaseShape) clone(clonedMap map[int64]*BaseShape) *BaseShape {
 	c := *s
 	clonedMap[s.ID] = &c
 
+	// Shared empty maps of low-memory mode are not copied.
 	// TODO: Node is not deep copied yet, but it's not mutated anyway
-	c.CustomShapeFacets = orderedmap.New[string, *Node](s.CustomShapeFacets.Len())
-	for pair := s.CustomShapeFacets.Oldest(); pair != nil; pair = pair.Next() {
-		c.CustomShapeFacets.Set(pair.Key, pair.Value)
+	if s.CustomShapeFacets != nil && !isSharedEmpty(s.CustomShapeFacets) {
+		c.CustomShapeFacets = orderedmap.New[string, *Node](s.CustomShapeFacets.Len())
+		for pair := s.CustomShapeFacets.Oldest(); pair != nil; pair = pair.Next() {
+			c.CustomShapeFacets.Set(pair.Key, pair.Value)
+		}
 	}
 
-	c.CustomShapeFacetDefinitions = orderedmap.New[string, Property](s.CustomShapeFacetDefinitions.Len())
-	for pair := s.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
-		prop := pair.Value
-		prop.Shape = prop.Shape.clone(clonedMap)
-		c.CustomShapeFacetDefinitions.Set(pair.Key, prop)
+	if s.CustomShapeFacetDefinitions != nil && !isSharedEmpty(s.CustomShapeFacetDefinitions) {
+		c.CustomShapeFacetDefinitions = orderedmap.New[string, Property](s.CustomShapeFacetDefinitions.Len())
+		for pair := s.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
+			prop := pair.Value
+			prop.Shape = prop.Shape.clone(clonedMap)
+			c.CustomShapeFacetDefinitions.Set(pair.Key, prop)
+		}
 	}
 
 	// TODO: DomainExtension is not deep copied yet, but it's not mutated anyway
-	c.CustomDomainProperties = orderedmap.New[string, *DomainExtension](s.CustomDomainProperties.Len())
-	for pair := s.CustomDomainProperties.Oldest(); pair != nil; pair = pair.Next() {
-		c.CustomDomainProperties.Set(pair.Key, pair.Value)
+	if s.CustomDomainProperties != nil && !isSharedEmpty(s.CustomDomainProperties) {
+		c.CustomDomainProperties = orderedmap.New[string, *DomainExtension](s.CustomDomainProperties.Len())
+		for pair := s.CustomDomainProperties.Oldest(); pair != nil; pair = pair.Next() {
+			c.CustomDomainProperties.Set(pair.Key, pair.Value)
+		}
 	}
 
 	if s.Alias != nil {
@@ -295,11 +346,7 @@ func (s *BaseShape) IsUnwrapped() bool {
 func (s *BaseShape) IsScalar() bool {
 	// TODO: Implement in Shape interface
 	switch s.Shape.(type) {
-	case *ObjectShape:
-	case *ArrayShape:
-	case *UnionShape:
-	case *JSONShape:
-	case *RecursiveShape:
+	case *ObjectShape, *ArrayShape, *UnionShape, *JSONShape, *RecursiveShape:
 		return false
 	}
 	return true
@@ -401,7 +448,7 @@ func identifyShapeType(shapeFacets []*yaml.Node) string {
 }
 
 func (r *RAML) MakeRecursiveShape(headBase *BaseShape) *BaseShape {
-	recursiveBase := r.MakeBaseShape(headBase.Name, headBase.Location, &headBase.Position)
+	recursiveBase := r.MakeBaseShape(headBase.Name, headBase.Location(), &headBase.Position)
 	recursiveBase.Name = headBase.Name
 	recursiveBase.Type = TypeRecursive
 	recursiveBase.Description = headBase.Description
@@ -419,7 +466,7 @@ func (r *RAML) MakeJSONShape(base *BaseShape, rawSchema string) (Shape, error) {
 	var schema *JSONSchema
 	err := json.Unmarshal([]byte(rawSchema), &schema)
 	if err != nil {
-		return nil, StacktraceNewWrapped("unmarshal json", err, base.Location,
+		return nil, StacktraceNewWrapped("unmarshal json", err, base.Location(),
 			stacktrace.WithPosition(&base.Position))
 	}
 
@@ -469,7 +516,7 @@ func (r *RAML) MakeConcreteShapeYAML(base *BaseShape, shapeType string, shapeFac
 	}
 
 	if err := shape.unmarshalYAMLNodes(shapeFacets); err != nil {
-		return nil, StacktraceNewWrapped("unmarshal yaml nodes", err, base.Location,
+		return nil, StacktraceNewWrapped("unmarshal yaml nodes", err, base.Location(),
 			stacktrace.WithPosition(&base.Position), stacktrace.WithInfo("shape type", shapeType))
 	}
 
@@ -478,28 +525,32 @@ func (r *RAML) MakeConcreteShapeYAML(base *BaseShape, shapeType string, shapeFac
 
 // MakeBaseShape creates a new base shape which is a base for all shapes.
 func (r *RAML) MakeBaseShape(name string, location string, position *stacktrace.Position) *BaseShape {
-	b := &BaseShape{
-		ID:       generateShapeID(),
-		Name:     name,
-		Location: location,
-		Position: *position,
-
-		raml:                        r,
-		CustomDomainProperties:      orderedmap.New[string, *DomainExtension](0),
-		CustomShapeFacets:           orderedmap.New[string, *Node](0),
-		CustomShapeFacetDefinitions: orderedmap.New[string, Property](0),
+	b := r.allocBaseShape()
+	*b = BaseShape{
+		ID:         r.newShapeID(name, location, position),
+		Name:       r.intern(name),
+		LocationID: r.locationHandle(location),
+		Position:   *position,
+
+		raml: r,
+	}
+	// Shapes share empty maps until the maps are set in low-memory mode.
+	if r.lowMemory {
+		b.CustomDomainProperties = noDomainProperties
+		b.CustomShapeFacets = noShapeFacets
+		b.CustomShapeFacetDefinitions = noShapeFacetDefinitions
+	} else {
+		b.CustomDomainProperties = orderedmap.New[string, *DomainExtension](0)
+		b.CustomShapeFacets = orderedmap.New[string, *Node](0)
+		b.CustomShapeFacetDefinitions = orderedmap.New[string, Property](0)
 	}
 	r.PutShape(b)
 	return b
 }
 
-// TODO: Temporary workaround
-var idCounter int64 = 1
-
-func generateShapeID() int64 {
-	id := idCounter
-	idCounter++
-	return id
+// setCustomShapeFacet sets the value of the custom facet.
+func (s *BaseShape) setCustomShapeFacet(name string, n *Node) {
+	orderedSet(&s.CustomShapeFacets, s.raml.intern(name), n)
 }
 
 func (r *RAML) makeShapeType(
@@ -538,8 +589,7 @@ func (r *RAML) makeShapeType(
 				return shapeType, s, nil
 			}
 		case TagInclude:
-			baseDir := filepath.Dir(location)
-			dt, errParse := r.parseDataType(filepath.Join(baseDir, shapeTypeNode.Value))
+			dt, errParse := r.parseDataType(location, shapeTypeNode.Value)
 			if errParse != nil {
 				return "", nil, StacktraceNewWrapped("parse data", errParse, location,
 					WithNodePosition(shapeTypeNode))
@@ -594,11 +644,18 @@ func (r *RAML) MakeNewShape(
 // makeNewShapeYAML creates a new shape from the given YAML node.
 func (r *RAML) makeNewShapeYAML(v *yaml.Node, name string, location string) (*BaseShape, error) {
 	base := r.MakeBaseShape(name, location, &stacktrace.Position{Line: v.Line, Column: v.Column})
+	base.rawNode = r.rawNode(v)
 
 	shapeTypeNode, shapeFacets, err := base.decode(v)
 	if err != nil {
 		return nil, StacktraceNewWrapped("decode", err, location, WithNodePosition(v))
 	}
+	if r.importJSONSchema && shapeTypeNode != nil && isJSONSchemaNode(shapeTypeNode) {
+		shapeTypeNode, shapeFacets, err = r.importJSONSchemaType(base, shapeTypeNode, shapeFacets)
+		if err != nil {
+			return nil, fmt.Errorf("import json schema: %w", err)
+		}
+	}
 
 	var shapeType string
 	if shapeTypeNode == nil {
@@ -617,7 +674,7 @@ func (r *RAML) makeNewShapeYAML(v *yaml.Node, name string, location string) (*Ba
 
 	s, err := r.MakeConcreteShapeYAML(base, shapeType, shapeFacets)
 	if err != nil {
-		return nil, StacktraceNewWrapped("make concrete shape", err, base.Location,
+		return nil, StacktraceNewWrapped("make concrete shape", err, base.Location(),
 			stacktrace.WithPosition(&base.Position))
 	}
 	if _, ok := s.(*UnknownShape); ok {
@@ -629,62 +686,63 @@ func (r *RAML) makeNewShapeYAML(v *yaml.Node, name string, location string) (*Ba
 
 func (s *BaseShape) decodeExamples(valueNode *yaml.Node) error {
 	if s.Example != nil {
-		return stacktrace.New("example and examples cannot be defined together", s.Location,
+		return stacktrace.New("example and examples cannot be defined together", s.Location(),
 			WithNodePosition(valueNode))
 	}
 	if valueNode.Kind == yaml.ScalarNode && valueNode.Tag == "!include" {
-		baseDir := filepath.Dir(s.Location)
-		n, err := s.raml.parseNamedExample(filepath.Join(baseDir, valueNode.Value))
+		n, err := s.raml.parseNamedExample(s.Location(), valueNode.Value)
 		if err != nil {
-			return StacktraceNewWrapped("parse named example", err, s.Location,
+			return StacktraceNewWrapped("parse named example", err, s.Location(),
 				WithNodePosition(valueNode))
 		}
-		s.Examples = &Examples{Link: n, Location: s.Location}
+		s.Examples = &Examples{Link: n, Location: s.Location()}
 		return nil
 	} else if valueNode.Kind != yaml.MappingNode {
-		return stacktrace.New("examples must be map", s.Location,
+		return stacktrace.New("examples must be map", s.Location(),
 			WithNodePosition(valueNode))
 	}
 	examples := orderedmap.New[string, *Example](len(valueNode.Content) / 2)
 	for j := 0; j != len(valueNode.Content); j += 2 {
 		name := valueNode.Content[j].Value
 		data := valueNode.Content[j+1]
-		example, err := s.raml.makeExample(data, name, s.Location)
+		example, err := s.raml.makeExample(data, name, s.Location())
 		if err != nil {
 			return StacktraceNewWrapped(fmt.Sprintf("make examples: [%d]", j),
-				err, s.Location, WithNodePosition(data))
+				err, s.Location(), WithNodePosition(data))
 		}
 		examples.Set(name, example)
 	}
-	s.Examples = &Examples{Map: examples, Location: s.Location}
+	s.Examples = &Examples{Map: examples, Location: s.Location()}
 	return nil
 }
 
 func (s *BaseShape) decodeFacets(valueNode *yaml.Node) error {
-	s.CustomShapeFacetDefinitions = orderedmap.New[string, Property](len(valueNode.Content) / 2)
 	for j := 0; j != len(valueNode.Content); j += 2 {
 		nodeName := valueNode.Content[j].Value
 		data := valueNode.Content[j+1]
 
 		propertyName, hasImplicitOptional := s.raml.chompImplicitOptional(nodeName)
-		property, err := s.raml.makeProperty(nodeName, propertyName, data, s.Location, hasImplicitOptional)
+		property, err := s.raml.makeProperty(nodeName, propertyName, data, s.Location(), hasImplicitOptional)
 		if err != nil {
-			return StacktraceNewWrapped("make property", err, s.Location,
+			return StacktraceNewWrapped("make property", err, s.Location(),
 				WithNodePosition(data))
 		}
-		s.CustomShapeFacetDefinitions.Set(property.Name, property)
+		property.Shape.Comments = makeComments(valueNode.Content[j], data)
+		property.Comments = property.Shape.Comments
+		property.rawNode = s.raml.rawNode(valueNode.Content[j])
+		orderedSet(&s.CustomShapeFacetDefinitions, property.Name, property)
 	}
 	return nil
 }
 
 func (s *BaseShape) decodeExample(valueNode *yaml.Node) error {
 	if s.Examples != nil {
-		return stacktrace.New("example and examples cannot be defined together", s.Location,
+		return stacktrace.New("example and examples cannot be defined together", s.Location(),
 			WithNodePosition(valueNode))
 	}
-	example, err := s.raml.makeExample(valueNode, "", s.Location)
+	example, err := s.raml.makeExample(valueNode, "", s.Location())
 	if err != nil {
-		return StacktraceNewWrapped("make example", err, s.Location,
+		return StacktraceNewWrapped("make example", err, s.Location(),
 			WithNodePosition(valueNode))
 	}
 	s.Example = example
@@ -696,55 +754,63 @@ func (s *BaseShape) decodeValueNode(node, valueNode *yaml.Node) (*yaml.Node, []*
 	shapeFacets := make([]*yaml.Node, 0)
 
 	switch node.Value {
-	case "type":
+	case "type", "schema":
+		if node.Value == "schema" {
+			s.raml.addWarning("schema is deprecated, use type", s.Location(), WithNodePosition(node))
+		}
 		shapeTypeNode = valueNode
 	case "displayName":
 		if err := valueNode.Decode(&s.DisplayName); err != nil {
-			return nil, nil, StacktraceNewWrapped("decode display name", err, s.Location,
+			return nil, nil, StacktraceNewWrapped("decode display name", err, s.Location(),
 				WithNodePosition(valueNode))
 		}
 	case "description":
 		if err := valueNode.Decode(&s.Description); err != nil {
-			return nil, nil, StacktraceNewWrapped("decode description", err, s.Location,
+			return nil, nil, StacktraceNewWrapped("decode description", err, s.Location(),
 				WithNodePosition(valueNode))
 		}
 	case "required":
 		if err := valueNode.Decode(&s.Required); err != nil {
-			return nil, nil, StacktraceNewWrapped("decode required", err, s.Location,
+			return nil, nil, StacktraceNewWrapped("decode required", err, s.Location(),
 				WithNodePosition(valueNode))
 		}
 	case "facets":
 		if err := s.decodeFacets(valueNode); err != nil {
-			return nil, nil, StacktraceNewWrapped("decode facets", err, s.Location,
+			return nil, nil, StacktraceNewWrapped("decode facets", err, s.Location(),
 				WithNodePosition(valueNode))
 		}
 	case "example":
 		if err := s.decodeExample(valueNode); err != nil {
-			return nil, nil, StacktraceNewWrapped("decode example", err, s.Location,
+			return nil, nil, StacktraceNewWrapped("decode example", err, s.Location(),
 				WithNodePosition(valueNode))
 		}
 	case "examples":
 		if err := s.decodeExamples(valueNode); err != nil {
-			return nil, nil, StacktraceNewWrapped("decode example", err, s.Location,
+			return nil, nil, StacktraceNewWrapped("decode example", err, s.Location(),
 				WithNodePosition(valueNode))
 		}
 	case "default":
-		n, err := s.raml.makeRootNode(valueNode, s.Location)
+		n, err := s.raml.makeRootNode(valueNode, s.Location())
 		if err != nil {
-			return nil, nil, StacktraceNewWrapped("make node default", err, s.Location,
+			return nil, nil, StacktraceNewWrapped("make node default", err, s.Location(),
 				WithNodePosition(valueNode))
 		}
 		s.Default = n
+	case "xml":
+		if err := s.decodeXML(valueNode); err != nil {
+			return nil, nil, StacktraceNewWrapped("decode xml", err, s.Location(),
+				WithNodePosition(valueNode))
+		}
 	case "allowedTargets":
 		// TODO: Included by annotationTypes
 	default:
 		if IsCustomDomainExtensionNode(node.Value) {
-			name, de, err := s.raml.unmarshalCustomDomainExtension(s.Location, node, valueNode)
+			name, de, err := s.raml.unmarshalCustomDomainExtension(s.Location(), node, valueNode)
 			if err != nil {
-				return nil, nil, StacktraceNewWrapped("unmarshal custom domain extension", err, s.Location,
+				return nil, nil, StacktraceNewWrapped("unmarshal custom domain extension", err, s.Location(),
 					WithNodePosition(valueNode))
 			}
-			s.CustomDomainProperties.Set(name, de)
+			orderedSet(&s.CustomDomainProperties, name, de)
 		} else {
 			shapeFacets = append(shapeFacets, node, valueNode)
 		}
@@ -760,7 +826,7 @@ func (s *BaseShape) decode(value *yaml.Node) (*yaml.Node, []*yaml.Node, error) {
 	}
 
 	if value.Kind != yaml.MappingNode {
-		return nil, nil, stacktrace.New("value kind must be map", s.Location, WithNodePosition(value))
+		return nil, nil, stacktrace.New("value kind must be map", s.Location(), WithNodePosition(value))
 	}
 
 	var shapeTypeNode *yaml.Node
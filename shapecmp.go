@@ -0,0 +1,409 @@
+package raml
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"math/big"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// shapeComparisonRoot is the path of compared shapes in changes returned by ShapeEquals and ShapeCompatible.
+const shapeComparisonRoot = "$"
+
+// ShapeEquals structurally compares two resolved shapes: facets, properties, items and union members. IDs, names,
+// positions and documentation facets (displayName and description) are ignored. It returns false and the list of
+// differences sorted by path if the shapes are not equal. Paths start with "$", e.g. "$.properties.name.maxLength".
+// NOTE: Shapes are expected to be unwrapped, otherwise inherited facets are not compared.
+func ShapeEquals(a, b *BaseShape) (bool, []Change) {
+	changes := compareShapes(a, b, true)
+	return len(changes) == 0, changes
+}
+
+// ShapeCompatible reports whether every value valid against sub is valid against super, e.g. whether a new
+// version of a request body accepts everything the old one did. Super may widen the type (integer to number, a
+// type to a union or any), relax facets or accept additional properties. It returns false and the list of
+// constraints of super that reject values valid against sub, in the form of changes from sub to super sorted by path.
+// The check is conservative: patterns and formats of super must be the same as in sub unless sub is an enum.
+// NOTE: Shapes are expected to be unwrapped, otherwise inherited facets are not compared.
+func ShapeCompatible(sub, super *BaseShape) (bool, []Change) {
+	sc := &subsumer{visited: make(map[[2]int64]struct{})}
+	sc.shapes(shapeComparisonRoot, sub, super)
+	slices.SortStableFunc(sc.changes, func(x, y Change) int {
+		return strings.Compare(x.Path, y.Path)
+	})
+	return len(sc.changes) == 0, sc.changes
+}
+
+func compareShapes(a, b *BaseShape, skipDocs bool) []Change {
+	d := &differ{visited: make(map[[2]int64]struct{}), skipDocs: skipDocs}
+	d.diffShapes(shapeComparisonRoot, a, b)
+	slices.SortStableFunc(d.changes, func(x, y Change) int {
+		return strings.Compare(x.Path, y.Path)
+	})
+	return d.changes
+}
+
+// subsumer checks that values of one shape are accepted by another shape.
+type subsumer struct {
+	changes []Change
+	// visited contains pairs of compared shape IDs. Recursive types are assumed compatible on the second visit.
+	visited map[[2]int64]struct{}
+}
+
+func (sc *subsumer) reject(kind ChangeKind, path string, sub, super any) {
+	sc.changes = append(sc.changes, Change{Kind: kind, Path: path, Old: sub, New: super, Breaking: true})
+}
+
+// accepts reports whether super accepts every value of sub without recording the reasons.
+func (sc *subsumer) accepts(sub, super *BaseShape) bool {
+	probe := &subsumer{visited: maps.Clone(sc.visited)}
+	probe.shapes("", sub, super)
+	return len(probe.changes) == 0
+}
+
+func (sc *subsumer) shapes(path string, sub, super *BaseShape) {
+	sub, super = recursionHead(sub), recursionHead(super)
+	key := [2]int64{sub.ID, super.ID}
+	if _, ok := sc.visited[key]; ok {
+		return
+	}
+	sc.visited[key] = struct{}{}
+
+	if _, ok := super.Shape.(*AnyShape); ok {
+		return
+	}
+	if union, ok := sub.Shape.(*UnionShape); ok && union.Enum == nil {
+		// Every member of sub must be accepted, either by super itself or by one of its members.
+		for _, m := range union.AnyOf {
+			sc.shapes(path, m, super)
+		}
+		return
+	}
+	if enum := enumOf(sub.Shape); enum != nil {
+		// Values of enums are checked exactly.
+		for _, v := range enum {
+			if err := super.Validate(v.Value); err != nil {
+				sc.reject(ChangeRemoved, path+"."+FacetEnum+"."+fmt.Sprint(v.Value), v.Value, nil)
+			}
+		}
+		return
+	}
+	if enum := enumOf(super.Shape); enum != nil {
+		sc.reject(ChangeAdded, path+"."+FacetEnum, nil, enum.String())
+		return
+	}
+	if union, ok := super.Shape.(*UnionShape); ok {
+		if slices.ContainsFunc(union.AnyOf, func(m *BaseShape) bool {
+			return sc.accepts(sub, m)
+		}) {
+			return
+		}
+		// The member that matches the type of sub explains why the value is rejected.
+		for _, m := range union.AnyOf {
+			if reflect.TypeOf(recursionHead(m).Shape) == reflect.TypeOf(sub.Shape) {
+				sc.shapes(path, sub, m)
+				return
+			}
+		}
+		sc.reject(ChangeChanged, path+".type", sub.Type, super.Type)
+		return
+	}
+
+	switch subShape := sub.Shape.(type) {
+	case *IntegerShape:
+		switch superShape := super.Shape.(type) {
+		case *IntegerShape:
+			sc.numeric(path, integerFacets(subShape), integerFacets(superShape))
+			sc.sameValue(path+"."+FacetFormat, subShape.Format, superShape.Format)
+			return
+		case *NumberShape:
+			sc.numeric(path, integerFacets(subShape), numberFacets(superShape))
+			sc.sameValue(path+"."+FacetFormat, subShape.Format, superShape.Format)
+			return
+		}
+	case *NumberShape:
+		if superShape, ok := super.Shape.(*NumberShape); ok {
+			sc.numeric(path, numberFacets(subShape), numberFacets(superShape))
+			sc.sameValue(path+"."+FacetFormat, subShape.Format, superShape.Format)
+			return
+		}
+	case *StringShape:
+		if superShape, ok := super.Shape.(*StringShape); ok {
+			sc.length(path, subShape.LengthFacets, superShape.LengthFacets)
+			if superShape.Pattern != nil &&
+				(subShape.Pattern == nil || subShape.Pattern.String() != superShape.Pattern.String()) {
+				sc.reject(ChangeChanged, path+"."+FacetPattern, patternString(subShape.Pattern),
+					superShape.Pattern.String())
+			}
+			return
+		}
+	case *FileShape:
+		if superShape, ok := super.Shape.(*FileShape); ok {
+			sc.length(path, subShape.LengthFacets, superShape.LengthFacets)
+			if superShape.FileTypes != nil {
+				superTypes := make(map[string]struct{}, len(superShape.FileTypes))
+				for _, v := range superShape.FileTypes {
+					superTypes[fmt.Sprint(v.Value)] = struct{}{}
+				}
+				if subShape.FileTypes == nil {
+					sc.reject(ChangeAdded, path+"."+FacetFileTypes, nil, superShape.FileTypes.String())
+				}
+				for _, v := range subShape.FileTypes {
+					if _, ok := superTypes[fmt.Sprint(v.Value)]; !ok {
+						sc.reject(ChangeRemoved, path+"."+FacetFileTypes+"."+fmt.Sprint(v.Value), v.Value, nil)
+					}
+				}
+			}
+			return
+		}
+	case *DateTimeShape:
+		if superShape, ok := super.Shape.(*DateTimeShape); ok {
+			sc.sameValue(path+"."+FacetFormat, subShape.Format, superShape.Format)
+			return
+		}
+	case *ArrayShape:
+		if superShape, ok := super.Shape.(*ArrayShape); ok {
+			sc.array(path, subShape, superShape)
+			return
+		}
+	case *ObjectShape:
+		if superShape, ok := super.Shape.(*ObjectShape); ok {
+			sc.object(path, subShape, superShape)
+			return
+		}
+	case *JSONShape:
+		if superShape, ok := super.Shape.(*JSONShape); ok {
+			if subShape.Raw != superShape.Raw {
+				sc.reject(ChangeChanged, path+".schema", nil, nil)
+			}
+			return
+		}
+	}
+	// Shapes without facets, e.g. booleans or nil, accept values of the same type.
+	if reflect.TypeOf(sub.Shape) != reflect.TypeOf(super.Shape) {
+		sc.reject(ChangeChanged, path+".type", sub.Type, super.Type)
+	}
+}
+
+// numericFacets are bounds of integer and number shapes converted to floats.
+type numericFacets struct {
+	minimum, maximum, multipleOf *float64
+	// integer means that values are whole numbers, i.e. multiples of 1.
+	integer bool
+}
+
+func integerFacets(s *IntegerShape) numericFacets {
+	toFloat := func(v *big.Int) *float64 {
+		if v == nil {
+			return nil
+		}
+		f, _ := new(big.Float).SetInt(v).Float64()
+		return &f
+	}
+	return numericFacets{minimum: toFloat(s.Minimum), maximum: toFloat(s.Maximum), multipleOf: s.MultipleOf,
+		integer: true}
+}
+
+func numberFacets(s *NumberShape) numericFacets {
+	return numericFacets{minimum: s.Minimum, maximum: s.Maximum, multipleOf: s.MultipleOf}
+}
+
+func (sc *subsumer) numeric(path string, sub, super numericFacets) {
+	sc.lowerBound(path+"."+FacetMinimum, sub.minimum, super.minimum)
+	sc.upperBound(path+"."+FacetMaximum, sub.maximum, super.maximum)
+	if super.multipleOf == nil {
+		return
+	}
+	step := sub.multipleOf
+	if step == nil && sub.integer {
+		one := 1.0
+		step = &one
+	}
+	if step == nil || !isMultiple(*step, *super.multipleOf) {
+		sc.reject(ChangeChanged, path+"."+FacetMultipleOf, deref(sub.multipleOf), *super.multipleOf)
+	}
+}
+
+// isMultiple reports whether v is a whole multiple of step.
+func isMultiple(v float64, step float64) bool {
+	if step == 0 {
+		return false
+	}
+	q := v / step
+	return math.Abs(q-math.Round(q)) < 1e-9
+}
+
+func (sc *subsumer) length(path string, sub, super LengthFacets) {
+	sc.lowerBound(path+"."+FacetMinLength, sub.MinLength, super.MinLength)
+	sc.upperBound(path+"."+FacetMaxLength, sub.MaxLength, super.MaxLength)
+}
+
+func (sc *subsumer) array(path string, sub, super *ArrayShape) {
+	sc.lowerBound(path+"."+FacetMinItems, sub.MinItems, super.MinItems)
+	sc.upperBound(path+"."+FacetMaxItems, sub.MaxItems, super.MaxItems)
+	if super.UniqueItems != nil && *super.UniqueItems && (sub.UniqueItems == nil || !*sub.UniqueItems) {
+		sc.reject(ChangeChanged, path+"."+FacetUniqueItems, false, true)
+	}
+	switch {
+	case super.Items == nil:
+	case sub.Items == nil:
+		sc.reject(ChangeAdded, path+"."+FacetItems, nil, super.Items.Type)
+	default:
+		sc.shapes(path+"."+FacetItems, sub.Items, super.Items)
+	}
+}
+
+func (sc *subsumer) object(path string, sub, super *ObjectShape) {
+	sc.lowerBound(path+"."+FacetMinProperties, sub.MinProperties, super.MinProperties)
+	sc.upperBound(path+"."+FacetMaxProperties, sub.MaxProperties, super.MaxProperties)
+	// Additional properties are allowed by default.
+	subOpen := sub.AdditionalProperties == nil || *sub.AdditionalProperties
+	superOpen := super.AdditionalProperties == nil || *super.AdditionalProperties
+
+	propsPath := path + "." + FacetProperties + "."
+	for pair := super.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		superProp := pair.Value
+		subProp, ok := sub.Properties.Get(pair.Key)
+		if ok {
+			if superProp.Required && !subProp.Required {
+				sc.reject(ChangeChanged, propsPath+pair.Key+".required", false, true)
+			}
+			sc.shapes(propsPath+pair.Key, subProp.Shape, superProp.Shape)
+			continue
+		}
+		switch {
+		case superProp.Required:
+			sc.reject(ChangeAdded, propsPath+pair.Key, nil, superProp.Shape.Type)
+		case sub.matchingPatternProperty(pair.Key) != nil:
+			sc.shapes(propsPath+pair.Key, sub.matchingPatternProperty(pair.Key).Shape, superProp.Shape)
+		case subOpen:
+			// Values of sub may have the property of any type.
+			if _, isAny := superProp.Shape.Shape.(*AnyShape); !isAny {
+				sc.reject(ChangeAdded, propsPath+pair.Key, nil, superProp.Shape.Type)
+			}
+		}
+	}
+	for pair := sub.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := super.Properties.Get(pair.Key); ok {
+			continue
+		}
+		if pp := super.matchingPatternProperty(pair.Key); pp != nil {
+			sc.shapes(propsPath+pair.Key, pair.Value.Shape, pp.Shape)
+		} else if !superOpen {
+			sc.reject(ChangeRemoved, propsPath+pair.Key, pair.Value.Shape.Type, nil)
+		}
+	}
+
+	patternPath := path + ".patternProperties."
+	for pair := sub.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		if pp, ok := super.PatternProperties.Get(pair.Key); ok {
+			sc.shapes(patternPath+pair.Key, pair.Value.Shape, pp.Shape)
+		} else if !superOpen || super.PatternProperties.Len() > 0 {
+			sc.reject(ChangeRemoved, patternPath+pair.Key, pair.Value.Shape.Type, nil)
+		}
+	}
+	if !subOpen {
+		return
+	}
+	// Values of open objects may have any other properties.
+	if !superOpen {
+		sc.reject(ChangeChanged, path+"."+FacetAdditionalProperties, true, false)
+		return
+	}
+	for pair := super.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := sub.PatternProperties.Get(pair.Key); ok {
+			continue
+		}
+		if _, isAny := pair.Value.Shape.Shape.(*AnyShape); !isAny {
+			sc.reject(ChangeAdded, patternPath+pair.Key, nil, pair.Value.Shape.Type)
+		}
+	}
+}
+
+// matchingPatternProperty returns the first pattern property that matches the name, nil if there is none.
+func (s *ObjectShape) matchingPatternProperty(name string) *PatternProperty {
+	for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		if pair.Value.Pattern.MatchString(name) {
+			pp := pair.Value
+			return &pp
+		}
+	}
+	return nil
+}
+
+func (sc *subsumer) sameValue(path string, sub, super *string) {
+	if super != nil && (sub == nil || *sub != *super) {
+		sc.reject(ChangeChanged, path, deref(sub), *super)
+	}
+}
+
+// lowerBound rejects super if it has a lower bound that is greater than the one of sub.
+func (sc *subsumer) lowerBound(path string, sub, super any) {
+	sc.bound(path, sub, super, 1)
+}
+
+// upperBound rejects super if it has an upper bound that is less than the one of sub.
+func (sc *subsumer) upperBound(path string, sub, super any) {
+	sc.bound(path, sub, super, -1)
+}
+
+func (sc *subsumer) bound(path string, sub, super any, tighter int) {
+	subValue, superValue := boundValue(sub), boundValue(super)
+	switch {
+	case superValue == nil:
+	case subValue == nil:
+		sc.reject(ChangeAdded, path, nil, *superValue)
+	case cmpFloat(*superValue, *subValue) == tighter:
+		sc.reject(ChangeChanged, path, *subValue, *superValue)
+	}
+}
+
+// boundValue converts uint64 and float64 pointers of bound facets to a float pointer.
+func boundValue(v any) *float64 {
+	switch b := v.(type) {
+	case *uint64:
+		if b != nil {
+			f := float64(*b)
+			return &f
+		}
+	case *float64:
+		return b
+	}
+	return nil
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// recursionHead returns the head of the recursion for recursive shapes and the shape itself otherwise.
+func recursionHead(s *BaseShape) *BaseShape {
+	if rs, ok := s.Shape.(*RecursiveShape); ok {
+		return rs.Head
+	}
+	return s
+}
+
+func patternString(re Regexp) any {
+	if re == nil {
+		return nil
+	}
+	return re.String()
+}
+
+// enumOf returns values of the enum of the scalar shape, nil if the shape has no enum.
+func enumOf(s Shape) Nodes {
+	if e, ok := s.(interface{ enumFacets() *EnumFacets }); ok {
+		return e.enumFacets().Enum
+	}
+	return nil
+}
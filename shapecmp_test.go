@@ -0,0 +1,141 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShapeEquals(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Pet:
+    properties:
+      name:
+        type: string
+        maxLength: 10
+      tags?: string[]
+  PetCopy:
+    description: The same pet.
+    properties:
+      name:
+        type: string
+        maxLength: 10
+      tags?: string[]
+  PetV2:
+    properties:
+      name:
+        type: string
+        maxLength: 20
+      tags?: string[]
+      age: integer
+`
+	r, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	lookup := func(name string) *BaseShape {
+		s, err := r.LookupType(name, r.GetLocation())
+		require.NoError(t, err)
+		return s
+	}
+
+	equal, changes := ShapeEquals(lookup("Pet"), lookup("PetCopy"))
+	require.True(t, equal)
+	require.Empty(t, changes)
+
+	equal, changes = ShapeEquals(lookup("Pet"), lookup("PetV2"))
+	require.False(t, equal)
+	require.Equal(t, []Change{
+		{Kind: ChangeAdded, Path: "$.properties.age", Breaking: true},
+		{Kind: ChangeChanged, Path: "$.properties.name.maxLength", Old: uint64(10), New: uint64(20)},
+	}, changes)
+}
+
+func TestShapeCompatible(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Narrow:
+    type: string
+    minLength: 2
+    maxLength: 5
+  Wide:
+    type: string
+    maxLength: 10
+  Nullable: Wide | nil
+  Any: any
+  Number: integer
+  Float:
+    type: number
+    multipleOf: 0.5
+  Small:
+    type: integer
+    minimum: 0
+    maximum: 10
+  Open:
+    properties:
+      a: string
+  Both:
+    properties:
+      a: string
+      b: integer
+  Closed:
+    additionalProperties: false
+    properties:
+      a: string
+  OptionalB:
+    properties:
+      a: string
+      b?: integer
+  Codes:
+    type: string
+    enum: [ab, abc]
+  Node:
+    properties:
+      next?: Node
+`
+	r, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	lookup := func(name string) *BaseShape {
+		s, err := r.LookupType(name, r.GetLocation())
+		require.NoError(t, err)
+		return s
+	}
+
+	tests := []struct {
+		sub, super string
+		want       []string
+	}{
+		{sub: "Narrow", super: "Wide"},
+		{sub: "Narrow", super: "Nullable"},
+		{sub: "Narrow", super: "Any"},
+		{sub: "Wide", super: "Narrow", want: []string{"$.maxLength", "$.minLength"}},
+		{sub: "Nullable", super: "Wide", want: []string{"$.type"}},
+		{sub: "Number", super: "Nullable", want: []string{"$.type"}},
+		{sub: "Number", super: "Float"},
+		{sub: "Float", super: "Number", want: []string{"$.type"}},
+		{sub: "Small", super: "Number"},
+		{sub: "Number", super: "Small", want: []string{"$.maximum", "$.minimum"}},
+		{sub: "Both", super: "Open"},
+		{sub: "Closed", super: "Open"},
+		{sub: "Open", super: "Closed", want: []string{"$.additionalProperties"}},
+		{sub: "Both", super: "Closed", want: []string{"$.additionalProperties", "$.properties.b"}},
+		{sub: "Closed", super: "OptionalB"},
+		{sub: "Open", super: "OptionalB", want: []string{"$.properties.b"}},
+		{sub: "Codes", super: "Narrow"},
+		{sub: "Codes", super: "Wide"},
+		{sub: "Wide", super: "Codes", want: []string{"$.enum"}},
+		{sub: "Node", super: "Node"},
+		{sub: "Node", super: "Open", want: []string{"$.properties.a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sub+" to "+tt.super, func(t *testing.T) {
+			compatible, changes := ShapeCompatible(lookup(tt.sub), lookup(tt.super))
+			require.Equal(t, len(tt.want) == 0, compatible)
+			var paths []string
+			for _, c := range changes {
+				require.True(t, c.Breaking)
+				paths = append(paths, c.Path)
+			}
+			require.Equal(t, tt.want, paths)
+		})
+	}
+}
@@ -0,0 +1,1078 @@
+package raml
+
+import (
+	"cmp"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// snapshotVersion is the version of the snapshot format. Snapshots of other versions are rejected.
+const snapshotVersion = 1
+
+func init() {
+	// Values of data nodes decoded from YAML and JSON.
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+}
+
+// snapshot is the gob-encoded form of the model. Shapes and annotations are stored in tables and refer to each other
+// by references, so shared and recursive shapes are stored once.
+type snapshot struct {
+	Version    int
+	EntryPoint string
+	Stage      PipelineStage
+	// Locations are locations of fragments in the order of their handles, dropped locations are empty.
+	Locations   []string
+	LastShapeID int64
+	Fragments   []snapshotFragment
+	Shapes      []snapshotShape
+	// Registered is the number of leading shapes of the table returned by GetShapes.
+	Registered int
+	// Annotations is the table of annotations, the annotations of the RAML come first.
+	Annotations []snapshotAnnotation
+	// Applied is the number of leading annotations returned by GetAllAnnotations.
+	Applied                 int
+	FragmentTypes           []snapshotDeclaration
+	FragmentAnnotationTypes []snapshotDeclaration
+}
+
+// optional is an optional value. Gob does not transmit zero values, so pointers to them would be decoded as nil.
+type optional[T any] struct {
+	Valid bool
+	Value T
+}
+
+func optionalOf[T any](p *T) optional[T] {
+	if p == nil {
+		return optional[T]{}
+	}
+	return optional[T]{Valid: true, Value: *p}
+}
+
+func (o optional[T]) ptr() *T {
+	if !o.Valid {
+		return nil
+	}
+	return &o.Value
+}
+
+// shapeRef refers to a shape of the table by its index plus one. The zero reference refers to no shape.
+type shapeRef int
+
+// annotationRef refers to an annotation of the table by its index.
+type annotationRef int
+
+type snapshotFragment struct {
+	Kind            FragmentKind
+	ID              string
+	Location        string
+	Usage           string
+	Uses            []snapshotUse
+	AnnotationTypes []snapshotDeclaration
+	Types           []snapshotDeclaration
+	Annotations     []snapshotAnnotationKey
+	Shape           shapeRef
+	Examples        []snapshotExample
+}
+
+type snapshotUse struct {
+	Alias    string
+	ID       string
+	Value    string
+	Link     string
+	Location string
+	stacktrace.Position
+}
+
+// snapshotDeclaration is a named shape. Location is set for declarations of the registry of fragment types.
+type snapshotDeclaration struct {
+	Location string
+	Name     string
+	Shape    shapeRef
+}
+
+type snapshotAnnotationKey struct {
+	Key        string
+	Annotation annotationRef
+}
+
+type snapshotAnnotation struct {
+	ID        string
+	Name      string
+	Extension *snapshotNode
+	DefinedBy shapeRef
+	Location  string
+	stacktrace.Position
+}
+
+type snapshotNode struct {
+	ID       string
+	Value    any
+	Location string
+	stacktrace.Position
+}
+
+type snapshotExample struct {
+	Key         string
+	ID          string
+	Name        string
+	DisplayName string
+	Description string
+	Data        *snapshotNode
+	Strict      bool
+	Annotations []snapshotAnnotationKey
+	Location    string
+	stacktrace.Position
+}
+
+type snapshotExamples struct {
+	ID       string
+	Examples []snapshotExample
+	// Link is the location of the included named example fragment.
+	Link     string
+	Location string
+	stacktrace.Position
+}
+
+type snapshotProperty struct {
+	Key      string
+	Name     string
+	Shape    shapeRef
+	Required bool
+	Comments *Comments
+}
+
+type snapshotXML struct {
+	Attribute optional[bool]
+	Wrapped   optional[bool]
+	Name      optional[string]
+	Namespace optional[string]
+	Prefix    optional[string]
+	Location  string
+	stacktrace.Position
+}
+
+type snapshotShape struct {
+	ID          int64
+	Name        string
+	Kind        string
+	DisplayName optional[string]
+	Description optional[string]
+	Type        string
+	TypeLabel   string
+	Example     *snapshotExample
+	Examples    *snapshotExamples
+	Inherits    []shapeRef
+	Alias       shapeRef
+	Default     *snapshotNode
+	Required    optional[bool]
+	XML         *snapshotXML
+	Comments    *Comments
+	// Link is the location of the included data type fragment.
+	Link                        string
+	CustomShapeFacets           []snapshotNode
+	CustomShapeFacetKeys        []string
+	CustomShapeFacetDefinitions []snapshotProperty
+	Annotations                 []snapshotAnnotationKey
+	Unwrapped                   bool
+	Location                    string
+	stacktrace.Position
+
+	// Facets of concrete shapes.
+	Enum                 []snapshotNode
+	Format               optional[string]
+	MinLength            optional[uint64]
+	MaxLength            optional[uint64]
+	Pattern              optional[string]
+	Minimum              optional[float64]
+	Maximum              optional[float64]
+	IntegerMinimum       *big.Int
+	IntegerMaximum       *big.Int
+	MultipleOf           optional[float64]
+	FileTypes            []snapshotNode
+	Items                shapeRef
+	MinItems             optional[uint64]
+	MaxItems             optional[uint64]
+	UniqueItems          optional[bool]
+	AnyOf                []shapeRef
+	Properties           []snapshotProperty
+	PatternProperties    []snapshotProperty
+	AdditionalProperties optional[bool]
+	MinProperties        optional[uint64]
+	MaxProperties        optional[uint64]
+	Discriminator        optional[string]
+	DiscriminatorValue   any
+	Head                 shapeRef
+	Raw                  string
+}
+
+// snapshotLocations returns the location table where locations of dropped fragments are empty.
+func (r *RAML) snapshotLocations() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	locations := slices.Clone(r.locationTable())
+	for i := range locations {
+		if !r.isActiveLocation(LocationID(i + 1)) {
+			locations[i] = ""
+		}
+	}
+	return locations
+}
+
+// SaveSnapshot writes the model to the writer in a binary form that LoadSnapshot reads without parsing and
+// resolving the fragments again, e.g. to parse the specification at build time and load it at startup.
+// Raw YAML nodes, warnings and copies of declared shapes are not saved. API definitions are not supported yet.
+func (r *RAML) SaveSnapshot(w io.Writer) error {
+	if r.api != nil {
+		return fmt.Errorf("save snapshot: API definitions are not supported")
+	}
+	sw := &snapshotWriter{
+		shapes:      make(map[*BaseShape]shapeRef),
+		annotations: make(map[*DomainExtension]annotationRef),
+	}
+	snap := &snapshot{
+		Version:     snapshotVersion,
+		EntryPoint:  r.GetLocation(),
+		Stage:       r.stage,
+		Locations:   r.snapshotLocations(),
+		LastShapeID: r.lastShapeID,
+	}
+	for _, s := range r.shapes {
+		sw.shape(s)
+	}
+	snap.Registered = len(sw.pending)
+	for _, de := range r.domainExtensions {
+		sw.annotation(de)
+	}
+	snap.Applied = len(sw.table)
+	locations := make([]string, 0, len(r.fragmentsCache))
+	for loc := range r.fragmentsCache {
+		locations = append(locations, loc)
+	}
+	slices.Sort(locations)
+	for _, loc := range locations {
+		snap.Fragments = append(snap.Fragments, sw.fragment(r.fragmentsCache[loc]))
+	}
+	snap.FragmentTypes = sw.fragmentDeclarations(r.fragmentTypes)
+	snap.FragmentAnnotationTypes = sw.fragmentDeclarations(r.fragmentAnnotationTypes)
+	// Shapes are written after they are referenced, so the table grows while it is written.
+	for i := 0; i < len(sw.pending); i++ {
+		snap.Shapes = append(snap.Shapes, sw.shapeData(sw.pending[i]))
+	}
+	snap.Annotations = sw.table
+	if err := gob.NewEncoder(w).Encode(snap); err != nil {
+		return fmt.Errorf("save snapshot: %w", err)
+	}
+	return nil
+}
+
+// snapshotWriter assigns references to shapes and annotations of the model.
+type snapshotWriter struct {
+	shapes      map[*BaseShape]shapeRef
+	pending     []*BaseShape
+	annotations map[*DomainExtension]annotationRef
+	table       []snapshotAnnotation
+}
+
+func (sw *snapshotWriter) shape(s *BaseShape) shapeRef {
+	if s == nil {
+		return 0
+	}
+	if ref, ok := sw.shapes[s]; ok {
+		return ref
+	}
+	sw.pending = append(sw.pending, s)
+	ref := shapeRef(len(sw.pending))
+	sw.shapes[s] = ref
+	return ref
+}
+
+func (sw *snapshotWriter) shapeRefs(shapes []*BaseShape) []shapeRef {
+	if shapes == nil {
+		return nil
+	}
+	refs := make([]shapeRef, len(shapes))
+	for i, s := range shapes {
+		refs[i] = sw.shape(s)
+	}
+	return refs
+}
+
+func (sw *snapshotWriter) annotation(de *DomainExtension) annotationRef {
+	if ref, ok := sw.annotations[de]; ok {
+		return ref
+	}
+	ref := annotationRef(len(sw.table))
+	sw.annotations[de] = ref
+	sw.table = append(sw.table, snapshotAnnotation{
+		ID:        de.ID,
+		Name:      de.Name,
+		Extension: snapshotNodeOf(de.Extension),
+		DefinedBy: sw.shape(de.DefinedBy),
+		Location:  de.Location,
+		Position:  de.Position,
+	})
+	return ref
+}
+
+func (sw *snapshotWriter) annotationKeys(m *orderedmap.OrderedMap[string, *DomainExtension]) []snapshotAnnotationKey {
+	var keys []snapshotAnnotationKey
+	for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+		keys = append(keys, snapshotAnnotationKey{Key: pair.Key, Annotation: sw.annotation(pair.Value)})
+	}
+	return keys
+}
+
+func (sw *snapshotWriter) declarations(m *orderedmap.OrderedMap[string, *BaseShape]) []snapshotDeclaration {
+	var decls []snapshotDeclaration
+	for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+		decls = append(decls, snapshotDeclaration{Name: pair.Key, Shape: sw.shape(pair.Value)})
+	}
+	return decls
+}
+
+// fragmentDeclarations returns declarations of the registry of fragment types sorted by locations and names.
+func (sw *snapshotWriter) fragmentDeclarations(m map[string]map[string]*BaseShape) []snapshotDeclaration {
+	var decls []snapshotDeclaration
+	for loc, shapes := range m {
+		for name := range shapes {
+			decls = append(decls, snapshotDeclaration{Location: loc, Name: name})
+		}
+	}
+	slices.SortFunc(decls, func(a, b snapshotDeclaration) int {
+		return cmp.Or(strings.Compare(a.Location, b.Location), strings.Compare(a.Name, b.Name))
+	})
+	for i := range decls {
+		decls[i].Shape = sw.shape(m[decls[i].Location][decls[i].Name])
+	}
+	return decls
+}
+
+func (sw *snapshotWriter) uses(m *orderedmap.OrderedMap[string, *LibraryLink]) []snapshotUse {
+	var uses []snapshotUse
+	for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+		u := snapshotUse{
+			Alias:    pair.Key,
+			ID:       pair.Value.ID,
+			Value:    pair.Value.Value,
+			Location: pair.Value.Location,
+			Position: pair.Value.Position,
+		}
+		if pair.Value.Link != nil {
+			u.Link = pair.Value.Link.Location
+		}
+		uses = append(uses, u)
+	}
+	return uses
+}
+
+func (sw *snapshotWriter) fragment(fragment Fragment) snapshotFragment {
+	switch f := fragment.(type) {
+	case *Library:
+		return snapshotFragment{
+			Kind:            FragmentLibrary,
+			ID:              f.ID,
+			Location:        f.Location,
+			Usage:           f.Usage,
+			Uses:            sw.uses(f.Uses),
+			AnnotationTypes: sw.declarations(f.AnnotationTypes),
+			Types:           sw.declarations(f.Types),
+			Annotations:     sw.annotationKeys(f.CustomDomainProperties),
+		}
+	case *DataType:
+		return snapshotFragment{
+			Kind:     FragmentDataType,
+			ID:       f.ID,
+			Location: f.Location,
+			Usage:    f.Usage,
+			Uses:     sw.uses(f.Uses),
+			Shape:    sw.shape(f.Shape),
+		}
+	case *NamedExample:
+		return snapshotFragment{
+			Kind:     FragmentNamedExample,
+			ID:       f.ID,
+			Location: f.Location,
+			Examples: sw.examples(f.Map),
+		}
+	default:
+		return snapshotFragment{Kind: FragmentUnknown, Location: fragment.GetLocation()}
+	}
+}
+
+func (sw *snapshotWriter) example(key string, ex *Example) snapshotExample {
+	return snapshotExample{
+		Key:         key,
+		ID:          ex.ID,
+		Name:        ex.Name,
+		DisplayName: ex.DisplayName,
+		Description: ex.Description,
+		Data:        snapshotNodeOf(ex.Data),
+		Strict:      ex.Strict,
+		Annotations: sw.annotationKeys(ex.CustomDomainProperties),
+		Location:    ex.Location,
+		Position:    ex.Position,
+	}
+}
+
+func (sw *snapshotWriter) examples(m *orderedmap.OrderedMap[string, *Example]) []snapshotExample {
+	var examples []snapshotExample
+	for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+		examples = append(examples, sw.example(pair.Key, pair.Value))
+	}
+	return examples
+}
+
+func (sw *snapshotWriter) properties(m *orderedmap.OrderedMap[string, Property]) []snapshotProperty {
+	var props []snapshotProperty
+	for pair := m.Oldest(); pair != nil; pair = pair.Next() {
+		props = append(props, snapshotProperty{
+			Key:      pair.Key,
+			Name:     pair.Value.Name,
+			Shape:    sw.shape(pair.Value.Shape),
+			Required: pair.Value.Required,
+			Comments: pair.Value.Comments,
+		})
+	}
+	return props
+}
+
+func (sw *snapshotWriter) shapeData(s *BaseShape) snapshotShape {
+	d := snapshotShape{
+		ID:          s.ID,
+		Name:        s.Name,
+		DisplayName: optionalOf(s.DisplayName),
+		Description: optionalOf(s.Description),
+		Type:        s.Type,
+		TypeLabel:   s.TypeLabel,
+		Inherits:    sw.shapeRefs(s.Inherits),
+		Alias:       sw.shape(s.Alias),
+		Default:     snapshotNodeOf(s.Default),
+		Required:    optionalOf(s.Required),
+		Comments:    s.Comments,
+		Annotations: sw.annotationKeys(s.CustomDomainProperties),
+		Unwrapped:   s.unwrapped,
+		Location:    s.Location(),
+		Position:    s.Position,
+	}
+	if s.Shape != nil {
+		d.Kind = shapeKind(s)
+	}
+	if s.XML != nil {
+		d.XML = &snapshotXML{
+			Attribute: optionalOf(s.XML.Attribute),
+			Wrapped:   optionalOf(s.XML.Wrapped),
+			Name:      optionalOf(s.XML.Name),
+			Namespace: optionalOf(s.XML.Namespace),
+			Prefix:    optionalOf(s.XML.Prefix),
+			Location:  s.XML.Location,
+			Position:  s.XML.Position,
+		}
+	}
+	if s.Example != nil {
+		ex := sw.example("", s.Example)
+		d.Example = &ex
+	}
+	if s.Examples != nil {
+		d.Examples = &snapshotExamples{
+			ID:       s.Examples.ID,
+			Examples: sw.examples(s.Examples.Map),
+			Location: s.Examples.Location,
+			Position: s.Examples.Position,
+		}
+		if s.Examples.Link != nil {
+			d.Examples.Link = s.Examples.Link.Location
+		}
+	}
+	if s.Link != nil {
+		d.Link = s.Link.Location
+	}
+	for pair := s.CustomShapeFacets.Oldest(); pair != nil; pair = pair.Next() {
+		d.CustomShapeFacetKeys = append(d.CustomShapeFacetKeys, pair.Key)
+		d.CustomShapeFacets = append(d.CustomShapeFacets, *snapshotNodeOf(pair.Value))
+	}
+	d.CustomShapeFacetDefinitions = sw.properties(s.CustomShapeFacetDefinitions)
+	switch shape := s.Shape.(type) {
+	case *StringShape:
+		d.Enum = snapshotNodesOf(shape.Enum)
+		d.MinLength, d.MaxLength = optionalOf(shape.MinLength), optionalOf(shape.MaxLength)
+		if shape.Pattern != nil {
+			d.Pattern = optional[string]{Valid: true, Value: shape.Pattern.String()}
+		}
+	case *NumberShape:
+		d.Enum = snapshotNodesOf(shape.Enum)
+		d.Format = optionalOf(shape.Format)
+		d.Minimum, d.Maximum = optionalOf(shape.Minimum), optionalOf(shape.Maximum)
+		d.MultipleOf = optionalOf(shape.MultipleOf)
+	case *IntegerShape:
+		d.Enum = snapshotNodesOf(shape.Enum)
+		d.Format = optionalOf(shape.Format)
+		d.IntegerMinimum, d.IntegerMaximum = shape.Minimum, shape.Maximum
+		d.MultipleOf = optionalOf(shape.MultipleOf)
+	case *BooleanShape:
+		d.Enum = snapshotNodesOf(shape.Enum)
+	case *DateTimeShape:
+		d.Enum = snapshotNodesOf(shape.Enum)
+		d.Format = optionalOf(shape.Format)
+	case *DateTimeOnlyShape:
+		d.Enum = snapshotNodesOf(shape.Enum)
+	case *DateOnlyShape:
+		d.Enum = snapshotNodesOf(shape.Enum)
+	case *TimeOnlyShape:
+		d.Enum = snapshotNodesOf(shape.Enum)
+	case *FileShape:
+		d.FileTypes = snapshotNodesOf(shape.FileTypes)
+		d.MinLength, d.MaxLength = optionalOf(shape.MinLength), optionalOf(shape.MaxLength)
+	case *UnionShape:
+		d.Enum = snapshotNodesOf(shape.Enum)
+		d.AnyOf = sw.shapeRefs(shape.AnyOf)
+	case *ArrayShape:
+		d.Items = sw.shape(shape.Items)
+		d.MinItems, d.MaxItems = optionalOf(shape.MinItems), optionalOf(shape.MaxItems)
+		d.UniqueItems = optionalOf(shape.UniqueItems)
+	case *ObjectShape:
+		d.Properties = sw.properties(shape.Properties)
+		for pair := shape.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+			d.PatternProperties = append(d.PatternProperties, snapshotProperty{
+				Key:   pair.Key,
+				Name:  pair.Value.Pattern.String(),
+				Shape: sw.shape(pair.Value.Shape),
+			})
+		}
+		d.AdditionalProperties = optionalOf(shape.AdditionalProperties)
+		d.MinProperties, d.MaxProperties = optionalOf(shape.MinProperties), optionalOf(shape.MaxProperties)
+		d.Discriminator, d.DiscriminatorValue = optionalOf(shape.Discriminator), shape.DiscriminatorValue
+	case *RecursiveShape:
+		d.Head = sw.shape(shape.Head)
+	case *JSONShape:
+		d.Raw = shape.Raw
+	}
+	return d
+}
+
+func snapshotNodeOf(n *Node) *snapshotNode {
+	if n == nil {
+		return nil
+	}
+	return &snapshotNode{ID: n.ID, Value: n.Value, Location: n.Location, Position: n.Position}
+}
+
+func snapshotNodesOf(nodes Nodes) []snapshotNode {
+	if nodes == nil {
+		return nil
+	}
+	result := make([]snapshotNode, len(nodes))
+	for i, n := range nodes {
+		result[i] = *snapshotNodeOf(n)
+	}
+	return result
+}
+
+// LoadSnapshot reads the model saved by SaveSnapshot. Options that cannot be saved, e.g. the regex engine or facet
+// validators, must be passed again; options of parsing and resolution are ignored.
+func LoadSnapshot(rd io.Reader, opts ...ParseOpt) (*RAML, error) {
+	return LoadSnapshotCtx(context.Background(), rd, opts...)
+}
+
+// LoadSnapshotCtx reads the model saved by SaveSnapshot like LoadSnapshot with the context of the RAML.
+func LoadSnapshotCtx(ctx context.Context, rd io.Reader, opts ...ParseOpt) (*RAML, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context is nil")
+	}
+	var snap snapshot
+	if err := gob.NewDecoder(rd).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("load snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return nil, fmt.Errorf("load snapshot: unsupported version %d", snap.Version)
+	}
+	pOpts := &parserOptions{}
+	for _, opt := range opts {
+		opt.Apply(pOpts)
+	}
+	r := New(ctx)
+	r.applyParserOptions(pOpts)
+	sr := &snapshotReader{r: r, snap: &snap}
+	if err := sr.load(); err != nil {
+		return nil, fmt.Errorf("load snapshot: %w", err)
+	}
+	// Dispatch tables of discriminators are not saved, they are computed from the restored shapes.
+	if err := r.buildShapeDiscriminators(); err != nil {
+		return nil, fmt.Errorf("load snapshot: %w", err)
+	}
+	return r, nil
+}
+
+// snapshotReader restores the model from the snapshot in passes: shapes are allocated first, so fragments,
+// annotations and shapes can refer to any shape while they are restored.
+type snapshotReader struct {
+	r           *RAML
+	snap        *snapshot
+	shapes      []*BaseShape
+	annotations []*DomainExtension
+}
+
+func (sr *snapshotReader) load() error {
+	r, snap := sr.r, sr.snap
+	for _, loc := range snap.Locations {
+		if loc == "" {
+			// Handles of dropped locations are not reused.
+			locations := append(r.locationTable(), loc)
+			r.locations.Store(&locations)
+			continue
+		}
+		r.registerLocation(loc)
+	}
+	r.lastShapeID = snap.LastShapeID
+	sr.shapes = make([]*BaseShape, len(snap.Shapes))
+	for i, d := range snap.Shapes {
+		b := r.allocBaseShape()
+		*b = BaseShape{
+			ID:          d.ID,
+			Name:        r.intern(d.Name),
+			DisplayName: d.DisplayName.ptr(),
+			Description: d.Description.ptr(),
+			Type:        d.Type,
+			TypeLabel:   d.TypeLabel,
+			Required:    d.Required.ptr(),
+			Comments:    d.Comments,
+			unwrapped:   d.Unwrapped,
+			raml:        r,
+			LocationID:  r.locationHandle(d.Location),
+			Position:    d.Position,
+		}
+		sr.shapes[i] = b
+	}
+	if snap.Registered > len(sr.shapes) {
+		return fmt.Errorf("invalid number of registered shapes %d", snap.Registered)
+	}
+	r.shapes = slices.Clone(sr.shapes[:snap.Registered])
+	sr.annotations = make([]*DomainExtension, len(snap.Annotations))
+	for i := range snap.Annotations {
+		sr.annotations[i] = &DomainExtension{raml: r}
+	}
+	if snap.Applied > len(sr.annotations) {
+		return fmt.Errorf("invalid number of annotations %d", snap.Applied)
+	}
+	r.domainExtensions = slices.Clone(sr.annotations[:snap.Applied])
+	for _, d := range snap.Fragments {
+		fragment, err := sr.fragment(d)
+		if err != nil {
+			return fmt.Errorf("fragment %s: %w", d.Location, err)
+		}
+		r.fragmentsCache[d.Location] = fragment
+	}
+	// Links to used libraries are restored when all fragments exist.
+	for _, d := range snap.Fragments {
+		if err := sr.links(d); err != nil {
+			return fmt.Errorf("fragment %s: %w", d.Location, err)
+		}
+	}
+	for i, d := range snap.Annotations {
+		de := sr.annotations[i]
+		de.ID, de.Name, de.Location, de.Position = d.ID, d.Name, d.Location, d.Position
+		de.Extension = sr.node(d.Extension)
+		var err error
+		if de.DefinedBy, err = sr.shape(d.DefinedBy); err != nil {
+			return fmt.Errorf("annotation %s: %w", d.Name, err)
+		}
+	}
+	for i, d := range snap.Shapes {
+		if err := sr.fillShape(sr.shapes[i], d); err != nil {
+			return fmt.Errorf("shape %s: %w", d.Name, err)
+		}
+	}
+	for _, d := range snap.FragmentTypes {
+		s, err := sr.shape(d.Shape)
+		if err != nil {
+			return fmt.Errorf("type %s: %w", d.Name, err)
+		}
+		r.PutTypeIntoFragment(d.Name, d.Location, s)
+	}
+	for _, d := range snap.FragmentAnnotationTypes {
+		s, err := sr.shape(d.Shape)
+		if err != nil {
+			return fmt.Errorf("annotation type %s: %w", d.Name, err)
+		}
+		r.PutAnnotationTypeIntoFragment(d.Name, d.Location, s)
+	}
+	if snap.EntryPoint != "" {
+		entryPoint, ok := r.fragmentsCache[snap.EntryPoint]
+		if !ok {
+			return fmt.Errorf("entry point %s not found", snap.EntryPoint)
+		}
+		r.SetEntryPoint(entryPoint)
+	}
+	r.stage = snap.Stage
+	return nil
+}
+
+func (sr *snapshotReader) shape(ref shapeRef) (*BaseShape, error) {
+	if ref == 0 {
+		return nil, nil
+	}
+	if int(ref) > len(sr.shapes) || ref < 0 {
+		return nil, fmt.Errorf("invalid shape reference %d", ref)
+	}
+	return sr.shapes[ref-1], nil
+}
+
+func (sr *snapshotReader) shapeList(refs []shapeRef) ([]*BaseShape, error) {
+	if refs == nil {
+		return nil, nil
+	}
+	shapes := make([]*BaseShape, len(refs))
+	for i, ref := range refs {
+		s, err := sr.shape(ref)
+		if err != nil {
+			return nil, err
+		}
+		shapes[i] = s
+	}
+	return shapes, nil
+}
+
+func (sr *snapshotReader) node(d *snapshotNode) *Node {
+	if d == nil {
+		return nil
+	}
+	return &Node{ID: d.ID, Value: d.Value, Location: d.Location, Position: d.Position, raml: sr.r}
+}
+
+func (sr *snapshotReader) nodes(ds []snapshotNode) Nodes {
+	if ds == nil {
+		return nil
+	}
+	nodes := make(Nodes, len(ds))
+	for i := range ds {
+		nodes[i] = sr.node(&ds[i])
+	}
+	return nodes
+}
+
+func (sr *snapshotReader) annotationMap(keys []snapshotAnnotationKey,
+) (*orderedmap.OrderedMap[string, *DomainExtension], error) {
+	m := orderedmap.New[string, *DomainExtension](len(keys))
+	for _, k := range keys {
+		if int(k.Annotation) >= len(sr.annotations) || k.Annotation < 0 {
+			return nil, fmt.Errorf("invalid annotation reference %d", k.Annotation)
+		}
+		m.Set(k.Key, sr.annotations[k.Annotation])
+	}
+	return m, nil
+}
+
+func (sr *snapshotReader) declarationMap(decls []snapshotDeclaration,
+) (*orderedmap.OrderedMap[string, *BaseShape], error) {
+	m := orderedmap.New[string, *BaseShape](len(decls))
+	for _, d := range decls {
+		s, err := sr.shape(d.Shape)
+		if err != nil {
+			return nil, fmt.Errorf("declaration %s: %w", d.Name, err)
+		}
+		m.Set(d.Name, s)
+	}
+	return m, nil
+}
+
+func (sr *snapshotReader) fragment(d snapshotFragment) (Fragment, error) {
+	r := sr.r
+	switch d.Kind {
+	case FragmentLibrary:
+		l := r.MakeLibrary(d.Location)
+		l.ID, l.Usage = d.ID, d.Usage
+		var err error
+		if l.AnnotationTypes, err = sr.declarationMap(d.AnnotationTypes); err != nil {
+			return nil, err
+		}
+		if l.Types, err = sr.declarationMap(d.Types); err != nil {
+			return nil, err
+		}
+		if l.CustomDomainProperties, err = sr.annotationMap(d.Annotations); err != nil {
+			return nil, err
+		}
+		return l, nil
+	case FragmentDataType:
+		dt := r.MakeDataType(d.Location)
+		dt.ID, dt.Usage = d.ID, d.Usage
+		var err error
+		if dt.Shape, err = sr.shape(d.Shape); err != nil {
+			return nil, err
+		}
+		return dt, nil
+	case FragmentNamedExample:
+		ne := r.MakeNamedExample(d.Location)
+		ne.ID = d.ID
+		var err error
+		if ne.Map, err = sr.exampleMap(d.Examples); err != nil {
+			return nil, err
+		}
+		return ne, nil
+	default:
+		return nil, fmt.Errorf("unsupported fragment kind %s", d.Kind)
+	}
+}
+
+func (sr *snapshotReader) links(d snapshotFragment) error {
+	var uses *orderedmap.OrderedMap[string, *LibraryLink]
+	switch f := sr.r.fragmentsCache[d.Location].(type) {
+	case *Library:
+		uses = f.Uses
+	case *DataType:
+		uses = f.Uses
+	default:
+		return nil
+	}
+	for _, u := range d.Uses {
+		link := &LibraryLink{ID: u.ID, Value: u.Value, Location: u.Location, Position: u.Position}
+		if u.Link != "" {
+			lib, ok := sr.r.fragmentsCache[u.Link].(*Library)
+			if !ok {
+				return fmt.Errorf("used library %s not found", u.Link)
+			}
+			link.Link = lib
+		}
+		uses.Set(u.Alias, link)
+	}
+	return nil
+}
+
+func (sr *snapshotReader) example(d *snapshotExample) (*Example, error) {
+	annotations, err := sr.annotationMap(d.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	return &Example{
+		ID:                     d.ID,
+		Name:                   d.Name,
+		DisplayName:            d.DisplayName,
+		Description:            d.Description,
+		Data:                   sr.node(d.Data),
+		Strict:                 d.Strict,
+		CustomDomainProperties: annotations,
+		Location:               d.Location,
+		Position:               d.Position,
+		raml:                   sr.r,
+	}, nil
+}
+
+func (sr *snapshotReader) exampleMap(ds []snapshotExample) (*orderedmap.OrderedMap[string, *Example], error) {
+	m := orderedmap.New[string, *Example](len(ds))
+	for i := range ds {
+		ex, err := sr.example(&ds[i])
+		if err != nil {
+			return nil, fmt.Errorf("example %s: %w", ds[i].Key, err)
+		}
+		m.Set(ds[i].Key, ex)
+	}
+	return m, nil
+}
+
+func (sr *snapshotReader) propertyMap(ds []snapshotProperty) (*orderedmap.OrderedMap[string, Property], error) {
+	m := orderedmap.New[string, Property](len(ds))
+	for _, d := range ds {
+		s, err := sr.shape(d.Shape)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", d.Name, err)
+		}
+		m.Set(d.Key, Property{Name: d.Name, Shape: s, Required: d.Required, Comments: d.Comments, raml: sr.r})
+	}
+	return m, nil
+}
+
+func (sr *snapshotReader) fillShape(b *BaseShape, d snapshotShape) error {
+	r := sr.r
+	var err error
+	if b.Inherits, err = sr.shapeList(d.Inherits); err != nil {
+		return err
+	}
+	if b.Alias, err = sr.shape(d.Alias); err != nil {
+		return err
+	}
+	b.Default = sr.node(d.Default)
+	if d.XML != nil {
+		b.XML = &XML{
+			Attribute: d.XML.Attribute.ptr(),
+			Wrapped:   d.XML.Wrapped.ptr(),
+			Name:      d.XML.Name.ptr(),
+			Namespace: d.XML.Namespace.ptr(),
+			Prefix:    d.XML.Prefix.ptr(),
+			Location:  d.XML.Location,
+			Position:  d.XML.Position,
+		}
+	}
+	if d.Link != "" {
+		dt, ok := r.fragmentsCache[d.Link].(*DataType)
+		if !ok {
+			return fmt.Errorf("included data type %s not found", d.Link)
+		}
+		b.Link = dt
+	}
+	if d.Example != nil {
+		if b.Example, err = sr.example(d.Example); err != nil {
+			return err
+		}
+	}
+	if d.Examples != nil {
+		b.Examples = &Examples{ID: d.Examples.ID, Location: d.Examples.Location, Position: d.Examples.Position}
+		if b.Examples.Map, err = sr.exampleMap(d.Examples.Examples); err != nil {
+			return err
+		}
+		if d.Examples.Link != "" {
+			ne, ok := r.fragmentsCache[d.Examples.Link].(*NamedExample)
+			if !ok {
+				return fmt.Errorf("included named example %s not found", d.Examples.Link)
+			}
+			b.Examples.Link = ne
+		}
+	}
+	b.CustomShapeFacets = orderedmap.New[string, *Node](len(d.CustomShapeFacets))
+	for i, key := range d.CustomShapeFacetKeys {
+		b.CustomShapeFacets.Set(key, sr.node(&d.CustomShapeFacets[i]))
+	}
+	if b.CustomShapeFacetDefinitions, err = sr.propertyMap(d.CustomShapeFacetDefinitions); err != nil {
+		return err
+	}
+	if b.CustomDomainProperties, err = sr.annotationMap(d.Annotations); err != nil {
+		return err
+	}
+	if d.Kind == "" {
+		return nil
+	}
+	shape, err := sr.concreteShape(b, d)
+	if err != nil {
+		return err
+	}
+	b.SetShape(shape)
+	return nil
+}
+
+// concreteShape restores the shape of the kind with its facets.
+func (sr *snapshotReader) concreteShape(b *BaseShape, d snapshotShape) (Shape, error) {
+	r := sr.r
+	switch d.Kind {
+	case TypeAny:
+		return &AnyShape{BaseShape: b}, nil
+	case TypeNil:
+		return &NilShape{BaseShape: b}, nil
+	case TypeString:
+		s := &StringShape{BaseShape: b}
+		s.Enum = sr.nodes(d.Enum)
+		s.MinLength, s.MaxLength = d.MinLength.ptr(), d.MaxLength.ptr()
+		if d.Pattern.Valid {
+			re, err := r.compilePattern(d.Pattern.Value, d.Location, &yaml.Node{Line: d.Line, Column: d.Column})
+			if err != nil {
+				return nil, fmt.Errorf("compile pattern: %w", err)
+			}
+			s.Pattern = re
+		}
+		return s, nil
+	case TypeNumber:
+		s := &NumberShape{BaseShape: b}
+		s.Enum = sr.nodes(d.Enum)
+		s.Format = d.Format.ptr()
+		s.Minimum, s.Maximum, s.MultipleOf = d.Minimum.ptr(), d.Maximum.ptr(), d.MultipleOf.ptr()
+		return s, nil
+	case TypeInteger:
+		s := &IntegerShape{BaseShape: b}
+		s.Enum = sr.nodes(d.Enum)
+		s.Format = d.Format.ptr()
+		s.Minimum, s.Maximum, s.MultipleOf = d.IntegerMinimum, d.IntegerMaximum, d.MultipleOf.ptr()
+		return s, nil
+	case TypeBoolean:
+		s := &BooleanShape{BaseShape: b}
+		s.Enum = sr.nodes(d.Enum)
+		return s, nil
+	case TypeDatetime:
+		s := &DateTimeShape{BaseShape: b}
+		s.Enum = sr.nodes(d.Enum)
+		s.Format = d.Format.ptr()
+		return s, nil
+	case TypeDatetimeOnly:
+		s := &DateTimeOnlyShape{BaseShape: b}
+		s.Enum = sr.nodes(d.Enum)
+		return s, nil
+	case TypeDateOnly:
+		s := &DateOnlyShape{BaseShape: b}
+		s.Enum = sr.nodes(d.Enum)
+		return s, nil
+	case TypeTimeOnly:
+		s := &TimeOnlyShape{BaseShape: b}
+		s.Enum = sr.nodes(d.Enum)
+		return s, nil
+	case TypeFile:
+		s := &FileShape{BaseShape: b}
+		s.FileTypes = sr.nodes(d.FileTypes)
+		s.MinLength, s.MaxLength = d.MinLength.ptr(), d.MaxLength.ptr()
+		return s, nil
+	case TypeUnion:
+		s := &UnionShape{BaseShape: b}
+		s.Enum = sr.nodes(d.Enum)
+		var err error
+		if s.AnyOf, err = sr.shapeList(d.AnyOf); err != nil {
+			return nil, err
+		}
+		return s, nil
+	case TypeArray:
+		s := &ArrayShape{BaseShape: b}
+		var err error
+		if s.Items, err = sr.shape(d.Items); err != nil {
+			return nil, err
+		}
+		s.MinItems, s.MaxItems, s.UniqueItems = d.MinItems.ptr(), d.MaxItems.ptr(), d.UniqueItems.ptr()
+		return s, nil
+	case TypeObject:
+		return sr.objectShape(b, d)
+	case TypeRecursive:
+		head, err := sr.shape(d.Head)
+		if err != nil {
+			return nil, err
+		}
+		return &RecursiveShape{BaseShape: b, Head: head}, nil
+	case TypeJSON:
+		s, err := r.MakeJSONShape(b, d.Raw)
+		if err != nil {
+			return nil, err
+		}
+		// MakeJSONShape sets the type of JSON schemas, the saved type is kept.
+		b.Type = d.Type
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unsupported shape kind %s", d.Kind)
+	}
+}
+
+func (sr *snapshotReader) objectShape(b *BaseShape, d snapshotShape) (Shape, error) {
+	s := &ObjectShape{BaseShape: b}
+	var err error
+	if s.Properties, err = sr.propertyMap(d.Properties); err != nil {
+		return nil, err
+	}
+	if d.PatternProperties != nil {
+		s.PatternProperties = orderedmap.New[string, PatternProperty](len(d.PatternProperties))
+		for _, p := range d.PatternProperties {
+			ps, errShape := sr.shape(p.Shape)
+			if errShape != nil {
+				return nil, fmt.Errorf("pattern property %s: %w", p.Key, errShape)
+			}
+			re, errCompile := sr.r.compilePattern(p.Name, d.Location, &yaml.Node{Line: d.Line, Column: d.Column})
+			if errCompile != nil {
+				return nil, fmt.Errorf("compile pattern property %s: %w", p.Key, errCompile)
+			}
+			s.PatternProperties.Set(p.Key, PatternProperty{Pattern: re, Shape: ps, raml: sr.r})
+		}
+	}
+	s.AdditionalProperties = d.AdditionalProperties.ptr()
+	s.MinProperties, s.MaxProperties = d.MinProperties.ptr(), d.MaxProperties.ptr()
+	s.Discriminator, s.DiscriminatorValue = d.Discriminator.ptr(), d.DiscriminatorValue
+	return s, nil
+}
@@ -0,0 +1,100 @@
+package raml
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAML_Snapshot(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	writeFile("common.raml", `#%RAML 1.0 Library
+annotationTypes:
+  pii: nil
+types:
+  Id:
+    type: string
+    pattern: ^[a-z]+$
+`)
+	writeFile("pet.raml", `#%RAML 1.0 DataType
+uses:
+  common: common.raml
+properties:
+  id: common.Id
+  name:
+    type: string
+    (common.pii):
+  age?:
+    type: integer
+    minimum: 0
+    maximum: 9223372036854775807
+`)
+	writeFile("examples.raml", `#%RAML 1.0 NamedExample
+first:
+  id: abc
+  name: Rex
+`)
+	content := `#%RAML 1.0 Library
+uses:
+  common: common.raml
+types:
+  Pet: !include pet.raml
+  Pets:
+    type: array
+    items: Pet
+    examples: !include examples.raml
+  Node:
+    properties:
+      value: number | nil
+      next?: Node
+    additionalProperties: false
+  Schema: |
+    {"type": "object", "properties": {"a": {"type": "string"}}}
+`
+	rml, err := ParseFromString(content, "library.raml", dir, OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, rml.SaveSnapshot(&buf))
+
+	loaded, err := LoadSnapshot(&buf)
+	require.NoError(t, err)
+	want, err := rml.ModelJSON(OptModelJSONWithPositions())
+	require.NoError(t, err)
+	got, err := loaded.ModelJSON(OptModelJSONWithPositions())
+	require.NoError(t, err)
+	require.JSONEq(t, string(want), string(got))
+	require.Equal(t, rml.GetLocation(), loaded.GetLocation())
+	require.Equal(t, rml.Stage(), loaded.Stage())
+	require.Len(t, loaded.GetShapes(), len(rml.GetShapes()))
+	require.Len(t, loaded.GetAllAnnotations(), len(rml.GetAllAnnotations()))
+
+	pet, err := loaded.LookupType("Pet", loaded.GetLocation())
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "library.raml"), pet.Location())
+	require.True(t, pet.IsUnwrapped())
+	require.NoError(t, pet.Validate(map[string]any{"id": "abc", "name": "Rex", "age": 3}))
+	require.ErrorContains(t, pet.Validate(map[string]any{"id": "ABC", "name": "Rex"}), "pattern")
+	id, err := loaded.LookupType("common.Id", loaded.GetLocation())
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "common.raml"), id.Location())
+
+	node, err := loaded.LookupType("Node", loaded.GetLocation())
+	require.NoError(t, err)
+	require.NoError(t, node.Validate(map[string]any{"value": 1.5, "next": map[string]any{"value": nil}}))
+	require.Error(t, node.Validate(map[string]any{"value": 1.5, "next": map[string]any{"value": "a"}}))
+}
+
+func TestLoadSnapshot_Errors(t *testing.T) {
+	_, err := LoadSnapshot(bytes.NewReader([]byte("not a snapshot")))
+	require.ErrorContains(t, err, "load snapshot")
+
+	rml, err := ParseFromString("#%RAML 1.0\ntitle: API\n", "api.raml", t.TempDir())
+	require.NoError(t, err)
+	require.ErrorContains(t, rml.SaveSnapshot(&bytes.Buffer{}), "API definitions are not supported")
+}
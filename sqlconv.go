@@ -0,0 +1,359 @@
+package raml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Annotations of SQLConverter. The annotation types must be declared by the user. Annotations of used libraries are
+// recognized by the name without the namespace, e.g. "(lib.primaryKey): true".
+const (
+	// AnnotationSQLTable sets the name of the table generated for the object type, e.g. "(table): pets".
+	AnnotationSQLTable = "table"
+	// AnnotationPrimaryKey adds the column of the property to the primary key, e.g. "(primaryKey): true".
+	AnnotationPrimaryKey = "primaryKey"
+	// AnnotationUnique makes the column of the property unique, e.g. "(unique): true".
+	AnnotationUnique = "unique"
+	// AnnotationIndex creates an index of the column of the property, e.g. "(index): true". Columns with the same
+	// index name, e.g. "(index): pet_owner_idx", are indexed together in the order of properties.
+	AnnotationIndex = "index"
+)
+
+// NamingPostgres quotes reserved keywords of PostgreSQL.
+var NamingPostgres = Naming{
+	Reserved: []string{
+		"all", "analyse", "analyze", "and", "any", "array", "as", "asc", "asymmetric", "authorization", "binary",
+		"both", "case", "cast", "check", "collate", "collation", "column", "concurrently", "constraint", "create",
+		"cross", "current_catalog", "current_date", "current_role", "current_schema", "current_time",
+		"current_timestamp", "current_user", "default", "deferrable", "desc", "distinct", "do", "else", "end",
+		"except", "false", "fetch", "for", "foreign", "freeze", "from", "full", "grant", "group", "having", "ilike",
+		"in", "initially", "inner", "intersect", "into", "is", "isnull", "join", "lateral", "leading", "left", "like",
+		"limit", "localtime", "localtimestamp", "natural", "not", "notnull", "null", "offset", "on", "only", "or",
+		"order", "outer", "overlaps", "placing", "primary", "references", "returning", "right", "select",
+		"session_user", "similar", "some", "symmetric", "table", "tablesample", "then", "to", "trailing", "true",
+		"union", "unique", "user", "using", "variadic", "verbose", "when", "where", "window", "with",
+	},
+	Escape: `"%s"`,
+}
+
+type SQLConverterOpt interface {
+	Apply(*SQLConverterOptions)
+}
+
+type SQLConverterOptions struct {
+	schema string
+	naming Naming
+}
+
+type optSQLSchema struct {
+	schema string
+}
+
+func (o optSQLSchema) Apply(opts *SQLConverterOptions) {
+	opts.schema = o.schema
+}
+
+// WithSQLSchema qualifies names of tables with the schema. Indexes are created in the schema of their tables.
+func WithSQLSchema(schema string) SQLConverterOpt {
+	return optSQLSchema{schema: schema}
+}
+
+type optSQLNaming struct {
+	naming Naming
+}
+
+func (o optSQLNaming) Apply(opts *SQLConverterOptions) {
+	opts.naming = o.naming
+}
+
+// WithSQLNaming sets the naming of tables, columns and indexes, NamingPostgres by default. Names are converted to
+// snake_case.
+func WithSQLNaming(naming Naming) SQLConverterOpt {
+	return optSQLNaming{naming: naming}
+}
+
+// SQLConverter converts declared object types to CREATE TABLE statements of PostgreSQL for services that persist
+// the API payload models.
+//
+// Every declared object type becomes a table with a column for every property, inherited properties first. Names
+// of types and properties are converted to snake_case, e.g. "common.Pet" becomes "common_pet". Scalar types are
+// mapped to column types by their facets, e.g. strings with maxLength become varchar, and the remaining facets
+// (minLength, pattern, enum, minimum, maximum) become CHECK constraints. Arrays of scalars become arrays, other
+// arrays, objects and unions become jsonb. Required properties are NOT NULL unless they are nullable ("T | nil").
+// Keys and indexes are declared with AnnotationPrimaryKey, AnnotationUnique and AnnotationIndex. Patterns are
+// checked with POSIX regular expressions of PostgreSQL, which may differ from the RAML semantics in corner cases.
+type SQLConverter struct {
+	opts SQLConverterOptions
+
+	r *RAML
+}
+
+func NewSQLConverter(opts ...SQLConverterOpt) *SQLConverter {
+	c := &SQLConverter{opts: SQLConverterOptions{naming: NamingPostgres}}
+	for _, opt := range opts {
+		opt.Apply(&c.opts)
+	}
+	return c
+}
+
+// sqlIndex is an index of columns in the order of properties.
+type sqlIndex struct {
+	name    string
+	columns []string
+}
+
+// Convert converts object types of the type graph (see RAML.TypeGraph) to tables.
+func (c *SQLConverter) Convert(r *RAML) ([]byte, error) {
+	c.r = r
+	graph := r.TypeGraph()
+	var sb strings.Builder
+	sb.WriteString("-- Code generated by go-raml. DO NOT EDIT.\n")
+	tables := NewNameScope()
+	for _, n := range graph.Nodes {
+		if _, ok := protoTarget(n.Shape).Shape.(*ObjectShape); !ok {
+			continue
+		}
+		name := n.Name
+		if table, ok := n.Shape.annotationValue(AnnotationSQLTable).(string); ok {
+			name = table
+		}
+		if err := c.writeTable(&sb, tables.Unique(c.opts.naming.Snake(name)), name, n.Shape); err != nil {
+			return nil, fmt.Errorf("convert type %s: %w", n.Name, err)
+		}
+	}
+	return []byte(sb.String()), nil
+}
+
+func (c *SQLConverter) qualify(name string) string {
+	if c.opts.schema == "" {
+		return name
+	}
+	return c.opts.naming.Snake(c.opts.schema) + "." + name
+}
+
+// writeTable writes the table of the object type. Names of indexes are prefixed with the name of the table before
+// the conversion.
+func (c *SQLConverter) writeTable(sb *strings.Builder, table string, name string, s *BaseShape) error {
+	var lines, primaryKey, unique []string
+	var indexes []*sqlIndex
+	byName := make(map[string]*sqlIndex)
+	columns := NewNameScope()
+	for _, p := range protoProperties(s) {
+		column := columns.Unique(c.opts.naming.Snake(p.Name))
+		resolved, err := c.r.UnwrapShape(p.Shape.CloneDetached())
+		if err != nil {
+			return fmt.Errorf("property %s: unwrap: %w", p.Name, err)
+		}
+		def, err := c.column(column, resolved, p.Required)
+		if err != nil {
+			return fmt.Errorf("property %s: %w", p.Name, err)
+		}
+		lines = append(lines, def)
+		if resolved.annotationValue(AnnotationPrimaryKey) == true {
+			primaryKey = append(primaryKey, column)
+		}
+		if resolved.annotationValue(AnnotationUnique) == true {
+			unique = append(unique, column)
+		}
+		switch v := resolved.annotationValue(AnnotationIndex).(type) {
+		case bool:
+			if v {
+				idx := &sqlIndex{name: c.opts.naming.Snake(name + " " + p.Name + " idx"), columns: []string{column}}
+				indexes = append(indexes, idx)
+			}
+		case string:
+			idx, ok := byName[v]
+			if !ok {
+				idx = &sqlIndex{name: c.opts.naming.Snake(v)}
+				byName[v] = idx
+				indexes = append(indexes, idx)
+			}
+			idx.columns = append(idx.columns, column)
+		}
+	}
+	if len(primaryKey) > 0 {
+		lines = append(lines, "PRIMARY KEY ("+strings.Join(primaryKey, ", ")+")")
+	}
+	for _, column := range unique {
+		lines = append(lines, "UNIQUE ("+column+")")
+	}
+	fmt.Fprintf(sb, "\nCREATE TABLE %s (\n", c.qualify(table))
+	for i, line := range lines {
+		sb.WriteString("  " + line)
+		if i < len(lines)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(");\n")
+	if s.Description != nil && strings.TrimSpace(*s.Description) != "" {
+		fmt.Fprintf(sb, "COMMENT ON TABLE %s IS %s;\n", c.qualify(table),
+			sqlString(strings.TrimSpace(*s.Description)))
+	}
+	for _, idx := range indexes {
+		fmt.Fprintf(sb, "CREATE INDEX %s ON %s (%s);\n", idx.name, c.qualify(table),
+			strings.Join(idx.columns, ", "))
+	}
+	return nil
+}
+
+// column returns the definition of the column for the resolved shape of the property.
+func (c *SQLConverter) column(column string, s *BaseShape, required bool) (string, error) {
+	nullable := false
+	target := s
+	if u, ok := s.Shape.(*UnionShape); ok {
+		var members []*BaseShape
+		for _, member := range u.AnyOf {
+			if _, isNil := member.Shape.(*NilShape); isNil {
+				nullable = true
+				continue
+			}
+			members = append(members, member)
+		}
+		if nullable && len(members) == 1 {
+			target = members[0]
+		}
+	}
+	typ, checks, err := c.columnType(column, target)
+	if err != nil {
+		return "", err
+	}
+	def := column + " " + typ
+	if required && !nullable {
+		def += " NOT NULL"
+	}
+	if target.Default != nil {
+		if literal, ok := sqlLiteral(target.Default.Value); ok {
+			def += " DEFAULT " + literal
+		}
+	}
+	if len(checks) > 0 {
+		def += " CHECK (" + strings.Join(checks, " AND ") + ")"
+	}
+	return def, nil
+}
+
+// columnType returns the column type and the check constraints of the shape.
+func (c *SQLConverter) columnType(column string, s *BaseShape) (string, []string, error) {
+	switch shape := s.Shape.(type) {
+	case *StringShape:
+		var checks []string
+		typ := "text"
+		if shape.MaxLength != nil {
+			typ = "varchar(" + strconv.FormatUint(*shape.MaxLength, 10) + ")"
+		}
+		if shape.MinLength != nil && *shape.MinLength > 0 {
+			checks = append(checks, fmt.Sprintf("char_length(%s) >= %d", column, *shape.MinLength))
+		}
+		if shape.Pattern != nil {
+			checks = append(checks, column+" ~ "+sqlString(shape.Pattern.String()))
+		}
+		return typ, append(checks, sqlEnumCheck(column, shape.Enum)...), nil
+	case *IntegerShape:
+		typ := "bigint"
+		if shape.Format != nil {
+			switch *shape.Format {
+			case "int8", "int16":
+				typ = "smallint"
+			case "int32", "int":
+				typ = "integer"
+			}
+		}
+		var checks []string
+		if shape.Minimum != nil {
+			checks = append(checks, sqlBound(column, s, AnnotationExclusiveMinimum, ">", shape.Minimum.String()))
+		}
+		if shape.Maximum != nil {
+			checks = append(checks, sqlBound(column, s, AnnotationExclusiveMaximum, "<", shape.Maximum.String()))
+		}
+		return typ, append(checks, sqlEnumCheck(column, shape.Enum)...), nil
+	case *NumberShape:
+		typ := "numeric"
+		if shape.Format != nil {
+			switch *shape.Format {
+			case "float":
+				typ = "real"
+			case "double":
+				typ = "double precision"
+			}
+		}
+		var checks []string
+		if shape.Minimum != nil {
+			checks = append(checks, sqlBound(column, s, AnnotationExclusiveMinimum, ">", formatFloat(*shape.Minimum)))
+		}
+		if shape.Maximum != nil {
+			checks = append(checks, sqlBound(column, s, AnnotationExclusiveMaximum, "<", formatFloat(*shape.Maximum)))
+		}
+		return typ, append(checks, sqlEnumCheck(column, shape.Enum)...), nil
+	case *BooleanShape:
+		return "boolean", nil, nil
+	case *DateTimeShape:
+		return "timestamptz", nil, nil
+	case *DateTimeOnlyShape:
+		return "timestamp", nil, nil
+	case *DateOnlyShape:
+		return "date", nil, nil
+	case *TimeOnlyShape:
+		return "time", nil, nil
+	case *FileShape:
+		return "bytea", nil, nil
+	case *ArrayShape:
+		if shape.Items != nil {
+			switch shape.Items.Shape.(type) {
+			case *ArrayShape, *ObjectShape, *UnionShape, *AnyShape, *JSONShape, *RecursiveShape, *NilShape:
+			default:
+				// Facets of items cannot be checked for elements of arrays.
+				typ, _, err := c.columnType(column, shape.Items)
+				if err != nil {
+					return "", nil, err
+				}
+				return typ + "[]", nil, nil
+			}
+		}
+		return "jsonb", nil, nil
+	case *ObjectShape, *UnionShape, *AnyShape, *JSONShape, *RecursiveShape:
+		return "jsonb", nil, nil
+	default:
+		return "", nil, fmt.Errorf("type %s is not supported", s.Type)
+	}
+}
+
+func sqlBound(column string, s *BaseShape, exclusive string, op string, value string) string {
+	if !s.isExclusiveBound(exclusive) {
+		op += "="
+	}
+	return column + " " + op + " " + value
+}
+
+func sqlEnumCheck(column string, enum Nodes) []string {
+	if enum == nil {
+		return nil
+	}
+	values := make([]string, 0, len(enum))
+	for _, v := range enum {
+		if literal, ok := sqlLiteral(v.Value); ok {
+			values = append(values, literal)
+		}
+	}
+	return []string{column + " IN (" + strings.Join(values, ", ") + ")"}
+}
+
+// sqlLiteral returns the SQL literal of the scalar value.
+func sqlLiteral(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return sqlString(v), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int, int64, uint64:
+		return fmt.Sprint(v), true
+	case float64:
+		return formatFloat(v), true
+	}
+	return "", false
+}
+
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,96 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLConverter(t *testing.T) {
+	content := `#%RAML 1.0 Library
+annotationTypes:
+  table: string
+  primaryKey: boolean
+  unique: boolean
+  index: boolean | string
+  exclusiveMinimum: boolean
+types:
+  Name:
+    type: string
+    minLength: 1
+    maxLength: 100
+  Entity:
+    properties:
+      id:
+        type: integer
+        format: int64
+        (primaryKey): true
+      createdAt:
+        type: datetime
+        default: 2024-01-01T00:00:00Z
+  Pet:
+    type: Entity
+    description: A pet.
+    (table): pets
+    properties:
+      name:
+        type: Name
+        (unique): true
+      user:
+        type: string
+        pattern: ^[a-z]+$
+        (index): true
+      ownerId:
+        type: integer
+        format: int32
+        (index): owner_tenant_idx
+      tenant:
+        type: string
+        (index): owner_tenant_idx
+      status:
+        enum: [active, it's blocked]
+        default: active
+      weight:
+        type: number
+        minimum: 0
+        (exclusiveMinimum): true
+      tag: string | nil
+      tags: string[]
+      meta?: object
+      born?: date-only
+  Status:
+    type: string
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+	b, err := NewSQLConverter(WithSQLSchema("app")).Convert(rml)
+	require.NoError(t, err)
+	require.Equal(t, `-- Code generated by go-raml. DO NOT EDIT.
+
+CREATE TABLE app.entity (
+  id bigint NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT '2024-01-01T00:00:00Z',
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE app.pets (
+  id bigint NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT '2024-01-01T00:00:00Z',
+  name varchar(100) NOT NULL CHECK (char_length(name) >= 1),
+  "user" text NOT NULL CHECK ("user" ~ '^[a-z]+$'),
+  owner_id integer NOT NULL,
+  tenant text NOT NULL,
+  status text NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'it''s blocked')),
+  weight numeric NOT NULL CHECK (weight > 0),
+  tag text,
+  tags text[] NOT NULL,
+  meta jsonb,
+  born date,
+  PRIMARY KEY (id),
+  UNIQUE (name)
+);
+COMMENT ON TABLE app.pets IS 'A pet.';
+CREATE INDEX pets_user_idx ON app.pets ("user");
+CREATE INDEX owner_tenant_idx ON app.pets (owner_id, tenant);
+`, string(b))
+}
@@ -0,0 +1,98 @@
+package raml
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// StringLength defines how minLength and maxLength of string instances are counted.
+type StringLength int
+
+const (
+	// StringLengthBytes counts UTF-8 bytes. This is the default.
+	StringLengthBytes StringLength = iota
+	// StringLengthCodePoints counts Unicode code points.
+	StringLengthCodePoints
+	// StringLengthGraphemes counts user-perceived characters, so combining marks, emoji modifiers and sequences
+	// joined by zero width joiners do not add to the length and pairs of regional indicators count as one flag.
+	StringLengthGraphemes
+)
+
+type parseOptWithStringLength struct {
+	mode StringLength
+}
+
+func (o parseOptWithStringLength) Apply(opt *parserOptions) {
+	opt.stringLength = o.mode
+}
+
+// OptWithStringLength sets how minLength and maxLength of string instances are counted.
+func OptWithStringLength(mode StringLength) ParseOpt {
+	return parseOptWithStringLength{mode: mode}
+}
+
+// stringLength returns the counting mode of the RAML the shape belongs to.
+func (s *BaseShape) stringLength() StringLength {
+	if s == nil || s.raml == nil {
+		return StringLengthBytes
+	}
+	return s.raml.stringLength
+}
+
+// countLength returns the length of the string in the units of the mode.
+func countLength(str string, mode StringLength) uint64 {
+	switch mode {
+	case StringLengthCodePoints:
+		return uint64(utf8.RuneCountInString(str))
+	case StringLengthGraphemes:
+		return countGraphemes(str)
+	default:
+		return uint64(len(str))
+	}
+}
+
+const (
+	zeroWidthJoiner = '\u200d'
+	// Emoji modifiers are skin tone modifiers that follow emoji.
+	emojiModifierFirst = '\U0001F3FB'
+	emojiModifierLast  = '\U0001F3FF'
+	// Regional indicators are paired into flags.
+	regionalIndicatorFirst = '\U0001F1E6'
+	regionalIndicatorLast  = '\U0001F1FF'
+)
+
+// countGraphemes approximates the number of extended grapheme clusters of Unicode Standard Annex #29 without
+// the full tables of grapheme break properties.
+func countGraphemes(str string) uint64 {
+	var n uint64
+	joined, regional := false, false
+	for _, r := range str {
+		switch {
+		case r == zeroWidthJoiner:
+			joined = true
+			continue
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc), r >= emojiModifierFirst && r <= emojiModifierLast:
+			// Marks and modifiers extend the previous character.
+			if n == 0 {
+				n++
+			}
+			continue
+		case r >= regionalIndicatorFirst && r <= regionalIndicatorLast:
+			if regional {
+				// The second indicator of the pair.
+				regional = false
+				continue
+			}
+			regional = true
+		default:
+			regional = false
+		}
+		if joined && n > 0 {
+			joined = false
+			continue
+		}
+		joined = false
+		n++
+	}
+	return n
+}
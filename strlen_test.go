@@ -0,0 +1,64 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_countLength(t *testing.T) {
+	tests := []struct {
+		name       string
+		str        string
+		bytes      uint64
+		codePoints uint64
+		graphemes  uint64
+	}{
+		{name: "ascii", str: "hello", bytes: 5, codePoints: 5, graphemes: 5},
+		{name: "cyrillic", str: "привет", bytes: 12, codePoints: 6, graphemes: 6},
+		{name: "combining mark", str: "e\u0301te\u0301", bytes: 7, codePoints: 5, graphemes: 3},
+		{name: "emoji with modifier", str: "\U0001F44D\U0001F3FD!", bytes: 9, codePoints: 3, graphemes: 2},
+		{name: "zwj sequence", str: "\U0001F468\u200d\U0001F469\u200d\U0001F467", bytes: 18, codePoints: 5, graphemes: 1},
+		{name: "flags", str: "\U0001F1FA\U0001F1E6\U0001F1E9\U0001F1EA", bytes: 16, codePoints: 4, graphemes: 2},
+		{name: "leading mark", str: "\u0301a", bytes: 3, codePoints: 2, graphemes: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.bytes, countLength(tt.str, StringLengthBytes))
+			require.Equal(t, tt.codePoints, countLength(tt.str, StringLengthCodePoints))
+			require.Equal(t, tt.graphemes, countLength(tt.str, StringLengthGraphemes))
+		})
+	}
+}
+
+func TestOptWithStringLength(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Name:
+    type: string
+    minLength: 2
+    maxLength: 5
+`
+	parse := func(opts ...ParseOpt) *BaseShape {
+		rml, err := ParseFromString(content, "library.raml", t.TempDir(), opts...)
+		require.NoError(t, err)
+		name, err := rml.LookupType("Name", "")
+		require.NoError(t, err)
+		return name
+	}
+
+	name := parse()
+	require.ErrorContains(t, name.Validate("привет"), "length must be less than 5")
+	require.NoError(t, name.Validate("пр"))
+
+	name = parse(OptWithStringLength(StringLengthCodePoints))
+	require.NoError(t, name.Validate("приве"))
+	require.ErrorContains(t, name.Validate("привет"), "length must be less than 5")
+	require.ErrorContains(t, name.Validate("я"), "length must be greater than 2")
+	require.ErrorContains(t, name.Validate("e\u0301e\u0301e\u0301"), "length must be less than 5")
+
+	name = parse(OptWithStringLength(StringLengthGraphemes))
+	require.NoError(t, name.Validate("e\u0301e\u0301e\u0301"))
+	require.ErrorContains(t, name.Validate("\U0001F468\u200d\U0001F469\u200d\U0001F467"),
+		"length must be greater than 2")
+}
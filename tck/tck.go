@@ -0,0 +1,281 @@
+// Package tck runs the RAML test compatibility kit (https://github.com/raml-org/raml-tck) against the parser and
+// reports whether every test passes, so users can gauge the conformance of the version they embed:
+//
+//	report, err := tck.Run(ctx, "raml-tck/tests/raml-1.0")
+//	fmt.Printf("%d of %d tests passed\n", report.Passed, report.Total)
+//
+// A test is a RAML file of the corpus that is parsed and validated with raml.OptWithValidate. Files with the word
+// "invalid" in their names, e.g. "api-invalid.raml" or "invalid-uses.raml", must be rejected, others must be
+// accepted. Entry points of a directory are listed by "filePaths" of its manifest.json if it exists, otherwise all
+// files of the directory with the "#%RAML" header are entry points; included files without the header are not tests.
+package tck
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/acronis/go-raml"
+)
+
+// ManifestFile is the name of the file that lists entry points of a directory of the corpus.
+const ManifestFile = "manifest.json"
+
+// Expectation is the expected outcome of parsing a test.
+type Expectation string
+
+const (
+	ExpectValid   Expectation = "valid"
+	ExpectInvalid Expectation = "invalid"
+)
+
+var invalidName = regexp.MustCompile(`(^|[^a-z])invalid([^a-z]|$)`)
+
+// Test is a test of the corpus.
+type Test struct {
+	// Name is the slash-separated path of the file relative to the root of the corpus, e.g. "Api/api-invalid.raml".
+	Name string `json:"name"`
+	// Group is the top-level directory of the test, e.g. "Api", which is the feature of the specification it covers.
+	Group  string      `json:"group"`
+	Expect Expectation `json:"expect"`
+
+	path string
+}
+
+// Result is the outcome of a test.
+type Result struct {
+	Test
+	Passed bool `json:"passed"`
+	// Error is the error of the parser, empty if the test is accepted.
+	Error string `json:"error,omitempty"`
+	// Duration is the time of parsing, in nanoseconds in JSON.
+	Duration time.Duration `json:"duration"`
+}
+
+// GroupSummary counts results of tests of the group.
+type GroupSummary struct {
+	Name   string `json:"name"`
+	Total  int    `json:"total"`
+	Passed int    `json:"passed"`
+}
+
+// Report contains results of tests sorted by name.
+type Report struct {
+	Total   int            `json:"total"`
+	Passed  int            `json:"passed"`
+	Failed  int            `json:"failed"`
+	Groups  []GroupSummary `json:"groups"`
+	Results []Result       `json:"results"`
+}
+
+// Failures returns results of failed tests.
+func (r *Report) Failures() []Result {
+	var failures []Result
+	for _, res := range r.Results {
+		if !res.Passed {
+			failures = append(failures, res)
+		}
+	}
+	return failures
+}
+
+type Opt interface {
+	Apply(*Options)
+}
+
+type Options struct {
+	filter    *regexp.Regexp
+	parseOpts []raml.ParseOpt
+}
+
+type optFilter struct {
+	filter *regexp.Regexp
+}
+
+func (o optFilter) Apply(opts *Options) {
+	opts.filter = o.filter
+}
+
+// WithFilter runs only tests whose names match the regular expression.
+func WithFilter(filter *regexp.Regexp) Opt {
+	return optFilter{filter: filter}
+}
+
+type optParseOpts struct {
+	opts []raml.ParseOpt
+}
+
+func (o optParseOpts) Apply(opts *Options) {
+	opts.parseOpts = append(opts.parseOpts, o.opts...)
+}
+
+// WithParseOpts adds options of the parser, e.g. raml.OptWithInheritancePolicy, to check conformance of the
+// configuration the parser is embedded with.
+func WithParseOpts(opts ...raml.ParseOpt) Opt {
+	return optParseOpts{opts: opts}
+}
+
+// Discover returns tests of the corpus in the root directory sorted by name.
+func Discover(root string) ([]Test, error) {
+	var tests []Test
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		entries, err := entryPoints(path)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			rel, err := filepath.Rel(root, entry)
+			if err != nil {
+				return fmt.Errorf("relative path: %w", err)
+			}
+			name := filepath.ToSlash(rel)
+			group, _, _ := strings.Cut(name, "/")
+			if group == name {
+				group = ""
+			}
+			expect := ExpectValid
+			if invalidName.MatchString(strings.ToLower(filepath.Base(entry))) {
+				expect = ExpectInvalid
+			}
+			tests = append(tests, Test{Name: name, Group: group, Expect: expect, path: entry})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("discover tests: %w", err)
+	}
+	slices.SortFunc(tests, func(a, b Test) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return tests, nil
+}
+
+type manifest struct {
+	FilePaths []string `json:"filePaths"`
+}
+
+// entryPoints returns paths of entry points of the directory.
+func entryPoints(dir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	switch {
+	case err == nil:
+		var m manifest
+		if err = json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", filepath.Join(dir, ManifestFile), err)
+		}
+		paths := make([]string, 0, len(m.FilePaths))
+		for _, p := range m.FilePaths {
+			paths = append(paths, filepath.Join(dir, filepath.FromSlash(p)))
+		}
+		return paths, nil
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".raml" {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		ok, err := hasRAMLHeader(path)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+func hasRAMLHeader(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return false, nil
+	}
+	return strings.HasPrefix(line, "#%RAML"), nil
+}
+
+// Run discovers tests of the corpus in the root directory and runs them.
+func Run(ctx context.Context, root string, opts ...Opt) (*Report, error) {
+	tests, err := Discover(root)
+	if err != nil {
+		return nil, err
+	}
+	return RunTests(ctx, tests, opts...)
+}
+
+// RunTests runs the discovered tests. A test passes if the parser accepts a valid test or rejects an invalid one.
+func RunTests(ctx context.Context, tests []Test, opts ...Opt) (*Report, error) {
+	var o Options
+	for _, opt := range opts {
+		opt.Apply(&o)
+	}
+	parseOpts := append([]raml.ParseOpt{raml.OptWithValidate()}, o.parseOpts...)
+	report := &Report{}
+	groups := make(map[string]*GroupSummary)
+	for _, t := range tests {
+		if o.filter != nil && !o.filter.MatchString(t.Name) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		_, err := raml.ParseFromPathCtx(ctx, t.path, parseOpts...)
+		res := Result{Test: t, Duration: time.Since(start)}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		if err != nil {
+			res.Error = err.Error()
+		}
+		res.Passed = (err == nil) == (t.Expect == ExpectValid)
+		report.Results = append(report.Results, res)
+
+		g, ok := groups[t.Group]
+		if !ok {
+			g = &GroupSummary{Name: t.Group}
+			groups[t.Group] = g
+		}
+		g.Total++
+		report.Total++
+		if res.Passed {
+			g.Passed++
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+	}
+	for _, g := range groups {
+		report.Groups = append(report.Groups, *g)
+	}
+	slices.SortFunc(report.Groups, func(a, b GroupSummary) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return report, nil
+}
@@ -0,0 +1,86 @@
+package tck
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeCorpus(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	return root
+}
+
+func TestRun(t *testing.T) {
+	root := writeCorpus(t, map[string]string{
+		"Api/api-valid.raml":   "#%RAML 1.0\ntitle: API\n",
+		"Api/api-invalid.raml": "#%RAML 1.0\nversion: v1\n",
+		// The parser accepts the file, so the test fails.
+		"Api/invalid-unknown-facet.raml": "#%RAML 1.0\ntitle: API\n",
+		"Types/types-valid.raml":         "#%RAML 1.0 Library\nusage: !include usage.raml\ntypes:\n  A: string\n",
+		"Types/usage.raml":               "Included text is not a test.\n",
+		"Types/examples/example-invalid.raml": `#%RAML 1.0 Library
+types:
+  A:
+    type: integer
+    example: abc
+`,
+		"Uses/manifest.json":   `{"filePaths": ["main.raml"]}`,
+		"Uses/main.raml":       "#%RAML 1.0 Library\nuses:\n  lib: lib.raml\n",
+		"Uses/lib.raml":        "#%RAML 1.0 Library\nusage: Lib\n",
+		"Uses/other-lib.raml":  "#%RAML 1.0 Library\nusage: Other\n",
+		"README.md":            "not a test",
+		"Root/root-valid.yaml": "#%RAML 1.0\ntitle: API\n",
+	})
+	tests, err := Discover(root)
+	require.NoError(t, err)
+	var names []string
+	for _, test := range tests {
+		names = append(names, test.Name)
+	}
+	require.Equal(t, []string{
+		"Api/api-invalid.raml",
+		"Api/api-valid.raml",
+		"Api/invalid-unknown-facet.raml",
+		"Types/examples/example-invalid.raml",
+		"Types/types-valid.raml",
+		"Uses/main.raml",
+	}, names)
+	require.Equal(t, ExpectInvalid, tests[0].Expect)
+	require.Equal(t, ExpectValid, tests[1].Expect)
+	require.Equal(t, "Types", tests[3].Group)
+
+	report, err := Run(context.Background(), root)
+	require.NoError(t, err)
+	require.Equal(t, 6, report.Total)
+	require.Equal(t, 5, report.Passed)
+	require.Equal(t, 1, report.Failed)
+	require.Equal(t, []GroupSummary{
+		{Name: "Api", Total: 3, Passed: 2},
+		{Name: "Types", Total: 2, Passed: 2},
+		{Name: "Uses", Total: 1, Passed: 1},
+	}, report.Groups)
+	failures := report.Failures()
+	require.Len(t, failures, 1)
+	require.Equal(t, "Api/invalid-unknown-facet.raml", failures[0].Name)
+	require.Empty(t, failures[0].Error)
+	require.NotEmpty(t, report.Results[0].Error)
+
+	report, err = Run(context.Background(), root, WithFilter(regexp.MustCompile(`^Types/`)))
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Total)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Run(ctx, root)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,623 @@
+package raml
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// TemplateExt is the extension of files rendered by TemplateGenerator.Generate.
+const TemplateExt = ".tmpl"
+
+// TemplateTypeMapping maps RAML types to type names of a target language for the typeName and typeDefinition
+// template functions. Format strings receive the name of the element type, e.g. "[]%s".
+type TemplateTypeMapping struct {
+	// Scalars maps RAML scalar types, e.g. "string" or "datetime", and formats of integers and numbers,
+	// e.g. "int64", to type names. Formats take precedence over types.
+	Scalars map[string]string
+	// Array formats arrays from the type of items.
+	Array string
+	// Map formats objects that only have pattern properties from the type of values. If empty, they are Object.
+	Map string
+	// Object is the type of inline objects that cannot be named.
+	Object string
+	// Nullable formats unions of a type and nil from the type.
+	Nullable string
+	// Union joins member types of unions. If empty, unions are Any.
+	Union string
+	// Any is the type of any, JSON schemas and unions that cannot be mapped.
+	Any string
+	// Name converts qualified names of declared types, e.g. "common.Pet", to type names. By default, they are
+	// Identifier of TemplateType.
+	Name func(string) string
+}
+
+// Built-in type mappings of TemplateGenerator.
+var (
+	TemplateTypeMappingGo = TemplateTypeMapping{
+		Scalars: map[string]string{
+			TypeString: "string", TypeInteger: "int", TypeNumber: "float64", TypeBoolean: "bool",
+			TypeDatetime: "time.Time", TypeDatetimeOnly: "string", TypeDateOnly: "string", TypeTimeOnly: "string",
+			TypeFile: "[]byte", TypeNil: "any",
+			"int8": "int8", "int16": "int16", "int32": "int32", "int64": "int64", "int": "int", "long": "int64",
+			"float": "float32", "double": "float64",
+		},
+		Array:    "[]%s",
+		Map:      "map[string]%s",
+		Object:   "map[string]any",
+		Nullable: "*%s",
+		Any:      "any",
+	}
+	TemplateTypeMappingTypeScript = TemplateTypeMapping{
+		Scalars: map[string]string{
+			TypeString: "string", TypeInteger: "number", TypeNumber: "number", TypeBoolean: "boolean",
+			TypeDatetime: "string", TypeDatetimeOnly: "string", TypeDateOnly: "string", TypeTimeOnly: "string",
+			TypeFile: "string", TypeNil: "null",
+		},
+		Array:    "Array<%s>",
+		Map:      "Record<string, %s>",
+		Object:   "Record<string, unknown>",
+		Nullable: "%s | null",
+		Union:    " | ",
+		Any:      "unknown",
+	}
+)
+
+type TemplateGeneratorOpt interface {
+	Apply(*TemplateGeneratorOptions)
+}
+
+type TemplateGeneratorOptions struct {
+	funcs    template.FuncMap
+	params   map[string]string
+	mappings map[string]TemplateTypeMapping
+	naming   Naming
+}
+
+type optTemplateFuncs struct {
+	funcs template.FuncMap
+}
+
+func (o optTemplateFuncs) Apply(opts *TemplateGeneratorOptions) {
+	for name, fn := range o.funcs {
+		opts.funcs[name] = fn
+	}
+}
+
+// WithTemplateFuncs adds functions to templates. Functions with names of built-in helpers replace them.
+func WithTemplateFuncs(funcs template.FuncMap) TemplateGeneratorOpt {
+	return optTemplateFuncs{funcs: funcs}
+}
+
+type optTemplateParams struct {
+	params map[string]string
+}
+
+func (o optTemplateParams) Apply(opts *TemplateGeneratorOptions) {
+	for k, v := range o.params {
+		opts.params[k] = v
+	}
+}
+
+// WithTemplateParams passes user-defined parameters to templates as .Params, e.g. the package name of generated code.
+func WithTemplateParams(params map[string]string) TemplateGeneratorOpt {
+	return optTemplateParams{params: params}
+}
+
+type optTemplateTypeMapping struct {
+	lang    string
+	mapping TemplateTypeMapping
+}
+
+func (o optTemplateTypeMapping) Apply(opts *TemplateGeneratorOptions) {
+	opts.mappings[o.lang] = o.mapping
+}
+
+// WithTemplateTypeMapping registers the type mapping of the language, e.g. {{typeName "kotlin" .Shape}}.
+// The built-in "go" and "typescript" mappings can be replaced.
+func WithTemplateTypeMapping(lang string, mapping TemplateTypeMapping) TemplateGeneratorOpt {
+	return optTemplateTypeMapping{lang: lang, mapping: mapping}
+}
+
+type optTemplateNaming struct {
+	naming Naming
+}
+
+func (o optTemplateNaming) Apply(opts *TemplateGeneratorOptions) {
+	opts.naming = o.naming
+}
+
+// WithTemplateNaming sets the naming of the casing functions and of type names, the zero Naming by default,
+// e.g. NamingGo for Go code.
+func WithTemplateNaming(naming Naming) TemplateGeneratorOpt {
+	return optTemplateNaming{naming: naming}
+}
+
+// TemplateGenerator renders Go text/template templates with the model of declared types, so users can build
+// generators of code, DDL or documentation without modifying the library.
+//
+// Templates receive TemplateData and can use the helpers of Funcs:
+//
+//	{{range .Types}}{{if eq (kind .Resolved) "object"}}
+//	type {{pascal .Name}} struct {
+//	{{- range properties .Shape}}
+//		{{pascal .Name}} {{typeName "go" .Shape}} `json:"{{.Name}}"`
+//	{{- end}}
+//	}
+//	{{end}}{{end}}
+//
+// Types are the nodes of RAML.TypeGraph.
+type TemplateGenerator struct {
+	opts TemplateGeneratorOptions
+}
+
+func NewTemplateGenerator(opts ...TemplateGeneratorOpt) *TemplateGenerator {
+	g := &TemplateGenerator{opts: TemplateGeneratorOptions{
+		funcs:  make(template.FuncMap),
+		params: make(map[string]string),
+		mappings: map[string]TemplateTypeMapping{
+			"go":         TemplateTypeMappingGo,
+			"typescript": TemplateTypeMappingTypeScript,
+		},
+	}}
+	for _, opt := range opts {
+		opt.Apply(&g.opts)
+	}
+	return g
+}
+
+// TemplateData is the data templates of TemplateGenerator are executed with.
+type TemplateData struct {
+	// Title is the title of the API or the usage of the library.
+	Title string
+	// RAML is the parsed model, e.g. to render resources with {{with .RAML.API}}...{{end}}.
+	RAML *RAML
+	// Types contains types declared by the entry point, libraries it uses and included data types, sorted by name.
+	Types  []*TemplateType
+	Params map[string]string
+}
+
+// TemplateType is a declared type. Shape of the node keeps references to other declared types.
+type TemplateType struct {
+	*TypeGraphNode
+	// Identifier is the name in CasePascal by the naming of the generator. Colliding identifiers get number
+	// suffixes, e.g. "CommonPet2".
+	Identifier string
+	// Resolved is the shape with facets and properties resolved through inheritance.
+	Resolved *BaseShape
+}
+
+// GeneratedFile is a file rendered by TemplateGenerator.Generate.
+type GeneratedFile struct {
+	// Path is the slash-separated path relative to the output directory.
+	Path    string
+	Content []byte
+}
+
+// templateModel resolves names and facets of declared types for the template functions.
+type templateModel struct {
+	r *RAML
+	// names maps IDs of declared types to their qualified names.
+	names map[int64]string
+	// identifiers maps qualified names of declared types to their collision-free identifiers.
+	identifiers map[string]string
+	// declarations maps IDs of properties of declared object types to their declarations, since properties of
+	// resolved shapes have the same IDs but no references.
+	declarations map[int64]*BaseShape
+	resolved     map[int64]*BaseShape
+	graph        *TypeGraph
+}
+
+func newTemplateModel(r *RAML, naming Naming) *templateModel {
+	m := &templateModel{
+		r:            r,
+		names:        make(map[int64]string),
+		identifiers:  make(map[string]string),
+		declarations: make(map[int64]*BaseShape),
+		resolved:     make(map[int64]*BaseShape),
+		graph:        r.TypeGraph(),
+	}
+	scope := NewNameScope()
+	for _, n := range m.graph.Nodes {
+		m.names[n.Shape.ID] = n.Name
+		m.identifiers[n.Name] = scope.Unique(naming.Pascal(n.Name))
+		if obj, ok := n.Shape.Shape.(*ObjectShape); ok {
+			for pair := obj.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				m.declarations[pair.Value.Shape.ID] = pair.Value.Shape
+			}
+			for pair := obj.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+				m.declarations[pair.Value.Shape.ID] = pair.Value.Shape
+			}
+		}
+	}
+	return m
+}
+
+// Data returns the data templates are executed with.
+func (g *TemplateGenerator) Data(r *RAML) (*TemplateData, error) {
+	return g.data(newTemplateModel(r, g.opts.naming))
+}
+
+func (g *TemplateGenerator) data(m *templateModel) (*TemplateData, error) {
+	data := &TemplateData{RAML: m.r, Params: g.opts.params}
+	if lib, ok := m.r.EntryPoint().(*Library); ok {
+		data.Title = lib.Usage
+	}
+	if api := m.r.API(); api != nil {
+		data.Title = api.Title
+	}
+	for _, n := range m.graph.Nodes {
+		resolved, err := m.resolve(n.Shape)
+		if err != nil {
+			return nil, fmt.Errorf("resolve type %s: %w", n.Name, err)
+		}
+		data.Types = append(data.Types, &TemplateType{
+			TypeGraphNode: n,
+			Identifier:    m.identifiers[n.Name],
+			Resolved:      resolved,
+		})
+	}
+	return data, nil
+}
+
+// Funcs returns the template functions for the model:
+//
+//   - pascal, camel, snake, kebab, screamingSnake convert names to the case, e.g. {{snake "ownerID"}} gives "owner_id";
+//   - typeName LANG SHAPE returns the type name of the shape by the type mapping of the language, i.e. the name of
+//     the declared type or the definition of an inline type;
+//   - typeDefinition LANG SHAPE returns the definition of the declared type, e.g. "string" for "ID: string";
+//   - declaredName SHAPE returns the qualified name of the declared type the shape refers to, empty if it is inline;
+//   - resolve SHAPE returns the shape with facets and properties resolved through inheritance;
+//   - kind SHAPE returns the RAML type of the resolved shape, e.g. "object", "union" or "string";
+//   - properties SHAPE returns properties of the resolved object including inherited ones, pattern properties last;
+//   - items SHAPE returns items of the resolved array, nil if they are not declared;
+//   - nullable SHAPE reports whether nil is a valid value of the shape;
+//   - nonNil SHAPE returns the type of "T | nil" unions, otherwise the shape;
+//   - enum SHAPE returns values of the resolved enum facet;
+//   - annotation SHAPE NAME returns the value of the annotation by the name without the namespace;
+//   - join, lower, upper, quote, trimPrefix and trimSuffix are the strings functions.
+func (g *TemplateGenerator) Funcs(r *RAML) template.FuncMap {
+	return g.funcs(newTemplateModel(r, g.opts.naming))
+}
+
+func (g *TemplateGenerator) funcs(m *templateModel) template.FuncMap {
+	mapping := func(lang string) (TemplateTypeMapping, error) {
+		tm, ok := g.opts.mappings[lang]
+		if !ok {
+			return tm, fmt.Errorf("unknown type mapping %q", lang)
+		}
+		return tm, nil
+	}
+	funcs := template.FuncMap{
+		"pascal":         g.opts.naming.Pascal,
+		"camel":          g.opts.naming.Camel,
+		"snake":          g.opts.naming.Snake,
+		"kebab":          g.opts.naming.Kebab,
+		"screamingSnake": g.opts.naming.ScreamingSnake,
+		"typeName": func(lang string, s *BaseShape) (string, error) {
+			tm, err := mapping(lang)
+			if err != nil {
+				return "", err
+			}
+			return m.typeName(tm, s)
+		},
+		"typeDefinition": func(lang string, s *BaseShape) (string, error) {
+			tm, err := mapping(lang)
+			if err != nil {
+				return "", err
+			}
+			return m.typeDefinition(tm, s)
+		},
+		"declaredName": func(s *BaseShape) string {
+			name, _ := m.declaredName(s)
+			return name
+		},
+		"resolve":    m.resolve,
+		"kind":       m.kind,
+		"properties": m.properties,
+		"items":      m.items,
+		"nullable":   m.nullable,
+		"nonNil":     m.nonNil,
+		"enum":       m.enum,
+		"annotation": func(s *BaseShape, name string) any {
+			return s.annotationValue(name)
+		},
+		"join":       func(sep string, elems []string) string { return strings.Join(elems, sep) },
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"quote":      strconv.Quote,
+		"trimPrefix": func(prefix string, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix string, s string) string { return strings.TrimSuffix(s, suffix) },
+	}
+	for name, fn := range g.opts.funcs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// Generate renders files of the template directory with the extension TemplateExt to files with the same paths
+// without the extension, sorted by path. All templates are parsed together, so they can use definitions of each
+// other. Templates whose names start with "_" only contain definitions and are not rendered.
+func (g *TemplateGenerator) Generate(r *RAML, templates fs.FS) ([]GeneratedFile, error) {
+	m := newTemplateModel(r, g.opts.naming)
+	data, err := g.data(m)
+	if err != nil {
+		return nil, err
+	}
+	set := template.New("").Funcs(g.funcs(m))
+	var outputs []string
+	err = fs.WalkDir(templates, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != TemplateExt {
+			return nil
+		}
+		content, err := fs.ReadFile(templates, p)
+		if err != nil {
+			return fmt.Errorf("read template: %w", err)
+		}
+		if _, err = set.New(p).Parse(string(content)); err != nil {
+			return fmt.Errorf("parse template: %w", err)
+		}
+		if !strings.HasPrefix(path.Base(p), "_") {
+			outputs = append(outputs, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(outputs)
+	files := make([]GeneratedFile, 0, len(outputs))
+	for _, p := range outputs {
+		var buf bytes.Buffer
+		if err = set.ExecuteTemplate(&buf, p, data); err != nil {
+			return nil, fmt.Errorf("execute template: %w", err)
+		}
+		files = append(files, GeneratedFile{Path: strings.TrimSuffix(p, TemplateExt), Content: buf.Bytes()})
+	}
+	return files, nil
+}
+
+// declaredName returns the qualified name of the declared type the shape refers to.
+func (m *templateModel) declaredName(s *BaseShape) (string, bool) {
+	for s != nil {
+		if name, ok := m.names[s.ID]; ok {
+			return name, true
+		}
+		switch {
+		case s.Alias != nil:
+			s = s.Alias
+		case s.Link != nil:
+			s = s.Link.Shape
+		default:
+			if rs, ok := s.Shape.(*RecursiveShape); ok {
+				s = rs.Head
+				continue
+			}
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// declaration returns the declaration of the property of the resolved shape that keeps references.
+func (m *templateModel) declaration(s *BaseShape) *BaseShape {
+	if decl, ok := m.declarations[s.ID]; ok {
+		return decl
+	}
+	return s
+}
+
+func (m *templateModel) resolve(s *BaseShape) (*BaseShape, error) {
+	if s.IsUnwrapped() {
+		return s, nil
+	}
+	if resolved, ok := m.resolved[s.ID]; ok {
+		return resolved, nil
+	}
+	resolved, err := m.r.UnwrapShape(s.CloneDetached())
+	if err != nil {
+		return nil, fmt.Errorf("unwrap: %w", err)
+	}
+	m.resolved[s.ID] = resolved
+	return resolved, nil
+}
+
+func (m *templateModel) kind(s *BaseShape) (string, error) {
+	resolved, err := m.resolve(s)
+	if err != nil {
+		return "", err
+	}
+	return shapeKind(resolved), nil
+}
+
+func (m *templateModel) properties(s *BaseShape) ([]Property, error) {
+	resolved, err := m.resolve(s)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := resolved.Shape.(*ObjectShape)
+	if !ok {
+		return nil, nil
+	}
+	var props []Property
+	for pair := obj.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		p := pair.Value
+		p.Shape = m.declaration(p.Shape)
+		props = append(props, p)
+	}
+	for pair := obj.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+		props = append(props, Property{Name: pair.Key, Shape: m.declaration(pair.Value.Shape)})
+	}
+	return props, nil
+}
+
+func (m *templateModel) items(s *BaseShape) (*BaseShape, error) {
+	if arr, ok := s.Shape.(*ArrayShape); ok && arr.Items != nil {
+		return arr.Items, nil
+	}
+	resolved, err := m.resolve(s)
+	if err != nil {
+		return nil, err
+	}
+	if arr, ok := resolved.Shape.(*ArrayShape); ok && arr.Items != nil {
+		return m.declaration(arr.Items), nil
+	}
+	return nil, nil
+}
+
+// target follows references of the shape to the shape that defines the type.
+func (m *templateModel) target(s *BaseShape) *BaseShape {
+	for {
+		switch {
+		case s.Alias != nil:
+			s = s.Alias
+		case s.Link != nil:
+			s = s.Link.Shape
+		default:
+			return s
+		}
+	}
+}
+
+func (m *templateModel) nullable(s *BaseShape) bool {
+	switch shape := m.target(s).Shape.(type) {
+	case *NilShape:
+		return true
+	case *UnionShape:
+		for _, member := range shape.AnyOf {
+			if m.nullable(member) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *templateModel) nonNil(s *BaseShape) *BaseShape {
+	u, ok := m.target(s).Shape.(*UnionShape)
+	if !ok {
+		return s
+	}
+	var members []*BaseShape
+	for _, member := range u.AnyOf {
+		if _, isNil := m.target(member).Shape.(*NilShape); !isNil {
+			members = append(members, member)
+		}
+	}
+	if len(members) == 1 && len(members) < len(u.AnyOf) {
+		return members[0]
+	}
+	return s
+}
+
+func (m *templateModel) enum(s *BaseShape) ([]any, error) {
+	resolved, err := m.resolve(s)
+	if err != nil {
+		return nil, err
+	}
+	es, ok := resolved.Shape.(enumShape)
+	if !ok {
+		return nil, nil
+	}
+	enum := es.enumFacets().Enum
+	values := make([]any, len(enum))
+	for i, v := range enum {
+		values[i] = v.Value
+	}
+	return values, nil
+}
+
+func (m *templateModel) typeName(tm TemplateTypeMapping, s *BaseShape) (string, error) {
+	if name, ok := m.declaredName(s); ok {
+		if tm.Name != nil {
+			return tm.Name(name), nil
+		}
+		return m.identifiers[name], nil
+	}
+	return m.typeDefinition(tm, s)
+}
+
+func (m *templateModel) typeDefinition(tm TemplateTypeMapping, s *BaseShape) (string, error) {
+	switch {
+	case s.Alias != nil:
+		return m.typeName(tm, s.Alias)
+	case s.Link != nil:
+		return m.typeName(tm, s.Link.Shape)
+	}
+	switch shape := s.Shape.(type) {
+	case *ObjectShape:
+		if tm.Map != "" && shape.Properties.Len() == 0 && shape.PatternProperties.Len() == 1 {
+			value, err := m.typeName(tm, shape.PatternProperties.Oldest().Value.Shape)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf(tm.Map, value), nil
+		}
+		return tm.Object, nil
+	case *ArrayShape:
+		items := tm.Any
+		if shape.Items != nil {
+			var err error
+			if items, err = m.typeName(tm, shape.Items); err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf(tm.Array, items), nil
+	case *UnionShape:
+		if nonNil := m.nonNil(s); nonNil != s && tm.Nullable != "" {
+			typ, err := m.typeName(tm, nonNil)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf(tm.Nullable, typ), nil
+		}
+		if tm.Union == "" {
+			return tm.Any, nil
+		}
+		members := make([]string, 0, len(shape.AnyOf))
+		for _, member := range shape.AnyOf {
+			typ, err := m.typeName(tm, member)
+			if err != nil {
+				return "", err
+			}
+			members = append(members, typ)
+		}
+		return strings.Join(members, tm.Union), nil
+	case *RecursiveShape:
+		if _, ok := m.declaredName(shape.Head); ok {
+			return m.typeName(tm, shape.Head)
+		}
+		return "", fmt.Errorf("recursive type is not declared")
+	case *AnyShape, *JSONShape:
+		return tm.Any, nil
+	}
+	// Formats may be inherited from declared types.
+	resolved, err := m.resolve(s)
+	if err != nil {
+		return "", err
+	}
+	var format *string
+	switch shape := resolved.Shape.(type) {
+	case *IntegerShape:
+		format = shape.Format
+	case *NumberShape:
+		format = shape.Format
+	}
+	if format != nil {
+		if typ, ok := tm.Scalars[*format]; ok {
+			return typ, nil
+		}
+	}
+	if typ, ok := tm.Scalars[shapeKind(resolved)]; ok {
+		return typ, nil
+	}
+	return tm.Any, nil
+}
@@ -0,0 +1,131 @@
+package raml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateGenerator(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common.raml"), []byte(`#%RAML 1.0 Library
+types:
+  ID:
+    type: integer
+    format: int64
+  Status:
+    type: string
+    enum: [active, blocked]
+`), 0o600))
+	content := `#%RAML 1.0 Library
+usage: Pets
+uses:
+  common: common.raml
+types:
+  Base:
+    properties:
+      id: common.ID
+  Pet:
+    type: Base
+    properties:
+      ownerID: integer
+      status: common.Status
+      tags: string[]
+      labels:
+        properties:
+          //: string
+      parent: Pet | nil
+      born?: datetime
+`
+	rml, err := ParseFromString(content, "library.raml", dir)
+	require.NoError(t, err)
+
+	templates := fstest.MapFS{
+		"_defs.tmpl": {Data: []byte(`{{define "field"}}{{pascal .Name}} {{typeName "go" .Shape}}{{end}}`)},
+		"models.go.tmpl": {Data: []byte(`package {{.Params.package}}
+{{range .Types}}{{if eq (kind .Shape) "object"}}
+type {{pascal .Name}} struct {
+{{- range properties .Shape}}
+	{{template "field" .}} ` + "`" + `json:"{{camel .Name}}"` + "`" + `
+{{- end}}
+}
+{{else}}
+type {{pascal .Name}} {{typeDefinition "go" .Shape}}
+{{end}}{{end}}`)},
+		"ts/models.ts.tmpl": {Data: []byte(`// {{.Title}}
+{{- range .Types}}{{if enum .Shape}}
+export type {{pascal .Name}} = {{range $i, $v := enum .Shape}}{{if $i}} | {{end}}{{quote $v}}{{end}};
+{{- end}}{{end}}
+{{- range properties (index .Types 1).Shape}}
+{{snake .Name}}{{if nullable .Shape}}?{{end}}: {{typeName "typescript" .Shape}}
+{{- end}}
+`)},
+		"README.md": {Data: []byte("not a template")},
+	}
+	files, err := NewTemplateGenerator(WithTemplateParams(map[string]string{"package": "pets"})).
+		Generate(rml, templates)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, "models.go", files[0].Path)
+	require.Equal(t, `package pets
+
+type Base struct {
+	Id CommonID `+"`"+`json:"id"`+"`"+`
+}
+
+type Pet struct {
+	OwnerID int `+"`"+`json:"ownerID"`+"`"+`
+	Status CommonStatus `+"`"+`json:"status"`+"`"+`
+	Tags []string `+"`"+`json:"tags"`+"`"+`
+	Labels map[string]string `+"`"+`json:"labels"`+"`"+`
+	Parent *Pet `+"`"+`json:"parent"`+"`"+`
+	Born time.Time `+"`"+`json:"born"`+"`"+`
+	Id CommonID `+"`"+`json:"id"`+"`"+`
+}
+
+type CommonID int64
+
+type CommonStatus string
+`, string(files[0].Content))
+	require.Equal(t, "ts/models.ts", files[1].Path)
+	require.Equal(t, `// Pets
+export type CommonStatus = "active" | "blocked";
+owner_id: number
+status: CommonStatus
+tags: Array<string>
+labels: Record<string, string>
+parent?: Pet | null
+born: string
+id: CommonID
+`, string(files[1].Content))
+
+	_, err = NewTemplateGenerator().Generate(rml, fstest.MapFS{
+		"bad.tmpl": {Data: []byte(`{{range .Types}}{{typeName "kotlin" .Shape}}{{end}}`)},
+	})
+	require.ErrorContains(t, err, `unknown type mapping "kotlin"`)
+
+	// Functions and type mappings are extensible.
+	g := NewTemplateGenerator(
+		WithTemplateFuncs(template.FuncMap{"pascal": func(s string) string { return "X" + s }}),
+		WithTemplateTypeMapping("kotlin", TemplateTypeMapping{
+			Scalars: map[string]string{TypeInteger: "Int", "int64": "Long"},
+			Name:    func(name string) string { return "K" + pascalIdentifier(name) },
+		}),
+	)
+	funcs := g.Funcs(rml)
+	require.Equal(t, "XPet", funcs["pascal"].(func(string) string)("Pet"))
+	data, err := g.Data(rml)
+	require.NoError(t, err)
+	require.Equal(t, "Pets", data.Title)
+	typeName := funcs["typeName"].(func(string, *BaseShape) (string, error))
+	name, err := typeName("kotlin", data.Types[0].Shape)
+	require.NoError(t, err)
+	require.Equal(t, "KBase", name)
+	name, err = funcs["typeDefinition"].(func(string, *BaseShape) (string, error))("kotlin", data.Types[2].Shape)
+	require.NoError(t, err)
+	require.Equal(t, "Long", name)
+}
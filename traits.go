@@ -0,0 +1,310 @@
+package raml
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// Trait is a declaration of a trait, a reusable part of methods.
+type Trait struct {
+	Name  string
+	Usage string
+
+	Location string
+	stacktrace.Position
+	node *yaml.Node
+}
+
+// ResourceType is a declaration of a resource type, a reusable part of resources and their methods.
+type ResourceType struct {
+	Name  string
+	Usage string
+
+	Location string
+	stacktrace.Position
+	node *yaml.Node
+}
+
+// templateRef is an application of a trait or a resource type with values of its parameters.
+type templateRef struct {
+	name   string
+	params map[string]string
+	node   *yaml.Node
+}
+
+// templateParamPattern matches parameters of traits and resource types, e.g. "<<resourcePathName | !singularize>>".
+var templateParamPattern = regexp.MustCompile(`<<([^<>]*)>>`)
+
+// decodeTemplateRefs decodes the value of "is" or "type": a name, a map of the name to parameters or a sequence of
+// them.
+func decodeTemplateRefs(node *yaml.Node, location string) ([]templateRef, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == TagNull {
+			return nil, nil
+		}
+		return []templateRef{{name: node.Value, node: node}}, nil
+	case yaml.SequenceNode:
+		var refs []templateRef
+		for _, item := range node.Content {
+			itemRefs, err := decodeTemplateRefs(item, location)
+			if err != nil {
+				return nil, err
+			}
+			refs = append(refs, itemRefs...)
+		}
+		return refs, nil
+	case yaml.MappingNode:
+		refs := make([]templateRef, 0, len(node.Content)/2)
+		for i := 0; i != len(node.Content); i += 2 {
+			ref := templateRef{name: node.Content[i].Value, node: node.Content[i]}
+			paramsNode := node.Content[i+1]
+			if paramsNode.Kind == yaml.MappingNode {
+				ref.params = make(map[string]string, len(paramsNode.Content)/2)
+				for j := 0; j != len(paramsNode.Content); j += 2 {
+					value := paramsNode.Content[j+1]
+					if value.Kind != yaml.ScalarNode {
+						return nil, stacktrace.New("parameter value must be scalar", location,
+							WithNodePosition(value), stacktrace.WithInfo("parameter", paramsNode.Content[j].Value))
+					}
+					ref.params[paramsNode.Content[j].Value] = value.Value
+				}
+			} else if paramsNode.Tag != TagNull {
+				return nil, stacktrace.New("parameters must be map", location, WithNodePosition(paramsNode))
+			}
+			refs = append(refs, ref)
+		}
+		return refs, nil
+	}
+	return nil, stacktrace.New("must be name, map or sequence", location, WithNodePosition(node))
+}
+
+// instantiateTemplate returns a deep copy of the declaration node with parameters substituted. The usage of
+// the declaration is dropped.
+func instantiateTemplate(node *yaml.Node, params map[string]string, location string) (*yaml.Node, error) {
+	if node.Kind != yaml.MappingNode {
+		if node.Tag == TagNull {
+			return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+		}
+		return nil, stacktrace.New("declaration must be map", location, WithNodePosition(node))
+	}
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: node.Tag, Line: node.Line, Column: node.Column}
+	for i := 0; i != len(node.Content); i += 2 {
+		if node.Content[i].Value == "usage" {
+			continue
+		}
+		k, err := copyWithParams(node.Content[i], params, location)
+		if err != nil {
+			return nil, err
+		}
+		v, err := copyWithParams(node.Content[i+1], params, location)
+		if err != nil {
+			return nil, err
+		}
+		out.Content = append(out.Content, k, v)
+	}
+	return out, nil
+}
+
+// copyWithParams returns a deep copy of the node with parameters substituted in scalars.
+func copyWithParams(node *yaml.Node, params map[string]string, location string) (*yaml.Node, error) {
+	out := *node
+	if node.Kind == yaml.ScalarNode {
+		if !strings.Contains(node.Value, "<<") {
+			return &out, nil
+		}
+		value, err := substituteTemplateParams(node.Value, params)
+		if err != nil {
+			return nil, StacktraceNewWrapped("substitute parameters", err, location, WithNodePosition(node))
+		}
+		out.Value = value
+		if node.Style == 0 {
+			// Plain scalars are resolved again, e.g. "maximum: <<max>>" is an integer.
+			out.Tag = ""
+			out.Tag = out.ShortTag()
+		}
+		return &out, nil
+	}
+	out.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		c, err := copyWithParams(child, params, location)
+		if err != nil {
+			return nil, err
+		}
+		out.Content[i] = c
+	}
+	return &out, nil
+}
+
+// substituteTemplateParams substitutes parameters in the string and applies their transformations.
+func substituteTemplateParams(s string, params map[string]string) (string, error) {
+	var err error
+	out := templateParamPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if err != nil {
+			return ""
+		}
+		parts := strings.Split(m[2:len(m)-2], "|")
+		name := strings.TrimSpace(parts[0])
+		value, ok := params[name]
+		if !ok {
+			err = fmt.Errorf("parameter %s is not provided", name)
+			return ""
+		}
+		for _, fn := range parts[1:] {
+			value, err = transformTemplateParam(strings.TrimSpace(fn), value)
+			if err != nil {
+				return ""
+			}
+		}
+		return value
+	})
+	return out, err
+}
+
+// transformTemplateParam applies the transformation function of RAML to the value of a parameter.
+func transformTemplateParam(fn string, value string) (string, error) {
+	switch fn {
+	case "!singularize":
+		return singularize(value), nil
+	case "!pluralize":
+		return pluralize(value), nil
+	case "!uppercase":
+		return strings.ToUpper(value), nil
+	case "!lowercase":
+		return strings.ToLower(value), nil
+	case "!lowercamelcase", "!uppercamelcase":
+		words := splitWords(value)
+		for i, w := range words {
+			words[i] = strings.ToLower(w)
+			if i > 0 || fn == "!uppercamelcase" {
+				words[i] = upperFirst(words[i])
+			}
+		}
+		return strings.Join(words, ""), nil
+	case "!lowerunderscorecase":
+		return strings.ToLower(strings.Join(splitWords(value), "_")), nil
+	case "!upperunderscorecase":
+		return strings.ToUpper(strings.Join(splitWords(value), "_")), nil
+	case "!lowerhyphencase":
+		return strings.ToLower(strings.Join(splitWords(value), "-")), nil
+	case "!upperhyphencase":
+		return strings.ToUpper(strings.Join(splitWords(value), "-")), nil
+	}
+	return "", fmt.Errorf("unknown transformation function %s", fn)
+}
+
+// splitWords splits the value into words at separators and case changes, e.g. "userId", "user_id" and "user-id"
+// give "user" and "id".
+func splitWords(value string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(value)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+			continue
+		}
+		// A word starts at an upper case letter after a lower case one or before a lower case one in acronyms,
+		// e.g. "HTTPServer" gives "HTTP" and "Server".
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				i+1 < len(runes) && unicode.IsUpper(prev) && unicode.IsLower(runes[i+1]) {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+func upperFirst(s string) string {
+	for i, r := range s {
+		return string(unicode.ToUpper(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
+
+// singularize returns the singular form of the English noun for regular plurals, e.g. "categories" gives "category".
+func singularize(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(s) > 3:
+		return s[:len(s)-3] + matchCase("y", s[len(s)-3:])
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "shes"), strings.HasSuffix(lower, "ches"),
+		strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "zes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"):
+		return s
+	case strings.HasSuffix(lower, "s"):
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+// pluralize returns the plural form of the English noun for regular plurals, e.g. "category" gives "categories".
+func pluralize(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lower, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return s[:len(s)-1] + matchCase("ies", s[len(s)-1:])
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "sh"), strings.HasSuffix(lower, "ch"),
+		strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"):
+		return s + matchCase("es", s[len(s)-1:])
+	}
+	return s + matchCase("s", s[len(s)-1:])
+}
+
+// matchCase returns the suffix in upper case if the replaced part of the word is in upper case.
+func matchCase(suffix string, replaced string) string {
+	if replaced != "" && strings.ToUpper(replaced) == replaced && strings.ToLower(replaced) != replaced {
+		return strings.ToUpper(suffix)
+	}
+	return suffix
+}
+
+// mergeNodes merges the source mapping into the destination mapping. Values of the destination take precedence,
+// mappings present in both are merged recursively and traits of "is" present in both are combined.
+func mergeNodes(dst *yaml.Node, src *yaml.Node) {
+	for i := 0; i != len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		j := mappingIndex(dst, key.Value)
+		if j < 0 {
+			dst.Content = append(dst.Content, key, value)
+			continue
+		}
+		target := dst.Content[j+1]
+		switch {
+		case target.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeNodes(target, value)
+		case key.Value == "is" && target.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode:
+			target.Content = append(target.Content, value.Content...)
+		case target.Tag == TagNull && value.Kind == yaml.MappingNode:
+			// An empty declaration, e.g. "get:", is filled by the source.
+			dst.Content[j+1] = value
+		}
+	}
+}
+
+// mappingIndex returns the index of the key in the content of the mapping, -1 if there is no key.
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,46 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_substituteTemplateParams(t *testing.T) {
+	params := map[string]string{"name": "userAccount", "item": "categories"}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"<<item>>", "categories"},
+		{"<<item | !singularize>>", "category"},
+		{"<<item | !singularize | !pluralize>>", "categories"},
+		{"<<name | !uppercase>>", "USERACCOUNT"},
+		{"<<name | !lowercase>>", "useraccount"},
+		{"<<name | !uppercamelcase>>", "UserAccount"},
+		{"<<name | !lowercamelcase>>", "userAccount"},
+		{"<<name | !lowerunderscorecase>>", "user_account"},
+		{"<<name | !upperunderscorecase>>", "USER_ACCOUNT"},
+		{"<<name | !lowerhyphencase>>", "user-account"},
+		{"<<name | !upperhyphencase>>", "USER-ACCOUNT"},
+		{"/<<item>>/{id}", "/categories/{id}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := substituteTemplateParams(tt.in, params)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := substituteTemplateParams("<<missing>>", params)
+	require.ErrorContains(t, err, "parameter missing is not provided")
+	_, err = substituteTemplateParams("<<item | !reverse>>", params)
+	require.ErrorContains(t, err, "unknown transformation function !reverse")
+}
+
+func Test_splitWords(t *testing.T) {
+	require.Equal(t, []string{"HTTP", "Server", "v2"}, splitWords("HTTPServer_v2"))
+	require.Equal(t, []string{"user", "id"}, splitWords("user-id"))
+	require.Equal(t, []string{"user", "Id"}, splitWords("userId"))
+}
@@ -0,0 +1,409 @@
+package raml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AnnotationReadOnly marks the property as read-only for TypeScriptConverter, e.g. "(readOnly): true".
+// The annotation type must be declared by the user. Annotations of used libraries are recognized by the name
+// without the namespace.
+const AnnotationReadOnly = "readOnly"
+
+// TypeScriptOptionality defines how TypeScriptConverter declares optional properties.
+type TypeScriptOptionality int
+
+const (
+	// TypeScriptOptionalProperty declares optional properties as "name?: T".
+	TypeScriptOptionalProperty TypeScriptOptionality = iota
+	// TypeScriptOptionalUndefined declares optional properties as "name?: T | undefined" for projects that
+	// enable exactOptionalPropertyTypes.
+	TypeScriptOptionalUndefined
+	// TypeScriptOptionalNull declares optional properties as "name: T | null" for APIs that send absent values
+	// as nulls.
+	TypeScriptOptionalNull
+)
+
+type TypeScriptConverterOpt interface {
+	Apply(*TypeScriptConverterOptions)
+}
+
+type TypeScriptConverterOptions struct {
+	optionality  TypeScriptOptionality
+	readonly     bool
+	literalEnums bool
+	naming       Naming
+}
+
+type optTypeScriptOptionality struct {
+	optionality TypeScriptOptionality
+}
+
+func (o optTypeScriptOptionality) Apply(opts *TypeScriptConverterOptions) {
+	opts.optionality = o.optionality
+}
+
+// WithTypeScriptOptionality sets how optional properties are declared, TypeScriptOptionalProperty by default.
+func WithTypeScriptOptionality(optionality TypeScriptOptionality) TypeScriptConverterOpt {
+	return optTypeScriptOptionality{optionality: optionality}
+}
+
+type optTypeScriptReadonly struct{}
+
+func (optTypeScriptReadonly) Apply(opts *TypeScriptConverterOptions) {
+	opts.readonly = true
+}
+
+// WithTypeScriptReadonly declares all properties and arrays as readonly. Otherwise, only properties with
+// the readOnly annotation are readonly.
+func WithTypeScriptReadonly() TypeScriptConverterOpt {
+	return optTypeScriptReadonly{}
+}
+
+type optTypeScriptLiteralEnums struct{}
+
+func (optTypeScriptLiteralEnums) Apply(opts *TypeScriptConverterOptions) {
+	opts.literalEnums = true
+}
+
+// WithTypeScriptLiteralEnums declares string enums as unions of string literals instead of enums.
+func WithTypeScriptLiteralEnums() TypeScriptConverterOpt {
+	return optTypeScriptLiteralEnums{}
+}
+
+type optTypeScriptNaming struct {
+	naming Naming
+}
+
+func (o optTypeScriptNaming) Apply(opts *TypeScriptConverterOptions) {
+	opts.naming = o.naming
+}
+
+// WithTypeScriptNaming sets the naming of types and enum members, NamingTypeScript by default.
+func WithTypeScriptNaming(naming Naming) TypeScriptConverterOpt {
+	return optTypeScriptNaming{naming: naming}
+}
+
+// TypeScriptConverter converts declared types to TypeScript type definitions (.d.ts).
+//
+// Object types become interfaces that extend interfaces of their parents, string enums become enums and other types
+// become type aliases. Inline objects are rendered as object literal types, pattern properties as index signatures.
+// Names of declared types are converted to PascalCase, e.g. "common.Pet" becomes "CommonPet", colliding names get
+// number suffixes. Date and time types
+// and files are strings, as they are represented in JSON.
+type TypeScriptConverter struct {
+	opts TypeScriptConverterOptions
+
+	names map[int64]string
+}
+
+func NewTypeScriptConverter(opts ...TypeScriptConverterOpt) *TypeScriptConverter {
+	c := &TypeScriptConverter{opts: TypeScriptConverterOptions{naming: NamingTypeScript}}
+	for _, opt := range opts {
+		opt.Apply(&c.opts)
+	}
+	return c
+}
+
+// Convert converts types of the type graph (see RAML.TypeGraph) to a TypeScript module.
+func (c *TypeScriptConverter) Convert(r *RAML) ([]byte, error) {
+	graph := r.TypeGraph()
+	c.names = make(map[int64]string, len(graph.Nodes))
+	scope := NewNameScope()
+	for _, n := range graph.Nodes {
+		c.names[n.Shape.ID] = scope.Unique(c.opts.naming.Pascal(n.Name))
+	}
+	var sb strings.Builder
+	sb.WriteString("// Code generated by go-raml. DO NOT EDIT.\n")
+	for _, n := range graph.Nodes {
+		sb.WriteString("\n")
+		if err := c.writeDeclaration(&sb, c.names[n.Shape.ID], n.Shape); err != nil {
+			return nil, fmt.Errorf("convert type %s: %w", n.Name, err)
+		}
+	}
+	return []byte(sb.String()), nil
+}
+
+func (c *TypeScriptConverter) writeDeclaration(sb *strings.Builder, name string, s *BaseShape) error {
+	writeTSDoc(sb, s, "")
+	if s.Alias == nil && s.Link == nil {
+		switch shape := s.Shape.(type) {
+		case *ObjectShape:
+			if parents, ok := c.interfaceParents(s); ok {
+				sb.WriteString("export interface " + name)
+				if len(parents) > 0 {
+					sb.WriteString(" extends " + strings.Join(parents, ", "))
+				}
+				body, err := c.objectType(shape, "")
+				if err != nil {
+					return err
+				}
+				sb.WriteString(" " + body + "\n")
+				return nil
+			}
+		case *StringShape:
+			if shape.Enum != nil && !c.opts.literalEnums {
+				c.writeEnum(sb, name, shape.Enum)
+				return nil
+			}
+		}
+	}
+	typ, err := c.definition(s, "")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(sb, "export type %s = %s;\n", name, typ)
+	return nil
+}
+
+// interfaceParents returns names of parents of the object type, false if some parent is not a declared object type,
+// so the type cannot be declared as an interface.
+func (c *TypeScriptConverter) interfaceParents(s *BaseShape) ([]string, bool) {
+	parents := make([]string, 0, len(s.Inherits))
+	for _, parent := range s.Inherits {
+		name, ok := c.declaredName(parent)
+		if !ok {
+			return nil, false
+		}
+		if _, isObject := protoTarget(parent).Shape.(*ObjectShape); !isObject {
+			return nil, false
+		}
+		parents = append(parents, name)
+	}
+	return parents, true
+}
+
+func (c *TypeScriptConverter) writeEnum(sb *strings.Builder, name string, values Nodes) {
+	sb.WriteString("export enum " + name + " {\n")
+	scope := NewNameScope()
+	for _, v := range values {
+		value := fmt.Sprint(v.Value)
+		member := scope.Unique(c.opts.naming.Pascal(value))
+		fmt.Fprintf(sb, "  %s = %s,\n", member, strconv.Quote(value))
+	}
+	sb.WriteString("}\n")
+}
+
+func writeTSDoc(sb *strings.Builder, s *BaseShape, indent string) {
+	if s.Description == nil || strings.TrimSpace(*s.Description) == "" {
+		return
+	}
+	lines := strings.Split(strings.TrimSpace(*s.Description), "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(sb, "%s/** %s */\n", indent, strings.ReplaceAll(lines[0], "*/", "*\\/"))
+		return
+	}
+	sb.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		sb.WriteString(strings.TrimRight(indent+" * "+strings.ReplaceAll(line, "*/", "*\\/"), " ") + "\n")
+	}
+	sb.WriteString(indent + " */\n")
+}
+
+// declaredName returns the name of the declared type the shape refers to.
+func (c *TypeScriptConverter) declaredName(s *BaseShape) (string, bool) {
+	for s != nil {
+		if name, ok := c.names[s.ID]; ok {
+			return name, true
+		}
+		switch {
+		case s.Alias != nil:
+			s = s.Alias
+		case s.Link != nil:
+			s = s.Link.Shape
+		default:
+			if rs, ok := s.Shape.(*RecursiveShape); ok {
+				s = rs.Head
+				continue
+			}
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// typeOf returns the type of the shape used by the property, items or the union member.
+func (c *TypeScriptConverter) typeOf(s *BaseShape, indent string) (string, error) {
+	if name, ok := c.declaredName(s); ok {
+		return name, nil
+	}
+	return c.definition(s, indent)
+}
+
+// definition returns the type the shape is defined with.
+func (c *TypeScriptConverter) definition(s *BaseShape, indent string) (string, error) {
+	switch {
+	case s.Alias != nil:
+		return c.typeOf(s.Alias, indent)
+	case s.Link != nil:
+		return c.typeOf(s.Link.Shape, indent)
+	}
+	switch shape := s.Shape.(type) {
+	case *ObjectShape:
+		var parents []string
+		for _, parent := range s.Inherits {
+			typ, err := c.typeOf(parent, indent)
+			if err != nil {
+				return "", err
+			}
+			parents = append(parents, parenthesizeTSType(typ))
+		}
+		body, err := c.objectType(shape, indent)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case body == "{}" && len(parents) == 0:
+			return "Record<string, unknown>", nil
+		case body == "{}":
+			return strings.Join(parents, " & "), nil
+		}
+		return strings.Join(append(parents, body), " & "), nil
+	case *ArrayShape:
+		items := "unknown"
+		if shape.Items != nil {
+			var err error
+			if items, err = c.typeOf(shape.Items, indent); err != nil {
+				return "", err
+			}
+		}
+		if c.opts.readonly {
+			return "readonly " + parenthesizeTSType(items) + "[]", nil
+		}
+		return parenthesizeTSType(items) + "[]", nil
+	case *UnionShape:
+		members := make([]string, 0, len(shape.AnyOf))
+		for _, member := range shape.AnyOf {
+			typ, err := c.typeOf(member, indent)
+			if err != nil {
+				return "", err
+			}
+			members = append(members, typ)
+		}
+		return strings.Join(members, " | "), nil
+	case *RecursiveShape:
+		if name, ok := c.declaredName(shape.Head); ok {
+			return name, nil
+		}
+		return "", fmt.Errorf("recursive type is not declared")
+	case *StringShape:
+		return tsEnumOr(shape.Enum, "string"), nil
+	case *IntegerShape:
+		return tsEnumOr(shape.Enum, "number"), nil
+	case *NumberShape:
+		return tsEnumOr(shape.Enum, "number"), nil
+	case *BooleanShape:
+		return tsEnumOr(shape.Enum, "boolean"), nil
+	case *DateTimeShape, *DateTimeOnlyShape, *DateOnlyShape, *TimeOnlyShape, *FileShape:
+		return "string", nil
+	case *NilShape:
+		return "null", nil
+	case *AnyShape, *JSONShape:
+		return "unknown", nil
+	default:
+		return "", fmt.Errorf("type %s is not supported", s.Type)
+	}
+}
+
+// tsEnumOr returns the union of enum literals, or the type if there is no enum.
+func tsEnumOr(enum Nodes, typ string) string {
+	if enum == nil {
+		return typ
+	}
+	literals := make([]string, len(enum))
+	for i, v := range enum {
+		if s, ok := v.Value.(string); ok {
+			literals[i] = strconv.Quote(s)
+		} else {
+			literals[i] = fmt.Sprint(v.Value)
+		}
+	}
+	return strings.Join(literals, " | ")
+}
+
+// parenthesizeTSType wraps unions and intersections in parentheses, so they can be used as operands.
+func parenthesizeTSType(typ string) string {
+	if strings.HasPrefix(typ, "{") || (!strings.Contains(typ, " | ") && !strings.Contains(typ, " & ")) {
+		return typ
+	}
+	return "(" + typ + ")"
+}
+
+// objectType returns the object literal type with own properties of the object, "{}" if there are none.
+func (c *TypeScriptConverter) objectType(shape *ObjectShape, indent string) (string, error) {
+	var sb strings.Builder
+	inner := indent + "  "
+	sb.WriteString("{\n")
+	if shape.Properties != nil {
+		for pair := shape.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			if err := c.writeProperty(&sb, pair.Value, inner); err != nil {
+				return "", fmt.Errorf("property %s: %w", pair.Key, err)
+			}
+		}
+	}
+	if shape.PatternProperties != nil && shape.PatternProperties.Len() > 0 {
+		// Index signatures must be compatible with all properties.
+		valueType := "unknown"
+		if shape.Properties == nil || shape.Properties.Len() == 0 {
+			var types []string
+			for pair := shape.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+				typ, err := c.typeOf(pair.Value.Shape, inner)
+				if err != nil {
+					return "", fmt.Errorf("property %s: %w", pair.Key, err)
+				}
+				types = append(types, typ)
+			}
+			valueType = strings.Join(types, " | ")
+		}
+		fmt.Fprintf(&sb, "%s%s[key: string]: %s;\n", inner, c.readonlyPrefix(nil), valueType)
+	} else if shape.Properties == nil || shape.Properties.Len() == 0 {
+		return "{}", nil
+	}
+	sb.WriteString(indent + "}")
+	return sb.String(), nil
+}
+
+func (c *TypeScriptConverter) writeProperty(sb *strings.Builder, p Property, indent string) error {
+	typ, err := c.typeOf(p.Shape, indent)
+	if err != nil {
+		return err
+	}
+	name := p.Name
+	if !isTSIdentifier(name) {
+		name = strconv.Quote(name)
+	}
+	if !p.Required {
+		switch c.opts.optionality {
+		case TypeScriptOptionalProperty:
+			name += "?"
+		case TypeScriptOptionalUndefined:
+			name += "?"
+			typ += " | undefined"
+		case TypeScriptOptionalNull:
+			if !strings.HasSuffix(typ, " | null") && typ != "null" {
+				typ += " | null"
+			}
+		}
+	}
+	writeTSDoc(sb, p.Shape, indent)
+	fmt.Fprintf(sb, "%s%s%s: %s;\n", indent, c.readonlyPrefix(p.Shape), name, typ)
+	return nil
+}
+
+func (c *TypeScriptConverter) readonlyPrefix(s *BaseShape) string {
+	if c.opts.readonly || (s != nil && s.annotationValue(AnnotationReadOnly) == true) {
+		return "readonly "
+	}
+	return ""
+}
+
+func isTSIdentifier(name string) bool {
+	for i, r := range name {
+		isLetter := r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !isLetter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return name != ""
+}
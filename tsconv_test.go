@@ -0,0 +1,156 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeScriptConverter_Convert(t *testing.T) {
+	content := `#%RAML 1.0 Library
+annotationTypes:
+  readOnly: boolean
+types:
+  Base:
+    properties:
+      id:
+        type: integer
+        (readOnly): true
+  Pet:
+    type: Base
+    description: A pet.
+    properties:
+      name: string
+      tag?: string
+      born: datetime
+      status: Status
+      size:
+        enum: [1, 2]
+      owner:
+        properties:
+          email: string
+      labels:
+        properties:
+          //: string
+      codes:
+        type: array
+        items: string | integer
+      parent: Pet | nil
+      kind: Dog | Cat
+      x-extra?: any
+  Status:
+    type: string
+    enum: [active, sold-out]
+  Dog:
+    properties:
+      bark: boolean
+  Cat:
+    properties:
+      weight: number
+  Animal: Dog | Cat
+`
+	r, err := ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		opts []TypeScriptConverterOpt
+		want string
+	}{
+		{
+			name: "default",
+			want: `// Code generated by go-raml. DO NOT EDIT.
+
+export type Animal = Dog | Cat;
+
+export interface Base {
+  readonly id: number;
+}
+
+export interface Cat {
+  weight: number;
+}
+
+export interface Dog {
+  bark: boolean;
+}
+
+/** A pet. */
+export interface Pet extends Base {
+  name: string;
+  tag?: string;
+  born: string;
+  status: Status;
+  size: 1 | 2;
+  owner: {
+    email: string;
+  };
+  labels: {
+    [key: string]: string;
+  };
+  codes: (string | number)[];
+  parent: Pet | null;
+  kind: Dog | Cat;
+  "x-extra"?: unknown;
+}
+
+export enum Status {
+  Active = "active",
+  SoldOut = "sold-out",
+}
+`,
+		},
+		{
+			name: "options",
+			opts: []TypeScriptConverterOpt{
+				WithTypeScriptOptionality(TypeScriptOptionalNull),
+				WithTypeScriptReadonly(),
+				WithTypeScriptLiteralEnums(),
+			},
+			want: `// Code generated by go-raml. DO NOT EDIT.
+
+export type Animal = Dog | Cat;
+
+export interface Base {
+  readonly id: number;
+}
+
+export interface Cat {
+  readonly weight: number;
+}
+
+export interface Dog {
+  readonly bark: boolean;
+}
+
+/** A pet. */
+export interface Pet extends Base {
+  readonly name: string;
+  readonly tag: string | null;
+  readonly born: string;
+  readonly status: Status;
+  readonly size: 1 | 2;
+  readonly owner: {
+    readonly email: string;
+  };
+  readonly labels: {
+    readonly [key: string]: string;
+  };
+  readonly codes: readonly (string | number)[];
+  readonly parent: Pet | null;
+  readonly kind: Dog | Cat;
+  readonly "x-extra": unknown | null;
+}
+
+export type Status = "active" | "sold-out";
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTypeScriptConverter(tt.opts...).Convert(r)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package raml
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/antlr4-go/antlr/v4"
+	"gopkg.in/yaml.v3"
+
+	"github.com/acronis/go-stacktrace"
+
+	"github.com/acronis/go-raml/rdt"
+)
+
+// ResolutionContext is the model and the fragment in which ParseTypeExpression resolves references.
+type ResolutionContext struct {
+	// RAML is the loaded model that declares the referenced types.
+	RAML *RAML
+	// Location is the location of the fragment whose declarations and uses aliases resolve references, e.g. "Pet"
+	// or "common.Pet". The entry point is used if empty.
+	Location string
+	// Unwrap returns the unwrapped shape with inherited facets, e.g. to validate values against it.
+	Unwrap bool
+}
+
+// ParseTypeExpression evaluates the type expression, e.g. "Cat | Dog[]" or "string?", against the loaded model as if
+// it was the type of an anonymous declaration in the fragment of the context. The returned shape refers to the
+// declared types of the model and is not added to any fragment.
+func ParseTypeExpression(expr string, ctx ResolutionContext) (Shape, error) {
+	r := ctx.RAML
+	if r == nil {
+		return nil, fmt.Errorf("parse type expression: model is nil")
+	}
+	if expr == "" {
+		return nil, fmt.Errorf("parse type expression: expression is empty")
+	}
+	frag, err := r.fragmentAt(ctx.Location)
+	if err != nil {
+		return nil, fmt.Errorf("parse type expression: %w", err)
+	}
+	base, s, err := r.MakeNewShape("", expr, frag.GetLocation(), &stacktrace.Position{})
+	if err != nil {
+		return nil, fmt.Errorf("parse type expression: %w", err)
+	}
+	if _, ok := s.(*UnknownShape); ok {
+		if err = r.resolveShape(base); err != nil {
+			// Syntax errors are returned as is, so callers can point at the offending character.
+			if st, ok := stacktrace.Unwrap(err); ok {
+				var exprErr *TypeExpressionError
+				if errors.As(st.Err, &exprErr) {
+					return nil, exprErr
+				}
+			}
+			return nil, fmt.Errorf("parse type expression %q: %w", expr, err)
+		}
+	}
+	if !ctx.Unwrap {
+		return base.Shape, nil
+	}
+	unwrapped, err := r.UnwrapShape(base)
+	if err != nil {
+		return nil, fmt.Errorf("parse type expression %q: unwrap: %w", expr, err)
+	}
+	return unwrapped.Shape, nil
+}
+
+// visitTypeExpression parses the type expression with the RDT grammar and builds the shape of the target from it.
+func (r *RAML) visitTypeExpression(expr string, target *UnknownShape) (Shape, error) {
+	is := antlr.NewInputStream(expr)
+	lexer := rdt.NewrdtLexer(is)
+	tokens := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	rdtParser := rdt.NewrdtParser(tokens)
+	// Syntax errors are collected instead of being printed to the console.
+	listener := &typeExpressionErrorListener{DefaultErrorListener: antlr.NewDefaultErrorListener(), expr: expr}
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(listener)
+	rdtParser.RemoveErrorListeners()
+	rdtParser.AddErrorListener(listener)
+	tree := rdtParser.Entrypoint()
+	if listener.err != nil {
+		return nil, listener.err
+	}
+	visitor := NewRdtVisitor(r)
+	return visitor.Visit(tree, target)
+}
+
+// TypeExpressionError is a syntax error of a type expression, e.g. "Cat |".
+type TypeExpressionError struct {
+	// Expression is the malformed type expression.
+	Expression string
+	// Offset is the offset of the first unexpected character in the expression, the length of the expression if it
+	// ends unexpectedly.
+	Offset int
+	// Message describes the error, e.g. "unexpected end of expression, expected type name or '('".
+	Message string
+}
+
+func (e *TypeExpressionError) Error() string {
+	return fmt.Sprintf("%s at offset %d of type expression %q", e.Message, e.Offset, e.Expression)
+}
+
+// typeExpressionErrorListener keeps the first syntax error reported by the RDT lexer or parser.
+type typeExpressionErrorListener struct {
+	*antlr.DefaultErrorListener
+	expr string
+	err  *TypeExpressionError
+}
+
+func (l *typeExpressionErrorListener) SyntaxError(recognizer antlr.Recognizer, offendingSymbol any, _, column int,
+	_ string, e antlr.RecognitionException) {
+	if l.err != nil {
+		return
+	}
+	var msg string
+	token, ok := offendingSymbol.(antlr.Token)
+	if !ok {
+		// The lexer reports characters that do not start any token.
+		msg = "unexpected character"
+		if column < len(l.expr) {
+			msg = fmt.Sprintf("unexpected character %q", l.expr[column])
+		}
+	} else {
+		msg = "unexpected " + tokenDescription(recognizer, token.GetTokenType(), token.GetText())
+		parser, ok := recognizer.(antlr.Parser)
+		if _, noViableAlt := e.(*antlr.NoViableAltException); noViableAlt && ok &&
+			parser.GetCurrentToken().GetTokenIndex() != token.GetTokenIndex() {
+			// The alternatives were predicted from the current token and failed further on, the expected tokens
+			// of the current state are not the ones expected at the offending token.
+			ok = false
+		}
+		if ok {
+			if expected := expectedTokens(parser); expected != "" {
+				msg += ", expected " + expected
+			}
+		}
+	}
+	l.err = &TypeExpressionError{Expression: l.expr, Offset: column, Message: msg}
+}
+
+// expectedTokens returns the descriptions of the tokens the parser expects in its current state.
+func expectedTokens(parser antlr.Parser) string {
+	set := parser.GetExpectedTokens()
+	if set == nil {
+		return ""
+	}
+	var descriptions []string
+	for _, interval := range set.GetIntervals() {
+		// The stop of an interval is exclusive.
+		for t := interval.Start; t < interval.Stop; t++ {
+			if symbolicName(parser, t) == "WS" {
+				continue
+			}
+			d := tokenDescription(parser, t, "")
+			if !slices.Contains(descriptions, d) {
+				descriptions = append(descriptions, d)
+			}
+		}
+	}
+	switch len(descriptions) {
+	case 0:
+		return ""
+	case 1:
+		return descriptions[0]
+	default:
+		return strings.Join(descriptions[:len(descriptions)-1], ", ") + " or " + descriptions[len(descriptions)-1]
+	}
+}
+
+// tokenDescription returns a human-readable description of the token type, e.g. "'|'" or "type name". Type names
+// include built-in types and references.
+func tokenDescription(recognizer antlr.Recognizer, tokenType int, text string) string {
+	if tokenType == antlr.TokenEOF {
+		return "end of expression"
+	}
+	if symbolicName(recognizer, tokenType) == "IDENTIFIER" {
+		return typeNameDescription(text)
+	}
+	names := recognizer.GetLiteralNames()
+	if tokenType < 0 || tokenType >= len(names) || names[tokenType] == "" {
+		if text != "" {
+			return fmt.Sprintf("%q", text)
+		}
+		return "token"
+	}
+	literal := names[tokenType]
+	if strings.IndexFunc(literal, unicode.IsLetter) >= 0 {
+		return typeNameDescription(text)
+	}
+	return literal
+}
+
+func symbolicName(recognizer antlr.Recognizer, tokenType int) string {
+	names := recognizer.GetSymbolicNames()
+	if tokenType < 0 || tokenType >= len(names) {
+		return ""
+	}
+	return names[tokenType]
+}
+
+func typeNameDescription(text string) string {
+	if text == "" {
+		return "type name"
+	}
+	return fmt.Sprintf("type name %q", text)
+}
+
+// typeExpressionPosition returns the position of the character at the offset of the type expression of the shape
+// in its YAML document. The position of the shape is returned if the character cannot be located, e.g. in folded
+// or multi-line scalars.
+func (s *BaseShape) typeExpressionPosition(offset int) stacktrace.Position {
+	pos := s.Position
+	node := s.rawNode
+	if node == nil {
+		return pos
+	}
+	if node.Kind == yaml.MappingNode {
+		i := mappingIndex(node, "type")
+		if i < 0 {
+			i = mappingIndex(node, "schema")
+		}
+		if i < 0 {
+			return pos
+		}
+		node = node.Content[i+1]
+	}
+	if node.Kind != yaml.ScalarNode || node.Value != s.Type || strings.ContainsAny(node.Value, "\n") {
+		return pos
+	}
+	column := node.Column + offset
+	switch node.Style {
+	case 0, yaml.TaggedStyle:
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		// The expression starts after the quote. Escaped characters are not expected in type expressions.
+		column++
+	default:
+		return pos
+	}
+	return stacktrace.Position{Line: node.Line, Column: column}
+}
+
+// TypeExpressionPrinter renders shapes as RAML type expressions, the inverse of ParseTypeExpression.
+type TypeExpressionPrinter struct {
+	// Reference returns the name of the declared type the shape refers to, false to render the shape itself.
+	// References are named by TypeLabel, as they are written in their fragments, if nil.
+	Reference func(s *BaseShape) (string, bool)
+}
+
+// TypeExpression returns the most compact type expression of the shape with references named as they are written,
+// e.g. "(Cat | Dog)[]" or "string[]?". See TypeExpressionPrinter.Print.
+func TypeExpression(s *BaseShape) string {
+	return TypeExpressionPrinter{}.Print(s)
+}
+
+// Print returns the most compact type expression of the shape: references are written by names, arrays with "[]",
+// unions of a type and an anonymous nil with "?" and unions with " | "; operands of "[]" and "?" are grouped with
+// parentheses only if they are unions. Included data types are written by their types and facets other than items
+// and members are not written, e.g. an object with properties is "object".
+func (p TypeExpressionPrinter) Print(s *BaseShape) string {
+	expr, _ := p.print(s)
+	return expr
+}
+
+// print returns the type expression of the shape and true if it is a union that must be grouped as an operand.
+func (p TypeExpressionPrinter) print(s *BaseShape) (string, bool) {
+	if s == nil {
+		return TypeAny, false
+	}
+	if s.Link != nil && s.Link.Shape != nil {
+		return p.print(s.Link.Shape)
+	}
+	if p.Reference != nil {
+		if name, ok := p.Reference(s); ok {
+			return name, false
+		}
+	} else if s.TypeLabel != "" {
+		return s.TypeLabel, false
+	}
+	switch shape := s.Shape.(type) {
+	case *ArrayShape:
+		if shape.Items == nil {
+			return TypeArray, false
+		}
+		return p.operand(shape.Items) + "[]", false
+	case *UnionShape:
+		if len(shape.AnyOf) == 2 {
+			for i, member := range shape.AnyOf {
+				if isAnonymousNil(member) {
+					return p.operand(shape.AnyOf[1-i]) + "?", false
+				}
+			}
+		}
+		members := make([]string, len(shape.AnyOf))
+		for i, member := range shape.AnyOf {
+			members[i], _ = p.print(member)
+		}
+		return strings.Join(members, " | "), len(members) > 1
+	case *RecursiveShape:
+		if shape.Head != nil {
+			return shape.Head.Name, false
+		}
+	case *JSONShape:
+		return shape.Raw, false
+	}
+	if len(s.Inherits) == 1 {
+		return p.print(s.Inherits[0])
+	}
+	return s.Type, false
+}
+
+// operand returns the type expression of the shape grouped with parentheses if it is a union.
+func (p TypeExpressionPrinter) operand(s *BaseShape) string {
+	expr, isUnion := p.print(s)
+	if isUnion {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+func isAnonymousNil(s *BaseShape) bool {
+	_, ok := s.Shape.(*NilShape)
+	return ok && s.TypeLabel == ""
+}
@@ -0,0 +1,275 @@
+package raml
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTypeExpression(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Cat:
+    properties:
+      meows: boolean
+  Dog:
+    properties:
+      barks: boolean
+  Name:
+    type: string
+    minLength: 2
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	shapes := len(rml.GetShapes())
+
+	s, err := ParseTypeExpression("Cat | Dog[]", ResolutionContext{RAML: rml})
+	require.NoError(t, err)
+	union, ok := s.(*UnionShape)
+	require.True(t, ok)
+	require.Len(t, union.AnyOf, 2)
+	require.Equal(t, "Cat", union.AnyOf[0].TypeLabel)
+	arr, ok := union.AnyOf[1].Shape.(*ArrayShape)
+	require.True(t, ok)
+	require.Equal(t, "Dog", arr.Items.TypeLabel)
+	require.Greater(t, len(rml.GetShapes()), shapes)
+
+	s, err = ParseTypeExpression("Cat | Dog[]", ResolutionContext{RAML: rml, Unwrap: true})
+	require.NoError(t, err)
+	require.NoError(t, s.Base().Validate(map[string]any{"meows": true}))
+	require.NoError(t, s.Base().Validate([]any{map[string]any{"barks": false}}))
+	require.Error(t, s.Base().Validate([]any{map[string]any{"purrs": true}}))
+
+	s, err = ParseTypeExpression("Name?", ResolutionContext{RAML: rml, Unwrap: true})
+	require.NoError(t, err)
+	require.NoError(t, s.Base().Validate(nil))
+	require.Error(t, s.Base().Validate("A"))
+
+	s, err = ParseTypeExpression("integer", ResolutionContext{RAML: rml})
+	require.NoError(t, err)
+	require.IsType(t, &IntegerShape{}, s)
+
+	_, err = ParseTypeExpression("Cat | Bird", ResolutionContext{RAML: rml})
+	require.ErrorContains(t, err, `reference "Bird" not found`)
+	_, err = ParseTypeExpression("Cat", ResolutionContext{RAML: rml, Location: "other.raml"})
+	require.ErrorContains(t, err, "fragment other.raml not found")
+	_, err = ParseTypeExpression("Cat", ResolutionContext{})
+	require.ErrorContains(t, err, "model is nil")
+}
+
+func TestTypeExpression(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Cat:
+    properties:
+      meows: boolean
+  Dog:
+    properties:
+      barks: boolean
+  Animal: Cat | Dog
+  Pets: Cat[]
+  MaybeCat: Cat?
+  Names: string[]
+  Pen:
+    properties:
+      name: string
+  Animals:
+    type: array
+    items: Cat | Dog
+  Matrix:
+    type: array
+    items:
+      type: array
+      items: number
+  MaybeNames: string[] | nil
+  Mixed: Animal | Cat? | integer
+  Bounded:
+    type: Cat
+    minProperties: 1
+`
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Animal", want: "Cat | Dog"},
+		{name: "Pets", want: "Cat[]"},
+		{name: "MaybeCat", want: "Cat?"},
+		{name: "Names", want: "string[]"},
+		{name: "Pen", want: "object"},
+		{name: "Animals", want: "(Cat | Dog)[]"},
+		{name: "Matrix", want: "number[][]"},
+		{name: "MaybeNames", want: "string[]?"},
+		{name: "Mixed", want: "Animal | Cat? | integer"},
+		{name: "Bounded", want: "Cat"},
+	}
+	for _, unwrap := range []bool{false, true} {
+		var opts []ParseOpt
+		if unwrap {
+			opts = append(opts, OptWithUnwrap())
+		}
+		rml, err := ParseFromString(content, "library.raml", t.TempDir(), opts...)
+		require.NoError(t, err)
+		types := rml.EntryPoint().(*Library).Types
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s unwrap=%t", tt.name, unwrap), func(t *testing.T) {
+				require.Equal(t, tt.want, TypeExpression(types.Value(tt.name)))
+			})
+		}
+	}
+
+	rml, err := ParseFromString(content, "library.raml", t.TempDir())
+	require.NoError(t, err)
+	printer := TypeExpressionPrinter{Reference: func(s *BaseShape) (string, bool) {
+		if s.TypeLabel == "" {
+			return "", false
+		}
+		return "lib_" + s.TypeLabel, true
+	}}
+	require.Equal(t, "(lib_Cat | lib_Dog)[]", printer.Print(rml.EntryPoint().(*Library).Types.Value("Animals")))
+}
+
+func TestTypeExpressionError(t *testing.T) {
+	tests := []struct {
+		name     string
+		decl     string
+		wantMsg  string
+		offset   int
+		position string
+	}{
+		{
+			name:     "unterminated union",
+			decl:     "Pet: Cat |",
+			wantMsg:  "unexpected end of expression, expected '(' or type name",
+			offset:   5,
+			position: "library.raml:5:13",
+		},
+		{
+			name:     "unknown operator",
+			decl:     "Pet:\n    type: Cat & Dog",
+			wantMsg:  "unexpected character '&'",
+			offset:   4,
+			position: "library.raml:6:15",
+		},
+		{
+			name:     "missing operator",
+			decl:     `Pet: "Cat Dog"`,
+			wantMsg:  `unexpected type name "Dog", expected '|'`,
+			offset:   4,
+			position: "library.raml:5:13",
+		},
+		{
+			name:     "trailing token",
+			decl:     "Pet: Cat)",
+			wantMsg:  "unexpected ')', expected end of expression",
+			offset:   3,
+			position: "library.raml:5:11",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "#%RAML 1.0 Library\ntypes:\n  Cat: object\n  Dog: object\n  " + tt.decl + "\n"
+			_, err := ParseFromString(content, "library.raml", t.TempDir())
+			require.ErrorContains(t, err, tt.wantMsg)
+			require.ErrorContains(t, err, tt.position)
+
+			rml, err := ParseFromString("#%RAML 1.0 Library\ntypes:\n  Cat: object\n  Dog: object\n",
+				"library.raml", t.TempDir())
+			require.NoError(t, err)
+			expr := tt.decl[strings.LastIndex(tt.decl, ": ")+2:]
+			_, err = ParseTypeExpression(strings.Trim(expr, `"`), ResolutionContext{RAML: rml})
+			var exprErr *TypeExpressionError
+			require.ErrorAs(t, err, &exprErr)
+			require.Equal(t, tt.wantMsg, exprErr.Message)
+			require.Equal(t, tt.offset, exprErr.Offset)
+		})
+	}
+}
+
+func TestParseTypeExpression_Precedence(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  A: string
+  B: integer
+  Grid: A[][]
+  Groups: (A | B[])[]?
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	types := rml.EntryPoint().(*Library).Types
+	require.Equal(t, "A[][]", TypeExpression(types.Value("Grid")))
+	require.Equal(t, "(A | B[])[]?", TypeExpression(types.Value("Groups")))
+
+	tests := []struct {
+		expr    string
+		want    string
+		valid   []any
+		invalid []any
+	}{
+		{
+			expr:    "A[][]",
+			want:    "A[][]",
+			valid:   []any{[]any{[]any{"a"}, []any{}}},
+			invalid: []any{[]any{"a"}, nil},
+		},
+		{
+			expr:    "(A | B[])[]?",
+			want:    "(A | B[])[]?",
+			valid:   []any{nil, []any{"a", []any{int64(1)}}},
+			invalid: []any{"a", []any{int64(1)}, []any{nil}},
+		},
+		{
+			expr:    "A[]?",
+			want:    "A[]?",
+			valid:   []any{nil, []any{"a"}},
+			invalid: []any{[]any{nil}},
+		},
+		{
+			expr:    "A?[]",
+			want:    "A?[]",
+			valid:   []any{[]any{"a", nil}},
+			invalid: []any{nil},
+		},
+		{
+			expr:    "A? | B?",
+			want:    "A? | B?",
+			valid:   []any{nil, "a", int64(1)},
+			invalid: []any{true},
+		},
+		{
+			expr:    "A | B[]",
+			want:    "A | B[]",
+			valid:   []any{"a", []any{int64(1)}},
+			invalid: []any{int64(1), []any{"a"}},
+		},
+		{
+			expr:    "((A | B))[]",
+			want:    "(A | B)[]",
+			valid:   []any{[]any{"a", int64(1)}},
+			invalid: []any{"a"},
+		},
+		{
+			expr:    "(A)",
+			want:    "A",
+			valid:   []any{"a"},
+			invalid: []any{int64(1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseTypeExpression(tt.expr, ResolutionContext{RAML: rml})
+			require.NoError(t, err)
+			require.Equal(t, tt.want, TypeExpression(s.Base()))
+
+			s, err = ParseTypeExpression(tt.expr, ResolutionContext{RAML: rml, Unwrap: true})
+			require.NoError(t, err)
+			for _, v := range tt.valid {
+				require.NoError(t, s.Base().Validate(v), "%v", v)
+			}
+			for _, v := range tt.invalid {
+				require.Error(t, s.Base().Validate(v), "%v", v)
+			}
+		})
+	}
+}
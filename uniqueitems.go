@@ -0,0 +1,128 @@
+package raml
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"reflect"
+)
+
+// pairwiseUniqueItemsLimit is the maximum number of items that are checked for uniqueness without a set.
+const pairwiseUniqueItemsLimit = 16
+
+// hasUniqueItems reports whether no two items are deeply equal. Larger arrays are bucketed by canonical hashes of
+// items, so objects and arrays are supported as items.
+func hasUniqueItems(items []any) bool {
+	// Small arrays are compared pairwise instead of allocating a set.
+	if len(items) <= pairwiseUniqueItemsLimit {
+		for i := 1; i < len(items); i++ {
+			for j := 0; j < i; j++ {
+				if sameItem(items[i], items[j]) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	var h maphash.Hash
+	buckets := make(map[uint64][]int, len(items))
+	for i, item := range items {
+		h.Reset()
+		writeItemHash(&h, item)
+		sum := h.Sum64()
+		for _, j := range buckets[sum] {
+			if sameItem(items[j], item) {
+				return false
+			}
+		}
+		buckets[sum] = append(buckets[sum], i)
+	}
+	return true
+}
+
+// sameItem reports whether the values are deeply equal, numbers are compared by value regardless of their types.
+func sameItem(a, b any) bool {
+	switch a := a.(type) {
+	case string:
+		bs, ok := b.(string)
+		return ok && a == bs
+	case bool:
+		bb, ok := b.(bool)
+		return ok && a == bb
+	case map[string]any:
+		bm, ok := b.(map[string]any)
+		if !ok || len(a) != len(bm) {
+			return false
+		}
+		for k, av := range a {
+			bv, present := bm[k]
+			if !present || !sameItem(av, bv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bs, ok := b.([]any)
+		if !ok || len(a) != len(bs) {
+			return false
+		}
+		for i := range a {
+			if !sameItem(a[i], bs[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if x, ok := exactNumber(a); ok {
+		y, isNum := exactNumber(b)
+		return isNum && x.Cmp(y) == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// writeItemHash writes the canonical hash of the value, deeply equal values have equal hashes.
+func writeItemHash(h *maphash.Hash, v any) {
+	var buf [8]byte
+	switch v := v.(type) {
+	case nil:
+		h.WriteByte(0)
+	case string:
+		h.WriteByte(1)
+		h.WriteString(v)
+	case bool:
+		h.WriteByte(2)
+		if v {
+			h.WriteByte(1)
+		}
+	case map[string]any:
+		h.WriteByte(3)
+		// Entries are hashed separately and summed, so the hash does not depend on the iteration order.
+		var sum uint64
+		var eh maphash.Hash
+		eh.SetSeed(h.Seed())
+		for k, item := range v {
+			eh.Reset()
+			eh.WriteString(k)
+			writeItemHash(&eh, item)
+			sum += eh.Sum64()
+		}
+		binary.LittleEndian.PutUint64(buf[:], sum)
+		h.Write(buf[:])
+	case []any:
+		h.WriteByte(4)
+		binary.LittleEndian.PutUint64(buf[:], uint64(len(v)))
+		h.Write(buf[:])
+		for _, item := range v {
+			writeItemHash(h, item)
+		}
+	default:
+		if n, ok := exactNumber(v); ok {
+			// Equal numbers have the same normalized fraction regardless of their types.
+			h.WriteByte(5)
+			h.WriteString(n.RatString())
+			return
+		}
+		// Other values are told apart by sameItem.
+		h.WriteByte(6)
+		h.WriteString(reflect.TypeOf(v).String())
+	}
+}
@@ -0,0 +1,75 @@
+package raml
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_hasUniqueItems(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []any
+		want  bool
+	}{
+		{name: "scalars", items: []any{"a", 1, true, nil, 1.5}, want: true},
+		{name: "same strings", items: []any{"a", "b", "a"}, want: false},
+		{name: "numbers of different types", items: []any{1, int64(2), 1.0}, want: false},
+		{name: "json numbers", items: []any{json.Number("1"), json.Number("1.0")}, want: false},
+		{name: "json number and float", items: []any{0.1, json.Number("0.10")}, want: false},
+		{name: "big numbers", items: []any{json.Number("9007199254740993"), uint64(9007199254740992)}, want: true},
+		{name: "objects", items: []any{map[string]any{"a": 1}, map[string]any{"a": 2}}, want: true},
+		{
+			name:  "equal objects",
+			items: []any{map[string]any{"a": 1, "b": []any{"x"}}, map[string]any{"b": []any{"x"}, "a": 1.0}},
+			want:  false,
+		},
+		{name: "object and array", items: []any{map[string]any{}, []any{}}, want: true},
+		{name: "arrays", items: []any{[]any{1, 2}, []any{2, 1}}, want: true},
+		{name: "equal arrays", items: []any{[]any{1, []any{2}}, []any{1, []any{2}}}, want: false},
+		{name: "other slices", items: []any{[]string{"a"}, []string{"a"}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, hasUniqueItems(tt.items))
+			// Larger arrays are checked by hashes.
+			large := append([]any{}, tt.items...)
+			for i := 0; len(large) <= pairwiseUniqueItemsLimit; i++ {
+				large = append(large, map[string]any{"filler": fmt.Sprint(i)})
+			}
+			require.Equal(t, tt.want, hasUniqueItems(large))
+		})
+	}
+}
+
+func TestArrayShape_validateUniqueItems_objects(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Tags:
+    type: array
+    items:
+      properties:
+        label: string
+        aliases?: string[]
+    uniqueItems: true
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	tags, err := rml.LookupType("Tags", "")
+	require.NoError(t, err)
+	compiled, err := tags.Compile()
+	require.NoError(t, err)
+
+	for _, validate := range []func(any) error{tags.Validate, compiled.Validate} {
+		require.NoError(t, validate([]any{
+			map[string]any{"label": "a", "aliases": []any{"x"}},
+			map[string]any{"label": "a", "aliases": []any{"y"}},
+		}))
+		require.EqualError(t, validate([]any{
+			map[string]any{"label": "a", "aliases": []any{"x"}},
+			map[string]any{"aliases": []any{"x"}, "label": "a"},
+		}), "array contains duplicate items")
+	}
+}
@@ -0,0 +1,123 @@
+package raml
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// DeclarationKind is a kind of declaration reported by UnusedDeclarations.
+type DeclarationKind string
+
+const (
+	DeclarationType           DeclarationKind = "type"
+	DeclarationAnnotationType DeclarationKind = "annotation type"
+	DeclarationLibrary        DeclarationKind = "library"
+)
+
+// UnusedDeclaration is a declaration that is never referenced.
+type UnusedDeclaration struct {
+	Kind DeclarationKind
+	// Name is the name of the type or the annotation type as declared or the alias of the library.
+	Name string
+
+	Location string
+	stacktrace.Position
+}
+
+func (d UnusedDeclaration) String() string {
+	return fmt.Sprintf("%s %q is not used", d.Kind, d.Name)
+}
+
+// UnusedDeclarations returns types and annotation types that are not referenced by other declarations or annotations,
+// and entries of uses that are not referenced by their fragments. Types and annotation types of the entry point are
+// not reported since they are the interface of the entry point. Results are sorted by position. References are
+// collected like edges of RAML.TypeGraph.
+func (r *RAML) UnusedDeclarations() []UnusedDeclaration {
+	var result []UnusedDeclaration
+
+	referenced := r.referencedTypes()
+	annotated := make(map[int64]struct{})
+	for _, de := range r.domainExtensions {
+		if de.DefinedBy != nil {
+			annotated[de.DefinedBy.ID] = struct{}{}
+		}
+	}
+	entryPoint := ""
+	if r.entryPoint != nil {
+		entryPoint = r.entryPoint.GetLocation()
+	}
+	for location, frag := range r.fragmentsCache {
+		result = append(result, r.unusedLibraries(location)...)
+		lib, ok := frag.(*Library)
+		if !ok || location == entryPoint {
+			continue
+		}
+		for pair := lib.Types.Oldest(); pair != nil; pair = pair.Next() {
+			if _, ok := referenced[pair.Value.ID]; !ok {
+				result = append(result, UnusedDeclaration{Kind: DeclarationType, Name: pair.Key,
+					Location: pair.Value.Location(), Position: pair.Value.Position})
+			}
+		}
+		for pair := lib.AnnotationTypes.Oldest(); pair != nil; pair = pair.Next() {
+			if _, ok := annotated[pair.Value.ID]; !ok {
+				result = append(result, UnusedDeclaration{Kind: DeclarationAnnotationType, Name: pair.Key,
+					Location: pair.Value.Location(), Position: pair.Value.Position})
+			}
+		}
+	}
+	slices.SortFunc(result, func(x, y UnusedDeclaration) int {
+		return cmp.Or(cmp.Compare(x.Location, y.Location), cmp.Compare(x.Line, y.Line),
+			cmp.Compare(x.Column, y.Column))
+	})
+	return result
+}
+
+// unusedLibraries returns entries of uses of the fragment at the location that are not referenced by the fragment.
+func (r *RAML) unusedLibraries(location string) []UnusedDeclaration {
+	var result []UnusedDeclaration
+	used := r.usedLibraries[filepath.Clean(location)]
+	for pair := r.Uses(location).Oldest(); pair != nil; pair = pair.Next() {
+		if _, ok := used[pair.Key]; !ok {
+			result = append(result, UnusedDeclaration{Kind: DeclarationLibrary, Name: pair.Key,
+				Location: pair.Value.Location, Position: pair.Value.Position})
+		}
+	}
+	return result
+}
+
+// referencedTypes returns IDs of declared types that are referenced by other types or by annotation types.
+// Self-references of recursive types do not count.
+func (r *RAML) referencedTypes() map[int64]struct{} {
+	b := &typeGraphBuilder{
+		graph: &TypeGraph{Edges: make(map[string][]TypeDependency)},
+		names: make(map[int64]string),
+		edges: make(map[TypeDependency]struct{}),
+	}
+	b.collect(r)
+	ids := make(map[string]int64, len(b.graph.Nodes))
+	for _, n := range b.graph.Nodes {
+		ids[n.Name] = n.Shape.ID
+		b.walk(n.Name, n.Shape, "", true, make(map[int64]struct{}))
+	}
+	// Annotation types are walked under names that cannot clash with type names.
+	for _, frag := range r.fragmentsCache {
+		if lib, ok := frag.(*Library); ok {
+			for pair := lib.AnnotationTypes.Oldest(); pair != nil; pair = pair.Next() {
+				b.walk("("+lib.Location+"#"+pair.Key+")", pair.Value, "", true, make(map[int64]struct{}))
+			}
+		}
+	}
+	result := make(map[int64]struct{})
+	for from, edges := range b.graph.Edges {
+		for _, e := range edges {
+			if e.To != from {
+				result[ids[e.To]] = struct{}{}
+			}
+		}
+	}
+	return result
+}
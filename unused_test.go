@@ -0,0 +1,39 @@
+package raml
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAML_UnusedDeclarations(t *testing.T) {
+	dir, err := filepath.Abs("./fixtures/unused")
+	require.NoError(t, err)
+	content := `#%RAML 1.0 Library
+uses:
+  c: common.raml
+types:
+  Pet:
+    type: object
+    (c.internal): true
+    properties:
+      id: c.Id
+  Private: string
+`
+	rml, err := ParseFromString(content, "library.raml", dir)
+	require.NoError(t, err)
+
+	commonLocation := filepath.Join(dir, "common.raml")
+	extraLocation := filepath.Join(dir, "extra.raml")
+	unused := rml.UnusedDeclarations()
+	require.Equal(t, []UnusedDeclaration{
+		{Kind: DeclarationLibrary, Name: "extra", Location: commonLocation, Position: unused[0].Position},
+		{Kind: DeclarationAnnotationType, Name: "deprecated", Location: commonLocation, Position: unused[1].Position},
+		{Kind: DeclarationType, Name: "Node", Location: commonLocation, Position: unused[2].Position},
+		{Kind: DeclarationType, Name: "Legacy", Location: commonLocation, Position: unused[3].Position},
+		{Kind: DeclarationType, Name: "Unused", Location: extraLocation, Position: unused[4].Position},
+	}, unused)
+	require.Equal(t, 3, unused[0].Line)
+	require.Equal(t, "library \"extra\" is not used", unused[0].String())
+}
@@ -1,19 +1,25 @@
 package raml
 
 import (
+	"context"
 	"fmt"
+	"slices"
 
 	"github.com/acronis/go-stacktrace"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 )
 
 func (r *RAML) unwrapTypes(
+	ctx context.Context,
 	types *orderedmap.OrderedMap[string, *BaseShape],
 	f *Library,
 	isAnnotationType bool,
 ) *stacktrace.StackTrace {
 	var st *stacktrace.StackTrace
 	for pair := types.Oldest(); pair != nil; pair = pair.Next() {
+		if se := canceledTrace(ctx, f.Location, stacktrace.TypeUnwrapping); se != nil {
+			return se
+		}
 		base := pair.Value
 		if base == nil {
 			se := stacktrace.New("shape is nil", f.Location,
@@ -25,7 +31,7 @@ func (r *RAML) unwrapTypes(
 			}
 			continue
 		}
-		us, err := r.UnwrapShape(base)
+		us, err := r.unwrapBaseShape(base)
 		if err != nil {
 			se := StacktraceNewWrapped("unwrap shape", err, f.Location,
 				stacktrace.WithType(stacktrace.TypeUnwrapping), stacktrace.WithPosition(&base.Position))
@@ -46,9 +52,9 @@ func (r *RAML) unwrapTypes(
 	return st
 }
 
-func (r *RAML) unwrapLibrary(f *Library) *stacktrace.StackTrace {
-	st := r.unwrapTypes(f.AnnotationTypes, f, true)
-	se := r.unwrapTypes(f.Types, f, false)
+func (r *RAML) unwrapLibrary(ctx context.Context, f *Library) *stacktrace.StackTrace {
+	st := r.unwrapTypes(ctx, f.AnnotationTypes, f, true)
+	se := r.unwrapTypes(ctx, f.Types, f, false)
 	if se != nil {
 		if st == nil {
 			st = se
@@ -59,12 +65,15 @@ func (r *RAML) unwrapLibrary(f *Library) *stacktrace.StackTrace {
 	return st
 }
 
-func (r *RAML) unwrapDataType(f *DataType) *stacktrace.StackTrace {
+func (r *RAML) unwrapDataType(ctx context.Context, f *DataType) *stacktrace.StackTrace {
+	if se := canceledTrace(ctx, f.Location, stacktrace.TypeUnwrapping); se != nil {
+		return se
+	}
 	if f.Shape == nil {
 		return stacktrace.New("shape is nil", f.Location,
 			stacktrace.WithType(stacktrace.TypeUnwrapping))
 	}
-	us, err := r.UnwrapShape(f.Shape)
+	us, err := r.unwrapBaseShape(f.Shape)
 	if err != nil {
 		return StacktraceNewWrapped("unwrap shape", err, f.Location,
 			stacktrace.WithType(stacktrace.TypeUnwrapping), stacktrace.WithPosition(&f.Shape.Position))
@@ -74,12 +83,12 @@ func (r *RAML) unwrapDataType(f *DataType) *stacktrace.StackTrace {
 	return nil
 }
 
-func (r *RAML) unwrapFragments() *stacktrace.StackTrace {
+func (r *RAML) unwrapFragments(ctx context.Context) *stacktrace.StackTrace {
 	var st *stacktrace.StackTrace
-	for _, frag := range r.fragmentsCache {
-		switch f := frag.(type) {
+	for _, loc := range r.fragmentLocations() {
+		switch f := r.fragmentsCache[loc].(type) {
 		case *Library:
-			se := r.unwrapLibrary(f)
+			se := r.unwrapLibrary(ctx, f)
 			if se != nil {
 				if st == nil {
 					st = se
@@ -88,7 +97,7 @@ func (r *RAML) unwrapFragments() *stacktrace.StackTrace {
 				}
 			}
 		case *DataType:
-			se := r.unwrapDataType(f)
+			se := r.unwrapDataType(ctx, f)
 			if se != nil {
 				if st == nil {
 					st = se
@@ -105,9 +114,9 @@ func (r *RAML) unwrapDomainExtensions() *stacktrace.StackTrace {
 	var st *stacktrace.StackTrace
 	for _, item := range r.domainExtensions {
 		db := item.DefinedBy
-		ptr, err := r.GetAnnotationTypeFromFragmentPtr(db.Location, db.Name)
+		ptr, err := r.GetAnnotationTypeFromFragmentPtr(db.Location(), db.Name)
 		if err != nil {
-			se := StacktraceNewWrapped("get annotation from fragment", err, db.Location,
+			se := StacktraceNewWrapped("get annotation from fragment", err, db.Location(),
 				stacktrace.WithPosition(&db.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
 			if st == nil {
 				st = se
@@ -121,20 +130,38 @@ func (r *RAML) unwrapDomainExtensions() *stacktrace.StackTrace {
 	return st
 }
 
+// fragmentLocations returns sorted locations of fragments. Fragments are unwrapped in a stable order, so IDs of
+// shapes created by resolution are reproducible.
+func (r *RAML) fragmentLocations() []string {
+	locations := make([]string, 0, len(r.fragmentsCache))
+	for loc := range r.fragmentsCache {
+		locations = append(locations, loc)
+	}
+	slices.Sort(locations)
+	return locations
+}
+
 // UnwrapShapes unwraps all shapes in the RAML in-place.
 func (r *RAML) UnwrapShapes() error {
+	return r.unwrapShapes(r.context())
+}
+
+func (r *RAML) unwrapShapes(ctx context.Context) error {
+	if r.preserveDeclared {
+		r.preserveDeclaredShapes()
+	}
 	// We need to invalidate old cache and re-populate it because references will no longer be valid after unwrapping.
 	r.fragmentTypes = make(map[string]map[string]*BaseShape)
 	r.fragmentAnnotationTypes = make(map[string]map[string]*BaseShape)
 	r.shapes = make([]*BaseShape, 0, len(r.shapes))
-	st := r.unwrapFragments()
-	err := r.markShapeRecursions()
-	if err != nil {
-		return fmt.Errorf("mark shape recursions: %w", err)
+	r.resetSubtypes()
+	var st *stacktrace.StackTrace
+	if r.resolveWorkers > 0 {
+		st = r.unwrapFragmentsParallel(ctx)
+	} else {
+		st = r.unwrapFragments(ctx)
 	}
-	// Links to definedBy must be updated after unwrapping.
-	se := r.unwrapDomainExtensions()
-	if se != nil {
+	if se := r.unwrapAPI(); se != nil {
 		if st == nil {
 			st = se
 		} else {
@@ -142,14 +169,27 @@ func (r *RAML) UnwrapShapes() error {
 		}
 	}
 	if st != nil {
+		// Recursions cannot be marked in shapes that failed to unwrap.
 		return st
 	}
+	err := r.markShapeRecursions()
+	if err != nil {
+		return fmt.Errorf("mark shape recursions: %w", err)
+	}
+	if err = r.buildShapeDiscriminators(); err != nil {
+		return fmt.Errorf("build discriminators: %w", err)
+	}
+	// Links to definedBy must be updated after unwrapping.
+	if se := r.unwrapDomainExtensions(); se != nil {
+		return se
+	}
 	return nil
 }
 
 // markShapeRecursions marks recursive shapes by replacing the beginning of recursion with RecursiveShape in the RAML.
 func (r *RAML) markShapeRecursions() error {
 	// TODO: Maybe count shapes here?
+	r.resetRecursionCycles()
 	for _, frag := range r.fragmentsCache {
 		switch f := frag.(type) {
 		case *Library:
@@ -178,11 +218,13 @@ func (r *RAML) FindAndMarkRecursion(base *BaseShape) (*BaseShape, error) {
 	}
 
 	if base.ShapeVisited {
+		r.addRecursionCycle(base)
 		s := r.MakeRecursiveShape(base)
 		s.unwrapped = true
 		return s, nil
 	}
 	base.ShapeVisited = true
+	r.recursionStack = append(r.recursionStack, base)
 
 	var err error
 	switch t := base.Shape.(type) {
@@ -195,6 +237,7 @@ func (r *RAML) FindAndMarkRecursion(base *BaseShape) (*BaseShape, error) {
 	}
 
 	base.ShapeVisited = false
+	r.recursionStack = r.recursionStack[:len(r.recursionStack)-1]
 	if err != nil {
 		return nil, err
 	}
@@ -261,9 +304,9 @@ func (r *RAML) unwrapObjShape(objShape *ObjectShape) error {
 	if objShape.Properties != nil {
 		for pair := objShape.Properties.Oldest(); pair != nil; pair = pair.Next() {
 			prop := pair.Value
-			us, err := r.UnwrapShape(prop.Shape)
+			us, err := r.unwrapBaseShape(prop.Shape)
 			if err != nil {
-				return StacktraceNewWrapped("object property unwrap", err, objShape.Location,
+				return StacktraceNewWrapped("object property unwrap", err, objShape.Location(),
 					stacktrace.WithPosition(&objShape.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
 			}
 			prop.Shape = us
@@ -273,9 +316,9 @@ func (r *RAML) unwrapObjShape(objShape *ObjectShape) error {
 	if objShape.PatternProperties != nil {
 		for pair := objShape.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
 			prop := pair.Value
-			us, err := r.UnwrapShape(prop.Shape)
+			us, err := r.unwrapBaseShape(prop.Shape)
 			if err != nil {
-				return StacktraceNewWrapped("object pattern property unwrap", err, objShape.Location,
+				return StacktraceNewWrapped("object pattern property unwrap", err, objShape.Location(),
 					stacktrace.WithPosition(&objShape.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
 			}
 			prop.Shape = us
@@ -288,9 +331,9 @@ func (r *RAML) unwrapObjShape(objShape *ObjectShape) error {
 
 func (r *RAML) unwrapArrayShape(arrayShape *ArrayShape) error {
 	if arrayShape.Items != nil {
-		us, err := r.UnwrapShape(arrayShape.Items)
+		us, err := r.unwrapBaseShape(arrayShape.Items)
 		if err != nil {
-			return StacktraceNewWrapped("array item unwrap", err, arrayShape.Location,
+			return StacktraceNewWrapped("array item unwrap", err, arrayShape.Location(),
 				stacktrace.WithPosition(&arrayShape.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
 		}
 		arrayShape.Items = us
@@ -300,9 +343,9 @@ func (r *RAML) unwrapArrayShape(arrayShape *ArrayShape) error {
 
 func (r *RAML) unwrapUnionShape(unionShape *UnionShape) error {
 	for i, item := range unionShape.AnyOf {
-		us, err := r.UnwrapShape(item)
+		us, err := r.unwrapBaseShape(item)
 		if err != nil {
-			return StacktraceNewWrapped("union unwrap", err, unionShape.Location,
+			return StacktraceNewWrapped("union unwrap", err, unionShape.Location(),
 				stacktrace.WithPosition(&unionShape.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
 		}
 		unionShape.AnyOf[i] = us
@@ -314,34 +357,31 @@ func (r *RAML) unwrapParents(base *BaseShape) (*BaseShape, error) {
 	var source *BaseShape
 	switch {
 	case base.Link != nil:
-		us, err := r.UnwrapShape(base.Link.Shape)
+		us, err := r.unwrapBaseShape(base.Link.Shape)
 		if err != nil {
-			return nil, StacktraceNewWrapped("link unwrap", err, base.Location,
+			return nil, StacktraceNewWrapped("link unwrap", err, base.Location(),
 				stacktrace.WithPosition(&base.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
 		}
 		source = us
 		base.Link = nil
 	case len(base.Inherits) > 0:
 		inherits := base.Inherits
-		// FIXME: Multiple inheritance members must be checked for compatibility with each other before unwrapping.
-		ss, err := r.UnwrapShape(inherits[0])
-		if err != nil {
-			return nil, StacktraceNewWrapped("parent unwrap", err, base.Location,
-				stacktrace.WithPosition(&base.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
-		}
-		inherits[0] = ss
-		for i := 1; i < len(inherits); i++ {
-			us, errUnwrap := r.UnwrapShape(inherits[i])
-			if errUnwrap != nil {
-				return nil, errUnwrap
-			}
-			_, errUnwrap = ss.Inherit(us)
-			if errUnwrap != nil {
-				return nil, StacktraceNewWrapped("multiple parents unwrap", errUnwrap, base.Location,
+		for i, parent := range inherits {
+			us, err := r.unwrapBaseShape(parent)
+			if err != nil {
+				return nil, StacktraceNewWrapped("parent unwrap", err, base.Location(),
 					stacktrace.WithPosition(&base.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
 			}
 			inherits[i] = us
 		}
+		ss := inherits[0]
+		if len(inherits) > 1 {
+			var err error
+			if ss, err = r.mergeParents(base, inherits); err != nil {
+				return nil, StacktraceNewWrapped("multiple parents unwrap", err, base.Location(),
+					stacktrace.WithPosition(&base.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
+			}
+		}
 		source = ss
 	}
 	return source, nil
@@ -368,27 +408,39 @@ func (r *RAML) unwrapTarget(target Shape) error {
 // UnwrapShape recursively copies and unwraps a shape in-place. Use Clone() to create a copy of a shape if necessary.
 // Note that this method removes information about links.
 func (r *RAML) UnwrapShape(base *BaseShape) (*BaseShape, error) {
+	us, err := r.unwrapBaseShape(base)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.buildDiscriminators(us); err != nil {
+		return nil, err
+	}
+	return us, nil
+}
+
+// unwrapBaseShape unwraps the shape without computing dispatch tables of discriminators, since subtypes may not be
+// unwrapped yet while fragments are unwrapped.
+func (r *RAML) unwrapBaseShape(base *BaseShape) (*BaseShape, error) {
 	s := base.Shape
 	if s == nil {
 		return nil, fmt.Errorf("shape is nil")
 	}
 
-	if base.ShapeVisited {
+	// Skip already unwrapped shapes. They are checked first so that shapes resolved in parallel are only read.
+	if base.IsUnwrapped() {
 		return base, nil
 	}
-	base.ShapeVisited = true
 
-	// Skip already unwrapped shapes
-	if base.IsUnwrapped() {
-		base.ShapeVisited = false
+	if base.ShapeVisited {
 		return base, nil
 	}
+	base.ShapeVisited = true
 
 	// NOTE: Type aliasing is not inheritance and is not used as a source. It must be unwrapped and returned as is.
 	if base.Alias != nil {
-		us, err := r.UnwrapShape(base.Alias)
+		us, err := r.unwrapBaseShape(base.Alias)
 		if err != nil {
-			return nil, StacktraceNewWrapped("alias unwrap", err, base.Location,
+			return nil, StacktraceNewWrapped("alias unwrap", err, base.Location(),
 				stacktrace.WithPosition(&base.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
 		}
 		base.unwrapped = true
@@ -399,30 +451,30 @@ func (r *RAML) UnwrapShape(base *BaseShape) (*BaseShape, error) {
 
 	source, err := r.unwrapParents(base)
 	if err != nil {
-		return nil, StacktraceNewWrapped("unwrap parents", err, base.Location,
+		return nil, StacktraceNewWrapped("unwrap parents", err, base.Location(),
 			stacktrace.WithPosition(&base.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
 	}
 
 	if errUnwrap := r.unwrapTarget(s); errUnwrap != nil {
-		return nil, StacktraceNewWrapped("unwrap target", errUnwrap, base.Location,
+		return nil, StacktraceNewWrapped("unwrap target", errUnwrap, base.Location(),
 			stacktrace.WithPosition(&base.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
 	}
 
 	for pair := base.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
 		prop := pair.Value
-		us, errUnwrap := r.UnwrapShape(prop.Shape)
+		us, errUnwrap := r.unwrapBaseShape(prop.Shape)
 		if errUnwrap != nil {
-			return nil, StacktraceNewWrapped("custom shape facet definition unwrap", errUnwrap, base.Location,
+			return nil, StacktraceNewWrapped("custom shape facet definition unwrap", errUnwrap, base.Location(),
 				stacktrace.WithPosition(&base.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
 		}
 		prop.Shape = us
-		base.CustomShapeFacetDefinitions.Set(pair.Key, prop)
+		orderedSet(&base.CustomShapeFacetDefinitions, pair.Key, prop)
 	}
 
 	if source != nil {
 		is, errInherit := base.Inherit(source)
 		if errInherit != nil {
-			return nil, StacktraceNewWrapped("merge shapes", errInherit, base.Location,
+			return nil, StacktraceNewWrapped("merge shapes", errInherit, base.Location(),
 				stacktrace.WithPosition(&base.Position), stacktrace.WithType(stacktrace.TypeUnwrapping))
 		}
 		is.ShapeVisited = false
@@ -0,0 +1,75 @@
+package raml
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// uriTemplatePart is a literal or a parameter of a URI template.
+type uriTemplatePart struct {
+	literal string
+	// param is the name of the parameter, empty for literals.
+	param string
+}
+
+// parseURITemplate splits the URI template into literals and parameters. Only the simple string expansion of
+// RFC 6570 is supported, e.g. "https://{host}/api/{version}", which is what RAML allows in baseUri and resource paths.
+func parseURITemplate(template string) ([]uriTemplatePart, error) {
+	var parts []uriTemplatePart
+	rest := template
+	for rest != "" {
+		i := strings.IndexAny(rest, "{}")
+		if i < 0 {
+			parts = append(parts, uriTemplatePart{literal: rest})
+			break
+		}
+		if rest[i] == '}' {
+			return nil, fmt.Errorf("unexpected \"}\" at %d", len(template)-len(rest)+i)
+		}
+		if i > 0 {
+			parts = append(parts, uriTemplatePart{literal: rest[:i]})
+		}
+		rest = rest[i+1:]
+		end := strings.IndexAny(rest, "{}")
+		if end < 0 || rest[end] == '{' {
+			return nil, fmt.Errorf("unclosed \"{\" at %d", len(template)-len(rest)-1)
+		}
+		name := rest[:end]
+		if name == "" {
+			return nil, fmt.Errorf("empty parameter name at %d", len(template)-len(rest)-1)
+		}
+		parts = append(parts, uriTemplatePart{param: name})
+		rest = rest[end+1:]
+	}
+	return parts, nil
+}
+
+// uriTemplateParams returns names of parameters of the template in the order of appearance without duplicates.
+func uriTemplateParams(parts []uriTemplatePart) []string {
+	var names []string
+	seen := make(map[string]struct{}, len(parts))
+	for _, part := range parts {
+		if part.param == "" {
+			continue
+		}
+		if _, ok := seen[part.param]; !ok {
+			seen[part.param] = struct{}{}
+			names = append(names, part.param)
+		}
+	}
+	return names
+}
+
+// expandURITemplate substitutes parameters of the template with escaped values.
+func expandURITemplate(parts []uriTemplatePart, values map[string]string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		if part.param == "" {
+			b.WriteString(part.literal)
+		} else {
+			b.WriteString(url.PathEscape(values[part.param]))
+		}
+	}
+	return b.String()
+}
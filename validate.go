@@ -1,6 +1,7 @@
 package raml
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/acronis/go-stacktrace"
@@ -12,13 +13,13 @@ func (r *RAML) unwrapShape(shape *BaseShape, unwrapCache map[int64]*BaseShape) (
 		shape = shape.CloneDetached()
 		us, err := r.UnwrapShape(shape)
 		if err != nil {
-			return nil, StacktraceNewWrapped("unwrap shape", err, shape.Location,
+			return nil, StacktraceNewWrapped("unwrap shape", err, shape.Location(),
 				stacktrace.WithPosition(&shape.Position),
 				stacktrace.WithType(stacktrace.TypeValidating))
 		}
 		_, err = r.FindAndMarkRecursion(us)
 		if err != nil {
-			return nil, StacktraceNewWrapped("find recursion", err, shape.Location,
+			return nil, StacktraceNewWrapped("find recursion", err, shape.Location(),
 				stacktrace.WithPosition(&shape.Position),
 				stacktrace.WithType(stacktrace.TypeValidating))
 		}
@@ -29,11 +30,15 @@ func (r *RAML) unwrapShape(shape *BaseShape, unwrapCache map[int64]*BaseShape) (
 }
 
 func (r *RAML) validateTypes(
+	ctx context.Context,
 	types *orderedmap.OrderedMap[string, *BaseShape],
 	unwrapCache map[int64]*BaseShape,
 ) *stacktrace.StackTrace {
 	var st *stacktrace.StackTrace
 	for pair := types.Oldest(); pair != nil; pair = pair.Next() {
+		if se := canceledTrace(ctx, pair.Value.Location(), stacktrace.TypeValidating); se != nil {
+			return se
+		}
 		shape, se := r.unwrapShape(pair.Value, unwrapCache)
 		if se != nil {
 			if st == nil {
@@ -44,7 +49,7 @@ func (r *RAML) validateTypes(
 			continue
 		}
 		if err := shape.Check(); err != nil {
-			se = StacktraceNewWrapped("check type", err, shape.Location,
+			se = StacktraceNewWrapped("check type", err, shape.Location(),
 				stacktrace.WithPosition(&shape.Position),
 				stacktrace.WithType(stacktrace.TypeValidating))
 			if st == nil {
@@ -55,7 +60,7 @@ func (r *RAML) validateTypes(
 			continue
 		}
 		if err := r.validateShapeCommons(shape); err != nil {
-			se = StacktraceNewWrapped("validate shape commons", err, shape.Location,
+			se = StacktraceNewWrapped("validate shape commons", err, shape.Location(),
 				stacktrace.WithPosition(&shape.Position),
 				stacktrace.WithType(stacktrace.TypeValidating))
 			if st == nil {
@@ -69,10 +74,10 @@ func (r *RAML) validateTypes(
 	return st
 }
 
-func (r *RAML) validateLibrary(f *Library, unwrapCache map[int64]*BaseShape) *stacktrace.StackTrace {
-	st := r.validateTypes(f.AnnotationTypes, unwrapCache)
+func (r *RAML) validateLibrary(ctx context.Context, f *Library, unwrapCache map[int64]*BaseShape) *stacktrace.StackTrace {
+	st := r.validateTypes(ctx, f.AnnotationTypes, unwrapCache)
 
-	if se := r.validateTypes(f.Types, unwrapCache); se != nil {
+	if se := r.validateTypes(ctx, f.Types, unwrapCache); se != nil {
 		if st == nil {
 			st = se
 		} else {
@@ -82,19 +87,22 @@ func (r *RAML) validateLibrary(f *Library, unwrapCache map[int64]*BaseShape) *st
 	return st
 }
 
-func (r *RAML) validateDataType(f *DataType, unwrapCache map[int64]*BaseShape) *stacktrace.StackTrace {
+func (r *RAML) validateDataType(ctx context.Context, f *DataType, unwrapCache map[int64]*BaseShape) *stacktrace.StackTrace {
+	if se := canceledTrace(ctx, f.Location, stacktrace.TypeValidating); se != nil {
+		return se
+	}
 	s := f.Shape
 	if !s.unwrapped {
 		s = s.CloneDetached()
 		us, err := r.UnwrapShape(s)
 		if err != nil {
-			return StacktraceNewWrapped("unwrap shape", err, s.Location,
+			return StacktraceNewWrapped("unwrap shape", err, s.Location(),
 				stacktrace.WithPosition(&s.Position),
 				stacktrace.WithType(stacktrace.TypeValidating))
 		}
 		_, err = r.FindAndMarkRecursion(us)
 		if err != nil {
-			return StacktraceNewWrapped("find recursion", err, s.Location,
+			return StacktraceNewWrapped("find recursion", err, s.Location(),
 				stacktrace.WithPosition(&s.Position),
 				stacktrace.WithType(stacktrace.TypeValidating))
 		}
@@ -102,24 +110,24 @@ func (r *RAML) validateDataType(f *DataType, unwrapCache map[int64]*BaseShape) *
 		s = us
 	}
 	if err := s.Check(); err != nil {
-		return StacktraceNewWrapped("check data type", err, s.Location,
+		return StacktraceNewWrapped("check data type", err, s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithType(stacktrace.TypeValidating))
 	}
 	if err := r.validateShapeCommons(s); err != nil {
-		return StacktraceNewWrapped("validate shape commons", err, s.Location,
+		return StacktraceNewWrapped("validate shape commons", err, s.Location(),
 			stacktrace.WithPosition(&s.Position),
 			stacktrace.WithType(stacktrace.TypeValidating))
 	}
 	return nil
 }
 
-func (r *RAML) validateFragments(unwrapCache map[int64]*BaseShape) *stacktrace.StackTrace {
+func (r *RAML) validateFragments(ctx context.Context, unwrapCache map[int64]*BaseShape) *stacktrace.StackTrace {
 	var st *stacktrace.StackTrace
 	for _, frag := range r.fragmentsCache {
 		switch f := frag.(type) {
 		case *Library:
-			if err := r.validateLibrary(f, unwrapCache); err != nil {
+			if err := r.validateLibrary(ctx, f, unwrapCache); err != nil {
 				if st == nil {
 					st = err
 				} else {
@@ -127,7 +135,7 @@ func (r *RAML) validateFragments(unwrapCache map[int64]*BaseShape) *stacktrace.S
 				}
 			}
 		case *DataType:
-			if err := r.validateDataType(f, unwrapCache); err != nil {
+			if err := r.validateDataType(ctx, f, unwrapCache); err != nil {
 				if st == nil {
 					st = err
 				} else {
@@ -146,7 +154,7 @@ func (r *RAML) validateDomainExtensions(unwrapCache map[int64]*BaseShape) *stack
 		if !db.unwrapped {
 			us, ok := unwrapCache[db.ID]
 			if !ok {
-				se := stacktrace.New("unwrapped shape not found", db.Location,
+				se := stacktrace.New("unwrapped shape not found", db.Location(),
 					stacktrace.WithPosition(&db.Position),
 					stacktrace.WithType(stacktrace.TypeValidating))
 				if st == nil {
@@ -175,11 +183,15 @@ func (r *RAML) validateDomainExtensions(unwrapCache map[int64]*BaseShape) *stack
 }
 
 func (r *RAML) ValidateShapes() error {
+	return r.validateShapes(r.context())
+}
+
+func (r *RAML) validateShapes(ctx context.Context) error {
 	// Unwrap cache stores the mapping of original IDs to unwrapped shapes
 	// to ensure the original references (aliases and links) match.
 	unwrapCache := make(map[int64]*BaseShape)
 
-	st := r.validateFragments(unwrapCache)
+	st := r.validateFragments(ctx, unwrapCache)
 
 	if se := r.validateDomainExtensions(unwrapCache); se != nil {
 		if st == nil {
@@ -200,14 +212,14 @@ func (r *RAML) validateObjectShape(s *ObjectShape) error {
 		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
 			s := pair.Value.Shape
 			if err := r.validateShapeCommons(s); err != nil {
-				return StacktraceNewWrapped("validate property", err, s.Location,
+				return StacktraceNewWrapped("validate property", err, s.Location(),
 					stacktrace.WithPosition(&s.Position), stacktrace.WithInfo("property", pair.Key))
 			}
 		}
 		for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
 			s := pair.Value.Shape
 			if err := r.validateShapeCommons(s); err != nil {
-				return StacktraceNewWrapped("validate pattern property", err, s.Location,
+				return StacktraceNewWrapped("validate pattern property", err, s.Location(),
 					stacktrace.WithPosition(&s.Position), stacktrace.WithInfo("property", pair.Key))
 			}
 		}
@@ -219,9 +231,15 @@ func (r *RAML) validateShapeCommons(s *BaseShape) error {
 	if err := r.validateShapeFacets(s); err != nil {
 		return err
 	}
+	if err := r.runFacetValidators(s); err != nil {
+		return err
+	}
 	if err := r.validateExamples(s); err != nil {
 		return err
 	}
+	if err := s.validateXML(); err != nil {
+		return err
+	}
 
 	switch s := s.Shape.(type) {
 	case *ObjectShape:
@@ -231,14 +249,14 @@ func (r *RAML) validateShapeCommons(s *BaseShape) error {
 	case *ArrayShape:
 		if s.Items != nil {
 			if err := r.validateShapeCommons(s.Items); err != nil {
-				return StacktraceNewWrapped("validate items", err, s.Base().Location,
+				return StacktraceNewWrapped("validate items", err, s.Base().Location(),
 					stacktrace.WithPosition(&s.Base().Position))
 			}
 		}
 	case *UnionShape:
 		for _, item := range s.AnyOf {
 			if err := r.validateShapeCommons(item); err != nil {
-				return StacktraceNewWrapped("validate union item", err, s.Base().Location,
+				return StacktraceNewWrapped("validate union item", err, s.Base().Location(),
 					stacktrace.WithPosition(&s.Base().Position))
 			}
 		}
@@ -247,7 +265,8 @@ func (r *RAML) validateShapeCommons(s *BaseShape) error {
 }
 
 func (r *RAML) validateExamples(base *BaseShape) error {
-	if base.Example != nil {
+	// Examples with "strict: false" are not validated against the type.
+	if base.Example != nil && base.Example.Strict {
 		if err := base.Validate(base.Example.Data.Value); err != nil {
 			return StacktraceNewWrapped("validate example", err, base.Example.Location,
 				stacktrace.WithPosition(&base.Example.Position))
@@ -256,6 +275,9 @@ func (r *RAML) validateExamples(base *BaseShape) error {
 	if base.Examples != nil {
 		for pair := base.Examples.Map.Oldest(); pair != nil; pair = pair.Next() {
 			ex := pair.Value
+			if !ex.Strict {
+				continue
+			}
 			if err := base.Validate(ex.Data.Value); err != nil {
 				return StacktraceNewWrapped("validate example", err, ex.Location,
 					stacktrace.WithPosition(&ex.Position))
@@ -272,32 +294,23 @@ func (r *RAML) validateExamples(base *BaseShape) error {
 }
 
 func (r *RAML) validateShapeFacets(base *BaseShape) error {
-	// TODO: Doesn't support multiple inheritance.
-	inherits := base.Inherits
 	shapeFacetDefs := base.CustomShapeFacetDefinitions
-	validationFacetDefs := make(map[string]Property)
-	for {
-		if len(inherits) == 0 {
-			break
-		}
-		parent := inherits[0]
-		for pair := parent.CustomShapeFacetDefinitions.Oldest(); pair != nil; pair = pair.Next() {
-			f := pair.Value
-			if _, ok := shapeFacetDefs.Get(f.Name); ok {
-				return stacktrace.New("duplicate custom facet", f.Shape.Location,
-					stacktrace.WithPosition(&f.Shape.Position), stacktrace.WithInfo("facet", f.Name))
-			}
-			validationFacetDefs[f.Name] = f
+	validationFacetDefs := base.inheritedFacetDefinitions()
+	for pair := validationFacetDefs.Oldest(); pair != nil; pair = pair.Next() {
+		f := pair.Value
+		if _, ok := orderedGet(shapeFacetDefs, f.Name); ok {
+			return stacktrace.New("duplicate custom facet", f.Shape.Location(),
+				stacktrace.WithPosition(&f.Shape.Position), stacktrace.WithInfo("facet", f.Name))
 		}
-		inherits = parent.Inherits
 	}
 
 	shapeFacets := base.CustomShapeFacets
-	for k, facetDef := range validationFacetDefs {
-		f, ok := shapeFacets.Get(k)
+	for pair := validationFacetDefs.Oldest(); pair != nil; pair = pair.Next() {
+		k, facetDef := pair.Key, pair.Value
+		f, ok := orderedGet(shapeFacets, k)
 		if !ok {
 			if facetDef.Required {
-				return stacktrace.New("required custom facet is missing", base.Location,
+				return stacktrace.New("required custom facet is missing", base.Location(),
 					stacktrace.WithPosition(&base.Position), stacktrace.WithInfo("facet", k))
 			}
 			continue
@@ -310,7 +323,7 @@ func (r *RAML) validateShapeFacets(base *BaseShape) error {
 
 	for pair := shapeFacets.Oldest(); pair != nil; pair = pair.Next() {
 		k, f := pair.Key, pair.Value
-		if _, ok := validationFacetDefs[k]; !ok {
+		if _, ok := validationFacetDefs.Get(k); !ok {
 			return stacktrace.New("unknown facet", f.Location, stacktrace.WithPosition(&f.Position),
 				stacktrace.WithInfo("facet", k))
 		}
@@ -0,0 +1,223 @@
+package raml
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// UnknownPropertyPolicy defines how properties that are covered neither by properties nor by pattern properties
+// are handled when the object type does not set additionalProperties.
+type UnknownPropertyPolicy int
+
+const (
+	// UnknownPropertiesIgnore accepts unknown properties as RAML does by default.
+	UnknownPropertiesIgnore UnknownPropertyPolicy = iota
+	// UnknownPropertiesWarn accepts unknown properties and reports them as warnings.
+	UnknownPropertiesWarn
+	// UnknownPropertiesReject rejects unknown properties as if the type had "additionalProperties: false".
+	UnknownPropertiesReject
+)
+
+// ValidateOptions controls a single validation of a value, see BaseShape.ValidateWithOptions.
+type ValidateOptions struct {
+	// UnknownProperties is the policy of properties not declared by object types.
+	// It does not apply to types that set additionalProperties explicitly.
+	UnknownProperties UnknownPropertyPolicy
+	// OptionalNull makes null values of optional and pattern properties valid as if the properties were absent.
+	// By default, null is valid only for the nil type, unions with nil (e.g. "string?") and any.
+	OptionalNull bool
+	// Explain makes a failed validation return ExplainedError with the chain of shapes consulted, including
+	// attempted union members, matched pattern properties and discriminated subtypes.
+	Explain bool
+}
+
+// ValidationWarning is a non-fatal issue found during validation of a value.
+type ValidationWarning struct {
+	// Path is the path of the value, e.g. "$.pets[0].nickname".
+	Path    string
+	Message string
+}
+
+func (w ValidationWarning) String() string {
+	return w.Path + ": " + w.Message
+}
+
+// ValidationStep is a shape consulted during validation of a value, see ValidateOptions.Explain.
+type ValidationStep struct {
+	// Path is the path of the value, e.g. "$.pets[0]".
+	Path  string
+	Shape *BaseShape
+	// Note tells why the shape was consulted, e.g. "union member 2 of 3", empty for the declared shape of the value.
+	Note string
+	// Err is the error of the shape, nil if the value is valid against it.
+	Err error
+	// Depth is the number of steps the step is nested in.
+	Depth int
+}
+
+func (s ValidationStep) String() string {
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat("  ", s.Depth))
+	sb.WriteString(s.Path + ": ")
+	if s.Shape.Name != "" {
+		sb.WriteString(s.Shape.Name)
+	} else {
+		sb.WriteString(s.Shape.Type)
+	}
+	fmt.Fprintf(&sb, " (%s:%d)", filepath.Base(s.Shape.Location()), s.Shape.Line)
+	if len(s.Shape.Inherits) > 0 {
+		names := make([]string, len(s.Shape.Inherits))
+		for i, parent := range s.Shape.Inherits {
+			names[i] = parent.Name
+			if names[i] == "" {
+				names[i] = parent.Type
+			}
+		}
+		sb.WriteString(" inherits " + strings.Join(names, ", "))
+	}
+	if s.Note != "" {
+		sb.WriteString(" [" + s.Note + "]")
+	}
+	if s.Err != nil {
+		sb.WriteString(": " + s.Err.Error())
+	} else {
+		sb.WriteString(": ok")
+	}
+	return sb.String()
+}
+
+// ExplainedError is returned by ValidateWithOptions with ValidateOptions.Explain set when the value is invalid.
+type ExplainedError struct {
+	Err error
+	// Steps contains shapes consulted during validation in the order they were entered.
+	Steps []ValidationStep
+}
+
+func (e *ExplainedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExplainedError) Unwrap() error {
+	return e.Err
+}
+
+// Explain returns consulted shapes one per line, nested steps are indented.
+func (e *ExplainedError) Explain() string {
+	var sb strings.Builder
+	for _, step := range e.Steps {
+		sb.WriteString(step.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// ValidateWithOptions validates the value like Validate does and returns warnings sorted by path.
+func (s *BaseShape) ValidateWithOptions(v interface{}, opts ValidateOptions) ([]ValidationWarning, error) {
+	start := s.raml.validationStart()
+	st := newValidationState(opts)
+	defer st.release()
+	if opts.Explain {
+		st.explain = &explanation{}
+	}
+	err := validateValue(s.Shape, v, st)
+	s.raml.validationDone(start, err)
+	if err != nil {
+		if st.explain != nil {
+			return nil, &ExplainedError{Err: err, Steps: st.explain.steps}
+		}
+		return nil, err
+	}
+	slices.SortStableFunc(st.warnings, func(x, y ValidationWarning) int {
+		return strings.Compare(x.Path, y.Path)
+	})
+	return st.warnings, nil
+}
+
+// validationState is shared by shapes during a single validation.
+type validationState struct {
+	opts     ValidateOptions
+	warnings []ValidationWarning
+	// path is the path of the validated value, shared by forks.
+	path *valuePath
+	// depth is the number of recursive shapes entered on the path to the validated value.
+	depth int
+	// explain records consulted shapes, nil unless ValidateOptions.Explain is set.
+	explain *explanation
+}
+
+func newValidationState(opts ValidateOptions) *validationState {
+	return &validationState{opts: opts, path: newValuePath("$")}
+}
+
+// release returns the path to the pool, the state must not be used afterwards.
+func (st *validationState) release() {
+	st.path.release()
+}
+
+// explanation is shared by forks of the validation state to record all attempted alternatives.
+type explanation struct {
+	steps []ValidationStep
+	depth int
+	// note is the note of the next step.
+	note string
+}
+
+// note sets the note of the shape that is validated next.
+func (st *validationState) note(format string, args ...any) {
+	if st.explain != nil {
+		st.explain.note = fmt.Sprintf(format, args...)
+	}
+}
+
+func (st *validationState) warn(message string) {
+	st.warnings = append(st.warnings, ValidationWarning{Path: st.path.String(), Message: message})
+}
+
+// fork returns a state for an alternative that may fail, e.g. a union member.
+func (st *validationState) fork() *validationState {
+	return &validationState{opts: st.opts, path: st.path, depth: st.depth, explain: st.explain}
+}
+
+// merge keeps warnings of the alternative that succeeded.
+func (st *validationState) merge(other *validationState) {
+	st.warnings = append(st.warnings, other.warnings...)
+}
+
+// stateValidator is implemented by shapes that contain other shapes to pass the state to them.
+type stateValidator interface {
+	validateWithState(v interface{}, st *validationState) error
+}
+
+// validateAt validates the value at the path with default options, it backs validate of shapes with nested values.
+func validateAt(sv stateValidator, v interface{}, ctxPath string) error {
+	st := &validationState{path: newValuePath(ctxPath)}
+	defer st.release()
+	return sv.validateWithState(v, st)
+}
+
+// validateValue validates the value against the shape within the validation state.
+// Null is reported explicitly instead of a type mismatch if the shape does not accept it.
+func validateValue(s Shape, v interface{}, st *validationState) (err error) {
+	if e := st.explain; e != nil {
+		i := len(e.steps)
+		e.steps = append(e.steps, ValidationStep{Path: st.path.String(), Shape: s.Base(), Note: e.note, Depth: e.depth})
+		e.note = ""
+		e.depth++
+		defer func() {
+			e.depth--
+			e.steps[i].Err = err
+		}()
+	}
+	if sv, ok := s.(stateValidator); ok {
+		err = sv.validateWithState(v, st)
+	} else {
+		// Shapes without nested values do not report paths, so the path is not rendered for them.
+		err = s.validate(v, "")
+	}
+	if err != nil && v == nil {
+		return fmt.Errorf("null is not allowed, expected %s", s.Base().Type)
+	}
+	return err
+}
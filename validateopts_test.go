@@ -0,0 +1,199 @@
+package raml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseShape_ValidateWithOptions(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Pet:
+    type: object
+    properties:
+      name: string
+      tags?: Tag[]
+      extra?:
+        type: object
+        additionalProperties: true
+  Tag:
+    type: object
+    properties:
+      label: string
+  Owner:
+    type: object
+    properties:
+      pet: Pet | string
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	lib := rml.EntryPoint().(*Library)
+	pet, _ := lib.Types.Get("Pet")
+	owner, _ := lib.Types.Get("Owner")
+
+	value := map[string]any{
+		"name":  "Rex",
+		"age":   3,
+		"tags":  []any{map[string]any{"label": "dog", "color": "brown"}},
+		"extra": map[string]any{"anything": true},
+	}
+
+	warnings, err := pet.ValidateWithOptions(value, ValidateOptions{})
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+
+	warnings, err = pet.ValidateWithOptions(value, ValidateOptions{UnknownProperties: UnknownPropertiesWarn})
+	require.NoError(t, err)
+	require.Equal(t, []ValidationWarning{
+		{Path: "$.age", Message: "unknown property \"age\""},
+		{Path: "$.tags[0].color", Message: "unknown property \"color\""},
+	}, warnings)
+
+	_, err = pet.ValidateWithOptions(value, ValidateOptions{UnknownProperties: UnknownPropertiesReject})
+	require.ErrorContains(t, err, "unknown property")
+	delete(value, "age")
+	value["tags"] = []any{map[string]any{"label": "dog"}}
+	_, err = pet.ValidateWithOptions(value, ValidateOptions{UnknownProperties: UnknownPropertiesReject})
+	require.NoError(t, err)
+
+	t.Run("union members", func(t *testing.T) {
+		warnings, err := owner.ValidateWithOptions(map[string]any{"pet": map[string]any{"name": "Rex", "age": 3}},
+			ValidateOptions{UnknownProperties: UnknownPropertiesWarn})
+		require.NoError(t, err)
+		require.Equal(t, []ValidationWarning{{Path: "$.pet.age", Message: "unknown property \"age\""}}, warnings)
+
+		warnings, err = owner.ValidateWithOptions(map[string]any{"pet": "Rex"},
+			ValidateOptions{UnknownProperties: UnknownPropertiesReject})
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+
+		_, err = owner.ValidateWithOptions(map[string]any{"pet": map[string]any{"name": "Rex", "age": 3}},
+			ValidateOptions{UnknownProperties: UnknownPropertiesReject})
+		require.Error(t, err)
+	})
+}
+
+func TestBaseShape_ValidateWithOptions_OptionalNull(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Pet:
+    type: object
+    properties:
+      name: string
+      nickname?: string
+      birthday: date-only | nil
+      tags:
+        type: array
+        items: string
+        required: false
+      /^x-/: integer
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	pet, _ := rml.EntryPoint().(*Library).Types.Get("Pet")
+
+	tests := []struct {
+		name      string
+		value     map[string]any
+		strictErr string
+		lenientOK bool
+	}{
+		{
+			name:      "nil-unioned property",
+			value:     map[string]any{"name": "Rex", "birthday": nil},
+			lenientOK: true,
+		},
+		{
+			name:      "required property",
+			value:     map[string]any{"name": nil, "birthday": nil},
+			strictErr: "validate property $.name: null is not allowed, expected string",
+		},
+		{
+			name:      "optional property",
+			value:     map[string]any{"name": "Rex", "birthday": nil, "nickname": nil},
+			strictErr: "validate property $.nickname: null is not allowed, expected string",
+			lenientOK: true,
+		},
+		{
+			name:      "optional array property",
+			value:     map[string]any{"name": "Rex", "birthday": nil, "tags": nil},
+			strictErr: "validate property $.tags: null is not allowed, expected array",
+			lenientOK: true,
+		},
+		{
+			name:      "pattern property",
+			value:     map[string]any{"name": "Rex", "birthday": nil, "x-age": nil},
+			lenientOK: true,
+		},
+		{
+			name:      "array item",
+			value:     map[string]any{"name": "Rex", "birthday": nil, "tags": []any{"a", nil}},
+			strictErr: "validate array item $.tags[1]: null is not allowed, expected string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := pet.ValidateWithOptions(tt.value, ValidateOptions{})
+			if tt.strictErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.strictErr)
+			}
+			_, err = pet.ValidateWithOptions(tt.value, ValidateOptions{OptionalNull: true})
+			if tt.lenientOK {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+	require.EqualError(t, pet.Validate(nil), "null is not allowed, expected object")
+}
+
+func TestBaseShape_ValidateWithOptions_Explain(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Named:
+    type: object
+    properties:
+      name:
+        type: string
+        minLength: 3
+  Pet:
+    type: Named
+    properties:
+      /^x-/: integer
+  Owner:
+    type: object
+    properties:
+      pet: Pet | string
+`
+	dir := t.TempDir()
+	rml, err := ParseFromString(content, "library.raml", dir, OptWithUnwrap())
+	require.NoError(t, err)
+	owner, err := rml.LookupType("Owner", "")
+	require.NoError(t, err)
+
+	value := map[string]any{"pet": map[string]any{"x-age": "three"}}
+	_, err = owner.ValidateWithOptions(value, ValidateOptions{})
+	require.Error(t, err)
+	var explained *ExplainedError
+	require.False(t, errors.As(err, &explained))
+
+	_, err = owner.ValidateWithOptions(value, ValidateOptions{Explain: true})
+	require.ErrorAs(t, err, &explained)
+	require.Equal(t, explained.Err.Error(), err.Error())
+	require.Equal(t, `$: Owner (library.raml:14): validate properties: validate property $.pet: `+
+		`library.raml:16:12: value does not match any type
+  $.pet: pet (library.raml:16): library.raml:16:12: value does not match any type
+    $.pet: Pet (library.raml:10) inherits Named [union member 1 of 2]: missing required property "name" at $.pet
+      $.pet.x-age: /^x-/ (library.raml:12) [pattern property /^x-/]: invalid type, got string, expected int, uint, float64, json.Number or *big.Int
+    $.pet: string (library.raml:16) [union member 2 of 2]: invalid type, got map[string]interface {}, expected string
+`, strings.ReplaceAll(explained.Explain(), "[error] parsing: "+dir+"/", ""))
+
+	_, err = owner.ValidateWithOptions(map[string]any{"pet": "Rex"}, ValidateOptions{Explain: true})
+	require.NoError(t, err)
+}
@@ -0,0 +1,54 @@
+package raml
+
+import (
+	"strconv"
+	"sync"
+)
+
+// valuePath is the path of the validated value, e.g. "$.pets[0]". Segments are appended when validation enters
+// nested values and truncated when it leaves them, so the path is rendered only when it is reported.
+type valuePath struct {
+	buf []byte
+}
+
+var valuePathPool = sync.Pool{
+	New: func() any {
+		return &valuePath{buf: make([]byte, 0, 64)}
+	},
+}
+
+// newValuePath returns a pooled path of the root value, it should be released after validation.
+func newValuePath(root string) *valuePath {
+	p := valuePathPool.Get().(*valuePath)
+	p.buf = append(p.buf[:0], root...)
+	return p
+}
+
+func (p *valuePath) release() {
+	valuePathPool.Put(p)
+}
+
+// key enters the property and returns the length of the path to truncate to when leaving it.
+func (p *valuePath) key(k string) int {
+	n := len(p.buf)
+	p.buf = append(p.buf, '.')
+	p.buf = append(p.buf, k...)
+	return n
+}
+
+// index enters the array item and returns the length of the path to truncate to when leaving it.
+func (p *valuePath) index(i int) int {
+	n := len(p.buf)
+	p.buf = append(p.buf, '[')
+	p.buf = strconv.AppendInt(p.buf, int64(i), 10)
+	p.buf = append(p.buf, ']')
+	return n
+}
+
+func (p *valuePath) truncate(n int) {
+	p.buf = p.buf[:n]
+}
+
+func (p *valuePath) String() string {
+	return string(p.buf)
+}
@@ -0,0 +1,110 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseShape_Validate_paths(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Numbers:
+    properties:
+      values: integer[]
+  Strings:
+    properties:
+      values: string[]
+  Owner:
+    properties:
+      name: string
+  Pet:
+    properties:
+      data: Numbers | Strings
+      owners: Owner[]
+      tags:
+        type: array
+        items: string
+        uniqueItems: true
+`
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap())
+	require.NoError(t, err)
+	pet, err := rml.LookupType("Pet", "")
+	require.NoError(t, err)
+	compiled, err := pet.Compile()
+	require.NoError(t, err)
+
+	// The first union member fails deep in the value, paths of later errors must not include its segments.
+	value := map[string]any{
+		"data":   map[string]any{"values": []any{"a", "b"}},
+		"owners": []any{map[string]any{"name": "Bob"}, map[string]any{}},
+		"tags":   []any{"a", "b"},
+	}
+	for _, validate := range []func(any) error{pet.Validate, compiled.Validate} {
+		err = validate(value)
+		var missing *MissingPropertiesError
+		require.ErrorAs(t, err, &missing)
+		require.Equal(t, "$.owners[1]", missing.Path)
+		require.ErrorContains(t, err, "validate array item $.owners[1]:")
+	}
+
+	value["owners"] = []any{}
+	value["tags"] = []any{"a", "b", "a"}
+	require.EqualError(t, pet.Validate(value), "validate properties: validate property $.tags: "+
+		"array contains duplicate items")
+	tags := make([]any, 2*pairwiseUniqueItemsLimit)
+	for i := range tags {
+		tags[i] = string(rune('a' + i))
+	}
+	value["tags"] = tags
+	require.NoError(t, pet.Validate(value))
+	tags[len(tags)-1] = "a"
+	require.Error(t, pet.Validate(value))
+}
+
+func BenchmarkBaseShape_Validate(b *testing.B) {
+	content := `#%RAML 1.0 Library
+types:
+  Owner:
+    properties:
+      name: string
+      tags:
+        type: array
+        items: string
+        uniqueItems: true
+  Pet:
+    properties:
+      name: string
+      owners: Owner[]
+      parent?: Pet
+`
+	rml, err := ParseFromString(content, "library.raml", b.TempDir(), OptWithUnwrap())
+	require.NoError(b, err)
+	pet, err := rml.LookupType("Pet", "")
+	require.NoError(b, err)
+	owners := make([]any, 10)
+	for i := range owners {
+		owners[i] = map[string]any{"name": "owner", "tags": []any{"a", "b", "c", "d"}}
+	}
+	valid := map[string]any{
+		"name": "Rex", "owners": owners, "parent": map[string]any{"name": "Max", "owners": owners},
+	}
+	invalid := map[string]any{"name": "Rex", "owners": []any{map[string]any{"name": "owner"}}}
+
+	b.Run("Valid", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if err := pet.Validate(valid); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Invalid", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if err := pet.Validate(invalid); err == nil {
+				b.Fatal("expected error")
+			}
+		}
+	})
+}
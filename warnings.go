@@ -0,0 +1,109 @@
+package raml
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/acronis/go-stacktrace"
+)
+
+// Warnings returns non-fatal issues found during parsing: issues reported while reading fragments (e.g. duplicate
+// keys, deprecated keys or unsupported patterns) followed by issues found by the analysis of the parsed model
+// (unknown facets, unused libraries and types that shadow built-in types).
+func (r *RAML) Warnings() []*stacktrace.StackTrace {
+	return slices.Concat(r.warnings, r.analysisWarnings)
+}
+
+// Diagnostics returns warnings (see Warnings) as diagnostics.
+func (r *RAML) Diagnostics() []Diagnostic {
+	var result []Diagnostic
+	for _, st := range r.Warnings() {
+		collectDiagnostics(st, &result)
+	}
+	return result
+}
+
+// addWarning records the non-fatal issue found while reading a fragment.
+func (r *RAML) addWarning(message string, location string, opts ...stacktrace.Option) {
+	opts = append(opts, stacktrace.WithSeverity(stacktrace.SeverityWarning))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.warnings = append(r.warnings, stacktrace.New(message, location, opts...))
+}
+
+// markLibraryUsed records that the fragment at the location references the library by its alias.
+func (r *RAML) markLibraryUsed(refName string, location string) {
+	alias, _, found := CutReferenceName(refName)
+	if !found {
+		return
+	}
+	if r.usedLibraries == nil {
+		r.usedLibraries = make(map[string]map[string]struct{})
+	}
+	location = filepath.Clean(location)
+	if r.usedLibraries[location] == nil {
+		r.usedLibraries[location] = make(map[string]struct{})
+	}
+	r.usedLibraries[location][alias] = struct{}{}
+}
+
+// analyze reports issues of the resolved model that do not prevent its use. Results replace the results of
+// the previous analysis since the model may be partially re-read by Reparse.
+func (r *RAML) analyze() {
+	r.analysisWarnings = nil
+	warn := func(message string, location string, opts ...stacktrace.Option) {
+		opts = append(opts, stacktrace.WithSeverity(stacktrace.SeverityWarning))
+		r.analysisWarnings = append(r.analysisWarnings, stacktrace.New(message, location, opts...))
+	}
+
+	locations := make([]string, 0, len(r.fragmentsCache))
+	for location := range r.fragmentsCache {
+		locations = append(locations, location)
+	}
+	slices.Sort(locations)
+	for _, location := range locations {
+		frag := r.fragmentsCache[location]
+		for _, d := range r.unusedLibraries(location) {
+			warn(d.String(), d.Location, stacktrace.WithPosition(&d.Position))
+		}
+		lib, ok := frag.(*Library)
+		if !ok {
+			continue
+		}
+		for pair := lib.Types.Oldest(); pair != nil; pair = pair.Next() {
+			if isBuiltinType(pair.Key) {
+				warn(fmt.Sprintf("type %q shadows the built-in type", pair.Key), pair.Value.Location(),
+					stacktrace.WithPosition(&pair.Value.Position))
+			}
+		}
+	}
+
+	for _, base := range r.shapes {
+		if base.CustomShapeFacets.Len() == 0 {
+			continue
+		}
+		defs := base.inheritedFacetDefinitions()
+		for pair := base.CustomShapeFacets.Oldest(); pair != nil; pair = pair.Next() {
+			if _, ok := defs.Get(pair.Key); ok {
+				continue
+			}
+			msg := fmt.Sprintf("unknown facet %q", pair.Key)
+			if _, isAny := base.Shape.(*AnyShape); isAny && isTypeFacet(pair.Key) {
+				msg = fmt.Sprintf("facet %q has no effect on any", pair.Key)
+			}
+			warn(msg, pair.Value.Location, stacktrace.WithPosition(&pair.Value.Position))
+		}
+	}
+}
+
+func isBuiltinType(name string) bool {
+	if _, ok := SetOfScalarTypes[name]; ok {
+		return true
+	}
+	switch name {
+	case TypeAny, TypeArray, TypeObject, TypeNil, TypeUnion:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package raml
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/acronis/go-stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRAML_Diagnostics(t *testing.T) {
+	dir, err := filepath.Abs("./fixtures/diagnostics")
+	require.NoError(t, err)
+	content := `#%RAML 1.0 Library
+uses:
+  types: common.raml
+  annotations: common.raml
+  unused: common.raml
+schemas:
+  string:
+    type: object
+  Pet:
+    schema: object
+    (annotations.internal): true
+    properties:
+      id: types.Id
+  Name:
+    type: string
+    color: red
+`
+	rml, err := ParseFromString(content, "library.raml", dir)
+	require.NoError(t, err)
+	location := filepath.Join(dir, "library.raml")
+
+	diagnostics := rml.Diagnostics()
+	messages := make([]string, len(diagnostics))
+	for i, d := range diagnostics {
+		require.Equal(t, stacktrace.SeverityWarning, d.Severity)
+		require.Equal(t, location, d.Location)
+		messages[i] = d.Message
+	}
+	require.Equal(t, []string{
+		"schemas is deprecated, use types",
+		"schema is deprecated, use type",
+		"library \"unused\" is not used",
+		"type \"string\" shadows the built-in type",
+		"unknown facet \"color\"",
+	}, messages)
+	require.Equal(t, 5, diagnostics[2].Line)
+	require.Equal(t, 16, diagnostics[4].Line)
+	require.Len(t, rml.Warnings(), 5)
+
+	_, err = ParseFromString(content, "library.raml", dir, OptWithValidate())
+	require.ErrorContains(t, err, "unknown facet")
+}
@@ -0,0 +1,665 @@
+package raml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/acronis/go-stacktrace"
+	"gopkg.in/yaml.v3"
+)
+
+// Facets of the xml node.
+const (
+	XMLFacetAttribute = "attribute"
+	XMLFacetWrapped   = "wrapped"
+	XMLFacetName      = "name"
+	XMLFacetNamespace = "namespace"
+	XMLFacetPrefix    = "prefix"
+)
+
+// XML describes the serialization of the type instance to XML.
+type XML struct {
+	// Attribute serializes the instance as an attribute of the parent element instead of an element.
+	Attribute *bool
+	// Wrapped wraps the items of an array into an additional element.
+	Wrapped *bool
+	// Name overrides the name of the element or attribute.
+	Name *string
+	// Namespace is the namespace of the element or attribute.
+	Namespace *string
+	// Prefix is the prefix of the namespace.
+	Prefix *string
+
+	Location string
+	stacktrace.Position
+}
+
+// IsAttribute returns true if the instance is serialized as an attribute.
+func (x *XML) IsAttribute() bool {
+	return x != nil && x.Attribute != nil && *x.Attribute
+}
+
+// IsWrapped returns true if the items of an array are wrapped into an additional element.
+func (x *XML) IsWrapped() bool {
+	return x != nil && x.Wrapped != nil && *x.Wrapped
+}
+
+func (s *BaseShape) decodeXML(valueNode *yaml.Node) error {
+	if valueNode.Kind != yaml.MappingNode {
+		return stacktrace.New("xml must be map", s.Location(), WithNodePosition(valueNode))
+	}
+	x := &XML{Location: s.Location(), Position: stacktrace.Position{Line: valueNode.Line, Column: valueNode.Column}}
+	for i := 0; i != len(valueNode.Content); i += 2 {
+		node := valueNode.Content[i]
+		data := valueNode.Content[i+1]
+		var err error
+		switch node.Value {
+		case XMLFacetAttribute:
+			err = data.Decode(&x.Attribute)
+		case XMLFacetWrapped:
+			err = data.Decode(&x.Wrapped)
+		case XMLFacetName:
+			err = data.Decode(&x.Name)
+		case XMLFacetNamespace:
+			err = data.Decode(&x.Namespace)
+		case XMLFacetPrefix:
+			err = data.Decode(&x.Prefix)
+		default:
+			return stacktrace.New("unknown xml facet", s.Location(), WithNodePosition(node),
+				stacktrace.WithInfo("facet", node.Value))
+		}
+		if err != nil {
+			return StacktraceNewWrapped(fmt.Sprintf("decode xml %s", node.Value), err, s.Location(),
+				WithNodePosition(data))
+		}
+	}
+	s.XML = x
+	return nil
+}
+
+// validateXML checks that xml facets are applicable to the shape.
+func (s *BaseShape) validateXML() error {
+	if s.XML == nil {
+		return nil
+	}
+	if s.XML.IsAttribute() && !isScalarShape(s.Shape) {
+		return stacktrace.New("xml attribute is allowed only for scalar types", s.XML.Location,
+			stacktrace.WithPosition(&s.XML.Position))
+	}
+	if _, ok := s.Shape.(*ArrayShape); s.XML.IsWrapped() && !ok {
+		return stacktrace.New("xml wrapped is allowed only for array types", s.XML.Location,
+			stacktrace.WithPosition(&s.XML.Position))
+	}
+	if s.XML.Prefix != nil && s.XML.Namespace == nil {
+		return stacktrace.New("xml prefix requires namespace", s.XML.Location,
+			stacktrace.WithPosition(&s.XML.Position))
+	}
+	return nil
+}
+
+func isScalarShape(s Shape) bool {
+	switch s.(type) {
+	case *StringShape, *IntegerShape, *NumberShape, *BooleanShape, *DateTimeShape, *DateTimeOnlyShape,
+		*DateOnlyShape, *TimeOnlyShape, *FileShape, *NilShape:
+		return true
+	}
+	return false
+}
+
+// xmlRootName is used for the root element of anonymous types without xml name.
+const xmlRootName = "root"
+
+// ToXML serializes the instance of the shape to XML according to xml facets of the shape and its members.
+// Objects are expected as map[string]any and arrays as []any, i.e. in the form produced by YAML and JSON decoders.
+// NOTE: Shape must be unwrapped to take inherited properties into account.
+func (s *BaseShape) ToXML(v any) ([]byte, error) {
+	name := s.Name
+	if name == "" {
+		name = xmlRootName
+	}
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	w := &xmlWriter{enc: enc, namespaces: make(map[string]string)}
+	if err := w.element(s, name, v); err != nil {
+		return nil, fmt.Errorf("encode xml: %w", err)
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, fmt.Errorf("flush xml: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+type xmlWriter struct {
+	enc *xml.Encoder
+	// namespaces maps declared prefixes to namespaces in the scope of the current element.
+	namespaces map[string]string
+}
+
+// xmlName returns the qualified name of the node and the namespace declaration if it is not declared yet.
+func (w *xmlWriter) xmlName(s *BaseShape, defaultName string) (xml.Name, *xml.Attr) {
+	name := defaultName
+	x := s.XML
+	if x == nil {
+		return xml.Name{Local: name}, nil
+	}
+	if x.Name != nil {
+		name = *x.Name
+	}
+	if x.Namespace == nil {
+		return xml.Name{Local: name}, nil
+	}
+	prefix := ""
+	if x.Prefix != nil {
+		prefix = *x.Prefix
+	}
+	var decl *xml.Attr
+	if ns, ok := w.namespaces[prefix]; !ok || ns != *x.Namespace {
+		if prefix == "" {
+			decl = &xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: *x.Namespace}
+		} else {
+			decl = &xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: *x.Namespace}
+		}
+	}
+	if prefix != "" {
+		name = prefix + ":" + name
+	}
+	return xml.Name{Local: name}, decl
+}
+
+func (w *xmlWriter) element(s *BaseShape, name string, v any) error {
+	switch shape := s.Shape.(type) {
+	case *ArrayShape:
+		return w.array(shape, name, v)
+	case *UnionShape:
+		// The first member that accepts the value defines the serialization.
+		for _, member := range shape.AnyOf {
+			if member.Validate(v) == nil {
+				return w.element(member, s.elementName(name), v)
+			}
+		}
+		return w.untyped(s, name, v)
+	case *ObjectShape:
+		return w.object(shape, name, v)
+	default:
+		return w.untyped(s, name, v)
+	}
+}
+
+// elementName returns the element name of the shape, the name from xml facets has priority over the default one.
+func (s *BaseShape) elementName(defaultName string) string {
+	if s.XML != nil && s.XML.Name != nil {
+		return *s.XML.Name
+	}
+	return defaultName
+}
+
+func (w *xmlWriter) start(s *BaseShape, name string, attrs []xml.Attr) (xml.StartElement, func(), error) {
+	xn, decl := w.xmlName(s, name)
+	restore := func() {}
+	if decl != nil {
+		prefix := strings.TrimPrefix(strings.TrimPrefix(decl.Name.Local, "xmlns"), ":")
+		prev, existed := w.namespaces[prefix]
+		w.namespaces[prefix] = decl.Value
+		restore = func() {
+			if existed {
+				w.namespaces[prefix] = prev
+			} else {
+				delete(w.namespaces, prefix)
+			}
+		}
+		attrs = append([]xml.Attr{*decl}, attrs...)
+	}
+	start := xml.StartElement{Name: xn, Attr: attrs}
+	if err := w.enc.EncodeToken(start); err != nil {
+		restore()
+		return start, nil, fmt.Errorf("encode start element %s: %w", xn.Local, err)
+	}
+	return start, restore, nil
+}
+
+func (w *xmlWriter) end(start xml.StartElement, restore func()) error {
+	defer restore()
+	if err := w.enc.EncodeToken(start.End()); err != nil {
+		return fmt.Errorf("encode end element %s: %w", start.Name.Local, err)
+	}
+	return nil
+}
+
+func (w *xmlWriter) untyped(s *BaseShape, name string, v any) error {
+	switch val := v.(type) {
+	case map[string]any:
+		return w.object(&ObjectShape{BaseShape: s}, name, val)
+	case []any:
+		return w.array(&ArrayShape{BaseShape: s}, name, val)
+	}
+	start, restore, err := w.start(s, name, nil)
+	if err != nil {
+		return err
+	}
+	if v != nil {
+		if err = w.enc.EncodeToken(xml.CharData(fmt.Sprint(v))); err != nil {
+			restore()
+			return fmt.Errorf("encode value of %s: %w", name, err)
+		}
+	}
+	return w.end(start, restore)
+}
+
+func (w *xmlWriter) array(s *ArrayShape, name string, v any) error {
+	items, ok := v.([]any)
+	if !ok {
+		return fmt.Errorf("invalid type of %s, got %T, expected []any", name, v)
+	}
+	itemShape := s.Items
+	if itemShape == nil {
+		itemShape = &BaseShape{Shape: &AnyShape{}}
+	}
+	if !s.XML.IsWrapped() {
+		// Items are repeated elements named after the array.
+		for _, item := range items {
+			if err := w.element(itemShape, s.elementName(name), item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	start, restore, err := w.start(s.BaseShape, name, nil)
+	if err != nil {
+		return err
+	}
+	itemName := itemShape.Name
+	if itemName == "" {
+		itemName = itemShape.TypeLabel
+	}
+	if itemName == "" {
+		itemName = name
+	}
+	for _, item := range items {
+		if err = w.element(itemShape, itemName, item); err != nil {
+			restore()
+			return err
+		}
+	}
+	return w.end(start, restore)
+}
+
+func (w *xmlWriter) object(s *ObjectShape, name string, v any) error {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("invalid type of %s, got %T, expected map[string]any", name, v)
+	}
+	var attrs []xml.Attr
+	type child struct {
+		shape *BaseShape
+		name  string
+		value any
+	}
+	var children []child
+	known := make(map[string]struct{})
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		known[pair.Key] = struct{}{}
+		value, present := obj[pair.Key]
+		if !present {
+			continue
+		}
+		prop := pair.Value.Shape
+		if prop.XML.IsAttribute() {
+			xn, _ := w.xmlName(prop, pair.Key)
+			if value != nil {
+				attrs = append(attrs, xml.Attr{Name: xn, Value: fmt.Sprint(value)})
+			}
+			continue
+		}
+		children = append(children, child{shape: prop, name: pair.Key, value: value})
+	}
+	// Additional and pattern properties are written after declared ones in a stable order.
+	rest := make([]string, 0, len(obj))
+	for k := range obj {
+		if _, ok := known[k]; !ok {
+			rest = append(rest, k)
+		}
+	}
+	slices.Sort(rest)
+	for _, k := range rest {
+		shape := &BaseShape{Shape: &AnyShape{}}
+		for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value.Pattern != nil && pair.Value.Pattern.MatchString(k) {
+				shape = pair.Value.Shape
+				break
+			}
+		}
+		children = append(children, child{shape: shape, name: k, value: obj[k]})
+	}
+
+	start, restore, err := w.start(s.BaseShape, name, attrs)
+	if err != nil {
+		return err
+	}
+	for _, c := range children {
+		if err = w.element(c.shape, c.name, c.value); err != nil {
+			restore()
+			return err
+		}
+	}
+	return w.end(start, restore)
+}
+
+// xsiNamespace is the namespace of the "nil" attribute that marks nil elements.
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// xmlNode is an element of the decoded XML document.
+type xmlNode struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*xmlNode
+	text     strings.Builder
+}
+
+func (n *xmlNode) isNil() bool {
+	for _, attr := range n.attrs {
+		if attr.Name.Space == xsiNamespace && attr.Name.Local == "nil" {
+			return strings.TrimSpace(attr.Value) == "true"
+		}
+	}
+	return false
+}
+
+func decodeXMLDocument(data []byte) (*xmlNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: t.Name}
+			for _, attr := range t.Attr {
+				// Namespace declarations are resolved by the decoder.
+				if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					continue
+				}
+				n.attrs = append(n.attrs, attr)
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root != nil {
+				return nil, fmt.Errorf("multiple root elements")
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+	return root, nil
+}
+
+// FromXML deserializes the XML document according to xml facets of the shape and its members.
+// It is the inverse of ToXML: objects are returned as map[string]any, arrays as []any and scalars are converted to
+// the types expected by Validate. Elements and attributes that are not declared by the shape are returned as
+// additional properties.
+// The name of the root element is checked only if it is set by xml facets.
+// NOTE: Shape must be unwrapped to take inherited properties into account.
+func (s *BaseShape) FromXML(data []byte) (any, error) {
+	root, err := decodeXMLDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode xml: %w", err)
+	}
+	if s.XML != nil && s.XML.Name != nil && root.name.Local != *s.XML.Name {
+		return nil, fmt.Errorf("unexpected root element %s, expected %s", root.name.Local, *s.XML.Name)
+	}
+	if s.XML != nil && s.XML.Namespace != nil && root.name.Space != *s.XML.Namespace {
+		return nil, fmt.Errorf("unexpected namespace of root element %q, expected %q", root.name.Space,
+			*s.XML.Namespace)
+	}
+	return xmlValue(s, root, "$")
+}
+
+// ValidateXML validates the XML document against the shape.
+// See FromXML for the rules of mapping XML to the shape.
+func (s *BaseShape) ValidateXML(data []byte) error {
+	v, err := s.FromXML(data)
+	if err != nil {
+		return err
+	}
+	return s.Validate(v)
+}
+
+// xmlValue converts the element to the value of the shape.
+func xmlValue(s *BaseShape, n *xmlNode, ctxPath string) (any, error) {
+	if n.isNil() {
+		return nil, nil
+	}
+	switch shape := s.Shape.(type) {
+	case *RecursiveShape:
+		return xmlValue(shape.Head, n, ctxPath)
+	case *ObjectShape:
+		return xmlObject(shape, n, ctxPath)
+	case *ArrayShape:
+		// The element wraps the items.
+		items := make([]any, len(n.children))
+		for i, child := range n.children {
+			v, err := xmlValue(xmlItems(shape), child, ctxPath+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return nil, err
+			}
+			items[i] = v
+		}
+		return items, nil
+	case *UnionShape:
+		// The first member that accepts the value defines the deserialization.
+		for _, member := range shape.AnyOf {
+			v, err := xmlValue(member, n, ctxPath)
+			if err == nil && member.Validate(v) == nil {
+				return v, nil
+			}
+		}
+		return xmlUntyped(n), nil
+	case *AnyShape, *JSONShape:
+		return xmlUntyped(n), nil
+	default:
+		return xmlScalar(s, n.text.String(), ctxPath)
+	}
+}
+
+// xmlScalar converts the text of the element or the attribute to the value of the scalar shape.
+func xmlScalar(s *BaseShape, text string, ctxPath string) (any, error) {
+	switch s.Shape.(type) {
+	case *IntegerShape:
+		v, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid integer %q", ctxPath, text)
+		}
+		return v, nil
+	case *NumberShape:
+		v, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid number %q", ctxPath, text)
+		}
+		return v, nil
+	case *BooleanShape:
+		// Lexical space of xs:boolean.
+		switch strings.TrimSpace(text) {
+		case "true", "1":
+			return true, nil
+		case "false", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%s: invalid boolean %q", ctxPath, text)
+	case *NilShape:
+		if text == "" {
+			return nil, nil
+		}
+		return text, nil
+	case *UnionShape, *RecursiveShape:
+		n := &xmlNode{}
+		n.text.WriteString(text)
+		return xmlValue(s, n, ctxPath)
+	default:
+		return text, nil
+	}
+}
+
+func xmlItems(s *ArrayShape) *BaseShape {
+	if s.Items == nil {
+		return &BaseShape{Shape: &AnyShape{}}
+	}
+	return s.Items
+}
+
+// xmlProperty resolves recursion of the property shape to determine the mapping of the property.
+func xmlProperty(s *BaseShape) *BaseShape {
+	for {
+		rec, ok := s.Shape.(*RecursiveShape)
+		if !ok {
+			return s
+		}
+		s = rec.Head
+	}
+}
+
+// xmlMatches reports whether the XML name matches the name and the namespace of the property.
+func xmlMatches(s *BaseShape, key string, name xml.Name) bool {
+	if name.Local != s.elementName(key) {
+		return false
+	}
+	return s.XML == nil || s.XML.Namespace == nil || name.Space == *s.XML.Namespace
+}
+
+func xmlObject(s *ObjectShape, n *xmlNode, ctxPath string) (map[string]any, error) {
+	obj := make(map[string]any)
+	matched := make([]bool, len(n.children))
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		key := pair.Key
+		prop := xmlProperty(pair.Value.Shape)
+		ctxPathK := ctxPath + "." + key
+		if prop.XML.IsAttribute() {
+			for _, attr := range n.attrs {
+				if xmlMatches(prop, key, attr.Name) {
+					v, err := xmlScalar(prop, attr.Value, ctxPathK)
+					if err != nil {
+						return nil, err
+					}
+					obj[key] = v
+				}
+			}
+			continue
+		}
+		arr, isArray := prop.Shape.(*ArrayShape)
+		var items []any
+		for i, child := range n.children {
+			if matched[i] || !xmlMatches(prop, key, child.name) {
+				continue
+			}
+			matched[i] = true
+			if isArray && !prop.XML.IsWrapped() {
+				// Items are repeated elements named after the array.
+				v, err := xmlValue(xmlItems(arr), child, ctxPathK+"["+strconv.Itoa(len(items))+"]")
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, v)
+				continue
+			}
+			if _, ok := obj[key]; ok {
+				return nil, fmt.Errorf("%s: duplicate element %s", ctxPathK, child.name.Local)
+			}
+			v, err := xmlValue(pair.Value.Shape, child, ctxPathK)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = v
+		}
+		if items != nil {
+			obj[key] = items
+		}
+	}
+	// Undeclared attributes and elements are additional properties.
+	for _, attr := range n.attrs {
+		if attr.Name.Space == xsiNamespace {
+			continue
+		}
+		if _, ok := obj[attr.Name.Local]; !ok && !s.hasXMLAttribute(attr.Name) {
+			obj[attr.Name.Local] = attr.Value
+		}
+	}
+	for i, child := range n.children {
+		if matched[i] {
+			continue
+		}
+		key := child.name.Local
+		shape := &BaseShape{Shape: &AnyShape{}}
+		for pair := s.PatternProperties.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Value.Pattern != nil && pair.Value.Pattern.MatchString(key) {
+				shape = pair.Value.Shape
+				break
+			}
+		}
+		v, err := xmlValue(shape, child, ctxPath+"."+key)
+		if err != nil {
+			return nil, err
+		}
+		xmlAppend(obj, key, v)
+	}
+	return obj, nil
+}
+
+// xmlAppend sets the value of the key, values of repeated elements are collected into an array.
+func xmlAppend(obj map[string]any, key string, v any) {
+	prev, ok := obj[key]
+	if !ok {
+		obj[key] = v
+		return
+	}
+	if items, isArray := prev.([]any); isArray {
+		obj[key] = append(items, v)
+		return
+	}
+	obj[key] = []any{prev, v}
+}
+
+func (s *ObjectShape) hasXMLAttribute(name xml.Name) bool {
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		prop := pair.Value.Shape
+		if prop.XML.IsAttribute() && xmlMatches(prop, pair.Key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// xmlUntyped converts the element that is not described by a shape. Elements with neither attributes nor children are
+// returned as strings.
+func xmlUntyped(n *xmlNode) any {
+	if len(n.attrs) == 0 && len(n.children) == 0 {
+		return n.text.String()
+	}
+	obj := make(map[string]any)
+	for _, attr := range n.attrs {
+		obj[attr.Name.Local] = attr.Value
+	}
+	for _, child := range n.children {
+		xmlAppend(obj, child.name.Local, xmlUntyped(child))
+	}
+	return obj
+}
@@ -0,0 +1,293 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseShape_ToXML(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Person:
+    xml:
+      name: person
+      namespace: http://example.com/person
+      prefix: p
+    properties:
+      id:
+        type: integer
+        xml:
+          attribute: true
+      name: string
+      nick?:
+        type: string
+        xml:
+          name: nickname
+      addresses:
+        type: Address[]
+        xml:
+          wrapped: true
+      tags?: string[]
+  Address:
+    properties:
+      city: string
+  Base:
+    xml:
+      name: base
+    properties:
+      a: string
+  Child:
+    type: Base
+`
+	tests := []struct {
+		name     string
+		typeName string
+		value    any
+		want     string
+	}{
+		{
+			name:     "object with attributes, wrapped and repeated arrays",
+			typeName: "Person",
+			value: map[string]any{
+				"id":        1,
+				"name":      "John",
+				"nick":      "J",
+				"addresses": []any{map[string]any{"city": "Berlin"}, map[string]any{"city": "Paris"}},
+				"tags":      []any{"a", "b"},
+				"extra":     true,
+			},
+			want: `<p:person xmlns:p="http://example.com/person" id="1">` +
+				`<name>John</name><nickname>J</nickname>` +
+				`<addresses><Address><city>Berlin</city></Address><Address><city>Paris</city></Address></addresses>` +
+				`<tags>a</tags><tags>b</tags><extra>true</extra></p:person>`,
+		},
+		{
+			name:     "inherited xml facets",
+			typeName: "Child",
+			value:    map[string]any{"a": "x & y"},
+			want:     `<base><a>x &amp; y</a></base>`,
+		},
+	}
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	lib := rml.EntryPoint().(*Library)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ok := lib.Types.Get(tt.typeName)
+			require.True(t, ok)
+			got, err := s.ToXML(tt.value)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestBaseShape_validateXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "attribute on object",
+			content: `#%RAML 1.0 Library
+types:
+  A:
+    type: object
+    xml:
+      attribute: true
+`,
+			wantErr: "xml attribute is allowed only for scalar types",
+		},
+		{
+			name: "wrapped on string",
+			content: `#%RAML 1.0 Library
+types:
+  A:
+    type: string
+    xml:
+      wrapped: true
+`,
+			wantErr: "xml wrapped is allowed only for array types",
+		},
+		{
+			name: "prefix without namespace",
+			content: `#%RAML 1.0 Library
+types:
+  A:
+    type: string
+    xml:
+      prefix: p
+`,
+			wantErr: "xml prefix requires namespace",
+		},
+		{
+			name: "unknown facet",
+			content: `#%RAML 1.0 Library
+types:
+  A:
+    type: string
+    xml:
+      unknown: true
+`,
+			wantErr: "unknown xml facet",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFromString(tt.content, "library.raml", t.TempDir(), OptWithValidate())
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBaseShape_FromXML(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Person:
+    xml:
+      name: person
+      namespace: http://example.com/person
+      prefix: p
+    properties:
+      id:
+        type: integer
+        xml:
+          attribute: true
+      name: string
+      nick?:
+        type: string
+        xml:
+          name: nickname
+      active?: boolean
+      addresses:
+        type: Address[]
+        xml:
+          wrapped: true
+      tags?: string[]
+  Address:
+    properties:
+      city: string
+      zip?: integer
+`
+	tests := []struct {
+		name    string
+		data    string
+		want    any
+		wantErr string
+	}{
+		{
+			name: "object with attributes, wrapped and repeated arrays",
+			data: `<p:person xmlns:p="http://example.com/person" id="1">` +
+				`<name>John</name><nickname>J</nickname><active>1</active>` +
+				`<addresses><Address><city>Berlin</city><zip>10115</zip></Address><Address><city>Paris</city></Address></addresses>` +
+				`<tags>a</tags><tags>b</tags><extra>x</extra><extra>y</extra></p:person>`,
+			want: map[string]any{
+				"id":     1,
+				"name":   "John",
+				"nick":   "J",
+				"active": true,
+				"addresses": []any{
+					map[string]any{"city": "Berlin", "zip": 10115},
+					map[string]any{"city": "Paris"},
+				},
+				"tags":  []any{"a", "b"},
+				"extra": []any{"x", "y"},
+			},
+		},
+		{
+			name:    "unexpected root element",
+			data:    `<p:human xmlns:p="http://example.com/person" id="1"></p:human>`,
+			wantErr: "unexpected root element human, expected person",
+		},
+		{
+			name:    "unexpected namespace",
+			data:    `<person id="1"></person>`,
+			wantErr: "unexpected namespace of root element",
+		},
+		{
+			name:    "invalid integer attribute",
+			data:    `<p:person xmlns:p="http://example.com/person" id="one"></p:person>`,
+			wantErr: `$.id: invalid integer "one"`,
+		},
+		{
+			name:    "duplicate element",
+			data:    `<p:person xmlns:p="http://example.com/person" id="1"><name>a</name><name>b</name></p:person>`,
+			wantErr: "$.name: duplicate element name",
+		},
+		{
+			name:    "malformed document",
+			data:    `<p:person xmlns:p="http://example.com/person">`,
+			wantErr: "decode xml",
+		},
+	}
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	lib := rml.EntryPoint().(*Library)
+	s, ok := lib.Types.Get("Person")
+	require.True(t, ok)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.FromXML([]byte(tt.data))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.NoError(t, s.ValidateXML([]byte(tt.data)))
+		})
+	}
+}
+
+func TestBaseShape_ValidateXML(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Item:
+    additionalProperties: false
+    properties:
+      count:
+        type: integer
+        minimum: 1
+      label?: string | nil
+`
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			data: `<Item><count>2</count><label>a</label></Item>`,
+		},
+		{
+			name: "nil union member",
+			data: `<Item xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><count>2</count><label xsi:nil="true"/></Item>`,
+		},
+		{
+			name:    "facet violation",
+			data:    `<Item><count>0</count></Item>`,
+			wantErr: "value must be greater than 1",
+		},
+		{
+			name:    "additional element",
+			data:    `<Item><count>1</count><other/></Item>`,
+			wantErr: `unexpected additional property "other"`,
+		},
+	}
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	s, ok := rml.EntryPoint().(*Library).Types.Get("Item")
+	require.True(t, ok)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidateXML([]byte(tt.data))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package raml
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// XSDNamespace is the namespace of XML Schema.
+const XSDNamespace = "http://www.w3.org/2001/XMLSchema"
+
+// XSDSchema represents an XML Schema document.
+//
+// https://www.w3.org/TR/xmlschema-1/
+type XSDSchema struct {
+	XMLName            xml.Name `xml:"xs:schema"`
+	XMLNSXS            string   `xml:"xmlns:xs,attr"`
+	XMLNSTNS           string   `xml:"xmlns:tns,attr,omitempty"`
+	TargetNamespace    string   `xml:"targetNamespace,attr,omitempty"`
+	ElementFormDefault string   `xml:"elementFormDefault,attr,omitempty"`
+
+	Elements     []*XSDElement     `xml:"xs:element"`
+	ComplexTypes []*XSDComplexType `xml:"xs:complexType"`
+	SimpleTypes  []*XSDSimpleType  `xml:"xs:simpleType"`
+}
+
+// XSDAnnotation contains the documentation of a schema component.
+type XSDAnnotation struct {
+	Documentation string `xml:"xs:documentation"`
+}
+
+// XSDElement represents an element declaration.
+type XSDElement struct {
+	Name      string `xml:"name,attr"`
+	Type      string `xml:"type,attr,omitempty"`
+	MinOccurs string `xml:"minOccurs,attr,omitempty"`
+	MaxOccurs string `xml:"maxOccurs,attr,omitempty"`
+	Nillable  bool   `xml:"nillable,attr,omitempty"`
+
+	Annotation  *XSDAnnotation  `xml:"xs:annotation,omitempty"`
+	ComplexType *XSDComplexType `xml:"xs:complexType,omitempty"`
+	SimpleType  *XSDSimpleType  `xml:"xs:simpleType,omitempty"`
+}
+
+// XSDAttribute represents an attribute declaration.
+type XSDAttribute struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	Use  string `xml:"use,attr,omitempty"`
+
+	Annotation *XSDAnnotation `xml:"xs:annotation,omitempty"`
+	SimpleType *XSDSimpleType `xml:"xs:simpleType,omitempty"`
+}
+
+// XSDComplexType represents a complex type definition.
+type XSDComplexType struct {
+	Name string `xml:"name,attr,omitempty"`
+
+	Annotation *XSDAnnotation  `xml:"xs:annotation,omitempty"`
+	Sequence   *XSDGroup       `xml:"xs:sequence,omitempty"`
+	Choice     *XSDGroup       `xml:"xs:choice,omitempty"`
+	Attributes []*XSDAttribute `xml:"xs:attribute"`
+}
+
+// XSDGroup represents a sequence or a choice model group.
+type XSDGroup struct {
+	MinOccurs string `xml:"minOccurs,attr,omitempty"`
+	MaxOccurs string `xml:"maxOccurs,attr,omitempty"`
+
+	Elements []*XSDElement `xml:"xs:element"`
+	Any      *XSDAny       `xml:"xs:any,omitempty"`
+}
+
+// XSDAny represents a wildcard of elements.
+type XSDAny struct {
+	MinOccurs       string `xml:"minOccurs,attr,omitempty"`
+	MaxOccurs       string `xml:"maxOccurs,attr,omitempty"`
+	ProcessContents string `xml:"processContents,attr,omitempty"`
+}
+
+// XSDSimpleType represents a simple type definition.
+type XSDSimpleType struct {
+	Name string `xml:"name,attr,omitempty"`
+
+	Annotation  *XSDAnnotation  `xml:"xs:annotation,omitempty"`
+	Restriction *XSDRestriction `xml:"xs:restriction,omitempty"`
+	Union       *XSDUnion       `xml:"xs:union,omitempty"`
+}
+
+// XSDUnion represents a union of simple types.
+type XSDUnion struct {
+	SimpleTypes []*XSDSimpleType `xml:"xs:simpleType"`
+}
+
+// XSDRestriction represents a restriction of a built-in simple type.
+type XSDRestriction struct {
+	Base string `xml:"base,attr"`
+
+	Enumerations []XSDFacet `xml:"xs:enumeration"`
+	MinInclusive *XSDFacet  `xml:"xs:minInclusive,omitempty"`
+	MaxInclusive *XSDFacet  `xml:"xs:maxInclusive,omitempty"`
+	MinExclusive *XSDFacet  `xml:"xs:minExclusive,omitempty"`
+	MaxExclusive *XSDFacet  `xml:"xs:maxExclusive,omitempty"`
+	MinLength    *XSDFacet  `xml:"xs:minLength,omitempty"`
+	MaxLength    *XSDFacet  `xml:"xs:maxLength,omitempty"`
+	Patterns     []XSDFacet `xml:"xs:pattern"`
+}
+
+// XSDFacet represents a constraining facet.
+type XSDFacet struct {
+	Value string `xml:"value,attr"`
+}
+
+// Marshal renders the schema as an indented XML document.
+func (s *XSDSchema) Marshal() ([]byte, error) {
+	b, err := xml.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal xsd: %w", err)
+	}
+	return append([]byte(xml.Header), append(b, '\n')...), nil
+}
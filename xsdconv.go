@@ -0,0 +1,496 @@
+package raml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Built-in XML Schema types.
+const (
+	XSDTypeString       = "xs:string"
+	XSDTypeInteger      = "xs:integer"
+	XSDTypeByte         = "xs:byte"
+	XSDTypeShort        = "xs:short"
+	XSDTypeInt          = "xs:int"
+	XSDTypeLong         = "xs:long"
+	XSDTypeFloat        = "xs:float"
+	XSDTypeDouble       = "xs:double"
+	XSDTypeDecimal      = "xs:decimal"
+	XSDTypeBoolean      = "xs:boolean"
+	XSDTypeDateTime     = "xs:dateTime"
+	XSDTypeDate         = "xs:date"
+	XSDTypeTime         = "xs:time"
+	XSDTypeBase64Binary = "xs:base64Binary"
+	XSDTypeAnyType      = "xs:anyType"
+	XSDTypeAnySimple    = "xs:anySimpleType"
+)
+
+const (
+	xsdUnbounded = "unbounded"
+	xsdOptional  = "0"
+	// rfc2616Pattern matches the IMF-fixdate format of HTTP-date.
+	rfc2616Pattern = "(Mon|Tue|Wed|Thu|Fri|Sat|Sun), [0-3][0-9] (Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) " +
+		"[0-9]{4} ([01][0-9]|2[0-3]):[0-5][0-9]:[0-5][0-9] GMT"
+)
+
+// XSDConverter converts shapes to XML Schema honoring xml facets.
+//
+// The entrypoint shape is declared as a named type and a global element. Nested shapes are declared inline,
+// except recursive ones that are declared as named types.
+// NOTE: Namespaces of nested shapes are not supported, the namespace of the entrypoint becomes the target namespace.
+type XSDConverter struct {
+	schema *XSDSchema
+	// declared is a set of names of declared types.
+	declared map[string]struct{}
+}
+
+func NewXSDConverter() *XSDConverter {
+	return &XSDConverter{}
+}
+
+func (c *XSDConverter) Convert(s Shape) (*XSDSchema, error) {
+	base := s.Base()
+	if !base.IsUnwrapped() {
+		return nil, fmt.Errorf("entrypoint shape must be unwrapped")
+	}
+	if base.Name == "" {
+		return nil, fmt.Errorf("entrypoint shape must be named")
+	}
+	c.schema = &XSDSchema{XMLNSXS: XSDNamespace}
+	c.declared = make(map[string]struct{})
+	if base.XML != nil && base.XML.Namespace != nil {
+		c.schema.TargetNamespace = *base.XML.Namespace
+		c.schema.XMLNSTNS = *base.XML.Namespace
+		c.schema.ElementFormDefault = "qualified"
+	}
+	c.schema.Elements = append(c.schema.Elements, &XSDElement{
+		Name:       base.elementName(base.Name),
+		Type:       c.namedType(base),
+		Annotation: xsdAnnotation(base),
+	})
+	return c.schema, nil
+}
+
+// ref returns the qualified reference to the named type.
+func (c *XSDConverter) ref(name string) string {
+	if c.schema.TargetNamespace != "" {
+		return "tns:" + name
+	}
+	return name
+}
+
+// namedType declares the named type for the shape if it is not declared yet and returns the reference to it.
+func (c *XSDConverter) namedType(base *BaseShape) string {
+	// TODO: Type name is not unique, need pretty naming to avoid collisions.
+	name := xsdTypeName(base)
+	if _, ok := c.declared[name]; ok {
+		return c.ref(name)
+	}
+	// NOTE: Occupy the name before traversing to support recursive types.
+	c.declared[name] = struct{}{}
+	c.define(name, base)
+	return c.ref(name)
+}
+
+// define declares the named type for the shape.
+func (c *XSDConverter) define(name string, base *BaseShape) {
+	switch shape := base.Shape.(type) {
+	case *ArrayShape:
+		// Root array has no parent element, so items are always wrapped by the element of the type.
+		ct := &XSDComplexType{Name: name, Annotation: xsdAnnotation(base)}
+		ct.Sequence = &XSDGroup{Elements: []*XSDElement{c.itemsElement(shape, name, true)}}
+		c.schema.ComplexTypes = append(c.schema.ComplexTypes, ct)
+		return
+	}
+	typeName, ct, st := c.typeOf(base)
+	switch {
+	case ct != nil:
+		ct.Name = name
+		ct.Annotation = xsdAnnotation(base)
+		c.schema.ComplexTypes = append(c.schema.ComplexTypes, ct)
+	case st != nil:
+		st.Name = name
+		st.Annotation = xsdAnnotation(base)
+		c.schema.SimpleTypes = append(c.schema.SimpleTypes, st)
+	case typeName == XSDTypeAnyType:
+		c.schema.ComplexTypes = append(c.schema.ComplexTypes, &XSDComplexType{
+			Name:       name,
+			Annotation: xsdAnnotation(base),
+			Sequence:   &XSDGroup{Any: xsdAnyElements()},
+		})
+	default:
+		c.schema.SimpleTypes = append(c.schema.SimpleTypes, &XSDSimpleType{
+			Name:        name,
+			Annotation:  xsdAnnotation(base),
+			Restriction: &XSDRestriction{Base: typeName},
+		})
+	}
+}
+
+// element returns the element declaration of the object property or array item.
+func (c *XSDConverter) element(base *BaseShape, name string, required bool) *XSDElement {
+	nillable := false
+	if member, ok := nonNilMember(base); ok {
+		base = member
+		nillable = true
+	}
+	name = base.elementName(name)
+	if rec, ok := base.Shape.(*RecursiveShape); ok {
+		if _, isArray := rec.Head.Shape.(*ArrayShape); isArray {
+			return c.recursiveArrayElement(base, rec.Head, name, required)
+		}
+	}
+	if arr, ok := base.Shape.(*ArrayShape); ok {
+		if !base.XML.IsWrapped() {
+			el := c.itemsElement(arr, name, required)
+			el.Annotation = xsdAnnotation(base)
+			return el
+		}
+		el := &XSDElement{Name: name, Annotation: xsdAnnotation(base), Nillable: nillable}
+		if !required {
+			el.MinOccurs = xsdOptional
+		}
+		el.ComplexType = &XSDComplexType{Sequence: &XSDGroup{
+			Elements: []*XSDElement{c.itemsElement(arr, xsdItemName(arr, name), true)},
+		}}
+		return el
+	}
+	el := &XSDElement{Name: name, Annotation: xsdAnnotation(base), Nillable: nillable}
+	if !required {
+		el.MinOccurs = xsdOptional
+	}
+	el.Type, el.ComplexType, el.SimpleType = c.typeOf(base)
+	return el
+}
+
+// recursiveArrayElement returns the element of the array that refers to itself through its items.
+// Items are declared as a named type to keep the serialization of the array the same as for non-recursive arrays.
+func (c *XSDConverter) recursiveArrayElement(base, head *BaseShape, name string, required bool) *XSDElement {
+	arr := head.Shape.(*ArrayShape)
+	items := &XSDElement{Name: name, Type: XSDTypeAnyType}
+	if arr.Items != nil {
+		items.Name = arr.Items.elementName(name)
+		items.Type = c.namedType(arr.Items)
+	}
+	if !head.XML.IsWrapped() {
+		items.MinOccurs, items.MaxOccurs = xsdOccurs(arr, required)
+		items.Annotation = xsdAnnotation(base)
+		return items
+	}
+	items.Name = xsdItemName(arr, name)
+	items.MinOccurs, items.MaxOccurs = xsdOccurs(arr, true)
+	el := &XSDElement{Name: name, Annotation: xsdAnnotation(base)}
+	if !required {
+		el.MinOccurs = xsdOptional
+	}
+	el.ComplexType = &XSDComplexType{Sequence: &XSDGroup{Elements: []*XSDElement{items}}}
+	return el
+}
+
+// itemsElement returns the repeated element of array items.
+func (c *XSDConverter) itemsElement(arr *ArrayShape, name string, required bool) *XSDElement {
+	el := &XSDElement{Name: name}
+	el.MinOccurs, el.MaxOccurs = xsdOccurs(arr, required)
+	if arr.Items == nil {
+		el.Type = XSDTypeAnyType
+		return el
+	}
+	items := arr.Items
+	if member, ok := nonNilMember(items); ok {
+		items = member
+		el.Nillable = true
+	}
+	el.Name = items.elementName(name)
+	el.Type, el.ComplexType, el.SimpleType = c.typeOf(items)
+	return el
+}
+
+// typeOf returns either the reference to the type or the anonymous type definition.
+func (c *XSDConverter) typeOf(base *BaseShape) (string, *XSDComplexType, *XSDSimpleType) {
+	switch shape := base.Shape.(type) {
+	case *ObjectShape:
+		return "", c.complexType(shape), nil
+	case *ArrayShape:
+		ct := &XSDComplexType{Sequence: &XSDGroup{
+			Elements: []*XSDElement{c.itemsElement(shape, xsdItemName(shape, base.Name), true)},
+		}}
+		return "", ct, nil
+	case *UnionShape:
+		if st, ok := c.simpleUnion(shape); ok {
+			return "", nil, st
+		}
+		return "", c.choice(shape), nil
+	case *RecursiveShape:
+		return c.namedType(shape.Head), nil, nil
+	case *AnyShape, *JSONShape:
+		return XSDTypeAnyType, nil, nil
+	}
+	typeName, st := c.simpleType(base)
+	return typeName, nil, st
+}
+
+func (c *XSDConverter) complexType(s *ObjectShape) *XSDComplexType {
+	ct := &XSDComplexType{}
+	seq := &XSDGroup{}
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		prop := pair.Value
+		if prop.Shape.XML.IsAttribute() {
+			attr := &XSDAttribute{Name: prop.Shape.elementName(pair.Key), Annotation: xsdAnnotation(prop.Shape)}
+			attr.Type, attr.SimpleType = c.simpleType(prop.Shape)
+			if prop.Required {
+				attr.Use = "required"
+			}
+			ct.Attributes = append(ct.Attributes, attr)
+			continue
+		}
+		seq.Elements = append(seq.Elements, c.element(prop.Shape, pair.Key, prop.Required))
+	}
+	// NOTE: Additional properties are allowed by default in RAML, but the wildcard is added only if it is explicit
+	// to keep the schema strict for the most common case.
+	if s.PatternProperties.Len() > 0 || (s.AdditionalProperties != nil && *s.AdditionalProperties) {
+		seq.Any = xsdAnyElements()
+	}
+	if len(seq.Elements) > 0 || seq.Any != nil {
+		ct.Sequence = seq
+	}
+	return ct
+}
+
+// simpleUnion returns the union of simple types if all members are scalars.
+func (c *XSDConverter) simpleUnion(s *UnionShape) (*XSDSimpleType, bool) {
+	union := &XSDUnion{}
+	for _, member := range s.AnyOf {
+		if !isScalarShape(member.Shape) {
+			return nil, false
+		}
+		typeName, st := c.simpleType(member)
+		if st == nil {
+			st = &XSDSimpleType{Restriction: &XSDRestriction{Base: typeName}}
+		}
+		union.SimpleTypes = append(union.SimpleTypes, st)
+	}
+	return &XSDSimpleType{Union: union}, true
+}
+
+// choice returns the choice of elements named after the union members.
+func (c *XSDConverter) choice(s *UnionShape) *XSDComplexType {
+	group := &XSDGroup{}
+	for _, member := range s.AnyOf {
+		name := member.TypeLabel
+		if name == "" {
+			name = member.Name
+		}
+		if name == "" {
+			name = member.Type
+		}
+		group.Elements = append(group.Elements, c.element(member, name, true))
+	}
+	return &XSDComplexType{Choice: group}
+}
+
+// simpleType returns the built-in type of the scalar shape or the restriction of the built-in type if the shape
+// declares facets.
+func (c *XSDConverter) simpleType(base *BaseShape) (string, *XSDSimpleType) {
+	r := &XSDRestriction{}
+	switch shape := base.Shape.(type) {
+	case *StringShape:
+		r.Base = XSDTypeString
+		r.Enumerations = xsdEnum(shape.Enum)
+		r.MinLength = xsdUint(shape.MinLength)
+		r.MaxLength = xsdUint(shape.MaxLength)
+		if shape.Pattern != nil {
+			r.Patterns = []XSDFacet{{Value: xsdPattern(shape.Pattern.String())}}
+		}
+	case *IntegerShape:
+		r.Base = XSDTypeInteger
+		if shape.Format != nil {
+			r.Base = xsdIntegerTypes[*shape.Format]
+		}
+		r.Enumerations = xsdEnum(shape.Enum)
+		if shape.Minimum != nil {
+			r.setMinimum(shape.Minimum.String(), base.isExclusiveBound(AnnotationExclusiveMinimum))
+		}
+		if shape.Maximum != nil {
+			r.setMaximum(shape.Maximum.String(), base.isExclusiveBound(AnnotationExclusiveMaximum))
+		}
+	case *NumberShape:
+		r.Base = XSDTypeDouble
+		if shape.Format != nil {
+			switch *shape.Format {
+			case "float":
+				r.Base = XSDTypeFloat
+			case NumberFormatDecimal:
+				r.Base = XSDTypeDecimal
+			}
+		}
+		r.Enumerations = xsdEnum(shape.Enum)
+		if shape.Minimum != nil {
+			r.setMinimum(formatFloat(*shape.Minimum), base.isExclusiveBound(AnnotationExclusiveMinimum))
+		}
+		if shape.Maximum != nil {
+			r.setMaximum(formatFloat(*shape.Maximum), base.isExclusiveBound(AnnotationExclusiveMaximum))
+		}
+	case *BooleanShape:
+		r.Base = XSDTypeBoolean
+		r.Enumerations = xsdEnum(shape.Enum)
+	case *DateTimeShape:
+		r.Base = XSDTypeDateTime
+		r.Enumerations = xsdEnum(shape.Enum)
+		if shape.Format != nil && *shape.Format == DateTimeFormatRFC2616 {
+			r.Base = XSDTypeString
+			r.Patterns = []XSDFacet{{Value: rfc2616Pattern}}
+		}
+	case *DateTimeOnlyShape:
+		r.Base = XSDTypeDateTime
+		r.Enumerations = xsdEnum(shape.Enum)
+	case *DateOnlyShape:
+		r.Base = XSDTypeDate
+		r.Enumerations = xsdEnum(shape.Enum)
+	case *TimeOnlyShape:
+		r.Base = XSDTypeTime
+		r.Enumerations = xsdEnum(shape.Enum)
+	case *FileShape:
+		r.Base = XSDTypeBase64Binary
+		r.MinLength = xsdUint(shape.MinLength)
+		r.MaxLength = xsdUint(shape.MaxLength)
+	case *NilShape:
+		r.Base = XSDTypeString
+		r.MaxLength = &XSDFacet{Value: "0"}
+	case *UnionShape:
+		if st, ok := c.simpleUnion(shape); ok {
+			return "", st
+		}
+		return XSDTypeAnySimple, nil
+	default:
+		return XSDTypeAnySimple, nil
+	}
+	if r.Enumerations == nil && r.MinInclusive == nil && r.MaxInclusive == nil && r.MinExclusive == nil &&
+		r.MaxExclusive == nil && r.MinLength == nil && r.MaxLength == nil && r.Patterns == nil {
+		return r.Base, nil
+	}
+	return "", &XSDSimpleType{Restriction: r}
+}
+
+func (r *XSDRestriction) setMinimum(value string, exclusive bool) {
+	if exclusive {
+		r.MinExclusive = &XSDFacet{Value: value}
+	} else {
+		r.MinInclusive = &XSDFacet{Value: value}
+	}
+}
+
+func (r *XSDRestriction) setMaximum(value string, exclusive bool) {
+	if exclusive {
+		r.MaxExclusive = &XSDFacet{Value: value}
+	} else {
+		r.MaxInclusive = &XSDFacet{Value: value}
+	}
+}
+
+var xsdIntegerTypes = map[string]string{
+	"int8":  XSDTypeByte,
+	"int16": XSDTypeShort,
+	"int32": XSDTypeInt,
+	"int":   XSDTypeInt,
+	"int64": XSDTypeLong,
+	"long":  XSDTypeLong,
+}
+
+// nonNilMember returns the member of the nullable union, e.g. "string?".
+func nonNilMember(base *BaseShape) (*BaseShape, bool) {
+	union, ok := base.Shape.(*UnionShape)
+	if !ok || len(union.AnyOf) != 2 {
+		return nil, false
+	}
+	for i, member := range union.AnyOf {
+		if _, isNil := member.Shape.(*NilShape); isNil {
+			return union.AnyOf[1-i], true
+		}
+	}
+	return nil, false
+}
+
+// xsdOccurs returns occurrence constraints of repeated elements of array items.
+func xsdOccurs(arr *ArrayShape, required bool) (string, string) {
+	minOccurs, maxOccurs := xsdOptional, xsdUnbounded
+	if arr.MinItems != nil && required {
+		minOccurs = strconv.FormatUint(*arr.MinItems, 10)
+	}
+	if arr.MaxItems != nil {
+		maxOccurs = strconv.FormatUint(*arr.MaxItems, 10)
+	}
+	return minOccurs, maxOccurs
+}
+
+// xsdTypeName returns the name of the type declared for the shape.
+func xsdTypeName(base *BaseShape) string {
+	name := base.TypeLabel
+	if name == "" || strings.ContainsAny(name, "[]|?()/.") {
+		name = strings.TrimSuffix(base.Name, "?")
+	}
+	if name == "" {
+		return "Type"
+	}
+	return name
+}
+
+// xsdItemName returns the element name of items of the wrapped array.
+func xsdItemName(arr *ArrayShape, defaultName string) string {
+	if arr.Items == nil {
+		return defaultName
+	}
+	if arr.Items.XML != nil && arr.Items.XML.Name != nil {
+		return *arr.Items.XML.Name
+	}
+	if arr.Items.TypeLabel != "" {
+		return arr.Items.TypeLabel
+	}
+	return defaultName
+}
+
+func xsdAnyElements() *XSDAny {
+	return &XSDAny{MinOccurs: xsdOptional, MaxOccurs: xsdUnbounded, ProcessContents: "lax"}
+}
+
+func xsdAnnotation(base *BaseShape) *XSDAnnotation {
+	if base.Description == nil {
+		return nil
+	}
+	return &XSDAnnotation{Documentation: *base.Description}
+}
+
+func xsdEnum(enum Nodes) []XSDFacet {
+	if enum == nil {
+		return nil
+	}
+	facets := make([]XSDFacet, len(enum))
+	for i, n := range enum {
+		facets[i] = XSDFacet{Value: fmt.Sprint(n.Value)}
+	}
+	return facets
+}
+
+func xsdUint(v *uint64) *XSDFacet {
+	if v == nil {
+		return nil
+	}
+	return &XSDFacet{Value: strconv.FormatUint(*v, 10)}
+}
+
+// xsdPattern converts the regular expression to XSD pattern that is always anchored to the whole value.
+func xsdPattern(pattern string) string {
+	p, anchoredStart := strings.CutPrefix(pattern, "^")
+	p, anchoredEnd := strings.CutSuffix(p, "$")
+	if anchoredStart && anchoredEnd {
+		return p
+	}
+	p = "(" + p + ")"
+	if !anchoredStart {
+		p = ".*" + p
+	}
+	if !anchoredEnd {
+		p += ".*"
+	}
+	return p
+}
@@ -0,0 +1,208 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestXSDConverter_Convert(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Person:
+    description: A person.
+    xml:
+      name: person
+      namespace: http://example.com/person
+    properties:
+      id:
+        type: integer
+        format: int64
+        xml:
+          attribute: true
+      name:
+        type: string
+        pattern: ^[A-Z]
+      nick: string?
+      addresses:
+        type: array
+        items: Address
+        minItems: 1
+        xml:
+          wrapped: true
+      tags?: string[]
+  Address:
+    additionalProperties: true
+    properties:
+      born: date-only
+  Status:
+    type: string
+    enum: [active, inactive]
+  Price:
+    type: number
+    format: decimal
+  Pet:
+    properties:
+      kind: Dog | Cat
+      id: string | integer
+  Dog:
+    properties:
+      bark: boolean
+  Cat:
+    properties:
+      meow: number
+  Node:
+    properties:
+      next?: Node
+`
+	tests := []struct {
+		name     string
+		typeName string
+		want     string
+	}{
+		{
+			name:     "object with attributes, wrapped and repeated arrays",
+			typeName: "Person",
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:tns="http://example.com/person" targetNamespace="http://example.com/person" elementFormDefault="qualified">
+  <xs:element name="person" type="tns:Person">
+    <xs:annotation>
+      <xs:documentation>A person.</xs:documentation>
+    </xs:annotation>
+  </xs:element>
+  <xs:complexType name="Person">
+    <xs:annotation>
+      <xs:documentation>A person.</xs:documentation>
+    </xs:annotation>
+    <xs:sequence>
+      <xs:element name="name">
+        <xs:simpleType>
+          <xs:restriction base="xs:string">
+            <xs:pattern value="([A-Z]).*"></xs:pattern>
+          </xs:restriction>
+        </xs:simpleType>
+      </xs:element>
+      <xs:element name="nick" type="xs:string" nillable="true"></xs:element>
+      <xs:element name="addresses">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="Address" minOccurs="1" maxOccurs="unbounded">
+              <xs:complexType>
+                <xs:sequence>
+                  <xs:element name="born" type="xs:date"></xs:element>
+                  <xs:any minOccurs="0" maxOccurs="unbounded" processContents="lax"></xs:any>
+                </xs:sequence>
+              </xs:complexType>
+            </xs:element>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="tags" type="xs:string" minOccurs="0" maxOccurs="unbounded"></xs:element>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:long" use="required"></xs:attribute>
+  </xs:complexType>
+</xs:schema>
+`,
+		},
+		{
+			name:     "scalar with enum",
+			typeName: "Status",
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Status" type="Status"></xs:element>
+  <xs:simpleType name="Status">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="active"></xs:enumeration>
+      <xs:enumeration value="inactive"></xs:enumeration>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>
+`,
+		},
+		{
+			name:     "decimal number",
+			typeName: "Price",
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Price" type="Price"></xs:element>
+  <xs:simpleType name="Price">
+    <xs:restriction base="xs:decimal"></xs:restriction>
+  </xs:simpleType>
+</xs:schema>
+`,
+		},
+		{
+			name:     "unions",
+			typeName: "Pet",
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Pet" type="Pet"></xs:element>
+  <xs:complexType name="Pet">
+    <xs:sequence>
+      <xs:element name="kind">
+        <xs:complexType>
+          <xs:choice>
+            <xs:element name="Dog">
+              <xs:complexType>
+                <xs:sequence>
+                  <xs:element name="bark" type="xs:boolean"></xs:element>
+                </xs:sequence>
+              </xs:complexType>
+            </xs:element>
+            <xs:element name="Cat">
+              <xs:complexType>
+                <xs:sequence>
+                  <xs:element name="meow" type="xs:double"></xs:element>
+                </xs:sequence>
+              </xs:complexType>
+            </xs:element>
+          </xs:choice>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="id">
+        <xs:simpleType>
+          <xs:union>
+            <xs:simpleType>
+              <xs:restriction base="xs:string"></xs:restriction>
+            </xs:simpleType>
+            <xs:simpleType>
+              <xs:restriction base="xs:integer"></xs:restriction>
+            </xs:simpleType>
+          </xs:union>
+        </xs:simpleType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>
+`,
+		},
+		{
+			name:     "recursive type",
+			typeName: "Node",
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Node" type="Node"></xs:element>
+  <xs:complexType name="Node">
+    <xs:sequence>
+      <xs:element name="next" type="Node" minOccurs="0"></xs:element>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>
+`,
+		},
+	}
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithUnwrap(), OptWithValidate())
+	require.NoError(t, err)
+	lib := rml.EntryPoint().(*Library)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ok := lib.Types.Get(tt.typeName)
+			require.True(t, ok)
+			schema, err := NewXSDConverter().Convert(s.Shape)
+			require.NoError(t, err)
+			got, err := schema.Marshal()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package raml
+
+import (
+	"io"
+
+	"github.com/acronis/go-stacktrace"
+	"gopkg.in/yaml.v3"
+)
+
+type parseOptWithYAMLAliases struct{}
+
+func (parseOptWithYAMLAliases) Apply(opt *parserOptions) {
+	opt.yamlAliases = true
+}
+
+// OptWithYAMLAliases expands YAML aliases (*name) and merge keys (<<) in fragments. Expanded nodes keep positions
+// of the anchored nodes, so errors point to the definitions. Without this option, aliases and merge keys are
+// rejected with errors at their positions.
+func OptWithYAMLAliases() ParseOpt {
+	return parseOptWithYAMLAliases{}
+}
+
+// decodeDocument decodes the YAML document of the fragment at the path into out after aliases and merge keys are
+// expanded or rejected.
+func (r *RAML) decodeDocument(f io.Reader, path string, out any) error {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(f).Decode(&doc); err != nil {
+		return StacktraceNewWrapped("decode fragment", err, path, stacktrace.WithType(stacktrace.TypeParsing))
+	}
+	if err := r.resolveYAMLAliases(&doc, path); err != nil {
+		return err
+	}
+	if err := doc.Decode(out); err != nil {
+		return StacktraceNewWrapped("decode fragment", err, path, stacktrace.WithType(stacktrace.TypeParsing))
+	}
+	return nil
+}
+
+// resolveYAMLAliases expands aliases and merge keys in the node if OptWithYAMLAliases is set or returns an error at
+// the first one otherwise.
+func (r *RAML) resolveYAMLAliases(node *yaml.Node, location string) error {
+	return (&aliasResolver{expand: r.yamlAliases, location: location}).resolve(node)
+}
+
+type aliasResolver struct {
+	expand   bool
+	location string
+	// expanding contains anchored nodes that are being expanded to detect aliases that contain themselves.
+	expanding []*yaml.Node
+}
+
+func (a *aliasResolver) resolve(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.AliasNode:
+		if !a.expand {
+			return stacktrace.New("YAML aliases are not allowed", a.location, WithNodePosition(node),
+				stacktrace.WithInfo("anchor", node.Value), stacktrace.WithType(stacktrace.TypeParsing))
+		}
+		target := node.Alias
+		for _, n := range a.expanding {
+			if n == target {
+				return stacktrace.New("YAML alias refers to its own anchor", a.location, WithNodePosition(node),
+					stacktrace.WithInfo("anchor", node.Value), stacktrace.WithType(stacktrace.TypeParsing))
+			}
+		}
+		a.expanding = append(a.expanding, target)
+		defer func() { a.expanding = a.expanding[:len(a.expanding)-1] }()
+		expanded := *target
+		expanded.Anchor = ""
+		expanded.Content = append([]*yaml.Node(nil), target.Content...)
+		if err := a.resolve(&expanded); err != nil {
+			return err
+		}
+		*node = expanded
+		return nil
+	case yaml.MappingNode:
+		// Merge keys are reported before the aliases they usually refer to.
+		for i := 0; i < len(node.Content) && !a.expand; i += 2 {
+			if isMergeKey(node.Content[i]) {
+				return stacktrace.New("YAML merge keys are not allowed", a.location, WithNodePosition(node.Content[i]),
+					stacktrace.WithType(stacktrace.TypeParsing))
+			}
+		}
+		for i := range node.Content {
+			if err := a.resolve(node.Content[i]); err != nil {
+				return err
+			}
+		}
+		return a.merge(node)
+	default:
+		for _, n := range node.Content {
+			if err := a.resolve(n); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// merge replaces merge keys of the mapping with the keys of the merged mappings that the mapping does not define.
+// Like in YAML 1.1, explicit keys take precedence over merged ones and earlier merged mappings over later ones.
+func (a *aliasResolver) merge(node *yaml.Node) error {
+	var explicit, merged []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if !isMergeKey(key) {
+			explicit = append(explicit, key, value)
+			continue
+		}
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for _, src := range sources {
+			if src.Kind != yaml.MappingNode {
+				return stacktrace.New("merge key value must be a mapping or a sequence of mappings", a.location,
+					WithNodePosition(src), stacktrace.WithType(stacktrace.TypeParsing))
+			}
+			merged = append(merged, src.Content...)
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	defined := make(map[string]struct{}, len(explicit)/2)
+	for i := 0; i < len(explicit); i += 2 {
+		defined[explicit[i].Value] = struct{}{}
+	}
+	for i := 0; i+1 < len(merged); i += 2 {
+		if _, ok := defined[merged[i].Value]; ok {
+			continue
+		}
+		defined[merged[i].Value] = struct{}{}
+		explicit = append(explicit, merged[i], merged[i+1])
+	}
+	node.Content = explicit
+	return nil
+}
+
+func isMergeKey(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!merge"
+}
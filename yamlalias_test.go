@@ -0,0 +1,58 @@
+package raml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptWithYAMLAliases(t *testing.T) {
+	content := `#%RAML 1.0 Library
+types:
+  Base: &base
+    type: object
+    properties:
+      id: integer
+  Pet:
+    <<: *base
+    properties:
+      name: string
+  Copy: *base
+  Named:
+    type: object
+    properties:
+      first: &name
+        type: string
+        minLength: 1
+      last: *name
+`
+	_, err := ParseFromString(content, "library.raml", t.TempDir())
+	require.ErrorContains(t, err, "library.raml:8:5: YAML merge keys are not allowed")
+
+	_, err = ParseFromString("#%RAML 1.0 Library\ntypes:\n  A: &a string\n  B: *a\n", "library.raml", t.TempDir())
+	require.ErrorContains(t, err, "library.raml:4:6: YAML aliases are not allowed")
+
+	rml, err := ParseFromString(content, "library.raml", t.TempDir(), OptWithYAMLAliases(), OptWithUnwrap())
+	require.NoError(t, err)
+	lookup := func(name string) *BaseShape {
+		s, errLookup := rml.LookupType(name, "")
+		require.NoError(t, errLookup)
+		return s
+	}
+	// Explicit keys take precedence over merged ones.
+	pet := lookup("Pet").Shape.(*ObjectShape)
+	require.Equal(t, 1, pet.Properties.Len())
+	_, ok := pet.Properties.Get("name")
+	require.True(t, ok)
+	require.Equal(t, TypeObject, lookup("Copy").Type)
+	// Expanded nodes keep positions of the anchored ones.
+	require.Equal(t, lookup("Base").Position, lookup("Copy").Position)
+
+	named := lookup("Named")
+	require.NoError(t, named.Validate(map[string]any{"first": "A", "last": "B"}))
+	require.Error(t, named.Validate(map[string]any{"first": "A", "last": ""}))
+
+	_, err = ParseFromString("#%RAML 1.0 Library\ntypes:\n  A: &a\n    properties:\n      self: *a\n",
+		"library.raml", t.TempDir(), OptWithYAMLAliases())
+	require.ErrorContains(t, err, "library.raml:5:13: YAML alias refers to its own anchor")
+}